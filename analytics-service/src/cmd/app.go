@@ -3,14 +3,12 @@ package main
 import (
 	"context"
 	"flag"
-	"fmt"
-	"os/signal"
-	"syscall"
 	"time"
 
 	"github.com/linggaaskaedo/go-kill/analytics-service/src/internal/config"
 	"github.com/linggaaskaedo/go-kill/analytics-service/src/internal/handler/pubsub"
 	restHandler "github.com/linggaaskaedo/go-kill/analytics-service/src/internal/handler/rest"
+	analyticsMetrics "github.com/linggaaskaedo/go-kill/analytics-service/src/internal/metrics"
 	"github.com/linggaaskaedo/go-kill/common/app"
 	"github.com/linggaaskaedo/go-kill/common/component/http"
 	"github.com/linggaaskaedo/go-kill/common/component/kafkaconsumer"
@@ -18,10 +16,11 @@ import (
 	"github.com/linggaaskaedo/go-kill/common/component/mongo"
 	"github.com/linggaaskaedo/go-kill/common/component/redis"
 	"github.com/linggaaskaedo/go-kill/common/component/server"
+	"github.com/linggaaskaedo/go-kill/common/component/tracer"
+	commonConfig "github.com/linggaaskaedo/go-kill/common/pkg/config"
 	"github.com/linggaaskaedo/go-kill/common/pkg/logger"
+	"github.com/linggaaskaedo/go-kill/common/pkg/metrics"
 	"github.com/linggaaskaedo/go-kill/common/pkg/middleware"
-
-	"golang.org/x/sync/errgroup"
 )
 
 var (
@@ -42,6 +41,8 @@ var (
 // @host			localhost:8080
 // @schemes		http https
 func main() {
+	src := commonConfig.Source{File: "config.yaml", EnvPrefix: "ANALYTICS"}
+	src.RegisterFlags(flag.CommandLine)
 	flag.IntVar(&minJitter, "minSleep", DefaultMinJitter, "min. sleep duration during app initialization")
 	flag.IntVar(&maxJitter, "maxSleep", DefaultMaxJitter, "max. sleep duration during app initialization")
 	flag.Parse()
@@ -50,7 +51,7 @@ func main() {
 	sleepWithJitter(minJitter, maxJitter)
 
 	// Load config
-	cfg, err := config.Load("config.yaml")
+	cfg, err := config.Load(src)
 	if err != nil {
 		panic(err)
 	}
@@ -58,93 +59,57 @@ func main() {
 	// Initialize logger
 	log := logger.Init(cfg.Logger)
 
+	// Label the metrics with the service name
+	metrics.Init("analytics-service")
+	analyticsMetrics.Register()
+
 	log.Info().Msg("Starting analytics service...")
 
 	// Create application with options
-	appSubComp := app.New(app.WithShutdownTimeout(15*time.Second), app.WithLogger(log))
-	appMainComp := app.New(app.WithShutdownTimeout(15*time.Second), app.WithLogger(log))
+	a := app.New(app.WithShutdownTimeout(10*time.Second), app.WithLogger(log))
+
+	// Added first so that it stops last, exporting the spans of shutdown
+	a.Add(tracer.NewTracerComponent(log, cfg.Tracer))
+
+	// Apply the reloadable settings when the config changes
+	watcher := commonConfig.NewWatcher(log, src, cfg)
+	watcher.Subscribe(func(cfg *config.Config) {
+		logger.SetLevel(cfg.Logger.Level)
+	})
+	a.Add(watcher)
 
-	// Initialize redis component
 	redisComp0 := redis.NewRedisComponent(log, cfg.Redis)
-	appSubComp.Add(redisComp0, 10*time.Second)
+	a.Add(redisComp0)
 
-	// Initialize database component
 	mongoComp0 := mongo.NewMongoDBComponent(log, cfg.Mongo["mongo-0"])
-	if mongoComp0 != nil {
-		appSubComp.Add(mongoComp0, 10*time.Second)
-	}
+	a.Add(mongoComp0)
 
-	// Initialize Kafka producer component
+	// Kafka producer for the consumer's dead-letter queue
 	producerComp := kafkaproducer.NewKafkaProducerComponent(log, cfg.KafkaProducer)
-	appSubComp.Add(producerComp, 10*time.Second)
-
-	// Stage 1: Start independent components (no dependencies)
-	independent := []app.Component{redisComp0, mongoComp0, producerComp}
-
-	// Create a shared context that cancels on SIGINT/SIGTERM
-	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
-	defer stop()
-
-	// Use an errgroup to manage their goroutines
-	indepGroup, indepCtx := errgroup.WithContext(ctx)
-	for _, comp := range independent {
-		indepGroup.Go(func() error {
-			return comp.Start(indepCtx)
-		})
-	}
+	a.Add(producerComp)
 
-	// Wait for each independent component to be ready (with timeout)
-	for _, comp := range independent {
-		select {
-		case <-comp.Ready():
-			log.Info().Str("component", fmt.Sprintf("%T", comp)).Msg("ready")
-		case <-time.After(10 * time.Second):
-			log.Fatal().Msgf("timeout waiting for component %T", comp)
-		}
-	}
+	serviceComp := config.NewServiceComponent(log, redisComp0, mongoComp0, cfg.Repository)
+	a.Add(serviceComp, app.DependsOn(redisComp0, mongoComp0))
 
-	select {
-	case <-producerComp.Ready():
-		log.Info().Str("component", fmt.Sprintf("%T", producerComp)).Msg("ready")
-	case <-time.After(10 * time.Second):
-		log.Fatal().Msgf("timeout waiting for component %T", producerComp)
-	}
+	// The consumer handler needs the initialized service and producer
+	consumerComp := app.NewDeferred(func() (app.Component, error) {
+		consumerHandler := pubsub.NewConsumerGroupHandler(log, serviceComp.Service(), producerComp.Producer())
+		return kafkaconsumer.NewKafkaConsumerComponent(log, cfg.KafkaConsumer, consumerHandler), nil
+	})
+	a.Add(consumerComp, app.DependsOn(serviceComp, producerComp))
 
-	// Initialize middleware
 	mw := middleware.Init(log)
-
-	// Initialize Gin engine
 	gin := http.Init(log, mw, cfg.Http)
 
-	// Now build the service component (which depends on database, mongo, query, etc.)
-	serviceComp := config.NewServiceComponent(log, redisComp0, mongoComp0, cfg.Repository)
-	appMainComp.Add(serviceComp, 10*time.Second)
-
-	// Build Kafka consumer with producer for DLQ
-	consumerHandler := pubsub.NewConsumerGroupHandler(log, serviceComp.Service(), producerComp.Producer())
-	consumerComp := kafkaconsumer.NewKafkaConsumerComponent(log, cfg.KafkaConsumer, consumerHandler)
-	appMainComp.Add(consumerComp, 10*time.Second)
-
-	// Build HTTP server (depends on service)
 	httpServerComp := server.NewHTTPServerComponent(log, cfg.Server, mw, gin, func(ctx context.Context, engine *server.Engine) error {
-		select {
-		case <-serviceComp.Ready():
-			restHandler.InitRestHandler(engine, serviceComp.Service(), serviceComp.Redis(), serviceComp.Mongo())
-			return nil
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-time.After(10 * time.Second):
-			return fmt.Errorf("timeout waiting for HTTP Server")
-		}
+		server.RegisterHealth(engine, a)
+		server.RegisterMetrics(engine)
+		restHandler.InitRestHandler(engine, serviceComp.Service())
+		return nil
 	})
-	appMainComp.Add(httpServerComp, 10*time.Second)
+	a.Add(httpServerComp, app.DependsOn(serviceComp))
 
-	// Run the app – now all components are added and will start in the order they were added.
-	if err := appMainComp.Run(); err != nil {
+	if err := a.Run(); err != nil {
 		log.Fatal().Err(err).Msg("app failed")
 	}
-
-	if err := indepGroup.Wait(); err != nil && err != context.Canceled {
-		log.Error().Err(err).Msg("Independent component error")
-	}
 }
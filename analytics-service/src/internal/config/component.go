@@ -7,8 +7,6 @@ import (
 	"github.com/linggaaskaedo/go-kill/analytics-service/src/internal/service"
 	mongocomponent "github.com/linggaaskaedo/go-kill/common/component/mongo"
 	rediscomponent "github.com/linggaaskaedo/go-kill/common/component/redis"
-	goredis "github.com/redis/go-redis/v9"
-	"go.mongodb.org/mongo-driver/v2/mongo"
 
 	"github.com/rs/zerolog"
 )
@@ -67,11 +65,3 @@ func (s *ServiceComponent) Service() *service.Service {
 func (s *ServiceComponent) Ready() <-chan struct{} {
 	return s.ready
 }
-
-func (s *ServiceComponent) Redis() *goredis.Client {
-	return s.redisComp0.Client()
-}
-
-func (s *ServiceComponent) Mongo() *mongo.Database {
-	return s.mongoComp0.Database()
-}
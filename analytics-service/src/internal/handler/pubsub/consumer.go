@@ -6,9 +6,11 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/linggaaskaedo/go-kill/analytics-service/src/internal/metrics"
 	"github.com/linggaaskaedo/go-kill/analytics-service/src/internal/model/dto"
 	"github.com/linggaaskaedo/go-kill/analytics-service/src/internal/service"
-	"github.com/linggaaskaedo/go-kill/common/pkg/metrics"
+	"github.com/linggaaskaedo/go-kill/common/pkg/tracing"
+	"github.com/linggaaskaedo/go-kill/common/pkg/tracing/kafkatracing"
 
 	"github.com/IBM/sarama"
 	"github.com/rs/xid"
@@ -55,6 +57,9 @@ func (h *ConsumerGroupHandler) ConsumeClaim(sess sarama.ConsumerGroupSession, cl
 		logWithReq := h.log.With().Str("req_id", reqID).Logger()
 		ctx = logWithReq.WithContext(ctx)
 
+		// Continue the trace the producer sent in the headers
+		ctx, span := kafkatracing.StartProcessSpan(ctx, msg)
+
 		metrics.MessagesReceived.WithLabelValues(msg.Topic).Inc()
 		h.log.Info().Str("topic", msg.Topic).Int32("partition", msg.Partition).Int64("offset", msg.Offset).Msg("received message")
 
@@ -66,6 +71,7 @@ func (h *ConsumerGroupHandler) ConsumeClaim(sess sarama.ConsumerGroupSession, cl
 				select {
 				case <-time.After(delay):
 				case <-ctx.Done():
+					tracing.End(span, ctx.Err())
 					return ctx.Err()
 				}
 				zerolog.Ctx(ctx).Info().Int("attempt", attempt).Msg("retrying message processing")
@@ -90,6 +96,8 @@ func (h *ConsumerGroupHandler) ConsumeClaim(sess sarama.ConsumerGroupSession, cl
 			h.sendToDLQ(ctx, reqID, msg, lastErr)
 			sess.MarkMessage(msg, "")
 		}
+
+		tracing.End(span, lastErr)
 	}
 
 	return nil
@@ -160,7 +168,9 @@ func (h *ConsumerGroupHandler) sendToDLQ(ctx context.Context, reqID string, msg
 		},
 	}
 
+	_, span := kafkatracing.StartSendSpan(ctx, producerMsg)
 	_, _, err = h.producer.SendMessage(producerMsg)
+	tracing.End(span, err)
 	if err != nil {
 		metrics.KafkaProducerErrors.Inc()
 		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to send message to DLQ")
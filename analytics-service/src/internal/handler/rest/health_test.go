@@ -1,145 +1,84 @@
 package rest
 
 import (
+	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
-	"github.com/gin-gonic/gin"
-	"github.com/redis/go-redis/v9"
-	"github.com/rs/zerolog"
-	"go.mongodb.org/mongo-driver/v2/mongo"
+	"github.com/linggaaskaedo/go-kill/common/app"
+	"github.com/linggaaskaedo/go-kill/common/component/server"
 )
 
 const (
 	pathHealthLive  = "/health/live"
 	pathHealthReady = "/health/ready"
-	pathMetrics     = "/metrics"
-	errMsgStatusFmt = "expected status %d, got %d"
 )
 
-func setupTestRouter() *gin.Engine {
-	gin.SetMode(gin.TestMode)
-	return gin.New()
-}
-
-func TestLiveness(t *testing.T) {
-	router := setupTestRouter()
-
-	r := &rest{
-		gin: router,
-		log: zerolog.Logger{},
-	}
+// idleComponent is never started, so the app reports it down.
+type idleComponent struct{}
 
-	router.GET(pathHealthLive, r.liveness)
+func (idleComponent) Start(ctx context.Context) error { return nil }
+func (idleComponent) Stop(ctx context.Context) error  { return nil }
+func (idleComponent) Ready() <-chan struct{}          { return make(chan struct{}) }
 
-	req, _ := http.NewRequest(http.MethodGet, pathHealthLive, nil)
-	w := httptest.NewRecorder()
-	router.ServeHTTP(w, req)
-
-	if w.Code != http.StatusOK {
-		t.Errorf(errMsgStatusFmt, http.StatusOK, w.Code)
-	}
-}
+func serveHealth(t *testing.T, a *app.App, path string) (int, app.HealthReport) {
+	t.Helper()
 
-func TestReadinessAllHealthy(t *testing.T) {
 	router := setupTestRouter()
+	server.RegisterHealth(router, a)
 
-	mockRedis := redis.NewClient(&redis.Options{
-		Addr: "localhost:6379",
-	})
-	_ = mockRedis
-
-	mockMongo := &mongo.Client{}
-	_ = mockMongo
-
-	r := &rest{
-		gin:   router,
-		redis: nil,
-		mongo: nil,
-		log:   zerolog.Logger{},
-	}
-
-	router.GET(pathHealthReady, r.readiness)
-
-	req, _ := http.NewRequest(http.MethodGet, pathHealthReady, nil)
+	req, _ := http.NewRequest(http.MethodGet, path, nil)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
-	if w.Code != http.StatusOK {
-		t.Errorf(errMsgStatusFmt, http.StatusOK, w.Code)
+	var report app.HealthReport
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatalf("decode health report: %v", err)
 	}
+
+	return w.Code, report
 }
 
-func TestReadinessRedisDown(t *testing.T) {
-	router := setupTestRouter()
+func TestLiveness(t *testing.T) {
+	a := app.New()
+	a.Add(idleComponent{})
 
-	mockRedis := redis.NewClient(&redis.Options{
-		Addr: "invalid:6379",
-	})
+	code, report := serveHealth(t, a, pathHealthLive)
 
-	r := &rest{
-		gin:   router,
-		redis: mockRedis,
-		mongo: nil,
-		log:   zerolog.Logger{},
+	if code != http.StatusOK {
+		t.Errorf(errMsgStatusFmt, http.StatusOK, code)
 	}
-
-	router.GET(pathHealthReady, r.readiness)
-
-	req, _ := http.NewRequest(http.MethodGet, pathHealthReady, nil)
-	w := httptest.NewRecorder()
-	router.ServeHTTP(w, req)
-
-	if w.Code != http.StatusServiceUnavailable {
-		t.Errorf(errMsgStatusFmt, http.StatusServiceUnavailable, w.Code)
+	if report.Status != app.HealthUp {
+		t.Errorf("expected status %q, got %q", app.HealthUp, report.Status)
 	}
 }
 
-func TestReadinessMongoDown(t *testing.T) {
-	router := setupTestRouter()
+func TestReadinessAllHealthy(t *testing.T) {
+	code, report := serveHealth(t, app.New(), pathHealthReady)
 
-	r := &rest{
-		gin:   router,
-		redis: nil,
-		mongo: nil,
-		log:   zerolog.Logger{},
+	if code != http.StatusOK {
+		t.Errorf(errMsgStatusFmt, http.StatusOK, code)
 	}
-
-	router.GET(pathHealthReady, r.readiness)
-
-	req, _ := http.NewRequest(http.MethodGet, pathHealthReady, nil)
-	w := httptest.NewRecorder()
-	router.ServeHTTP(w, req)
-
-	if w.Code != http.StatusOK {
-		t.Errorf(errMsgStatusFmt, http.StatusOK, w.Code)
+	if report.Status != app.HealthUp {
+		t.Errorf("expected status %q, got %q", app.HealthUp, report.Status)
 	}
 }
 
-func TestMetricsHandler(t *testing.T) {
-	router := setupTestRouter()
-
-	r := &rest{
-		gin: router,
-		log: zerolog.Logger{},
-	}
+func TestReadinessComponentDown(t *testing.T) {
+	a := app.New()
+	a.Add(idleComponent{}, app.Name("mongo"))
 
-	router.GET(pathMetrics, r.metricsHandler())
-
-	req, _ := http.NewRequest(http.MethodGet, pathMetrics, nil)
-	w := httptest.NewRecorder()
-	router.ServeHTTP(w, req)
+	code, report := serveHealth(t, a, pathHealthReady)
 
-	if w.Code != http.StatusOK {
-		t.Errorf(errMsgStatusFmt, http.StatusOK, w.Code)
+	if code != http.StatusServiceUnavailable {
+		t.Errorf(errMsgStatusFmt, http.StatusServiceUnavailable, code)
 	}
-}
-
-func TestInitMetrics(t *testing.T) {
-	r := &rest{
-		log: zerolog.Logger{},
+	if report.Status != app.HealthDown {
+		t.Errorf("expected status %q, got %q", app.HealthDown, report.Status)
+	}
+	if len(report.Checks) != 1 || report.Checks[0].Name != "mongo" {
+		t.Errorf("expected one check named mongo, got %+v", report.Checks)
 	}
-
-	r.initMetrics()
 }
@@ -0,0 +1,19 @@
+package rest
+
+import (
+	"github.com/linggaaskaedo/go-kill/analytics-service/src/internal/metrics"
+)
+
+// initMetrics exports the pipeline's series before the first message, so
+// that they exist at zero. /metrics itself is served by the app, see
+// server.RegisterMetrics.
+func (e *rest) initMetrics() {
+	metrics.MessagesReceived.WithLabelValues("")
+	metrics.MessagesProcessed.WithLabelValues("", "")
+	metrics.MessageProcessingDuration.WithLabelValues("")
+	metrics.DLQMessagesSent.WithLabelValues("")
+	metrics.RetryAttempts.WithLabelValues("")
+	metrics.MongoOperations.WithLabelValues("", "", "")
+	metrics.MongoOperationDuration.WithLabelValues("", "")
+	metrics.RedisOperations.WithLabelValues("", "")
+}
@@ -0,0 +1,92 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/linggaaskaedo/go-kill/analytics-service/src/internal/metrics"
+	"github.com/linggaaskaedo/go-kill/common/component/server"
+	commonMetrics "github.com/linggaaskaedo/go-kill/common/pkg/metrics"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+const (
+	pathMetrics     = "/metrics"
+	errMsgStatusFmt = "expected status %d, got %d"
+)
+
+// The collectors are registered the way the app does it, once per process
+func init() {
+	commonMetrics.Init("analytics-service")
+	metrics.Register()
+}
+
+func setupTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	return gin.New()
+}
+
+func scrapeMetrics(t *testing.T) string {
+	t.Helper()
+
+	router := setupTestRouter()
+	server.RegisterMetrics(router)
+
+	req, _ := http.NewRequest(http.MethodGet, pathMetrics, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf(errMsgStatusFmt, http.StatusOK, w.Code)
+	}
+
+	return w.Body.String()
+}
+
+func TestInitMetrics(t *testing.T) {
+	r := &rest{
+		log: zerolog.Logger{},
+	}
+
+	r.initMetrics()
+
+	body := scrapeMetrics(t)
+
+	for _, series := range []string{
+		`analytics_messages_received_total{service="analytics-service",topic=""} 0`,
+		`analytics_messages_processed_total{service="analytics-service",status="",topic=""} 0`,
+		`analytics_message_processing_duration_seconds_count{service="analytics-service",topic=""} 0`,
+		`analytics_dlq_messages_total{service="analytics-service",topic=""} 0`,
+		`analytics_retry_attempts_total{service="analytics-service",topic=""} 0`,
+		`analytics_mongo_operations_total{collection="",operation="",service="analytics-service",status=""} 0`,
+		`analytics_mongo_operation_duration_seconds_count{collection="",operation="",service="analytics-service"} 0`,
+		`analytics_redis_operations_total{operation="",service="analytics-service",status=""} 0`,
+		`analytics_kafka_producer_errors_total{service="analytics-service"} 0`,
+	} {
+		if !strings.Contains(body, series) {
+			t.Errorf("expected series %s", series)
+		}
+	}
+}
+
+func TestMetricsCountMessages(t *testing.T) {
+	metrics.MessagesReceived.WithLabelValues("user.registered").Inc()
+	metrics.MessagesProcessed.WithLabelValues("user.registered", "success").Inc()
+
+	body := scrapeMetrics(t)
+
+	for _, series := range []string{
+		`analytics_messages_received_total{service="analytics-service",topic="user.registered"} 1`,
+		`analytics_messages_processed_total{service="analytics-service",status="success",topic="user.registered"} 1`,
+		// The shared collectors are served next to the pipeline's
+		`http_requests_in_flight{service="analytics-service"}`,
+	} {
+		if !strings.Contains(body, series) {
+			t.Errorf("expected series %s", series)
+		}
+	}
+}
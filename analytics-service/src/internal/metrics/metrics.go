@@ -0,0 +1,99 @@
+// Package metrics declares the analytics pipeline's collectors.
+package metrics
+
+import (
+	commonMetrics "github.com/linggaaskaedo/go-kill/common/pkg/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	MessagesReceived = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "analytics_messages_received_total",
+			Help: "Total number of messages received from Kafka",
+		},
+		[]string{"topic"},
+	)
+
+	MessagesProcessed = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "analytics_messages_processed_total",
+			Help: "Total number of messages successfully processed",
+		},
+		[]string{"topic", "status"},
+	)
+
+	MessageProcessingDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "analytics_message_processing_duration_seconds",
+			Help:    "Histogram of message processing duration",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"topic"},
+	)
+
+	DLQMessagesSent = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "analytics_dlq_messages_total",
+			Help: "Total number of messages sent to DLQ",
+		},
+		[]string{"topic"},
+	)
+
+	RetryAttempts = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "analytics_retry_attempts_total",
+			Help: "Total number of retry attempts",
+		},
+		[]string{"topic"},
+	)
+
+	MongoOperations = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "analytics_mongo_operations_total",
+			Help: "Total number of MongoDB operations",
+		},
+		[]string{"collection", "operation", "status"},
+	)
+
+	MongoOperationDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "analytics_mongo_operation_duration_seconds",
+			Help:    "Histogram of MongoDB operation duration",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"collection", "operation"},
+	)
+
+	RedisOperations = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "analytics_redis_operations_total",
+			Help: "Total number of Redis operations",
+		},
+		[]string{"operation", "status"},
+	)
+
+	KafkaProducerErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "analytics_kafka_producer_errors_total",
+			Help: "Total number of Kafka producer errors",
+		},
+	)
+)
+
+// Register adds the collectors above to the service's metrics. Call it
+// after commonMetrics.Init.
+func Register() {
+	commonMetrics.MustRegister(
+		MessagesReceived,
+		MessagesProcessed,
+		MessageProcessingDuration,
+		DLQMessagesSent,
+		RetryAttempts,
+		MongoOperations,
+		MongoOperationDuration,
+		RedisOperations,
+		KafkaProducerErrors,
+	)
+}
@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users_auth ADD COLUMN locked_until TIMESTAMP NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users_auth DROP COLUMN IF EXISTS locked_until;
+-- +goose StatementEnd
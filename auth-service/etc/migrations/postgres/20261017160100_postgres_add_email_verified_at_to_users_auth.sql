@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users_auth ADD COLUMN email_verified_at TIMESTAMP NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users_auth DROP COLUMN IF EXISTS email_verified_at;
+-- +goose StatementEnd
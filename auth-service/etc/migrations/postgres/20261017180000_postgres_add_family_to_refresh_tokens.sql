@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE refresh_tokens
+    ADD COLUMN family_id UUID,
+    ADD COLUMN parent_id UUID NULL REFERENCES refresh_tokens(id) ON DELETE SET NULL,
+    ADD COLUMN used_at TIMESTAMP NULL,
+    ADD COLUMN revoked_at TIMESTAMP NULL;
+
+UPDATE refresh_tokens SET family_id = id WHERE family_id IS NULL;
+
+ALTER TABLE refresh_tokens ALTER COLUMN family_id SET NOT NULL;
+
+CREATE UNIQUE INDEX idx_refresh_tokens_token_hash ON refresh_tokens(token_hash);
+CREATE INDEX idx_refresh_tokens_family_id ON refresh_tokens(family_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_refresh_tokens_family_id;
+DROP INDEX IF EXISTS idx_refresh_tokens_token_hash;
+
+ALTER TABLE refresh_tokens
+    DROP COLUMN IF EXISTS revoked_at,
+    DROP COLUMN IF EXISTS used_at,
+    DROP COLUMN IF EXISTS parent_id,
+    DROP COLUMN IF EXISTS family_id;
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE user_sessions (
+    id UUID PRIMARY KEY DEFAULT uuidv7(),
+    user_id UUID NOT NULL REFERENCES users_auth(id) ON DELETE CASCADE,
+    ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    user_agent VARCHAR(512) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMP NULL
+);
+
+CREATE INDEX idx_user_sessions_user_id ON user_sessions(user_id);
+
+-- Every existing token family becomes a session without device details
+INSERT INTO user_sessions (id, user_id, created_at, last_seen_at, revoked_at)
+SELECT family_id, user_id, MIN(created_at), MAX(created_at),
+    CASE WHEN BOOL_AND(revoked_at IS NOT NULL) THEN MAX(revoked_at) END
+FROM refresh_tokens
+GROUP BY family_id, user_id;
+
+ALTER TABLE refresh_tokens
+    ADD CONSTRAINT fk_refresh_tokens_session FOREIGN KEY (family_id) REFERENCES user_sessions(id) ON DELETE CASCADE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE refresh_tokens DROP CONSTRAINT IF EXISTS fk_refresh_tokens_session;
+
+DROP TABLE IF EXISTS user_sessions;
+-- +goose StatementEnd
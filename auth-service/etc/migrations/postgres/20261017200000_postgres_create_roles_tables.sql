@@ -0,0 +1,61 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE roles (
+    id UUID PRIMARY KEY DEFAULT uuidv7(),
+    name VARCHAR(64) UNIQUE NOT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE permissions (
+    id UUID PRIMARY KEY DEFAULT uuidv7(),
+    name VARCHAR(128) UNIQUE NOT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE role_permissions (
+    role_id UUID NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    permission_id UUID NOT NULL REFERENCES permissions(id) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, permission_id)
+);
+
+CREATE TABLE user_roles (
+    user_id UUID NOT NULL REFERENCES users_auth(id) ON DELETE CASCADE,
+    role_id UUID NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, role_id)
+);
+
+CREATE INDEX idx_user_roles_role_id ON user_roles(role_id);
+
+INSERT INTO roles (name, description) VALUES
+    ('customer', 'Places and manages own orders'),
+    ('admin', 'Manages the catalog and all orders');
+
+INSERT INTO permissions (name, description) VALUES
+    ('product:write', 'Create and update products and categories'),
+    ('order:read:any', 'Read orders of any user'),
+    ('order:status:update', 'Move orders through their status transitions');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+CROSS JOIN permissions p
+WHERE r.name = 'admin';
+
+-- Everyone registered so far is a customer
+INSERT INTO user_roles (user_id, role_id)
+SELECT u.id, r.id
+FROM users_auth u
+CROSS JOIN roles r
+WHERE r.name = 'customer';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_roles;
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS permissions;
+DROP TABLE IF EXISTS roles;
+-- +goose StatementEnd
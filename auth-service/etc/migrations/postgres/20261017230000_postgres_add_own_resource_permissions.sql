@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Own orders and profile are behind permissions too, so an API key only
+-- reaches them when its scopes say so
+INSERT INTO permissions (name, description) VALUES
+    ('order:read', 'Read own orders'),
+    ('order:write', 'Place and cancel own orders'),
+    ('user:read', 'Read own profile, activities and addresses'),
+    ('user:write', 'Change own profile and addresses');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+CROSS JOIN permissions p
+WHERE r.name IN ('customer', 'admin')
+  AND p.name IN ('order:read', 'order:write', 'user:read', 'user:write');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM permissions
+WHERE name IN ('order:read', 'order:write', 'user:read', 'user:write');
+-- +goose StatementEnd
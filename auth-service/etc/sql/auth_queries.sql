@@ -7,19 +7,231 @@ VALUES ($1, $2, true, NOW(), NOW())
 RETURNING id;
 
 -- name: GetUserByEmail
-SELECT id, email, password_hash, is_active 
-FROM users_auth 
-WHERE email = $1;
+SELECT u.id, u.email, u.password_hash, u.is_active, u.locked_until, u.email_verified_at, 
+    (m.enabled_at IS NOT NULL) AS mfa_enabled 
+FROM users_auth u 
+LEFT JOIN user_mfa m ON m.user_id = u.id 
+WHERE u.email = $1;
+
+-- name: LockUser
+UPDATE users_auth 
+SET locked_until = $2, updated_at = NOW() 
+WHERE id = $1;
 
 -- name: StoreRefreshToken
-INSERT INTO refresh_tokens (user_id, token_hash, expires_at, created_at) 
-VALUES ($1, $2, $3, NOW());
+INSERT INTO refresh_tokens (user_id, token_hash, family_id, parent_id, expires_at, created_at) 
+VALUES ($1, $2, $3, $4, $5, NOW()) 
+RETURNING id;
+
+-- name: GetRefreshTokenByHash
+SELECT id, user_id, token_hash, family_id, parent_id, expires_at, used_at, revoked_at 
+FROM refresh_tokens 
+WHERE token_hash = $1;
+
+-- name: UseRefreshToken
+UPDATE refresh_tokens 
+SET used_at = NOW() 
+WHERE id = $1 AND used_at IS NULL AND revoked_at IS NULL AND expires_at > NOW();
+
+-- name: RevokeRefreshTokenFamily
+UPDATE refresh_tokens 
+SET revoked_at = NOW() 
+WHERE family_id = $1 AND revoked_at IS NULL 
+RETURNING token_hash;
+
+-- name: CreateSession
+INSERT INTO user_sessions (user_id, ip_address, user_agent, created_at, last_seen_at) 
+VALUES ($1, $2, $3, NOW(), NOW()) 
+RETURNING id, created_at, last_seen_at;
+
+-- name: TouchSession
+UPDATE user_sessions 
+SET last_seen_at = NOW(), 
+    ip_address = COALESCE(NULLIF($2, ''), ip_address), 
+    user_agent = COALESCE(NULLIF($3, ''), user_agent) 
+WHERE id = $1;
+
+-- name: GetSession
+SELECT id, user_id, ip_address, user_agent, created_at, last_seen_at, revoked_at 
+FROM user_sessions 
+WHERE id = $1;
+
+-- name: ListActiveSessions
+SELECT s.id, s.user_id, s.ip_address, s.user_agent, s.created_at, s.last_seen_at, s.revoked_at 
+FROM user_sessions s 
+WHERE s.user_id = $1 AND s.revoked_at IS NULL 
+    AND EXISTS (
+        SELECT 1 FROM refresh_tokens r 
+        WHERE r.family_id = s.id AND r.used_at IS NULL AND r.revoked_at IS NULL AND r.expires_at > NOW()
+    ) 
+ORDER BY s.last_seen_at DESC;
+
+-- name: RevokeSession
+UPDATE user_sessions 
+SET revoked_at = NOW() 
+WHERE id = $1 AND revoked_at IS NULL 
+RETURNING id;
+
+-- name: RevokeUserSessions
+UPDATE user_sessions 
+SET revoked_at = NOW() 
+WHERE user_id = $1 AND revoked_at IS NULL 
+RETURNING id;
+
+-- name: RevokeUserRefreshTokens
+UPDATE refresh_tokens 
+SET revoked_at = NOW() 
+WHERE user_id = $1 AND revoked_at IS NULL 
+RETURNING token_hash;
 
 -- name: GetUserWithID
-SELECT email, is_active 
-FROM users_auth 
+SELECT u.id, u.email, u.password_hash, u.is_active, u.locked_until, u.email_verified_at, 
+    (m.enabled_at IS NOT NULL) AS mfa_enabled 
+FROM users_auth u 
+LEFT JOIN user_mfa m ON m.user_id = u.id 
+WHERE u.id = $1;
+
+-- name: DeleteUnusedAuthTokens
+DELETE FROM auth_tokens 
+WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL;
+
+-- name: StoreAuthToken
+INSERT INTO auth_tokens (user_id, purpose, token_hash, expires_at, created_at) 
+VALUES ($1, $2, $3, $4, NOW());
+
+-- name: GetAuthTokenUser
+SELECT user_id FROM auth_tokens 
+WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW();
+
+-- name: ConsumeAuthToken
+UPDATE auth_tokens 
+SET used_at = NOW() 
+WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW() 
+RETURNING user_id;
+
+-- name: MarkEmailVerified
+UPDATE users_auth 
+SET email_verified_at = COALESCE(email_verified_at, NOW()), updated_at = NOW() 
+WHERE id = $1;
+
+-- name: UpdatePassword
+UPDATE users_auth 
+SET password_hash = $2, locked_until = NULL, updated_at = NOW() 
 WHERE id = $1;
 
--- name: DeleteRefreshToken
-DELETE FROM refresh_tokens 
-WHERE user_id = $1;
\ No newline at end of file
+-- name: RehashPassword
+UPDATE users_auth 
+SET password_hash = $3, updated_at = NOW() 
+WHERE id = $1 AND password_hash = $2;
+
+-- name: SaveMfaSecret
+INSERT INTO user_mfa (user_id, secret, created_at, updated_at) 
+VALUES ($1, $2, NOW(), NOW()) 
+ON CONFLICT (user_id) DO UPDATE 
+SET secret = EXCLUDED.secret, last_used_step = 0, updated_at = NOW() 
+WHERE user_mfa.enabled_at IS NULL;
+
+-- name: GetUserMfa
+SELECT user_id, secret, enabled_at, last_used_step 
+FROM user_mfa 
+WHERE user_id = $1;
+
+-- name: EnableMfa
+UPDATE user_mfa 
+SET enabled_at = NOW(), last_used_step = $2, updated_at = NOW() 
+WHERE user_id = $1 AND enabled_at IS NULL;
+
+-- name: UseMfaStep
+UPDATE user_mfa 
+SET last_used_step = $2, updated_at = NOW() 
+WHERE user_id = $1 AND last_used_step < $2;
+
+-- name: DeleteUserMfa
+DELETE FROM user_mfa 
+WHERE user_id = $1;
+
+-- name: DeleteMfaRecoveryCodes
+DELETE FROM mfa_recovery_codes 
+WHERE user_id = $1;
+
+-- name: StoreMfaRecoveryCode
+INSERT INTO mfa_recovery_codes (user_id, code_hash, created_at) 
+VALUES ($1, $2, NOW());
+
+-- name: UseMfaRecoveryCode
+UPDATE mfa_recovery_codes 
+SET used_at = NOW() 
+WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL;
+
+-- name: AssignUserRole
+INSERT INTO user_roles (user_id, role_id, created_at) 
+SELECT $1, id, NOW() FROM roles WHERE name = $2 
+ON CONFLICT DO NOTHING;
+
+-- name: GetUserRoles
+SELECT r.name 
+FROM user_roles ur 
+JOIN roles r ON r.id = ur.role_id 
+WHERE ur.user_id = $1 
+ORDER BY r.name;
+
+-- name: GetUserPermissions
+SELECT DISTINCT p.name 
+FROM user_roles ur 
+JOIN role_permissions rp ON rp.role_id = ur.role_id 
+JOIN permissions p ON p.id = rp.permission_id 
+WHERE ur.user_id = $1 
+ORDER BY p.name;
+
+-- name: GetUserIdentity
+SELECT id, user_id, provider, subject, email, created_at, last_login_at 
+FROM user_identities 
+WHERE provider = $1 AND subject = $2;
+
+-- name: SaveUserIdentity
+INSERT INTO user_identities (user_id, provider, subject, email, created_at, last_login_at) 
+VALUES ($1, $2, $3, $4, NOW(), NOW()) 
+ON CONFLICT (provider, subject) DO UPDATE 
+SET email = EXCLUDED.email, last_login_at = NOW() 
+WHERE user_identities.user_id = EXCLUDED.user_id;
+
+-- name: TouchUserIdentity
+UPDATE user_identities 
+SET email = $2, last_login_at = NOW() 
+WHERE id = $1;
+
+-- name: CreateApiKey
+INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes, expires_at, created_at) 
+VALUES ($1, $2, $3, $4, $5, $6, NOW()) 
+RETURNING id, created_at;
+
+-- name: GetApiKeyByID
+SELECT id, user_id, name, prefix, scopes, expires_at, last_used_at, created_at, revoked_at 
+FROM api_keys 
+WHERE id = $1;
+
+-- name: GetApiKeyByHash
+SELECT id, user_id, name, prefix, scopes, expires_at, last_used_at, created_at, revoked_at 
+FROM api_keys 
+WHERE key_hash = $1;
+
+-- name: ListApiKeys
+SELECT id, user_id, name, prefix, scopes, expires_at, last_used_at, created_at, revoked_at 
+FROM api_keys 
+WHERE user_id = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > NOW()) 
+ORDER BY created_at DESC;
+
+-- name: ExpireApiKey
+UPDATE api_keys 
+SET expires_at = LEAST(COALESCE(expires_at, $2), $2) 
+WHERE id = $1 AND revoked_at IS NULL;
+
+-- name: RevokeApiKey
+UPDATE api_keys 
+SET revoked_at = NOW() 
+WHERE id = $1 AND revoked_at IS NULL;
+
+-- name: TouchApiKey
+UPDATE api_keys 
+SET last_used_at = NOW() 
+WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - $2 * INTERVAL '1 second');
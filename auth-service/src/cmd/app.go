@@ -3,25 +3,28 @@ package main
 import (
 	"context"
 	"flag"
-	"fmt"
-	"os/signal"
-	"syscall"
 	"time"
 
 	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/config"
 	restHandler "github.com/linggaaskaedo/go-kill/auth-service/src/internal/handler/rest"
 	"github.com/linggaaskaedo/go-kill/common/app"
+	"github.com/linggaaskaedo/go-kill/common/component/breachlist"
 	"github.com/linggaaskaedo/go-kill/common/component/database"
+	"github.com/linggaaskaedo/go-kill/common/component/grpcclient"
 	"github.com/linggaaskaedo/go-kill/common/component/grpcserver"
 	"github.com/linggaaskaedo/go-kill/common/component/http"
+	"github.com/linggaaskaedo/go-kill/common/component/kafkaproducer"
 	"github.com/linggaaskaedo/go-kill/common/component/query"
 	"github.com/linggaaskaedo/go-kill/common/component/redis"
 	"github.com/linggaaskaedo/go-kill/common/component/server"
+	"github.com/linggaaskaedo/go-kill/common/component/tracer"
+	commonConfig "github.com/linggaaskaedo/go-kill/common/pkg/config"
+	"github.com/linggaaskaedo/go-kill/common/pkg/idempotency"
 	"github.com/linggaaskaedo/go-kill/common/pkg/logger"
+	"github.com/linggaaskaedo/go-kill/common/pkg/metrics"
 	"github.com/linggaaskaedo/go-kill/common/pkg/middleware"
 	authpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/auth"
 
-	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
 )
 
@@ -43,6 +46,8 @@ var (
 // @host			localhost:8080
 // @schemes		http https
 func main() {
+	src := commonConfig.Source{File: "config.yaml", EnvPrefix: "AUTH"}
+	src.RegisterFlags(flag.CommandLine)
 	flag.IntVar(&minJitter, "minSleep", DefaultMinJitter, "min. sleep duration during app initialization")
 	flag.IntVar(&maxJitter, "maxSleep", DefaultMaxJitter, "max. sleep duration during app initialization")
 	flag.Parse()
@@ -51,7 +56,7 @@ func main() {
 	sleepWithJitter(minJitter, maxJitter)
 
 	// Load config
-	cfg, err := config.Load("config.yaml")
+	cfg, err := config.Load(src)
 	if err != nil {
 		panic(err)
 	}
@@ -59,95 +64,73 @@ func main() {
 	// Initialize logger
 	log := logger.Init(cfg.Logger)
 
+	// Label the metrics with the service name
+	metrics.Init("auth-service")
+
 	log.Info().Msg("Starting user service...")
 
 	// Create application with options
-	appSubComp := app.New(app.WithShutdownTimeout(15*time.Second), app.WithLogger(log))
-	appMainComp := app.New(app.WithShutdownTimeout(15*time.Second), app.WithLogger(log))
+	a := app.New(app.WithShutdownTimeout(10*time.Second), app.WithLogger(log))
+
+	// Added first so that it stops last, exporting the spans of shutdown
+	a.Add(tracer.NewTracerComponent(log, cfg.Tracer))
+
+	// Apply the reloadable settings when the config changes
+	watcher := commonConfig.NewWatcher(log, src, cfg)
+	watcher.Subscribe(func(cfg *config.Config) {
+		logger.SetLevel(cfg.Logger.Level)
+	})
+	a.Add(watcher)
 
-	// Initialize redis component
 	redisComp0 := redis.NewRedisComponent(log, cfg.Redis)
-	appSubComp.Add(redisComp0, 10*time.Second)
+	a.Add(redisComp0)
 
-	// Initialize database component
 	dbComp0 := database.NewDatabaseComponent(log, cfg.Database["db-0"])
-	if dbComp0 != nil {
-		appSubComp.Add(dbComp0, 10*time.Second)
-	}
+	a.Add(dbComp0)
 
-	// Initialize query loader component
 	queryComp := query.NewQueryComponent(log, cfg.Query)
-	appSubComp.Add(queryComp, 10*time.Second)
-
-	// Initialze middleware
-	mw := middleware.Init(log)
-
-	// Initialize Gin engine
-	gin := http.Init(log, mw, cfg.Http)
+	a.Add(queryComp)
 
-	// Stage 1: Start independent components (no dependencies)
-	independent := []app.Component{redisComp0, dbComp0, queryComp}
+	// user-service also calls auth-service, so this client connects lazily
+	// instead of waiting for user-service to be up.
+	userClientComp := grpcclient.NewGRPCClientComponent(log, cfg.GRPCClient["user_service"])
+	a.Add(userClientComp, app.Name("user_service"))
 
-	// Create a shared context that cancels on SIGINT/SIGTERM
-	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
-	defer stop()
+	kafkaProducerComp := kafkaproducer.NewKafkaProducerComponent(log, cfg.KafkaProducer)
+	a.Add(kafkaProducerComp)
 
-	// Use an errgroup to manage their goroutines
-	indepGroup, indepCtx := errgroup.WithContext(ctx)
-	for _, comp := range independent {
-		indepGroup.Go(func() error {
-			return comp.Start(indepCtx)
-		})
+	// Breached password list, only when one is configured
+	var breachListComp *breachlist.BreachListComponent
+	if cfg.BreachedPasswords.Path != "" {
+		breachListComp = breachlist.NewBreachListComponent(log, cfg.BreachedPasswords)
 	}
+	a.Add(breachListComp)
 
-	// Wait for each independent component to be ready (with timeout)
-	for _, comp := range independent {
-		select {
-		case <-comp.Ready():
-			log.Info().Str("component", fmt.Sprintf("%T", comp)).Msg("ready")
-		case <-time.After(10 * time.Second):
-			log.Fatal().Msgf("timeout waiting for component %T", comp)
-		}
-	}
+	serviceComp := config.NewServiceComponent(log, dbComp0, queryComp, redisComp0, userClientComp, kafkaProducerComp, breachListComp, cfg.Service)
+	a.Add(serviceComp, app.DependsOn(redisComp0, dbComp0, queryComp, userClientComp, kafkaProducerComp, breachListComp))
 
-	// Now build the service component (which depends on database, mongo, query, etc.)
-	serviceComp := config.NewServiceComponent(log, dbComp0, queryComp, redisComp0, cfg.Service)
-	appMainComp.Add(serviceComp, 10*time.Second)
+	// Idempotency keys for CreateAuthUser
+	idem := idempotency.New(idempotency.NewRedisStore(redisComp0.Client()), cfg.Idempotency)
 
-	// Now build gRPC server (depends on service)
 	grpcServerComp := grpcserver.NewGRPCServerComponent(log, cfg.GRPCServer, func(ctx context.Context, s *grpc.Server) error {
-		select {
-		case <-serviceComp.Ready():
-			authpb.RegisterAuthServiceServer(s, serviceComp.GrpcHandler())
-			return nil
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-time.After(10 * time.Second):
-			return fmt.Errorf("timeout waiting for gRPC Server")
-		}
-	})
-	appMainComp.Add(grpcServerComp, 10*time.Second)
+		authpb.RegisterAuthServiceServer(s, serviceComp.GrpcHandler())
+		return nil
+	}, idem.UnaryServerInterceptor(grpcserver.Principal))
+	grpcServerComp.SetHealthCheck(a.Serving)
+	a.Add(grpcServerComp, app.DependsOn(serviceComp))
+
+	mw := middleware.Init(log)
+	gin := http.Init(log, mw, cfg.Http)
 
-	// Build HTTP server (depends on service)
 	httpServerComp := server.NewHTTPServerComponent(log, cfg.Server, mw, gin, func(ctx context.Context, engine *server.Engine) error {
-		select {
-		case <-serviceComp.Ready():
-			restHandler.InitRestHandler(engine, serviceComp.Service(), serviceComp.GrpcHandler(), cfg.Service.AuthOpts.JwtSecret)
-			return nil
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-time.After(10 * time.Second):
-			return fmt.Errorf("timeout waiting for HTTP Server")
-		}
+		server.RegisterHealth(engine, a)
+		server.RegisterMetrics(engine)
+		restHandler.InitRestHandler(engine, serviceComp.Service(), serviceComp.GrpcHandler(), serviceComp.Keys())
+		return nil
 	})
-	appMainComp.Add(httpServerComp, 10*time.Second)
+	a.Add(httpServerComp, app.DependsOn(serviceComp))
 
-	// Run the app – now all components are added and will start in the order they were added.
-	if err := appMainComp.Run(); err != nil {
+	if err := a.Run(); err != nil {
 		log.Fatal().Err(err).Msg("app failed")
 	}
-
-	if err := indepGroup.Wait(); err != nil && err != context.Canceled {
-		log.Error().Err(err).Msg("Independent component error")
-	}
 }
@@ -4,22 +4,31 @@ import (
 	"context"
 
 	grpcHandler "github.com/linggaaskaedo/go-kill/auth-service/src/internal/handler/grpc"
+	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/provider/oauth"
 	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/repository"
 	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/service"
+	"github.com/linggaaskaedo/go-kill/common/component/breachlist"
 	"github.com/linggaaskaedo/go-kill/common/component/database"
+	"github.com/linggaaskaedo/go-kill/common/component/grpcclient"
+	"github.com/linggaaskaedo/go-kill/common/component/kafkaproducer"
 	"github.com/linggaaskaedo/go-kill/common/component/query"
 	"github.com/linggaaskaedo/go-kill/common/component/redis"
+	"github.com/linggaaskaedo/go-kill/common/pkg/token"
 
 	"github.com/rs/zerolog"
 )
 
 type ServiceComponent struct {
-	log        zerolog.Logger
-	dbComp0    *database.DatabaseComponent
-	queryComp  *query.QueryComponent
-	redisComp0 *redis.RedisComponent
-	svcOpts    service.Options
+	log               zerolog.Logger
+	dbComp0           *database.DatabaseComponent
+	queryComp         *query.QueryComponent
+	redisComp0        *redis.RedisComponent
+	userClientComp    *grpcclient.GRPCClientComponent
+	kafkaProducerComp *kafkaproducer.KafkaProducerComponent
+	breachListComp    *breachlist.BreachListComponent
+	svcOpts           service.Options
 
+	keys        *token.KeySet
 	repo        *repository.Repository
 	service     *service.Service
 	grpcHandler *grpcHandler.Grpc
@@ -31,21 +40,38 @@ func NewServiceComponent(
 	dbComp0 *database.DatabaseComponent,
 	queryComp *query.QueryComponent,
 	redisComp0 *redis.RedisComponent,
+	userClientComp *grpcclient.GRPCClientComponent,
+	kafkaProducerComp *kafkaproducer.KafkaProducerComponent,
+	breachListComp *breachlist.BreachListComponent,
 	svcOpts service.Options,
 ) *ServiceComponent {
 	return &ServiceComponent{
-		log:        log,
-		dbComp0:    dbComp0,
-		queryComp:  queryComp,
-		redisComp0: redisComp0,
-		svcOpts:    svcOpts,
-		ready:      make(chan struct{}),
+		log:               log,
+		dbComp0:           dbComp0,
+		queryComp:         queryComp,
+		redisComp0:        redisComp0,
+		userClientComp:    userClientComp,
+		kafkaProducerComp: kafkaProducerComp,
+		breachListComp:    breachListComp,
+		svcOpts:           svcOpts,
+		ready:             make(chan struct{}),
 	}
 }
 
 func (s *ServiceComponent) Start(ctx context.Context) error {
+	keys, err := token.NewKeySet(s.svcOpts.AuthOpts.SigningKeys)
+	if err != nil {
+		return err
+	}
+
+	oauthProviders, err := oauth.NewProviders(s.svcOpts.AuthOpts.OAuth)
+	if err != nil {
+		return err
+	}
+
+	s.keys = keys
 	s.repo = repository.InitRepository(s.dbComp0.Client(), s.queryComp, s.redisComp0.Client())
-	s.service = service.InitService(s.repo, s.svcOpts)
+	s.service = service.InitService(s.repo, s.userClientComp.Conn(), s.kafkaProducerComp, s.svcOpts, s.keys, oauthProviders, s.breachListComp)
 	s.grpcHandler = grpcHandler.InitGrpcHandler(s.log, s.service)
 
 	close(s.ready) // signal that service is ready
@@ -55,6 +81,7 @@ func (s *ServiceComponent) Start(ctx context.Context) error {
 	return nil
 }
 
+// Stop leaves the clients alone; the app stops them after this component.
 func (s *ServiceComponent) Stop(ctx context.Context) error {
 	s.log.Debug().Msg("Service component stopped")
 	return nil
@@ -64,6 +91,10 @@ func (s *ServiceComponent) Service() *service.Service {
 	return s.service
 }
 
+func (s *ServiceComponent) Keys() *token.KeySet {
+	return s.keys
+}
+
 func (s *ServiceComponent) GrpcHandler() *grpcHandler.Grpc {
 	return s.grpcHandler
 }
@@ -1,45 +1,45 @@
 package config
 
 import (
-	"os"
-
 	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/service"
+	"github.com/linggaaskaedo/go-kill/common/component/breachlist"
 	"github.com/linggaaskaedo/go-kill/common/component/database"
 	"github.com/linggaaskaedo/go-kill/common/component/grpcclient"
 	"github.com/linggaaskaedo/go-kill/common/component/grpcserver"
 	"github.com/linggaaskaedo/go-kill/common/component/http"
+	"github.com/linggaaskaedo/go-kill/common/component/kafkaproducer"
 	"github.com/linggaaskaedo/go-kill/common/component/query"
 	"github.com/linggaaskaedo/go-kill/common/component/redis"
 	"github.com/linggaaskaedo/go-kill/common/component/server"
+	"github.com/linggaaskaedo/go-kill/common/component/tracer"
+	commonConfig "github.com/linggaaskaedo/go-kill/common/pkg/config"
+	"github.com/linggaaskaedo/go-kill/common/pkg/idempotency"
 	"github.com/linggaaskaedo/go-kill/common/pkg/logger"
-
-	"github.com/goccy/go-yaml"
 )
 
 type Config struct {
 	Logger     logger.Config                `yaml:"logger"`
+	Tracer     tracer.Config                `yaml:"tracer"`
 	Redis      redis.Config                 `yaml:"redis"`
-	Database   map[string]database.Config   `yaml:"database"`
+	Database   map[string]database.Config   `yaml:"database" validate:"dive"`
 	Query      query.Config                 `yaml:"queries"`
-	GRPCClient map[string]grpcclient.Config `yaml:"grpc_client"`
+	GRPCClient map[string]grpcclient.Config `yaml:"grpc_client" validate:"dive"`
 	GRPCServer grpcserver.Config            `yaml:"grpc_server"`
 	Http       http.Config                  `yaml:"http"`
 	Server     server.Config                `yaml:"server"`
 
-	Service service.Options `yaml:"service"`
-}
+	KafkaProducer kafkaproducer.Config `yaml:"kafka_produce"`
 
-func Load(configPath string) (*Config, error) {
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		return nil, err
-	}
+	Idempotency idempotency.Config `yaml:"idempotency"`
+
+	BreachedPasswords breachlist.Config `yaml:"breached_passwords"`
 
-	// Expand environment variables in the YAML content
-	expandedData := os.ExpandEnv(string(data))
+	Service service.Options `yaml:"service"`
+}
 
+func Load(src commonConfig.Source) (*Config, error) {
 	var cfg Config
-	if err := yaml.Unmarshal([]byte(expandedData), &cfg); err != nil {
+	if err := commonConfig.Load(src, &cfg); err != nil {
 		return nil, err
 	}
 
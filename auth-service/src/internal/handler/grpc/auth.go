@@ -2,9 +2,14 @@ package grpc
 
 import (
 	"context"
+	"strings"
 
 	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/model/dto"
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
 	authpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/auth"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func (g *Grpc) CreateAuthUser(ctx context.Context, req *authpb.CreateAuthUserRequest) (*authpb.CreateAuthUserResponse, error) {
@@ -15,6 +20,9 @@ func (g *Grpc) CreateAuthUser(ctx context.Context, req *authpb.CreateAuthUserReq
 
 	resp, err := g.svc.Auth.CreateAuthUser(ctx, dtoReq)
 	if err != nil {
+		if x.ErrCode(err) == x.CodeHTTPUnprocessableEntity {
+			return nil, passwordStatusError(err)
+		}
 		return nil, err
 	}
 
@@ -34,6 +42,10 @@ func (g *Grpc) Login(ctx context.Context, req *authpb.LoginRequest) (*authpb.Log
 
 	resp, err := g.svc.Auth.Login(ctx, dtoReq)
 	if err != nil {
+		// Lets callers tell a lockout apart from bad credentials
+		if x.ErrCode(err) == x.CodeHTTPTooManyRequest {
+			return nil, status.Error(codes.ResourceExhausted, "too many failed login attempts")
+		}
 		return nil, err
 	}
 
@@ -42,6 +54,8 @@ func (g *Grpc) Login(ctx context.Context, req *authpb.LoginRequest) (*authpb.Log
 		AccessToken:  resp.AccessToken,
 		RefreshToken: resp.RefreshToken,
 		ExpiresIn:    resp.ExpiresIn,
+		MfaRequired:  resp.MfaRequired,
+		MfaToken:     resp.MfaToken,
 	}, nil
 }
 
@@ -56,19 +70,28 @@ func (g *Grpc) ValidateToken(ctx context.Context, req *authpb.ValidateTokenReque
 	}
 
 	return &authpb.ValidateTokenResponse{
-		Valid:  resp.Valid,
-		UserId: resp.UserId,
-		Email:  resp.Email,
+		Valid:       resp.Valid,
+		UserId:      resp.UserId,
+		Email:       resp.Email,
+		SessionId:   resp.SessionId,
+		Roles:       resp.Roles,
+		Permissions: resp.Permissions,
 	}, nil
 }
 
 func (g *Grpc) RefreshToken(ctx context.Context, req *authpb.RefreshTokenRequest) (*authpb.RefreshTokenResponse, error) {
 	dtoReq := &dto.RefreshTokenRequest{
 		RefreshToken: req.RefreshToken,
+		IpAddress:    req.IpAddress,
+		UserAgent:    req.UserAgent,
 	}
 
 	resp, err := g.svc.Auth.RefreshToken(ctx, dtoReq)
 	if err != nil {
+		// Unknown, expired, revoked and reused tokens all look the same
+		if x.ErrCode(err) == x.CodeHTTPUnauthorized {
+			return nil, status.Error(codes.Unauthenticated, "invalid refresh token")
+		}
 		return nil, err
 	}
 
@@ -96,3 +119,426 @@ func (g *Grpc) Logout(ctx context.Context, req *authpb.LogoutRequest) (*authpb.L
 		Message: resp.Message,
 	}, nil
 }
+
+func (g *Grpc) RequestEmailVerification(ctx context.Context, req *authpb.RequestEmailVerificationRequest) (*authpb.RequestEmailVerificationResponse, error) {
+	dtoReq := &dto.RequestEmailVerificationRequest{
+		Email: req.Email,
+	}
+
+	resp, err := g.svc.Auth.RequestEmailVerification(ctx, dtoReq)
+	if err != nil {
+		return nil, accountStatusError(err)
+	}
+
+	return &authpb.RequestEmailVerificationResponse{
+		Success: resp.Success,
+	}, nil
+}
+
+func (g *Grpc) VerifyEmail(ctx context.Context, req *authpb.VerifyEmailRequest) (*authpb.VerifyEmailResponse, error) {
+	dtoReq := &dto.VerifyEmailRequest{
+		Token: req.Token,
+	}
+
+	resp, err := g.svc.Auth.VerifyEmail(ctx, dtoReq)
+	if err != nil {
+		return nil, accountStatusError(err)
+	}
+
+	return &authpb.VerifyEmailResponse{
+		Success: resp.Success,
+	}, nil
+}
+
+func (g *Grpc) RequestPasswordReset(ctx context.Context, req *authpb.RequestPasswordResetRequest) (*authpb.RequestPasswordResetResponse, error) {
+	dtoReq := &dto.RequestPasswordResetRequest{
+		Email: req.Email,
+	}
+
+	resp, err := g.svc.Auth.RequestPasswordReset(ctx, dtoReq)
+	if err != nil {
+		return nil, accountStatusError(err)
+	}
+
+	return &authpb.RequestPasswordResetResponse{
+		Success: resp.Success,
+	}, nil
+}
+
+func (g *Grpc) ResetPassword(ctx context.Context, req *authpb.ResetPasswordRequest) (*authpb.ResetPasswordResponse, error) {
+	dtoReq := &dto.ResetPasswordRequest{
+		Token:       req.Token,
+		NewPassword: req.NewPassword,
+	}
+
+	resp, err := g.svc.Auth.ResetPassword(ctx, dtoReq)
+	if err != nil {
+		return nil, accountStatusError(err)
+	}
+
+	return &authpb.ResetPasswordResponse{
+		Success: resp.Success,
+	}, nil
+}
+
+// accountStatusError gives the errors callers can act on a gRPC code, so the
+// gateway answers a bad token with 400 rather than 500.
+func accountStatusError(err error) error {
+	switch x.ErrCode(err) {
+	case x.CodeHTTPBadRequest:
+		return status.Error(codes.InvalidArgument, "invalid or expired token")
+	case x.CodeHTTPServiceUnavailable:
+		return status.Error(codes.Unavailable, "email could not be sent, try again later")
+	case x.CodeHTTPUnprocessableEntity:
+		return passwordStatusError(err)
+	}
+
+	return err
+}
+
+// passwordStatusError passes the rules a rejected password broke on to the
+// caller.
+func passwordStatusError(err error) error {
+	return messageStatusError(codes.InvalidArgument, err)
+}
+
+// messageStatusError answers with err's own message, without the stack
+// trace, for errors whose message tells the caller what to fix.
+func messageStatusError(code codes.Code, err error) error {
+	msg, _, _ := strings.Cut(err.Error(), "\n ---")
+	return status.Error(code, msg)
+}
+
+func (g *Grpc) EnrollMfa(ctx context.Context, req *authpb.EnrollMfaRequest) (*authpb.EnrollMfaResponse, error) {
+	dtoReq := &dto.EnrollMfaRequest{
+		UserId: req.UserId,
+	}
+
+	resp, err := g.svc.Auth.EnrollMfa(ctx, dtoReq)
+	if err != nil {
+		return nil, mfaStatusError(err)
+	}
+
+	return &authpb.EnrollMfaResponse{
+		Secret:     resp.Secret,
+		OtpauthUri: resp.OtpauthUri,
+	}, nil
+}
+
+func (g *Grpc) ConfirmMfa(ctx context.Context, req *authpb.ConfirmMfaRequest) (*authpb.ConfirmMfaResponse, error) {
+	dtoReq := &dto.ConfirmMfaRequest{
+		UserId: req.UserId,
+		Code:   req.Code,
+	}
+
+	resp, err := g.svc.Auth.ConfirmMfa(ctx, dtoReq)
+	if err != nil {
+		return nil, mfaStatusError(err)
+	}
+
+	return &authpb.ConfirmMfaResponse{
+		Success:       resp.Success,
+		RecoveryCodes: resp.RecoveryCodes,
+	}, nil
+}
+
+func (g *Grpc) DisableMfa(ctx context.Context, req *authpb.DisableMfaRequest) (*authpb.DisableMfaResponse, error) {
+	dtoReq := &dto.DisableMfaRequest{
+		UserId: req.UserId,
+		Code:   req.Code,
+	}
+
+	resp, err := g.svc.Auth.DisableMfa(ctx, dtoReq)
+	if err != nil {
+		return nil, mfaStatusError(err)
+	}
+
+	return &authpb.DisableMfaResponse{
+		Success: resp.Success,
+	}, nil
+}
+
+func (g *Grpc) VerifyMfa(ctx context.Context, req *authpb.VerifyMfaRequest) (*authpb.VerifyMfaResponse, error) {
+	dtoReq := &dto.VerifyMfaRequest{
+		MfaToken:  req.MfaToken,
+		Code:      req.Code,
+		IpAddress: req.IpAddress,
+		UserAgent: req.UserAgent,
+	}
+
+	resp, err := g.svc.Auth.VerifyMfa(ctx, dtoReq)
+	if err != nil {
+		return nil, mfaStatusError(err)
+	}
+
+	return &authpb.VerifyMfaResponse{
+		Success:      resp.Success,
+		AccessToken:  resp.AccessToken,
+		RefreshToken: resp.RefreshToken,
+		ExpiresIn:    resp.ExpiresIn,
+	}, nil
+}
+
+func mfaStatusError(err error) error {
+	switch x.ErrCode(err) {
+	case x.CodeHTTPBadRequest:
+		return status.Error(codes.InvalidArgument, "invalid mfa code")
+	case x.CodeHTTPUnauthorized:
+		return status.Error(codes.Unauthenticated, "invalid mfa code or expired mfa token")
+	case x.CodeHTTPConflict:
+		return status.Error(codes.AlreadyExists, "mfa is already enabled")
+	case x.CodeHTTPUnprocessableEntity:
+		return status.Error(codes.FailedPrecondition, "mfa is not enabled or enrolment has not been started")
+	case x.CodeSQLRecordDoesNotExist:
+		return status.Error(codes.NotFound, "user not found")
+	}
+
+	return err
+}
+
+func (g *Grpc) ListSessions(ctx context.Context, req *authpb.ListSessionsRequest) (*authpb.ListSessionsResponse, error) {
+	dtoReq := &dto.ListSessionsRequest{
+		UserId:           req.UserId,
+		CurrentSessionId: req.CurrentSessionId,
+	}
+
+	resp, err := g.svc.Auth.ListSessions(ctx, dtoReq)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]*authpb.Session, len(resp.Sessions))
+	for i, session := range resp.Sessions {
+		sessions[i] = &authpb.Session{
+			SessionId:  session.SessionId,
+			IpAddress:  session.IpAddress,
+			UserAgent:  session.UserAgent,
+			CreatedAt:  session.CreatedAt.Unix(),
+			LastSeenAt: session.LastSeenAt.Unix(),
+			Current:    session.Current,
+		}
+	}
+
+	return &authpb.ListSessionsResponse{
+		Sessions: sessions,
+	}, nil
+}
+
+func (g *Grpc) RevokeSession(ctx context.Context, req *authpb.RevokeSessionRequest) (*authpb.RevokeSessionResponse, error) {
+	dtoReq := &dto.RevokeSessionRequest{
+		UserId:    req.UserId,
+		SessionId: req.SessionId,
+	}
+
+	resp, err := g.svc.Auth.RevokeSession(ctx, dtoReq)
+	if err != nil {
+		if x.ErrCode(err) == x.CodeHTTPNotFound {
+			return nil, status.Error(codes.NotFound, "session not found")
+		}
+		return nil, err
+	}
+
+	return &authpb.RevokeSessionResponse{
+		Success: resp.Success,
+	}, nil
+}
+
+func (g *Grpc) LogoutAll(ctx context.Context, req *authpb.LogoutAllRequest) (*authpb.LogoutAllResponse, error) {
+	dtoReq := &dto.LogoutAllRequest{
+		UserId: req.UserId,
+	}
+
+	resp, err := g.svc.Auth.LogoutAll(ctx, dtoReq)
+	if err != nil {
+		return nil, err
+	}
+
+	return &authpb.LogoutAllResponse{
+		Success: resp.Success,
+		Revoked: resp.Revoked,
+	}, nil
+}
+
+func (g *Grpc) StartOAuthLogin(ctx context.Context, req *authpb.StartOAuthLoginRequest) (*authpb.StartOAuthLoginResponse, error) {
+	dtoReq := &dto.StartOAuthLoginRequest{
+		Provider: req.Provider,
+	}
+
+	resp, err := g.svc.Auth.StartOAuthLogin(ctx, dtoReq)
+	if err != nil {
+		return nil, oauthStatusError(err)
+	}
+
+	return &authpb.StartOAuthLoginResponse{
+		AuthorizationUrl: resp.AuthorizationUrl,
+		State:            resp.State,
+	}, nil
+}
+
+func (g *Grpc) CompleteOAuthLogin(ctx context.Context, req *authpb.CompleteOAuthLoginRequest) (*authpb.LoginResponse, error) {
+	dtoReq := &dto.CompleteOAuthLoginRequest{
+		Provider:  req.Provider,
+		Code:      req.Code,
+		State:     req.State,
+		IpAddress: req.IpAddress,
+		UserAgent: req.UserAgent,
+	}
+
+	resp, err := g.svc.Auth.CompleteOAuthLogin(ctx, dtoReq)
+	if err != nil {
+		return nil, oauthStatusError(err)
+	}
+
+	return &authpb.LoginResponse{
+		Success:      resp.Success,
+		AccessToken:  resp.AccessToken,
+		RefreshToken: resp.RefreshToken,
+		ExpiresIn:    resp.ExpiresIn,
+		MfaRequired:  resp.MfaRequired,
+		MfaToken:     resp.MfaToken,
+	}, nil
+}
+
+func oauthStatusError(err error) error {
+	switch x.ErrCode(err) {
+	case x.CodeHTTPNotFound:
+		return status.Error(codes.NotFound, "unknown oauth provider")
+	case x.CodeHTTPUnauthorized:
+		return status.Error(codes.Unauthenticated, "oauth sign in failed or state expired")
+	case x.CodeHTTPForbidden:
+		return status.Error(codes.PermissionDenied, "oauth provider did not return a verified email address")
+	case x.CodeHTTPConflict:
+		return status.Error(codes.AlreadyExists, "oauth account is linked to another user")
+	case x.CodeHTTPTooManyRequest:
+		return status.Error(codes.ResourceExhausted, "account is locked")
+	case x.CodeHTTPServiceUnavailable:
+		return status.Error(codes.Unavailable, "oauth provider is unavailable")
+	}
+
+	return err
+}
+
+func (g *Grpc) ValidateApiKey(ctx context.Context, req *authpb.ValidateApiKeyRequest) (*authpb.ValidateTokenResponse, error) {
+	dtoReq := &dto.ValidateApiKeyRequest{
+		ApiKey: req.ApiKey,
+	}
+
+	resp, err := g.svc.Auth.ValidateApiKey(ctx, dtoReq)
+	if err != nil {
+		return nil, apiKeyStatusError(err)
+	}
+
+	return &authpb.ValidateTokenResponse{
+		Valid:       resp.Valid,
+		UserId:      resp.UserId,
+		Email:       resp.Email,
+		Roles:       resp.Roles,
+		Permissions: resp.Permissions,
+		ApiKeyId:    resp.ApiKeyId,
+	}, nil
+}
+
+func (g *Grpc) CreateApiKey(ctx context.Context, req *authpb.CreateApiKeyRequest) (*authpb.CreateApiKeyResponse, error) {
+	dtoReq := &dto.CreateApiKeyRequest{
+		UserId:    req.UserId,
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		ExpiresIn: req.ExpiresIn,
+	}
+
+	resp, err := g.svc.Auth.CreateApiKey(ctx, dtoReq)
+	if err != nil {
+		return nil, apiKeyStatusError(err)
+	}
+
+	return &authpb.CreateApiKeyResponse{
+		ApiKey: apiKeyToPb(resp.ApiKey),
+		Key:    resp.Key,
+	}, nil
+}
+
+func (g *Grpc) ListApiKeys(ctx context.Context, req *authpb.ListApiKeysRequest) (*authpb.ListApiKeysResponse, error) {
+	dtoReq := &dto.ListApiKeysRequest{
+		UserId: req.UserId,
+	}
+
+	resp, err := g.svc.Auth.ListApiKeys(ctx, dtoReq)
+	if err != nil {
+		return nil, err
+	}
+
+	apiKeys := make([]*authpb.ApiKey, len(resp.ApiKeys))
+	for i, apiKey := range resp.ApiKeys {
+		apiKeys[i] = apiKeyToPb(apiKey)
+	}
+
+	return &authpb.ListApiKeysResponse{
+		ApiKeys: apiKeys,
+	}, nil
+}
+
+func (g *Grpc) RotateApiKey(ctx context.Context, req *authpb.RotateApiKeyRequest) (*authpb.CreateApiKeyResponse, error) {
+	dtoReq := &dto.RotateApiKeyRequest{
+		UserId: req.UserId,
+		KeyId:  req.KeyId,
+	}
+
+	resp, err := g.svc.Auth.RotateApiKey(ctx, dtoReq)
+	if err != nil {
+		return nil, apiKeyStatusError(err)
+	}
+
+	return &authpb.CreateApiKeyResponse{
+		ApiKey: apiKeyToPb(resp.ApiKey),
+		Key:    resp.Key,
+	}, nil
+}
+
+func (g *Grpc) RevokeApiKey(ctx context.Context, req *authpb.RevokeApiKeyRequest) (*authpb.RevokeApiKeyResponse, error) {
+	dtoReq := &dto.RevokeApiKeyRequest{
+		UserId: req.UserId,
+		KeyId:  req.KeyId,
+	}
+
+	resp, err := g.svc.Auth.RevokeApiKey(ctx, dtoReq)
+	if err != nil {
+		return nil, apiKeyStatusError(err)
+	}
+
+	return &authpb.RevokeApiKeyResponse{
+		Success: resp.Success,
+	}, nil
+}
+
+func apiKeyToPb(apiKey dto.ApiKeyResponse) *authpb.ApiKey {
+	pb := &authpb.ApiKey{
+		KeyId:     apiKey.KeyId,
+		Name:      apiKey.Name,
+		Prefix:    apiKey.Prefix,
+		Scopes:    apiKey.Scopes,
+		CreatedAt: apiKey.CreatedAt.Unix(),
+	}
+	if apiKey.ExpiresAt != nil {
+		pb.ExpiresAt = apiKey.ExpiresAt.Unix()
+	}
+	if apiKey.LastUsedAt != nil {
+		pb.LastUsedAt = apiKey.LastUsedAt.Unix()
+	}
+
+	return pb
+}
+
+func apiKeyStatusError(err error) error {
+	switch x.ErrCode(err) {
+	case x.CodeHTTPUnauthorized:
+		return status.Error(codes.Unauthenticated, "invalid api key")
+	case x.CodeHTTPNotFound:
+		return status.Error(codes.NotFound, "api key not found")
+	case x.CodeHTTPForbidden:
+		return messageStatusError(codes.PermissionDenied, err)
+	case x.CodeHTTPBadRequest, x.CodeHTTPUnprocessableEntity:
+		return messageStatusError(codes.InvalidArgument, err)
+	}
+
+	return err
+}
@@ -4,15 +4,19 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/model/dto"
 	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/service"
 	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/service/auth"
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
 	authpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/auth"
 
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 const (
@@ -73,6 +77,150 @@ func (m *MockAuthService) Logout(ctx context.Context, req *dto.LogoutRequest) (*
 	return args.Get(0).(*dto.LogoutResponse), args.Error(1)
 }
 
+func (m *MockAuthService) RequestEmailVerification(ctx context.Context, req *dto.RequestEmailVerificationRequest) (*dto.RequestEmailVerificationResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.RequestEmailVerificationResponse), args.Error(1)
+}
+
+func (m *MockAuthService) VerifyEmail(ctx context.Context, req *dto.VerifyEmailRequest) (*dto.VerifyEmailResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.VerifyEmailResponse), args.Error(1)
+}
+
+func (m *MockAuthService) RequestPasswordReset(ctx context.Context, req *dto.RequestPasswordResetRequest) (*dto.RequestPasswordResetResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.RequestPasswordResetResponse), args.Error(1)
+}
+
+func (m *MockAuthService) ResetPassword(ctx context.Context, req *dto.ResetPasswordRequest) (*dto.ResetPasswordResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ResetPasswordResponse), args.Error(1)
+}
+
+func (m *MockAuthService) EnrollMfa(ctx context.Context, req *dto.EnrollMfaRequest) (*dto.EnrollMfaResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.EnrollMfaResponse), args.Error(1)
+}
+
+func (m *MockAuthService) ConfirmMfa(ctx context.Context, req *dto.ConfirmMfaRequest) (*dto.ConfirmMfaResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ConfirmMfaResponse), args.Error(1)
+}
+
+func (m *MockAuthService) DisableMfa(ctx context.Context, req *dto.DisableMfaRequest) (*dto.DisableMfaResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.DisableMfaResponse), args.Error(1)
+}
+
+func (m *MockAuthService) VerifyMfa(ctx context.Context, req *dto.VerifyMfaRequest) (*dto.VerifyMfaResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.VerifyMfaResponse), args.Error(1)
+}
+
+func (m *MockAuthService) ListSessions(ctx context.Context, req *dto.ListSessionsRequest) (*dto.ListSessionsResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ListSessionsResponse), args.Error(1)
+}
+
+func (m *MockAuthService) RevokeSession(ctx context.Context, req *dto.RevokeSessionRequest) (*dto.RevokeSessionResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.RevokeSessionResponse), args.Error(1)
+}
+
+func (m *MockAuthService) LogoutAll(ctx context.Context, req *dto.LogoutAllRequest) (*dto.LogoutAllResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.LogoutAllResponse), args.Error(1)
+}
+
+func (m *MockAuthService) StartOAuthLogin(ctx context.Context, req *dto.StartOAuthLoginRequest) (*dto.StartOAuthLoginResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.StartOAuthLoginResponse), args.Error(1)
+}
+
+func (m *MockAuthService) CompleteOAuthLogin(ctx context.Context, req *dto.CompleteOAuthLoginRequest) (*dto.LoginResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.LoginResponse), args.Error(1)
+}
+
+func (m *MockAuthService) ValidateApiKey(ctx context.Context, req *dto.ValidateApiKeyRequest) (*dto.ValidateTokenResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ValidateTokenResponse), args.Error(1)
+}
+
+func (m *MockAuthService) CreateApiKey(ctx context.Context, req *dto.CreateApiKeyRequest) (*dto.CreateApiKeyResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.CreateApiKeyResponse), args.Error(1)
+}
+
+func (m *MockAuthService) ListApiKeys(ctx context.Context, req *dto.ListApiKeysRequest) (*dto.ListApiKeysResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ListApiKeysResponse), args.Error(1)
+}
+
+func (m *MockAuthService) RotateApiKey(ctx context.Context, req *dto.RotateApiKeyRequest) (*dto.CreateApiKeyResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.CreateApiKeyResponse), args.Error(1)
+}
+
+func (m *MockAuthService) RevokeApiKey(ctx context.Context, req *dto.RevokeApiKeyRequest) (*dto.RevokeApiKeyResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.RevokeApiKeyResponse), args.Error(1)
+}
+
 func setupTestGrpc(mockAuth *MockAuthService) (*Grpc, *service.Service) {
 	mockSvc := &service.Service{}
 	mockSvc.Auth = mockAuth
@@ -189,6 +337,149 @@ func TestLoginError(t *testing.T) {
 	mockAuth.AssertExpectations(t)
 }
 
+func TestLoginLockedOut(t *testing.T) {
+	mockAuth := new(MockAuthService)
+	grpcHandler, _ := setupTestGrpc(mockAuth)
+	ctx := context.Background()
+
+	lockedErr := x.NewWithCode(x.CodeHTTPTooManyRequest, "Account is locked")
+	mockAuth.On("Login", ctx, mock.AnythingOfType("*dto.LoginRequest")).Return(nil, lockedErr)
+
+	req := &authpb.LoginRequest{
+		Email:    testEmail,
+		Password: "wrongpassword",
+	}
+
+	resp, err := grpcHandler.Login(ctx, req)
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	mockAuth.AssertExpectations(t)
+}
+
+func TestRequestPasswordResetSuccess(t *testing.T) {
+	mockAuth := new(MockAuthService)
+	grpcHandler, _ := setupTestGrpc(mockAuth)
+	ctx := context.Background()
+
+	mockAuth.On("RequestPasswordReset", ctx, mock.MatchedBy(func(req *dto.RequestPasswordResetRequest) bool {
+		return req.Email == testEmail
+	})).Return(&dto.RequestPasswordResetResponse{Success: true}, nil)
+
+	resp, err := grpcHandler.RequestPasswordReset(ctx, &authpb.RequestPasswordResetRequest{Email: testEmail})
+
+	assert.NoError(t, err)
+	assert.True(t, resp.Success)
+	mockAuth.AssertExpectations(t)
+}
+
+func TestResetPasswordInvalidToken(t *testing.T) {
+	mockAuth := new(MockAuthService)
+	grpcHandler, _ := setupTestGrpc(mockAuth)
+	ctx := context.Background()
+
+	invalidErr := x.NewWithCode(x.CodeHTTPBadRequest, "Invalid or expired token")
+	mockAuth.On("ResetPassword", ctx, mock.MatchedBy(func(req *dto.ResetPasswordRequest) bool {
+		return req.Token == "used-token" && req.NewPassword == testPassword
+	})).Return(nil, invalidErr)
+
+	resp, err := grpcHandler.ResetPassword(ctx, &authpb.ResetPasswordRequest{Token: "used-token", NewPassword: testPassword})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	mockAuth.AssertExpectations(t)
+}
+
+func TestVerifyEmailSuccess(t *testing.T) {
+	mockAuth := new(MockAuthService)
+	grpcHandler, _ := setupTestGrpc(mockAuth)
+	ctx := context.Background()
+
+	mockAuth.On("VerifyEmail", ctx, mock.MatchedBy(func(req *dto.VerifyEmailRequest) bool {
+		return req.Token == "verify-token"
+	})).Return(&dto.VerifyEmailResponse{Success: true}, nil)
+
+	resp, err := grpcHandler.VerifyEmail(ctx, &authpb.VerifyEmailRequest{Token: "verify-token"})
+
+	assert.NoError(t, err)
+	assert.True(t, resp.Success)
+	mockAuth.AssertExpectations(t)
+}
+
+func TestLoginMfaRequired(t *testing.T) {
+	mockAuth := new(MockAuthService)
+	grpcHandler, _ := setupTestGrpc(mockAuth)
+	ctx := context.Background()
+
+	mockAuth.On("Login", ctx, mock.AnythingOfType("*dto.LoginRequest")).Return(&dto.LoginResponse{
+		Success:     true,
+		MfaRequired: true,
+		MfaToken:    "mfa-token-123",
+	}, nil)
+
+	resp, err := grpcHandler.Login(ctx, &authpb.LoginRequest{Email: testEmail, Password: testPassword})
+
+	assert.NoError(t, err)
+	assert.True(t, resp.MfaRequired)
+	assert.Equal(t, "mfa-token-123", resp.MfaToken)
+	assert.Empty(t, resp.AccessToken)
+	mockAuth.AssertExpectations(t)
+}
+
+func TestVerifyMfaSuccess(t *testing.T) {
+	mockAuth := new(MockAuthService)
+	grpcHandler, _ := setupTestGrpc(mockAuth)
+	ctx := context.Background()
+
+	mockAuth.On("VerifyMfa", ctx, mock.MatchedBy(func(req *dto.VerifyMfaRequest) bool {
+		return req.MfaToken == "mfa-token-123" && req.Code == "123456"
+	})).Return(&dto.VerifyMfaResponse{
+		Success:      true,
+		AccessToken:  testAccessToken,
+		RefreshToken: testRefreshToken,
+		ExpiresIn:    3600,
+	}, nil)
+
+	resp, err := grpcHandler.VerifyMfa(ctx, &authpb.VerifyMfaRequest{MfaToken: "mfa-token-123", Code: "123456"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, testAccessToken, resp.AccessToken)
+	assert.Equal(t, testRefreshToken, resp.RefreshToken)
+	mockAuth.AssertExpectations(t)
+}
+
+func TestVerifyMfaInvalidCode(t *testing.T) {
+	mockAuth := new(MockAuthService)
+	grpcHandler, _ := setupTestGrpc(mockAuth)
+	ctx := context.Background()
+
+	mockAuth.On("VerifyMfa", ctx, mock.AnythingOfType("*dto.VerifyMfaRequest")).
+		Return(nil, x.NewWithCode(x.CodeHTTPUnauthorized, "Invalid MFA code"))
+
+	resp, err := grpcHandler.VerifyMfa(ctx, &authpb.VerifyMfaRequest{MfaToken: "mfa-token-123", Code: "000000"})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	mockAuth.AssertExpectations(t)
+}
+
+func TestConfirmMfaReturnsRecoveryCodes(t *testing.T) {
+	mockAuth := new(MockAuthService)
+	grpcHandler, _ := setupTestGrpc(mockAuth)
+	ctx := context.Background()
+
+	recoveryCodes := []string{"ABCDE-FGHIJ", "KLMNO-PQRST"}
+	mockAuth.On("ConfirmMfa", ctx, mock.MatchedBy(func(req *dto.ConfirmMfaRequest) bool {
+		return req.UserId == testUserID && req.Code == "123456"
+	})).Return(&dto.ConfirmMfaResponse{Success: true, RecoveryCodes: recoveryCodes}, nil)
+
+	resp, err := grpcHandler.ConfirmMfa(ctx, &authpb.ConfirmMfaRequest{UserId: testUserID, Code: "123456"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, recoveryCodes, resp.RecoveryCodes)
+	mockAuth.AssertExpectations(t)
+}
+
 func TestValidateTokenSuccess(t *testing.T) {
 	mockAuth := new(MockAuthService)
 	grpcHandler, _ := setupTestGrpc(mockAuth)
@@ -289,6 +580,25 @@ func TestRefreshTokenError(t *testing.T) {
 	mockAuth.AssertExpectations(t)
 }
 
+func TestRefreshTokenReused(t *testing.T) {
+	mockAuth := new(MockAuthService)
+	grpcHandler, _ := setupTestGrpc(mockAuth)
+	ctx := context.Background()
+
+	reusedErr := x.NewWithCode(x.CodeHTTPUnauthorized, "Invalid refresh token")
+	mockAuth.On("RefreshToken", ctx, mock.AnythingOfType("*dto.RefreshTokenRequest")).Return(nil, reusedErr)
+
+	req := &authpb.RefreshTokenRequest{
+		RefreshToken: "rotated-refresh-token",
+	}
+
+	resp, err := grpcHandler.RefreshToken(ctx, req)
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	mockAuth.AssertExpectations(t)
+}
+
 func TestLogoutSuccess(t *testing.T) {
 	mockAuth := new(MockAuthService)
 	grpcHandler, _ := setupTestGrpc(mockAuth)
@@ -337,3 +647,150 @@ func TestLogoutError(t *testing.T) {
 	assert.Equal(t, expectedErr, err)
 	mockAuth.AssertExpectations(t)
 }
+
+func TestListSessionsSuccess(t *testing.T) {
+	mockAuth := new(MockAuthService)
+	grpcHandler, _ := setupTestGrpc(mockAuth)
+	ctx := context.Background()
+
+	lastSeen := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	expectedResp := &dto.ListSessionsResponse{
+		Sessions: []dto.SessionResponse{
+			{SessionId: "session-1", IpAddress: testIPAddress, UserAgent: testUserAgent, CreatedAt: lastSeen.Add(-time.Hour), LastSeenAt: lastSeen, Current: true},
+			{SessionId: "session-2", CreatedAt: lastSeen.Add(-2 * time.Hour), LastSeenAt: lastSeen.Add(-time.Hour)},
+		},
+	}
+
+	mockAuth.On("ListSessions", ctx, mock.MatchedBy(func(req *dto.ListSessionsRequest) bool {
+		return req.UserId == testUserID && req.CurrentSessionId == "session-1"
+	})).Return(expectedResp, nil)
+
+	resp, err := grpcHandler.ListSessions(ctx, &authpb.ListSessionsRequest{UserId: testUserID, CurrentSessionId: "session-1"})
+
+	assert.NoError(t, err)
+	assert.Len(t, resp.Sessions, 2)
+	assert.Equal(t, "session-1", resp.Sessions[0].SessionId)
+	assert.Equal(t, testUserAgent, resp.Sessions[0].UserAgent)
+	assert.Equal(t, lastSeen.Unix(), resp.Sessions[0].LastSeenAt)
+	assert.True(t, resp.Sessions[0].Current)
+	assert.False(t, resp.Sessions[1].Current)
+	mockAuth.AssertExpectations(t)
+}
+
+func TestRevokeSessionNotFound(t *testing.T) {
+	mockAuth := new(MockAuthService)
+	grpcHandler, _ := setupTestGrpc(mockAuth)
+	ctx := context.Background()
+
+	notFoundErr := x.NewWithCode(x.CodeHTTPNotFound, "Session not found")
+	mockAuth.On("RevokeSession", ctx, mock.AnythingOfType("*dto.RevokeSessionRequest")).Return(nil, notFoundErr)
+
+	resp, err := grpcHandler.RevokeSession(ctx, &authpb.RevokeSessionRequest{UserId: testUserID, SessionId: "someone-elses"})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	mockAuth.AssertExpectations(t)
+}
+
+func TestLogoutAllSuccess(t *testing.T) {
+	mockAuth := new(MockAuthService)
+	grpcHandler, _ := setupTestGrpc(mockAuth)
+	ctx := context.Background()
+
+	mockAuth.On("LogoutAll", ctx, mock.MatchedBy(func(req *dto.LogoutAllRequest) bool {
+		return req.UserId == testUserID
+	})).Return(&dto.LogoutAllResponse{Success: true, Revoked: 3}, nil)
+
+	resp, err := grpcHandler.LogoutAll(ctx, &authpb.LogoutAllRequest{UserId: testUserID})
+
+	assert.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.Equal(t, int64(3), resp.Revoked)
+	mockAuth.AssertExpectations(t)
+}
+
+func TestCompleteOAuthLoginSuccess(t *testing.T) {
+	mockAuth := new(MockAuthService)
+	grpcHandler, _ := setupTestGrpc(mockAuth)
+	ctx := context.Background()
+
+	mockAuth.On("CompleteOAuthLogin", ctx, mock.MatchedBy(func(req *dto.CompleteOAuthLoginRequest) bool {
+		return req.Provider == "google" && req.Code == "code-123" && req.State == "state-123"
+	})).Return(&dto.LoginResponse{Success: true, AccessToken: "access", RefreshToken: "refresh", ExpiresIn: 900}, nil)
+
+	resp, err := grpcHandler.CompleteOAuthLogin(ctx, &authpb.CompleteOAuthLoginRequest{Provider: "google", Code: "code-123", State: "state-123"})
+
+	assert.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.Equal(t, "access", resp.AccessToken)
+	mockAuth.AssertExpectations(t)
+}
+
+func TestCompleteOAuthLoginUnverifiedEmail(t *testing.T) {
+	mockAuth := new(MockAuthService)
+	grpcHandler, _ := setupTestGrpc(mockAuth)
+	ctx := context.Background()
+
+	forbiddenErr := x.NewWithCode(x.CodeHTTPForbidden, "OAuth provider did not return a verified email address")
+	mockAuth.On("CompleteOAuthLogin", ctx, mock.AnythingOfType("*dto.CompleteOAuthLoginRequest")).Return(nil, forbiddenErr)
+
+	resp, err := grpcHandler.CompleteOAuthLogin(ctx, &authpb.CompleteOAuthLoginRequest{Provider: "github", Code: "code", State: "state"})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	mockAuth.AssertExpectations(t)
+}
+
+func TestValidateApiKeySuccess(t *testing.T) {
+	mockAuth := new(MockAuthService)
+	grpcHandler, _ := setupTestGrpc(mockAuth)
+	ctx := context.Background()
+
+	mockAuth.On("ValidateApiKey", ctx, &dto.ValidateApiKeyRequest{ApiKey: "gk_secret"}).Return(&dto.ValidateTokenResponse{
+		Valid:       true,
+		UserId:      "user-123",
+		Email:       "test@example.com",
+		Roles:       []string{},
+		Permissions: []string{"product:write"},
+		ApiKeyId:    "key-123",
+	}, nil)
+
+	resp, err := grpcHandler.ValidateApiKey(ctx, &authpb.ValidateApiKeyRequest{ApiKey: "gk_secret"})
+
+	assert.NoError(t, err)
+	assert.True(t, resp.Valid)
+	assert.Equal(t, "key-123", resp.ApiKeyId)
+	assert.Equal(t, []string{"product:write"}, resp.Permissions)
+	mockAuth.AssertExpectations(t)
+}
+
+func TestValidateApiKeyInvalid(t *testing.T) {
+	mockAuth := new(MockAuthService)
+	grpcHandler, _ := setupTestGrpc(mockAuth)
+	ctx := context.Background()
+
+	invalidErr := x.NewWithCode(x.CodeHTTPUnauthorized, "Invalid API key")
+	mockAuth.On("ValidateApiKey", ctx, mock.AnythingOfType("*dto.ValidateApiKeyRequest")).Return(nil, invalidErr)
+
+	resp, err := grpcHandler.ValidateApiKey(ctx, &authpb.ValidateApiKeyRequest{ApiKey: "gk_revoked"})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	mockAuth.AssertExpectations(t)
+}
+
+func TestCreateApiKeyScopesDenied(t *testing.T) {
+	mockAuth := new(MockAuthService)
+	grpcHandler, _ := setupTestGrpc(mockAuth)
+	ctx := context.Background()
+
+	deniedErr := x.NewWithCode(x.CodeHTTPForbidden, "API key scopes exceed your permissions: product:write")
+	mockAuth.On("CreateApiKey", ctx, mock.AnythingOfType("*dto.CreateApiKeyRequest")).Return(nil, deniedErr)
+
+	resp, err := grpcHandler.CreateApiKey(ctx, &authpb.CreateApiKeyRequest{UserId: "user-123", Name: "batch", Scopes: []string{"product:write"}})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "product:write")
+	mockAuth.AssertExpectations(t)
+}
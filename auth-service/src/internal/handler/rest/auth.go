@@ -24,7 +24,7 @@ func (e *rest) handleLogin(c *gin.Context) {
 	loginReq := &dto.LoginRequest{
 		Email:     req.Email,
 		Password:  req.Password,
-		IpAddress: c.Request.Host,
+		IpAddress: c.ClientIP(),
 		UserAgent: c.Request.UserAgent(),
 	}
 
@@ -47,6 +47,9 @@ func (e *rest) handleRefresh(c *gin.Context) {
 		return
 	}
 
+	req.IpAddress = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+
 	resp, err := e.svc.Auth.RefreshToken(ctx, &req)
 	if err != nil {
 		e.httpRespError(c, err)
@@ -67,7 +70,7 @@ func (e *rest) handleLogout(c *gin.Context) {
 
 	token := authHeader[7:]
 
-	parsedToken, err := jwt.Parse(token, func(t *jwt.Token) (interface{}, error) { return e.jwtSecret, nil })
+	parsedToken, err := e.keys.Parse(token)
 	if err != nil || !parsedToken.Valid {
 		e.httpRespError(c, x.NewWithCode(x.CodeHTTPUnauthorized, "invalid_token"))
 		return
@@ -101,10 +104,309 @@ func (e *rest) handleLogout(c *gin.Context) {
 	e.httpRespSuccess(c, http.StatusOK, resp, nil)
 }
 
-func (e *rest) handleHealth(c *gin.Context) {
-	resp := map[string]string{
-		"status":  "healthy",
-		"service": "auth-service",
+func (e *rest) handleRequestEmailVerification(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req dto.RequestEmailVerificationRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("invalid_request_body")
+		e.httpRespError(c, x.WrapWithCode(err, x.CodeHTTPUnmarshal, "invalid_request_body"))
+		return
+	}
+
+	resp, err := e.svc.Auth.RequestEmailVerification(ctx, &req)
+	if err != nil {
+		e.httpRespError(c, err)
+		return
+	}
+
+	e.httpRespSuccess(c, http.StatusAccepted, resp, nil)
+}
+
+func (e *rest) handleVerifyEmail(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req dto.VerifyEmailRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("invalid_request_body")
+		e.httpRespError(c, x.WrapWithCode(err, x.CodeHTTPUnmarshal, "invalid_request_body"))
+		return
+	}
+
+	resp, err := e.svc.Auth.VerifyEmail(ctx, &req)
+	if err != nil {
+		e.httpRespError(c, err)
+		return
+	}
+
+	e.httpRespSuccess(c, http.StatusOK, resp, nil)
+}
+
+func (e *rest) handleForgotPassword(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req dto.RequestPasswordResetRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("invalid_request_body")
+		e.httpRespError(c, x.WrapWithCode(err, x.CodeHTTPUnmarshal, "invalid_request_body"))
+		return
+	}
+
+	resp, err := e.svc.Auth.RequestPasswordReset(ctx, &req)
+	if err != nil {
+		e.httpRespError(c, err)
+		return
+	}
+
+	e.httpRespSuccess(c, http.StatusAccepted, resp, nil)
+}
+
+func (e *rest) handleResetPassword(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req dto.ResetPasswordRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("invalid_request_body")
+		e.httpRespError(c, x.WrapWithCode(err, x.CodeHTTPUnmarshal, "invalid_request_body"))
+		return
+	}
+
+	resp, err := e.svc.Auth.ResetPassword(ctx, &req)
+	if err != nil {
+		e.httpRespError(c, err)
+		return
+	}
+
+	e.httpRespSuccess(c, http.StatusOK, resp, nil)
+}
+
+func (e *rest) handleEnrollMfa(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	resp, err := e.svc.Auth.EnrollMfa(ctx, &dto.EnrollMfaRequest{UserId: c.GetString("user_auth_id")})
+	if err != nil {
+		e.httpRespError(c, err)
+		return
+	}
+
+	e.httpRespSuccess(c, http.StatusOK, resp, nil)
+}
+
+func (e *rest) handleConfirmMfa(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req dto.ConfirmMfaRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("invalid_request_body")
+		e.httpRespError(c, x.WrapWithCode(err, x.CodeHTTPUnmarshal, "invalid_request_body"))
+		return
+	}
+
+	req.UserId = c.GetString("user_auth_id")
+
+	resp, err := e.svc.Auth.ConfirmMfa(ctx, &req)
+	if err != nil {
+		e.httpRespError(c, err)
+		return
+	}
+
+	e.httpRespSuccess(c, http.StatusOK, resp, nil)
+}
+
+func (e *rest) handleDisableMfa(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req dto.DisableMfaRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("invalid_request_body")
+		e.httpRespError(c, x.WrapWithCode(err, x.CodeHTTPUnmarshal, "invalid_request_body"))
+		return
+	}
+
+	req.UserId = c.GetString("user_auth_id")
+
+	resp, err := e.svc.Auth.DisableMfa(ctx, &req)
+	if err != nil {
+		e.httpRespError(c, err)
+		return
+	}
+
+	e.httpRespSuccess(c, http.StatusOK, resp, nil)
+}
+
+func (e *rest) handleVerifyMfa(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req dto.VerifyMfaRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("invalid_request_body")
+		e.httpRespError(c, x.WrapWithCode(err, x.CodeHTTPUnmarshal, "invalid_request_body"))
+		return
+	}
+
+	req.IpAddress = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+
+	resp, err := e.svc.Auth.VerifyMfa(ctx, &req)
+	if err != nil {
+		e.httpRespError(c, err)
+		return
+	}
+
+	e.httpRespSuccess(c, http.StatusOK, resp, nil)
+}
+
+func (e *rest) handleJWKS(c *gin.Context) {
+	// Served as a bare JWK Set (RFC 7517) so standard clients can consume it.
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, e.keys.JWKS())
+}
+
+func (e *rest) handleListSessions(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	resp, err := e.svc.Auth.ListSessions(ctx, &dto.ListSessionsRequest{
+		UserId:           c.GetString("user_auth_id"),
+		CurrentSessionId: c.GetString("user_session_id"),
+	})
+	if err != nil {
+		e.httpRespError(c, err)
+		return
+	}
+
+	e.httpRespSuccess(c, http.StatusOK, resp, nil)
+}
+
+func (e *rest) handleRevokeSession(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	resp, err := e.svc.Auth.RevokeSession(ctx, &dto.RevokeSessionRequest{
+		UserId:    c.GetString("user_auth_id"),
+		SessionId: c.Param("session_id"),
+	})
+	if err != nil {
+		e.httpRespError(c, err)
+		return
+	}
+
+	e.httpRespSuccess(c, http.StatusOK, resp, nil)
+}
+
+func (e *rest) handleLogoutAll(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	resp, err := e.svc.Auth.LogoutAll(ctx, &dto.LogoutAllRequest{UserId: c.GetString("user_auth_id")})
+	if err != nil {
+		e.httpRespError(c, err)
+		return
+	}
+
+	e.httpRespSuccess(c, http.StatusOK, resp, nil)
+}
+
+func (e *rest) handleStartOAuthLogin(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	resp, err := e.svc.Auth.StartOAuthLogin(ctx, &dto.StartOAuthLoginRequest{Provider: c.Param("provider")})
+	if err != nil {
+		e.httpRespError(c, err)
+		return
+	}
+
+	e.httpRespSuccess(c, http.StatusOK, resp, nil)
+}
+
+// handleOAuthCallback is the redirect URL registered with the providers. A
+// provider reports a denied or failed sign in with an error parameter
+// instead of a code.
+func (e *rest) handleOAuthCallback(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	if providerErr := c.Query("error"); providerErr != "" {
+		zerolog.Ctx(ctx).Warn().Str("error", providerErr).Str("description", c.Query("error_description")).Msg("oauth_provider_error")
+		e.httpRespError(c, x.NewWithCode(x.CodeHTTPUnauthorized, "oauth_sign_in_denied"))
+		return
+	}
+
+	code, state := c.Query("code"), c.Query("state")
+	if code == "" || state == "" {
+		e.httpRespError(c, x.NewWithCode(x.CodeHTTPBadRequest, "missing_code_or_state"))
+		return
+	}
+
+	resp, err := e.svc.Auth.CompleteOAuthLogin(ctx, &dto.CompleteOAuthLoginRequest{
+		Provider:  c.Param("provider"),
+		Code:      code,
+		State:     state,
+		IpAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+	if err != nil {
+		e.httpRespError(c, err)
+		return
+	}
+
+	e.httpRespSuccess(c, http.StatusOK, resp, nil)
+}
+
+func (e *rest) handleCreateApiKey(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req dto.CreateApiKeyRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("invalid_request_body")
+		e.httpRespError(c, x.WrapWithCode(err, x.CodeHTTPUnmarshal, "invalid_request_body"))
+		return
+	}
+
+	req.UserId = c.GetString("user_auth_id")
+
+	resp, err := e.svc.Auth.CreateApiKey(ctx, &req)
+	if err != nil {
+		e.httpRespError(c, err)
+		return
+	}
+
+	e.httpRespSuccess(c, http.StatusCreated, resp, nil)
+}
+
+func (e *rest) handleListApiKeys(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	resp, err := e.svc.Auth.ListApiKeys(ctx, &dto.ListApiKeysRequest{UserId: c.GetString("user_auth_id")})
+	if err != nil {
+		e.httpRespError(c, err)
+		return
+	}
+
+	e.httpRespSuccess(c, http.StatusOK, resp, nil)
+}
+
+func (e *rest) handleRotateApiKey(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	resp, err := e.svc.Auth.RotateApiKey(ctx, &dto.RotateApiKeyRequest{
+		UserId: c.GetString("user_auth_id"),
+		KeyId:  c.Param("key_id"),
+	})
+	if err != nil {
+		e.httpRespError(c, err)
+		return
+	}
+
+	e.httpRespSuccess(c, http.StatusCreated, resp, nil)
+}
+
+func (e *rest) handleRevokeApiKey(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	resp, err := e.svc.Auth.RevokeApiKey(ctx, &dto.RevokeApiKeyRequest{
+		UserId: c.GetString("user_auth_id"),
+		KeyId:  c.Param("key_id"),
+	})
+	if err != nil {
+		e.httpRespError(c, err)
+		return
 	}
 
 	e.httpRespSuccess(c, http.StatusOK, resp, nil)
@@ -1,10 +1,15 @@
 package rest
 
 import (
+	"strings"
 	"sync"
 
 	rpc "github.com/linggaaskaedo/go-kill/auth-service/src/internal/handler/grpc"
+	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/model/dto"
 	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/service"
+	"github.com/linggaaskaedo/go-kill/common/pkg/authz"
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+	"github.com/linggaaskaedo/go-kill/common/pkg/token"
 
 	"github.com/gin-gonic/gin"
 )
@@ -12,30 +17,81 @@ import (
 var onceRestHandler = &sync.Once{}
 
 type rest struct {
-	gin       *gin.Engine
-	svc       *service.Service
-	grpc      *rpc.Grpc
-	jwtSecret []byte
+	gin  *gin.Engine
+	svc  *service.Service
+	grpc *rpc.Grpc
+	keys *token.KeySet
 }
 
-func InitRestHandler(gin *gin.Engine, svc *service.Service, grpc *rpc.Grpc, jwtSecret string) {
+func InitRestHandler(gin *gin.Engine, svc *service.Service, grpc *rpc.Grpc, keys *token.KeySet) {
 	var e *rest
 
 	onceRestHandler.Do(func() {
 		e = &rest{
-			gin:       gin,
-			svc:       svc,
-			grpc:      grpc,
-			jwtSecret: []byte(jwtSecret),
+			gin:  gin,
+			svc:  svc,
+			grpc: grpc,
+			keys: keys,
 		}
 
 		e.Serve()
 	})
 }
 
+// authMiddleware accepts a valid, unrevoked access token and keeps its
+// subject and session on the context as user_auth_id and user_session_id,
+// and its roles and permissions as the request's authz.Principal. API keys
+// are deliberately not accepted: a leaked key must not be able to manage
+// the account, its sessions or other keys.
+func (e *rest) authMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const bearerPrefix = "Bearer "
+
+		authHeader := c.GetHeader("Authorization")
+		if !strings.HasPrefix(authHeader, bearerPrefix) {
+			e.httpRespError(c, x.NewWithCode(x.CodeHTTPUnauthorized, "no_authorization_header"))
+			c.Abort()
+			return
+		}
+
+		resp, err := e.svc.Auth.ValidateToken(c.Request.Context(), &dto.ValidateTokenRequest{Token: authHeader[len(bearerPrefix):]})
+		if err != nil {
+			e.httpRespError(c, x.WrapWithCode(err, x.CodeHTTPUnauthorized, "invalid_token"))
+			c.Abort()
+			return
+		}
+
+		c.Set("user_auth_id", resp.UserId)
+		c.Set("user_session_id", resp.SessionId)
+		c.Request = c.Request.WithContext(authz.WithPrincipal(c.Request.Context(), &authz.Principal{
+			UserID:      resp.UserId,
+			Roles:       resp.Roles,
+			Permissions: resp.Permissions,
+		}))
+		c.Next()
+	}
+}
+
 func (e *rest) Serve() {
 	e.gin.POST("/api/v1/auth/login", e.handleLogin)
 	e.gin.POST("/api/v1/auth/refresh", e.handleRefresh)
 	e.gin.POST("/api/v1/auth/logout", e.handleLogout)
-	e.gin.GET("/health", e.handleHealth)
+	e.gin.POST("/api/v1/auth/verify-email/request", e.handleRequestEmailVerification)
+	e.gin.POST("/api/v1/auth/verify-email", e.handleVerifyEmail)
+	e.gin.POST("/api/v1/auth/forgot-password", e.handleForgotPassword)
+	e.gin.POST("/api/v1/auth/reset-password", e.handleResetPassword)
+	e.gin.POST("/api/v1/auth/mfa/enroll", e.authMiddleware(), e.handleEnrollMfa)
+	e.gin.POST("/api/v1/auth/mfa/confirm", e.authMiddleware(), e.handleConfirmMfa)
+	e.gin.POST("/api/v1/auth/mfa/disable", e.authMiddleware(), e.handleDisableMfa)
+	e.gin.POST("/api/v1/auth/mfa/verify", e.handleVerifyMfa)
+	e.gin.GET("/api/v1/auth/sessions", e.authMiddleware(), e.handleListSessions)
+	e.gin.DELETE("/api/v1/auth/sessions/:session_id", e.authMiddleware(), e.handleRevokeSession)
+	e.gin.POST("/api/v1/auth/logout-all", e.authMiddleware(), e.handleLogoutAll)
+	e.gin.POST("/api/v1/auth/api-keys", e.authMiddleware(), e.handleCreateApiKey)
+	e.gin.GET("/api/v1/auth/api-keys", e.authMiddleware(), e.handleListApiKeys)
+	e.gin.POST("/api/v1/auth/api-keys/:key_id/rotate", e.authMiddleware(), e.handleRotateApiKey)
+	e.gin.DELETE("/api/v1/auth/api-keys/:key_id", e.authMiddleware(), e.handleRevokeApiKey)
+	e.gin.GET("/api/v1/auth/oauth/:provider/start", e.handleStartOAuthLogin)
+	e.gin.GET("/api/v1/auth/oauth/:provider/callback", e.handleOAuthCallback)
+	e.gin.GET(token.JWKSPath, e.handleJWKS)
 }
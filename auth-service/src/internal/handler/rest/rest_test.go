@@ -1,21 +1,44 @@
 package rest
 
 import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/handler/grpc"
 	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/service"
+	"github.com/linggaaskaedo/go-kill/common/pkg/token"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 )
 
 const (
-	pathHealth      = "/health"
 	pathAuthLogin   = "/api/v1/auth/login"
 	pathAuthRefresh = "/api/v1/auth/refresh"
 	pathAuthLogout  = "/api/v1/auth/logout"
+	pathJWKS        = "/.well-known/jwks.json"
+
+	pathAuthVerifyEmailRequest = "/api/v1/auth/verify-email/request"
+	pathAuthVerifyEmail        = "/api/v1/auth/verify-email"
+	pathAuthForgotPassword     = "/api/v1/auth/forgot-password"
+	pathAuthResetPassword      = "/api/v1/auth/reset-password"
+	pathAuthMfaEnroll          = "/api/v1/auth/mfa/enroll"
+	pathAuthMfaConfirm         = "/api/v1/auth/mfa/confirm"
+	pathAuthMfaDisable         = "/api/v1/auth/mfa/disable"
+	pathAuthMfaVerify          = "/api/v1/auth/mfa/verify"
+	pathAuthSessions           = "/api/v1/auth/sessions"
+	pathAuthLogoutAll          = "/api/v1/auth/logout-all"
+	pathAuthOAuthCallback      = "/api/v1/auth/oauth/google/callback"
+	pathAuthApiKeys            = "/api/v1/auth/api-keys"
 )
 
 func setupTestRouter() *gin.Engine {
@@ -23,14 +46,48 @@ func setupTestRouter() *gin.Engine {
 	return gin.New()
 }
 
+func writeTestKey(t *testing.T, dir, kid string) string {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	path := filepath.Join(dir, kid+".pem")
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	if err := os.WriteFile(path, pemBytes, 0o600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+
+	return path
+}
+
+func setupTestKeySet(t *testing.T, signingKeyID string, kids ...string) *token.KeySet {
+	t.Helper()
+
+	dir := t.TempDir()
+	cfg := token.Config{SigningKeyID: signingKeyID}
+	for _, kid := range kids {
+		cfg.Keys = append(cfg.Keys, token.Key{ID: kid, PrivateKeyPath: writeTestKey(t, dir, kid)})
+	}
+
+	keys, err := token.NewKeySet(cfg)
+	if err != nil {
+		t.Fatalf("new key set: %v", err)
+	}
+
+	return keys
+}
+
 func TestInitRestHandler(t *testing.T) {
 	router := setupTestRouter()
 
 	svc := &service.Service{}
 	grpcHandler := &grpc.Grpc{}
-	jwtSecret := "test-secret"
+	keys := setupTestKeySet(t, "test", "test")
 
-	InitRestHandler(router, svc, grpcHandler, jwtSecret)
+	InitRestHandler(router, svc, grpcHandler, keys)
 }
 
 func TestServeRoutesRegistered(t *testing.T) {
@@ -40,10 +97,10 @@ func TestServeRoutesRegistered(t *testing.T) {
 	grpcHandler := &grpc.Grpc{}
 
 	handler := &rest{
-		gin:       router,
-		svc:       svc,
-		grpc:      grpcHandler,
-		jwtSecret: []byte("test"),
+		gin:  router,
+		svc:  svc,
+		grpc: grpcHandler,
+		keys: setupTestKeySet(t, "test", "test"),
 	}
 
 	handler.Serve()
@@ -56,7 +113,24 @@ func TestServeRoutesRegistered(t *testing.T) {
 		{http.MethodPost, pathAuthLogin, http.StatusBadRequest},
 		{http.MethodPost, pathAuthRefresh, http.StatusBadRequest},
 		{http.MethodPost, pathAuthLogout, http.StatusUnauthorized},
-		{http.MethodGet, pathHealth, http.StatusOK},
+		{http.MethodPost, pathAuthVerifyEmailRequest, http.StatusBadRequest},
+		{http.MethodPost, pathAuthVerifyEmail, http.StatusBadRequest},
+		{http.MethodPost, pathAuthForgotPassword, http.StatusBadRequest},
+		{http.MethodPost, pathAuthResetPassword, http.StatusBadRequest},
+		{http.MethodPost, pathAuthMfaEnroll, http.StatusUnauthorized},
+		{http.MethodPost, pathAuthMfaConfirm, http.StatusUnauthorized},
+		{http.MethodPost, pathAuthMfaDisable, http.StatusUnauthorized},
+		{http.MethodPost, pathAuthMfaVerify, http.StatusBadRequest},
+		{http.MethodGet, pathAuthSessions, http.StatusUnauthorized},
+		{http.MethodDelete, pathAuthSessions + "/session-123", http.StatusUnauthorized},
+		{http.MethodPost, pathAuthLogoutAll, http.StatusUnauthorized},
+		{http.MethodPost, pathAuthApiKeys, http.StatusUnauthorized},
+		{http.MethodGet, pathAuthApiKeys, http.StatusUnauthorized},
+		{http.MethodPost, pathAuthApiKeys + "/key-123/rotate", http.StatusUnauthorized},
+		{http.MethodDelete, pathAuthApiKeys + "/key-123", http.StatusUnauthorized},
+		{http.MethodGet, pathAuthOAuthCallback, http.StatusBadRequest},
+		{http.MethodGet, pathAuthOAuthCallback + "?error=access_denied", http.StatusUnauthorized},
+		{http.MethodGet, pathJWKS, http.StatusOK},
 	}
 
 	for _, tt := range tests {
@@ -70,46 +144,46 @@ func TestServeRoutesRegistered(t *testing.T) {
 	}
 }
 
-func TestHandleHealth(t *testing.T) {
+func TestHandleLoginInvalidBody(t *testing.T) {
 	router := setupTestRouter()
 
 	svc := &service.Service{}
 	grpcHandler := &grpc.Grpc{}
 
 	handler := &rest{
-		gin:       router,
-		svc:       svc,
-		grpc:      grpcHandler,
-		jwtSecret: []byte("test"),
+		gin:  router,
+		svc:  svc,
+		grpc: grpcHandler,
+		keys: setupTestKeySet(t, "test", "test"),
 	}
 
-	router.GET(pathHealth, handler.handleHealth)
+	router.POST(pathAuthLogin, handler.handleLogin)
 
-	req, _ := http.NewRequest(http.MethodGet, pathHealth, nil)
+	req, _ := http.NewRequest(http.MethodPost, pathAuthLogin, nil)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
-	if w.Code != http.StatusOK {
-		t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+	if w.Code != http.StatusBadRequest && w.Code != http.StatusOK {
+		t.Errorf("expected status %d or %d, got %d", http.StatusBadRequest, http.StatusOK, w.Code)
 	}
 }
 
-func TestHandleLoginInvalidBody(t *testing.T) {
+func TestHandleRefreshInvalidBody(t *testing.T) {
 	router := setupTestRouter()
 
 	svc := &service.Service{}
 	grpcHandler := &grpc.Grpc{}
 
 	handler := &rest{
-		gin:       router,
-		svc:       svc,
-		grpc:      grpcHandler,
-		jwtSecret: []byte("test"),
+		gin:  router,
+		svc:  svc,
+		grpc: grpcHandler,
+		keys: setupTestKeySet(t, "test", "test"),
 	}
 
-	router.POST(pathAuthLogin, handler.handleLogin)
+	router.POST(pathAuthRefresh, handler.handleRefresh)
 
-	req, _ := http.NewRequest(http.MethodPost, pathAuthLogin, nil)
+	req, _ := http.NewRequest(http.MethodPost, pathAuthRefresh, nil)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
@@ -118,46 +192,79 @@ func TestHandleLoginInvalidBody(t *testing.T) {
 	}
 }
 
-func TestHandleRefreshInvalidBody(t *testing.T) {
+func TestHandleLogoutNoAuthorization(t *testing.T) {
 	router := setupTestRouter()
 
 	svc := &service.Service{}
 	grpcHandler := &grpc.Grpc{}
 
 	handler := &rest{
-		gin:       router,
-		svc:       svc,
-		grpc:      grpcHandler,
-		jwtSecret: []byte("test"),
+		gin:  router,
+		svc:  svc,
+		grpc: grpcHandler,
+		keys: setupTestKeySet(t, "test", "test"),
 	}
 
-	router.POST(pathAuthRefresh, handler.handleRefresh)
+	router.POST(pathAuthLogout, handler.handleLogout)
 
-	req, _ := http.NewRequest(http.MethodPost, pathAuthRefresh, nil)
+	req, _ := http.NewRequest(http.MethodPost, pathAuthLogout, nil)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
-	if w.Code != http.StatusBadRequest && w.Code != http.StatusOK {
-		t.Errorf("expected status %d or %d, got %d", http.StatusBadRequest, http.StatusOK, w.Code)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected status %d, got %d", http.StatusUnauthorized, w.Code)
 	}
 }
 
-func TestHandleLogoutNoAuthorization(t *testing.T) {
+func TestHandleLogoutRejectsUnknownKey(t *testing.T) {
 	router := setupTestRouter()
 
-	svc := &service.Service{}
-	grpcHandler := &grpc.Grpc{}
+	handler := &rest{
+		gin:  router,
+		svc:  &service.Service{},
+		grpc: &grpc.Grpc{},
+		keys: setupTestKeySet(t, "current", "current"),
+	}
+
+	router.POST(pathAuthLogout, handler.handleLogout)
+
+	otherKeys := setupTestKeySet(t, "other", "other")
+	signed, err := otherKeys.Sign(jwt.MapClaims{"sub": "user-1", "exp": time.Now().Add(time.Hour).Unix()})
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, pathAuthLogout, nil)
+	req.Header.Set("Authorization", "Bearer "+signed)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected status %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+}
+
+func TestHandleLogoutRejectsHS256(t *testing.T) {
+	router := setupTestRouter()
 
 	handler := &rest{
-		gin:       router,
-		svc:       svc,
-		grpc:      grpcHandler,
-		jwtSecret: []byte("test"),
+		gin:  router,
+		svc:  &service.Service{},
+		grpc: &grpc.Grpc{},
+		keys: setupTestKeySet(t, "test", "test"),
 	}
 
 	router.POST(pathAuthLogout, handler.handleLogout)
 
+	hs := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "user-1", "exp": time.Now().Add(time.Hour).Unix()})
+	hs.Header["kid"] = "test"
+	signed, err := hs.SignedString([]byte("test"))
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
 	req, _ := http.NewRequest(http.MethodPost, pathAuthLogout, nil)
+	req.Header.Set("Authorization", "Bearer "+signed)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
@@ -165,3 +272,72 @@ func TestHandleLogoutNoAuthorization(t *testing.T) {
 		t.Errorf("expected status %d, got %d", http.StatusUnauthorized, w.Code)
 	}
 }
+
+func TestHandleJWKS(t *testing.T) {
+	router := setupTestRouter()
+
+	keys := setupTestKeySet(t, "2026-10", "2026-04", "2026-10")
+	handler := &rest{
+		gin:  router,
+		svc:  &service.Service{},
+		grpc: &grpc.Grpc{},
+		keys: keys,
+	}
+
+	router.GET(pathJWKS, handler.handleJWKS)
+
+	req, _ := http.NewRequest(http.MethodGet, pathJWKS, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var set token.JWKS
+	if err := json.Unmarshal(w.Body.Bytes(), &set); err != nil {
+		t.Fatalf("decode jwks: %v", err)
+	}
+
+	if len(set.Keys) != 2 {
+		t.Fatalf("expected 2 keys, got %d", len(set.Keys))
+	}
+
+	if set.Keys[0].Kid != "2026-10" || set.Keys[1].Kid != "2026-04" {
+		t.Errorf("expected signing key first, got %s, %s", set.Keys[0].Kid, set.Keys[1].Kid)
+	}
+
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || k.Alg != "RS256" || k.Use != "sig" || k.N == "" || k.E == "" {
+			t.Errorf("unexpected jwk %+v", k)
+		}
+	}
+}
+
+func TestRemoteKeySetVerifiesPublishedKeys(t *testing.T) {
+	router := setupTestRouter()
+
+	keys := setupTestKeySet(t, "test", "test")
+	handler := &rest{
+		gin:  router,
+		svc:  &service.Service{},
+		grpc: &grpc.Grpc{},
+		keys: keys,
+	}
+
+	router.GET(pathJWKS, handler.handleJWKS)
+
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	signed, err := keys.Sign(jwt.MapClaims{"sub": "user-1", "exp": time.Now().Add(time.Hour).Unix()})
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	remote := token.NewRemoteKeySet(srv.URL+pathJWKS, time.Minute)
+	parsed, err := remote.Parse(t.Context(), signed)
+	if err != nil || !parsed.Valid {
+		t.Fatalf("expected token to verify against jwks, got %v", err)
+	}
+}
@@ -0,0 +1,20 @@
+package dto
+
+import "time"
+
+// AuthEvent asks notification-service to email a single-use link.
+type AuthEvent struct {
+	EventID   string        `json:"event_id"`
+	EventType string        `json:"event_type"`
+	Version   string        `json:"version"`
+	Timestamp time.Time     `json:"timestamp"`
+	Source    string        `json:"source"`
+	Data      AuthEventData `json:"data"`
+}
+
+type AuthEventData struct {
+	AuthID    string    `json:"auth_id"`
+	Email     string    `json:"email"`
+	Link      string    `json:"link"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -7,6 +7,8 @@ type UserLoginRequest struct {
 
 type RefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token" binding:"required"`
+	IpAddress    string `json:"-"`
+	UserAgent    string `json:"-"`
 }
 
 type CreateAuthUserRequest struct {
@@ -25,7 +27,97 @@ type ValidateTokenRequest struct {
 	Token string `json:"token" binding:"required"`
 }
 
+type ValidateApiKeyRequest struct {
+	ApiKey string `json:"api_key" binding:"required"`
+}
+
 type LogoutRequest struct {
 	Token  string `json:"token" binding:"required"`
 	UserId string `json:"user_id" binding:"required"`
 }
+
+type RequestEmailVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type RequestPasswordResetRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=8"`
+}
+
+type EnrollMfaRequest struct {
+	UserId string `json:"-"`
+}
+
+type ConfirmMfaRequest struct {
+	UserId string `json:"-"`
+	Code   string `json:"code" binding:"required"`
+}
+
+type DisableMfaRequest struct {
+	UserId string `json:"-"`
+	Code   string `json:"code" binding:"required"`
+}
+
+type VerifyMfaRequest struct {
+	MfaToken  string `json:"mfa_token" binding:"required"`
+	Code      string `json:"code" binding:"required"`
+	IpAddress string `json:"ip_address"`
+	UserAgent string `json:"user_agent"`
+}
+
+type ListSessionsRequest struct {
+	UserId           string `json:"-"`
+	CurrentSessionId string `json:"-"`
+}
+
+type RevokeSessionRequest struct {
+	UserId    string `json:"-"`
+	SessionId string `json:"-"`
+}
+
+type LogoutAllRequest struct {
+	UserId string `json:"-"`
+}
+
+type StartOAuthLoginRequest struct {
+	Provider string `json:"provider" binding:"required"`
+}
+
+type CompleteOAuthLoginRequest struct {
+	Provider  string `json:"provider" binding:"required"`
+	Code      string `json:"code" binding:"required"`
+	State     string `json:"state" binding:"required"`
+	IpAddress string `json:"ip_address"`
+	UserAgent string `json:"user_agent"`
+}
+
+type CreateApiKeyRequest struct {
+	UserId string   `json:"-"`
+	Name   string   `json:"name" binding:"required,max=100"`
+	Scopes []string `json:"scopes"`
+	// Seconds until the key expires, 0 for never
+	ExpiresIn int64 `json:"expires_in" binding:"min=0"`
+}
+
+type ListApiKeysRequest struct {
+	UserId string `json:"-"`
+}
+
+type RotateApiKeyRequest struct {
+	UserId string `json:"-"`
+	KeyId  string `json:"-"`
+}
+
+type RevokeApiKeyRequest struct {
+	UserId string `json:"-"`
+	KeyId  string `json:"-"`
+}
@@ -1,6 +1,8 @@
 package dto
 
 import (
+	"time"
+
 	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
 )
 
@@ -30,15 +32,21 @@ type CreateAuthUserResponse struct {
 
 type LoginResponse struct {
 	Success      bool   `json:"success"`
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
-	ExpiresIn    int64  `json:"expires_in"`
+	AccessToken  string `json:"access_token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ExpiresIn    int64  `json:"expires_in,omitempty"`
+	MfaRequired  bool   `json:"mfa_required,omitempty"`
+	MfaToken     string `json:"mfa_token,omitempty"`
 }
 
 type ValidateTokenResponse struct {
-	Valid  bool   `json:"valid"`
-	UserId string `json:"user_id"`
-	Email  string `json:"email"`
+	Valid       bool     `json:"valid"`
+	UserId      string   `json:"user_id"`
+	Email       string   `json:"email"`
+	SessionId   string   `json:"session_id,omitempty"`
+	Roles       []string `json:"roles"`
+	Permissions []string `json:"permissions"`
+	ApiKeyId    string   `json:"api_key_id,omitempty"`
 }
 
 type RefreshTokenResponse struct {
@@ -52,3 +60,91 @@ type LogoutResponse struct {
 	Success bool   `json:"success"`
 	Message string `json:"message"`
 }
+
+type RequestEmailVerificationResponse struct {
+	Success bool `json:"success"`
+}
+
+type VerifyEmailResponse struct {
+	Success bool `json:"success"`
+}
+
+type RequestPasswordResetResponse struct {
+	Success bool `json:"success"`
+}
+
+type ResetPasswordResponse struct {
+	Success bool `json:"success"`
+}
+
+type EnrollMfaResponse struct {
+	Secret     string `json:"secret"`
+	OtpauthUri string `json:"otpauth_uri"`
+}
+
+type ConfirmMfaResponse struct {
+	Success       bool     `json:"success"`
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type DisableMfaResponse struct {
+	Success bool `json:"success"`
+}
+
+type VerifyMfaResponse struct {
+	Success      bool   `json:"success"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+type SessionResponse struct {
+	SessionId  string    `json:"session_id"`
+	IpAddress  string    `json:"ip_address"`
+	UserAgent  string    `json:"user_agent"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	Current    bool      `json:"current"`
+}
+
+type ListSessionsResponse struct {
+	Sessions []SessionResponse `json:"sessions"`
+}
+
+type RevokeSessionResponse struct {
+	Success bool `json:"success"`
+}
+
+type LogoutAllResponse struct {
+	Success bool  `json:"success"`
+	Revoked int64 `json:"revoked"`
+}
+
+type StartOAuthLoginResponse struct {
+	AuthorizationUrl string `json:"authorization_url"`
+	State            string `json:"state"`
+}
+
+type ApiKeyResponse struct {
+	KeyId      string     `json:"key_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreateApiKeyResponse carries the key itself, which is shown only once.
+type CreateApiKeyResponse struct {
+	ApiKey ApiKeyResponse `json:"api_key"`
+	Key    string         `json:"key"`
+}
+
+type ListApiKeysResponse struct {
+	ApiKeys []ApiKeyResponse `json:"api_keys"`
+}
+
+type RevokeApiKeyResponse struct {
+	Success bool `json:"success"`
+}
@@ -1,12 +1,144 @@
 package entity
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 type UserAuth struct {
-	ID           string    `db:"id" json:"id"`
-	Email        string    `db:"email" json:"email"`
-	PasswordHash string    `db:"password_hash" json:"password_hash"`
-	IsActive     bool      `db:"is_active" json:"is_active"`
-	CreatedAt    time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt    time.Time `db:"updated_at" json:"updated_at"`
+	ID              string     `db:"id" json:"id"`
+	Email           string     `db:"email" json:"email"`
+	PasswordHash    string     `db:"password_hash" json:"password_hash"`
+	IsActive        bool       `db:"is_active" json:"is_active"`
+	LockedUntil     *time.Time `db:"locked_until" json:"locked_until"`
+	EmailVerifiedAt *time.Time `db:"email_verified_at" json:"email_verified_at"`
+	MfaEnabled      bool       `db:"mfa_enabled" json:"mfa_enabled"`
+	CreatedAt       time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt       time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// LoginFailures is the failed-login state tracked for one email and one
+// client IP. RetryAt is zero unless the email is in a progressive delay.
+type LoginFailures struct {
+	Email   int64
+	IP      int64
+	RetryAt time.Time
+}
+
+// AuthTokenPurpose scopes a single-use token so one issued for email
+// verification cannot reset a password, and the other way around.
+type AuthTokenPurpose string
+
+const (
+	TokenEmailVerification AuthTokenPurpose = "email_verification"
+	TokenPasswordReset     AuthTokenPurpose = "password_reset"
+)
+
+// UserMfa is a user's TOTP enrolment. EnabledAt is nil until the first code
+// is confirmed. LastUsedStep is the newest time step accepted, so a code
+// cannot be replayed within its validity window.
+type UserMfa struct {
+	UserID       string     `db:"user_id"`
+	Secret       string     `db:"secret"`
+	EnabledAt    *time.Time `db:"enabled_at"`
+	LastUsedStep int64      `db:"last_used_step"`
+}
+
+// MfaChallenge is a login that passed the password check and waits for an
+// MFA code.
+type MfaChallenge struct {
+	UserID    string
+	Email     string
+	IPAddress string
+	UserAgent string
+	Attempts  int64
+}
+
+// RefreshToken is one refresh token in a family. A login starts a family and
+// every rotation marks the presented token used and issues a child in the
+// same family, so a used token showing up again can be traced to its session.
+// FamilyID is the id of that Session.
+type RefreshToken struct {
+	ID        string     `db:"id" json:"id"`
+	UserID    string     `db:"user_id" json:"user_id"`
+	TokenHash string     `db:"token_hash" json:"-"`
+	FamilyID  string     `db:"family_id" json:"family_id"`
+	ParentID  *string    `db:"parent_id" json:"parent_id"`
+	ExpiresAt time.Time  `db:"expires_at" json:"expires_at"`
+	UsedAt    *time.Time `db:"used_at" json:"used_at"`
+	RevokedAt *time.Time `db:"revoked_at" json:"revoked_at"`
+}
+
+// Active reports whether the token can still be exchanged at now.
+func (r *RefreshToken) Active(now time.Time) bool {
+	return r.UsedAt == nil && r.RevokedAt == nil && now.Before(r.ExpiresAt)
+}
+
+// Session is one logged-in device. Its id is the family id of the refresh
+// tokens issued to it and the "sid" claim of its access tokens.
+type Session struct {
+	ID         string     `db:"id" json:"id"`
+	UserID     string     `db:"user_id" json:"user_id"`
+	IPAddress  string     `db:"ip_address" json:"ip_address"`
+	UserAgent  string     `db:"user_agent" json:"user_agent"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	LastSeenAt time.Time  `db:"last_seen_at" json:"last_seen_at"`
+	RevokedAt  *time.Time `db:"revoked_at" json:"revoked_at"`
+}
+
+// RoleCustomer is the role every new account starts with.
+const RoleCustomer = "customer"
+
+// UserRoles is what a user may do: the names of their roles and the
+// permissions those roles grant. Both end up as access token claims.
+type UserRoles struct {
+	Roles       []string
+	Permissions []string
+}
+
+// UserIdentity links an account at an external OAuth provider to a user.
+// Subject is the provider's stable id for the account; Email is the address
+// it reported at the last login.
+type UserIdentity struct {
+	ID          string    `db:"id" json:"id"`
+	UserID      string    `db:"user_id" json:"user_id"`
+	Provider    string    `db:"provider" json:"provider"`
+	Subject     string    `db:"subject" json:"subject"`
+	Email       string    `db:"email" json:"email"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	LastLoginAt time.Time `db:"last_login_at" json:"last_login_at"`
+}
+
+// OAuthState is a started OAuth login waiting for the provider's callback.
+// CodeVerifier is the PKCE secret and Nonce is expected back in the ID token.
+type OAuthState struct {
+	Provider     string
+	CodeVerifier string
+	Nonce        string
+}
+
+// ApiKey is a long-lived credential a user issues to a machine client.
+// Only the SHA-256 of the key is stored; Prefix is its first characters,
+// kept to tell keys apart. Scopes is a space-separated list of permissions
+// the key may use, out of those its owner holds.
+type ApiKey struct {
+	ID         string     `db:"id" json:"id"`
+	UserID     string     `db:"user_id" json:"user_id"`
+	Name       string     `db:"name" json:"name"`
+	Prefix     string     `db:"prefix" json:"prefix"`
+	Scopes     string     `db:"scopes" json:"scopes"`
+	ExpiresAt  *time.Time `db:"expires_at" json:"expires_at"`
+	LastUsedAt *time.Time `db:"last_used_at" json:"last_used_at"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	RevokedAt  *time.Time `db:"revoked_at" json:"revoked_at"`
+}
+
+// ScopeList splits Scopes.
+func (k *ApiKey) ScopeList() []string {
+	return strings.Fields(k.Scopes)
+}
+
+// Active reports whether the key can still be used at now.
+func (k *ApiKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
 }
@@ -0,0 +1,218 @@
+// Package oauthtest runs an in-process OpenID Connect provider for tests, so
+// the OAuth login flow can be exercised end to end without network access.
+package oauthtest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/linggaaskaedo/go-kill/common/pkg/token"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	ClientID     = "test-client"
+	ClientSecret = "test-secret"
+
+	keyID = "oauthtest"
+)
+
+// User is the account the next authorization signs in as.
+type User struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+type grant struct {
+	user          User
+	redirectURI   string
+	codeChallenge string
+	nonce         string
+}
+
+// Server is a minimal OIDC provider. Its authorization endpoint skips the
+// login page and redirects straight back with a code for the current User.
+// The token endpoint enforces the client secret, redirect URI and S256 PKCE
+// verifier, and codes work once.
+type Server struct {
+	*httptest.Server
+
+	key *rsa.PrivateKey
+
+	mu     sync.Mutex
+	user   User
+	grants map[string]grant
+}
+
+func NewServer() *Server {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+
+	s := &Server{
+		key:    key,
+		grants: map[string]grant{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", s.handleDiscovery)
+	mux.HandleFunc("GET /authorize", s.handleAuthorize)
+	mux.HandleFunc("POST /token", s.handleToken)
+	mux.HandleFunc("GET /jwks", s.handleJWKS)
+
+	s.Server = httptest.NewServer(mux)
+
+	return s
+}
+
+// SetUser picks the account later authorizations sign in as.
+func (s *Server) SetUser(u User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.user = u
+}
+
+// Authorize follows the redirect a browser would and returns the code and
+// state it carried back to the client.
+func (s *Server) Authorize(authCodeURL string) (code string, state string, err error) {
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+
+	resp, err := client.Get(authCodeURL)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+
+	location, err := resp.Location()
+	if err != nil {
+		return "", "", err
+	}
+
+	return location.Query().Get("code"), location.Query().Get("state"), nil
+}
+
+func (s *Server) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                 s.URL,
+		"authorization_endpoint": s.URL + "/authorize",
+		"token_endpoint":         s.URL + "/token",
+		"jwks_uri":               s.URL + "/jwks",
+	})
+}
+
+func (s *Server) handleAuthorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("client_id") != ClientID || q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		http.Error(w, "invalid authorization request", http.StatusBadRequest)
+		return
+	}
+
+	redirect, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil || redirect.Scheme == "" {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	code := rand.Text()
+
+	s.mu.Lock()
+	s.grants[code] = grant{
+		user:          s.user,
+		redirectURI:   q.Get("redirect_uri"),
+		codeChallenge: q.Get("code_challenge"),
+		nonce:         q.Get("nonce"),
+	}
+	s.mu.Unlock()
+
+	params := redirect.Query()
+	params.Set("code", code)
+	params.Set("state", q.Get("state"))
+	redirect.RawQuery = params.Encode()
+
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	if r.PostForm.Get("client_id") != ClientID || r.PostForm.Get("client_secret") != ClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	code := r.PostForm.Get("code")
+
+	s.mu.Lock()
+	g, ok := s.grants[code]
+	delete(s.grants, code)
+	s.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || r.PostForm.Get("redirect_uri") != g.redirectURI || base64.RawURLEncoding.EncodeToString(sum[:]) != g.codeChallenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":            s.URL,
+		"aud":            ClientID,
+		"sub":            g.user.Subject,
+		"email":          g.user.Email,
+		"email_verified": g.user.EmailVerified,
+		"name":           g.user.Name,
+		"nonce":          g.nonce,
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+	})
+	idToken.Header["kid"] = keyID
+
+	signed, err := idToken.SignedString(s.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": rand.Text(),
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     signed,
+	})
+}
+
+func (s *Server) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, token.JWKS{Keys: []token.JWK{{
+		Kty: "RSA",
+		Use: "sig",
+		Alg: jwt.SigningMethodRS256.Alg(),
+		Kid: keyID,
+		N:   base64.RawURLEncoding.EncodeToString(s.key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(s.key.E)).Bytes()),
+	}}})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,170 @@
+package oauth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/linggaaskaedo/go-kill/common/pkg/token"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const discoveryPath = "/.well-known/openid-configuration"
+
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// OIDCProvider signs users in with OpenID Connect. Endpoints and signing keys
+// come from the issuer's discovery document, fetched on first use so the
+// service starts without reaching the provider. The identity is read from
+// the ID token, whose issuer, audience and nonce are checked.
+type OIDCProvider struct {
+	name   string
+	opts   ProviderOptions
+	client *http.Client
+
+	mu        sync.Mutex
+	discovery *discoveryDocument
+	keys      *token.RemoteKeySet
+}
+
+func NewOIDCProvider(name string, opts ProviderOptions) *OIDCProvider {
+	if len(opts.Scopes) == 0 {
+		opts.Scopes = []string{"openid", "email", "profile"}
+	}
+
+	return &OIDCProvider{
+		name:   name,
+		opts:   opts,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *OIDCProvider) Name() string {
+	return p.name
+}
+
+func (p *OIDCProvider) AuthCodeURL(ctx context.Context, req AuthRequest) (string, error) {
+	doc, _, err := p.endpoints(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return withQuery(doc.AuthorizationEndpoint, url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.opts.ClientID},
+		"redirect_uri":          {p.opts.RedirectURL},
+		"scope":                 {strings.Join(p.opts.Scopes, " ")},
+		"state":                 {req.State},
+		"nonce":                 {req.Nonce},
+		"code_challenge":        {req.CodeChallenge},
+		"code_challenge_method": {"S256"},
+	})
+}
+
+func (p *OIDCProvider) Exchange(ctx context.Context, code string, codeVerifier string, nonce string) (*Identity, error) {
+	doc, keys, err := p.endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := exchangeCode(ctx, p.client, doc.TokenEndpoint, p.opts, code, codeVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.IDToken == "" {
+		return nil, fmt.Errorf("oauth: token endpoint returned no id_token")
+	}
+
+	idToken, err := keys.Parse(ctx, resp.IDToken)
+	if err != nil {
+		return nil, fmt.Errorf("oauth: verify id_token: %w", err)
+	}
+
+	claims, ok := idToken.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, fmt.Errorf("oauth: id_token has no claims")
+	}
+
+	if iss, _ := claims.GetIssuer(); iss != doc.Issuer {
+		return nil, fmt.Errorf("oauth: id_token issuer %q, want %q", iss, doc.Issuer)
+	}
+
+	if aud, _ := claims.GetAudience(); !slices.Contains(aud, p.opts.ClientID) {
+		return nil, fmt.Errorf("oauth: id_token not issued for this client")
+	}
+
+	if claimNonce, _ := claims["nonce"].(string); claimNonce != nonce {
+		return nil, fmt.Errorf("oauth: id_token nonce mismatch")
+	}
+
+	sub, _ := claims.GetSubject()
+	if sub == "" {
+		return nil, fmt.Errorf("oauth: id_token has no subject")
+	}
+
+	email, _ := claims["email"].(string)
+	name, _ := claims["name"].(string)
+
+	return &Identity{
+		Provider:      p.name,
+		Subject:       sub,
+		Email:         strings.ToLower(email),
+		EmailVerified: emailVerified(claims["email_verified"]),
+		Name:          name,
+	}, nil
+}
+
+// endpoints returns the discovery document, fetching it the first time. A
+// failed fetch is retried on the next login.
+func (p *OIDCProvider) endpoints(ctx context.Context) (*discoveryDocument, *token.RemoteKeySet, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, p.keys, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(p.opts.Issuer, "/")+discoveryPath, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("oauth: build discovery request: %w", err)
+	}
+
+	var doc discoveryDocument
+	if err := doJSON(p.client, req, &doc); err != nil {
+		return nil, nil, err
+	}
+
+	if doc.Issuer == "" || doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return nil, nil, fmt.Errorf("oauth: incomplete discovery document from %s", p.opts.Issuer)
+	}
+
+	p.discovery = &doc
+	p.keys = token.NewRemoteKeySet(doc.JWKSURI, time.Minute)
+
+	return p.discovery, p.keys, nil
+}
+
+// emailVerified accepts the boolean the spec requires and the string some
+// providers send instead.
+func emailVerified(v any) bool {
+	switch v := v.(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	}
+
+	return false
+}
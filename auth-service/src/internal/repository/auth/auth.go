@@ -4,7 +4,6 @@ import (
 	"context"
 	"time"
 
-	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/model/dto"
 	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/model/entity"
 	"github.com/linggaaskaedo/go-kill/common/component/query"
 
@@ -14,14 +13,65 @@ import (
 )
 
 type AuthRepositoryItf interface {
-	CreateAuthUser(ctx context.Context, req *dto.CreateAuthUserRequest) (string, error)
+	CreateAuthUser(ctx context.Context, email string, passwordHash string) (string, error)
 	FindAuthUserByEmail(ctx context.Context, email string) (*entity.UserAuth, error)
-	StoreSession(ctx context.Context, userID string, refreshToken string, expired time.Time, email string, ipAddress string) error
-	RotateRefreshToken(ctx context.Context, userID string, oldRefreshToken string, newRefreshToken string, expired time.Time) error
+	FindAuthUserByID(ctx context.Context, userID string) (*entity.UserAuth, error)
+	FindUserRoles(ctx context.Context, userID string) (*entity.UserRoles, error)
 	FindTokenID(ctx context.Context, tokenID string) bool
-	GetUserInfo(ctx context.Context, refreshToken string) (*entity.UserAuth, error)
 	BlacklistToken(ctx context.Context, token *jwt.Token) error
-	ClearSession(ctx context.Context, userID string) error
+
+	// Sessions and refresh token families
+	StoreSession(ctx context.Context, session *entity.Session, email string, refreshToken string, expired time.Time) (string, error)
+	FindRefreshToken(ctx context.Context, refreshToken string) (*entity.RefreshToken, error)
+	RotateRefreshToken(ctx context.Context, current *entity.RefreshToken, newRefreshToken string, expired time.Time, ipAddress string, userAgent string) (bool, error)
+	GetSession(ctx context.Context, sessionID string) (*entity.Session, error)
+	ListSessions(ctx context.Context, userID string) ([]entity.Session, error)
+	RevokeSession(ctx context.Context, sessionID string) error
+	RevokeAllSessions(ctx context.Context, userID string) (int, error)
+	FindRevokedSession(ctx context.Context, sessionID string) bool
+
+	// Login throttling
+	GetLoginFailures(ctx context.Context, email string, ipAddress string) (*entity.LoginFailures, error)
+	RecordLoginFailure(ctx context.Context, email string, ipAddress string, window time.Duration) (*entity.LoginFailures, error)
+	DelayLogin(ctx context.Context, email string, delay time.Duration) error
+	ClearLoginFailures(ctx context.Context, email string) error
+	LockAuthUser(ctx context.Context, userID string, until time.Time) error
+
+	// Email verification and password reset
+	StoreAuthToken(ctx context.Context, userID string, purpose entity.AuthTokenPurpose, token string, expiresAt time.Time) error
+	VerifyEmail(ctx context.Context, token string) (string, error)
+	FindAuthTokenUser(ctx context.Context, token string, purpose entity.AuthTokenPurpose) (string, error)
+	ResetPassword(ctx context.Context, token string, passwordHash string) (string, error)
+	RehashPassword(ctx context.Context, userID string, oldHash string, newHash string) error
+
+	// MFA
+	SaveMfaSecret(ctx context.Context, userID string, secret string) error
+	GetUserMfa(ctx context.Context, userID string) (*entity.UserMfa, error)
+	EnableMfa(ctx context.Context, userID string, step int64, recoveryCodes []string) error
+	DisableMfa(ctx context.Context, userID string) error
+	UseMfaStep(ctx context.Context, userID string, step int64) (bool, error)
+	UseMfaRecoveryCode(ctx context.Context, userID string, code string) (bool, error)
+	StoreMfaChallenge(ctx context.Context, token string, challenge *entity.MfaChallenge, ttl time.Duration) error
+	GetMfaChallenge(ctx context.Context, token string) (*entity.MfaChallenge, error)
+	RecordMfaFailure(ctx context.Context, token string) (int64, error)
+	ConsumeMfaChallenge(ctx context.Context, token string) (bool, error)
+
+	// OAuth login
+	FindUserIdentity(ctx context.Context, provider string, subject string) (*entity.UserIdentity, error)
+	TouchUserIdentity(ctx context.Context, identityID string, email string) error
+	LinkUserIdentity(ctx context.Context, identity *entity.UserIdentity) error
+	CreateOAuthUser(ctx context.Context, identity *entity.UserIdentity) (string, error)
+	StoreOAuthState(ctx context.Context, state string, oauthState *entity.OAuthState, ttl time.Duration) error
+	ConsumeOAuthState(ctx context.Context, state string) (*entity.OAuthState, error)
+
+	// API keys
+	CreateApiKey(ctx context.Context, apiKey *entity.ApiKey, key string) error
+	GetApiKey(ctx context.Context, keyID string) (*entity.ApiKey, error)
+	FindApiKey(ctx context.Context, key string) (*entity.ApiKey, error)
+	ListApiKeys(ctx context.Context, userID string) ([]entity.ApiKey, error)
+	RotateApiKey(ctx context.Context, current *entity.ApiKey, next *entity.ApiKey, key string, expireCurrentAt time.Time) error
+	RevokeApiKey(ctx context.Context, keyID string) error
+	TouchApiKey(ctx context.Context, keyID string, interval time.Duration) error
 }
 
 type authRepository struct {
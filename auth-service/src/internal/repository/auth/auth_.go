@@ -2,22 +2,20 @@ package auth
 
 import (
 	"context"
-	"fmt"
 	"time"
 
-	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/model/dto"
 	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/model/entity"
 	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/rs/zerolog"
-	"golang.org/x/crypto/bcrypt"
 )
 
-func (a *authRepository) CreateAuthUser(ctx context.Context, req *dto.CreateAuthUserRequest) (string, error) {
+// CreateAuthUser registers a customer with an already hashed password.
+func (a *authRepository) CreateAuthUser(ctx context.Context, email string, passwordHash string) (string, error) {
 	var authID string
 
-	emailExists, err := a.checkUserExistSql(ctx, req.Email)
+	emailExists, err := a.checkUserExistSql(ctx, email)
 	if err != nil {
 		return authID, err
 	}
@@ -27,17 +25,28 @@ func (a *authRepository) CreateAuthUser(ctx context.Context, req *dto.CreateAuth
 		return authID, x.New("Email already registered")
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), 12)
+	tx, err := a.db0.BeginTxx(ctx, nil)
 	if err != nil {
-		zerolog.Ctx(ctx).Error().Err(err).Msg("hashed_password")
-		return authID, x.Wrap(err, "hashed_password")
+		zerolog.Ctx(ctx).Error().Err(err).Msg("tx_create_auth_user")
+		return authID, x.WrapWithCode(err, x.CodeSQLTxBegin, "tx_create_auth_user")
 	}
 
-	authID, err = a.saveUserSql(ctx, req.Email, hashedPassword)
+	authID, err = a.saveUserSql(ctx, tx, email, passwordHash)
 	if err != nil {
+		_ = tx.Rollback()
 		return authID, err
 	}
 
+	if err := a.assignUserRoleSql(ctx, tx, authID, entity.RoleCustomer); err != nil {
+		_ = tx.Rollback()
+		return "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("commit_create_auth_user")
+		return "", x.WrapWithCode(err, x.CodeSQLTxCommit, "commit_create_auth_user")
+	}
+
 	return authID, nil
 }
 
@@ -50,36 +59,92 @@ func (a *authRepository) FindAuthUserByEmail(ctx context.Context, email string)
 	return userAuth, nil
 }
 
-func (a *authRepository) StoreSession(ctx context.Context, userID string, refreshToken string, expired time.Time, email string, ipAddress string) error {
-	err := a.storeRefreshTokenSql(ctx, userID, refreshToken, expired)
+func (a *authRepository) FindAuthUserByID(ctx context.Context, userID string) (*entity.UserAuth, error) {
+	return a.getUserByIDSql(ctx, userID)
+}
+
+func (a *authRepository) FindUserRoles(ctx context.Context, userID string) (*entity.UserRoles, error) {
+	return a.getUserRolesSql(ctx, userID)
+}
+
+// StoreSession creates session and stores refreshToken as the first token
+// of its family. It returns the session id.
+func (a *authRepository) StoreSession(ctx context.Context, session *entity.Session, email string, refreshToken string, expired time.Time) (string, error) {
+	tx, err := a.db0.BeginTxx(ctx, nil)
 	if err != nil {
-		return err
+		zerolog.Ctx(ctx).Error().Err(err).Msg("tx_store_session")
+		return "", x.WrapWithCode(err, x.CodeSQLTxBegin, "tx_store_session")
 	}
 
-	err = a.storeSessionCache(ctx, userID, refreshToken, email, ipAddress)
-	if err != nil {
-		return err
+	if err := a.createSessionSql(ctx, tx, session); err != nil {
+		_ = tx.Rollback()
+		return "", err
 	}
 
-	return nil
+	token := &entity.RefreshToken{
+		UserID:    session.UserID,
+		TokenHash: hashToken(refreshToken),
+		FamilyID:  session.ID,
+		ExpiresAt: expired,
+	}
+
+	if err := a.storeRefreshTokenSql(ctx, tx, token); err != nil {
+		_ = tx.Rollback()
+		return "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("commit_store_session")
+		return "", x.WrapWithCode(err, x.CodeSQLTxCommit, "commit_store_session")
+	}
+
+	if err := a.storeRefreshTokenCache(ctx, token); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("store_refresh_token_cache")
+	}
+
+	if err := a.storeSessionCache(ctx, session, email); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("store_session_cache")
+	}
+
+	return session.ID, nil
 }
 
 func (a *authRepository) FindTokenID(ctx context.Context, tokenID string) bool {
 	return a.findTokenIDCache(ctx, tokenID)
 }
 
-func (a *authRepository) GetUserInfo(ctx context.Context, refreshToken string) (*entity.UserAuth, error) {
-	userID, err := a.findRefreshTokenCache(ctx, refreshToken)
-	if err != nil {
-		return nil, err
+func (a *authRepository) FindRevokedSession(ctx context.Context, sessionID string) bool {
+	return a.findRevokedSessionCache(ctx, sessionID)
+}
+
+// FindRefreshToken looks refreshToken up in the cache and falls back to the
+// table, which is the source of truth. Active tokens found only in the table
+// are cached again. It fails with CodeSQLRecordDoesNotExist for a token that
+// was never issued; used and revoked tokens are returned so the caller can
+// tell reuse apart from garbage.
+func (a *authRepository) FindRefreshToken(ctx context.Context, refreshToken string) (*entity.RefreshToken, error) {
+	hashedToken := hashToken(refreshToken)
+
+	token, err := a.getRefreshTokenCache(ctx, hashedToken)
+	if err == nil {
+		return token, nil
+	}
+	if x.ErrCode(err) != x.CodeCacheNotFound {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("get_refresh_token_cache")
 	}
 
-	result, err := a.getUserByIDSql(ctx, userID)
+	token, err = a.getRefreshTokenSql(ctx, hashedToken)
 	if err != nil {
 		return nil, err
 	}
 
-	return result, nil
+	if token.Active(time.Now()) {
+		if err := a.storeRefreshTokenCache(ctx, token); err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("store_refresh_token_cache")
+		}
+	}
+
+	return token, nil
 }
 
 func (a *authRepository) BlacklistToken(ctx context.Context, token *jwt.Token) error {
@@ -95,37 +160,484 @@ func (a *authRepository) BlacklistToken(ctx context.Context, token *jwt.Token) e
 	return nil
 }
 
-func (a *authRepository) ClearSession(ctx context.Context, userID string) error {
-	// Delete session
-	err := a.deleteSessionCache(ctx, userID)
+// RotateRefreshToken uses up current, stores newRefreshToken as its child in
+// the same family and marks the session seen from ipAddress and userAgent.
+// It reports false, storing nothing, when current was already used or
+// revoked in the meantime.
+func (a *authRepository) RotateRefreshToken(ctx context.Context, current *entity.RefreshToken, newRefreshToken string, expired time.Time, ipAddress string, userAgent string) (bool, error) {
+	next := &entity.RefreshToken{
+		UserID:    current.UserID,
+		TokenHash: hashToken(newRefreshToken),
+		FamilyID:  current.FamilyID,
+		ParentID:  &current.ID,
+		ExpiresAt: expired,
+	}
+
+	tx, err := a.db0.BeginTxx(ctx, nil)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("tx_rotate_refresh_token")
+		return false, x.WrapWithCode(err, x.CodeSQLTxBegin, "tx_rotate_refresh_token")
+	}
+
+	used, err := a.useRefreshTokenSql(ctx, tx, current.ID)
+	if err != nil || !used {
+		_ = tx.Rollback()
+		return false, err
+	}
+
+	if err := a.storeRefreshTokenSql(ctx, tx, next); err != nil {
+		_ = tx.Rollback()
+		return false, err
+	}
+
+	if err := a.touchSessionSql(ctx, tx, current.FamilyID, ipAddress, userAgent); err != nil {
+		_ = tx.Rollback()
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("commit_rotate_refresh_token")
+		return false, x.WrapWithCode(err, x.CodeSQLTxCommit, "commit_rotate_refresh_token")
+	}
+
+	// The table already has the truth; a cache write that fails here only
+	// costs a database read on the next refresh.
+	if err := a.deleteRefreshTokenCache(ctx, current.TokenHash); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("delete_refresh_token_cache")
+	}
+
+	if err := a.storeRefreshTokenCache(ctx, next); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("store_refresh_token_cache")
+	}
+
+	return true, nil
+}
+
+// GetSession returns the session with any status. It fails with
+// CodeSQLRecordDoesNotExist for an unknown id.
+func (a *authRepository) GetSession(ctx context.Context, sessionID string) (*entity.Session, error) {
+	return a.getSessionSql(ctx, sessionID)
+}
+
+// ListSessions returns the user's sessions that are not revoked and still
+// hold a refresh token that can be exchanged, most recently seen first.
+func (a *authRepository) ListSessions(ctx context.Context, userID string) ([]entity.Session, error) {
+	return a.listActiveSessionsSql(ctx, userID)
+}
+
+// RevokeSession revokes the session and every refresh token in its family,
+// and rejects its access tokens until they would have expired anyway.
+func (a *authRepository) RevokeSession(ctx context.Context, sessionID string) error {
+	tx, err := a.db0.BeginTxx(ctx, nil)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("tx_revoke_session")
+		return x.WrapWithCode(err, x.CodeSQLTxBegin, "tx_revoke_session")
+	}
+
+	if _, err := a.revokeSessionsSql(ctx, tx, "RevokeSession", sessionID); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	hashes, err := a.revokeRefreshTokensSql(ctx, tx, "RevokeRefreshTokenFamily", sessionID)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("commit_revoke_session")
+		return x.WrapWithCode(err, x.CodeSQLTxCommit, "commit_revoke_session")
+	}
+
+	return a.clearSessionsCache(ctx, []string{sessionID}, hashes)
+}
+
+// RevokeAllSessions is RevokeSession for every session the user has. It
+// returns how many sessions were still active.
+func (a *authRepository) RevokeAllSessions(ctx context.Context, userID string) (int, error) {
+	tx, err := a.db0.BeginTxx(ctx, nil)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("tx_revoke_all_sessions")
+		return 0, x.WrapWithCode(err, x.CodeSQLTxBegin, "tx_revoke_all_sessions")
+	}
+
+	sessionIDs, hashes, err := a.revokeUserSessionsSql(ctx, tx, userID)
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("commit_revoke_all_sessions")
+		return 0, x.WrapWithCode(err, x.CodeSQLTxCommit, "commit_revoke_all_sessions")
+	}
+
+	return len(sessionIDs), a.clearSessionsCache(ctx, sessionIDs, hashes)
+}
+
+func (a *authRepository) GetLoginFailures(ctx context.Context, email string, ipAddress string) (*entity.LoginFailures, error) {
+	return a.getLoginFailuresCache(ctx, email, ipAddress)
+}
+
+func (a *authRepository) RecordLoginFailure(ctx context.Context, email string, ipAddress string, window time.Duration) (*entity.LoginFailures, error) {
+	return a.incrLoginFailuresCache(ctx, email, ipAddress, window)
+}
+
+func (a *authRepository) DelayLogin(ctx context.Context, email string, delay time.Duration) error {
+	return a.setLoginDelayCache(ctx, email, delay)
+}
+
+func (a *authRepository) ClearLoginFailures(ctx context.Context, email string) error {
+	return a.deleteLoginFailuresCache(ctx, email)
+}
+
+func (a *authRepository) LockAuthUser(ctx context.Context, userID string, until time.Time) error {
+	return a.lockUserSql(ctx, userID, until)
+}
+
+// StoreAuthToken saves the hash of a single-use token. Unused tokens the user
+// already holds for the same purpose are dropped, so only the latest link
+// works.
+func (a *authRepository) StoreAuthToken(ctx context.Context, userID string, purpose entity.AuthTokenPurpose, token string, expiresAt time.Time) error {
+	tx, err := a.db0.BeginTxx(ctx, nil)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("tx_store_auth_token")
+		return x.WrapWithCode(err, x.CodeSQLTxBegin, "tx_store_auth_token")
+	}
+
+	if err := a.deleteUnusedAuthTokensSql(ctx, tx, userID, purpose); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := a.storeAuthTokenSql(ctx, tx, userID, purpose, token, expiresAt); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("commit_store_auth_token")
+		return x.WrapWithCode(err, x.CodeSQLTxCommit, "commit_store_auth_token")
+	}
+
+	return nil
+}
+
+// VerifyEmail uses up an email verification token and marks the owner's
+// address verified. It returns the user id.
+func (a *authRepository) VerifyEmail(ctx context.Context, token string) (string, error) {
+	tx, err := a.db0.BeginTxx(ctx, nil)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("tx_verify_email")
+		return "", x.WrapWithCode(err, x.CodeSQLTxBegin, "tx_verify_email")
+	}
+
+	userID, err := a.consumeAuthTokenSql(ctx, tx, token, entity.TokenEmailVerification)
+	if err != nil {
+		_ = tx.Rollback()
+		return "", err
+	}
+
+	if err := a.markEmailVerifiedSql(ctx, tx, userID); err != nil {
+		_ = tx.Rollback()
+		return "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("commit_verify_email")
+		return "", x.WrapWithCode(err, x.CodeSQLTxCommit, "commit_verify_email")
+	}
+
+	return userID, nil
+}
+
+// FindAuthTokenUser returns the owner of a valid token without using it up.
+func (a *authRepository) FindAuthTokenUser(ctx context.Context, token string, purpose entity.AuthTokenPurpose) (string, error) {
+	return a.getAuthTokenUserSql(ctx, token, purpose)
+}
+
+// ResetPassword uses up a password reset token, sets the new password hash,
+// lifts any lockout and signs the user out everywhere. It returns the user id.
+func (a *authRepository) ResetPassword(ctx context.Context, token string, passwordHash string) (string, error) {
+	tx, err := a.db0.BeginTxx(ctx, nil)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("tx_reset_password")
+		return "", x.WrapWithCode(err, x.CodeSQLTxBegin, "tx_reset_password")
+	}
+
+	userID, err := a.consumeAuthTokenSql(ctx, tx, token, entity.TokenPasswordReset)
+	if err != nil {
+		_ = tx.Rollback()
+		return "", err
+	}
+
+	if err := a.updatePasswordSql(ctx, tx, userID, passwordHash); err != nil {
+		_ = tx.Rollback()
+		return "", err
+	}
+
+	sessionIDs, hashes, err := a.revokeUserSessionsSql(ctx, tx, userID)
+	if err != nil {
+		_ = tx.Rollback()
+		return "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("commit_reset_password")
+		return "", x.WrapWithCode(err, x.CodeSQLTxCommit, "commit_reset_password")
+	}
+
+	if err := a.clearSessionsCache(ctx, sessionIDs, hashes); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("clear_sessions_cache")
+	}
+
+	return userID, nil
+}
+
+// SaveMfaSecret stores a new secret for an enrolment that is not confirmed
+// yet. It fails with CodeSQLUniqueConstraint once MFA is enabled.
+func (a *authRepository) SaveMfaSecret(ctx context.Context, userID string, secret string) error {
+	return a.saveMfaSecretSql(ctx, userID, secret)
+}
+
+func (a *authRepository) GetUserMfa(ctx context.Context, userID string) (*entity.UserMfa, error) {
+	return a.getUserMfaSql(ctx, userID)
+}
+
+// EnableMfa confirms the enrolment, marking step used, and replaces the
+// user's recovery codes with recoveryCodes.
+func (a *authRepository) EnableMfa(ctx context.Context, userID string, step int64, recoveryCodes []string) error {
+	tx, err := a.db0.BeginTxx(ctx, nil)
 	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("tx_enable_mfa")
+		return x.WrapWithCode(err, x.CodeSQLTxBegin, "tx_enable_mfa")
+	}
+
+	if err := a.enableMfaSql(ctx, tx, userID, step); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := a.deleteMfaRecoveryCodesSql(ctx, tx, userID); err != nil {
+		_ = tx.Rollback()
 		return err
 	}
 
-	// Delete refresh tokens from DB
-	err = a.deleteRefreshTokenSql(ctx, userID)
+	for _, code := range recoveryCodes {
+		if err := a.storeMfaRecoveryCodeSql(ctx, tx, userID, code); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("commit_enable_mfa")
+		return x.WrapWithCode(err, x.CodeSQLTxCommit, "commit_enable_mfa")
+	}
+
+	return nil
+}
+
+func (a *authRepository) DisableMfa(ctx context.Context, userID string) error {
+	tx, err := a.db0.BeginTxx(ctx, nil)
 	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("tx_disable_mfa")
+		return x.WrapWithCode(err, x.CodeSQLTxBegin, "tx_disable_mfa")
+	}
+
+	if err := a.deleteMfaRecoveryCodesSql(ctx, tx, userID); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := a.deleteUserMfaSql(ctx, tx, userID); err != nil {
+		_ = tx.Rollback()
 		return err
 	}
 
+	if err := tx.Commit(); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("commit_disable_mfa")
+		return x.WrapWithCode(err, x.CodeSQLTxCommit, "commit_disable_mfa")
+	}
+
 	return nil
 }
 
-func (a *authRepository) RotateRefreshToken(ctx context.Context, userID string, oldRefreshToken string, newRefreshToken string, expired time.Time) error {
-	err := a.deleteRefreshTokenByTokenSql(ctx, oldRefreshToken)
+func (a *authRepository) UseMfaStep(ctx context.Context, userID string, step int64) (bool, error) {
+	return a.useMfaStepSql(ctx, userID, step)
+}
+
+func (a *authRepository) UseMfaRecoveryCode(ctx context.Context, userID string, code string) (bool, error) {
+	return a.useMfaRecoveryCodeSql(ctx, userID, code)
+}
+
+func (a *authRepository) StoreMfaChallenge(ctx context.Context, token string, challenge *entity.MfaChallenge, ttl time.Duration) error {
+	return a.storeMfaChallengeCache(ctx, token, challenge, ttl)
+}
+
+// GetMfaChallenge fails with CodeCacheNotFound for an unknown or expired
+// token.
+func (a *authRepository) GetMfaChallenge(ctx context.Context, token string) (*entity.MfaChallenge, error) {
+	return a.getMfaChallengeCache(ctx, token)
+}
+
+func (a *authRepository) RecordMfaFailure(ctx context.Context, token string) (int64, error) {
+	return a.incrMfaAttemptsCache(ctx, token)
+}
+
+func (a *authRepository) ConsumeMfaChallenge(ctx context.Context, token string) (bool, error) {
+	return a.deleteMfaChallengeCache(ctx, token)
+}
+
+// FindUserIdentity fails with CodeSQLRecordDoesNotExist if the provider
+// account was never linked.
+func (a *authRepository) FindUserIdentity(ctx context.Context, provider string, subject string) (*entity.UserIdentity, error) {
+	return a.getUserIdentitySql(ctx, provider, subject)
+}
+
+func (a *authRepository) TouchUserIdentity(ctx context.Context, identityID string, email string) error {
+	return a.touchUserIdentitySql(ctx, identityID, email)
+}
+
+// LinkUserIdentity links identity to the existing user identity.UserID. The
+// provider vouched for the address, so it also counts as verified.
+func (a *authRepository) LinkUserIdentity(ctx context.Context, identity *entity.UserIdentity) error {
+	tx, err := a.db0.BeginTxx(ctx, nil)
 	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("tx_link_user_identity")
+		return x.WrapWithCode(err, x.CodeSQLTxBegin, "tx_link_user_identity")
+	}
+
+	if err := a.saveUserIdentitySql(ctx, tx, identity); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := a.markEmailVerifiedSql(ctx, tx, identity.UserID); err != nil {
+		_ = tx.Rollback()
 		return err
 	}
 
-	err = a.storeRefreshTokenSql(ctx, userID, newRefreshToken, expired)
+	if err := tx.Commit(); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("commit_link_user_identity")
+		return x.WrapWithCode(err, x.CodeSQLTxCommit, "commit_link_user_identity")
+	}
+
+	return nil
+}
+
+// CreateOAuthUser registers a user who signed in through identity. The
+// account has a verified email and no password; the user can set one through
+// password reset. It returns the new user id.
+func (a *authRepository) CreateOAuthUser(ctx context.Context, identity *entity.UserIdentity) (string, error) {
+	tx, err := a.db0.BeginTxx(ctx, nil)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("tx_create_oauth_user")
+		return "", x.WrapWithCode(err, x.CodeSQLTxBegin, "tx_create_oauth_user")
+	}
+
+	authID, err := a.saveUserSql(ctx, tx, identity.Email, "")
 	if err != nil {
+		_ = tx.Rollback()
+		return "", err
+	}
+
+	if err := a.markEmailVerifiedSql(ctx, tx, authID); err != nil {
+		_ = tx.Rollback()
+		return "", err
+	}
+
+	if err := a.assignUserRoleSql(ctx, tx, authID, entity.RoleCustomer); err != nil {
+		_ = tx.Rollback()
+		return "", err
+	}
+
+	identity.UserID = authID
+	if err := a.saveUserIdentitySql(ctx, tx, identity); err != nil {
+		_ = tx.Rollback()
+		return "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("commit_create_oauth_user")
+		return "", x.WrapWithCode(err, x.CodeSQLTxCommit, "commit_create_oauth_user")
+	}
+
+	return authID, nil
+}
+
+func (a *authRepository) StoreOAuthState(ctx context.Context, state string, oauthState *entity.OAuthState, ttl time.Duration) error {
+	return a.storeOAuthStateCache(ctx, state, oauthState, ttl)
+}
+
+// ConsumeOAuthState returns and forgets a started login. It fails with
+// CodeCacheNotFound for an unknown, expired or already used state.
+func (a *authRepository) ConsumeOAuthState(ctx context.Context, state string) (*entity.OAuthState, error) {
+	return a.takeOAuthStateCache(ctx, state)
+}
+
+// RehashPassword replaces a password hash made with outdated parameters. It
+// only applies while the stored hash is still oldHash, so a password changed
+// in the meantime is never overwritten.
+func (a *authRepository) RehashPassword(ctx context.Context, userID string, oldHash string, newHash string) error {
+	return a.rehashPasswordSql(ctx, userID, oldHash, newHash)
+}
+
+// CreateApiKey stores the hash of key for apiKey and fills in its id and
+// creation time.
+func (a *authRepository) CreateApiKey(ctx context.Context, apiKey *entity.ApiKey, key string) error {
+	return a.createApiKeySql(ctx, a.db0, apiKey, key)
+}
+
+func (a *authRepository) GetApiKey(ctx context.Context, keyID string) (*entity.ApiKey, error) {
+	return a.getApiKeySql(ctx, "GetApiKeyByID", keyID)
+}
+
+// FindApiKey looks a key up by its hash, whatever its state.
+func (a *authRepository) FindApiKey(ctx context.Context, key string) (*entity.ApiKey, error) {
+	return a.getApiKeySql(ctx, "GetApiKeyByHash", hashToken(key))
+}
+
+// ListApiKeys returns the user's keys that are neither revoked nor expired,
+// newest first.
+func (a *authRepository) ListApiKeys(ctx context.Context, userID string) ([]entity.ApiKey, error) {
+	return a.listApiKeysSql(ctx, userID)
+}
+
+// RotateApiKey stores next as the replacement of current and makes current
+// expire at expireCurrentAt, unless it expires sooner anyway.
+func (a *authRepository) RotateApiKey(ctx context.Context, current *entity.ApiKey, next *entity.ApiKey, key string, expireCurrentAt time.Time) error {
+	tx, err := a.db0.BeginTxx(ctx, nil)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("tx_rotate_api_key")
+		return x.WrapWithCode(err, x.CodeSQLTxBegin, "tx_rotate_api_key")
+	}
+
+	if err := a.createApiKeySql(ctx, tx, next, key); err != nil {
+		_ = tx.Rollback()
 		return err
 	}
 
-	hashedOldToken := hashToken(oldRefreshToken)
-	hashedNewToken := hashToken(newRefreshToken)
-	a.redis0.Del(ctx, fmt.Sprintf("refresh:%s", hashedOldToken))
-	a.redis0.Set(ctx, fmt.Sprintf("refresh:%s", hashedNewToken), userID, time.Hour*24*7)
+	if err := a.expireApiKeySql(ctx, tx, current.ID, expireCurrentAt); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("commit_rotate_api_key")
+		return x.WrapWithCode(err, x.CodeSQLTxCommit, "commit_rotate_api_key")
+	}
 
 	return nil
 }
+
+func (a *authRepository) RevokeApiKey(ctx context.Context, keyID string) error {
+	return a.revokeApiKeySql(ctx, keyID)
+}
+
+// TouchApiKey records that the key was used. It writes at most once per
+// interval so busy clients do not turn every request into an update.
+func (a *authRepository) TouchApiKey(ctx context.Context, keyID string, interval time.Duration) error {
+	return a.touchApiKeySql(ctx, keyID, interval)
+}
@@ -4,10 +4,17 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/model/entity"
 	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+
+	"github.com/redis/go-redis/v9"
 )
 
 func hashToken(token string) string {
@@ -15,17 +22,23 @@ func hashToken(token string) string {
 	return hex.EncodeToString(hash[:])
 }
 
-func (a *authRepository) storeSessionCache(ctx context.Context, userID string, refreshToken string, email string, ipAddress string) error {
-	sessionKey := fmt.Sprintf("session:%s", userID)
-	sessionData := fmt.Sprintf(`{"user_id":"%s","email":"%s","ip":"%s"}`, userID, email, ipAddress)
+func sessionKey(sessionID string) string {
+	return fmt.Sprintf("session:%s", sessionID)
+}
 
-	if err := a.redis0.Set(ctx, sessionKey, sessionData, time.Hour*1).Err(); err != nil {
-		return x.WrapWithCode(err, x.CodeCacheSetHashKey, "set_cache_session_user")
+func (a *authRepository) storeSessionCache(ctx context.Context, session *entity.Session, email string) error {
+	sessionData, err := json.Marshal(map[string]string{
+		"user_id":    session.UserID,
+		"email":      email,
+		"ip":         session.IPAddress,
+		"user_agent": session.UserAgent,
+	})
+	if err != nil {
+		return x.Wrap(err, "marshal_session_cache")
 	}
 
-	hashedToken := hashToken(refreshToken)
-	if err := a.redis0.Set(ctx, fmt.Sprintf("refresh:%s", hashedToken), userID, time.Hour*24*7).Err(); err != nil {
-		return x.WrapWithCode(err, x.CodeCacheSetHashKey, "set_cache_refresh_token_user")
+	if err := a.redis0.Set(ctx, sessionKey(session.ID), sessionData, time.Hour*1).Err(); err != nil {
+		return x.WrapWithCode(err, x.CodeCacheSetHashKey, "set_cache_session_user")
 	}
 
 	return nil
@@ -37,14 +50,102 @@ func (a *authRepository) findTokenIDCache(ctx context.Context, tokenID string) b
 	return exists > 0
 }
 
-func (a *authRepository) findRefreshTokenCache(ctx context.Context, refreshToken string) (string, error) {
-	hashedToken := hashToken(refreshToken)
-	userID, err := a.redis0.Get(ctx, fmt.Sprintf("refresh:%s", hashedToken)).Result()
+func refreshTokenKey(hashedToken string) string {
+	return fmt.Sprintf("refresh:%s", hashedToken)
+}
+
+func revokedSessionKey(sessionID string) string {
+	return fmt.Sprintf("revoked_session:%s", sessionID)
+}
+
+// storeRefreshTokenCache caches a token that can still be exchanged. Used
+// and revoked tokens are never cached, so a miss always goes to the table.
+func (a *authRepository) storeRefreshTokenCache(ctx context.Context, token *entity.RefreshToken) error {
+	ttl := time.Until(token.ExpiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(token)
 	if err != nil {
-		return userID, x.WrapWithCode(err, x.CodeCacheGetSimpleKey, "Invalid refresh token")
+		return x.Wrap(err, "marshal_refresh_token_cache")
+	}
+
+	if err := a.redis0.Set(ctx, refreshTokenKey(token.TokenHash), data, ttl).Err(); err != nil {
+		return x.WrapWithCode(err, x.CodeCacheSetSimpleKey, "set_cache_refresh_token")
+	}
+
+	return nil
+}
+
+func (a *authRepository) getRefreshTokenCache(ctx context.Context, hashedToken string) (*entity.RefreshToken, error) {
+	data, err := a.redis0.Get(ctx, refreshTokenKey(hashedToken)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, x.NewWithCode(x.CodeCacheNotFound, "refresh_token_not_cached")
+		}
+		return nil, x.WrapWithCode(err, x.CodeCacheGetSimpleKey, "get_cache_refresh_token")
+	}
+
+	var token entity.RefreshToken
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, x.Wrap(err, "unmarshal_refresh_token_cache")
+	}
+	token.TokenHash = hashedToken
+
+	return &token, nil
+}
+
+func (a *authRepository) deleteRefreshTokenCache(ctx context.Context, hashedTokens ...string) error {
+	if len(hashedTokens) == 0 {
+		return nil
+	}
+
+	keys := make([]string, len(hashedTokens))
+	for i, hashedToken := range hashedTokens {
+		keys[i] = refreshTokenKey(hashedToken)
+	}
+
+	if err := a.redis0.Del(ctx, keys...).Err(); err != nil {
+		return x.WrapWithCode(err, x.CodeCacheDeleteSimpleKey, "delete_cache_refresh_token")
 	}
 
-	return userID, nil
+	return nil
+}
+
+// revokeSessionCache rejects access tokens issued to the sessions. Like a
+// blacklisted token id the marker only has to outlive the access token.
+func (a *authRepository) revokeSessionCache(ctx context.Context, sessionIDs ...string) error {
+	if len(sessionIDs) == 0 {
+		return nil
+	}
+
+	pipe := a.redis0.Pipeline()
+	for _, sessionID := range sessionIDs {
+		pipe.Set(ctx, revokedSessionKey(sessionID), "revoked", time.Hour*1)
+		pipe.Del(ctx, sessionKey(sessionID))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return x.WrapWithCode(err, x.CodeCacheSetSimpleKey, "revoke_session_cache")
+	}
+
+	return nil
+}
+
+// clearSessionsCache drops what the cache holds for revoked sessions and
+// refresh tokens.
+func (a *authRepository) clearSessionsCache(ctx context.Context, sessionIDs []string, hashedTokens []string) error {
+	if err := a.revokeSessionCache(ctx, sessionIDs...); err != nil {
+		return err
+	}
+
+	return a.deleteRefreshTokenCache(ctx, hashedTokens...)
+}
+
+func (a *authRepository) findRevokedSessionCache(ctx context.Context, sessionID string) bool {
+	exists, _ := a.redis0.Exists(ctx, revokedSessionKey(sessionID)).Result()
+
+	return exists > 0
 }
 
 func (a *authRepository) blacklistTokenCache(ctx context.Context, tokenID string) error {
@@ -55,10 +156,198 @@ func (a *authRepository) blacklistTokenCache(ctx context.Context, tokenID string
 	return nil
 }
 
-func (a *authRepository) deleteSessionCache(ctx context.Context, userID string) error {
-	if err := a.redis0.Del(ctx, fmt.Sprintf("session:%s", userID)).Err(); err != nil {
-		return x.WrapWithCode(err, x.CodeCacheDeleteSimpleKey, "delete_session_cache")
+func loginFailuresEmailKey(email string) string {
+	return fmt.Sprintf("login_failures:email:%s", strings.ToLower(email))
+}
+
+func loginFailuresIPKey(ipAddress string) string {
+	return fmt.Sprintf("login_failures:ip:%s", ipAddress)
+}
+
+func loginDelayKey(email string) string {
+	return fmt.Sprintf("login_delay:%s", strings.ToLower(email))
+}
+
+func (a *authRepository) getLoginFailuresCache(ctx context.Context, email string, ipAddress string) (*entity.LoginFailures, error) {
+	pipe := a.redis0.Pipeline()
+	emailCmd := pipe.Get(ctx, loginFailuresEmailKey(email))
+	delayCmd := pipe.PTTL(ctx, loginDelayKey(email))
+	var ipCmd *redis.StringCmd
+	if ipAddress != "" {
+		ipCmd = pipe.Get(ctx, loginFailuresIPKey(ipAddress))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, x.WrapWithCode(err, x.CodeCacheGetSimpleKey, "get_login_failures_cache")
+	}
+
+	failures := &entity.LoginFailures{}
+	failures.Email, _ = emailCmd.Int64()
+	if ipCmd != nil {
+		failures.IP, _ = ipCmd.Int64()
+	}
+	if ttl := delayCmd.Val(); ttl > 0 {
+		failures.RetryAt = time.Now().Add(ttl)
+	}
+
+	return failures, nil
+}
+
+// incrLoginFailuresCache counts one failure against the email and, when
+// known, the IP. Each failure pushes the expiry out by window, so counters
+// reset once an address has been quiet for that long.
+func (a *authRepository) incrLoginFailuresCache(ctx context.Context, email string, ipAddress string, window time.Duration) (*entity.LoginFailures, error) {
+	pipe := a.redis0.TxPipeline()
+	emailCmd := pipe.Incr(ctx, loginFailuresEmailKey(email))
+	pipe.Expire(ctx, loginFailuresEmailKey(email), window)
+	var ipCmd *redis.IntCmd
+	if ipAddress != "" {
+		ipCmd = pipe.Incr(ctx, loginFailuresIPKey(ipAddress))
+		pipe.Expire(ctx, loginFailuresIPKey(ipAddress), window)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, x.WrapWithCode(err, x.CodeCacheSetSimpleKey, "incr_login_failures_cache")
+	}
+
+	failures := &entity.LoginFailures{Email: emailCmd.Val()}
+	if ipCmd != nil {
+		failures.IP = ipCmd.Val()
+	}
+
+	return failures, nil
+}
+
+func (a *authRepository) setLoginDelayCache(ctx context.Context, email string, delay time.Duration) error {
+	if err := a.redis0.Set(ctx, loginDelayKey(email), "1", delay).Err(); err != nil {
+		return x.WrapWithCode(err, x.CodeCacheSetSimpleKey, "set_login_delay_cache")
 	}
 
 	return nil
 }
+
+func (a *authRepository) deleteLoginFailuresCache(ctx context.Context, email string) error {
+	if err := a.redis0.Del(ctx, loginFailuresEmailKey(email), loginDelayKey(email)).Err(); err != nil {
+		return x.WrapWithCode(err, x.CodeCacheDeleteSimpleKey, "delete_login_failures_cache")
+	}
+
+	return nil
+}
+
+func mfaChallengeKey(token string) string {
+	return fmt.Sprintf("mfa_challenge:%s", hashToken(token))
+}
+
+func (a *authRepository) storeMfaChallengeCache(ctx context.Context, token string, challenge *entity.MfaChallenge, ttl time.Duration) error {
+	key := mfaChallengeKey(token)
+
+	pipe := a.redis0.TxPipeline()
+	pipe.HSet(ctx, key, map[string]any{
+		"user_id":    challenge.UserID,
+		"email":      challenge.Email,
+		"ip":         challenge.IPAddress,
+		"user_agent": challenge.UserAgent,
+		"attempts":   0,
+	})
+	pipe.Expire(ctx, key, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return x.WrapWithCode(err, x.CodeCacheSetHashKey, "store_mfa_challenge_cache")
+	}
+
+	return nil
+}
+
+func (a *authRepository) getMfaChallengeCache(ctx context.Context, token string) (*entity.MfaChallenge, error) {
+	values, err := a.redis0.HGetAll(ctx, mfaChallengeKey(token)).Result()
+	if err != nil {
+		return nil, x.WrapWithCode(err, x.CodeCacheGetHashKey, "get_mfa_challenge_cache")
+	}
+
+	if values["user_id"] == "" {
+		return nil, x.NewWithCode(x.CodeCacheNotFound, "mfa_challenge_not_found")
+	}
+
+	attempts, _ := strconv.ParseInt(values["attempts"], 10, 64)
+
+	return &entity.MfaChallenge{
+		UserID:    values["user_id"],
+		Email:     values["email"],
+		IPAddress: values["ip"],
+		UserAgent: values["user_agent"],
+		Attempts:  attempts,
+	}, nil
+}
+
+func (a *authRepository) incrMfaAttemptsCache(ctx context.Context, token string) (int64, error) {
+	key := mfaChallengeKey(token)
+
+	pipe := a.redis0.TxPipeline()
+	attemptsCmd := pipe.HIncrBy(ctx, key, "attempts", 1)
+	ttlCmd := pipe.TTL(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, x.WrapWithCode(err, x.CodeCacheSetHashKey, "incr_mfa_attempts_cache")
+	}
+
+	// The challenge expired in between and HIncrBy recreated it without a
+	// TTL; drop the stray key
+	if ttlCmd.Val() < 0 {
+		a.redis0.Del(ctx, key)
+		return 0, x.NewWithCode(x.CodeCacheNotFound, "mfa_challenge_not_found")
+	}
+
+	return attemptsCmd.Val(), nil
+}
+
+// deleteMfaChallengeCache reports whether this call removed the challenge,
+// so of two concurrent successful verifications only one wins.
+func (a *authRepository) deleteMfaChallengeCache(ctx context.Context, token string) (bool, error) {
+	deleted, err := a.redis0.Del(ctx, mfaChallengeKey(token)).Result()
+	if err != nil {
+		return false, x.WrapWithCode(err, x.CodeCacheDeleteHashKey, "delete_mfa_challenge_cache")
+	}
+
+	return deleted == 1, nil
+}
+
+func oauthStateKey(state string) string {
+	return fmt.Sprintf("oauth_state:%s", hashToken(state))
+}
+
+func (a *authRepository) storeOAuthStateCache(ctx context.Context, state string, oauthState *entity.OAuthState, ttl time.Duration) error {
+	key := oauthStateKey(state)
+
+	pipe := a.redis0.TxPipeline()
+	pipe.HSet(ctx, key, map[string]any{
+		"provider":      oauthState.Provider,
+		"code_verifier": oauthState.CodeVerifier,
+		"nonce":         oauthState.Nonce,
+	})
+	pipe.Expire(ctx, key, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return x.WrapWithCode(err, x.CodeCacheSetHashKey, "store_oauth_state_cache")
+	}
+
+	return nil
+}
+
+// takeOAuthStateCache reads and deletes the state in one transaction, so a
+// callback can only be completed once.
+func (a *authRepository) takeOAuthStateCache(ctx context.Context, state string) (*entity.OAuthState, error) {
+	key := oauthStateKey(state)
+
+	pipe := a.redis0.TxPipeline()
+	valuesCmd := pipe.HGetAll(ctx, key)
+	pipe.Del(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, x.WrapWithCode(err, x.CodeCacheGetHashKey, "take_oauth_state_cache")
+	}
+
+	values := valuesCmd.Val()
+	if values["provider"] == "" {
+		return nil, x.NewWithCode(x.CodeCacheNotFound, "oauth_state_not_found")
+	}
+
+	return &entity.OAuthState{
+		Provider:     values["provider"],
+		CodeVerifier: values["code_verifier"],
+		Nonce:        values["nonce"],
+	}, nil
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/model/entity"
 	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/rs/zerolog"
 )
 
@@ -29,11 +30,11 @@ func (a *authRepository) checkUserExistSql(ctx context.Context, email string) (b
 	return emailExists, nil
 }
 
-func (a *authRepository) saveUserSql(ctx context.Context, email string, hashedPassword []byte) (string, error) {
+func (a *authRepository) saveUserSql(ctx context.Context, tx *sqlx.Tx, email string, passwordHash string) (string, error) {
 	var authID string
 
 	query, _ := a.queryLoader.Get("SaveUSer")
-	err := a.db0.QueryRowContext(ctx, query, email, string(hashedPassword)).Scan(&authID)
+	err := tx.QueryRowContext(ctx, query, email, passwordHash).Scan(&authID)
 	if err != nil {
 		zerolog.Ctx(ctx).Error().Err(err).Msg("save_user_sql")
 		return authID, x.WrapWithCode(err, x.CodeSQLCreate, "save_user_sql")
@@ -42,6 +43,35 @@ func (a *authRepository) saveUserSql(ctx context.Context, email string, hashedPa
 	return authID, nil
 }
 
+func (a *authRepository) assignUserRoleSql(ctx context.Context, tx *sqlx.Tx, userID string, role string) error {
+	query, _ := a.queryLoader.Get("AssignUserRole")
+	_, err := tx.ExecContext(ctx, query, userID, role)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("assign_user_role_sql")
+		return x.WrapWithCode(err, x.CodeSQLCreate, "assign_user_role_sql")
+	}
+
+	return nil
+}
+
+func (a *authRepository) getUserRolesSql(ctx context.Context, userID string) (*entity.UserRoles, error) {
+	userRoles := &entity.UserRoles{Roles: []string{}, Permissions: []string{}}
+
+	query, _ := a.queryLoader.Get("GetUserRoles")
+	if err := a.db0.SelectContext(ctx, &userRoles.Roles, query, userID); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("get_user_roles_sql")
+		return nil, x.WrapWithCode(err, x.CodeSQLRead, "get_user_roles_sql")
+	}
+
+	query, _ = a.queryLoader.Get("GetUserPermissions")
+	if err := a.db0.SelectContext(ctx, &userRoles.Permissions, query, userID); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("get_user_permissions_sql")
+		return nil, x.WrapWithCode(err, x.CodeSQLRead, "get_user_permissions_sql")
+	}
+
+	return userRoles, nil
+}
+
 func (a *authRepository) getUserByEmailSql(ctx context.Context, email string) (*entity.UserAuth, error) {
 	var userAuth entity.UserAuth
 
@@ -60,9 +90,10 @@ func (a *authRepository) getUserByEmailSql(ctx context.Context, email string) (*
 	return &userAuth, nil
 }
 
-func (a *authRepository) storeRefreshTokenSql(ctx context.Context, userID string, refreshToken string, expired time.Time) error {
+// storeRefreshTokenSql saves the hash of token and fills in its id.
+func (a *authRepository) storeRefreshTokenSql(ctx context.Context, tx *sqlx.Tx, token *entity.RefreshToken) error {
 	query, _ := a.queryLoader.Get("StoreRefreshToken")
-	_, err := a.db0.ExecContext(ctx, query, userID, refreshToken, expired)
+	err := tx.QueryRowxContext(ctx, query, token.UserID, token.TokenHash, token.FamilyID, token.ParentID, token.ExpiresAt).Scan(&token.ID)
 	if err != nil {
 		zerolog.Ctx(ctx).Error().Err(err).Msg("store_refresh_token_sql")
 		return x.WrapWithCode(err, x.CodeSQLCreate, "store_refresh_token_sql")
@@ -71,11 +102,149 @@ func (a *authRepository) storeRefreshTokenSql(ctx context.Context, userID string
 	return nil
 }
 
+func (a *authRepository) getRefreshTokenSql(ctx context.Context, hashedToken string) (*entity.RefreshToken, error) {
+	var token entity.RefreshToken
+
+	query, _ := a.queryLoader.Get("GetRefreshTokenByHash")
+	err := a.db0.QueryRowxContext(ctx, query, hashedToken).StructScan(&token)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("get_refresh_token_sql")
+
+		if err == sql.ErrNoRows {
+			return nil, x.WrapWithCode(err, x.CodeSQLRecordDoesNotExist, "get_refresh_token_sql")
+		}
+
+		return nil, x.WrapWithCode(err, x.CodeSQLRead, "get_refresh_token_sql")
+	}
+
+	return &token, nil
+}
+
+// useRefreshTokenSql marks the token used. It reports false when the token
+// was already used, revoked or has expired, which makes it the point where
+// two concurrent rotations of the same token are told apart.
+func (a *authRepository) useRefreshTokenSql(ctx context.Context, tx *sqlx.Tx, tokenID string) (bool, error) {
+	query, _ := a.queryLoader.Get("UseRefreshToken")
+	result, err := tx.ExecContext(ctx, query, tokenID)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("use_refresh_token_sql")
+		return false, x.WrapWithCode(err, x.CodeSQLUpdate, "use_refresh_token_sql")
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, x.WrapWithCode(err, x.CodeSQLCannotRetrieveAffectedRows, "use_refresh_token_sql")
+	}
+
+	return affected == 1, nil
+}
+
+func (a *authRepository) createSessionSql(ctx context.Context, tx *sqlx.Tx, session *entity.Session) error {
+	query, _ := a.queryLoader.Get("CreateSession")
+	err := tx.QueryRowxContext(ctx, query, session.UserID, session.IPAddress, session.UserAgent).Scan(&session.ID, &session.CreatedAt, &session.LastSeenAt)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("create_session_sql")
+		return x.WrapWithCode(err, x.CodeSQLCreate, "create_session_sql")
+	}
+
+	return nil
+}
+
+// touchSessionSql moves last_seen_at and records the latest IP and user
+// agent; empty values keep the old ones.
+func (a *authRepository) touchSessionSql(ctx context.Context, tx *sqlx.Tx, sessionID string, ipAddress string, userAgent string) error {
+	query, _ := a.queryLoader.Get("TouchSession")
+	_, err := tx.ExecContext(ctx, query, sessionID, ipAddress, userAgent)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("touch_session_sql")
+		return x.WrapWithCode(err, x.CodeSQLUpdate, "touch_session_sql")
+	}
+
+	return nil
+}
+
+func (a *authRepository) getSessionSql(ctx context.Context, sessionID string) (*entity.Session, error) {
+	var session entity.Session
+
+	query, _ := a.queryLoader.Get("GetSession")
+	err := a.db0.QueryRowxContext(ctx, query, sessionID).StructScan(&session)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("get_session_sql")
+
+		if err == sql.ErrNoRows {
+			return nil, x.WrapWithCode(err, x.CodeSQLRecordDoesNotExist, "get_session_sql")
+		}
+
+		return nil, x.WrapWithCode(err, x.CodeSQLRead, "get_session_sql")
+	}
+
+	return &session, nil
+}
+
+func (a *authRepository) listActiveSessionsSql(ctx context.Context, userID string) ([]entity.Session, error) {
+	sessions := []entity.Session{}
+
+	query, _ := a.queryLoader.Get("ListActiveSessions")
+	err := a.db0.SelectContext(ctx, &sessions, query, userID)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("list_active_sessions_sql")
+		return nil, x.WrapWithCode(err, x.CodeSQLRead, "list_active_sessions_sql")
+	}
+
+	return sessions, nil
+}
+
+// revokeSessionsSql revokes the sessions selected by queryName and returns
+// their ids.
+func (a *authRepository) revokeSessionsSql(ctx context.Context, tx *sqlx.Tx, queryName string, id string) ([]string, error) {
+	var sessionIDs []string
+
+	query, _ := a.queryLoader.Get(queryName)
+	err := tx.SelectContext(ctx, &sessionIDs, query, id)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("query", queryName).Msg("revoke_sessions_sql")
+		return nil, x.WrapWithCode(err, x.CodeSQLUpdate, "revoke_sessions_sql")
+	}
+
+	return sessionIDs, nil
+}
+
+// revokeUserSessionsSql revokes all of the user's sessions and refresh
+// tokens, returning the session ids and token hashes for the cache.
+func (a *authRepository) revokeUserSessionsSql(ctx context.Context, tx *sqlx.Tx, userID string) ([]string, []string, error) {
+	sessionIDs, err := a.revokeSessionsSql(ctx, tx, "RevokeUserSessions", userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hashes, err := a.revokeRefreshTokensSql(ctx, tx, "RevokeUserRefreshTokens", userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return sessionIDs, hashes, nil
+}
+
+// revokeRefreshTokensSql revokes the tokens selected by queryName and
+// returns their hashes so the cached copies can be dropped.
+func (a *authRepository) revokeRefreshTokensSql(ctx context.Context, tx *sqlx.Tx, queryName string, id string) ([]string, error) {
+	var hashes []string
+
+	query, _ := a.queryLoader.Get(queryName)
+	err := tx.SelectContext(ctx, &hashes, query, id)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("query", queryName).Msg("revoke_refresh_tokens_sql")
+		return nil, x.WrapWithCode(err, x.CodeSQLUpdate, "revoke_refresh_tokens_sql")
+	}
+
+	return hashes, nil
+}
+
 func (a *authRepository) getUserByIDSql(ctx context.Context, userID string) (*entity.UserAuth, error) {
 	var userAuth entity.UserAuth
 
 	query, _ := a.queryLoader.Get("GetUserWithID")
-	err := a.db0.QueryRowContext(ctx, query, userID).Scan(&userAuth)
+	err := a.db0.QueryRowxContext(ctx, query, userID).StructScan(&userAuth)
 	if err != nil {
 		zerolog.Ctx(ctx).Error().Err(err).Msg("get_user_by_id_sql")
 
@@ -89,24 +258,336 @@ func (a *authRepository) getUserByIDSql(ctx context.Context, userID string) (*en
 	return &userAuth, nil
 }
 
-func (a *authRepository) deleteRefreshTokenSql(ctx context.Context, userID string) error {
-	query, _ := a.queryLoader.Get("DeleteRefreshToken")
-	_, err := a.db0.ExecContext(ctx, query, userID)
+func (a *authRepository) lockUserSql(ctx context.Context, userID string, until time.Time) error {
+	query, _ := a.queryLoader.Get("LockUser")
+	_, err := a.db0.ExecContext(ctx, query, userID, until)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("lock_user_sql")
+		return x.WrapWithCode(err, x.CodeSQLUpdate, "lock_user_sql")
+	}
+
+	return nil
+}
+
+func (a *authRepository) deleteUnusedAuthTokensSql(ctx context.Context, tx *sqlx.Tx, userID string, purpose entity.AuthTokenPurpose) error {
+	query, _ := a.queryLoader.Get("DeleteUnusedAuthTokens")
+	_, err := tx.ExecContext(ctx, query, userID, purpose)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("delete_unused_auth_tokens_sql")
+		return x.WrapWithCode(err, x.CodeSQLDelete, "delete_unused_auth_tokens_sql")
+	}
+
+	return nil
+}
+
+func (a *authRepository) storeAuthTokenSql(ctx context.Context, tx *sqlx.Tx, userID string, purpose entity.AuthTokenPurpose, token string, expiresAt time.Time) error {
+	query, _ := a.queryLoader.Get("StoreAuthToken")
+	_, err := tx.ExecContext(ctx, query, userID, purpose, hashToken(token), expiresAt)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("store_auth_token_sql")
+		return x.WrapWithCode(err, x.CodeSQLCreate, "store_auth_token_sql")
+	}
+
+	return nil
+}
+
+func (a *authRepository) getAuthTokenUserSql(ctx context.Context, token string, purpose entity.AuthTokenPurpose) (string, error) {
+	var userID string
+
+	query, _ := a.queryLoader.Get("GetAuthTokenUser")
+	err := a.db0.QueryRowxContext(ctx, query, hashToken(token), purpose).Scan(&userID)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("purpose", string(purpose)).Msg("get_auth_token_user_sql")
+
+		if err == sql.ErrNoRows {
+			return "", x.WrapWithCode(err, x.CodeSQLRecordDoesNotExist, "get_auth_token_user_sql")
+		}
+
+		return "", x.WrapWithCode(err, x.CodeSQLRead, "get_auth_token_user_sql")
+	}
+
+	return userID, nil
+}
+
+// consumeAuthTokenSql marks a token used and returns its owner. An unknown,
+// expired or already used token matches no row.
+func (a *authRepository) consumeAuthTokenSql(ctx context.Context, tx *sqlx.Tx, token string, purpose entity.AuthTokenPurpose) (string, error) {
+	var userID string
+
+	query, _ := a.queryLoader.Get("ConsumeAuthToken")
+	err := tx.QueryRowxContext(ctx, query, hashToken(token), purpose).Scan(&userID)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("purpose", string(purpose)).Msg("consume_auth_token_sql")
+
+		if err == sql.ErrNoRows {
+			return "", x.WrapWithCode(err, x.CodeSQLRecordDoesNotExist, "consume_auth_token_sql")
+		}
+
+		return "", x.WrapWithCode(err, x.CodeSQLUpdate, "consume_auth_token_sql")
+	}
+
+	return userID, nil
+}
+
+func (a *authRepository) markEmailVerifiedSql(ctx context.Context, tx *sqlx.Tx, userID string) error {
+	query, _ := a.queryLoader.Get("MarkEmailVerified")
+	_, err := tx.ExecContext(ctx, query, userID)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("mark_email_verified_sql")
+		return x.WrapWithCode(err, x.CodeSQLUpdate, "mark_email_verified_sql")
+	}
+
+	return nil
+}
+
+func (a *authRepository) updatePasswordSql(ctx context.Context, tx *sqlx.Tx, userID string, passwordHash string) error {
+	query, _ := a.queryLoader.Get("UpdatePassword")
+	_, err := tx.ExecContext(ctx, query, userID, passwordHash)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("update_password_sql")
+		return x.WrapWithCode(err, x.CodeSQLUpdate, "update_password_sql")
+	}
+
+	return nil
+}
+
+func (a *authRepository) rehashPasswordSql(ctx context.Context, userID string, oldHash string, newHash string) error {
+	query, _ := a.queryLoader.Get("RehashPassword")
+	_, err := a.db0.ExecContext(ctx, query, userID, oldHash, newHash)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("rehash_password_sql")
+		return x.WrapWithCode(err, x.CodeSQLUpdate, "rehash_password_sql")
+	}
+
+	return nil
+}
+
+func (a *authRepository) saveMfaSecretSql(ctx context.Context, userID string, secret string) error {
+	query, _ := a.queryLoader.Get("SaveMfaSecret")
+	result, err := a.db0.ExecContext(ctx, query, userID, secret)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("save_mfa_secret_sql")
+		return x.WrapWithCode(err, x.CodeSQLCreate, "save_mfa_secret_sql")
+	}
+
+	// The upsert skips rows that are already enabled
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return x.NewWithCode(x.CodeSQLUniqueConstraint, "mfa_already_enabled")
+	}
+
+	return nil
+}
+
+func (a *authRepository) getUserMfaSql(ctx context.Context, userID string) (*entity.UserMfa, error) {
+	var userMfa entity.UserMfa
+
+	query, _ := a.queryLoader.Get("GetUserMfa")
+	err := a.db0.QueryRowxContext(ctx, query, userID).StructScan(&userMfa)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, x.WrapWithCode(err, x.CodeSQLRecordDoesNotExist, "get_user_mfa_sql")
+		}
+
+		zerolog.Ctx(ctx).Error().Err(err).Msg("get_user_mfa_sql")
+		return nil, x.WrapWithCode(err, x.CodeSQLRead, "get_user_mfa_sql")
+	}
+
+	return &userMfa, nil
+}
+
+func (a *authRepository) enableMfaSql(ctx context.Context, tx *sqlx.Tx, userID string, step int64) error {
+	query, _ := a.queryLoader.Get("EnableMfa")
+	result, err := tx.ExecContext(ctx, query, userID, step)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("enable_mfa_sql")
+		return x.WrapWithCode(err, x.CodeSQLUpdate, "enable_mfa_sql")
+	}
+
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return x.NewWithCode(x.CodeSQLUniqueConstraint, "mfa_already_enabled")
+	}
+
+	return nil
+}
+
+func (a *authRepository) deleteUserMfaSql(ctx context.Context, tx *sqlx.Tx, userID string) error {
+	query, _ := a.queryLoader.Get("DeleteUserMfa")
+	_, err := tx.ExecContext(ctx, query, userID)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("delete_user_mfa_sql")
+		return x.WrapWithCode(err, x.CodeSQLDelete, "delete_user_mfa_sql")
+	}
+
+	return nil
+}
+
+func (a *authRepository) deleteMfaRecoveryCodesSql(ctx context.Context, tx *sqlx.Tx, userID string) error {
+	query, _ := a.queryLoader.Get("DeleteMfaRecoveryCodes")
+	_, err := tx.ExecContext(ctx, query, userID)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("delete_mfa_recovery_codes_sql")
+		return x.WrapWithCode(err, x.CodeSQLDelete, "delete_mfa_recovery_codes_sql")
+	}
+
+	return nil
+}
+
+func (a *authRepository) storeMfaRecoveryCodeSql(ctx context.Context, tx *sqlx.Tx, userID string, code string) error {
+	query, _ := a.queryLoader.Get("StoreMfaRecoveryCode")
+	_, err := tx.ExecContext(ctx, query, userID, hashToken(code))
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("store_mfa_recovery_code_sql")
+		return x.WrapWithCode(err, x.CodeSQLCreate, "store_mfa_recovery_code_sql")
+	}
+
+	return nil
+}
+
+// useMfaStepSql records step as the newest accepted one. It reports false
+// when a code from that step or a later one was already accepted.
+func (a *authRepository) useMfaStepSql(ctx context.Context, userID string, step int64) (bool, error) {
+	query, _ := a.queryLoader.Get("UseMfaStep")
+	result, err := a.db0.ExecContext(ctx, query, userID, step)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("use_mfa_step_sql")
+		return false, x.WrapWithCode(err, x.CodeSQLUpdate, "use_mfa_step_sql")
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, x.WrapWithCode(err, x.CodeSQLCannotRetrieveAffectedRows, "use_mfa_step_sql")
+	}
+
+	return affected == 1, nil
+}
+
+func (a *authRepository) useMfaRecoveryCodeSql(ctx context.Context, userID string, code string) (bool, error) {
+	query, _ := a.queryLoader.Get("UseMfaRecoveryCode")
+	result, err := a.db0.ExecContext(ctx, query, userID, hashToken(code))
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("use_mfa_recovery_code_sql")
+		return false, x.WrapWithCode(err, x.CodeSQLUpdate, "use_mfa_recovery_code_sql")
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, x.WrapWithCode(err, x.CodeSQLCannotRetrieveAffectedRows, "use_mfa_recovery_code_sql")
+	}
+
+	return affected == 1, nil
+}
+
+func (a *authRepository) getUserIdentitySql(ctx context.Context, provider string, subject string) (*entity.UserIdentity, error) {
+	var identity entity.UserIdentity
+
+	query, _ := a.queryLoader.Get("GetUserIdentity")
+	err := a.db0.QueryRowxContext(ctx, query, provider, subject).StructScan(&identity)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, x.WrapWithCode(err, x.CodeSQLRecordDoesNotExist, "get_user_identity_sql")
+		}
+
+		zerolog.Ctx(ctx).Error().Err(err).Msg("get_user_identity_sql")
+		return nil, x.WrapWithCode(err, x.CodeSQLRead, "get_user_identity_sql")
+	}
+
+	return &identity, nil
+}
+
+// saveUserIdentitySql links identity to its user. An identity already linked
+// to a different user is left alone and reported as a unique constraint
+// violation.
+func (a *authRepository) saveUserIdentitySql(ctx context.Context, tx *sqlx.Tx, identity *entity.UserIdentity) error {
+	query, _ := a.queryLoader.Get("SaveUserIdentity")
+	result, err := tx.ExecContext(ctx, query, identity.UserID, identity.Provider, identity.Subject, identity.Email)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("save_user_identity_sql")
+		return x.WrapWithCode(err, x.CodeSQLCreate, "save_user_identity_sql")
+	}
+
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return x.NewWithCode(x.CodeSQLUniqueConstraint, "identity_linked_to_another_user")
+	}
+
+	return nil
+}
+
+func (a *authRepository) touchUserIdentitySql(ctx context.Context, identityID string, email string) error {
+	query, _ := a.queryLoader.Get("TouchUserIdentity")
+	_, err := a.db0.ExecContext(ctx, query, identityID, email)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("touch_user_identity_sql")
+		return x.WrapWithCode(err, x.CodeSQLUpdate, "touch_user_identity_sql")
+	}
+
+	return nil
+}
+
+func (a *authRepository) createApiKeySql(ctx context.Context, db sqlx.QueryerContext, apiKey *entity.ApiKey, key string) error {
+	query, _ := a.queryLoader.Get("CreateApiKey")
+	err := db.QueryRowxContext(ctx, query, apiKey.UserID, apiKey.Name, apiKey.Prefix, hashToken(key), apiKey.Scopes, apiKey.ExpiresAt).Scan(&apiKey.ID, &apiKey.CreatedAt)
 	if err != nil {
-		zerolog.Ctx(ctx).Error().Err(err).Msg("delete_refresh_token_sql")
-		return x.WrapWithCode(err, x.CodeSQLRowScan, "delete_refresh_token_sql")
+		zerolog.Ctx(ctx).Error().Err(err).Msg("create_api_key_sql")
+		return x.WrapWithCode(err, x.CodeSQLCreate, "create_api_key_sql")
 	}
 
 	return nil
 }
 
-func (a *authRepository) deleteRefreshTokenByTokenSql(ctx context.Context, refreshToken string) error {
-	hashedToken := hashToken(refreshToken)
-	query, _ := a.queryLoader.Get("DeleteRefreshTokenByToken")
-	_, err := a.db0.ExecContext(ctx, query, hashedToken)
+func (a *authRepository) getApiKeySql(ctx context.Context, queryName string, arg string) (*entity.ApiKey, error) {
+	var apiKey entity.ApiKey
+
+	query, _ := a.queryLoader.Get(queryName)
+	err := a.db0.QueryRowxContext(ctx, query, arg).StructScan(&apiKey)
 	if err != nil {
-		zerolog.Ctx(ctx).Error().Err(err).Msg("delete_refresh_token_by_token_sql")
-		return x.WrapWithCode(err, x.CodeSQLRowScan, "delete_refresh_token_by_token_sql")
+		if err == sql.ErrNoRows {
+			return nil, x.WrapWithCode(err, x.CodeSQLRecordDoesNotExist, "get_api_key_sql")
+		}
+
+		zerolog.Ctx(ctx).Error().Err(err).Msg("get_api_key_sql")
+		return nil, x.WrapWithCode(err, x.CodeSQLRead, "get_api_key_sql")
+	}
+
+	return &apiKey, nil
+}
+
+func (a *authRepository) listApiKeysSql(ctx context.Context, userID string) ([]entity.ApiKey, error) {
+	apiKeys := []entity.ApiKey{}
+
+	query, _ := a.queryLoader.Get("ListApiKeys")
+	if err := a.db0.SelectContext(ctx, &apiKeys, query, userID); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("list_api_keys_sql")
+		return nil, x.WrapWithCode(err, x.CodeSQLRead, "list_api_keys_sql")
+	}
+
+	return apiKeys, nil
+}
+
+func (a *authRepository) expireApiKeySql(ctx context.Context, tx *sqlx.Tx, keyID string, expiresAt time.Time) error {
+	query, _ := a.queryLoader.Get("ExpireApiKey")
+	if _, err := tx.ExecContext(ctx, query, keyID, expiresAt); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("expire_api_key_sql")
+		return x.WrapWithCode(err, x.CodeSQLUpdate, "expire_api_key_sql")
+	}
+
+	return nil
+}
+
+func (a *authRepository) revokeApiKeySql(ctx context.Context, keyID string) error {
+	query, _ := a.queryLoader.Get("RevokeApiKey")
+	if _, err := a.db0.ExecContext(ctx, query, keyID); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("revoke_api_key_sql")
+		return x.WrapWithCode(err, x.CodeSQLUpdate, "revoke_api_key_sql")
+	}
+
+	return nil
+}
+
+func (a *authRepository) touchApiKeySql(ctx context.Context, keyID string, interval time.Duration) error {
+	query, _ := a.queryLoader.Get("TouchApiKey")
+	if _, err := a.db0.ExecContext(ctx, query, keyID, int64(interval/time.Second)); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("touch_api_key_sql")
+		return x.WrapWithCode(err, x.CodeSQLUpdate, "touch_api_key_sql")
 	}
 
 	return nil
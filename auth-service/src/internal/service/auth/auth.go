@@ -6,7 +6,12 @@ import (
 	"time"
 
 	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/model/dto"
+	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/provider/oauth"
 	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/repository/auth"
+	userpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/user"
+	"github.com/linggaaskaedo/go-kill/common/pkg/token"
+
+	"google.golang.org/grpc"
 )
 
 type AuthServiceItf interface {
@@ -15,21 +20,96 @@ type AuthServiceItf interface {
 	ValidateToken(ctx context.Context, req *dto.ValidateTokenRequest) (*dto.ValidateTokenResponse, error)
 	RefreshToken(ctx context.Context, req *dto.RefreshTokenRequest) (*dto.RefreshTokenResponse, error)
 	Logout(ctx context.Context, req *dto.LogoutRequest) (*dto.LogoutResponse, error)
+
+	// Email verification and password reset
+	RequestEmailVerification(ctx context.Context, req *dto.RequestEmailVerificationRequest) (*dto.RequestEmailVerificationResponse, error)
+	VerifyEmail(ctx context.Context, req *dto.VerifyEmailRequest) (*dto.VerifyEmailResponse, error)
+	RequestPasswordReset(ctx context.Context, req *dto.RequestPasswordResetRequest) (*dto.RequestPasswordResetResponse, error)
+	ResetPassword(ctx context.Context, req *dto.ResetPasswordRequest) (*dto.ResetPasswordResponse, error)
+
+	// MFA
+	EnrollMfa(ctx context.Context, req *dto.EnrollMfaRequest) (*dto.EnrollMfaResponse, error)
+	ConfirmMfa(ctx context.Context, req *dto.ConfirmMfaRequest) (*dto.ConfirmMfaResponse, error)
+	DisableMfa(ctx context.Context, req *dto.DisableMfaRequest) (*dto.DisableMfaResponse, error)
+	VerifyMfa(ctx context.Context, req *dto.VerifyMfaRequest) (*dto.VerifyMfaResponse, error)
+
+	// Sessions
+	ListSessions(ctx context.Context, req *dto.ListSessionsRequest) (*dto.ListSessionsResponse, error)
+	RevokeSession(ctx context.Context, req *dto.RevokeSessionRequest) (*dto.RevokeSessionResponse, error)
+	LogoutAll(ctx context.Context, req *dto.LogoutAllRequest) (*dto.LogoutAllResponse, error)
+
+	// OAuth login
+	StartOAuthLogin(ctx context.Context, req *dto.StartOAuthLoginRequest) (*dto.StartOAuthLoginResponse, error)
+	CompleteOAuthLogin(ctx context.Context, req *dto.CompleteOAuthLoginRequest) (*dto.LoginResponse, error)
+
+	// API keys
+	ValidateApiKey(ctx context.Context, req *dto.ValidateApiKeyRequest) (*dto.ValidateTokenResponse, error)
+	CreateApiKey(ctx context.Context, req *dto.CreateApiKeyRequest) (*dto.CreateApiKeyResponse, error)
+	ListApiKeys(ctx context.Context, req *dto.ListApiKeysRequest) (*dto.ListApiKeysResponse, error)
+	RotateApiKey(ctx context.Context, req *dto.RotateApiKeyRequest) (*dto.CreateApiKeyResponse, error)
+	RevokeApiKey(ctx context.Context, req *dto.RevokeApiKeyRequest) (*dto.RevokeApiKeyResponse, error)
+}
+
+type KafkaProducer interface {
+	SendMessage(ctx context.Context, topic string, key, value []byte) (partition int32, offset int64, err error)
 }
 
 type authService struct {
 	authRepository auth.AuthRepositoryItf
+	userClient     userpb.UserServiceClient
+	kafkaProducer  KafkaProducer
 	authOptions    Options
+	keys           *token.KeySet
+	oauthProviders map[string]oauth.Provider
+	breachList     BreachedPasswordChecker
+
+	// now is the clock MFA codes are checked against
+	now func() time.Time
 }
 
 type Options struct {
-	JwtSecret string `yaml:"jwt_secret"`
+	SigningKeys token.Config   `yaml:"signing_keys"`
+	Lockout     LockoutOptions `yaml:"lockout"`
+
+	// RequireVerifiedEmail rejects logins until the address is verified
+	RequireVerifiedEmail bool                `yaml:"require_verified_email"`
+	EmailVerification    AccountTokenOptions `yaml:"email_verification"`
+	PasswordReset        AccountTokenOptions `yaml:"password_reset"`
+
+	Mfa MfaOptions `yaml:"mfa"`
+
+	OAuth oauth.Options `yaml:"oauth"`
+
+	Password PasswordOptions `yaml:"password"`
+
+	ApiKeys ApiKeyOptions `yaml:"api_keys"`
 }
 
-func InitAuthService(authRepository auth.AuthRepositoryItf, authOptions Options) AuthServiceItf {
+func InitAuthService(authRepository auth.AuthRepositoryItf, userClientConn *grpc.ClientConn, kafkaProducer KafkaProducer, authOptions Options, keys *token.KeySet, oauthProviders map[string]oauth.Provider, breachList BreachedPasswordChecker) AuthServiceItf {
+	authOptions.Lockout = authOptions.Lockout.withDefaults()
+	authOptions.EmailVerification = authOptions.EmailVerification.withDefaults(24 * time.Hour)
+	authOptions.PasswordReset = authOptions.PasswordReset.withDefaults(time.Hour)
+	authOptions.Mfa = authOptions.Mfa.withDefaults()
+	authOptions.Password = authOptions.Password.withDefaults()
+	authOptions.ApiKeys = authOptions.ApiKeys.withDefaults()
+	if authOptions.OAuth.StateTTL <= 0 {
+		authOptions.OAuth.StateTTL = defaultOAuthStateTTL
+	}
+
+	var userClient userpb.UserServiceClient
+	if userClientConn != nil {
+		userClient = userpb.NewUserServiceClient(userClientConn)
+	}
+
 	return &authService{
 		authRepository: authRepository,
+		userClient:     userClient,
+		kafkaProducer:  kafkaProducer,
 		authOptions:    authOptions,
+		keys:           keys,
+		oauthProviders: oauthProviders,
+		breachList:     breachList,
+		now:            time.Now,
 	}
 }
 
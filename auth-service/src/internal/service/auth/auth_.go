@@ -5,19 +5,36 @@ import (
 	"time"
 
 	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/model/dto"
+	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/model/entity"
 	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/rs/zerolog"
-	"golang.org/x/crypto/bcrypt"
 )
 
 func (a *authService) CreateAuthUser(ctx context.Context, req *dto.CreateAuthUserRequest) (*dto.CreateAuthUserResponse, error) {
-	authID, err := a.authRepository.CreateAuthUser(ctx, req)
+	if err := a.validatePassword(ctx, req.Password, req.Email); err != nil {
+		return nil, err
+	}
+
+	passwordHash, err := a.hashPassword(req.Password)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("hashed_password")
+		return nil, x.Wrap(err, "hashed_password")
+	}
+
+	authID, err := a.authRepository.CreateAuthUser(ctx, req.Email, passwordHash)
 	if err != nil {
 		return nil, err
 	}
 
+	// Registration succeeds even if the email cannot go out; the user can ask
+	// for another link.
+	userAuth := &entity.UserAuth{ID: authID, Email: req.Email}
+	if err := a.sendAccountToken(ctx, userAuth, entity.TokenEmailVerification, eventVerificationRequested, a.authOptions.EmailVerification); err != nil {
+		zerolog.Ctx(ctx).Warn().Err(err).Str("authID", authID).Msg("send_verification_email")
+	}
+
 	return &dto.CreateAuthUserResponse{
 		Success: true,
 		AuthId:  authID,
@@ -25,8 +42,19 @@ func (a *authService) CreateAuthUser(ctx context.Context, req *dto.CreateAuthUse
 }
 
 func (a *authService) Login(ctx context.Context, req *dto.LoginRequest) (*dto.LoginResponse, error) {
+	if err := a.checkLoginThrottle(ctx, req.Email, req.IpAddress); err != nil {
+		return nil, err
+	}
+
 	userAuth, err := a.authRepository.FindAuthUserByEmail(ctx, req.Email)
 	if err != nil {
+		if x.ErrCode(err) == x.CodeSQLRecordDoesNotExist {
+			a.loginFailed(ctx, nil, req.Email, req.IpAddress)
+		}
+		return nil, err
+	}
+
+	if err := checkAccountLock(userAuth); err != nil {
 		return nil, err
 	}
 
@@ -35,41 +63,69 @@ func (a *authService) Login(ctx context.Context, req *dto.LoginRequest) (*dto.Lo
 		return nil, x.New("User is not active")
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(userAuth.PasswordHash), []byte(req.Password))
+	needsRehash, err := a.verifyPassword(userAuth.PasswordHash, req.Password)
 	if err != nil {
+		a.loginFailed(ctx, userAuth, req.Email, req.IpAddress)
 		return nil, x.Wrap(err, "Invalid email or password")
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"sub":   userAuth.ID,
-		"email": userAuth.Email,
-		"iat":   time.Now().Unix(),
-		"exp":   time.Now().Add(time.Hour * 1).Unix(),
-		"jti":   generateTokenID(),
-	})
+	if needsRehash {
+		a.rehashPassword(ctx, userAuth.ID, userAuth.PasswordHash, req.Password)
+	}
 
-	accessToken, err := token.SignedString([]byte(a.authOptions.JwtSecret))
-	if err != nil {
-		return nil, x.Wrap(err, "Failed to generate token")
+	if a.authOptions.RequireVerifiedEmail && userAuth.EmailVerifiedAt == nil {
+		return nil, x.NewWithCode(x.CodeHTTPForbidden, "Email address is not verified")
+	}
+
+	// An MFA login is not done yet: its failures stay counted until
+	// VerifyMfa succeeds, so wrong codes feed the same lockout
+	if userAuth.MfaEnabled {
+		return a.startMfaChallenge(ctx, userAuth, req.IpAddress, req.UserAgent)
+	}
+
+	if err := a.authRepository.ClearLoginFailures(ctx, req.Email); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("clear_login_failures")
 	}
 
+	return a.issueSession(ctx, userAuth, req.IpAddress, req.UserAgent)
+}
+
+// issueSession starts a session on a new device for a user who has fully
+// authenticated and signs an access token tied to it.
+func (a *authService) issueSession(ctx context.Context, userAuth *entity.UserAuth, ipAddress string, userAgent string) (*dto.LoginResponse, error) {
 	refreshToken := generateRefreshToken()
 
-	err = a.authRepository.StoreSession(ctx, userAuth.ID, refreshToken, time.Now().Add(time.Hour*24*7), userAuth.Email, req.IpAddress)
+	session := &entity.Session{
+		UserID:    userAuth.ID,
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+	}
+
+	userRoles, err := a.authRepository.FindUserRoles(ctx, userAuth.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionID, err := a.authRepository.StoreSession(ctx, session, userAuth.Email, refreshToken, time.Now().Add(refreshTokenTTL))
 	if err != nil {
 		return nil, err
 	}
 
+	accessToken, err := a.signAccessToken(userAuth.ID, userAuth.Email, sessionID, userRoles)
+	if err != nil {
+		return nil, x.Wrap(err, "Failed to generate token")
+	}
+
 	return &dto.LoginResponse{
 		Success:      true,
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
-		ExpiresIn:    3600,
+		ExpiresIn:    int64(accessTokenTTL / time.Second),
 	}, nil
 }
 
 func (a *authService) ValidateToken(ctx context.Context, req *dto.ValidateTokenRequest) (*dto.ValidateTokenResponse, error) {
-	token, err := jwt.Parse(req.Token, func(token *jwt.Token) (any, error) { return []byte(a.authOptions.JwtSecret), nil })
+	token, err := a.keys.Parse(req.Token)
 	if err != nil {
 		return nil, x.Wrap(err, "Failed to parse token")
 	}
@@ -99,35 +155,71 @@ func (a *authService) ValidateToken(ctx context.Context, req *dto.ValidateTokenR
 		return nil, x.New("token in the blacklist")
 	}
 
+	// Tokens signed before sessions existed carry no sid
+	sid, _ := claims["sid"].(string)
+	if sid != "" && a.authRepository.FindRevokedSession(ctx, sid) {
+		return nil, x.New("session revoked")
+	}
+
 	return &dto.ValidateTokenResponse{
-		Valid:  true,
-		UserId: sub,
-		Email:  email,
+		Valid:       true,
+		UserId:      sub,
+		Email:       email,
+		SessionId:   sid,
+		Roles:       claimStrings(claims, "roles"),
+		Permissions: claimStrings(claims, "perms"),
 	}, nil
 }
 
+// RefreshToken exchanges a refresh token for a new pair. A token that was
+// already exchanged revokes its whole session; see revokeReusedSession.
 func (a *authService) RefreshToken(ctx context.Context, req *dto.RefreshTokenRequest) (*dto.RefreshTokenResponse, error) {
-	user, err := a.authRepository.GetUserInfo(ctx, req.RefreshToken)
+	current, err := a.authRepository.FindRefreshToken(ctx, req.RefreshToken)
+	if err != nil {
+		if x.ErrCode(err) == x.CodeSQLRecordDoesNotExist {
+			return nil, x.WrapWithCode(err, x.CodeHTTPUnauthorized, "Invalid refresh token")
+		}
+		return nil, err
+	}
+
+	if current.RevokedAt != nil || !time.Now().Before(current.ExpiresAt) {
+		return nil, x.NewWithCode(x.CodeHTTPUnauthorized, "Invalid refresh token")
+	}
+
+	if current.UsedAt != nil {
+		a.revokeReusedSession(ctx, current)
+		return nil, x.NewWithCode(x.CodeHTTPUnauthorized, "Invalid refresh token")
+	}
+
+	user, err := a.authRepository.FindAuthUserByID(ctx, current.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsActive {
+		zerolog.Ctx(ctx).Error().Msg("user_not_active")
+		return nil, x.New("User is not active")
+	}
+
+	userRoles, err := a.authRepository.FindUserRoles(ctx, user.ID)
 	if err != nil {
 		return nil, err
 	}
 
 	newRefreshToken := generateRefreshToken()
 
-	err = a.authRepository.RotateRefreshToken(ctx, user.ID, req.RefreshToken, newRefreshToken, time.Now().Add(time.Hour*24*7))
+	rotated, err := a.authRepository.RotateRefreshToken(ctx, current, newRefreshToken, time.Now().Add(refreshTokenTTL), req.IpAddress, req.UserAgent)
 	if err != nil {
 		return nil, err
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"sub":   user.ID,
-		"email": user.Email,
-		"iat":   time.Now().Unix(),
-		"exp":   time.Now().Add(time.Hour * 1).Unix(),
-		"jti":   generateTokenID(),
-	})
+	// Another request used the same token between the lookup and now
+	if !rotated {
+		a.revokeReusedSession(ctx, current)
+		return nil, x.NewWithCode(x.CodeHTTPUnauthorized, "Invalid refresh token")
+	}
 
-	accessToken, err := token.SignedString([]byte(a.authOptions.JwtSecret))
+	accessToken, err := a.signAccessToken(user.ID, user.Email, current.FamilyID, userRoles)
 	if err != nil {
 		return nil, x.Wrap(err, "Failed signed token")
 	}
@@ -136,22 +228,32 @@ func (a *authService) RefreshToken(ctx context.Context, req *dto.RefreshTokenReq
 		Success:      true,
 		AccessToken:  accessToken,
 		RefreshToken: newRefreshToken,
-		ExpiresIn:    3600,
+		ExpiresIn:    int64(accessTokenTTL / time.Second),
 	}, nil
 }
 
+// Logout ends the session the access token belongs to and blacklists the
+// token. Without a session to go by, it ends all of the user's sessions.
 func (a *authService) Logout(ctx context.Context, req *dto.LogoutRequest) (*dto.LogoutResponse, error) {
-	token, err := jwt.Parse(req.Token, func(token *jwt.Token) (interface{}, error) {
-		return []byte(a.authOptions.JwtSecret), nil
-	})
+	var sessionID string
 
+	token, err := a.keys.Parse(req.Token)
 	if err == nil && token != nil {
 		if err := a.authRepository.BlacklistToken(ctx, token); err != nil {
 			zerolog.Ctx(ctx).Error().Err(err).Msg("failed_to_blacklist_token")
 		}
+
+		if claims, ok := token.Claims.(jwt.MapClaims); ok && claims["sub"] == req.UserId {
+			sessionID, _ = claims["sid"].(string)
+		}
 	}
 
-	if err := a.authRepository.ClearSession(ctx, req.UserId); err != nil {
+	if sessionID != "" {
+		err = a.authRepository.RevokeSession(ctx, sessionID)
+	} else {
+		_, err = a.authRepository.RevokeAllSessions(ctx, req.UserId)
+	}
+	if err != nil {
 		zerolog.Ctx(ctx).Error().Err(err).Msg("failed_to_clear_session")
 		return &dto.LogoutResponse{Success: false, Message: "Failed to logout"}, err
 	}
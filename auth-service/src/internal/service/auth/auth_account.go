@@ -0,0 +1,198 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/model/dto"
+	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/model/entity"
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+
+	"github.com/rs/xid"
+	"github.com/rs/zerolog"
+)
+
+const (
+	eventVerificationRequested  = "auth.verification_requested"
+	eventPasswordResetRequested = "auth.password_reset_requested"
+
+	activityPasswordReset = "password_reset"
+)
+
+// AccountTokenOptions configures one kind of emailed single-use link. URL is
+// the page the link opens; "{token}" in it is replaced by the token.
+type AccountTokenOptions struct {
+	TTL   time.Duration `yaml:"ttl"`
+	URL   string        `yaml:"url"`
+	Topic string        `yaml:"topic"`
+}
+
+func (o AccountTokenOptions) withDefaults(ttl time.Duration) AccountTokenOptions {
+	if o.TTL <= 0 {
+		o.TTL = ttl
+	}
+
+	return o
+}
+
+func (o AccountTokenOptions) link(token string) string {
+	return strings.ReplaceAll(o.URL, "{token}", url.QueryEscape(token))
+}
+
+// generateAccountToken returns 256 bits of randomness, URL safe. Only its
+// hash is stored.
+func generateAccountToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// RequestEmailVerification emails a fresh verification link. It answers the
+// same whether or not the address has an account, so it cannot be used to
+// find out which emails are registered.
+func (a *authService) RequestEmailVerification(ctx context.Context, req *dto.RequestEmailVerificationRequest) (*dto.RequestEmailVerificationResponse, error) {
+	userAuth, err := a.authRepository.FindAuthUserByEmail(ctx, req.Email)
+	if err != nil {
+		if x.ErrCode(err) == x.CodeSQLRecordDoesNotExist {
+			return &dto.RequestEmailVerificationResponse{Success: true}, nil
+		}
+		return nil, err
+	}
+
+	if userAuth.EmailVerifiedAt != nil {
+		return &dto.RequestEmailVerificationResponse{Success: true}, nil
+	}
+
+	err = a.sendAccountToken(ctx, userAuth, entity.TokenEmailVerification, eventVerificationRequested, a.authOptions.EmailVerification)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.RequestEmailVerificationResponse{Success: true}, nil
+}
+
+func (a *authService) VerifyEmail(ctx context.Context, req *dto.VerifyEmailRequest) (*dto.VerifyEmailResponse, error) {
+	userID, err := a.authRepository.VerifyEmail(ctx, req.Token)
+	if err != nil {
+		return nil, invalidAccountToken(err)
+	}
+
+	zerolog.Ctx(ctx).Info().Str("authID", userID).Msg("email_verified")
+
+	return &dto.VerifyEmailResponse{Success: true}, nil
+}
+
+// RequestPasswordReset emails a password reset link. Like
+// RequestEmailVerification it succeeds for unknown emails.
+func (a *authService) RequestPasswordReset(ctx context.Context, req *dto.RequestPasswordResetRequest) (*dto.RequestPasswordResetResponse, error) {
+	userAuth, err := a.authRepository.FindAuthUserByEmail(ctx, req.Email)
+	if err != nil {
+		if x.ErrCode(err) == x.CodeSQLRecordDoesNotExist {
+			return &dto.RequestPasswordResetResponse{Success: true}, nil
+		}
+		return nil, err
+	}
+
+	if !userAuth.IsActive {
+		return &dto.RequestPasswordResetResponse{Success: true}, nil
+	}
+
+	err = a.sendAccountToken(ctx, userAuth, entity.TokenPasswordReset, eventPasswordResetRequested, a.authOptions.PasswordReset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.RequestPasswordResetResponse{Success: true}, nil
+}
+
+func (a *authService) ResetPassword(ctx context.Context, req *dto.ResetPasswordRequest) (*dto.ResetPasswordResponse, error) {
+	userID, err := a.authRepository.FindAuthTokenUser(ctx, req.Token, entity.TokenPasswordReset)
+	if err != nil {
+		return nil, invalidAccountToken(err)
+	}
+
+	userAuth, err := a.authRepository.FindAuthUserByID(ctx, userID)
+	if err != nil {
+		return nil, invalidAccountToken(err)
+	}
+
+	if err := a.validatePassword(ctx, req.NewPassword, userAuth.Email); err != nil {
+		return nil, err
+	}
+
+	passwordHash, err := a.hashPassword(req.NewPassword)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("hashed_password")
+		return nil, x.Wrap(err, "hashed_password")
+	}
+
+	// The token is only used up here, so a rejected password can be retried
+	userID, err = a.authRepository.ResetPassword(ctx, req.Token, passwordHash)
+	if err != nil {
+		return nil, invalidAccountToken(err)
+	}
+
+	zerolog.Ctx(ctx).Info().Str("authID", userID).Msg("password_reset")
+	a.logActivity(ctx, userID, activityPasswordReset, nil)
+
+	return &dto.ResetPasswordResponse{Success: true}, nil
+}
+
+func invalidAccountToken(err error) error {
+	if x.ErrCode(err) == x.CodeSQLRecordDoesNotExist {
+		return x.WrapWithCode(err, x.CodeHTTPBadRequest, "Invalid or expired token")
+	}
+
+	return err
+}
+
+// sendAccountToken stores a new token for userAuth and publishes the event
+// notification-service turns into an email. The token is only ever sent in
+// the event, never returned to the caller.
+func (a *authService) sendAccountToken(ctx context.Context, userAuth *entity.UserAuth, purpose entity.AuthTokenPurpose, eventType string, opts AccountTokenOptions) error {
+	token, err := generateAccountToken()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("generate_account_token")
+		return x.Wrap(err, "generate_account_token")
+	}
+
+	expiresAt := time.Now().Add(opts.TTL)
+	if err := a.authRepository.StoreAuthToken(ctx, userAuth.ID, purpose, token, expiresAt); err != nil {
+		return err
+	}
+
+	event := dto.AuthEvent{
+		EventID:   xid.New().String(),
+		EventType: eventType,
+		Version:   "1.0",
+		Timestamp: time.Now(),
+		Source:    "auth-service",
+		Data: dto.AuthEventData{
+			AuthID:    userAuth.ID,
+			Email:     userAuth.Email,
+			Link:      opts.link(token),
+			ExpiresAt: expiresAt,
+		},
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("marshal_auth_event")
+		return x.Wrap(err, "marshal_auth_event")
+	}
+
+	if _, _, err := a.kafkaProducer.SendMessage(ctx, opts.Topic, []byte(userAuth.ID), payload); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("topic", opts.Topic).Msg("publish_auth_event")
+		return x.WrapWithCode(err, x.CodeHTTPServiceUnavailable, "Failed to send email, try again later")
+	}
+
+	return nil
+}
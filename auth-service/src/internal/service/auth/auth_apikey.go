@@ -0,0 +1,300 @@
+package auth
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/model/dto"
+	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/model/entity"
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	activityApiKeyCreated = "api_key_created"
+	activityApiKeyRotated = "api_key_rotated"
+	activityApiKeyRevoked = "api_key_revoked"
+
+	// ApiKeyPrefix starts every API key, so one pasted as a bearer token, or
+	// found in a leaked file, is easy to recognise
+	ApiKeyPrefix = "gk_"
+
+	// apiKeyDisplayLength is how much of a key is kept in the clear
+	apiKeyDisplayLength = len(ApiKeyPrefix) + 8
+)
+
+type ApiKeyOptions struct {
+	MaxPerUser int `yaml:"max_per_user"`
+	// MaxTTL caps the lifetime of new keys; 0 allows keys that never expire
+	MaxTTL time.Duration `yaml:"max_ttl"`
+	// RotationGrace keeps a rotated key working this long, so clients can
+	// switch over without downtime; 0 retires it at once
+	RotationGrace time.Duration `yaml:"rotation_grace"`
+	// LastUsedInterval is how often last_used_at is written for a busy key
+	LastUsedInterval time.Duration `yaml:"last_used_interval"`
+}
+
+func (o ApiKeyOptions) withDefaults() ApiKeyOptions {
+	if o.MaxPerUser <= 0 {
+		o.MaxPerUser = 10
+	}
+	if o.LastUsedInterval <= 0 {
+		o.LastUsedInterval = time.Minute
+	}
+
+	return o
+}
+
+// CreateApiKey issues a key that authenticates as the user with the given
+// scopes. Scopes are permissions and must be ones the user holds.
+func (a *authService) CreateApiKey(ctx context.Context, req *dto.CreateApiKeyRequest) (*dto.CreateApiKeyResponse, error) {
+	opts := a.authOptions.ApiKeys
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, x.NewWithCode(x.CodeHTTPBadRequest, "API key name is required")
+	}
+
+	ttl := time.Duration(req.ExpiresIn) * time.Second
+	if req.ExpiresIn < 0 {
+		return nil, x.NewWithCode(x.CodeHTTPBadRequest, "expires_in must not be negative")
+	}
+	if opts.MaxTTL > 0 {
+		if ttl > opts.MaxTTL {
+			return nil, x.NewWithCode(x.CodeHTTPUnprocessableEntity, "API keys expire after at most %s", opts.MaxTTL)
+		}
+		if ttl == 0 {
+			ttl = opts.MaxTTL
+		}
+	}
+
+	scopes, err := a.checkApiKeyScopes(ctx, req.UserId, req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := a.authRepository.ListApiKeys(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= opts.MaxPerUser {
+		return nil, x.NewWithCode(x.CodeHTTPUnprocessableEntity, "API key limit of %d reached, revoke one first", opts.MaxPerUser)
+	}
+
+	apiKey := &entity.ApiKey{
+		UserID: req.UserId,
+		Name:   name,
+		Scopes: strings.Join(scopes, " "),
+	}
+	if ttl > 0 {
+		expiresAt := time.Now().Add(ttl)
+		apiKey.ExpiresAt = &expiresAt
+	}
+
+	key, err := a.newApiKey(ctx, apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.authRepository.CreateApiKey(ctx, apiKey, key); err != nil {
+		return nil, err
+	}
+
+	a.logActivity(ctx, req.UserId, activityApiKeyCreated, map[string]string{"key_id": apiKey.ID, "name": apiKey.Name})
+
+	return &dto.CreateApiKeyResponse{ApiKey: apiKeyResponse(apiKey), Key: key}, nil
+}
+
+func (a *authService) ListApiKeys(ctx context.Context, req *dto.ListApiKeysRequest) (*dto.ListApiKeysResponse, error) {
+	apiKeys, err := a.authRepository.ListApiKeys(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.ListApiKeysResponse{ApiKeys: make([]dto.ApiKeyResponse, len(apiKeys))}
+	for i := range apiKeys {
+		resp.ApiKeys[i] = apiKeyResponse(&apiKeys[i])
+	}
+
+	return resp, nil
+}
+
+// RotateApiKey replaces a key with a new one with the same name, scopes and
+// lifetime. The old key keeps working for the configured grace period.
+func (a *authService) RotateApiKey(ctx context.Context, req *dto.RotateApiKeyRequest) (*dto.CreateApiKeyResponse, error) {
+	current, err := a.findOwnApiKey(ctx, req.UserId, req.KeyId)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	next := &entity.ApiKey{
+		UserID: current.UserID,
+		Name:   current.Name,
+		Scopes: current.Scopes,
+	}
+	if current.ExpiresAt != nil {
+		expiresAt := now.Add(current.ExpiresAt.Sub(current.CreatedAt))
+		next.ExpiresAt = &expiresAt
+	}
+
+	key, err := a.newApiKey(ctx, next)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.authRepository.RotateApiKey(ctx, current, next, key, now.Add(a.authOptions.ApiKeys.RotationGrace)); err != nil {
+		return nil, err
+	}
+
+	a.logActivity(ctx, req.UserId, activityApiKeyRotated, map[string]string{"key_id": current.ID, "new_key_id": next.ID})
+
+	return &dto.CreateApiKeyResponse{ApiKey: apiKeyResponse(next), Key: key}, nil
+}
+
+func (a *authService) RevokeApiKey(ctx context.Context, req *dto.RevokeApiKeyRequest) (*dto.RevokeApiKeyResponse, error) {
+	apiKey, err := a.findOwnApiKey(ctx, req.UserId, req.KeyId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.authRepository.RevokeApiKey(ctx, apiKey.ID); err != nil {
+		return nil, err
+	}
+
+	a.logActivity(ctx, req.UserId, activityApiKeyRevoked, map[string]string{"key_id": apiKey.ID})
+
+	return &dto.RevokeApiKeyResponse{Success: true}, nil
+}
+
+// ValidateApiKey authenticates a request made with an API key. The answer
+// has the same shape as ValidateToken's, so services treat both alike. Its
+// permissions are the key's scopes the owner still holds, and it carries no
+// roles, so a key never reaches further than its scopes.
+func (a *authService) ValidateApiKey(ctx context.Context, req *dto.ValidateApiKeyRequest) (*dto.ValidateTokenResponse, error) {
+	if !strings.HasPrefix(req.ApiKey, ApiKeyPrefix) {
+		return nil, x.NewWithCode(x.CodeHTTPUnauthorized, "Invalid API key")
+	}
+
+	apiKey, err := a.authRepository.FindApiKey(ctx, req.ApiKey)
+	if err != nil {
+		if x.ErrCode(err) == x.CodeSQLRecordDoesNotExist {
+			return nil, x.WrapWithCode(err, x.CodeHTTPUnauthorized, "Invalid API key")
+		}
+		return nil, err
+	}
+
+	if !apiKey.Active(time.Now()) {
+		return nil, x.NewWithCode(x.CodeHTTPUnauthorized, "Invalid API key")
+	}
+
+	userAuth, err := a.authRepository.FindAuthUserByID(ctx, apiKey.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !userAuth.IsActive || checkAccountLock(userAuth) != nil {
+		return nil, x.NewWithCode(x.CodeHTTPUnauthorized, "Invalid API key")
+	}
+
+	userRoles, err := a.authRepository.FindUserRoles(ctx, apiKey.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := []string{}
+	for _, scope := range apiKey.ScopeList() {
+		if slices.Contains(userRoles.Permissions, scope) {
+			permissions = append(permissions, scope)
+		}
+	}
+
+	if err := a.authRepository.TouchApiKey(ctx, apiKey.ID, a.authOptions.ApiKeys.LastUsedInterval); err != nil {
+		zerolog.Ctx(ctx).Warn().Err(err).Str("key_id", apiKey.ID).Msg("touch_api_key")
+	}
+
+	return &dto.ValidateTokenResponse{
+		Valid:       true,
+		UserId:      userAuth.ID,
+		Email:       userAuth.Email,
+		Roles:       []string{},
+		Permissions: permissions,
+		ApiKeyId:    apiKey.ID,
+	}, nil
+}
+
+// checkApiKeyScopes returns scopes sorted and without duplicates, or an
+// error naming the ones the user does not hold.
+func (a *authService) checkApiKeyScopes(ctx context.Context, userID string, scopes []string) ([]string, error) {
+	scopes = slices.Clone(scopes)
+	slices.Sort(scopes)
+	scopes = slices.Compact(scopes)
+
+	if len(scopes) == 0 {
+		return []string{}, nil
+	}
+
+	userRoles, err := a.authRepository.FindUserRoles(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var denied []string
+	for _, scope := range scopes {
+		if !slices.Contains(userRoles.Permissions, scope) {
+			denied = append(denied, scope)
+		}
+	}
+
+	if len(denied) > 0 {
+		return nil, x.NewWithCode(x.CodeHTTPForbidden, "API key scopes exceed your permissions: %s", strings.Join(denied, ", "))
+	}
+
+	return scopes, nil
+}
+
+// findOwnApiKey returns a usable key of the user. Keys of other users are
+// reported as not found, the same as ids that do not exist.
+func (a *authService) findOwnApiKey(ctx context.Context, userID string, keyID string) (*entity.ApiKey, error) {
+	apiKey, err := a.authRepository.GetApiKey(ctx, keyID)
+	if err != nil && x.ErrCode(err) != x.CodeSQLRecordDoesNotExist {
+		return nil, err
+	}
+
+	if apiKey == nil || apiKey.UserID != userID || !apiKey.Active(time.Now()) {
+		return nil, x.NewWithCode(x.CodeHTTPNotFound, "API key not found")
+	}
+
+	return apiKey, nil
+}
+
+// newApiKey generates a key and sets the part of it apiKey keeps in the
+// clear.
+func (a *authService) newApiKey(ctx context.Context, apiKey *entity.ApiKey) (string, error) {
+	secret, err := generateAccountToken()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("generate_api_key")
+		return "", x.Wrap(err, "generate_api_key")
+	}
+
+	key := ApiKeyPrefix + secret
+	apiKey.Prefix = key[:apiKeyDisplayLength]
+
+	return key, nil
+}
+
+func apiKeyResponse(apiKey *entity.ApiKey) dto.ApiKeyResponse {
+	return dto.ApiKeyResponse{
+		KeyId:      apiKey.ID,
+		Name:       apiKey.Name,
+		Prefix:     apiKey.Prefix,
+		Scopes:     apiKey.ScopeList(),
+		ExpiresAt:  apiKey.ExpiresAt,
+		LastUsedAt: apiKey.LastUsedAt,
+		CreatedAt:  apiKey.CreatedAt,
+	}
+}
@@ -0,0 +1,228 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/model/dto"
+	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/model/entity"
+	"github.com/linggaaskaedo/go-kill/common/pkg/authz"
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// apiKeyFixture is the state behind the repository mock: the user, the
+// permissions their roles grant and the keys created so far.
+type apiKeyFixture struct {
+	user        *entity.UserAuth
+	permissions []string
+	keys        map[string]*entity.ApiKey
+	byKey       map[string]string
+}
+
+func newTestApiKeyService(t *testing.T, opts ApiKeyOptions) (*authService, *MockAuthRepository, *apiKeyFixture) {
+	t.Helper()
+
+	f := &apiKeyFixture{
+		user:        newTestUser(),
+		permissions: []string{authz.PermOrderReadAny, authz.PermOrderStatusUpdate},
+		keys:        map[string]*entity.ApiKey{},
+		byKey:       map[string]string{},
+	}
+
+	create := func(apiKey *entity.ApiKey, key string) {
+		apiKey.ID = fmt.Sprintf("key-%d", len(f.keys)+1)
+		apiKey.CreatedAt = time.Now()
+		f.keys[apiKey.ID] = apiKey
+		f.byKey[key] = apiKey.ID
+	}
+
+	// lookup answers GetApiKey and FindApiKey from the keys created so far
+	lookup := func(call *mock.Call, keyID string) {
+		apiKey, ok := f.keys[keyID]
+		if !ok {
+			call.ReturnArguments = mock.Arguments{nil, errNotFound("get_api_key_by_id_sql")}
+			return
+		}
+		call.ReturnArguments = mock.Arguments{apiKey, nil}
+	}
+
+	repo := &MockAuthRepository{}
+	repo.On("FindAuthUserByID", mock.Anything, testUserID).Return(f.user, nil)
+
+	roles := repo.On("FindUserRoles", mock.Anything, testUserID)
+	roles.Run(func(args mock.Arguments) {
+		roles.ReturnArguments = mock.Arguments{&entity.UserRoles{Roles: []string{entity.RoleCustomer}, Permissions: f.permissions}, nil}
+	})
+
+	repo.On("CreateApiKey", mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { create(args.Get(1).(*entity.ApiKey), args.String(2)) }).
+		Return(nil)
+
+	get := repo.On("GetApiKey", mock.Anything, mock.Anything)
+	get.Run(func(args mock.Arguments) { lookup(get, args.String(1)) })
+
+	find := repo.On("FindApiKey", mock.Anything, mock.Anything)
+	find.Run(func(args mock.Arguments) { lookup(find, f.byKey[args.String(1)]) })
+
+	list := repo.On("ListApiKeys", mock.Anything, testUserID)
+	list.Run(func(args mock.Arguments) {
+		var apiKeys []entity.ApiKey
+		for _, apiKey := range f.keys {
+			if apiKey.Active(time.Now()) {
+				apiKeys = append(apiKeys, *apiKey)
+			}
+		}
+		list.ReturnArguments = mock.Arguments{apiKeys, nil}
+	})
+
+	repo.On("RotateApiKey", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			expireCurrentAt := args.Get(4).(time.Time)
+			args.Get(1).(*entity.ApiKey).ExpiresAt = &expireCurrentAt
+			create(args.Get(2).(*entity.ApiKey), args.String(3))
+		}).
+		Return(nil)
+
+	repo.On("RevokeApiKey", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			now := time.Now()
+			f.keys[args.String(1)].RevokedAt = &now
+		}).
+		Return(nil)
+
+	repo.On("TouchApiKey", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	svc := InitAuthService(repo, nil, discardProducer{}, Options{ApiKeys: opts}, newTestKeySet(t), nil, nil).(*authService)
+
+	return svc, repo, f
+}
+
+func TestCreateApiKeyRejectsScopesBeyondPermissions(t *testing.T) {
+	svc, repo, f := newTestApiKeyService(t, ApiKeyOptions{})
+
+	_, err := svc.CreateApiKey(context.Background(), &dto.CreateApiKeyRequest{
+		UserId: testUserID,
+		Name:   "ci",
+		Scopes: []string{authz.PermOrderReadAny, authz.PermProductWrite},
+	})
+	require.Error(t, err)
+	assert.Equal(t, x.CodeHTTPForbidden, x.ErrCode(err))
+	assert.Contains(t, err.Error(), authz.PermProductWrite)
+	assert.Empty(t, f.keys)
+	repo.AssertNotCalled(t, "CreateApiKey", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCreateApiKeyEnforcesLimitAndMaxTTL(t *testing.T) {
+	svc, _, _ := newTestApiKeyService(t, ApiKeyOptions{MaxPerUser: 1, MaxTTL: time.Hour})
+	ctx := context.Background()
+
+	_, err := svc.CreateApiKey(ctx, &dto.CreateApiKeyRequest{UserId: testUserID, Name: "ci", ExpiresIn: 2 * 3600})
+	require.Error(t, err)
+	assert.Equal(t, x.CodeHTTPUnprocessableEntity, x.ErrCode(err))
+
+	resp, err := svc.CreateApiKey(ctx, &dto.CreateApiKeyRequest{UserId: testUserID, Name: "ci"})
+	require.NoError(t, err)
+	require.NotNil(t, resp.ApiKey.ExpiresAt, "keys default to the maximum lifetime")
+	assert.WithinDuration(t, time.Now().Add(time.Hour), *resp.ApiKey.ExpiresAt, time.Minute)
+
+	_, err = svc.CreateApiKey(ctx, &dto.CreateApiKeyRequest{UserId: testUserID, Name: "ci-2"})
+	require.Error(t, err)
+	assert.Equal(t, x.CodeHTTPUnprocessableEntity, x.ErrCode(err))
+}
+
+func TestValidateApiKeyGrantsOnlyHeldScopes(t *testing.T) {
+	svc, repo, f := newTestApiKeyService(t, ApiKeyOptions{})
+	ctx := context.Background()
+
+	created, err := svc.CreateApiKey(ctx, &dto.CreateApiKeyRequest{
+		UserId: testUserID,
+		Name:   "ci",
+		Scopes: []string{authz.PermOrderStatusUpdate, authz.PermOrderReadAny, authz.PermOrderReadAny},
+	})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(created.Key, ApiKeyPrefix))
+	assert.True(t, strings.HasPrefix(created.Key, created.ApiKey.Prefix))
+	assert.Equal(t, []string{authz.PermOrderReadAny, authz.PermOrderStatusUpdate}, created.ApiKey.Scopes)
+
+	// The user has since lost order:status:update
+	f.permissions = []string{authz.PermOrderReadAny}
+
+	resp, err := svc.ValidateApiKey(ctx, &dto.ValidateApiKeyRequest{ApiKey: created.Key})
+	require.NoError(t, err)
+	assert.Equal(t, testUserID, resp.UserId)
+	assert.Equal(t, created.ApiKey.KeyId, resp.ApiKeyId)
+	assert.Empty(t, resp.Roles)
+	assert.Equal(t, []string{authz.PermOrderReadAny}, resp.Permissions)
+	repo.AssertNumberOfCalls(t, "TouchApiKey", 1)
+
+	for _, key := range []string{"", "not-an-api-key", ApiKeyPrefix + "unknown"} {
+		_, err := svc.ValidateApiKey(ctx, &dto.ValidateApiKeyRequest{ApiKey: key})
+		require.Error(t, err, key)
+		assert.Equal(t, x.CodeHTTPUnauthorized, x.ErrCode(err), key)
+	}
+}
+
+func TestValidateApiKeyRejectsRevokedExpiredAndLockedOut(t *testing.T) {
+	svc, _, f := newTestApiKeyService(t, ApiKeyOptions{})
+	ctx := context.Background()
+
+	created, err := svc.CreateApiKey(ctx, &dto.CreateApiKeyRequest{UserId: testUserID, Name: "ci"})
+	require.NoError(t, err)
+
+	f.user.IsActive = false
+	_, err = svc.ValidateApiKey(ctx, &dto.ValidateApiKeyRequest{ApiKey: created.Key})
+	assert.Equal(t, x.CodeHTTPUnauthorized, x.ErrCode(err))
+	f.user.IsActive = true
+
+	expired := time.Now().Add(-time.Second)
+	f.keys[created.ApiKey.KeyId].ExpiresAt = &expired
+	_, err = svc.ValidateApiKey(ctx, &dto.ValidateApiKeyRequest{ApiKey: created.Key})
+	assert.Equal(t, x.CodeHTTPUnauthorized, x.ErrCode(err))
+	f.keys[created.ApiKey.KeyId].ExpiresAt = nil
+
+	_, err = svc.RevokeApiKey(ctx, &dto.RevokeApiKeyRequest{UserId: testUserID, KeyId: created.ApiKey.KeyId})
+	require.NoError(t, err)
+	_, err = svc.ValidateApiKey(ctx, &dto.ValidateApiKeyRequest{ApiKey: created.Key})
+	assert.Equal(t, x.CodeHTTPUnauthorized, x.ErrCode(err))
+}
+
+func TestRotateApiKeyKeepsOldKeyForGracePeriod(t *testing.T) {
+	svc, _, f := newTestApiKeyService(t, ApiKeyOptions{RotationGrace: time.Hour})
+	ctx := context.Background()
+
+	created, err := svc.CreateApiKey(ctx, &dto.CreateApiKeyRequest{
+		UserId:    testUserID,
+		Name:      "ci",
+		Scopes:    []string{authz.PermOrderReadAny},
+		ExpiresIn: 24 * 3600,
+	})
+	require.NoError(t, err)
+
+	_, err = svc.RotateApiKey(ctx, &dto.RotateApiKeyRequest{UserId: "someone-else", KeyId: created.ApiKey.KeyId})
+	require.Error(t, err)
+	assert.Equal(t, x.CodeHTTPNotFound, x.ErrCode(err))
+
+	rotated, err := svc.RotateApiKey(ctx, &dto.RotateApiKeyRequest{UserId: testUserID, KeyId: created.ApiKey.KeyId})
+	require.NoError(t, err)
+	assert.NotEqual(t, created.Key, rotated.Key)
+	assert.NotEqual(t, created.ApiKey.KeyId, rotated.ApiKey.KeyId)
+	assert.Equal(t, created.ApiKey.Name, rotated.ApiKey.Name)
+	assert.Equal(t, created.ApiKey.Scopes, rotated.ApiKey.Scopes)
+	require.NotNil(t, rotated.ApiKey.ExpiresAt)
+	assert.WithinDuration(t, time.Now().Add(24*time.Hour), *rotated.ApiKey.ExpiresAt, time.Minute)
+
+	old := f.keys[created.ApiKey.KeyId]
+	assert.WithinDuration(t, time.Now().Add(time.Hour), *old.ExpiresAt, time.Minute)
+
+	for _, key := range []string{created.Key, rotated.Key} {
+		_, err := svc.ValidateApiKey(ctx, &dto.ValidateApiKeyRequest{ApiKey: key})
+		assert.NoError(t, err)
+	}
+}
@@ -0,0 +1,181 @@
+package auth
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/model/entity"
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+	userpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/user"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	activityLoginFailed   = "login_failed"
+	activityAccountLocked = "account_locked"
+
+	activityTimeout = 5 * time.Second
+)
+
+// LockoutOptions throttles failed logins. The first failure for an email is
+// free; each later one makes the email wait BaseDelay, doubling up to
+// MaxDelay. MaxFailures within Window locks the account for LockDuration.
+// MaxIPFailures within Window blocks the client IP for whatever remains of
+// the window, whichever emails it tried.
+type LockoutOptions struct {
+	MaxFailures   int64         `yaml:"max_failures"`
+	MaxIPFailures int64         `yaml:"max_ip_failures"`
+	Window        time.Duration `yaml:"window"`
+	LockDuration  time.Duration `yaml:"lock_duration"`
+	BaseDelay     time.Duration `yaml:"base_delay"`
+	MaxDelay      time.Duration `yaml:"max_delay"`
+}
+
+func (o LockoutOptions) withDefaults() LockoutOptions {
+	if o.MaxFailures <= 0 {
+		o.MaxFailures = 5
+	}
+	if o.MaxIPFailures <= 0 {
+		o.MaxIPFailures = 50
+	}
+	if o.Window <= 0 {
+		o.Window = 15 * time.Minute
+	}
+	if o.LockDuration <= 0 {
+		o.LockDuration = 15 * time.Minute
+	}
+	if o.BaseDelay <= 0 {
+		o.BaseDelay = time.Second
+	}
+	if o.MaxDelay <= 0 {
+		o.MaxDelay = 30 * time.Second
+	}
+
+	return o
+}
+
+// delay returns how long an email must wait after its nth failure.
+func (o LockoutOptions) delay(failures int64) time.Duration {
+	if failures < 2 {
+		return 0
+	}
+
+	shift := failures - 2
+	if shift >= 32 || o.BaseDelay > time.Duration(math.MaxInt64>>shift) {
+		return o.MaxDelay
+	}
+
+	return min(o.BaseDelay<<shift, o.MaxDelay)
+}
+
+// checkLoginThrottle rejects a login before the password is looked at when
+// the client IP has failed too often or the email is serving a delay.
+func (a *authService) checkLoginThrottle(ctx context.Context, email string, ipAddress string) error {
+	failures, err := a.authRepository.GetLoginFailures(ctx, email, ipAddress)
+	if err != nil {
+		// Fail open: a Redis outage must not stop every login
+		zerolog.Ctx(ctx).Error().Err(err).Msg("get_login_failures")
+		return nil
+	}
+
+	if failures.IP >= a.authOptions.Lockout.MaxIPFailures {
+		zerolog.Ctx(ctx).Warn().Str("ip", ipAddress).Int64("failures", failures.IP).Msg("login_ip_blocked")
+		return x.NewWithCode(x.CodeHTTPTooManyRequest, "Too many failed login attempts from this address")
+	}
+
+	if !failures.RetryAt.IsZero() {
+		return x.NewWithCode(x.CodeHTTPTooManyRequest, "Too many failed login attempts, retry in %s", time.Until(failures.RetryAt).Round(time.Second))
+	}
+
+	return nil
+}
+
+func checkAccountLock(userAuth *entity.UserAuth) error {
+	if userAuth.LockedUntil != nil && time.Now().Before(*userAuth.LockedUntil) {
+		return x.NewWithCode(x.CodeHTTPTooManyRequest, "Account is locked until %s", userAuth.LockedUntil.UTC().Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+// loginFailed counts a failed attempt and applies its consequence: a delay
+// for the email, or a lock once the account reaches MaxFailures. userAuth is
+// nil when the email has no account, which is still counted so probing
+// unknown emails is throttled the same way. Errors are logged, not returned,
+// so the caller always answers with the original failure.
+func (a *authService) loginFailed(ctx context.Context, userAuth *entity.UserAuth, email string, ipAddress string) {
+	opts := a.authOptions.Lockout
+
+	failures, err := a.authRepository.RecordLoginFailure(ctx, email, ipAddress, opts.Window)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("record_login_failure")
+		return
+	}
+
+	if userAuth != nil && failures.Email >= opts.MaxFailures {
+		until := time.Now().Add(opts.LockDuration)
+		if err := a.authRepository.LockAuthUser(ctx, userAuth.ID, until); err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("lock_auth_user")
+			return
+		}
+
+		// The lock takes over; the next window starts clean once it expires
+		if err := a.authRepository.ClearLoginFailures(ctx, email); err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("clear_login_failures")
+		}
+
+		zerolog.Ctx(ctx).Warn().Str("authID", userAuth.ID).Time("lockedUntil", until).Msg("account_locked")
+		a.logActivity(ctx, userAuth.ID, activityAccountLocked, map[string]string{
+			"ip_address":   ipAddress,
+			"failures":     strconv.FormatInt(failures.Email, 10),
+			"locked_until": until.UTC().Format(time.RFC3339),
+		})
+		return
+	}
+
+	if delay := opts.delay(failures.Email); delay > 0 {
+		if err := a.authRepository.DelayLogin(ctx, email, delay); err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("delay_login")
+		}
+	}
+
+	if userAuth != nil {
+		a.logActivity(ctx, userAuth.ID, activityLoginFailed, map[string]string{
+			"ip_address": ipAddress,
+			"failures":   strconv.FormatInt(failures.Email, 10),
+		})
+	}
+}
+
+// logActivity records an event in the user's activity log. It runs in the
+// background and only logs failures, so login never waits on user-service.
+func (a *authService) logActivity(ctx context.Context, authID string, activityType string, metadata map[string]string) {
+	if a.userClient == nil {
+		return
+	}
+
+	log := zerolog.Ctx(ctx).With().Str("authID", authID).Str("activity", activityType).Logger()
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), activityTimeout)
+
+	go func() {
+		defer cancel()
+
+		user, err := a.userClient.GetUserByAuthId(ctx, &userpb.GetUserByAuthIdRequest{AuthId: authID})
+		if err != nil || !user.Found {
+			log.Warn().Err(err).Msg("log_activity_user_not_found")
+			return
+		}
+
+		_, err = a.userClient.LogActivity(ctx, &userpb.LogActivityRequest{
+			UserId:       user.Id,
+			ActivityType: activityType,
+			Metadata:     metadata,
+		})
+		if err != nil {
+			log.Warn().Err(err).Msg("log_activity_failed")
+		}
+	}()
+}
@@ -0,0 +1,142 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/model/entity"
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var testLockout = LockoutOptions{
+	MaxFailures:   5,
+	MaxIPFailures: 50,
+	Window:        15 * time.Minute,
+	LockDuration:  15 * time.Minute,
+	BaseDelay:     time.Second,
+	MaxDelay:      30 * time.Second,
+}
+
+func setupLockoutService(repo *MockAuthRepository) *authService {
+	return &authService{
+		authRepository: repo,
+		authOptions:    Options{Lockout: testLockout},
+	}
+}
+
+func TestLockoutOptionsDelay(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     LockoutOptions
+		failures int64
+		want     time.Duration
+	}{
+		{name: "first failure is free", opts: testLockout, failures: 1, want: 0},
+		{name: "no failures", opts: testLockout, failures: 0, want: 0},
+		{name: "second failure waits base", opts: testLockout, failures: 2, want: time.Second},
+		{name: "doubles", opts: testLockout, failures: 4, want: 4 * time.Second},
+		{name: "capped at max", opts: testLockout, failures: 10, want: 30 * time.Second},
+		{name: "huge count does not overflow", opts: testLockout, failures: 1 << 40, want: 30 * time.Second},
+		{name: "large base does not overflow", opts: LockoutOptions{BaseDelay: time.Hour, MaxDelay: 2 * time.Hour}, failures: 31, want: 2 * time.Hour},
+		{name: "defaults", opts: LockoutOptions{}.withDefaults(), failures: 3, want: 2 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.opts.delay(tt.failures))
+		})
+	}
+}
+
+func TestCheckLoginThrottle(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures *entity.LoginFailures
+		err      error
+		wantErr  bool
+	}{
+		{name: "clean", failures: &entity.LoginFailures{}},
+		{name: "ip below limit", failures: &entity.LoginFailures{IP: 49}},
+		{name: "ip blocked", failures: &entity.LoginFailures{IP: 50}, wantErr: true},
+		{name: "email serving a delay", failures: &entity.LoginFailures{Email: 2, RetryAt: time.Now().Add(time.Second)}, wantErr: true},
+		{name: "redis down fails open", err: errors.New("connection refused")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockAuthRepository)
+			repo.On("GetLoginFailures", mock.Anything, testEmail, testIPAddress).Return(tt.failures, tt.err)
+
+			err := setupLockoutService(repo).checkLoginThrottle(context.Background(), testEmail, testIPAddress)
+
+			if tt.wantErr {
+				assert.Equal(t, x.CodeHTTPTooManyRequest, x.ErrCode(err))
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestLoginFailedFirstFailureIsFree(t *testing.T) {
+	repo := new(MockAuthRepository)
+	repo.On("RecordLoginFailure", mock.Anything, testEmail, testIPAddress, testLockout.Window).Return(&entity.LoginFailures{Email: 1, IP: 1}, nil).Once()
+
+	setupLockoutService(repo).loginFailed(context.Background(), newTestUser(), testEmail, testIPAddress)
+
+	repo.AssertExpectations(t)
+	repo.AssertNotCalled(t, "DelayLogin", mock.Anything, mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "LockAuthUser", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestLoginFailedDelaysEmail(t *testing.T) {
+	repo := new(MockAuthRepository)
+	repo.On("RecordLoginFailure", mock.Anything, testEmail, testIPAddress, testLockout.Window).Return(&entity.LoginFailures{Email: 3, IP: 3}, nil).Once()
+	repo.On("DelayLogin", mock.Anything, testEmail, 2*time.Second).Return(nil).Once()
+
+	setupLockoutService(repo).loginFailed(context.Background(), newTestUser(), testEmail, testIPAddress)
+
+	repo.AssertExpectations(t)
+	repo.AssertNotCalled(t, "LockAuthUser", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestLoginFailedLocksAccount(t *testing.T) {
+	repo := new(MockAuthRepository)
+	repo.On("RecordLoginFailure", mock.Anything, testEmail, testIPAddress, testLockout.Window).Return(&entity.LoginFailures{Email: 5, IP: 5}, nil).Once()
+	repo.On("LockAuthUser", mock.Anything, testUserID, mock.MatchedBy(func(until time.Time) bool {
+		return time.Until(until) > testLockout.LockDuration-time.Minute
+	})).Return(nil).Once()
+	repo.On("ClearLoginFailures", mock.Anything, testEmail).Return(nil).Once()
+
+	setupLockoutService(repo).loginFailed(context.Background(), newTestUser(), testEmail, testIPAddress)
+
+	repo.AssertExpectations(t)
+	repo.AssertNotCalled(t, "DelayLogin", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestLoginFailedUnknownEmailIsDelayedNotLocked(t *testing.T) {
+	repo := new(MockAuthRepository)
+	repo.On("RecordLoginFailure", mock.Anything, testEmail, testIPAddress, testLockout.Window).Return(&entity.LoginFailures{Email: 9, IP: 9}, nil).Once()
+	repo.On("DelayLogin", mock.Anything, testEmail, testLockout.MaxDelay).Return(nil).Once()
+
+	setupLockoutService(repo).loginFailed(context.Background(), nil, testEmail, testIPAddress)
+
+	repo.AssertExpectations(t)
+	repo.AssertNotCalled(t, "LockAuthUser", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestLoginFailedRecordErrorStops(t *testing.T) {
+	repo := new(MockAuthRepository)
+	repo.On("RecordLoginFailure", mock.Anything, testEmail, testIPAddress, testLockout.Window).Return(nil, errors.New("connection refused")).Once()
+
+	setupLockoutService(repo).loginFailed(context.Background(), newTestUser(), testEmail, testIPAddress)
+
+	repo.AssertExpectations(t)
+	repo.AssertNotCalled(t, "DelayLogin", mock.Anything, mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "LockAuthUser", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,330 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"strings"
+	"time"
+
+	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/model/dto"
+	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/model/entity"
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+	"github.com/linggaaskaedo/go-kill/common/pkg/totp"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	activityMfaEnabled  = "mfa_enabled"
+	activityMfaDisabled = "mfa_disabled"
+
+	recoveryCodeSize = 10
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// MfaOptions configures TOTP MFA. Skew is how many 30s steps either side of
+// the current one are accepted, to allow for clock drift on the phone.
+type MfaOptions struct {
+	Issuer        string        `yaml:"issuer"`
+	Skew          int64         `yaml:"skew"`
+	ChallengeTTL  time.Duration `yaml:"challenge_ttl"`
+	MaxAttempts   int64         `yaml:"max_attempts"`
+	RecoveryCodes int           `yaml:"recovery_codes"`
+}
+
+func (o MfaOptions) withDefaults() MfaOptions {
+	if o.Issuer == "" {
+		o.Issuer = "Go-Kill"
+	}
+	if o.Skew < 0 {
+		o.Skew = 0
+	}
+	if o.ChallengeTTL <= 0 {
+		o.ChallengeTTL = 5 * time.Minute
+	}
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = 5
+	}
+	if o.RecoveryCodes <= 0 {
+		o.RecoveryCodes = 10
+	}
+
+	return o
+}
+
+// generateRecoveryCodes returns n codes of 50 random bits each, shown to the
+// user as XXXXX-XXXXX.
+func generateRecoveryCodes(n int) ([]string, error) {
+	codes := make([]string, n)
+	for i := range codes {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+
+		code := recoveryCodeEncoding.EncodeToString(b)[:recoveryCodeSize]
+		codes[i] = code[:5] + "-" + code[5:]
+	}
+
+	return codes, nil
+}
+
+// normalizeRecoveryCode accepts a recovery code typed with or without the
+// dash and in either case.
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	if len(code) != recoveryCodeSize {
+		return code
+	}
+
+	return code[:5] + "-" + code[5:]
+}
+
+// EnrollMfa issues a new secret. Calling it again before ConfirmMfa replaces
+// the secret, so a user who lost the QR code can start over.
+func (a *authService) EnrollMfa(ctx context.Context, req *dto.EnrollMfaRequest) (*dto.EnrollMfaResponse, error) {
+	userAuth, err := a.authRepository.FindAuthUserByID(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := totp.NewSecret()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("generate_mfa_secret")
+		return nil, x.Wrap(err, "generate_mfa_secret")
+	}
+
+	if err := a.authRepository.SaveMfaSecret(ctx, req.UserId, secret); err != nil {
+		return nil, mfaError(err)
+	}
+
+	return &dto.EnrollMfaResponse{
+		Secret:     secret,
+		OtpauthUri: totp.URI(a.authOptions.Mfa.Issuer, userAuth.Email, secret),
+	}, nil
+}
+
+// ConfirmMfa turns MFA on once the user proves their authenticator works,
+// and hands out the recovery codes. They are only ever returned here.
+func (a *authService) ConfirmMfa(ctx context.Context, req *dto.ConfirmMfaRequest) (*dto.ConfirmMfaResponse, error) {
+	userMfa, err := a.authRepository.GetUserMfa(ctx, req.UserId)
+	if err != nil {
+		if x.ErrCode(err) == x.CodeSQLRecordDoesNotExist {
+			return nil, x.WrapWithCode(err, x.CodeHTTPUnprocessableEntity, "MFA enrolment has not been started")
+		}
+		return nil, err
+	}
+
+	if userMfa.EnabledAt != nil {
+		return nil, x.NewWithCode(x.CodeHTTPConflict, "MFA is already enabled")
+	}
+
+	step, ok := totp.Validate(userMfa.Secret, req.Code, a.now(), a.authOptions.Mfa.Skew)
+	if !ok {
+		return nil, x.NewWithCode(x.CodeHTTPBadRequest, "Invalid MFA code")
+	}
+
+	codes, err := generateRecoveryCodes(a.authOptions.Mfa.RecoveryCodes)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("generate_recovery_codes")
+		return nil, x.Wrap(err, "generate_recovery_codes")
+	}
+
+	if err := a.authRepository.EnableMfa(ctx, req.UserId, step, codes); err != nil {
+		return nil, mfaError(err)
+	}
+
+	a.logActivity(ctx, req.UserId, activityMfaEnabled, nil)
+
+	return &dto.ConfirmMfaResponse{
+		Success:       true,
+		RecoveryCodes: codes,
+	}, nil
+}
+
+// DisableMfa turns MFA off. It takes a current code or a recovery code so a
+// stolen access token alone cannot remove the second factor.
+func (a *authService) DisableMfa(ctx context.Context, req *dto.DisableMfaRequest) (*dto.DisableMfaResponse, error) {
+	userMfa, err := a.authRepository.GetUserMfa(ctx, req.UserId)
+	if err != nil && x.ErrCode(err) != x.CodeSQLRecordDoesNotExist {
+		return nil, err
+	}
+
+	if userMfa == nil || userMfa.EnabledAt == nil {
+		return nil, x.NewWithCode(x.CodeHTTPUnprocessableEntity, "MFA is not enabled")
+	}
+
+	ok, err := a.checkMfaCode(ctx, userMfa, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, x.NewWithCode(x.CodeHTTPBadRequest, "Invalid MFA code")
+	}
+
+	if err := a.authRepository.DisableMfa(ctx, req.UserId); err != nil {
+		return nil, err
+	}
+
+	a.logActivity(ctx, req.UserId, activityMfaDisabled, nil)
+
+	return &dto.DisableMfaResponse{Success: true}, nil
+}
+
+// startMfaChallenge is the end of a password login for an MFA user: instead
+// of tokens it returns a short-lived challenge token for VerifyMfa.
+func (a *authService) startMfaChallenge(ctx context.Context, userAuth *entity.UserAuth, ipAddress string, userAgent string) (*dto.LoginResponse, error) {
+	mfaToken, err := generateAccountToken()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("generate_mfa_token")
+		return nil, x.Wrap(err, "generate_mfa_token")
+	}
+
+	err = a.authRepository.StoreMfaChallenge(ctx, mfaToken, &entity.MfaChallenge{
+		UserID:    userAuth.ID,
+		Email:     userAuth.Email,
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+	}, a.authOptions.Mfa.ChallengeTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.LoginResponse{
+		Success:     true,
+		MfaRequired: true,
+		MfaToken:    mfaToken,
+	}, nil
+}
+
+// VerifyMfa finishes a login started by Login. Wrong codes count as failed
+// logins for the email, so they are delayed and lock the account like wrong
+// passwords do. A challenge also allows only MaxAttempts wrong codes, after
+// which the user has to enter the password again.
+func (a *authService) VerifyMfa(ctx context.Context, req *dto.VerifyMfaRequest) (*dto.VerifyMfaResponse, error) {
+	challenge, err := a.authRepository.GetMfaChallenge(ctx, req.MfaToken)
+	if err != nil {
+		if x.ErrCode(err) == x.CodeCacheNotFound {
+			return nil, x.WrapWithCode(err, x.CodeHTTPUnauthorized, "Invalid or expired MFA token")
+		}
+		return nil, err
+	}
+
+	ipAddress := req.IpAddress
+	if ipAddress == "" {
+		ipAddress = challenge.IPAddress
+	}
+
+	userAgent := req.UserAgent
+	if userAgent == "" {
+		userAgent = challenge.UserAgent
+	}
+
+	if err := a.checkLoginThrottle(ctx, challenge.Email, ipAddress); err != nil {
+		return nil, err
+	}
+
+	userAuth, err := a.authRepository.FindAuthUserByID(ctx, challenge.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The account may have been locked since the challenge was issued
+	if err := checkAccountLock(userAuth); err != nil {
+		return nil, err
+	}
+
+	userMfa, err := a.authRepository.GetUserMfa(ctx, challenge.UserID)
+	if err != nil && x.ErrCode(err) != x.CodeSQLRecordDoesNotExist {
+		return nil, err
+	}
+
+	ok := false
+	if userMfa != nil && userMfa.EnabledAt != nil {
+		if ok, err = a.checkMfaCode(ctx, userMfa, req.Code); err != nil {
+			return nil, err
+		}
+	}
+
+	if !ok {
+		a.mfaFailed(ctx, req.MfaToken, userAuth, challenge.Email, ipAddress)
+		return nil, x.NewWithCode(x.CodeHTTPUnauthorized, "Invalid MFA code")
+	}
+
+	consumed, err := a.authRepository.ConsumeMfaChallenge(ctx, req.MfaToken)
+	if err != nil {
+		return nil, err
+	}
+	if !consumed {
+		return nil, x.NewWithCode(x.CodeHTTPUnauthorized, "Invalid or expired MFA token")
+	}
+
+	if err := a.authRepository.ClearLoginFailures(ctx, challenge.Email); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("clear_login_failures")
+	}
+
+	resp, err := a.issueSession(ctx, userAuth, ipAddress, userAgent)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.VerifyMfaResponse{
+		Success:      resp.Success,
+		AccessToken:  resp.AccessToken,
+		RefreshToken: resp.RefreshToken,
+		ExpiresIn:    resp.ExpiresIn,
+	}, nil
+}
+
+// checkMfaCode accepts a TOTP code whose step has not been used yet, or an
+// unused recovery code, using it up either way.
+func (a *authService) checkMfaCode(ctx context.Context, userMfa *entity.UserMfa, code string) (bool, error) {
+	if step, ok := totp.Validate(userMfa.Secret, code, a.now(), a.authOptions.Mfa.Skew); ok {
+		return a.authRepository.UseMfaStep(ctx, userMfa.UserID, step)
+	}
+
+	normalized := normalizeRecoveryCode(code)
+	if len(normalized) != recoveryCodeSize+1 {
+		return false, nil
+	}
+
+	used, err := a.authRepository.UseMfaRecoveryCode(ctx, userMfa.UserID, normalized)
+	if err == nil && used {
+		zerolog.Ctx(ctx).Info().Str("authID", userMfa.UserID).Msg("mfa_recovery_code_used")
+	}
+
+	return used, err
+}
+
+// mfaFailed counts a wrong code against the login throttle of the email
+// and against the challenge, which is dropped after MaxAttempts.
+func (a *authService) mfaFailed(ctx context.Context, mfaToken string, userAuth *entity.UserAuth, email string, ipAddress string) {
+	a.loginFailed(ctx, userAuth, email, ipAddress)
+
+	attempts, err := a.authRepository.RecordMfaFailure(ctx, mfaToken)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("record_mfa_failure")
+		return
+	}
+
+	if attempts < a.authOptions.Mfa.MaxAttempts {
+		return
+	}
+
+	if _, err := a.authRepository.ConsumeMfaChallenge(ctx, mfaToken); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("drop_mfa_challenge")
+	}
+
+	zerolog.Ctx(ctx).Warn().Str("authID", userAuth.ID).Int64("attempts", attempts).Msg("mfa_challenge_exhausted")
+}
+
+// mfaError turns a lost race with another enrolment request into a conflict.
+func mfaError(err error) error {
+	if x.ErrCode(err) == x.CodeSQLUniqueConstraint {
+		return x.WrapWithCode(err, x.CodeHTTPConflict, "MFA is already enabled")
+	}
+
+	return err
+}
@@ -0,0 +1,266 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/model/dto"
+	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/model/entity"
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+	"github.com/linggaaskaedo/go-kill/common/pkg/totp"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func setupMfaService(repo *MockAuthRepository, now *time.Time) *authService {
+	return &authService{
+		authRepository: repo,
+		authOptions:    Options{Mfa: MfaOptions{Skew: 1}.withDefaults()},
+		now:            func() time.Time { return *now },
+	}
+}
+
+// expectMfaEnrolment has the repository keep the secret and recovery codes
+// the service hands it in userMfa.
+func expectMfaEnrolment(repo *MockAuthRepository, userMfa *entity.UserMfa) {
+	repo.On("FindAuthUserByID", mock.Anything, testUserID).Return(newTestUser(), nil)
+	repo.On("SaveMfaSecret", mock.Anything, testUserID, mock.Anything).
+		Run(func(args mock.Arguments) { userMfa.Secret = args.String(2) }).
+		Return(nil)
+	repo.On("GetUserMfa", mock.Anything, testUserID).Return(userMfa, nil)
+	repo.On("EnableMfa", mock.Anything, testUserID, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			enabledAt := time.Now()
+			userMfa.EnabledAt = &enabledAt
+			userMfa.LastUsedStep = args.Get(2).(int64)
+		}).
+		Return(nil)
+}
+
+func codeAt(t *testing.T, secret string, at time.Time) string {
+	t.Helper()
+
+	code, err := totp.Code(secret, totp.Step(at))
+	require.NoError(t, err)
+
+	return code
+}
+
+func TestMfaEnrolmentAndCodeReuse(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	step := totp.Step(now)
+	userMfa := &entity.UserMfa{UserID: testUserID}
+
+	repo := &MockAuthRepository{}
+	expectMfaEnrolment(repo, userMfa)
+	repo.On("UseMfaStep", mock.Anything, testUserID, step).Return(false, nil)
+	repo.On("UseMfaStep", mock.Anything, testUserID, step+1).Return(true, nil).Once()
+	svc := setupMfaService(repo, &now)
+
+	enrolled, err := svc.EnrollMfa(ctx, &dto.EnrollMfaRequest{UserId: testUserID})
+	require.NoError(t, err)
+	assert.Contains(t, enrolled.OtpauthUri, "otpauth://totp/")
+	assert.Contains(t, enrolled.OtpauthUri, "secret="+enrolled.Secret)
+	assert.Equal(t, enrolled.Secret, userMfa.Secret)
+
+	_, err = svc.ConfirmMfa(ctx, &dto.ConfirmMfaRequest{UserId: testUserID, Code: "000000"})
+	assert.Equal(t, x.CodeHTTPBadRequest, x.ErrCode(err))
+
+	confirmed, err := svc.ConfirmMfa(ctx, &dto.ConfirmMfaRequest{UserId: testUserID, Code: codeAt(t, enrolled.Secret, now)})
+	require.NoError(t, err)
+	assert.Len(t, confirmed.RecoveryCodes, 10)
+	assert.Equal(t, step, userMfa.LastUsedStep)
+
+	_, err = svc.ConfirmMfa(ctx, &dto.ConfirmMfaRequest{UserId: testUserID, Code: codeAt(t, enrolled.Secret, now)})
+	assert.Equal(t, x.CodeHTTPConflict, x.ErrCode(err))
+
+	// The code that confirmed enrolment cannot be used again in its window
+	ok, err := svc.checkMfaCode(ctx, userMfa, codeAt(t, enrolled.Secret, now))
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// Thirty seconds later the next code works, and a code one step old
+	// is still inside the skew but already used
+	now = now.Add(totp.Period)
+	ok, err = svc.checkMfaCode(ctx, userMfa, codeAt(t, enrolled.Secret, now))
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = svc.checkMfaCode(ctx, userMfa, codeAt(t, enrolled.Secret, now.Add(-totp.Period)))
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	repo.AssertExpectations(t)
+}
+
+func TestMfaRecoveryCodeWorksOnce(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	userMfa := &entity.UserMfa{UserID: testUserID}
+
+	repo := &MockAuthRepository{}
+	expectMfaEnrolment(repo, userMfa)
+	svc := setupMfaService(repo, &now)
+
+	enrolled, err := svc.EnrollMfa(ctx, &dto.EnrollMfaRequest{UserId: testUserID})
+	require.NoError(t, err)
+
+	confirmed, err := svc.ConfirmMfa(ctx, &dto.ConfirmMfaRequest{UserId: testUserID, Code: codeAt(t, enrolled.Secret, now)})
+	require.NoError(t, err)
+
+	code := confirmed.RecoveryCodes[0]
+	typed := code[:5] + code[6:] // entered without the dash
+
+	repo.On("UseMfaRecoveryCode", mock.Anything, testUserID, code).Return(true, nil).Once()
+	repo.On("UseMfaRecoveryCode", mock.Anything, testUserID, code).Return(false, nil).Once()
+
+	ok, err := svc.checkMfaCode(ctx, userMfa, typed)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = svc.checkMfaCode(ctx, userMfa, code)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	repo.AssertExpectations(t)
+}
+
+func TestVerifyMfaFailuresLockAccountAcrossLogins(t *testing.T) {
+	const password = "correct horse battery"
+
+	ctx := context.Background()
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	secret := "JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"
+	enabledAt := now.Add(-24 * time.Hour)
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	user := newTestUser()
+	user.PasswordHash = string(hash)
+	user.MfaEnabled = true
+
+	// The repository counts failures per email and keeps one pending
+	// challenge per token, like the Redis keys behind it
+	var failures int64
+	challenges := map[string]*entity.MfaChallenge{}
+	attempts := map[string]int64{}
+
+	repo := &MockAuthRepository{}
+	repo.On("GetLoginFailures", mock.Anything, testEmail, testIPAddress).Return(&entity.LoginFailures{}, nil)
+	repo.On("FindAuthUserByEmail", mock.Anything, testEmail).Return(user, nil)
+	repo.On("FindAuthUserByID", mock.Anything, testUserID).Return(user, nil)
+	repo.On("RehashPassword", mock.Anything, testUserID, mock.Anything, mock.Anything).Return(nil).Maybe()
+	repo.On("GetUserMfa", mock.Anything, testUserID).Return(&entity.UserMfa{UserID: testUserID, Secret: secret, EnabledAt: &enabledAt}, nil)
+	repo.On("StoreMfaChallenge", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { challenges[args.String(1)] = args.Get(2).(*entity.MfaChallenge) }).
+		Return(nil)
+
+	getChallenge := repo.On("GetMfaChallenge", mock.Anything, mock.Anything)
+	getChallenge.Run(func(args mock.Arguments) {
+		challenge, ok := challenges[args.String(1)]
+		if !ok {
+			getChallenge.ReturnArguments = mock.Arguments{nil, x.NewWithCode(x.CodeCacheNotFound, "mfa_challenge_not_found")}
+			return
+		}
+		getChallenge.ReturnArguments = mock.Arguments{challenge, nil}
+	})
+
+	recordMfa := repo.On("RecordMfaFailure", mock.Anything, mock.Anything)
+	recordMfa.Run(func(args mock.Arguments) {
+		attempts[args.String(1)]++
+		recordMfa.ReturnArguments = mock.Arguments{attempts[args.String(1)], nil}
+	})
+
+	repo.On("ConsumeMfaChallenge", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { delete(challenges, args.String(1)) }).
+		Return(true, nil)
+
+	recordLogin := repo.On("RecordLoginFailure", mock.Anything, testEmail, testIPAddress, mock.Anything)
+	recordLogin.Run(func(args mock.Arguments) {
+		failures++
+		recordLogin.ReturnArguments = mock.Arguments{&entity.LoginFailures{Email: failures, IP: failures}, nil}
+	})
+
+	repo.On("DelayLogin", mock.Anything, testEmail, mock.Anything).Return(nil)
+	repo.On("ClearLoginFailures", mock.Anything, testEmail).
+		Run(func(args mock.Arguments) { failures = 0 }).
+		Return(nil)
+	repo.On("LockAuthUser", mock.Anything, testUserID, mock.Anything).
+		Run(func(args mock.Arguments) {
+			until := args.Get(2).(time.Time)
+			user.LockedUntil = &until
+		}).
+		Return(nil).Once()
+
+	svc := newTestAuthService(t, repo, Options{Mfa: MfaOptions{MaxAttempts: 3}})
+	svc.now = func() time.Time { return now }
+
+	wrongCode := codeAt(t, secret, now.Add(-time.Hour))
+	login := func() string {
+		resp, err := svc.Login(ctx, &dto.LoginRequest{Email: testEmail, Password: password, IpAddress: testIPAddress})
+		require.NoError(t, err)
+		require.True(t, resp.MfaRequired)
+		return resp.MfaToken
+	}
+	verify := func(mfaToken string, code string) error {
+		_, err := svc.VerifyMfa(ctx, &dto.VerifyMfaRequest{MfaToken: mfaToken, Code: code, IpAddress: testIPAddress})
+		return err
+	}
+
+	// The first challenge is used up after MaxAttempts wrong codes
+	first := login()
+	for range 3 {
+		assert.Equal(t, x.CodeHTTPUnauthorized, x.ErrCode(verify(first, wrongCode)))
+	}
+	assert.Equal(t, x.CodeHTTPUnauthorized, x.ErrCode(verify(first, codeAt(t, secret, now))))
+
+	// Entering the password again does not reset the count, so the fifth
+	// wrong code overall locks the account
+	second := login()
+	assert.EqualValues(t, 3, failures)
+	for range 2 {
+		assert.Equal(t, x.CodeHTTPUnauthorized, x.ErrCode(verify(second, wrongCode)))
+	}
+	require.NotNil(t, user.LockedUntil)
+
+	assert.Equal(t, x.CodeHTTPTooManyRequest, x.ErrCode(verify(second, codeAt(t, secret, now))))
+
+	_, err = svc.Login(ctx, &dto.LoginRequest{Email: testEmail, Password: password, IpAddress: testIPAddress})
+	assert.Equal(t, x.CodeHTTPTooManyRequest, x.ErrCode(err))
+
+	repo.AssertNotCalled(t, "UseMfaStep", mock.Anything, mock.Anything, totp.Step(now))
+	repo.AssertNotCalled(t, "StoreSession", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestVerifyMfaSuccessClearsLoginFailures(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	secret := "JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"
+	enabledAt := now.Add(-24 * time.Hour)
+	challenge := &entity.MfaChallenge{UserID: testUserID, Email: testEmail, IPAddress: testIPAddress}
+
+	repo := &MockAuthRepository{}
+	repo.On("GetMfaChallenge", mock.Anything, "mfa-token").Return(challenge, nil)
+	repo.On("GetLoginFailures", mock.Anything, testEmail, testIPAddress).Return(&entity.LoginFailures{Email: 2}, nil)
+	repo.On("FindAuthUserByID", mock.Anything, testUserID).Return(newTestUser(), nil)
+	repo.On("GetUserMfa", mock.Anything, testUserID).Return(&entity.UserMfa{UserID: testUserID, Secret: secret, EnabledAt: &enabledAt}, nil)
+	repo.On("UseMfaStep", mock.Anything, testUserID, totp.Step(now)).Return(true, nil).Once()
+	repo.On("ConsumeMfaChallenge", mock.Anything, "mfa-token").Return(true, nil).Once()
+	repo.On("ClearLoginFailures", mock.Anything, testEmail).Return(nil).Once()
+	repo.On("FindUserRoles", mock.Anything, testUserID).Return(&entity.UserRoles{}, nil)
+	repo.On("StoreSession", mock.Anything, mock.Anything, testEmail, mock.Anything, mock.Anything).Return("session-1", nil).Once()
+
+	svc := newTestAuthService(t, repo, Options{})
+	svc.now = func() time.Time { return now }
+
+	resp, err := svc.VerifyMfa(ctx, &dto.VerifyMfaRequest{MfaToken: "mfa-token", Code: codeAt(t, secret, now)})
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.AccessToken)
+	repo.AssertExpectations(t)
+}
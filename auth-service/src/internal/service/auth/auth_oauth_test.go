@@ -0,0 +1,231 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/model/dto"
+	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/model/entity"
+	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/provider/oauth"
+	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/provider/oauth/oauthtest"
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+	userpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+const testOAuthProvider = "mock"
+
+// expectOAuthStates has the repository hand each stored OAuth state back
+// once, the way the Redis GETDEL does.
+func expectOAuthStates(repo *MockAuthRepository) {
+	states := map[string]*entity.OAuthState{}
+
+	repo.On("StoreOAuthState", mock.Anything, mock.Anything, mock.Anything, defaultOAuthStateTTL).
+		Run(func(args mock.Arguments) { states[args.String(1)] = args.Get(2).(*entity.OAuthState) }).
+		Return(nil)
+
+	consume := repo.On("ConsumeOAuthState", mock.Anything, mock.Anything)
+	consume.Run(func(args mock.Arguments) {
+		oauthState, ok := states[args.String(1)]
+		if !ok {
+			consume.ReturnArguments = mock.Arguments{nil, x.NewWithCode(x.CodeCacheNotFound, "oauth_state_not_found")}
+			return
+		}
+
+		delete(states, args.String(1))
+		consume.ReturnArguments = mock.Arguments{oauthState, nil}
+	})
+}
+
+// expectOAuthSession covers issuing tokens to userAuth once it is resolved.
+func expectOAuthSession(repo *MockAuthRepository, userAuth *entity.UserAuth) {
+	repo.On("FindAuthUserByID", mock.Anything, userAuth.ID).Return(userAuth, nil)
+	repo.On("FindUserRoles", mock.Anything, userAuth.ID).Return(&entity.UserRoles{Roles: []string{entity.RoleCustomer}, Permissions: []string{}}, nil)
+	repo.On("StoreSession", mock.Anything, mock.Anything, userAuth.Email, mock.Anything, mock.Anything).Return("session-1", nil)
+}
+
+// fakeUserClient stands in for user-service and records the profiles created
+// through it.
+type fakeUserClient struct {
+	userpb.UserServiceClient
+
+	created []*userpb.CreateUserRequest
+}
+
+func (f *fakeUserClient) GetUserByAuthId(ctx context.Context, in *userpb.GetUserByAuthIdRequest, opts ...grpc.CallOption) (*userpb.GetUserResponse, error) {
+	for _, req := range f.created {
+		if req.AuthId == in.AuthId {
+			return &userpb.GetUserResponse{Id: "profile-" + req.AuthId, Found: true}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("user not found")
+}
+
+func (f *fakeUserClient) CreateUser(ctx context.Context, in *userpb.CreateUserRequest, opts ...grpc.CallOption) (*userpb.CreateUserResponse, error) {
+	f.created = append(f.created, in)
+	return &userpb.CreateUserResponse{Success: true, UserId: "profile-" + in.AuthId}, nil
+}
+
+func (f *fakeUserClient) LogActivity(ctx context.Context, in *userpb.LogActivityRequest, opts ...grpc.CallOption) (*userpb.LogActivityResponse, error) {
+	return &userpb.LogActivityResponse{}, nil
+}
+
+func newTestOAuthService(t *testing.T) (*authService, *MockAuthRepository, *fakeUserClient, *oauthtest.Server) {
+	t.Helper()
+
+	server := oauthtest.NewServer()
+	t.Cleanup(server.Close)
+
+	providers, err := oauth.NewProviders(oauth.Options{Providers: map[string]oauth.ProviderOptions{
+		testOAuthProvider: {
+			Type:         oauth.ProviderOIDC,
+			ClientID:     oauthtest.ClientID,
+			ClientSecret: oauthtest.ClientSecret,
+			RedirectURL:  "http://localhost/api/v1/auth/oauth/mock/callback",
+			Issuer:       server.URL,
+		},
+	}})
+	require.NoError(t, err)
+
+	repo := &MockAuthRepository{}
+	expectOAuthStates(repo)
+	userClient := &fakeUserClient{}
+	svc := &authService{
+		authRepository: repo,
+		userClient:     userClient,
+		keys:           newTestKeySet(t),
+		oauthProviders: providers,
+		authOptions:    Options{OAuth: oauth.Options{StateTTL: defaultOAuthStateTTL}},
+	}
+
+	return svc, repo, userClient, server
+}
+
+// oauthLogin runs the whole browser round trip against the mock provider.
+func oauthLogin(t *testing.T, svc *authService, server *oauthtest.Server) (*dto.LoginResponse, error) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	started, err := svc.StartOAuthLogin(ctx, &dto.StartOAuthLoginRequest{Provider: testOAuthProvider})
+	require.NoError(t, err)
+
+	code, state, err := server.Authorize(started.AuthorizationUrl)
+	require.NoError(t, err)
+	require.Equal(t, started.State, state)
+
+	return svc.CompleteOAuthLogin(ctx, &dto.CompleteOAuthLoginRequest{Provider: testOAuthProvider, Code: code, State: state})
+}
+
+func TestOAuthLoginCreatesUserOnFirstLogin(t *testing.T) {
+	svc, repo, userClient, server := newTestOAuthService(t)
+	server.SetUser(oauthtest.User{Subject: "sub-1", Email: "new@example.com", EmailVerified: true, Name: "Ada Lovelace"})
+
+	verifiedAt := time.Now()
+	created := &entity.UserAuth{ID: "user-new", Email: "new@example.com", IsActive: true, EmailVerifiedAt: &verifiedAt}
+	repo.On("FindUserIdentity", mock.Anything, testOAuthProvider, "sub-1").Return(nil, errNotFound("get_user_identity_sql")).Once()
+	repo.On("FindAuthUserByEmail", mock.Anything, "new@example.com").Return(nil, errNotFound("get_user_by_email_sql")).Once()
+	repo.On("CreateOAuthUser", mock.Anything, mock.MatchedBy(func(identity *entity.UserIdentity) bool {
+		return identity.Provider == testOAuthProvider && identity.Subject == "sub-1" && identity.Email == "new@example.com"
+	})).Return(created.ID, nil).Once()
+	expectOAuthSession(repo, created)
+
+	resp, err := oauthLogin(t, svc, server)
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.AccessToken)
+	require.Len(t, userClient.created, 1)
+	assert.Equal(t, "Ada", userClient.created[0].FirstName)
+	assert.Equal(t, "Lovelace", userClient.created[0].LastName)
+
+	// The second login finds the linked identity and the existing profile
+	repo.On("FindUserIdentity", mock.Anything, testOAuthProvider, "sub-1").Return(&entity.UserIdentity{ID: "identity-1", UserID: created.ID}, nil).Once()
+	repo.On("TouchUserIdentity", mock.Anything, "identity-1", "new@example.com").Return(nil).Once()
+
+	_, err = oauthLogin(t, svc, server)
+	require.NoError(t, err)
+	assert.Len(t, userClient.created, 1)
+	repo.AssertExpectations(t)
+}
+
+func TestOAuthLoginLinksExistingUserByVerifiedEmail(t *testing.T) {
+	svc, repo, userClient, server := newTestOAuthService(t)
+	existing := &entity.UserAuth{ID: "user-existing", Email: "known@example.com", IsActive: true}
+	userClient.created = append(userClient.created, &userpb.CreateUserRequest{AuthId: existing.ID})
+	server.SetUser(oauthtest.User{Subject: "sub-2", Email: "known@example.com", EmailVerified: true})
+
+	repo.On("FindUserIdentity", mock.Anything, testOAuthProvider, "sub-2").Return(nil, errNotFound("get_user_identity_sql"))
+	repo.On("FindAuthUserByEmail", mock.Anything, "known@example.com").Return(existing, nil)
+	repo.On("LinkUserIdentity", mock.Anything, mock.MatchedBy(func(identity *entity.UserIdentity) bool {
+		return identity.UserID == existing.ID && identity.Subject == "sub-2"
+	})).Return(nil).Once()
+	repo.On("FindTokenID", mock.Anything, mock.Anything).Return(false)
+	repo.On("FindRevokedSession", mock.Anything, mock.Anything).Return(false)
+	expectOAuthSession(repo, existing)
+
+	resp, err := oauthLogin(t, svc, server)
+	require.NoError(t, err)
+
+	validated, err := svc.ValidateToken(context.Background(), &dto.ValidateTokenRequest{Token: resp.AccessToken})
+	require.NoError(t, err)
+	assert.Equal(t, existing.ID, validated.UserId)
+	assert.Len(t, userClient.created, 1)
+	repo.AssertNotCalled(t, "CreateOAuthUser", mock.Anything, mock.Anything)
+	repo.AssertExpectations(t)
+}
+
+func TestOAuthLoginRejectsUnverifiedEmail(t *testing.T) {
+	svc, repo, _, server := newTestOAuthService(t)
+	server.SetUser(oauthtest.User{Subject: "sub-3", Email: "known@example.com", EmailVerified: false})
+
+	repo.On("FindUserIdentity", mock.Anything, testOAuthProvider, "sub-3").Return(nil, errNotFound("get_user_identity_sql"))
+
+	_, err := oauthLogin(t, svc, server)
+	require.Error(t, err)
+	assert.Equal(t, x.CodeHTTPForbidden, x.ErrCode(err))
+	repo.AssertNotCalled(t, "FindAuthUserByEmail", mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "LinkUserIdentity", mock.Anything, mock.Anything)
+}
+
+func TestOAuthLoginStateIsSingleUse(t *testing.T) {
+	svc, repo, _, server := newTestOAuthService(t)
+	server.SetUser(oauthtest.User{Subject: "sub-4", Email: "once@example.com", EmailVerified: true})
+	ctx := context.Background()
+
+	user := &entity.UserAuth{ID: "user-once", Email: "once@example.com", IsActive: true}
+	repo.On("FindUserIdentity", mock.Anything, testOAuthProvider, "sub-4").Return(&entity.UserIdentity{ID: "identity-4", UserID: user.ID}, nil)
+	repo.On("TouchUserIdentity", mock.Anything, "identity-4", user.Email).Return(nil)
+	expectOAuthSession(repo, user)
+
+	started, err := svc.StartOAuthLogin(ctx, &dto.StartOAuthLoginRequest{Provider: testOAuthProvider})
+	require.NoError(t, err)
+
+	code, state, err := server.Authorize(started.AuthorizationUrl)
+	require.NoError(t, err)
+
+	_, err = svc.CompleteOAuthLogin(ctx, &dto.CompleteOAuthLoginRequest{Provider: testOAuthProvider, Code: code, State: state})
+	require.NoError(t, err)
+
+	_, err = svc.CompleteOAuthLogin(ctx, &dto.CompleteOAuthLoginRequest{Provider: testOAuthProvider, Code: code, State: state})
+	require.Error(t, err)
+	assert.Equal(t, x.CodeHTTPUnauthorized, x.ErrCode(err))
+}
+
+func TestOAuthLoginRejectsForgedCode(t *testing.T) {
+	svc, _, _, server := newTestOAuthService(t)
+	server.SetUser(oauthtest.User{Subject: "sub-5", Email: "forged@example.com", EmailVerified: true})
+	ctx := context.Background()
+
+	started, err := svc.StartOAuthLogin(ctx, &dto.StartOAuthLoginRequest{Provider: testOAuthProvider})
+	require.NoError(t, err)
+
+	_, err = svc.CompleteOAuthLogin(ctx, &dto.CompleteOAuthLoginRequest{Provider: testOAuthProvider, Code: "forged", State: started.State})
+	require.Error(t, err)
+	assert.Equal(t, x.CodeHTTPUnauthorized, x.ErrCode(err))
+}
@@ -0,0 +1,307 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+
+	"github.com/rs/zerolog"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	HashBcrypt   = "bcrypt"
+	HashArgon2id = "argon2id"
+
+	// bcrypt ignores everything past the first 72 bytes
+	bcryptMaxBytes = 72
+)
+
+var errPasswordMismatch = errors.New("password does not match")
+
+// BreachedPasswordChecker looks a password up in a list of known breached
+// passwords.
+type BreachedPasswordChecker interface {
+	IsBreached(password string) (bool, error)
+}
+
+type PasswordOptions struct {
+	Policy PasswordPolicy      `yaml:"policy"`
+	Hash   PasswordHashOptions `yaml:"hash"`
+}
+
+// PasswordPolicy is checked on registration and password reset. Existing
+// passwords keep working when it is tightened.
+type PasswordPolicy struct {
+	MinLength     int  `yaml:"min_length"`
+	MaxLength     int  `yaml:"max_length"`
+	RequireUpper  bool `yaml:"require_upper"`
+	RequireLower  bool `yaml:"require_lower"`
+	RequireDigit  bool `yaml:"require_digit"`
+	RequireSymbol bool `yaml:"require_symbol"`
+
+	// AllowEmail permits passwords containing the user's email or its local part
+	AllowEmail bool `yaml:"allow_email"`
+}
+
+// PasswordHashOptions picks how new passwords are hashed. Hashes made with
+// another algorithm or other parameters still verify and are replaced on the
+// user's next successful login.
+type PasswordHashOptions struct {
+	Algorithm  string        `yaml:"algorithm"`
+	BcryptCost int           `yaml:"bcrypt_cost"`
+	Argon2id   Argon2Options `yaml:"argon2id"`
+}
+
+type Argon2Options struct {
+	Memory      uint32 `yaml:"memory"` // KiB
+	Iterations  uint32 `yaml:"iterations"`
+	Parallelism uint8  `yaml:"parallelism"`
+	SaltLength  uint32 `yaml:"salt_length"`
+	KeyLength   uint32 `yaml:"key_length"`
+}
+
+func (o PasswordOptions) withDefaults() PasswordOptions {
+	if o.Policy.MinLength <= 0 {
+		o.Policy.MinLength = 8
+	}
+	if o.Policy.MaxLength <= 0 {
+		o.Policy.MaxLength = 64
+	}
+
+	if o.Hash.Algorithm == "" {
+		o.Hash.Algorithm = HashBcrypt
+	}
+	if o.Hash.BcryptCost <= 0 {
+		o.Hash.BcryptCost = 12
+	}
+
+	argon := &o.Hash.Argon2id
+	if argon.Memory == 0 {
+		argon.Memory = 64 * 1024
+	}
+	if argon.Iterations == 0 {
+		argon.Iterations = 3
+	}
+	if argon.Parallelism == 0 {
+		argon.Parallelism = 2
+	}
+	if argon.SaltLength == 0 {
+		argon.SaltLength = 16
+	}
+	if argon.KeyLength == 0 {
+		argon.KeyLength = 32
+	}
+
+	return o
+}
+
+// validatePassword checks password against the policy and the breached
+// password list, and reports every rule it breaks in one error.
+func (a *authService) validatePassword(ctx context.Context, password string, email string) error {
+	policy := a.authOptions.Password.Policy
+
+	var violations []string
+
+	length := utf8.RuneCountInString(password)
+	if length < policy.MinLength {
+		violations = append(violations, fmt.Sprintf("must be at least %d characters", policy.MinLength))
+	}
+	if length > policy.MaxLength || (a.authOptions.Password.Hash.Algorithm == HashBcrypt && len(password) > bcryptMaxBytes) {
+		violations = append(violations, fmt.Sprintf("must be at most %d characters", policy.MaxLength))
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		}
+	}
+
+	if policy.RequireUpper && !upper {
+		violations = append(violations, "must contain an uppercase letter")
+	}
+	if policy.RequireLower && !lower {
+		violations = append(violations, "must contain a lowercase letter")
+	}
+	if policy.RequireDigit && !digit {
+		violations = append(violations, "must contain a digit")
+	}
+	if policy.RequireSymbol && !symbol {
+		violations = append(violations, "must contain a symbol")
+	}
+
+	if !policy.AllowEmail && containsEmail(password, email) {
+		violations = append(violations, "must not contain your email address")
+	}
+
+	// Only look the password up once it passes the cheaper rules
+	if len(violations) == 0 && a.breachList != nil {
+		breached, err := a.breachList.IsBreached(password)
+		if err != nil {
+			// Failing open keeps registration up if the list cannot be read
+			zerolog.Ctx(ctx).Error().Err(err).Msg("check_breached_password")
+		} else if breached {
+			violations = append(violations, "has appeared in a data breach, choose another one")
+		}
+	}
+
+	if len(violations) > 0 {
+		return x.NewWithCode(x.CodeHTTPUnprocessableEntity, "Password %s", strings.Join(violations, "; "))
+	}
+
+	return nil
+}
+
+// containsEmail reports whether password contains the email address or its
+// local part. Very short local parts are ignored, they would reject too much.
+func containsEmail(password string, email string) bool {
+	password = strings.ToLower(password)
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return false
+	}
+
+	local, _, _ := strings.Cut(email, "@")
+
+	return strings.Contains(password, email) || (len(local) >= 3 && strings.Contains(password, local))
+}
+
+// hashPassword hashes password with the configured algorithm.
+func (a *authService) hashPassword(password string) (string, error) {
+	opts := a.authOptions.Password.Hash
+
+	switch opts.Algorithm {
+	case HashArgon2id:
+		salt := make([]byte, opts.Argon2id.SaltLength)
+		if _, err := rand.Read(salt); err != nil {
+			return "", err
+		}
+
+		return encodeArgon2id(opts.Argon2id, salt, password), nil
+	case HashBcrypt:
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), opts.BcryptCost)
+		if err != nil {
+			return "", err
+		}
+
+		return string(hash), nil
+	}
+
+	return "", fmt.Errorf("unknown password hash algorithm %q", opts.Algorithm)
+}
+
+// verifyPassword checks password against hash, whatever algorithm made it.
+// On a match it also reports whether the hash should be replaced because it
+// was made with another algorithm or other parameters than configured now.
+func (a *authService) verifyPassword(hash string, password string) (bool, error) {
+	opts := a.authOptions.Password.Hash
+
+	if strings.HasPrefix(hash, "$argon2id$") {
+		params, salt, key, err := decodeArgon2id(hash)
+		if err != nil {
+			return false, err
+		}
+
+		computed := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+		if subtle.ConstantTimeCompare(computed, key) != 1 {
+			return false, errPasswordMismatch
+		}
+
+		want := opts.Argon2id
+		stale := opts.Algorithm != HashArgon2id ||
+			params.Memory != want.Memory || params.Iterations != want.Iterations ||
+			params.Parallelism != want.Parallelism || uint32(len(key)) != want.KeyLength
+
+		return stale, nil
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+		return false, err
+	}
+
+	cost, err := bcrypt.Cost([]byte(hash))
+	if err != nil {
+		return false, err
+	}
+
+	return opts.Algorithm != HashBcrypt || cost != opts.BcryptCost, nil
+}
+
+// rehashPassword replaces the stored hash of a user whose password just
+// verified against a stale one. Failures are only logged; the old hash keeps
+// working and the next login tries again.
+func (a *authService) rehashPassword(ctx context.Context, userID string, oldHash string, password string) {
+	log := zerolog.Ctx(ctx).With().Str("authID", userID).Logger()
+
+	newHash, err := a.hashPassword(password)
+	if err != nil {
+		log.Error().Err(err).Msg("rehash_password")
+		return
+	}
+
+	if err := a.authRepository.RehashPassword(ctx, userID, oldHash, newHash); err != nil {
+		log.Error().Err(err).Msg("rehash_password")
+		return
+	}
+
+	log.Info().Str("algorithm", a.authOptions.Password.Hash.Algorithm).Msg("password_rehashed")
+}
+
+// encodeArgon2id formats an argon2id hash in the PHC string format used by
+// the reference implementation, so the parameters travel with the hash.
+func encodeArgon2id(opts Argon2Options, salt []byte, password string) string {
+	key := argon2.IDKey([]byte(password), salt, opts.Iterations, opts.Memory, opts.Parallelism, opts.KeyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, opts.Memory, opts.Iterations, opts.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	)
+}
+
+func decodeArgon2id(hash string) (Argon2Options, []byte, []byte, error) {
+	var params Argon2Options
+
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return params, nil, nil, errors.New("malformed argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, errors.New("unsupported argon2id version")
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, fmt.Errorf("malformed argon2id parameters: %w", err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("malformed argon2id salt: %w", err)
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("malformed argon2id key: %w", err)
+	}
+
+	return params, salt, key, nil
+}
@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/model/dto"
+	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/model/entity"
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const testResetToken = "reset-token"
+
+type fakeBreachList map[string]bool
+
+func (f fakeBreachList) IsBreached(password string) (bool, error) {
+	return f[password], nil
+}
+
+// newTestPasswordService sets up a repository holding one user whose password
+// hash follows ResetPassword and RehashPassword.
+func newTestPasswordService(t *testing.T, opts PasswordOptions, breached fakeBreachList) (*authService, *MockAuthRepository, *entity.UserAuth) {
+	t.Helper()
+
+	user := newTestUser()
+	repo := &MockAuthRepository{}
+	repo.On("FindAuthUserByEmail", mock.Anything, testEmail).Return(user, nil).Maybe()
+	repo.On("FindAuthUserByID", mock.Anything, testUserID).Return(user, nil).Maybe()
+	repo.On("FindAuthTokenUser", mock.Anything, testResetToken, entity.TokenPasswordReset).Return(testUserID, nil).Maybe()
+	repo.On("ResetPassword", mock.Anything, testResetToken, mock.Anything).
+		Run(func(args mock.Arguments) { user.PasswordHash = args.String(2) }).
+		Return(testUserID, nil).Maybe()
+	repo.On("RehashPassword", mock.Anything, testUserID, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			if user.PasswordHash == args.String(2) {
+				user.PasswordHash = args.String(3)
+			}
+		}).
+		Return(nil).Maybe()
+	repo.On("GetLoginFailures", mock.Anything, testEmail, mock.Anything).Return(&entity.LoginFailures{}, nil).Maybe()
+	repo.On("ClearLoginFailures", mock.Anything, testEmail).Return(nil).Maybe()
+	repo.On("FindUserRoles", mock.Anything, testUserID).Return(&entity.UserRoles{Roles: []string{entity.RoleCustomer}, Permissions: []string{}}, nil).Maybe()
+	repo.On("StoreSession", mock.Anything, mock.Anything, testEmail, mock.Anything, mock.Anything).Return("session-1", nil).Maybe()
+
+	svc := InitAuthService(repo, nil, discardProducer{}, Options{Password: opts}, newTestKeySet(t), nil, breached).(*authService)
+
+	return svc, repo, user
+}
+
+// cheapArgon2id keeps the tests fast; the defaults take a noticeable time.
+var cheapArgon2id = Argon2Options{Memory: 1024, Iterations: 1, Parallelism: 1}
+
+func TestCreateAuthUserReportsEveryPolicyViolation(t *testing.T) {
+	svc, repo, _ := newTestPasswordService(t, PasswordOptions{
+		Policy: PasswordPolicy{MinLength: 12, RequireUpper: true, RequireDigit: true, RequireSymbol: true},
+	}, nil)
+
+	_, err := svc.CreateAuthUser(context.Background(), &dto.CreateAuthUserRequest{Email: "janedoe@example.com", Password: "janedoe"})
+	require.Error(t, err)
+	assert.Equal(t, x.CodeHTTPUnprocessableEntity, x.ErrCode(err))
+
+	for _, rule := range []string{"at least 12", "uppercase", "digit", "symbol", "email"} {
+		assert.Contains(t, err.Error(), rule)
+	}
+	repo.AssertNotCalled(t, "CreateAuthUser", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCreateAuthUserRejectsBreachedPassword(t *testing.T) {
+	svc, repo, _ := newTestPasswordService(t, PasswordOptions{}, fakeBreachList{"password123": true})
+
+	var hash string
+	repo.On("CreateAuthUser", mock.Anything, "new@example.com", mock.Anything).
+		Run(func(args mock.Arguments) { hash = args.String(2) }).
+		Return("user-new", nil).Once()
+	repo.On("StoreAuthToken", mock.Anything, "user-new", entity.TokenEmailVerification, mock.Anything, mock.Anything).Return(nil)
+
+	_, err := svc.CreateAuthUser(context.Background(), &dto.CreateAuthUserRequest{Email: "new@example.com", Password: "password123"})
+	require.Error(t, err)
+	assert.Equal(t, x.CodeHTTPUnprocessableEntity, x.ErrCode(err))
+	assert.Contains(t, err.Error(), "breach")
+
+	resp, err := svc.CreateAuthUser(context.Background(), &dto.CreateAuthUserRequest{Email: "new@example.com", Password: "correct horse battery"})
+	require.NoError(t, err)
+	assert.Equal(t, "user-new", resp.AuthId)
+
+	require.NoError(t, bcrypt.CompareHashAndPassword([]byte(hash), []byte("correct horse battery")))
+	repo.AssertExpectations(t)
+}
+
+func TestResetPasswordChecksPolicyBeforeUsingToken(t *testing.T) {
+	svc, repo, _ := newTestPasswordService(t, PasswordOptions{}, nil)
+	ctx := context.Background()
+
+	_, err := svc.ResetPassword(ctx, &dto.ResetPasswordRequest{Token: testResetToken, NewPassword: "Jane.Doe!2024"})
+	require.Error(t, err)
+	assert.Equal(t, x.CodeHTTPUnprocessableEntity, x.ErrCode(err))
+	repo.AssertNotCalled(t, "ResetPassword", mock.Anything, mock.Anything, mock.Anything)
+
+	_, err = svc.ResetPassword(ctx, &dto.ResetPasswordRequest{Token: testResetToken, NewPassword: "a better passphrase"})
+	require.NoError(t, err)
+	repo.AssertNumberOfCalls(t, "ResetPassword", 1)
+}
+
+func TestLoginRehashesBcryptToArgon2id(t *testing.T) {
+	const password = "correct horse battery"
+
+	svc, _, user := newTestPasswordService(t, PasswordOptions{
+		Hash: PasswordHashOptions{Algorithm: HashArgon2id, Argon2id: cheapArgon2id},
+	}, nil)
+
+	legacy, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(t, err)
+	user.PasswordHash = string(legacy)
+
+	_, err = svc.Login(context.Background(), &dto.LoginRequest{Email: user.Email, Password: password})
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(user.PasswordHash, "$argon2id$"), user.PasswordHash)
+
+	needsRehash, err := svc.verifyPassword(user.PasswordHash, password)
+	require.NoError(t, err)
+	assert.False(t, needsRehash)
+
+	// The new hash keeps working and is left alone
+	rehashed := user.PasswordHash
+	_, err = svc.Login(context.Background(), &dto.LoginRequest{Email: user.Email, Password: password})
+	require.NoError(t, err)
+	assert.Equal(t, rehashed, user.PasswordHash)
+}
+
+func TestVerifyPasswordFlagsOutdatedParameters(t *testing.T) {
+	svc, _, _ := newTestPasswordService(t, PasswordOptions{Hash: PasswordHashOptions{BcryptCost: bcrypt.MinCost + 1}}, nil)
+
+	cheap, err := bcrypt.GenerateFromPassword([]byte("secret-password"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	needsRehash, err := svc.verifyPassword(string(cheap), "secret-password")
+	require.NoError(t, err)
+	assert.True(t, needsRehash)
+
+	_, err = svc.verifyPassword(string(cheap), "wrong-password")
+	assert.Error(t, err)
+
+	argon := encodeArgon2id(Argon2Options{Memory: 1024, Iterations: 1, Parallelism: 1, KeyLength: 32}, []byte("0123456789abcdef"), "secret-password")
+
+	needsRehash, err = svc.verifyPassword(argon, "secret-password")
+	require.NoError(t, err)
+	assert.True(t, needsRehash)
+
+	_, err = svc.verifyPassword(argon, "wrong-password")
+	assert.Error(t, err)
+}
@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/model/entity"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog"
+)
+
+const (
+	accessTokenTTL  = time.Hour
+	refreshTokenTTL = 7 * 24 * time.Hour
+
+	activityRefreshTokenReuse = "refresh_token_reuse"
+)
+
+// signAccessToken signs an access token for the session sessionID. The
+// session travels in the "sid" claim so revoking the session also rejects
+// its access tokens. Roles and permissions are read when the token is
+// signed, so a change reaches the user with their next refresh.
+func (a *authService) signAccessToken(userID string, email string, sessionID string, userRoles *entity.UserRoles) (string, error) {
+	now := time.Now()
+
+	return a.keys.Sign(jwt.MapClaims{
+		"sub":   userID,
+		"email": email,
+		"sid":   sessionID,
+		"roles": userRoles.Roles,
+		"perms": userRoles.Permissions,
+		"iat":   now.Unix(),
+		"exp":   now.Add(accessTokenTTL).Unix(),
+		"jti":   generateTokenID(),
+	})
+}
+
+// claimStrings reads a string array claim. Tokens signed before the claim
+// existed yield an empty slice.
+func claimStrings(claims jwt.MapClaims, name string) []string {
+	values, _ := claims[name].([]any)
+
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if s, ok := v.(string); ok {
+			out = append(out, s)
+		}
+	}
+
+	return out
+}
+
+// revokeReusedSession handles a refresh token presented after it was already
+// exchanged. Only one of the legitimate client and whoever copied the token
+// should have it, and there is no telling which one this is, so the whole
+// session is revoked and both have to log in again.
+func (a *authService) revokeReusedSession(ctx context.Context, token *entity.RefreshToken) {
+	zerolog.Ctx(ctx).Warn().
+		Str("authID", token.UserID).
+		Str("familyID", token.FamilyID).
+		Str("tokenID", token.ID).
+		Msg("refresh_token_reuse")
+
+	if err := a.authRepository.RevokeSession(ctx, token.FamilyID); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("familyID", token.FamilyID).Msg("revoke_session")
+	}
+
+	a.logActivity(ctx, token.UserID, activityRefreshTokenReuse, map[string]string{
+		"family_id": token.FamilyID,
+	})
+}
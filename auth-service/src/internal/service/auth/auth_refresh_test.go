@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/model/dto"
+	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/model/entity"
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testFamilyID     = "family-123"
+	testRefreshToken = "refresh-token-123"
+)
+
+func newTestRefreshToken() *entity.RefreshToken {
+	return &entity.RefreshToken{
+		ID:        "token-1",
+		UserID:    testUserID,
+		FamilyID:  testFamilyID,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+}
+
+func TestRefreshTokenCarriesCurrentRoles(t *testing.T) {
+	ctx := context.Background()
+	repo := &MockAuthRepository{}
+	repo.On("FindRefreshToken", mock.Anything, testRefreshToken).Return(newTestRefreshToken(), nil)
+	repo.On("FindAuthUserByID", mock.Anything, testUserID).Return(newTestUser(), nil)
+	repo.On("FindUserRoles", mock.Anything, testUserID).Return(&entity.UserRoles{Roles: []string{"admin"}, Permissions: []string{"order:status:update"}}, nil)
+	repo.On("RotateRefreshToken", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Once()
+	repo.On("FindTokenID", mock.Anything, mock.Anything).Return(false)
+	repo.On("FindRevokedSession", mock.Anything, testFamilyID).Return(false)
+	svc := &authService{authRepository: repo, keys: newTestKeySet(t)}
+
+	resp, err := svc.RefreshToken(ctx, &dto.RefreshTokenRequest{RefreshToken: testRefreshToken})
+	require.NoError(t, err)
+
+	validated, err := svc.ValidateToken(ctx, &dto.ValidateTokenRequest{Token: resp.AccessToken})
+	require.NoError(t, err)
+	assert.Equal(t, testFamilyID, validated.SessionId)
+	assert.Equal(t, []string{"admin"}, validated.Roles)
+	assert.Equal(t, []string{"order:status:update"}, validated.Permissions)
+	repo.AssertExpectations(t)
+}
+
+func TestRefreshTokenReuseRevokesSession(t *testing.T) {
+	ctx := context.Background()
+	usedAt := time.Now().Add(-time.Minute)
+	token := newTestRefreshToken()
+	token.UsedAt = &usedAt
+
+	repo := &MockAuthRepository{}
+	repo.On("FindRefreshToken", mock.Anything, testRefreshToken).Return(token, nil)
+	repo.On("RevokeSession", mock.Anything, testFamilyID).Return(nil).Once()
+	svc := &authService{authRepository: repo}
+
+	_, err := svc.RefreshToken(ctx, &dto.RefreshTokenRequest{RefreshToken: testRefreshToken})
+	require.Error(t, err)
+	assert.Equal(t, x.CodeHTTPUnauthorized, x.ErrCode(err))
+	repo.AssertExpectations(t)
+	repo.AssertNotCalled(t, "RotateRefreshToken", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRefreshTokenLostRotationRevokesSession(t *testing.T) {
+	ctx := context.Background()
+	repo := &MockAuthRepository{}
+	repo.On("FindRefreshToken", mock.Anything, testRefreshToken).Return(newTestRefreshToken(), nil)
+	repo.On("FindAuthUserByID", mock.Anything, testUserID).Return(newTestUser(), nil)
+	repo.On("FindUserRoles", mock.Anything, testUserID).Return(&entity.UserRoles{}, nil)
+	repo.On("RotateRefreshToken", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(false, nil).Once()
+	repo.On("RevokeSession", mock.Anything, testFamilyID).Return(nil).Once()
+	svc := &authService{authRepository: repo}
+
+	_, err := svc.RefreshToken(ctx, &dto.RefreshTokenRequest{RefreshToken: testRefreshToken})
+	require.Error(t, err)
+	assert.Equal(t, x.CodeHTTPUnauthorized, x.ErrCode(err))
+	repo.AssertExpectations(t)
+}
+
+func TestRefreshTokenRevokedOrUnknown(t *testing.T) {
+	ctx := context.Background()
+	revokedAt := time.Now()
+	token := newTestRefreshToken()
+	token.RevokedAt = &revokedAt
+
+	repo := &MockAuthRepository{}
+	repo.On("FindRefreshToken", mock.Anything, testRefreshToken).Return(token, nil)
+	repo.On("FindRefreshToken", mock.Anything, "never-issued").Return(nil, errNotFound("get_refresh_token_sql"))
+	svc := &authService{authRepository: repo}
+
+	// A revoked session stays revoked without raising another alarm
+	_, err := svc.RefreshToken(ctx, &dto.RefreshTokenRequest{RefreshToken: testRefreshToken})
+	assert.Equal(t, x.CodeHTTPUnauthorized, x.ErrCode(err))
+
+	_, err = svc.RefreshToken(ctx, &dto.RefreshTokenRequest{RefreshToken: "never-issued"})
+	assert.Equal(t, x.CodeHTTPUnauthorized, x.ErrCode(err))
+
+	repo.AssertNotCalled(t, "RevokeSession", mock.Anything, mock.Anything)
+}
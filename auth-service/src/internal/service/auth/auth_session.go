@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/model/dto"
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+)
+
+const (
+	activitySessionRevoked = "session_revoked"
+	activityLogoutAll      = "logout_all"
+)
+
+func (a *authService) ListSessions(ctx context.Context, req *dto.ListSessionsRequest) (*dto.ListSessionsResponse, error) {
+	sessions, err := a.authRepository.ListSessions(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.ListSessionsResponse{Sessions: make([]dto.SessionResponse, len(sessions))}
+	for i, session := range sessions {
+		resp.Sessions[i] = dto.SessionResponse{
+			SessionId:  session.ID,
+			IpAddress:  session.IPAddress,
+			UserAgent:  session.UserAgent,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			Current:    session.ID == req.CurrentSessionId,
+		}
+	}
+
+	return resp, nil
+}
+
+// RevokeSession signs one of the user's devices out. Sessions of other users
+// are reported as not found, the same as ids that do not exist.
+func (a *authService) RevokeSession(ctx context.Context, req *dto.RevokeSessionRequest) (*dto.RevokeSessionResponse, error) {
+	session, err := a.authRepository.GetSession(ctx, req.SessionId)
+	if err != nil && x.ErrCode(err) != x.CodeSQLRecordDoesNotExist {
+		return nil, err
+	}
+
+	if session == nil || session.UserID != req.UserId || session.RevokedAt != nil {
+		return nil, x.NewWithCode(x.CodeHTTPNotFound, "Session not found")
+	}
+
+	if err := a.authRepository.RevokeSession(ctx, session.ID); err != nil {
+		return nil, err
+	}
+
+	a.logActivity(ctx, req.UserId, activitySessionRevoked, map[string]string{
+		"session_id": session.ID,
+	})
+
+	return &dto.RevokeSessionResponse{Success: true}, nil
+}
+
+// LogoutAll signs the user out on every device, the current one included.
+func (a *authService) LogoutAll(ctx context.Context, req *dto.LogoutAllRequest) (*dto.LogoutAllResponse, error) {
+	revoked, err := a.authRepository.RevokeAllSessions(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	a.logActivity(ctx, req.UserId, activityLogoutAll, nil)
+
+	return &dto.LogoutAllResponse{
+		Success: true,
+		Revoked: int64(revoked),
+	}, nil
+}
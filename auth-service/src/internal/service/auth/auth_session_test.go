@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/model/dto"
+	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/model/entity"
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevokeSessionOnlyOwnSessions(t *testing.T) {
+	ctx := context.Background()
+	repo := &MockAuthRepository{}
+	repo.On("GetSession", mock.Anything, "mine").Return(&entity.Session{ID: "mine", UserID: testUserID}, nil)
+	repo.On("GetSession", mock.Anything, "theirs").Return(&entity.Session{ID: "theirs", UserID: "someone-else"}, nil)
+	repo.On("GetSession", mock.Anything, "missing").Return(nil, errNotFound("get_session_sql"))
+	repo.On("RevokeSession", mock.Anything, "mine").Return(nil).Once()
+	svc := &authService{authRepository: repo}
+
+	_, err := svc.RevokeSession(ctx, &dto.RevokeSessionRequest{UserId: testUserID, SessionId: "theirs"})
+	assert.Equal(t, x.CodeHTTPNotFound, x.ErrCode(err))
+
+	_, err = svc.RevokeSession(ctx, &dto.RevokeSessionRequest{UserId: testUserID, SessionId: "missing"})
+	assert.Equal(t, x.CodeHTTPNotFound, x.ErrCode(err))
+
+	resp, err := svc.RevokeSession(ctx, &dto.RevokeSessionRequest{UserId: testUserID, SessionId: "mine"})
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+
+	repo.AssertExpectations(t)
+}
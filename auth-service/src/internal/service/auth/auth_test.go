@@ -0,0 +1,354 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/model/entity"
+	authRepo "github.com/linggaaskaedo/go-kill/auth-service/src/internal/repository/auth"
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+	"github.com/linggaaskaedo/go-kill/common/pkg/token"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testUserID    = "user-1"
+	testEmail     = "jane.doe@example.com"
+	testIPAddress = "192.168.1.1"
+)
+
+var _ authRepo.AuthRepositoryItf = (*MockAuthRepository)(nil)
+
+type MockAuthRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuthRepository) CreateAuthUser(ctx context.Context, email string, passwordHash string) (string, error) {
+	args := m.Called(ctx, email, passwordHash)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockAuthRepository) FindAuthUserByEmail(ctx context.Context, email string) (*entity.UserAuth, error) {
+	args := m.Called(ctx, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.UserAuth), args.Error(1)
+}
+
+func (m *MockAuthRepository) FindAuthUserByID(ctx context.Context, userID string) (*entity.UserAuth, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.UserAuth), args.Error(1)
+}
+
+func (m *MockAuthRepository) FindUserRoles(ctx context.Context, userID string) (*entity.UserRoles, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.UserRoles), args.Error(1)
+}
+
+func (m *MockAuthRepository) FindTokenID(ctx context.Context, tokenID string) bool {
+	args := m.Called(ctx, tokenID)
+	return args.Bool(0)
+}
+
+func (m *MockAuthRepository) BlacklistToken(ctx context.Context, token *jwt.Token) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) StoreSession(ctx context.Context, session *entity.Session, email string, refreshToken string, expired time.Time) (string, error) {
+	args := m.Called(ctx, session, email, refreshToken, expired)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockAuthRepository) FindRefreshToken(ctx context.Context, refreshToken string) (*entity.RefreshToken, error) {
+	args := m.Called(ctx, refreshToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.RefreshToken), args.Error(1)
+}
+
+func (m *MockAuthRepository) RotateRefreshToken(ctx context.Context, current *entity.RefreshToken, newRefreshToken string, expired time.Time, ipAddress string, userAgent string) (bool, error) {
+	args := m.Called(ctx, current, newRefreshToken, expired, ipAddress, userAgent)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAuthRepository) GetSession(ctx context.Context, sessionID string) (*entity.Session, error) {
+	args := m.Called(ctx, sessionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Session), args.Error(1)
+}
+
+func (m *MockAuthRepository) ListSessions(ctx context.Context, userID string) ([]entity.Session, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entity.Session), args.Error(1)
+}
+
+func (m *MockAuthRepository) RevokeSession(ctx context.Context, sessionID string) error {
+	args := m.Called(ctx, sessionID)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) RevokeAllSessions(ctx context.Context, userID string) (int, error) {
+	args := m.Called(ctx, userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockAuthRepository) FindRevokedSession(ctx context.Context, sessionID string) bool {
+	args := m.Called(ctx, sessionID)
+	return args.Bool(0)
+}
+
+func (m *MockAuthRepository) GetLoginFailures(ctx context.Context, email string, ipAddress string) (*entity.LoginFailures, error) {
+	args := m.Called(ctx, email, ipAddress)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.LoginFailures), args.Error(1)
+}
+
+func (m *MockAuthRepository) RecordLoginFailure(ctx context.Context, email string, ipAddress string, window time.Duration) (*entity.LoginFailures, error) {
+	args := m.Called(ctx, email, ipAddress, window)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.LoginFailures), args.Error(1)
+}
+
+func (m *MockAuthRepository) DelayLogin(ctx context.Context, email string, delay time.Duration) error {
+	args := m.Called(ctx, email, delay)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) ClearLoginFailures(ctx context.Context, email string) error {
+	args := m.Called(ctx, email)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) LockAuthUser(ctx context.Context, userID string, until time.Time) error {
+	args := m.Called(ctx, userID, until)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) StoreAuthToken(ctx context.Context, userID string, purpose entity.AuthTokenPurpose, token string, expiresAt time.Time) error {
+	args := m.Called(ctx, userID, purpose, token, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) VerifyEmail(ctx context.Context, token string) (string, error) {
+	args := m.Called(ctx, token)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockAuthRepository) FindAuthTokenUser(ctx context.Context, token string, purpose entity.AuthTokenPurpose) (string, error) {
+	args := m.Called(ctx, token, purpose)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockAuthRepository) ResetPassword(ctx context.Context, token string, passwordHash string) (string, error) {
+	args := m.Called(ctx, token, passwordHash)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockAuthRepository) RehashPassword(ctx context.Context, userID string, oldHash string, newHash string) error {
+	args := m.Called(ctx, userID, oldHash, newHash)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) SaveMfaSecret(ctx context.Context, userID string, secret string) error {
+	args := m.Called(ctx, userID, secret)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) GetUserMfa(ctx context.Context, userID string) (*entity.UserMfa, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.UserMfa), args.Error(1)
+}
+
+func (m *MockAuthRepository) EnableMfa(ctx context.Context, userID string, step int64, recoveryCodes []string) error {
+	args := m.Called(ctx, userID, step, recoveryCodes)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) DisableMfa(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) UseMfaStep(ctx context.Context, userID string, step int64) (bool, error) {
+	args := m.Called(ctx, userID, step)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAuthRepository) UseMfaRecoveryCode(ctx context.Context, userID string, code string) (bool, error) {
+	args := m.Called(ctx, userID, code)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAuthRepository) StoreMfaChallenge(ctx context.Context, token string, challenge *entity.MfaChallenge, ttl time.Duration) error {
+	args := m.Called(ctx, token, challenge, ttl)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) GetMfaChallenge(ctx context.Context, token string) (*entity.MfaChallenge, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.MfaChallenge), args.Error(1)
+}
+
+func (m *MockAuthRepository) RecordMfaFailure(ctx context.Context, token string) (int64, error) {
+	args := m.Called(ctx, token)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockAuthRepository) ConsumeMfaChallenge(ctx context.Context, token string) (bool, error) {
+	args := m.Called(ctx, token)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAuthRepository) FindUserIdentity(ctx context.Context, provider string, subject string) (*entity.UserIdentity, error) {
+	args := m.Called(ctx, provider, subject)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.UserIdentity), args.Error(1)
+}
+
+func (m *MockAuthRepository) TouchUserIdentity(ctx context.Context, identityID string, email string) error {
+	args := m.Called(ctx, identityID, email)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) LinkUserIdentity(ctx context.Context, identity *entity.UserIdentity) error {
+	args := m.Called(ctx, identity)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) CreateOAuthUser(ctx context.Context, identity *entity.UserIdentity) (string, error) {
+	args := m.Called(ctx, identity)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockAuthRepository) StoreOAuthState(ctx context.Context, state string, oauthState *entity.OAuthState, ttl time.Duration) error {
+	args := m.Called(ctx, state, oauthState, ttl)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) ConsumeOAuthState(ctx context.Context, state string) (*entity.OAuthState, error) {
+	args := m.Called(ctx, state)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.OAuthState), args.Error(1)
+}
+
+func (m *MockAuthRepository) CreateApiKey(ctx context.Context, apiKey *entity.ApiKey, key string) error {
+	args := m.Called(ctx, apiKey, key)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) GetApiKey(ctx context.Context, keyID string) (*entity.ApiKey, error) {
+	args := m.Called(ctx, keyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.ApiKey), args.Error(1)
+}
+
+func (m *MockAuthRepository) FindApiKey(ctx context.Context, key string) (*entity.ApiKey, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.ApiKey), args.Error(1)
+}
+
+func (m *MockAuthRepository) ListApiKeys(ctx context.Context, userID string) ([]entity.ApiKey, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entity.ApiKey), args.Error(1)
+}
+
+func (m *MockAuthRepository) RotateApiKey(ctx context.Context, current *entity.ApiKey, next *entity.ApiKey, key string, expireCurrentAt time.Time) error {
+	args := m.Called(ctx, current, next, key, expireCurrentAt)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) RevokeApiKey(ctx context.Context, keyID string) error {
+	args := m.Called(ctx, keyID)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) TouchApiKey(ctx context.Context, keyID string, interval time.Duration) error {
+	args := m.Called(ctx, keyID, interval)
+	return args.Error(0)
+}
+
+// errNotFound is what the repository returns for a missing row.
+func errNotFound(msg string) error {
+	return x.NewWithCode(x.CodeSQLRecordDoesNotExist, msg)
+}
+
+func newTestUser() *entity.UserAuth {
+	return &entity.UserAuth{ID: testUserID, Email: testEmail, IsActive: true}
+}
+
+// discardProducer drops the emails the service sends.
+type discardProducer struct{}
+
+func (discardProducer) SendMessage(ctx context.Context, topic string, key, value []byte) (int32, int64, error) {
+	return 0, 0, nil
+}
+
+func newTestKeySet(t *testing.T) *token.KeySet {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "test.pem")
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	require.NoError(t, os.WriteFile(path, pemBytes, 0o600))
+
+	keys, err := token.NewKeySet(token.Config{SigningKeyID: "test", Keys: []token.Key{{ID: "test", PrivateKeyPath: path}}})
+	require.NoError(t, err)
+
+	return keys
+}
+
+// newTestAuthService builds the service the way InitAuthService does, with
+// the options defaulted, on top of repo.
+func newTestAuthService(t *testing.T, repo *MockAuthRepository, opts Options) *authService {
+	t.Helper()
+
+	return InitAuthService(repo, nil, discardProducer{}, opts, newTestKeySet(t), nil, nil).(*authService)
+}
@@ -1,8 +1,14 @@
 package service
 
 import (
+	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/provider/oauth"
 	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/repository"
 	"github.com/linggaaskaedo/go-kill/auth-service/src/internal/service/auth"
+	"github.com/linggaaskaedo/go-kill/common/component/breachlist"
+	"github.com/linggaaskaedo/go-kill/common/component/kafkaproducer"
+	"github.com/linggaaskaedo/go-kill/common/pkg/token"
+
+	"google.golang.org/grpc"
 )
 
 type Service struct {
@@ -13,11 +19,22 @@ type Options struct {
 	AuthOpts auth.Options `yaml:"auth"`
 }
 
-func InitService(repository *repository.Repository, opts Options) *Service {
+func InitService(repository *repository.Repository, userClientConn *grpc.ClientConn, kafkaProducer *kafkaproducer.KafkaProducerComponent, opts Options, keys *token.KeySet, oauthProviders map[string]oauth.Provider, breachList *breachlist.BreachListComponent) *Service {
+	// A nil component must reach the service as a nil interface
+	var breachChecker auth.BreachedPasswordChecker
+	if breachList != nil {
+		breachChecker = breachList
+	}
+
 	return &Service{
 		Auth: auth.InitAuthService(
 			repository.Auth,
+			userClientConn,
+			kafkaProducer,
 			opts.AuthOpts,
+			keys,
+			oauthProviders,
+			breachChecker,
 		),
 	}
 }
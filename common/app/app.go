@@ -2,7 +2,10 @@ package app
 
 import (
 	"context"
+	"fmt"
 	"os/signal"
+	"reflect"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -13,12 +16,20 @@ import (
 type componentWrapper struct {
 	Component
 	shutdownTimeout time.Duration
+	readyTimeout    time.Duration
+	dependsOn       []Component
+	name            string
+	started         atomic.Bool
+	health          atomic.Value // HealthStatus of the last check
 }
 
 type App struct {
-	components    []componentWrapper
+	components    []*componentWrapper
 	globalTimeout time.Duration
+	readyTimeout  time.Duration
+	healthTimeout time.Duration
 	logger        zerolog.Logger
+	stopping      atomic.Bool
 }
 
 type Option func(*App)
@@ -27,6 +38,12 @@ func WithShutdownTimeout(timeout time.Duration) Option {
 	return func(a *App) { a.globalTimeout = timeout }
 }
 
+// WithReadyTimeout sets how long a component may take to become ready once
+// it is started, unless the component sets its own with ReadyTimeout.
+func WithReadyTimeout(timeout time.Duration) Option {
+	return func(a *App) { a.readyTimeout = timeout }
+}
+
 func WithLogger(logger zerolog.Logger) Option {
 	return func(a *App) { a.logger = logger }
 }
@@ -34,6 +51,8 @@ func WithLogger(logger zerolog.Logger) Option {
 func New(opts ...Option) *App {
 	a := &App{
 		globalTimeout: 30 * time.Second,
+		readyTimeout:  10 * time.Second,
+		healthTimeout: 2 * time.Second,
 		logger:        zerolog.Nop(),
 	}
 
@@ -44,33 +63,71 @@ func New(opts ...Option) *App {
 	return a
 }
 
-// Add registers components with optional per-component shutdown timeout.
-func (a *App) Add(comp Component, opts ...any) {
-	wrapper := componentWrapper{Component: comp}
+// ComponentOption configures a component registered with Add.
+type ComponentOption func(*componentWrapper)
 
-	// Options can be used to set per-component timeout, etc.
-	for _, opt := range opts {
-		if to, ok := opt.(time.Duration); ok {
-			wrapper.shutdownTimeout = to
+// ShutdownTimeout bounds the component's Stop.
+func ShutdownTimeout(timeout time.Duration) ComponentOption {
+	return func(w *componentWrapper) { w.shutdownTimeout = timeout }
+}
+
+// ReadyTimeout bounds how long the component may take to become ready once
+// it is started.
+func ReadyTimeout(timeout time.Duration) ComponentOption {
+	return func(w *componentWrapper) { w.readyTimeout = timeout }
+}
+
+// DependsOn starts the component only once comps are ready, and stops it
+// before them. Nil components are ignored, so optional ones can be listed
+// unconditionally.
+func DependsOn(comps ...Component) ComponentOption {
+	return func(w *componentWrapper) {
+		for _, comp := range comps {
+			if !isNil(comp) {
+				w.dependsOn = append(w.dependsOn, comp)
+			}
 		}
 	}
+}
+
+// Add registers a component. A nil component, e.g. a disabled database, is
+// ignored.
+func (a *App) Add(comp Component, opts ...ComponentOption) {
+	if isNil(comp) {
+		return
+	}
+
+	wrapper := &componentWrapper{Component: comp}
+	for _, opt := range opts {
+		opt(wrapper)
+	}
 
 	a.components = append(a.components, wrapper)
 }
 
+// Run starts every component once its dependencies are ready and blocks
+// until a shutdown signal or the first component error. Components are then
+// stopped in reverse dependency order.
 func (a *App) Run() error {
+	order, err := a.startOrder()
+	if err != nil {
+		return err
+	}
+
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
 	g, ctx := errgroup.WithContext(ctx)
 
-	a.startComponents(g, ctx)
+	a.startComponents(g, ctx, order)
 
 	if err := a.waitForShutdown(ctx, g); err != nil {
 		a.logger.Error().Err(err).Msg("Component error before shutdown")
 	}
 
-	a.stopComponents()
+	// Report down from here on, so traffic drains while components stop
+	a.stopping.Store(true)
+	a.stopComponents(order)
 
 	// Wait for all components to fully exit (they should have due to ctx cancellation)
 	if err := g.Wait(); err != nil && err != context.Canceled {
@@ -82,18 +139,46 @@ func (a *App) Run() error {
 	return nil
 }
 
-// startComponents launches all component goroutines.
-func (a *App) startComponents(g *errgroup.Group, ctx context.Context) {
-	for _, comp := range a.components {
-		comp := comp // capture loop variable
+// startComponents launches every component in its own goroutine as soon as
+// its dependencies are ready, and fails the group when one is not ready in
+// time.
+func (a *App) startComponents(g *errgroup.Group, ctx context.Context, order []*componentWrapper) {
+	for _, comp := range order {
 		g.Go(func() error {
-			a.logger.Info().Type("component", comp.Component).Msg("Starting component")
-			err := comp.Start(ctx)
-			if err != nil {
-				a.logger.Error().Err(err).Type("component", comp.Component).Msg("Component failed")
+			for _, dep := range comp.dependsOn {
+				select {
+				case <-dep.Ready():
+				case <-ctx.Done():
+					return nil
+				}
 			}
 
-			return err
+			a.logger.Info().Type("component", comp.Component).Msg("Starting component")
+			comp.started.Store(true)
+			startedAt := time.Now()
+
+			g.Go(func() error {
+				err := comp.Start(ctx)
+				if err != nil {
+					a.logger.Error().Err(err).Type("component", comp.Component).Msg("Component failed")
+				}
+
+				return err
+			})
+
+			timeout := a.getReadyTimeout(comp)
+			timer := time.NewTimer(timeout)
+			defer timer.Stop()
+
+			select {
+			case <-comp.Ready():
+				a.logger.Info().Type("component", comp.Component).Dur("elapsed", time.Since(startedAt)).Msg("Component ready")
+				return nil
+			case <-ctx.Done():
+				return nil
+			case <-timer.C:
+				return fmt.Errorf("component %T not ready after %s", comp.Component, timeout)
+			}
 		})
 	}
 }
@@ -114,10 +199,15 @@ func (a *App) waitForShutdown(ctx context.Context, g *errgroup.Group) error {
 	}
 }
 
-// stopComponents shuts down all components in reverse order with appropriate timeouts.
-func (a *App) stopComponents() {
-	for i := len(a.components) - 1; i >= 0; i-- {
-		comp := a.components[i]
+// stopComponents shuts down the started components in reverse start order,
+// so each one stops before the components it depends on.
+func (a *App) stopComponents(order []*componentWrapper) {
+	for i := len(order) - 1; i >= 0; i-- {
+		comp := order[i]
+		if !comp.started.Load() {
+			continue
+		}
+
 		timeout := a.getComponentTimeout(comp)
 		stopCtx, cancel := context.WithTimeout(context.Background(), timeout)
 		a.logger.Info().Type("component", comp.Component).Dur("timeout", timeout).Msg("Stopping component")
@@ -130,7 +220,7 @@ func (a *App) stopComponents() {
 }
 
 // getComponentTimeout determines the shutdown timeout for a component.
-func (a *App) getComponentTimeout(comp componentWrapper) time.Duration {
+func (a *App) getComponentTimeout(comp *componentWrapper) time.Duration {
 	if comp.shutdownTimeout != 0 {
 		return comp.shutdownTimeout
 	}
@@ -141,3 +231,23 @@ func (a *App) getComponentTimeout(comp componentWrapper) time.Duration {
 
 	return a.globalTimeout
 }
+
+func (a *App) getReadyTimeout(comp *componentWrapper) time.Duration {
+	if comp.readyTimeout != 0 {
+		return comp.readyTimeout
+	}
+
+	return a.readyTimeout
+}
+
+// isNil reports whether comp is nil, including a nil pointer stored in the
+// interface, which is what constructors of disabled components return.
+func isNil(comp Component) bool {
+	if comp == nil {
+		return true
+	}
+
+	v := reflect.ValueOf(comp)
+
+	return v.Kind() == reflect.Pointer && v.IsNil()
+}
@@ -0,0 +1,310 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder keeps the order components were started and stopped in.
+type recorder struct {
+	mu      sync.Mutex
+	started []string
+	stopped []string
+}
+
+func (r *recorder) add(list *[]string, name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	*list = append(*list, name)
+}
+
+// fakeComponent becomes ready as soon as it starts, unless neverReady, and
+// runs until its context ends or, with err set, fails right away.
+type fakeComponent struct {
+	name       string
+	rec        *recorder
+	neverReady bool
+	err        error
+	health     error
+
+	ready chan struct{}
+}
+
+func newFake(name string, rec *recorder) *fakeComponent {
+	return &fakeComponent{name: name, rec: rec, ready: make(chan struct{})}
+}
+
+func (f *fakeComponent) Start(ctx context.Context) error {
+	f.rec.add(&f.rec.started, f.name)
+
+	if f.neverReady {
+		<-ctx.Done()
+		return nil
+	}
+
+	close(f.ready)
+
+	if f.err != nil {
+		return f.err
+	}
+
+	<-ctx.Done()
+	return nil
+}
+
+func (f *fakeComponent) Stop(ctx context.Context) error {
+	f.rec.add(&f.rec.stopped, f.name)
+	return nil
+}
+
+func (f *fakeComponent) Ready() <-chan struct{} {
+	return f.ready
+}
+
+func (f *fakeComponent) CheckHealth(ctx context.Context) error {
+	return f.health
+}
+
+func names(order []*componentWrapper) []string {
+	out := make([]string, 0, len(order))
+	for _, w := range order {
+		out = append(out, w.Component.(*fakeComponent).name)
+	}
+	return out
+}
+
+func TestStartOrder(t *testing.T) {
+	rec := &recorder{}
+	db, cache, svc, api, worker := newFake("db", rec), newFake("cache", rec), newFake("svc", rec), newFake("api", rec), newFake("worker", rec)
+
+	tests := []struct {
+		name  string
+		build func(a *App)
+		want  []string
+	}{
+		{
+			name: "no dependencies keeps the order added",
+			build: func(a *App) {
+				a.Add(db)
+				a.Add(cache)
+				a.Add(api)
+			},
+			want: []string{"db", "cache", "api"},
+		},
+		{
+			name: "dependencies come first",
+			build: func(a *App) {
+				a.Add(api, DependsOn(svc))
+				a.Add(svc, DependsOn(db, cache))
+				a.Add(db)
+				a.Add(cache)
+			},
+			want: []string{"db", "cache", "svc", "api"},
+		},
+		{
+			name: "shared dependency starts once",
+			build: func(a *App) {
+				a.Add(api, DependsOn(svc))
+				a.Add(worker, DependsOn(svc))
+				a.Add(svc, DependsOn(db))
+				a.Add(db)
+			},
+			want: []string{"db", "svc", "api", "worker"},
+		},
+		{
+			name: "nil dependencies and components are ignored",
+			build: func(a *App) {
+				var disabled *fakeComponent
+				a.Add(disabled)
+				a.Add(api, DependsOn(disabled, db))
+				a.Add(db)
+			},
+			want: []string{"db", "api"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := New()
+			tt.build(a)
+
+			order, err := a.startOrder()
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, names(order))
+		})
+	}
+}
+
+func TestStartOrderErrors(t *testing.T) {
+	rec := &recorder{}
+	a, b, c := newFake("a", rec), newFake("b", rec), newFake("c", rec)
+
+	tests := []struct {
+		name  string
+		build func(app *App)
+		want  string
+	}{
+		{
+			name: "unknown dependency",
+			build: func(app *App) {
+				app.Add(a, DependsOn(b))
+			},
+			want: "depends on *app.fakeComponent, which was not added",
+		},
+		{
+			name: "self cycle",
+			build: func(app *App) {
+				app.Add(a, DependsOn(a))
+			},
+			want: "dependency cycle: *app.fakeComponent -> *app.fakeComponent",
+		},
+		{
+			name: "longer cycle",
+			build: func(app *App) {
+				app.Add(c)
+				app.Add(a, DependsOn(b))
+				app.Add(b, DependsOn(c, a))
+			},
+			want: "dependency cycle: *app.fakeComponent -> *app.fakeComponent -> *app.fakeComponent",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := New()
+			tt.build(app)
+
+			_, err := app.startOrder()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestRunStartsAndStopsInDependencyOrder(t *testing.T) {
+	rec := &recorder{}
+	db, svc, api := newFake("db", rec), newFake("svc", rec), newFake("api", rec)
+
+	// api failing once it is ready ends the run
+	errBoom := errors.New("boom")
+	api.err = errBoom
+
+	a := New(WithReadyTimeout(time.Second))
+	a.Add(api, DependsOn(svc))
+	a.Add(svc, DependsOn(db))
+	a.Add(db)
+
+	err := a.Run()
+	require.ErrorIs(t, err, errBoom)
+
+	assert.Equal(t, []string{"db", "svc", "api"}, rec.started)
+	assert.Equal(t, []string{"api", "svc", "db"}, rec.stopped)
+}
+
+func TestRunReadyTimeout(t *testing.T) {
+	rec := &recorder{}
+	db, api := newFake("db", rec), newFake("api", rec)
+	db.neverReady = true
+
+	a := New(WithReadyTimeout(time.Hour))
+	a.Add(db, ReadyTimeout(20*time.Millisecond))
+	a.Add(api, DependsOn(db))
+
+	err := a.Run()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not ready after 20ms")
+
+	// api never started, so it is not stopped either
+	assert.Equal(t, []string{"db"}, rec.started)
+	assert.Equal(t, []string{"db"}, rec.stopped)
+}
+
+func TestRunRejectsBadGraph(t *testing.T) {
+	rec := &recorder{}
+	a := New()
+	a.Add(newFake("api", rec), DependsOn(newFake("db", rec)))
+
+	require.Error(t, a.Run())
+	assert.Empty(t, rec.started)
+}
+
+func TestDeferred(t *testing.T) {
+	rec := &recorder{}
+	inner := newFake("consumer", rec)
+	inner.health = errors.New("broker down")
+
+	built := 0
+	d := NewDeferred(func() (Component, error) {
+		built++
+		return inner, nil
+	})
+
+	// Nothing is built before Start
+	assert.Equal(t, 0, built)
+	require.NoError(t, d.Stop(context.Background()))
+	require.NoError(t, d.CheckHealth(context.Background()))
+	assert.Empty(t, rec.stopped)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- d.Start(ctx) }()
+
+	select {
+	case <-d.Ready():
+	case <-time.After(time.Second):
+		t.Fatal("deferred component not ready")
+	}
+
+	assert.Equal(t, 1, built)
+	assert.EqualError(t, d.CheckHealth(context.Background()), "broker down")
+
+	cancel()
+	require.NoError(t, <-done)
+	require.NoError(t, d.Stop(context.Background()))
+	assert.Equal(t, []string{"consumer"}, rec.stopped)
+}
+
+func TestDeferredBuildError(t *testing.T) {
+	errBuild := errors.New("no service")
+	d := NewDeferred(func() (Component, error) {
+		return nil, errBuild
+	})
+
+	require.ErrorIs(t, d.Start(context.Background()), errBuild)
+
+	select {
+	case <-d.Ready():
+		t.Fatal("failed deferred component reported ready")
+	default:
+	}
+}
+
+func TestDeferredWaitsForDependencies(t *testing.T) {
+	rec := &recorder{}
+	svc := newFake("svc", rec)
+
+	var builtAfter []string
+	consumer := NewDeferred(func() (Component, error) {
+		rec.mu.Lock()
+		builtAfter = append([]string(nil), rec.started...)
+		rec.mu.Unlock()
+
+		failing := newFake("consumer", rec)
+		failing.err = errors.New("stop")
+		return failing, nil
+	})
+
+	a := New(WithReadyTimeout(time.Second))
+	a.Add(consumer, DependsOn(svc))
+	a.Add(svc)
+
+	require.Error(t, a.Run())
+	assert.Equal(t, []string{"svc"}, builtAfter)
+}
@@ -0,0 +1,76 @@
+package app
+
+import (
+	"context"
+	"sync"
+)
+
+// Deferred is a component built only when it is started, for components
+// whose constructor needs something a dependency creates on Start, such as a
+// Kafka consumer handler that needs the initialized service. Give it the
+// dependencies with DependsOn; build runs once they are ready.
+type Deferred struct {
+	build func() (Component, error)
+	ready chan struct{}
+
+	mu   sync.Mutex
+	comp Component
+}
+
+func NewDeferred(build func() (Component, error)) *Deferred {
+	return &Deferred{
+		build: build,
+		ready: make(chan struct{}),
+	}
+}
+
+func (d *Deferred) Start(ctx context.Context) error {
+	comp, err := d.build()
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	d.comp = comp
+	d.mu.Unlock()
+
+	go func() {
+		select {
+		case <-comp.Ready():
+			close(d.ready)
+		case <-ctx.Done():
+		}
+	}()
+
+	return comp.Start(ctx)
+}
+
+func (d *Deferred) Stop(ctx context.Context) error {
+	comp := d.component()
+	if comp == nil {
+		return nil
+	}
+
+	return comp.Stop(ctx)
+}
+
+func (d *Deferred) Ready() <-chan struct{} {
+	return d.ready
+}
+
+// CheckHealth checks the built component, if it is a HealthChecker.
+func (d *Deferred) CheckHealth(ctx context.Context) error {
+	checker, ok := d.component().(HealthChecker)
+	if !ok {
+		return nil
+	}
+
+	return checker.CheckHealth(ctx)
+}
+
+func (d *Deferred) component() Component {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.comp
+}
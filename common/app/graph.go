@@ -0,0 +1,86 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+)
+
+// startOrder sorts the components so each one comes after its dependencies,
+// keeping the order they were added in where the dependencies allow it. It
+// fails on a dependency that was never added and on dependency cycles.
+func (a *App) startOrder() ([]*componentWrapper, error) {
+	byComp := make(map[Component]*componentWrapper, len(a.components))
+	for _, w := range a.components {
+		byComp[w.Component] = w
+	}
+
+	for _, w := range a.components {
+		for _, dep := range w.dependsOn {
+			if _, ok := byComp[dep]; !ok {
+				return nil, fmt.Errorf("component %T depends on %T, which was not added", w.Component, dep)
+			}
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+
+	state := make(map[*componentWrapper]int, len(a.components))
+	order := make([]*componentWrapper, 0, len(a.components))
+
+	var path []*componentWrapper
+	var visit func(w *componentWrapper) error
+	visit = func(w *componentWrapper) error {
+		switch state[w] {
+		case done:
+			return nil
+		case visiting:
+			return fmt.Errorf("dependency cycle: %s", cycle(path, w))
+		}
+
+		state[w] = visiting
+		path = append(path, w)
+
+		for _, dep := range w.dependsOn {
+			if err := visit(byComp[dep]); err != nil {
+				return err
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[w] = done
+		order = append(order, w)
+
+		return nil
+	}
+
+	for _, w := range a.components {
+		if err := visit(w); err != nil {
+			return nil, err
+		}
+	}
+
+	return order, nil
+}
+
+// cycle describes the cycle that closes at w, e.g. "*a.X -> *b.Y -> *a.X".
+func cycle(path []*componentWrapper, w *componentWrapper) string {
+	start := 0
+	for i, p := range path {
+		if p == w {
+			start = i
+			break
+		}
+	}
+
+	names := make([]string, 0, len(path)-start+1)
+	for _, p := range path[start:] {
+		names = append(names, fmt.Sprintf("%T", p.Component))
+	}
+	names = append(names, fmt.Sprintf("%T", w.Component))
+
+	return strings.Join(names, " -> ")
+}
@@ -0,0 +1,261 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// HealthChecker is implemented by components that can check the resource
+// they own, such as a database ping. A check that fails with an error
+// wrapped by Degraded reports the component degraded rather than down.
+type HealthChecker interface {
+	CheckHealth(ctx context.Context) error
+}
+
+type HealthStatus string
+
+const (
+	// HealthUp means the component works normally.
+	HealthUp HealthStatus = "up"
+	// HealthDegraded means the component works, but not fully, e.g. only
+	// some brokers or replicas are reachable. The app keeps serving.
+	HealthDegraded HealthStatus = "degraded"
+	// HealthDown means the component does not work, so the app should not
+	// get traffic.
+	HealthDown HealthStatus = "down"
+)
+
+type degradedError struct {
+	err error
+}
+
+func (e *degradedError) Error() string { return e.err.Error() }
+func (e *degradedError) Unwrap() error { return e.err }
+
+// Degraded marks err as leaving the component usable.
+func Degraded(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &degradedError{err: err}
+}
+
+// CheckResult is the health of one component.
+type CheckResult struct {
+	Name   string       `json:"name"`
+	Status HealthStatus `json:"status"`
+	// LatencyMs is how long the check took, 0 for components without one
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// HealthReport is the health of the app: down if any component is down,
+// degraded if any is degraded and up otherwise.
+type HealthReport struct {
+	Status HealthStatus  `json:"status"`
+	Checks []CheckResult `json:"checks"`
+}
+
+// Name names the component in health reports. It defaults to the snake
+// cased type name without the "Component" suffix, e.g. "grpc_client", so
+// set it where an app has several components of one type.
+func Name(name string) ComponentOption {
+	return func(w *componentWrapper) { w.name = name }
+}
+
+// WithHealthTimeout bounds each component's health check.
+func WithHealthTimeout(timeout time.Duration) Option {
+	return func(a *App) { a.healthTimeout = timeout }
+}
+
+// Health checks every component concurrently. Components that are not
+// started or not ready yet are down; ready ones without a HealthChecker
+// are up.
+func (a *App) Health(ctx context.Context) HealthReport {
+	names := a.componentNames()
+	results := make([]CheckResult, len(a.components))
+
+	var wg sync.WaitGroup
+	for i, comp := range a.components {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = a.check(ctx, comp)
+			results[i].Name = names[i]
+			a.logHealthChange(comp, results[i])
+		}()
+	}
+	wg.Wait()
+
+	report := HealthReport{Status: HealthUp, Checks: results}
+	for _, result := range results {
+		if result.Status == HealthDown {
+			report.Status = HealthDown
+			break
+		}
+		if result.Status == HealthDegraded {
+			report.Status = HealthDegraded
+		}
+	}
+
+	if a.stopping.Load() {
+		report.Status = HealthDown
+	}
+
+	return report
+}
+
+// Serving reports whether the app should get traffic, i.e. is not down.
+func (a *App) Serving(ctx context.Context) bool {
+	return a.Health(ctx).Status != HealthDown
+}
+
+// LivenessHandler answers 200 as long as the process serves HTTP. It checks
+// no dependencies, so an outage of one does not get the app restarted.
+func (a *App) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeHealth(w, http.StatusOK, HealthReport{Status: HealthUp, Checks: []CheckResult{}})
+	})
+}
+
+// ReadinessHandler answers with the health report, as 503 when the app is
+// down and 200 otherwise.
+func (a *App) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := a.Health(r.Context())
+
+		status := http.StatusOK
+		if report.Status == HealthDown {
+			status = http.StatusServiceUnavailable
+		}
+
+		writeHealth(w, status, report)
+	})
+}
+
+func writeHealth(w http.ResponseWriter, status int, report HealthReport) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(report)
+}
+
+func (a *App) check(ctx context.Context, comp *componentWrapper) CheckResult {
+	if !comp.started.Load() || !isReady(comp) {
+		return CheckResult{Status: HealthDown, Error: "not ready"}
+	}
+
+	checker, ok := comp.Component.(HealthChecker)
+	if !ok {
+		return CheckResult{Status: HealthUp}
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, a.healthTimeout)
+	defer cancel()
+
+	startedAt := time.Now()
+	err := checker.CheckHealth(checkCtx)
+	result := CheckResult{
+		Status:    HealthUp,
+		LatencyMs: float64(time.Since(startedAt).Microseconds()) / 1000,
+	}
+
+	var degraded *degradedError
+	switch {
+	case err == nil:
+	case errors.As(err, &degraded):
+		result.Status = HealthDegraded
+		result.Error = err.Error()
+	default:
+		result.Status = HealthDown
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+// logHealthChange logs a component's status when it differs from the last
+// check, so a probe polling a failing component does not flood the log.
+func (a *App) logHealthChange(comp *componentWrapper, result CheckResult) {
+	previous, _ := comp.health.Swap(result.Status).(HealthStatus)
+	if previous == result.Status || (previous == "" && result.Status == HealthUp) {
+		return
+	}
+
+	event := a.logger.Warn()
+	if result.Status == HealthUp {
+		event = a.logger.Info()
+	}
+
+	event.Str("component", result.Name).Str("status", string(result.Status)).Str("previous", string(previous)).Str("error", result.Error).Msg("Component health changed")
+}
+
+func isReady(comp Component) bool {
+	select {
+	case <-comp.Ready():
+		return true
+	default:
+		return false
+	}
+}
+
+// componentNames returns the names of the components in the order they were
+// added, numbering repeated default names.
+func (a *App) componentNames() []string {
+	names := make([]string, len(a.components))
+	seen := make(map[string]int, len(a.components))
+
+	for i, comp := range a.components {
+		name := comp.name
+		if name == "" {
+			name = typeName(comp.Component)
+		}
+
+		seen[name]++
+		if n := seen[name]; n > 1 {
+			name = fmt.Sprintf("%s_%d", name, n)
+		}
+
+		names[i] = name
+	}
+
+	return names
+}
+
+// typeName turns e.g. *grpcclient.GRPCClientComponent into "grpc_client".
+// A Deferred is named after the component it built.
+func typeName(comp Component) string {
+	if d, ok := comp.(*Deferred); ok {
+		if built := d.component(); built != nil {
+			comp = built
+		}
+	}
+
+	name := reflect.TypeOf(comp).String()
+	name = name[strings.LastIndex(name, ".")+1:]
+	name = strings.TrimSuffix(name, "Component")
+
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prevLower := unicode.IsLower(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || (unicode.IsUpper(runes[i-1]) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+
+	return b.String()
+}
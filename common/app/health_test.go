@@ -0,0 +1,222 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowComponent's health check outlasts any timeout.
+type slowComponent struct {
+	*fakeComponent
+}
+
+func (s slowComponent) CheckHealth(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// markStarted puts a's components in the state Run leaves them in once
+// they are ready, except those listed in notReady.
+func markStarted(a *App, notReady ...Component) {
+	for _, w := range a.components {
+		w.started.Store(true)
+
+		ready := true
+		for _, comp := range notReady {
+			if w.Component == comp {
+				ready = false
+			}
+		}
+
+		if f, ok := w.Component.(*fakeComponent); ok && ready {
+			close(f.ready)
+		}
+		if s, ok := w.Component.(slowComponent); ok && ready {
+			close(s.ready)
+		}
+	}
+}
+
+func TestHealth(t *testing.T) {
+	errDown := errors.New("connection refused")
+
+	tests := []struct {
+		name     string
+		health   []error
+		notReady []int
+		want     HealthStatus
+		wantEach []HealthStatus
+	}{
+		{
+			name:     "all up",
+			health:   []error{nil, nil},
+			want:     HealthUp,
+			wantEach: []HealthStatus{HealthUp, HealthUp},
+		},
+		{
+			name:     "one degraded keeps serving",
+			health:   []error{nil, Degraded(errDown)},
+			want:     HealthDegraded,
+			wantEach: []HealthStatus{HealthUp, HealthDegraded},
+		},
+		{
+			name:     "one down takes the app down",
+			health:   []error{errDown, nil},
+			want:     HealthDown,
+			wantEach: []HealthStatus{HealthDown, HealthUp},
+		},
+		{
+			name:     "down wins over degraded",
+			health:   []error{Degraded(errDown), errDown},
+			want:     HealthDown,
+			wantEach: []HealthStatus{HealthDegraded, HealthDown},
+		},
+		{
+			name:     "not ready is down",
+			health:   []error{nil, nil},
+			notReady: []int{1},
+			want:     HealthDown,
+			wantEach: []HealthStatus{HealthUp, HealthDown},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recorder{}
+			a := New()
+
+			var notReady []Component
+			for i, err := range tt.health {
+				comp := newFake("fake", rec)
+				comp.health = err
+				a.Add(comp)
+
+				for _, n := range tt.notReady {
+					if n == i {
+						notReady = append(notReady, comp)
+					}
+				}
+			}
+			markStarted(a, notReady...)
+
+			report := a.Health(context.Background())
+			assert.Equal(t, tt.want, report.Status)
+			assert.Equal(t, tt.want != HealthDown, a.Serving(context.Background()))
+
+			require.Len(t, report.Checks, len(tt.wantEach))
+			for i, want := range tt.wantEach {
+				assert.Equal(t, want, report.Checks[i].Status, report.Checks[i].Name)
+				assert.Equal(t, want == HealthUp, report.Checks[i].Error == "", report.Checks[i].Name)
+			}
+		})
+	}
+}
+
+func TestHealthNotStartedIsDown(t *testing.T) {
+	a := New()
+	a.Add(newFake("db", &recorder{}))
+
+	report := a.Health(context.Background())
+	assert.Equal(t, HealthDown, report.Status)
+	assert.Equal(t, "not ready", report.Checks[0].Error)
+}
+
+func TestHealthStoppingIsDown(t *testing.T) {
+	a := New()
+	a.Add(newFake("db", &recorder{}))
+	markStarted(a)
+
+	require.Equal(t, HealthUp, a.Health(context.Background()).Status)
+
+	a.stopping.Store(true)
+	assert.Equal(t, HealthDown, a.Health(context.Background()).Status)
+}
+
+func TestHealthCheckTimeout(t *testing.T) {
+	a := New(WithHealthTimeout(10 * time.Millisecond))
+	a.Add(slowComponent{newFake("db", &recorder{})})
+	markStarted(a)
+
+	report := a.Health(context.Background())
+	assert.Equal(t, HealthDown, report.Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks[0].Error)
+}
+
+func TestHealthNames(t *testing.T) {
+	rec := &recorder{}
+	a := New()
+	a.Add(newFake("a", rec))
+	a.Add(newFake("b", rec))
+	a.Add(newFake("c", rec), Name("primary_db"))
+	a.Add(NewDeferred(func() (Component, error) { return newFake("d", rec), nil }))
+
+	var names []string
+	for _, check := range a.Health(context.Background()).Checks {
+		names = append(names, check.Name)
+	}
+
+	// The Deferred is not built yet, so it goes by its own type
+	assert.Equal(t, []string{"fake", "fake_2", "primary_db", "deferred"}, names)
+}
+
+func TestReadinessHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		health     error
+		wantCode   int
+		wantStatus HealthStatus
+	}{
+		{name: "up", wantCode: http.StatusOK, wantStatus: HealthUp},
+		{name: "degraded still gets traffic", health: Degraded(errors.New("1 of 3 brokers down")), wantCode: http.StatusOK, wantStatus: HealthDegraded},
+		{name: "down", health: errors.New("connection refused"), wantCode: http.StatusServiceUnavailable, wantStatus: HealthDown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comp := newFake("db", &recorder{})
+			comp.health = tt.health
+
+			a := New()
+			a.Add(comp)
+			markStarted(a)
+
+			w := httptest.NewRecorder()
+			a.ReadinessHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+
+			var report HealthReport
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+			assert.Equal(t, tt.wantStatus, report.Status)
+			require.Len(t, report.Checks, 1)
+			assert.Equal(t, "fake", report.Checks[0].Name)
+		})
+	}
+}
+
+func TestLivenessHandlerIgnoresDependencies(t *testing.T) {
+	comp := newFake("db", &recorder{})
+	comp.health = errors.New("connection refused")
+
+	a := New()
+	a.Add(comp)
+	markStarted(a)
+
+	w := httptest.NewRecorder()
+	a.LivenessHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/live", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var report HealthReport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Equal(t, HealthUp, report.Status)
+}
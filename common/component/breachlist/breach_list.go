@@ -0,0 +1,144 @@
+package breachlist
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+const prefixLength = 5
+
+// Config points at a directory of k-anonymity range files, one per SHA-1
+// prefix, in the format of the Pwned Passwords range API: a file named
+// "21BD1.txt" holds the lines "SUFFIX:COUNT" for every breached password
+// whose uppercase hex SHA-1 starts with 21BD1. MinCount ignores passwords
+// seen fewer times than that in breaches.
+type Config struct {
+	Path     string `yaml:"path"`
+	MinCount int64  `yaml:"min_count"`
+}
+
+type BreachListComponent struct {
+	log      zerolog.Logger
+	cfg      Config
+	prefixes map[string]string
+	ready    chan struct{}
+}
+
+// NewBreachListComponent creates a new component but does not index the
+// range files yet.
+func NewBreachListComponent(log zerolog.Logger, cfg Config) *BreachListComponent {
+	if cfg.MinCount <= 0 {
+		cfg.MinCount = 1
+	}
+
+	return &BreachListComponent{
+		log:      log,
+		cfg:      cfg,
+		prefixes: make(map[string]string),
+		ready:    make(chan struct{}),
+	}
+}
+
+// Start indexes the range files in the configured directory and then blocks
+// until the context is cancelled. Files are only read on lookup, so the
+// list can be far larger than memory. It returns an error if no range files
+// are found.
+func (bc *BreachListComponent) Start(ctx context.Context) error {
+	files, err := filepath.Glob(filepath.Join(bc.cfg.Path, "*.txt"))
+	if err != nil {
+		return fmt.Errorf("failed to glob range files: %w", err)
+	}
+
+	for _, file := range files {
+		prefix := strings.ToUpper(strings.TrimSuffix(filepath.Base(file), ".txt"))
+		if !isHexPrefix(prefix) {
+			bc.log.Warn().Str("file", filepath.Base(file)).Msg("Skipping file not named after a SHA-1 prefix")
+			continue
+		}
+
+		bc.prefixes[prefix] = file
+	}
+
+	if len(bc.prefixes) == 0 {
+		return fmt.Errorf("no range files found in path: %s", bc.cfg.Path)
+	}
+
+	close(bc.ready) // signal readiness
+	bc.log.Debug().Msgf("Breached password list indexed, total prefixes: %d", len(bc.prefixes))
+	<-ctx.Done() // Block until shutdown signal
+	bc.log.Debug().Msg("Breach list component context cancelled – stopping")
+
+	return nil
+}
+
+// Stop performs any necessary cleanup. For this component, nothing is required.
+func (bc *BreachListComponent) Stop(ctx context.Context) error {
+	bc.log.Debug().Msg("Breach list component stopped")
+	return nil
+}
+
+// IsBreached reports whether password appears in the list at least MinCount
+// times. Only the range file for the first five hex digits of its SHA-1 is
+// read; a prefix without a file counts as not breached.
+func (bc *BreachListComponent) IsBreached(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	file, ok := bc.prefixes[hash[:prefixLength]]
+	if !ok {
+		return false, nil
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return false, fmt.Errorf("open range file: %w", err)
+	}
+	defer f.Close()
+
+	suffix := hash[prefixLength:]
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lineSuffix, count, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !strings.EqualFold(lineSuffix, suffix) {
+			continue
+		}
+
+		// A list without counts flags every entry
+		seen, err := strconv.ParseInt(count, 10, 64)
+		if err != nil {
+			return true, nil
+		}
+
+		return seen >= bc.cfg.MinCount, nil
+	}
+
+	if err := scanner.Err(); err != nil {
+		return false, fmt.Errorf("read range file: %w", err)
+	}
+
+	return false, nil
+}
+
+// Ready returns a channel that is closed when the range files are indexed.
+func (bc *BreachListComponent) Ready() <-chan struct{} {
+	return bc.ready
+}
+
+func isHexPrefix(s string) bool {
+	if len(s) != prefixLength {
+		return false
+	}
+
+	_, err := hex.DecodeString(s + "0")
+	return err == nil
+}
@@ -8,22 +8,29 @@ import (
 	"strings"
 	"time"
 
+	"github.com/linggaaskaedo/go-kill/common/app"
+	"github.com/linggaaskaedo/go-kill/common/pkg/metrics"
 	"github.com/linggaaskaedo/go-kill/common/pkg/preference"
 
+	"github.com/XSAM/otelsql"
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
 )
 
 type Config struct {
 	Enabled         bool          `yaml:"enabled"`
-	Driver          string        `yaml:"driver"`
-	Host            string        `yaml:"host"`
-	Port            int           `yaml:"port"`
+	Driver          string        `yaml:"driver" validate:"required_if=Enabled true,omitempty,oneof=postgres mysql mariadb"`
+	Host            string        `yaml:"host" validate:"required_if=Enabled true"`
+	Port            int           `yaml:"port" validate:"required_if=Enabled true,max=65535"`
 	User            string        `yaml:"user"`
 	Password        string        `yaml:"password"`
-	DBName          string        `yaml:"dbname"`
+	DBName          string        `yaml:"dbname" validate:"required_if=Enabled true"`
 	SSLMode         bool          `yaml:"sslmode"`
 	MaxOpenConns    int           `yaml:"max_open_conns"`
 	MaxIdleConns    int           `yaml:"max_idle_conns"`
@@ -36,6 +43,7 @@ type DatabaseComponent struct {
 	cfg   Config
 	ready chan struct{}
 	db    *sqlx.DB
+	stats prometheus.Collector
 }
 
 // NewDatabaseComponent creates a new database component but does not start it.
@@ -62,12 +70,27 @@ func (d *DatabaseComponent) Start(ctx context.Context) error {
 		return fmt.Errorf("build database URI: %w", err)
 	}
 
-	db, err := sqlx.ConnectContext(ctx, driver, uri)
+	// Open through otelsql so that every query gets a client span
+	sqlDB, err := otelsql.Open(driver, uri,
+		otelsql.WithAttributes(d.systemName(), semconv.DBNamespace(d.cfg.DBName)),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			OmitConnResetSession: true,
+			OmitRows:             true,
+			DisableErrSkip:       true,
+		}),
+	)
 	if err != nil {
 		d.log.Error().Err(err).Msg("Failed connect to database")
 		return fmt.Errorf("connect to database: %w", err)
 	}
 
+	db := sqlx.NewDb(sqlDB, driver)
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		d.log.Error().Err(err).Msg("Failed connect to database")
+		return fmt.Errorf("connect to database: %w", err)
+	}
+
 	d.db = db
 
 	// Validate and configure connection pool
@@ -84,6 +107,14 @@ func (d *DatabaseComponent) Start(ctx context.Context) error {
 		d.db.SetConnMaxIdleTime(d.cfg.ConnMaxIdleTime)
 	}
 
+	// Export the pool stats as go_sql_* labelled with the database name
+	stats := collectors.NewDBStatsCollector(d.db.DB, d.cfg.DBName)
+	if err := metrics.Register(stats); err != nil {
+		d.log.Warn().Err(err).Msg("Failed to register database pool metrics")
+	} else {
+		d.stats = stats
+	}
+
 	close(d.ready) // signal readiness
 	d.log.Debug().Msgf("%s database connected and ping OK", strings.ToUpper(d.cfg.Driver))
 	<-ctx.Done() // Block until shutdown signal
@@ -99,6 +130,10 @@ func (d *DatabaseComponent) Stop(ctx context.Context) error {
 		return nil
 	}
 
+	if d.stats != nil {
+		metrics.Unregister(d.stats)
+	}
+
 	// Close waits for all connections to be returned to the pool before closing.
 	if err := d.db.Close(); err != nil {
 		return fmt.Errorf("close database: %w", err)
@@ -109,6 +144,25 @@ func (d *DatabaseComponent) Stop(ctx context.Context) error {
 	return nil
 }
 
+// CheckHealth pings the database. A pool with every connection in use and
+// callers waiting for one is degraded.
+func (d *DatabaseComponent) CheckHealth(ctx context.Context) error {
+	if d.db == nil {
+		return errors.New("database not connected")
+	}
+
+	if err := d.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("database ping: %w", err)
+	}
+
+	stats := d.db.Stats()
+	if stats.MaxOpenConnections > 0 && stats.InUse >= stats.MaxOpenConnections && stats.WaitCount > 0 {
+		return app.Degraded(fmt.Errorf("connection pool exhausted: %d of %d in use", stats.InUse, stats.MaxOpenConnections))
+	}
+
+	return nil
+}
+
 // getURI constructs the driver name and connection string based on config.
 // It is a slightly modified version of your existing getURI.
 func (d *DatabaseComponent) getURI(cfg Config) (string, string, error) {
@@ -145,6 +199,18 @@ func (d *DatabaseComponent) getURI(cfg Config) (string, string, error) {
 	}
 }
 
+// systemName returns the db.system.name attribute of the configured driver.
+func (d *DatabaseComponent) systemName() attribute.KeyValue {
+	switch d.cfg.Driver {
+	case preference.POSTGRES:
+		return semconv.DBSystemNamePostgreSQL
+	case preference.MARIADB:
+		return semconv.DBSystemNameMariaDB
+	default:
+		return semconv.DBSystemNameMySQL
+	}
+}
+
 // Client returns the underlying *sqlx.DB for use by other components.
 // It is safe to call only after Start has completed successfully.
 func (d *DatabaseComponent) Client() *sqlx.DB {
@@ -8,6 +8,8 @@ import (
 	"os"
 	"time"
 
+	"github.com/linggaaskaedo/go-kill/common/app"
+
 	"github.com/rs/zerolog"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/connectivity"
@@ -16,17 +18,28 @@ import (
 )
 
 type Config struct {
-	Target   string        `yaml:"target"`
-	Timeout  time.Duration `yaml:"timeout"`
+	Target   string        `yaml:"target" validate:"required"`
+	Timeout  time.Duration `yaml:"timeout" default:"5s"`
 	Insecure bool          `yaml:"insecure"`
 	TLS      TLSConfig     `yaml:"tls"`
+	// Lazy skips waiting for the connection on Start. Use it for optional
+	// peers, or where two services call each other and neither can wait for
+	// the other to come up first. Calls fail with Unavailable until the
+	// target is reachable.
+	Lazy bool `yaml:"lazy"`
 }
 
+// TLSConfig verifies the server against CAFile. CertFile and KeyFile are
+// the client certificate presented to servers that require mutual TLS; the
+// service name in it is what their allowed_callers match. ServerName
+// overrides the name checked in the server certificate when it differs from
+// the target host.
 type TLSConfig struct {
-	Enabled  bool   `yaml:"enabled"`
-	CertFile string `yaml:"cert_file"`
-	KeyFile  string `yaml:"key_file"`
-	CAFile   string `yaml:"ca_file"`
+	Enabled    bool   `yaml:"enabled"`
+	CertFile   string `yaml:"cert_file"`
+	KeyFile    string `yaml:"key_file"`
+	CAFile     string `yaml:"ca_file"`
+	ServerName string `yaml:"server_name"`
 }
 
 type GRPCClientComponent struct {
@@ -48,7 +61,7 @@ func NewGRPCClientComponent(log zerolog.Logger, cfg Config) *GRPCClientComponent
 // Start dials the target and blocks until the context is cancelled.
 func (c *GRPCClientComponent) Start(ctx context.Context) error {
 	opts := []grpc.DialOption{
-		grpc.WithUnaryInterceptor(c.ReqIDClientInterceptor),
+		grpc.WithChainUnaryInterceptor(TracingClientInterceptor, MetricsClientInterceptor, c.ReqIDClientInterceptor),
 	}
 
 	switch {
@@ -71,6 +84,13 @@ func (c *GRPCClientComponent) Start(ctx context.Context) error {
 
 	c.conn = client
 
+	if c.cfg.Lazy {
+		close(c.ready)
+		c.log.Debug().Str("target", c.cfg.Target).Msg("gRPC client created, connecting lazily")
+		<-ctx.Done()
+		return nil
+	}
+
 	waitCtx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
 	defer cancel()
 
@@ -117,6 +137,7 @@ func (c *GRPCClientComponent) loadTLSCredentials() (credentials.TransportCredent
 		tlsConfig.Certificates = []tls.Certificate{cert}
 	}
 
+	tlsConfig.ServerName = c.cfg.TLS.ServerName
 	tlsConfig.MinVersion = tls.VersionTLS12
 
 	return credentials.NewTLS(tlsConfig), nil
@@ -136,6 +157,34 @@ func (c *GRPCClientComponent) Stop(ctx context.Context) error {
 	return nil
 }
 
+// CheckHealth reports the connection state. A connection that is still
+// connecting is degraded, as is a failing one of a lazy client, which the
+// service is expected to run without.
+func (c *GRPCClientComponent) CheckHealth(ctx context.Context) error {
+	if c.conn == nil {
+		return fmt.Errorf("gRPC client to %s not started", c.cfg.Target)
+	}
+
+	switch state := c.conn.GetState(); state {
+	case connectivity.Ready:
+		return nil
+	case connectivity.Idle:
+		// Idle connections reconnect on the next call; start that now
+		c.conn.Connect()
+		return nil
+	case connectivity.Connecting:
+		return app.Degraded(fmt.Errorf("connecting to %s", c.cfg.Target))
+	case connectivity.TransientFailure:
+		err := fmt.Errorf("connection to %s failing", c.cfg.Target)
+		if c.cfg.Lazy {
+			return app.Degraded(err)
+		}
+		return err
+	default:
+		return fmt.Errorf("connection to %s is %s", c.cfg.Target, state)
+	}
+}
+
 // Conn returns the underlying *grpc.ClientConn. Use it to create stubs.
 func (c *GRPCClientComponent) Conn() *grpc.ClientConn {
 	return c.conn
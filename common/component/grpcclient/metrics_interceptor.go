@@ -0,0 +1,24 @@
+package grpcclient
+
+import (
+	"context"
+	"time"
+
+	"github.com/linggaaskaedo/go-kill/common/pkg/metrics"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// MetricsClientInterceptor counts calls by status code and records their
+// duration.
+func MetricsClientInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	start := time.Now()
+	err := invoker(ctx, method, req, reply, cc, opts...)
+
+	service, name := metrics.SplitMethodName(method)
+	metrics.GRPCClientHandled.WithLabelValues(service, name, status.Code(err).String()).Inc()
+	metrics.GRPCClientHandlingDuration.WithLabelValues(service, name).Observe(time.Since(start).Seconds())
+
+	return err
+}
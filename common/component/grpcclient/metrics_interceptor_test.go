@@ -0,0 +1,40 @@
+package grpcclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/linggaaskaedo/go-kill/common/pkg/metrics"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMetricsClientInterceptor(t *testing.T) {
+	metrics.Init("test-service")
+
+	invoke := func(err error) grpc.UnaryInvoker {
+		return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			return err
+		}
+	}
+
+	const method = "/product.ProductService/CommitInventory"
+	assert.NoError(t, MetricsClientInterceptor(context.Background(), method, nil, nil, nil, invoke(nil)))
+	assert.NoError(t, MetricsClientInterceptor(context.Background(), method, nil, nil, nil, invoke(nil)))
+
+	err := MetricsClientInterceptor(context.Background(), method, nil, nil, nil, invoke(status.Error(codes.NotFound, "reservation not found")))
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	w := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := w.Body.String()
+
+	assert.Contains(t, body, `grpc_client_handled_total{grpc_code="OK",grpc_method="CommitInventory",grpc_service="product.ProductService",service="test-service"} 2`)
+	assert.Contains(t, body, `grpc_client_handled_total{grpc_code="NotFound",grpc_method="CommitInventory",grpc_service="product.ProductService",service="test-service"} 1`)
+	assert.Contains(t, body, `grpc_client_handling_seconds_count{grpc_method="CommitInventory",grpc_service="product.ProductService",service="test-service"} 3`)
+}
@@ -0,0 +1,47 @@
+package grpcclient
+
+import (
+	"context"
+	"strings"
+
+	"github.com/linggaaskaedo/go-kill/common/pkg/tracing"
+
+	"go.opentelemetry.io/otel"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// TracingClientInterceptor starts a client span for the call and sends its
+// trace context in the outgoing metadata.
+func TracingClientInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	name := strings.TrimPrefix(method, "/")
+
+	ctx, span := tracing.Tracer().Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.RPCSystemNameGRPC,
+			semconv.RPCMethod(name),
+			semconv.ServerAddress(cc.Target()),
+		),
+	)
+
+	// Copy the metadata; the one in ctx may be shared with other calls
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if ok {
+		md = md.Copy()
+	} else {
+		md = metadata.New(nil)
+	}
+	otel.GetTextMapPropagator().Inject(ctx, tracing.MetadataCarrier(md))
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	err := invoker(ctx, method, req, reply, cc, opts...)
+
+	span.SetAttributes(semconv.RPCResponseStatusCode(status.Code(err).String()))
+	tracing.End(span, err)
+
+	return err
+}
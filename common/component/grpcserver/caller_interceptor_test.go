@@ -0,0 +1,172 @@
+package grpcserver
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	testReserveMethod = "/product.ProductService/ReserveInventory"
+	testGetMethod     = "/product.ProductService/GetProduct"
+)
+
+func mustURL(t *testing.T, raw string) *url.URL {
+	t.Helper()
+
+	u, err := url.Parse(raw)
+	require.NoError(t, err)
+
+	return u
+}
+
+func TestCertIdentity(t *testing.T) {
+	tests := []struct {
+		name string
+		cert *x509.Certificate
+		want string
+	}{
+		{
+			name: "spiffe uri san",
+			cert: &x509.Certificate{
+				URIs:     []*url.URL{mustURL(t, "spiffe://cluster.local/ns/shop/sa/order-service")},
+				Subject:  pkix.Name{CommonName: "ignored"},
+				DNSNames: []string{"ignored.shop.svc"},
+			},
+			want: "order-service",
+		},
+		{
+			name: "uri without path falls back to common name",
+			cert: &x509.Certificate{
+				URIs:    []*url.URL{mustURL(t, "spiffe://cluster.local")},
+				Subject: pkix.Name{CommonName: "user-service"},
+			},
+			want: "user-service",
+		},
+		{
+			name: "common name",
+			cert: &x509.Certificate{Subject: pkix.Name{CommonName: "gateway-service"}, DNSNames: []string{"gateway.shop.svc"}},
+			want: "gateway-service",
+		},
+		{
+			name: "dns san",
+			cert: &x509.Certificate{DNSNames: []string{"product-service", "product.shop.svc"}},
+			want: "product-service",
+		},
+		{
+			name: "nothing to go by",
+			cert: &x509.Certificate{},
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, certIdentity(tt.cert))
+		})
+	}
+}
+
+func TestMethodCallersAllowed(t *testing.T) {
+	callers := MethodCallers{
+		testReserveMethod:           {"order-service"},
+		"/product.ProductService/*": {"order-service", "gateway-service"},
+		"/auth.AuthService/Login":   {},
+		"*":                         {"gateway-service"},
+	}
+
+	tests := []struct {
+		name     string
+		callers  MethodCallers
+		method   string
+		want     []string
+		wantOkay bool
+	}{
+		{name: "exact method wins over wildcards", callers: callers, method: testReserveMethod, want: []string{"order-service"}, wantOkay: true},
+		{name: "service wildcard", callers: callers, method: testGetMethod, want: []string{"order-service", "gateway-service"}, wantOkay: true},
+		{name: "empty list allows nobody", callers: callers, method: "/auth.AuthService/Login", want: []string{}, wantOkay: true},
+		{name: "catch all", callers: callers, method: "/user.UserService/GetUser", want: []string{"gateway-service"}, wantOkay: true},
+		{name: "not covered", callers: MethodCallers{testReserveMethod: {"order-service"}}, method: testGetMethod},
+		{name: "no entries", callers: nil, method: testGetMethod},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.callers.allowed(tt.method)
+			assert.Equal(t, tt.wantOkay, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// callerContext is a call from a peer that presented a verified client
+// certificate with the given common name.
+func callerContext(name string) context.Context {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: name}}
+
+	return peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}},
+	})
+}
+
+func TestCallerUnaryServerInterceptor(t *testing.T) {
+	interceptor := CallerUnaryServerInterceptor(MethodCallers{
+		testReserveMethod:         {"order-service"},
+		"/auth.AuthService/Login": {},
+	})
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		method   string
+		wantCode codes.Code
+	}{
+		{name: "listed caller", ctx: callerContext("order-service"), method: testReserveMethod, wantCode: codes.OK},
+		{name: "caller not on the list", ctx: callerContext("gateway-service"), method: testReserveMethod, wantCode: codes.PermissionDenied},
+		{name: "empty list", ctx: callerContext("order-service"), method: "/auth.AuthService/Login", wantCode: codes.PermissionDenied},
+		{name: "method not covered accepts any verified caller", ctx: callerContext("gateway-service"), method: testGetMethod, wantCode: codes.OK},
+		{name: "no peer", ctx: context.Background(), method: testGetMethod, wantCode: codes.Unauthenticated},
+		{
+			name:     "tls without a verified certificate",
+			ctx:      peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{}}),
+			method:   testGetMethod,
+			wantCode: codes.Unauthenticated,
+		},
+		{name: "certificate without an identity", ctx: callerContext(""), method: testGetMethod, wantCode: codes.Unauthenticated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			handler := func(ctx context.Context, req any) (any, error) {
+				called = true
+				return "ok", nil
+			}
+
+			_, err := interceptor(tt.ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			assert.Equal(t, tt.wantCode == codes.OK, called)
+		})
+	}
+}
+
+func TestCallerIdentity(t *testing.T) {
+	name, ok := CallerIdentity(callerContext("notification-service"))
+	assert.True(t, ok)
+	assert.Equal(t, "notification-service", name)
+
+	_, ok = CallerIdentity(context.Background())
+	assert.False(t, ok)
+}
@@ -2,41 +2,72 @@ package grpcserver
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"net"
+	"os"
 	"time"
 
 	"github.com/rs/zerolog"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 type Config struct {
-	Port            string        `yaml:"port"`
-	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	Port            string        `yaml:"port" validate:"required"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" default:"10s"`
+	TLS             TLSConfig     `yaml:"tls"`
+	// AllowedCallers restricts methods to the services named in the
+	// caller's client certificate. It needs TLS with a client CA.
+	AllowedCallers MethodCallers `yaml:"allowed_callers"`
+}
+
+// TLSConfig serves TLS with CertFile and KeyFile. With ClientCAFile set,
+// callers must present a certificate signed by that CA (mutual TLS).
+type TLSConfig struct {
+	Enabled      bool   `yaml:"enabled"`
+	CertFile     string `yaml:"cert_file" validate:"required_if=Enabled true"`
+	KeyFile      string `yaml:"key_file" validate:"required_if=Enabled true"`
+	ClientCAFile string `yaml:"client_ca_file"`
 }
 
 type GRPCServerComponent struct {
-	log       zerolog.Logger
-	cfg       Config
-	registrar func(context.Context, *grpc.Server) error
-	ready     chan struct{}
-	server    *grpc.Server
-	lis       net.Listener
+	log          zerolog.Logger
+	cfg          Config
+	registrar    func(context.Context, *grpc.Server) error
+	interceptors []grpc.UnaryServerInterceptor
+	ready        chan struct{}
+	server       *grpc.Server
+	lis          net.Listener
+	health       *health.Server
+	healthCheck  func(context.Context) bool
 }
 
 // NewGRPCServerComponent creates a new server component with the given service registrars.
-func NewGRPCServerComponent(log zerolog.Logger, cfg Config, registrar func(context.Context, *grpc.Server) error) *GRPCServerComponent {
+// Extra unary interceptors run after the metrics, request id, logging and
+// caller interceptors.
+func NewGRPCServerComponent(log zerolog.Logger, cfg Config, registrar func(context.Context, *grpc.Server) error, interceptors ...grpc.UnaryServerInterceptor) *GRPCServerComponent {
 	return &GRPCServerComponent{
-		log:       log,
-		cfg:       cfg,
-		registrar: registrar,
-		ready:     make(chan struct{}),
+		log:          log,
+		cfg:          cfg,
+		registrar:    registrar,
+		interceptors: interceptors,
+		ready:        make(chan struct{}),
 	}
 }
 
 // Start creates the listener, registers services, and begins serving.
 // It blocks until the context is cancelled or the server fails.
 func (s *GRPCServerComponent) Start(ctx context.Context) error {
+	serverOpts, err := s.serverOptions()
+	if err != nil {
+		return err
+	}
+
 	// 1. Create listener (non‑blocking, but we'll close it on cancellation).
 	lis, err := net.Listen("tcp", s.cfg.Port)
 	if err != nil {
@@ -45,27 +76,25 @@ func (s *GRPCServerComponent) Start(ctx context.Context) error {
 	s.lis = lis
 
 	// 2. Ensure listener is closed if context is cancelled before we finish.
+	// Stop may have closed it already.
 	go func() {
 		<-ctx.Done()
-		if err := lis.Close(); err != nil {
-			panic(err)
+		if err := lis.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+			s.log.Error().Err(err).Msg("gRPC listener close")
 		}
 	}()
 
 	s.log.Info().Str("port", s.cfg.Port).Msg("gRPC server listening")
 
 	// 3. Create server with interceptors.
-	grpcServer := grpc.NewServer(
-		grpc.ChainUnaryInterceptor(
-			s.ReqIDServerInterceptor,
-			LoggingUnaryServerInterceptor(s.log),
-		),
-		grpc.ChainStreamInterceptor(
-			s.StreamServerInterceptor,
-			LoggingStreamServerInterceptor(s.log),
-		))
+	grpcServer := grpc.NewServer(serverOpts...)
+
+	// 4. Serve grpc.health.v1 next to the services. It reports not serving
+	// until the server is up.
+	s.health = newHealthServer()
+	healthpb.RegisterHealthServer(grpcServer, s.health)
 
-	// 4. Run the (possibly blocking) registrar with context.
+	// 5. Run the (possibly blocking) registrar with context.
 	if err := s.registrar(ctx, grpcServer); err != nil {
 		// Registration failed – close listener and return error.
 		lis.Close()
@@ -74,7 +103,7 @@ func (s *GRPCServerComponent) Start(ctx context.Context) error {
 
 	s.server = grpcServer
 
-	// 5. Channel for Serve errors.
+	// 6. Channel for Serve errors.
 	serveErr := make(chan error, 1)
 	go func() {
 		s.log.Debug().Str("port", s.cfg.Port).Msg("gRPC server starting")
@@ -83,10 +112,11 @@ func (s *GRPCServerComponent) Start(ctx context.Context) error {
 		}
 	}()
 
-	// 6. Signal readiness.
+	// 7. Signal readiness.
 	close(s.ready)
+	go s.watchHealth(ctx)
 
-	// 7. Wait for shutdown or serve error.
+	// 8. Wait for shutdown or serve error.
 	select {
 	case <-ctx.Done():
 		s.log.Debug().Msg("gRPC server context cancelled – stopping")
@@ -96,6 +126,71 @@ func (s *GRPCServerComponent) Start(ctx context.Context) error {
 	}
 }
 
+// serverOptions builds the transport credentials and interceptor chain from
+// the config.
+func (s *GRPCServerComponent) serverOptions() ([]grpc.ServerOption, error) {
+	unary := []grpc.UnaryServerInterceptor{
+		TracingUnaryServerInterceptor,
+		MetricsUnaryServerInterceptor,
+		s.ReqIDServerInterceptor,
+		LoggingUnaryServerInterceptor(s.log),
+	}
+
+	var opts []grpc.ServerOption
+
+	if s.cfg.TLS.Enabled {
+		creds, err := s.loadTLSCredentials()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load TLS credentials: %w", err)
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	if len(s.cfg.AllowedCallers) > 0 {
+		if !s.cfg.TLS.Enabled || s.cfg.TLS.ClientCAFile == "" {
+			return nil, fmt.Errorf("allowed_callers needs tls with client_ca_file")
+		}
+		unary = append(unary, CallerUnaryServerInterceptor(s.cfg.AllowedCallers))
+	}
+
+	return append(opts,
+		grpc.ChainUnaryInterceptor(append(unary, s.interceptors...)...),
+		grpc.ChainStreamInterceptor(
+			TracingStreamServerInterceptor,
+			MetricsStreamServerInterceptor,
+			s.StreamServerInterceptor,
+			LoggingStreamServerInterceptor(s.log),
+		),
+	), nil
+}
+
+func (s *GRPCServerComponent) loadTLSCredentials() (credentials.TransportCredentials, error) {
+	cert, err := tls.LoadX509KeyPair(s.cfg.TLS.CertFile, s.cfg.TLS.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load server cert: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if s.cfg.TLS.ClientCAFile != "" {
+		caCert, err := os.ReadFile(s.cfg.TLS.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA cert: %w", err)
+		}
+		caCertPool := x509.NewCertPool()
+		if !caCertPool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("failed to add client CA cert to pool")
+		}
+		tlsConfig.ClientCAs = caCertPool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return credentials.NewTLS(tlsConfig), nil
+}
+
 // Stop gracefully stops the server, waiting for ongoing requests to finish.
 func (s *GRPCServerComponent) Stop(ctx context.Context) error {
 	s.log.Debug().Msg("GRPCServerComponent.Stop: starting")
@@ -104,6 +199,9 @@ func (s *GRPCServerComponent) Stop(ctx context.Context) error {
 		return nil
 	}
 
+	// Report not serving while the in-flight calls finish
+	s.health.Shutdown()
+
 	stopCtx, cancel := context.WithTimeout(ctx, s.cfg.ShutdownTimeout)
 	defer cancel()
 
@@ -0,0 +1,53 @@
+package grpcserver
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// healthCheckInterval is how often the grpc.health.v1 status is refreshed
+// from the health check.
+const healthCheckInterval = 5 * time.Second
+
+// SetHealthCheck makes the grpc.health.v1 service report the server as
+// serving only while check, e.g. App.Serving, returns true. Without one the
+// server is serving for as long as it runs. Call it before Start.
+func (s *GRPCServerComponent) SetHealthCheck(check func(context.Context) bool) {
+	s.healthCheck = check
+}
+
+// watchHealth keeps the overall ("") status of the health service up to
+// date until ctx is done.
+func (s *GRPCServerComponent) watchHealth(ctx context.Context) {
+	if s.healthCheck == nil {
+		s.health.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+		return
+	}
+
+	ticker := time.NewTicker(healthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		status := healthpb.HealthCheckResponse_NOT_SERVING
+		if s.healthCheck(ctx) {
+			status = healthpb.HealthCheckResponse_SERVING
+		}
+		s.health.SetServingStatus("", status)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func newHealthServer() *health.Server {
+	hs := health.NewServer()
+	hs.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	return hs
+}
@@ -0,0 +1,129 @@
+package grpcserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// startTestServer serves s on a free local port until the test ends and
+// returns a health client connected to it.
+func startTestServer(t *testing.T, s *GRPCServerComponent) healthpb.HealthClient {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Start(ctx) }()
+
+	select {
+	case <-s.Ready():
+	case err := <-done:
+		cancel()
+		t.Fatalf("server did not start: %v", err)
+	case <-time.After(5 * time.Second):
+		cancel()
+		t.Fatal("server not ready")
+	}
+
+	conn, err := grpc.NewClient(s.lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = conn.Close()
+		cancel()
+		<-done
+		_ = s.Stop(context.Background())
+	})
+
+	return healthpb.NewHealthClient(conn)
+}
+
+func newTestServer() *GRPCServerComponent {
+	cfg := Config{Port: "127.0.0.1:0", ShutdownTimeout: time.Second}
+
+	return NewGRPCServerComponent(zerolog.Nop(), cfg, func(context.Context, *grpc.Server) error { return nil })
+}
+
+// servingStatus polls the overall status until it is want or a second has
+// passed, since the health watcher sets it after the server is ready.
+func servingStatus(t *testing.T, client healthpb.HealthClient, want healthpb.HealthCheckResponse_ServingStatus) healthpb.HealthCheckResponse_ServingStatus {
+	t.Helper()
+
+	var got healthpb.HealthCheckResponse_ServingStatus
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+		require.NoError(t, err)
+
+		got = resp.Status
+		if got == want {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return got
+}
+
+func TestHealthServiceRegistered(t *testing.T) {
+	tests := []struct {
+		name  string
+		check func(context.Context) bool
+		want  healthpb.HealthCheckResponse_ServingStatus
+	}{
+		{name: "no check serves while running", want: healthpb.HealthCheckResponse_SERVING},
+		{name: "serving app", check: func(context.Context) bool { return true }, want: healthpb.HealthCheckResponse_SERVING},
+		{name: "app down", check: func(context.Context) bool { return false }, want: healthpb.HealthCheckResponse_NOT_SERVING},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer()
+			if tt.check != nil {
+				s.SetHealthCheck(tt.check)
+			}
+
+			client := startTestServer(t, s)
+
+			assert.Equal(t, tt.want, servingStatus(t, client, tt.want))
+		})
+	}
+}
+
+func TestWatchHealthFollowsCheck(t *testing.T) {
+	s := &GRPCServerComponent{health: newHealthServer()}
+
+	checked := make(chan struct{}, 1)
+	s.SetHealthCheck(func(context.Context) bool {
+		checked <- struct{}{}
+		return true
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.watchHealth(ctx)
+		close(done)
+	}()
+
+	<-checked
+	cancel()
+	<-done
+
+	resp, err := s.health.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+}
+
+func TestHealthServerStartsNotServing(t *testing.T) {
+	resp, err := newHealthServer().Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, resp.Status)
+}
@@ -0,0 +1,37 @@
+package grpcserver
+
+import (
+	"context"
+	"time"
+
+	"github.com/linggaaskaedo/go-kill/common/pkg/metrics"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// MetricsUnaryServerInterceptor counts calls by status code and records
+// their duration. It runs ahead of all but tracing, so calls the other
+// interceptors reject are counted too.
+func MetricsUnaryServerInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	observeCall(info.FullMethod, err, time.Since(start))
+
+	return resp, err
+}
+
+func MetricsStreamServerInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	observeCall(info.FullMethod, err, time.Since(start))
+
+	return err
+}
+
+func observeCall(fullMethod string, err error, latency time.Duration) {
+	service, method := metrics.SplitMethodName(fullMethod)
+
+	metrics.GRPCServerHandled.WithLabelValues(service, method, status.Code(err).String()).Inc()
+	metrics.GRPCServerHandlingDuration.WithLabelValues(service, method).Observe(latency.Seconds())
+}
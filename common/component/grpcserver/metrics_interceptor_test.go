@@ -0,0 +1,62 @@
+package grpcserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/linggaaskaedo/go-kill/common/pkg/metrics"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func init() {
+	metrics.Init("test-service")
+}
+
+func scrapeMetrics(t *testing.T) string {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	return w.Body.String()
+}
+
+func TestMetricsUnaryServerInterceptor(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/inventory.InventoryService/Reserve"}
+
+	ok := func(ctx context.Context, req any) (any, error) { return "done", nil }
+	fail := func(ctx context.Context, req any) (any, error) {
+		return nil, status.Error(codes.FailedPrecondition, "insufficient inventory")
+	}
+
+	resp, err := MetricsUnaryServerInterceptor(context.Background(), nil, info, ok)
+	assert.NoError(t, err)
+	assert.Equal(t, "done", resp)
+
+	_, err = MetricsUnaryServerInterceptor(context.Background(), nil, info, fail)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	body := scrapeMetrics(t)
+	assert.Contains(t, body, `grpc_server_handled_total{grpc_code="OK",grpc_method="Reserve",grpc_service="inventory.InventoryService",service="test-service"} 1`)
+	assert.Contains(t, body, `grpc_server_handled_total{grpc_code="FailedPrecondition",grpc_method="Reserve",grpc_service="inventory.InventoryService",service="test-service"} 1`)
+	assert.Contains(t, body, `grpc_server_handling_seconds_count{grpc_method="Reserve",grpc_service="inventory.InventoryService",service="test-service"} 2`)
+}
+
+func TestMetricsStreamServerInterceptor(t *testing.T) {
+	info := &grpc.StreamServerInfo{FullMethod: "/inventory.InventoryService/Watch"}
+
+	err := MetricsStreamServerInterceptor(nil, nil, info, func(srv any, ss grpc.ServerStream) error {
+		return status.Error(codes.Unavailable, "draining")
+	})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	body := scrapeMetrics(t)
+	assert.Contains(t, body, `grpc_server_handled_total{grpc_code="Unavailable",grpc_method="Watch",grpc_service="inventory.InventoryService",service="test-service"} 1`)
+	assert.Contains(t, body, `grpc_server_handling_seconds_count{grpc_method="Watch",grpc_service="inventory.InventoryService",service="test-service"} 1`)
+}
@@ -0,0 +1,68 @@
+package grpcserver
+
+import (
+	"context"
+	"strings"
+
+	"github.com/linggaaskaedo/go-kill/common/pkg/tracing"
+
+	"go.opentelemetry.io/otel"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// healthMethodPrefix is skipped by the tracing interceptors; probes would
+// otherwise start a trace every few seconds.
+const healthMethodPrefix = "/grpc.health.v1.Health/"
+
+// TracingUnaryServerInterceptor starts a server span for the call,
+// continuing the trace the caller sent in the metadata.
+func TracingUnaryServerInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if strings.HasPrefix(info.FullMethod, healthMethodPrefix) {
+		return handler(ctx, req)
+	}
+
+	ctx, span := startServerSpan(ctx, info.FullMethod)
+	resp, err := handler(ctx, req)
+	endServerSpan(span, err)
+
+	return resp, err
+}
+
+func TracingStreamServerInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if strings.HasPrefix(info.FullMethod, healthMethodPrefix) {
+		return handler(srv, ss)
+	}
+
+	ctx, span := startServerSpan(ss.Context(), info.FullMethod)
+	err := handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	endServerSpan(span, err)
+
+	return err
+}
+
+func startServerSpan(ctx context.Context, fullMethod string) (context.Context, trace.Span) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		md = metadata.New(nil)
+	}
+
+	ctx = otel.GetTextMapPropagator().Extract(ctx, tracing.MetadataCarrier(md))
+	method := strings.TrimPrefix(fullMethod, "/")
+
+	return tracing.Tracer().Start(ctx, method,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			semconv.RPCSystemNameGRPC,
+			semconv.RPCMethod(method),
+		),
+	)
+}
+
+func endServerSpan(span trace.Span, err error) {
+	span.SetAttributes(semconv.RPCResponseStatusCode(status.Code(err).String()))
+	tracing.End(span, err)
+}
@@ -1,6 +1,9 @@
 package http
 
 import (
+	"net/http"
+	"strings"
+
 	"github.com/linggaaskaedo/go-kill/common/pkg/middleware"
 
 	"github.com/gin-gonic/gin"
@@ -10,18 +13,36 @@ import (
 	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
+// Config configures the router. TrustedProxies lists the addresses or CIDRs
+// whose X-Forwarded-For is believed when resolving the client IP; with none
+// set, the client IP is always the peer address.
 type Config struct {
-	AppName string `yaml:"app_name"`
+	AppName        string   `yaml:"app_name"`
+	TrustedProxies []string `yaml:"trusted_proxies" validate:"dive,ip|cidr"`
+}
+
+// traced leaves out probes and scrapes, which would otherwise start a trace
+// every few seconds.
+func traced(r *http.Request) bool {
+	return !strings.HasPrefix(r.URL.Path, "/health/") && r.URL.Path != "/metrics"
 }
 
 func Init(log zerolog.Logger, middleware middleware.Middleware, cfg Config) *gin.Engine {
 	gin.SetMode(gin.ReleaseMode)
 
 	router := gin.New()
+
+	// gin trusts every proxy by default, which lets any client pick its own
+	// IP. An invalid list falls back to trusting none.
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Error().Err(err).Msg("http_trusted_proxies")
+		_ = router.SetTrustedProxies(nil)
+	}
+
 	router.Use(middleware.Recovery())
 
 	if cfg.AppName != "" {
-		router.Use(otelgin.Middleware(cfg.AppName))
+		router.Use(otelgin.Middleware(cfg.AppName, otelgin.WithFilter(traced)))
 	}
 
 	router.Use(middleware.Handler())
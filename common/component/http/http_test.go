@@ -0,0 +1,82 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+// passMiddleware stands in for middleware.Middleware; these tests are only
+// about the router.
+type passMiddleware struct{}
+
+func (passMiddleware) Handler() gin.HandlerFunc  { return func(c *gin.Context) { c.Next() } }
+func (passMiddleware) Recovery() gin.HandlerFunc { return func(c *gin.Context) { c.Next() } }
+func (passMiddleware) CORS() gin.HandlerFunc     { return func(c *gin.Context) { c.Next() } }
+
+func TestInitClientIP(t *testing.T) {
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		remoteAddr     string
+		forwardedFor   string
+		want           string
+	}{
+		{
+			name:         "no proxies trusted ignores forwarded header",
+			remoteAddr:   "203.0.113.7:5000",
+			forwardedFor: "198.51.100.1",
+			want:         "203.0.113.7",
+		},
+		{
+			name:           "untrusted peer cannot spoof",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "203.0.113.7:5000",
+			forwardedFor:   "198.51.100.1",
+			want:           "203.0.113.7",
+		},
+		{
+			name:           "trusted proxy reports the client",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "10.1.2.3:5000",
+			forwardedFor:   "198.51.100.1",
+			want:           "198.51.100.1",
+		},
+		{
+			name:           "client supplied entries before the proxy are skipped",
+			trustedProxies: []string{"10.1.2.3"},
+			remoteAddr:     "10.1.2.3:5000",
+			forwardedFor:   "192.0.2.9, 198.51.100.1",
+			want:           "198.51.100.1",
+		},
+		{
+			name:           "invalid list trusts none",
+			trustedProxies: []string{"not-an-ip"},
+			remoteAddr:     "10.1.2.3:5000",
+			forwardedFor:   "198.51.100.1",
+			want:           "10.1.2.3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := Init(zerolog.Nop(), passMiddleware{}, Config{TrustedProxies: tt.trustedProxies})
+
+			var got string
+			router.GET("/ip", func(c *gin.Context) {
+				got = c.ClientIP()
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Forwarded-For", tt.forwardedFor)
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -5,31 +5,40 @@ import (
 	"fmt"
 	"math"
 	"math/rand/v2"
+	"strconv"
+	"sync/atomic"
 	"time"
 
+	"github.com/linggaaskaedo/go-kill/common/app"
+	"github.com/linggaaskaedo/go-kill/common/pkg/metrics"
+
 	"github.com/IBM/sarama"
 	"github.com/rs/zerolog"
 )
 
 type Config struct {
-	Brokers                        []string      `yaml:"brokers"`
-	GroupID                        string        `yaml:"group_id"`
-	Topics                         []string      `yaml:"topics"`
-	InitialOffset                  int64         `yaml:"initial_offset"`
-	DialTimeout                    time.Duration `yaml:"dial_timeout"`
-	ReadTimeout                    time.Duration `yaml:"read_timeout"`
-	WriteTimeout                   time.Duration `yaml:"write_timeout"`
-	ConsumerGroupSessionTimeout    time.Duration `yaml:"consumer_group_session_timeout"`
-	ConsumerGroupHeartbeatInterval time.Duration `yaml:"consumer_group_heartbeat_interval"`
+	Brokers                        []string      `yaml:"brokers" validate:"min=1"`
+	GroupID                        string        `yaml:"group_id" validate:"required"`
+	Topics                         []string      `yaml:"topics" validate:"min=1"`
+	InitialOffset                  int64         `yaml:"initial_offset" validate:"oneof=0 -1 -2"`
+	DialTimeout                    time.Duration `yaml:"dial_timeout" default:"30s"`
+	ReadTimeout                    time.Duration `yaml:"read_timeout" default:"30s"`
+	WriteTimeout                   time.Duration `yaml:"write_timeout" default:"30s"`
+	ConsumerGroupSessionTimeout    time.Duration `yaml:"consumer_group_session_timeout" default:"10s"`
+	ConsumerGroupHeartbeatInterval time.Duration `yaml:"consumer_group_heartbeat_interval" default:"3s"`
 }
 
 type KafkaConsumerComponent struct {
 	log     zerolog.Logger
 	cfg     Config
 	handler sarama.ConsumerGroupHandler
+	client  sarama.Client
 	group   sarama.ConsumerGroup
 	ready   chan struct{}
 	cancel  context.CancelFunc
+	// consumeErr is the error the consumer loop is backing off from, nil
+	// once a session is set up
+	consumeErr atomic.Pointer[error]
 }
 
 func NewKafkaConsumerComponent(log zerolog.Logger, cfg Config, handler sarama.ConsumerGroupHandler) *KafkaConsumerComponent {
@@ -61,10 +70,18 @@ func (k *KafkaConsumerComponent) Start(ctx context.Context) error {
 	config.Consumer.Group.Session.Timeout = k.cfg.ConsumerGroupSessionTimeout
 	config.Consumer.Group.Heartbeat.Interval = k.cfg.ConsumerGroupHeartbeatInterval
 
-	group, err := sarama.NewConsumerGroup(k.cfg.Brokers, k.cfg.GroupID, config)
+	// The group is built on a client of its own, which CheckHealth uses
+	client, err := sarama.NewClient(k.cfg.Brokers, config)
+	if err != nil {
+		return fmt.Errorf("failed to create Kafka client: %w", err)
+	}
+
+	group, err := sarama.NewConsumerGroupFromClient(k.cfg.GroupID, client)
 	if err != nil {
+		client.Close()
 		return fmt.Errorf("failed to create consumer group: %w", err)
 	}
+	k.client = client
 	k.group = group
 
 	consumeCtx, cancel := context.WithCancel(context.Background())
@@ -99,7 +116,7 @@ func (k *KafkaConsumerComponent) runConsumerLoop(ctx context.Context) {
 		default:
 		}
 
-		err := k.group.Consume(ctx, k.cfg.Topics, k.handler)
+		err := k.group.Consume(ctx, k.cfg.Topics, sessionHandler{k.handler, k})
 		if err == nil {
 			// Normal exit (e.g., after rebalance) – reset backoff and continue
 			attempt = 0
@@ -109,6 +126,7 @@ func (k *KafkaConsumerComponent) runConsumerLoop(ctx context.Context) {
 
 		// Log the error
 		k.log.Error().Err(err).Msg("Kafka consumer error")
+		k.consumeErr.Store(&err)
 
 		// Check again for cancellation
 		select {
@@ -156,6 +174,12 @@ func (k *KafkaConsumerComponent) Stop(ctx context.Context) error {
 		}
 	}
 
+	if k.client != nil && !k.client.Closed() {
+		if err := k.client.Close(); err != nil {
+			return fmt.Errorf("close Kafka client: %w", err)
+		}
+	}
+
 	k.log.Debug().Msg("Kafka consumer stopped")
 
 	return nil
@@ -164,3 +188,54 @@ func (k *KafkaConsumerComponent) Stop(ctx context.Context) error {
 func (k *KafkaConsumerComponent) Ready() <-chan struct{} {
 	return k.ready
 }
+
+// CheckHealth refreshes the cluster metadata, which needs a reachable
+// broker. While the consumer loop retries after an error, with the brokers
+// reachable, the consumer is degraded.
+func (k *KafkaConsumerComponent) CheckHealth(ctx context.Context) error {
+	if k.client == nil {
+		return fmt.Errorf("consumer not started")
+	}
+
+	if k.client.Closed() {
+		return fmt.Errorf("refresh Kafka metadata: client closed")
+	}
+
+	// RefreshMetadata takes no context, so give up waiting on it instead
+	refreshed := make(chan error, 1)
+	go func() { refreshed <- k.client.RefreshMetadata() }()
+
+	select {
+	case err := <-refreshed:
+		if err != nil {
+			return fmt.Errorf("refresh Kafka metadata: %w", err)
+		}
+	case <-ctx.Done():
+		return fmt.Errorf("refresh Kafka metadata: %w", ctx.Err())
+	}
+
+	if err := k.consumeErr.Load(); err != nil {
+		return app.Degraded(fmt.Errorf("consume: %w", *err))
+	}
+
+	return nil
+}
+
+// sessionHandler clears the consumer loop's error once a session is set up,
+// and measures the claims it hands to the handler.
+type sessionHandler struct {
+	sarama.ConsumerGroupHandler
+	k *KafkaConsumerComponent
+}
+
+func (h sessionHandler) Setup(session sarama.ConsumerGroupSession) error {
+	h.k.consumeErr.Store(nil)
+	return h.ConsumerGroupHandler.Setup(session)
+}
+
+func (h sessionHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	// The partition may be claimed elsewhere after this session
+	defer metrics.KafkaConsumerLag.DeleteLabelValues(h.k.cfg.GroupID, claim.Topic(), strconv.Itoa(int(claim.Partition())))
+
+	return h.ConsumerGroupHandler.ConsumeClaim(session, newMeasuredClaim(session.Context(), h.k.cfg.GroupID, claim))
+}
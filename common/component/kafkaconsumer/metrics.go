@@ -0,0 +1,48 @@
+package kafkaconsumer
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/linggaaskaedo/go-kill/common/pkg/metrics"
+
+	"github.com/IBM/sarama"
+)
+
+// measuredClaim counts the claim's messages and sets the partition's lag,
+// the messages after each one received, before handing them on.
+type measuredClaim struct {
+	sarama.ConsumerGroupClaim
+	messages chan *sarama.ConsumerMessage
+}
+
+func newMeasuredClaim(ctx context.Context, group string, claim sarama.ConsumerGroupClaim) *measuredClaim {
+	c := &measuredClaim{
+		ConsumerGroupClaim: claim,
+		messages:           make(chan *sarama.ConsumerMessage),
+	}
+
+	received := metrics.KafkaConsumerMessages.WithLabelValues(group, claim.Topic())
+	lag := metrics.KafkaConsumerLag.WithLabelValues(group, claim.Topic(), strconv.Itoa(int(claim.Partition())))
+
+	go func() {
+		defer close(c.messages)
+
+		for msg := range claim.Messages() {
+			received.Inc()
+			lag.Set(float64(claim.HighWaterMarkOffset() - msg.Offset - 1))
+
+			select {
+			case c.messages <- msg:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return c
+}
+
+func (c *measuredClaim) Messages() <-chan *sarama.ConsumerMessage {
+	return c.messages
+}
@@ -0,0 +1,49 @@
+package kafkaconsumer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/linggaaskaedo/go-kill/common/pkg/metrics"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClaim is a partition whose high water mark is 10.
+type fakeClaim struct {
+	messages chan *sarama.ConsumerMessage
+}
+
+func (c *fakeClaim) Topic() string                            { return "order.created" }
+func (c *fakeClaim) Partition() int32                         { return 3 }
+func (c *fakeClaim) InitialOffset() int64                     { return 0 }
+func (c *fakeClaim) HighWaterMarkOffset() int64               { return 10 }
+func (c *fakeClaim) Messages() <-chan *sarama.ConsumerMessage { return c.messages }
+
+func TestMeasuredClaim(t *testing.T) {
+	metrics.Init("test-service")
+
+	claim := &fakeClaim{messages: make(chan *sarama.ConsumerMessage, 2)}
+	claim.messages <- &sarama.ConsumerMessage{Topic: "order.created", Offset: 6}
+	claim.messages <- &sarama.ConsumerMessage{Topic: "order.created", Offset: 7}
+	close(claim.messages)
+
+	measured := newMeasuredClaim(context.Background(), "analytics", claim)
+
+	var offsets []int64
+	for msg := range measured.Messages() {
+		offsets = append(offsets, msg.Offset)
+	}
+	assert.Equal(t, []int64{6, 7}, offsets)
+
+	w := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := w.Body.String()
+
+	assert.Contains(t, body, `kafka_consumer_messages_total{group="analytics",service="test-service",topic="order.created"} 2`)
+	// Offsets 8 and 9 are still to come
+	assert.Contains(t, body, `kafka_consumer_lag{group="analytics",partition="3",service="test-service",topic="order.created"} 2`)
+}
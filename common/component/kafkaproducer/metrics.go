@@ -0,0 +1,56 @@
+package kafkaproducer
+
+import (
+	"errors"
+	"time"
+
+	"github.com/linggaaskaedo/go-kill/common/pkg/metrics"
+
+	"github.com/IBM/sarama"
+)
+
+// measuredProducer counts the messages sent by topic and outcome and
+// records how long Kafka took to acknowledge them.
+type measuredProducer struct {
+	sarama.SyncProducer
+}
+
+func (p measuredProducer) SendMessage(msg *sarama.ProducerMessage) (int32, int64, error) {
+	start := time.Now()
+	partition, offset, err := p.SyncProducer.SendMessage(msg)
+	observeSend(msg.Topic, err == nil, time.Since(start))
+
+	return partition, offset, err
+}
+
+func (p measuredProducer) SendMessages(msgs []*sarama.ProducerMessage) error {
+	start := time.Now()
+	err := p.SyncProducer.SendMessages(msgs)
+	latency := time.Since(start)
+
+	failed := make(map[*sarama.ProducerMessage]bool)
+	var errs sarama.ProducerErrors
+	if errors.As(err, &errs) {
+		for _, e := range errs {
+			failed[e.Msg] = true
+		}
+	}
+
+	for _, msg := range msgs {
+		// An error of another kind failed the whole batch
+		ok := err == nil || (errs != nil && !failed[msg])
+		observeSend(msg.Topic, ok, latency)
+	}
+
+	return err
+}
+
+func observeSend(topic string, ok bool, latency time.Duration) {
+	status := "success"
+	if !ok {
+		status = "failure"
+	}
+
+	metrics.KafkaProducerMessages.WithLabelValues(topic, status).Inc()
+	metrics.KafkaProducerSendDuration.WithLabelValues(topic).Observe(latency.Seconds())
+}
@@ -0,0 +1,48 @@
+package kafkaproducer
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/linggaaskaedo/go-kill/common/pkg/metrics"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMeasuredProducer(t *testing.T) {
+	metrics.Init("test-service")
+
+	mock := mocks.NewSyncProducer(t, nil)
+	mock.ExpectSendMessageAndSucceed()
+	mock.ExpectSendMessageAndFail(errors.New("leader not available"))
+	mock.ExpectSendMessageAndSucceed()
+	mock.ExpectSendMessageAndSucceed()
+
+	producer := measuredProducer{mock}
+
+	_, _, err := producer.SendMessage(&sarama.ProducerMessage{Topic: "order.created", Value: sarama.StringEncoder("1")})
+	assert.NoError(t, err)
+
+	_, _, err = producer.SendMessage(&sarama.ProducerMessage{Topic: "order.created", Value: sarama.StringEncoder("2")})
+	assert.Error(t, err)
+
+	err = producer.SendMessages([]*sarama.ProducerMessage{
+		{Topic: "order.updated", Value: sarama.StringEncoder("3")},
+		{Topic: "order.updated", Value: sarama.StringEncoder("4")},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.Close())
+
+	w := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := w.Body.String()
+
+	assert.Contains(t, body, `kafka_producer_messages_total{service="test-service",status="success",topic="order.created"} 1`)
+	assert.Contains(t, body, `kafka_producer_messages_total{service="test-service",status="failure",topic="order.created"} 1`)
+	assert.Contains(t, body, `kafka_producer_messages_total{service="test-service",status="success",topic="order.updated"} 2`)
+	assert.Contains(t, body, `kafka_producer_send_duration_seconds_count{service="test-service",topic="order.created"} 2`)
+}
@@ -3,21 +3,27 @@ package kafkaproducer
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"time"
 
+	"github.com/linggaaskaedo/go-kill/common/pkg/tracing"
+	"github.com/linggaaskaedo/go-kill/common/pkg/tracing/kafkatracing"
+
 	"github.com/IBM/sarama"
 	"github.com/rs/zerolog"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
 )
 
 type Config struct {
-	Brokers  []string      `yaml:"brokers"`
+	Brokers  []string      `yaml:"brokers" validate:"min=1"`
 	RetryMax int           `yaml:"retry_max"`
-	Timeout  time.Duration `yaml:"timeout"`
+	Timeout  time.Duration `yaml:"timeout" default:"30s"`
 }
 
 type KafkaProducerComponent struct {
 	log      zerolog.Logger
 	cfg      Config
+	client   sarama.Client
 	producer sarama.SyncProducer
 	ready    chan struct{}
 }
@@ -37,13 +43,22 @@ func (k *KafkaProducerComponent) Start(ctx context.Context) error {
 	config.Producer.Retry.Max = k.cfg.RetryMax
 	config.Net.DialTimeout = k.cfg.Timeout
 
-	producer, err := sarama.NewSyncProducer(k.cfg.Brokers, config)
+	// The producer is built on a client of its own, which CheckHealth uses
+	client, err := sarama.NewClient(k.cfg.Brokers, config)
 	if err != nil {
+		k.log.Error().Err(err).Msg("Failed to create Kafka client")
+		return fmt.Errorf("failed to create Kafka client: %w", err)
+	}
+
+	producer, err := sarama.NewSyncProducerFromClient(client)
+	if err != nil {
+		client.Close()
 		k.log.Error().Err(err).Msg("Failed to create Kafka producer")
 		return fmt.Errorf("failed to create Kafka producer: %w", err)
 	}
 
-	k.producer = producer
+	k.client = client
+	k.producer = measuredProducer{producer}
 
 	close(k.ready)
 	k.log.Debug().Strs("brokers", k.cfg.Brokers).Msg("Kafka producer started")
@@ -60,11 +75,44 @@ func (k *KafkaProducerComponent) Stop(ctx context.Context) error {
 		}
 	}
 
+	if k.client != nil && !k.client.Closed() {
+		if err := k.client.Close(); err != nil {
+			return fmt.Errorf("close Kafka client: %w", err)
+		}
+	}
+
 	k.log.Debug().Msg("Kafka producer stopped")
 
 	return nil
 }
 
+// CheckHealth refreshes the cluster metadata, which needs a reachable
+// broker.
+func (k *KafkaProducerComponent) CheckHealth(ctx context.Context) error {
+	if k.client == nil {
+		return fmt.Errorf("producer not started")
+	}
+
+	if k.client.Closed() {
+		return fmt.Errorf("refresh Kafka metadata: client closed")
+	}
+
+	// RefreshMetadata takes no context, so give up waiting on it instead
+	refreshed := make(chan error, 1)
+	go func() { refreshed <- k.client.RefreshMetadata() }()
+
+	select {
+	case err := <-refreshed:
+		if err != nil {
+			return fmt.Errorf("refresh Kafka metadata: %w", err)
+		}
+	case <-ctx.Done():
+		return fmt.Errorf("refresh Kafka metadata: %w", ctx.Err())
+	}
+
+	return nil
+}
+
 func (k *KafkaProducerComponent) Ready() <-chan struct{} {
 	return k.ready
 }
@@ -73,14 +121,25 @@ func (k *KafkaProducerComponent) Producer() sarama.SyncProducer {
 	return k.producer
 }
 
-// SendMessage sends a message to the specified topic.
+// SendMessage sends a message to the specified topic, carrying the trace
+// context of ctx in its headers.
 // Safe to call only after the component is ready.
-func (k *KafkaProducerComponent) SendMessage(topic string, key, value []byte) (partition int32, offset int64, err error) {
+func (k *KafkaProducerComponent) SendMessage(ctx context.Context, topic string, key, value []byte) (partition int32, offset int64, err error) {
 	msg := &sarama.ProducerMessage{
 		Topic: topic,
 		Key:   sarama.ByteEncoder(key),
 		Value: sarama.ByteEncoder(value),
 	}
 
-	return k.producer.SendMessage(msg)
+	_, span := kafkatracing.StartSendSpan(ctx, msg)
+	partition, offset, err = k.producer.SendMessage(msg)
+	if err == nil {
+		span.SetAttributes(
+			semconv.MessagingDestinationPartitionID(strconv.Itoa(int(partition))),
+			semconv.MessagingKafkaOffset(int(offset)),
+		)
+	}
+	tracing.End(span, err)
+
+	return partition, offset, err
 }
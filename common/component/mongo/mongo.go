@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/linggaaskaedo/go-kill/common/app"
+
 	"github.com/rs/zerolog"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
@@ -12,9 +14,9 @@ import (
 )
 
 type Config struct {
-	Host       string        `yaml:"host"`
-	Port       string        `yaml:"port"`
-	Database   string        `yaml:"database"`
+	Host       string        `yaml:"host" validate:"required"`
+	Port       string        `yaml:"port" validate:"required"`
+	Database   string        `yaml:"database" validate:"required"`
 	Username   string        `yaml:"username"`
 	Password   string        `yaml:"password"`
 	AuthSource string        `yaml:"auth_source"`
@@ -52,7 +54,7 @@ func (m *MongoDBComponent) Start(ctx context.Context) error {
 	} else {
 		uri = fmt.Sprintf("mongodb://%s:%s/%s", m.cfg.Host, m.cfg.Port, m.cfg.Database)
 	}
-	clientOpts := options.Client().ApplyURI(uri)
+	clientOpts := options.Client().ApplyURI(uri).SetMonitor(newCommandMonitor(m.cfg.Host))
 
 	if m.cfg.Timeout > 0 {
 		clientOpts.SetConnectTimeout(m.cfg.Timeout)
@@ -93,6 +95,34 @@ func (m *MongoDBComponent) Stop(ctx context.Context) error {
 	return nil
 }
 
+// CheckHealth pings the primary. Without one, but with another member
+// reachable, reads still work and the component is degraded.
+func (m *MongoDBComponent) CheckHealth(ctx context.Context) error {
+	if m.client == nil {
+		return fmt.Errorf("mongo not connected")
+	}
+
+	// Selecting a missing primary waits out the whole context, so leave half
+	// of it for trying the other members.
+	primaryCtx := ctx
+	if deadline, ok := ctx.Deadline(); ok {
+		var cancel context.CancelFunc
+		primaryCtx, cancel = context.WithTimeout(ctx, time.Until(deadline)/2)
+		defer cancel()
+	}
+
+	err := m.client.Ping(primaryCtx, readpref.Primary())
+	if err == nil {
+		return nil
+	}
+
+	if m.client.Ping(ctx, readpref.Nearest()) == nil {
+		return app.Degraded(fmt.Errorf("mongo primary unreachable: %w", err))
+	}
+
+	return fmt.Errorf("mongo ping: %w", err)
+}
+
 // Database returns the MongoDB database handle for use by other components.
 // Safe to call only after Start has completed.
 func (m *MongoDBComponent) Database() *mongo.Database {
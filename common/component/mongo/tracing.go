@@ -0,0 +1,67 @@
+package mongo
+
+import (
+	"context"
+	"sync"
+
+	"github.com/linggaaskaedo/go-kill/common/pkg/tracing"
+
+	"go.mongodb.org/mongo-driver/v2/event"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracingMonitor starts a client span when a command is sent and ends it
+// when the reply or the failure arrives. Command documents are left out, as
+// they hold user data.
+type tracingMonitor struct {
+	host  string
+	spans sync.Map // request id -> trace.Span
+}
+
+func newCommandMonitor(host string) *event.CommandMonitor {
+	t := &tracingMonitor{host: host}
+
+	return &event.CommandMonitor{
+		Started:   t.started,
+		Succeeded: t.succeeded,
+		Failed:    t.failed,
+	}
+}
+
+func (t *tracingMonitor) started(ctx context.Context, evt *event.CommandStartedEvent) {
+	attrs := []attribute.KeyValue{
+		semconv.DBSystemNameMongoDB,
+		semconv.DBNamespace(evt.DatabaseName),
+		semconv.DBOperationName(evt.CommandName),
+		semconv.ServerAddress(t.host),
+	}
+
+	name := evt.CommandName
+	// The command's first element names the collection, e.g. {find: "users"}
+	if collection, ok := evt.Command.Lookup(evt.CommandName).StringValueOK(); ok {
+		attrs = append(attrs, semconv.DBCollectionName(collection))
+		name += " " + collection
+	}
+
+	_, span := tracing.Tracer().Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+	t.spans.Store(evt.RequestID, span)
+}
+
+func (t *tracingMonitor) succeeded(_ context.Context, evt *event.CommandSucceededEvent) {
+	t.finish(evt.RequestID, nil)
+}
+
+func (t *tracingMonitor) failed(_ context.Context, evt *event.CommandFailedEvent) {
+	t.finish(evt.RequestID, evt.Failure)
+}
+
+func (t *tracingMonitor) finish(requestID int64, err error) {
+	if span, ok := t.spans.LoadAndDelete(requestID); ok {
+		tracing.End(span.(trace.Span), err)
+	}
+}
@@ -15,7 +15,7 @@ import (
 )
 
 type Config struct {
-	Path string `yaml:"path"`
+	Path string `yaml:"path" validate:"required"`
 }
 
 type QueryComponent struct {
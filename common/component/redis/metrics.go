@@ -0,0 +1,66 @@
+package redis
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+)
+
+// poolCollector exports the client's connection pool stats, like the
+// go_sql_* collector does for the database pool.
+type poolCollector struct {
+	client *redis.Client
+
+	hits         *prometheus.Desc
+	misses       *prometheus.Desc
+	timeouts     *prometheus.Desc
+	waitCount    *prometheus.Desc
+	waitDuration *prometheus.Desc
+	totalConns   *prometheus.Desc
+	idleConns    *prometheus.Desc
+	staleConns   *prometheus.Desc
+}
+
+func newPoolCollector(client *redis.Client, address string) *poolCollector {
+	labels := prometheus.Labels{"address": address}
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc("redis_pool_"+name, help, nil, labels)
+	}
+
+	return &poolCollector{
+		client:       client,
+		hits:         desc("hits_total", "The number of times a free connection was found in the pool."),
+		misses:       desc("misses_total", "The number of times a free connection was not found in the pool."),
+		timeouts:     desc("timeouts_total", "The number of times waiting for a connection timed out."),
+		waitCount:    desc("wait_count_total", "The total number of connections waited for."),
+		waitDuration: desc("wait_duration_seconds_total", "The total time blocked waiting for a connection."),
+		totalConns:   desc("connections", "The number of connections in the pool."),
+		idleConns:    desc("idle_connections", "The number of idle connections in the pool."),
+		staleConns:   desc("stale_connections_total", "The total number of stale connections removed from the pool."),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.timeouts
+	ch <- c.waitCount
+	ch <- c.waitDuration
+	ch <- c.totalConns
+	ch <- c.idleConns
+	ch <- c.staleConns
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.client.PoolStats()
+
+	ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(c.timeouts, prometheus.CounterValue, float64(stats.Timeouts))
+	ch <- prometheus.MustNewConstMetric(c.waitCount, prometheus.CounterValue, float64(stats.WaitCount))
+	ch <- prometheus.MustNewConstMetric(c.waitDuration, prometheus.CounterValue, time.Duration(stats.WaitDurationNs).Seconds())
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(stats.TotalConns))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(stats.IdleConns))
+	ch <- prometheus.MustNewConstMetric(c.staleConns, prometheus.CounterValue, float64(stats.StaleConns))
+}
@@ -0,0 +1,47 @@
+package redis
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/linggaaskaedo/go-kill/common/pkg/metrics"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoolCollector(t *testing.T) {
+	metrics.Init("test-service")
+
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6399"})
+	t.Cleanup(func() { _ = client.Close() })
+
+	stats := newPoolCollector(client, "localhost:6399")
+	require.NoError(t, metrics.Register(stats))
+
+	scrape := func() string {
+		w := httptest.NewRecorder()
+		metrics.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		return w.Body.String()
+	}
+
+	body := scrape()
+	for _, series := range []string{
+		`redis_pool_hits_total{address="localhost:6399",service="test-service"} 0`,
+		`redis_pool_misses_total{address="localhost:6399",service="test-service"} 0`,
+		`redis_pool_timeouts_total{address="localhost:6399",service="test-service"} 0`,
+		`redis_pool_wait_count_total{address="localhost:6399",service="test-service"} 0`,
+		`redis_pool_wait_duration_seconds_total{address="localhost:6399",service="test-service"} 0`,
+		`redis_pool_connections{address="localhost:6399",service="test-service"} 0`,
+		`redis_pool_idle_connections{address="localhost:6399",service="test-service"} 0`,
+		`redis_pool_stale_connections_total{address="localhost:6399",service="test-service"} 0`,
+	} {
+		assert.Contains(t, body, series)
+	}
+
+	// Stop unregisters the collector, so a restarted component can add it again
+	assert.True(t, metrics.Unregister(stats))
+	assert.NotContains(t, scrape(), "redis_pool_")
+}
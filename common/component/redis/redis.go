@@ -5,14 +5,16 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/linggaaskaedo/go-kill/common/pkg/metrics"
+
 	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
 )
 
 type Config struct {
 	Enabled         bool          `yaml:"enabled"`
-	Network         string        `yaml:"network"`
-	Address         string        `yaml:"address"`
+	Network         string        `yaml:"network" default:"tcp"`
+	Address         string        `yaml:"address" validate:"required_if=Enabled true"`
 	Password        string        `yaml:"password"`
 	DB              int           `yaml:"db"`
 	CacheTTL        time.Duration `yaml:"cache_ttl"`
@@ -34,44 +36,54 @@ type RedisComponent struct {
 	cfg    Config
 	ready  chan struct{}
 	client *redis.Client
+	stats  *poolCollector
 }
 
+// NewRedisComponent creates the Redis client up front so it can be handed to
+// other components before Start. The client connects on first use.
 func NewRedisComponent(log zerolog.Logger, cfg Config) *RedisComponent {
+	client := redis.NewClient(&redis.Options{
+		Network:         cfg.Network,
+		Addr:            cfg.Address,
+		Password:        cfg.Password,
+		DB:              cfg.DB,
+		MaxRetries:      cfg.MaxRetries,
+		MinRetryBackoff: cfg.MinRetryBackoff,
+		MaxRetryBackoff: cfg.MaxRetryBackoff,
+		DialTimeout:     cfg.DialTimeout,
+		ReadTimeout:     cfg.ReadTimeout,
+		WriteTimeout:    cfg.WriteTimeout,
+		PoolSize:        cfg.PoolSize,
+		MinIdleConns:    cfg.MinIdleConns,
+		MaxIdleConns:    cfg.MaxIdleConns,
+		MaxActiveConns:  cfg.MaxActiveConns,
+		PoolTimeout:     cfg.PoolTimeout,
+	})
+	client.AddHook(newTracingHook(cfg.Address, cfg.DB))
+
 	return &RedisComponent{
-		log:   log,
-		cfg:   cfg,
-		ready: make(chan struct{}),
+		log:    log,
+		cfg:    cfg,
+		ready:  make(chan struct{}),
+		client: client,
 	}
 }
 
-// Start initialises the Redis client, verifies the connection with a Ping,
-// and then blocks until the context is cancelled.
-// It returns an error if the client cannot be created or the ping fails.
+// Start verifies the connection with a Ping, and then blocks until the
+// context is cancelled. It returns an error if the ping fails.
 func (r *RedisComponent) Start(ctx context.Context) error {
-	// Create the client
-	r.client = redis.NewClient(&redis.Options{
-		Network:         r.cfg.Network,
-		Addr:            r.cfg.Address,
-		Password:        r.cfg.Password,
-		DB:              r.cfg.DB,
-		MaxRetries:      r.cfg.MaxRetries,
-		MinRetryBackoff: r.cfg.MinRetryBackoff,
-		MaxRetryBackoff: r.cfg.MaxRetryBackoff,
-		DialTimeout:     r.cfg.DialTimeout,
-		ReadTimeout:     r.cfg.ReadTimeout,
-		WriteTimeout:    r.cfg.WriteTimeout,
-		PoolSize:        r.cfg.PoolSize,
-		MinIdleConns:    r.cfg.MinIdleConns,
-		MaxIdleConns:    r.cfg.MaxIdleConns,
-		MaxActiveConns:  r.cfg.MaxActiveConns,
-		PoolTimeout:     r.cfg.PoolTimeout,
-	})
-
 	// Verify connectivity (Ping uses the provided context)
 	if err := r.client.Ping(ctx).Err(); err != nil {
 		return fmt.Errorf("redis ping failed: %w", err)
 	}
 
+	stats := newPoolCollector(r.client, r.cfg.Address)
+	if err := metrics.Register(stats); err != nil {
+		r.log.Warn().Err(err).Msg("Failed to register Redis pool metrics")
+	} else {
+		r.stats = stats
+	}
+
 	close(r.ready) // signal readiness
 	r.log.Debug().Msg("Redis component started and ping successful")
 	<-ctx.Done() // Block until shutdown signal
@@ -87,6 +99,10 @@ func (r *RedisComponent) Stop(ctx context.Context) error {
 		return nil
 	}
 
+	if r.stats != nil {
+		metrics.Unregister(r.stats)
+	}
+
 	// Close the client – it will wait for pending commands to finish.
 	if err := r.client.Close(); err != nil {
 		return fmt.Errorf("redis close error: %w", err)
@@ -97,8 +113,17 @@ func (r *RedisComponent) Stop(ctx context.Context) error {
 	return nil
 }
 
+// CheckHealth pings Redis.
+func (r *RedisComponent) CheckHealth(ctx context.Context) error {
+	if err := r.client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("redis ping: %w", err)
+	}
+
+	return nil
+}
+
 // Client returns the underlying Redis client for use by other components.
-// It is safe to call only after Start has completed successfully.
+// Commands only succeed once the component is ready.
 func (r *RedisComponent) Client() *redis.Client {
 	return r.client
 }
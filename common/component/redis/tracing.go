@@ -0,0 +1,87 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/linggaaskaedo/go-kill/common/pkg/tracing"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracingHook starts a client span for every command and pipeline. Command
+// arguments are left out, as they may hold cached user data.
+type tracingHook struct {
+	attrs []attribute.KeyValue
+}
+
+func newTracingHook(address string, db int) tracingHook {
+	return tracingHook{
+		attrs: []attribute.KeyValue{
+			semconv.DBSystemNameRedis,
+			semconv.ServerAddress(address),
+			semconv.DBNamespace(strconv.Itoa(db)),
+		},
+	}
+}
+
+func (h tracingHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		ctx, span := h.start(ctx, "redis.dial")
+		conn, err := next(ctx, network, addr)
+		tracing.End(span, err)
+
+		return conn, err
+	}
+}
+
+func (h tracingHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		ctx, span := h.start(ctx, cmd.FullName(), semconv.DBOperationName(cmd.Name()))
+		err := next(ctx, cmd)
+		tracing.End(span, commandError(err))
+
+		return err
+	}
+}
+
+func (h tracingHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		names := make([]string, len(cmds))
+		for i, cmd := range cmds {
+			names[i] = cmd.Name()
+		}
+
+		ctx, span := h.start(ctx, "pipeline",
+			semconv.DBOperationName("pipeline "+strings.Join(names, " ")),
+			semconv.DBOperationBatchSize(len(cmds)),
+		)
+		err := next(ctx, cmds)
+		tracing.End(span, commandError(err))
+
+		return err
+	}
+}
+
+func (h tracingHook) start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(h.attrs...),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// commandError drops redis.Nil, which is a cache miss rather than a failure.
+func commandError(err error) error {
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+
+	return err
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
+
+	"github.com/linggaaskaedo/go-kill/common/pkg/metrics"
 
 	"github.com/robfig/cron/v3"
 	"github.com/rs/xid"
@@ -13,7 +16,7 @@ import (
 type Config struct {
 	Enabled   bool   `yaml:"enabled"`
 	Name      string `yaml:"name"`
-	Cron      string `yaml:"cron"`
+	Cron      string `yaml:"cron" validate:"required_if=Enabled true"`
 	BatchSize int    `yaml:"batch_size"`
 }
 
@@ -59,11 +62,17 @@ func (sc *SchedulerComponent) addJob(job Job) error {
 		logWithReq.Info().Str("job", job.Name()).Msg("Job started")
 		ctx := logWithReq.WithContext(context.Background())
 
-		if err := job.Run(ctx); err != nil {
+		start := time.Now()
+		err := job.Run(ctx)
+		metrics.SchedulerJobDuration.WithLabelValues(job.Name()).Observe(time.Since(start).Seconds())
+
+		if err != nil {
+			metrics.SchedulerJobRuns.WithLabelValues(job.Name(), "failure").Inc()
 			logWithReq.Error().Err(err).Str("job", job.Name()).Msg("Job execution failed")
 			return
 		}
 
+		metrics.SchedulerJobRuns.WithLabelValues(job.Name(), "success").Inc()
 		logWithReq.Info().Str("job", job.Name()).Msg("Job completed successfully")
 	})
 	if err != nil {
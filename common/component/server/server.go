@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/linggaaskaedo/go-kill/common/pkg/metrics"
 	"github.com/linggaaskaedo/go-kill/common/pkg/middleware"
 
 	"github.com/gin-gonic/gin"
@@ -14,13 +15,32 @@ import (
 
 type Engine = gin.Engine
 
+// HealthReporter serves the liveness and readiness probes, see app.App.
+type HealthReporter interface {
+	LivenessHandler() http.Handler
+	ReadinessHandler() http.Handler
+}
+
+// RegisterHealth serves the probes of health on /health/live and
+// /health/ready.
+func RegisterHealth(engine *gin.Engine, health HealthReporter) {
+	engine.GET("/health/live", gin.WrapH(health.LivenessHandler()))
+	engine.GET("/health/ready", gin.WrapH(health.ReadinessHandler()))
+}
+
+// RegisterMetrics serves the Prometheus metrics on /metrics, see
+// metrics.Init.
+func RegisterMetrics(engine *gin.Engine) {
+	engine.GET("/metrics", gin.WrapH(metrics.Handler()))
+}
+
 type Config struct {
 	AppName         string        `yaml:"app_name"`
-	Port            int           `yaml:"port"`
+	Port            int           `yaml:"port" validate:"required,min=1,max=65535"`
 	WriteTimeout    time.Duration `yaml:"write_timeout"`
 	ReadTimeout     time.Duration `yaml:"read_timeout"`
 	IdleTimeout     time.Duration `yaml:"idle_timeout"`
-	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" default:"10s"`
 }
 
 type HTTPServerComponent struct {
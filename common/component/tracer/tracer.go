@@ -2,89 +2,138 @@ package tracer
 
 import (
 	"context"
+	"fmt"
+	"os"
 	"time"
 
 	"github.com/rs/zerolog"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
 )
 
+const (
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterNone   = "none"
+)
+
 type Config struct {
-	ServiceName    string        `yaml:"service_name"`
+	// Exporter is otlp, stdout, which prints the spans for local runs, or
+	// none, which still gives requests trace ids for the logs.
+	Exporter       string        `yaml:"exporter" default:"none" validate:"oneof=otlp stdout none"`
+	ServiceName    string        `yaml:"service_name" validate:"required"`
 	ServiceVersion string        `yaml:"service_version"`
-	Endpoint       string        `yaml:"endpoint"`
+	Endpoint       string        `yaml:"endpoint" validate:"required_if=Exporter otlp"`
 	Insecure       bool          `yaml:"insecure"`
-	Timeout        time.Duration `yaml:"timeout"`
-}
-
-type Tracer interface {
-	Stop(ctx context.Context) error
+	Timeout        time.Duration `yaml:"timeout" default:"10s"`
+	// SampleRatio is the share of new traces recorded; 0 is taken as unset.
+	// Calls continuing a trace follow the caller's decision.
+	SampleRatio float64 `yaml:"sample_ratio" default:"1" validate:"gte=0,lte=1"`
 }
 
-type tracerImpl struct {
+// TracerComponent installs the global tracer provider and the W3C trace
+// context propagator. Add it first so that it stops last, flushing the
+// spans of the other components' shutdown.
+type TracerComponent struct {
 	log      zerolog.Logger
+	cfg      Config
+	ready    chan struct{}
 	provider *sdktrace.TracerProvider
 }
 
-func Init(log zerolog.Logger, cfg Config) Tracer {
-	ctx := context.Background()
+func NewTracerComponent(log zerolog.Logger, cfg Config) *TracerComponent {
+	return &TracerComponent{
+		log:   log,
+		cfg:   cfg,
+		ready: make(chan struct{}),
+	}
+}
 
+func (t *TracerComponent) Start(ctx context.Context) error {
 	res, err := resource.New(ctx,
 		resource.WithAttributes(
-			semconv.ServiceName(cfg.ServiceName),
-			semconv.ServiceVersion(cfg.ServiceVersion),
+			semconv.ServiceName(t.cfg.ServiceName),
+			semconv.ServiceVersion(t.cfg.ServiceVersion),
 		),
 	)
 	if err != nil {
-		log.Panic().Err(err).Msg("Failed to create resource for tracer")
-		return nil
+		return fmt.Errorf("create tracer resource: %w", err)
 	}
 
-	exporter, err := otlptracegrpc.New(ctx,
-		otlptracegrpc.WithEndpoint(cfg.Endpoint),
-		otlptracegrpc.WithInsecure(),
-		otlptracegrpc.WithTimeout(cfg.Timeout),
-	)
-	if err != nil {
-		log.Panic().Err(err).Msg("Failed to create OTLP exporter for tracer")
-		return nil
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(t.cfg.SampleRatio))),
 	}
 
-	tp := sdktrace.NewTracerProvider(
-		sdktrace.WithBatcher(exporter,
+	exporter, err := t.newExporter(ctx)
+	if err != nil {
+		return fmt.Errorf("create %s exporter: %w", t.cfg.Exporter, err)
+	}
+	if exporter != nil {
+		opts = append(opts, sdktrace.WithBatcher(exporter,
 			sdktrace.WithBatchTimeout(5*time.Second),
 			sdktrace.WithMaxExportBatchSize(512),
-		),
-		sdktrace.WithResource(res),
-		sdktrace.WithSampler(sdktrace.AlwaysSample()),
-	)
+		))
+	}
+
+	t.provider = sdktrace.NewTracerProvider(opts...)
 
-	otel.SetTracerProvider(tp)
+	otel.SetTracerProvider(t.provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
 
-	log.Print("Tracer initialized successfully")
+	close(t.ready)
+	t.log.Debug().
+		Str("exporter", t.cfg.Exporter).
+		Float64("sample_ratio", t.cfg.SampleRatio).
+		Msg("Tracer started")
+	<-ctx.Done()
 
-	return &tracerImpl{
-		log:      log,
-		provider: tp,
+	return nil
+}
+
+func (t *TracerComponent) newExporter(ctx context.Context) (sdktrace.SpanExporter, error) {
+	switch t.cfg.Exporter {
+	case ExporterOTLP:
+		opts := []otlptracegrpc.Option{
+			otlptracegrpc.WithEndpoint(t.cfg.Endpoint),
+			otlptracegrpc.WithTimeout(t.cfg.Timeout),
+		}
+		if t.cfg.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		return otlptracegrpc.New(ctx, opts...)
+
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+
+	default:
+		return nil, nil
 	}
 }
 
-func (t *tracerImpl) Stop(ctx context.Context) error {
+// Stop flushes the spans not exported yet and shuts the provider down.
+func (t *TracerComponent) Stop(ctx context.Context) error {
 	if t.provider == nil {
-		t.log.Print("Tracer provider is nil, nothing to shut down...")
 		return nil
 	}
 
-	t.log.Print("Shutting down tracer...")
 	if err := t.provider.Shutdown(ctx); err != nil {
-		t.log.Printf("Error shutting down tracer: %v", err)
-		return err
+		return fmt.Errorf("shut down tracer: %w", err)
 	}
 
-	t.log.Print("Tracer shutdown complete...")
+	t.log.Debug().Msg("Tracer stopped")
 
 	return nil
 }
+
+func (t *TracerComponent) Ready() <-chan struct{} {
+	return t.ready
+}
@@ -0,0 +1,163 @@
+package tracer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/linggaaskaedo/go-kill/common/pkg/config"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNewExporter(t *testing.T) {
+	tests := []struct {
+		exporter string
+		want     string
+	}{
+		{exporter: ExporterNone, want: "<nil>"},
+		{exporter: ExporterStdout, want: "*stdouttrace.Exporter"},
+		{exporter: ExporterOTLP, want: "*otlptrace.Exporter"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.exporter, func(t *testing.T) {
+			// The OTLP exporter connects lazily, so no collector is needed
+			comp := NewTracerComponent(zerolog.Nop(), Config{Exporter: tt.exporter, Endpoint: "localhost:4317", Insecure: true, Timeout: time.Second})
+
+			exporter, err := comp.newExporter(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, fmt.Sprintf("%T", exporter))
+
+			if exporter != nil {
+				require.NoError(t, exporter.Shutdown(context.Background()))
+			}
+		})
+	}
+}
+
+// startTracer runs a tracer component with cfg until the test ends.
+func startTracer(t *testing.T, cfg Config) {
+	t.Helper()
+
+	comp := NewTracerComponent(zerolog.Nop(), cfg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- comp.Start(ctx) }()
+
+	select {
+	case <-comp.Ready():
+	case err := <-done:
+		cancel()
+		t.Fatalf("tracer did not start: %v", err)
+	}
+
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, <-done)
+		require.NoError(t, comp.Stop(context.Background()))
+	})
+}
+
+func TestSampleRatio(t *testing.T) {
+	sampledParent := trace.ContextWithRemoteSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x0a},
+		SpanID:     trace.SpanID{0x0b},
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	tests := []struct {
+		name  string
+		ratio float64
+		ctx   context.Context
+		want  bool
+	}{
+		{name: "all", ratio: 1, ctx: context.Background(), want: true},
+		{name: "none", ratio: 0, ctx: context.Background(), want: false},
+		{name: "caller decides", ratio: 0, ctx: sampledParent, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			startTracer(t, Config{Exporter: ExporterNone, ServiceName: "test-service", SampleRatio: tt.ratio})
+
+			_, span := otel.Tracer("test").Start(tt.ctx, "op")
+			defer span.End()
+
+			// Every span gets ids for the logs, recorded or not
+			assert.True(t, span.SpanContext().IsValid())
+			assert.Equal(t, tt.want, span.SpanContext().IsSampled())
+		})
+	}
+}
+
+func TestConfigDefaultsAndValidation(t *testing.T) {
+	type wrapper struct {
+		Tracer Config `yaml:"tracer"`
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+		check   func(t *testing.T, cfg Config)
+	}{
+		{
+			name:    "defaults",
+			content: "tracer:\n  service_name: orders\n",
+			check: func(t *testing.T, cfg Config) {
+				assert.Equal(t, ExporterNone, cfg.Exporter)
+				assert.Equal(t, 1.0, cfg.SampleRatio)
+				assert.Equal(t, 10*time.Second, cfg.Timeout)
+			},
+		},
+		{
+			name:    "ratio",
+			content: "tracer:\n  service_name: orders\n  sample_ratio: 0.25\n",
+			check: func(t *testing.T, cfg Config) {
+				assert.Equal(t, 0.25, cfg.SampleRatio)
+			},
+		},
+		{
+			name:    "ratio out of range",
+			content: "tracer:\n  service_name: orders\n  sample_ratio: 1.5\n",
+			wantErr: "tracer.sample_ratio: must be at most 1",
+		},
+		{
+			name:    "unknown exporter",
+			content: "tracer:\n  service_name: orders\n  exporter: jaeger\n",
+			wantErr: "tracer.exporter: must be one of otlp, stdout, none",
+		},
+		{
+			name:    "otlp needs an endpoint",
+			content: "tracer:\n  service_name: orders\n  exporter: otlp\n",
+			wantErr: "tracer.endpoint: is required if exporter is otlp",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "config.yaml")
+			require.NoError(t, os.WriteFile(file, []byte(tt.content), 0o600))
+
+			var cfg wrapper
+			err := config.Load(config.Source{File: file}, &cfg)
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			tt.check(t, cfg.Tracer)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package authz
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrincipalAllows(t *testing.T) {
+	admin := &Principal{
+		UserID:      "user-1",
+		Roles:       []string{"admin"},
+		Permissions: []string{PermProductWrite, PermOrderReadAny},
+	}
+
+	tests := []struct {
+		name      string
+		principal *Principal
+		required  []string
+		want      bool
+	}{
+		{"no requirement", &Principal{UserID: "user-1"}, nil, true},
+		{"holds the permission", admin, []string{PermProductWrite}, true},
+		{"holds every permission", admin, []string{PermProductWrite, PermOrderReadAny}, true},
+		{"misses one permission", admin, []string{PermProductWrite, PermOrderStatusUpdate}, false},
+		{"role is not a permission", admin, []string{"admin"}, false},
+		{"nil principal", nil, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.principal.Allows(tt.required))
+		})
+	}
+}
+
+func TestPrincipalHasRoleAndPermission(t *testing.T) {
+	p := &Principal{Roles: []string{"admin"}, Permissions: []string{PermOrderRead}}
+
+	assert.True(t, p.HasRole("admin"))
+	assert.False(t, p.HasRole("customer"))
+	assert.True(t, p.HasPermission(PermOrderRead))
+	assert.False(t, p.HasPermission(PermOrderWrite))
+
+	var none *Principal
+	assert.False(t, none.HasRole("admin"))
+	assert.False(t, none.HasPermission(PermOrderRead))
+}
+
+func TestPrincipalContext(t *testing.T) {
+	_, ok := FromContext(context.Background())
+	assert.False(t, ok)
+
+	_, ok = FromContext(WithPrincipal(context.Background(), nil))
+	assert.False(t, ok)
+
+	want := &Principal{UserID: "user-1"}
+	got, ok := FromContext(WithPrincipal(context.Background(), want))
+	assert.True(t, ok)
+	assert.Same(t, want, got)
+}
@@ -0,0 +1,127 @@
+// Package config loads a service's configuration from layered sources into
+// its Config struct, applies defaults and validates the result.
+//
+// Sources, from lowest to highest precedence:
+//
+//  1. the YAML file, with ${VAR} expanded from the environment
+//  2. the overlay for the environment, e.g. config.production.yaml
+//  3. environment variables PREFIX_A__B, overriding key a.b
+//  4. -set a.b=value flags
+//
+// A string key whose name ends in _file, e.g. password_file, is read from
+// that file into the key without the suffix, for secrets mounted as files.
+//
+// Struct tags on the Config fields:
+//
+//	default:"10s"                  used when the field is left zero
+//	validate:"required,max=65535"  go-playground/validator rules
+//	reload:"true"                  taken from a reload, see Watcher
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+var decodePosition = regexp.MustCompile(`^\[\d+:\d+\] `)
+
+// Source says where a configuration is loaded from.
+type Source struct {
+	// File is the base YAML file.
+	File string
+	// Env selects the overlay next to File, config.<env>.yaml, if it exists.
+	// It defaults to APP_ENV.
+	Env string
+	// EnvPrefix enables overrides from environment variables; with "AUTH",
+	// AUTH_SERVER__PORT sets server.port.
+	EnvPrefix string
+	// Overrides are "a.b=value" settings, applied last.
+	Overrides []string
+}
+
+// RegisterFlags adds -config, -env and -set to fs, writing to s.
+func (s *Source) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&s.File, "config", s.File, "configuration file")
+	fs.StringVar(&s.Env, "env", s.Env, "environment whose config.<env>.yaml overlay to apply (default $APP_ENV)")
+	fs.Func("set", "override a configuration key, e.g. -set logger.level=debug (repeatable)", func(v string) error {
+		if !strings.Contains(v, "=") {
+			return fmt.Errorf("want key=value, got %q", v)
+		}
+		s.Overrides = append(s.Overrides, v)
+		return nil
+	})
+}
+
+// files returns the files the configuration is read from, the overlay only
+// if it exists.
+func (s Source) files() []string {
+	files := []string{s.File}
+
+	env := s.Env
+	if env == "" {
+		env = os.Getenv("APP_ENV")
+	}
+
+	if env != "" {
+		ext := filepath.Ext(s.File)
+		overlay := strings.TrimSuffix(s.File, ext) + "." + env + ext
+		if _, err := os.Stat(overlay); err == nil {
+			files = append(files, overlay)
+		}
+	}
+
+	return files
+}
+
+// Load reads the configuration described by src into dst, a pointer to a
+// struct. It fails with a *ValidationError listing every problem found.
+func Load(src Source, dst any) error {
+	tree := map[string]any{}
+
+	for _, file := range src.files() {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		var layer map[string]any
+		if err := yaml.Unmarshal([]byte(os.ExpandEnv(string(data))), &layer); err != nil {
+			return fmt.Errorf("parse %s: %w", file, err)
+		}
+
+		merge(tree, layer)
+	}
+
+	var problems []string
+
+	if src.EnvPrefix != "" {
+		applyEnv(tree, typeOf(dst), src.EnvPrefix, os.Environ())
+	}
+	problems = append(problems, applyOverrides(tree, typeOf(dst), src.Overrides)...)
+	problems = append(problems, resolveSecrets(tree, typeOf(dst), "")...)
+
+	data, err := yaml.Marshal(tree)
+	if err != nil {
+		return err
+	}
+
+	if err := yaml.Unmarshal(data, dst); err != nil {
+		// Positions refer to the merged document, not to any file
+		problems = append(problems, decodePosition.ReplaceAllString(yaml.FormatError(err, false, false), ""))
+	} else {
+		problems = append(problems, applyDefaults(dst)...)
+		problems = append(problems, validate(dst)...)
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+
+	return nil
+}
@@ -0,0 +1,331 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testDatabase struct {
+	Host     string `yaml:"host" validate:"required"`
+	Password string `yaml:"password"`
+	MaxConns int    `yaml:"max_conns" default:"10" validate:"min=1,max=100"`
+}
+
+type testConfig struct {
+	Name   string `yaml:"name" validate:"required"`
+	Server struct {
+		Port    int           `yaml:"port" validate:"required,min=1,max=65535"`
+		Timeout time.Duration `yaml:"timeout" default:"10s"`
+	} `yaml:"server"`
+	Database  testDatabase            `yaml:"database"`
+	Databases map[string]testDatabase `yaml:"databases" validate:"dive"`
+	Logger    struct {
+		Level string `yaml:"level" default:"info" reload:"true" validate:"oneof=debug info warn error"`
+	} `yaml:"logger"`
+}
+
+const baseConfig = `
+name: orders
+server:
+  port: 8080
+database:
+  host: db.local
+`
+
+// writeFile writes content to name in dir and returns its path.
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func load(t *testing.T, src Source) (*testConfig, error) {
+	t.Helper()
+
+	cfg := &testConfig{}
+	err := Load(src, cfg)
+
+	return cfg, err
+}
+
+func problems(t *testing.T, err error) []string {
+	t.Helper()
+
+	var verr *ValidationError
+	require.True(t, errors.As(err, &verr), "want a *ValidationError, got %v", err)
+
+	return verr.Problems
+}
+
+func TestLoadDefaults(t *testing.T) {
+	file := writeFile(t, t.TempDir(), "config.yaml", baseConfig+`
+databases:
+  primary:
+    host: primary.local
+  replica:
+    host: replica.local
+    max_conns: 5
+`)
+
+	cfg, err := load(t, Source{File: file})
+	require.NoError(t, err)
+
+	assert.Equal(t, 10*time.Second, cfg.Server.Timeout)
+	assert.Equal(t, "info", cfg.Logger.Level)
+	assert.Equal(t, 10, cfg.Database.MaxConns)
+
+	// Structs held by maps get defaults too, without overriding set values
+	assert.Equal(t, 10, cfg.Databases["primary"].MaxConns)
+	assert.Equal(t, 5, cfg.Databases["replica"].MaxConns)
+}
+
+func TestLoadValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "required",
+			content: "server:\n  port: 8080\ndatabase:\n  host: db.local\n",
+			want:    []string{"name: is required"},
+		},
+		{
+			name:    "range",
+			content: "name: orders\nserver:\n  port: 70000\ndatabase:\n  host: db.local\n  max_conns: 500\n",
+			want: []string{
+				"server.port: must be at most 65535, got 70000",
+				"database.max_conns: must be at most 100, got 500",
+			},
+		},
+		{
+			name:    "oneof",
+			content: baseConfig + "logger:\n  level: loud\n",
+			want:    []string{`logger.level: must be one of debug, info, warn, error, got "loud"`},
+		},
+		{
+			name:    "nested in a map",
+			content: baseConfig + "databases:\n  replica:\n    max_conns: 5\n",
+			want:    []string{"databases[replica].host: is required"},
+		},
+		{
+			name:    "every problem at once",
+			content: "server:\n  port: 0\n",
+			want:    []string{"name: is required", "server.port: is required", "database.host: is required"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := load(t, Source{File: writeFile(t, t.TempDir(), "config.yaml", tt.content)})
+			require.Error(t, err)
+
+			assert.ElementsMatch(t, tt.want, problems(t, err))
+			for _, want := range tt.want {
+				assert.Contains(t, err.Error(), want)
+			}
+		})
+	}
+}
+
+func TestLoadTypeError(t *testing.T) {
+	_, err := load(t, Source{File: writeFile(t, t.TempDir(), "config.yaml", baseConfig+"logger: [not, a, mapping]\n")})
+	require.Error(t, err)
+
+	got := problems(t, err)
+	require.Len(t, got, 1)
+	assert.NotRegexp(t, `^\[\d+:\d+\]`, got[0])
+}
+
+func TestLoadInvalidDefault(t *testing.T) {
+	type badDefault struct {
+		Timeout time.Duration `yaml:"timeout" default:"soon"`
+	}
+
+	file := writeFile(t, t.TempDir(), "config.yaml", "{}\n")
+	err := Load(Source{File: file}, &badDefault{})
+	require.Error(t, err)
+
+	assert.Contains(t, problems(t, err)[0], `timeout: invalid default "soon"`)
+}
+
+func TestLoadSecretFiles(t *testing.T) {
+	dir := t.TempDir()
+	secret := writeFile(t, dir, "db-password", "s3cret\n")
+
+	file := writeFile(t, dir, "config.yaml", baseConfig+`
+databases:
+  primary:
+    host: primary.local
+    password_file: `+secret+`
+`)
+
+	t.Setenv("TEST_DATABASE__PASSWORD_FILE", secret)
+
+	cfg, err := load(t, Source{File: file, EnvPrefix: "TEST"})
+	require.NoError(t, err)
+
+	// Trailing newlines are trimmed, and the reference works from env too
+	assert.Equal(t, "s3cret", cfg.Databases["primary"].Password)
+	assert.Equal(t, "s3cret", cfg.Database.Password)
+}
+
+func TestLoadMissingSecretFile(t *testing.T) {
+	file := writeFile(t, t.TempDir(), "config.yaml", baseConfig+"  password_file: /nonexistent/secret\n")
+
+	_, err := load(t, Source{File: file})
+	require.Error(t, err)
+
+	got := problems(t, err)
+	require.Len(t, got, 1)
+	assert.Contains(t, got[0], "database.password_file: read secret:")
+	assert.NotContains(t, err.Error(), "s3cret")
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	file := writeFile(t, dir, "config.yaml", `
+name: ${TEST_SERVICE_NAME}
+server:
+  port: 8080
+  timeout: 5s
+database:
+  host: db.local
+logger:
+  level: info
+`)
+	writeFile(t, dir, "config.staging.yaml", "server:\n  port: 8081\n  timeout: 6s\nlogger:\n  level: warn\n")
+
+	t.Setenv("TEST_SERVICE_NAME", "orders")
+	t.Setenv("TEST_SERVER__PORT", "8082")
+	t.Setenv("TEST_UNKNOWN__KEY", "ignored")
+
+	tests := []struct {
+		name        string
+		src         Source
+		wantPort    int
+		wantTimeout time.Duration
+		wantLevel   string
+	}{
+		{
+			name:        "file",
+			src:         Source{File: file},
+			wantPort:    8080,
+			wantTimeout: 5 * time.Second,
+			wantLevel:   "info",
+		},
+		{
+			name:        "overlay over file",
+			src:         Source{File: file, Env: "staging"},
+			wantPort:    8081,
+			wantTimeout: 6 * time.Second,
+			wantLevel:   "warn",
+		},
+		{
+			name:        "env over overlay",
+			src:         Source{File: file, Env: "staging", EnvPrefix: "TEST"},
+			wantPort:    8082,
+			wantTimeout: 6 * time.Second,
+			wantLevel:   "warn",
+		},
+		{
+			name:        "flags over env",
+			src:         Source{File: file, Env: "staging", EnvPrefix: "TEST", Overrides: []string{"server.port=8083", "server.timeout=7s", "logger.level=debug"}},
+			wantPort:    8083,
+			wantTimeout: 7 * time.Second,
+			wantLevel:   "debug",
+		},
+		{
+			name:        "missing overlay is skipped",
+			src:         Source{File: file, Env: "production"},
+			wantPort:    8080,
+			wantTimeout: 5 * time.Second,
+			wantLevel:   "info",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := load(t, tt.src)
+			require.NoError(t, err)
+
+			assert.Equal(t, "orders", cfg.Name)
+			assert.Equal(t, tt.wantPort, cfg.Server.Port)
+			assert.Equal(t, tt.wantTimeout, cfg.Server.Timeout)
+			assert.Equal(t, tt.wantLevel, cfg.Logger.Level)
+		})
+	}
+}
+
+func TestLoadOverlayFromAppEnv(t *testing.T) {
+	dir := t.TempDir()
+	file := writeFile(t, dir, "config.yaml", baseConfig)
+	writeFile(t, dir, "config.staging.yaml", "server:\n  port: 8081\n")
+
+	t.Setenv("APP_ENV", "staging")
+
+	cfg, err := load(t, Source{File: file})
+	require.NoError(t, err)
+	assert.Equal(t, 8081, cfg.Server.Port)
+}
+
+func TestLoadEnvMapKeys(t *testing.T) {
+	file := writeFile(t, t.TempDir(), "config.yaml", baseConfig+"databases:\n  db-0:\n    host: a.local\n")
+
+	t.Setenv("TEST_DATABASES__DB_0__HOST", "b.local")
+	t.Setenv("TEST_DATABASES__DB_1__HOST", "c.local")
+
+	cfg, err := load(t, Source{File: file, EnvPrefix: "TEST"})
+	require.NoError(t, err)
+
+	// An existing key is matched with _ for -, a new one is added as written
+	assert.Equal(t, "b.local", cfg.Databases["db-0"].Host)
+	assert.Equal(t, "c.local", cfg.Databases["db_1"].Host)
+}
+
+func TestLoadUnknownOverride(t *testing.T) {
+	file := writeFile(t, t.TempDir(), "config.yaml", baseConfig)
+
+	_, err := load(t, Source{File: file, Overrides: []string{"server.prot=9000"}})
+	require.Error(t, err)
+
+	assert.Equal(t, []string{"server.prot: unknown key"}, problems(t, err))
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	_, err := load(t, Source{File: filepath.Join(t.TempDir(), "config.yaml")})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+}
+
+func TestRegisterFlags(t *testing.T) {
+	src := Source{File: "config.yaml"}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	src.RegisterFlags(fs)
+
+	require.NoError(t, fs.Parse([]string{"-config", "/etc/orders.yaml", "-env", "production", "-set", "server.port=9000", "-set", "logger.level=debug"}))
+
+	assert.Equal(t, "/etc/orders.yaml", src.File)
+	assert.Equal(t, "production", src.Env)
+	assert.Equal(t, []string{"server.port=9000", "logger.level=debug"}, src.Overrides)
+
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(nopWriter{})
+	src.RegisterFlags(fs)
+	assert.Error(t, fs.Parse([]string{"-set", "server.port"}))
+}
+
+type nopWriter struct{}
+
+func (nopWriter) Write(p []byte) (int, error) { return len(p), nil }
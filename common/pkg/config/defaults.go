@@ -0,0 +1,64 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/goccy/go-yaml"
+)
+
+// applyDefaults sets the fields of dst left zero that have a default tag,
+// in nested structs and in structs held by maps too.
+func applyDefaults(dst any) []string {
+	return defaults(reflect.ValueOf(dst).Elem(), "")
+}
+
+func defaults(v reflect.Value, path string) []string {
+	var problems []string
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			problems = append(problems, defaults(v.Elem(), path)...)
+		}
+
+	case reflect.Struct:
+		t := v.Type()
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+
+			field := v.Field(i)
+			fieldPath := join(path, yamlName(f))
+
+			if def, ok := f.Tag.Lookup("default"); ok && field.IsZero() {
+				value := reflect.New(f.Type)
+				if err := yaml.Unmarshal([]byte(def), value.Interface()); err != nil {
+					problems = append(problems, fmt.Sprintf("%s: invalid default %q: %v", fieldPath, def, err))
+					continue
+				}
+				field.Set(value.Elem())
+			}
+
+			problems = append(problems, defaults(field, fieldPath)...)
+		}
+
+	case reflect.Map:
+		// Map values are not addressable, so default a copy and store it back
+		for _, key := range v.MapKeys() {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(v.MapIndex(key))
+			problems = append(problems, defaults(elem, fmt.Sprintf("%s[%v]", path, key))...)
+			v.SetMapIndex(key, elem)
+		}
+
+	case reflect.Slice:
+		for i := range v.Len() {
+			problems = append(problems, defaults(v.Index(i), fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	}
+
+	return problems
+}
@@ -0,0 +1,235 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// merge copies layer into tree, merging nested mappings key by key.
+func merge(tree, layer map[string]any) {
+	for key, value := range layer {
+		if sub, ok := value.(map[string]any); ok {
+			if existing, ok := tree[key].(map[string]any); ok {
+				merge(existing, sub)
+				continue
+			}
+		}
+
+		tree[key] = value
+	}
+}
+
+// applyEnv applies the variables prefix_A__B=value of environ to key a.b.
+// Segments match keys case-insensitively, with _ standing for - too, so
+// AUTH_DATABASE__DB_0__HOST sets database.db-0.host. Variables naming no
+// key of t are ignored.
+func applyEnv(tree map[string]any, t reflect.Type, prefix string, environ []string) {
+	prefix += "_"
+
+	for _, kv := range environ {
+		name, value, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(name, prefix) || len(name) == len(prefix) {
+			continue
+		}
+
+		path := strings.Split(strings.ToLower(name[len(prefix):]), "__")
+		setPath(tree, t, path, value, func(key, segment string) bool {
+			return strings.EqualFold(strings.NewReplacer("-", "_", ".", "_").Replace(key), segment)
+		})
+	}
+}
+
+// applyOverrides applies "a.b=value" overrides.
+func applyOverrides(tree map[string]any, t reflect.Type, overrides []string) []string {
+	var problems []string
+
+	for _, override := range overrides {
+		key, value, _ := strings.Cut(override, "=")
+		if !setPath(tree, t, strings.Split(key, "."), value, exactKey) {
+			problems = append(problems, fmt.Sprintf("%s: unknown key", key))
+		}
+	}
+
+	return problems
+}
+
+// setPath sets the key at path to value, creating mappings on the way. It
+// returns false, changing nothing, if t has no such key.
+func setPath(tree map[string]any, t reflect.Type, path []string, value string, match func(key, segment string) bool) bool {
+	keys := make([]string, len(path))
+	for i, segment := range path {
+		ft, name, ok := fieldType(t, segment, match)
+		if !ok {
+			// A secret reference names no field of its own
+			base, isRef := strings.CutSuffix(segment, "_file")
+			if i < len(path)-1 || !isRef || !isSecretRef(t, segment, base) {
+				return false
+			}
+			ft, name = reflect.TypeOf(""), segment
+		}
+		keys[i] = name
+		t = ft
+	}
+
+	node := tree
+	for i, segment := range path {
+		key := keys[i]
+		// Map keys are free-form; reuse one written differently in the file
+		for existing := range node {
+			if match(existing, segment) {
+				key = existing
+				break
+			}
+		}
+
+		if i == len(path)-1 {
+			node[key] = parseValue(value, t)
+			break
+		}
+
+		next, ok := node[key].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			node[key] = next
+		}
+		node = next
+	}
+
+	return true
+}
+
+// resolveSecrets replaces each key k_file of tree with k, read from the file
+// it names, where t has a string field k and no field k_file.
+func resolveSecrets(tree map[string]any, t reflect.Type, path string) []string {
+	var problems []string
+
+	for key, value := range tree {
+		base, isRef := strings.CutSuffix(key, "_file")
+		if isRef && isSecretRef(t, key, base) {
+			file, _ := value.(string)
+			delete(tree, key)
+			if file == "" {
+				continue
+			}
+
+			secret, err := os.ReadFile(file)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s: read secret: %v", childPath(t, path, key), err))
+				continue
+			}
+
+			tree[base] = strings.TrimRight(string(secret), "\r\n")
+			continue
+		}
+
+		sub, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		if ft, _, ok := fieldType(t, key, exactKey); ok {
+			problems = append(problems, resolveSecrets(sub, ft, childPath(t, path, key))...)
+		}
+	}
+
+	return problems
+}
+
+func isSecretRef(t reflect.Type, key, base string) bool {
+	if _, _, ok := fieldType(t, key, exactKey); ok {
+		return false
+	}
+
+	ft, _, ok := fieldType(t, base, exactKey)
+
+	return ok && ft.Kind() == reflect.String
+}
+
+// fieldType returns the type of the value under key in a value of type t,
+// and the key's name: a field's yaml name, or key itself in a map.
+func fieldType(t reflect.Type, key string, match func(key, segment string) bool) (reflect.Type, string, bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Map:
+		return t.Elem(), key, true
+	case reflect.Struct:
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if name := yamlName(f); name != "" && match(name, key) {
+				return f.Type, name, true
+			}
+		}
+	}
+
+	return nil, "", false
+}
+
+func exactKey(key, segment string) bool { return key == segment }
+
+// yamlName returns the key of a struct field, "" for unexported fields and
+// ones without a yaml tag.
+func yamlName(f reflect.StructField) string {
+	if !f.IsExported() {
+		return ""
+	}
+
+	name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+	if name == "-" {
+		return ""
+	}
+
+	return name
+}
+
+// parseValue reads an override of a value of type t. Strings are taken
+// as they are; anything else is read as YAML, so "10s", "true" and "[a, b]"
+// get their types.
+func parseValue(s string, t reflect.Type) any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if s == "" || t.Kind() == reflect.String {
+		return s
+	}
+
+	var v any
+	if err := yaml.Unmarshal([]byte(s), &v); err != nil || v == nil {
+		return s
+	}
+
+	return v
+}
+
+func typeOf(dst any) reflect.Type {
+	return reflect.TypeOf(dst).Elem()
+}
+
+// childPath returns the path of key in a value of type t at path: a[key]
+// in maps and a.key elsewhere, as validation errors name them.
+func childPath(t reflect.Type, path, key string) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Kind() == reflect.Map {
+		return fmt.Sprintf("%s[%s]", path, key)
+	}
+
+	return join(path, key)
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
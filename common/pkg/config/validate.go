@@ -0,0 +1,137 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// ValidationError lists every problem found in a configuration.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid configuration:\n  %s", strings.Join(e.Problems, "\n  "))
+}
+
+var (
+	validatorOnce sync.Once
+	validatorInst *validator.Validate
+)
+
+func getValidator() *validator.Validate {
+	validatorOnce.Do(func() {
+		validatorInst = validator.New(validator.WithRequiredStructEnabled())
+		// Name fields by their yaml keys in errors
+		validatorInst.RegisterTagNameFunc(func(f reflect.StructField) string {
+			return yamlName(f)
+		})
+	})
+
+	return validatorInst
+}
+
+func validate(dst any) []string {
+	err := getValidator().Struct(dst)
+	if err == nil {
+		return nil
+	}
+
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return []string{err.Error()}
+	}
+
+	problems := make([]string, len(fieldErrs))
+	for i, fe := range fieldErrs {
+		// Drop the name of the root struct from the namespace
+		_, path, _ := strings.Cut(fe.Namespace(), ".")
+		problems[i] = path + ": " + describe(fe)
+	}
+
+	return problems
+}
+
+// describe says what is wrong with the field, without its value where that
+// could be a secret.
+func describe(fe validator.FieldError) string {
+	param := fe.Param()
+
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "required_if", "required_unless":
+		return fmt.Sprintf("is required %s %s", strings.TrimPrefix(fe.Tag(), "required_"), describeCondition(param))
+	case "min", "gte":
+		return fmt.Sprintf("must be at least %s%s, got %v", param, unit(fe), size(fe))
+	case "max", "lte":
+		return fmt.Sprintf("must be at most %s%s, got %v", param, unit(fe), size(fe))
+	case "gt":
+		return fmt.Sprintf("must be greater than %s%s, got %v", param, unit(fe), size(fe))
+	case "lt":
+		return fmt.Sprintf("must be less than %s%s, got %v", param, unit(fe), size(fe))
+	case "oneof":
+		return fmt.Sprintf("must be one of %s, got %q", strings.Join(strings.Fields(param), ", "), fmt.Sprint(fe.Value()))
+	default:
+		if param != "" {
+			return fmt.Sprintf("fails %s=%s", fe.Tag(), param)
+		}
+		return "fails " + fe.Tag()
+	}
+}
+
+// describeCondition turns "Enabled true" into "enabled is true".
+func describeCondition(param string) string {
+	fields := strings.Fields(param)
+
+	conds := make([]string, 0, len(fields)/2)
+	for i := 0; i+1 < len(fields); i += 2 {
+		conds = append(conds, fmt.Sprintf("%s is %s", snakeCase(fields[i]), fields[i+1]))
+	}
+
+	return strings.Join(conds, " and ")
+}
+
+// unit names what a bound counts for strings, slices and maps.
+func unit(fe validator.FieldError) string {
+	switch fe.Kind() {
+	case reflect.String:
+		return " characters"
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return " entries"
+	default:
+		return ""
+	}
+}
+
+// size is the field's value for numbers and its length otherwise.
+func size(fe validator.FieldError) any {
+	v := reflect.ValueOf(fe.Value())
+
+	switch fe.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len()
+	default:
+		return fe.Value()
+	}
+}
+
+// snakeCase turns a Go field name such as MaxOpenConns into max_open_conns,
+// the key the repo's yaml tags give it.
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if i > 0 && unicode.IsUpper(r) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+
+	return b.String()
+}
@@ -0,0 +1,212 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// watchInterval is how often the configuration files are checked for
+// changes.
+const watchInterval = 5 * time.Second
+
+// Watcher reloads the configuration when one of its files changes or the
+// process gets SIGHUP, and hands it to the subscribers. Only fields tagged
+// reload:"true" are taken from a reload, since the rest was used to build
+// components that are running already; other changes are logged as needing
+// a restart. A configuration that fails to load is logged and ignored.
+type Watcher[T any] struct {
+	log   zerolog.Logger
+	src   Source
+	ready chan struct{}
+
+	mu          sync.Mutex
+	current     *T
+	modTimes    map[string]time.Time
+	subscribers []func(*T)
+}
+
+// NewWatcher watches the configuration loaded from src, starting at cfg.
+func NewWatcher[T any](log zerolog.Logger, src Source, cfg *T) *Watcher[T] {
+	return &Watcher[T]{
+		log:      log,
+		src:      src,
+		ready:    make(chan struct{}),
+		current:  cfg,
+		modTimes: modTimes(src.files()),
+	}
+}
+
+// Subscribe calls fn with the configuration after each reload that changed
+// a reloadable field. fn must not modify it.
+func (w *Watcher[T]) Subscribe(fn func(cfg *T)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.subscribers = append(w.subscribers, fn)
+}
+
+// Current returns the configuration in effect.
+func (w *Watcher[T]) Current() *T {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.current
+}
+
+func (w *Watcher[T]) Start(ctx context.Context) error {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+
+	close(w.ready)
+	w.log.Debug().Str("file", w.src.File).Msg("Config watcher started")
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-hup:
+			w.log.Info().Msg("SIGHUP received, reloading configuration")
+			w.reload()
+		case <-ticker.C:
+			if w.filesChanged() {
+				w.log.Info().Msg("Configuration file changed, reloading")
+				w.reload()
+			}
+		}
+	}
+}
+
+func (w *Watcher[T]) Stop(ctx context.Context) error {
+	return nil
+}
+
+func (w *Watcher[T]) Ready() <-chan struct{} {
+	return w.ready
+}
+
+// Reload loads the configuration again and applies its reloadable fields.
+func (w *Watcher[T]) Reload() error {
+	next := new(T)
+	if err := Load(w.src, next); err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	applied := new(T)
+	*applied = *w.current
+	var changed, restart []string
+	reloadFields(reflect.ValueOf(applied).Elem(), reflect.ValueOf(next).Elem(), "", false, &changed, &restart)
+	if len(changed) > 0 {
+		w.current = applied
+	}
+	subscribers := w.subscribers
+	w.mu.Unlock()
+
+	if len(restart) > 0 {
+		w.log.Warn().Strs("keys", restart).Msg("Configuration changes need a restart to take effect")
+	}
+
+	if len(changed) == 0 {
+		return nil
+	}
+
+	w.log.Info().Strs("keys", changed).Msg("Configuration reloaded")
+	for _, fn := range subscribers {
+		fn(applied)
+	}
+
+	return nil
+}
+
+func (w *Watcher[T]) reload() {
+	if err := w.Reload(); err != nil {
+		w.log.Error().Err(err).Msg("Configuration reload failed, keeping the current one")
+	}
+}
+
+func (w *Watcher[T]) filesChanged() bool {
+	current := modTimes(w.src.files())
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	changed := !reflect.DeepEqual(current, w.modTimes)
+	w.modTimes = current
+
+	return changed
+}
+
+func modTimes(files []string) map[string]time.Time {
+	times := make(map[string]time.Time, len(files))
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil {
+			times[file] = info.ModTime()
+		}
+	}
+
+	return times
+}
+
+// reloadFields copies the reloadable fields of next into dst, recording the
+// keys it changed and the keys that differ but are not reloadable.
+func reloadFields(dst, next reflect.Value, path string, reloadable bool, changed, restart *[]string) {
+	if reflect.DeepEqual(dst.Interface(), next.Interface()) {
+		return
+	}
+
+	if reloadable {
+		dst.Set(next)
+		*changed = append(*changed, path)
+		return
+	}
+
+	switch dst.Kind() {
+	case reflect.Struct:
+		t := dst.Type()
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+
+			reloadFields(dst.Field(i), next.Field(i), join(path, yamlName(f)), f.Tag.Get("reload") == "true", changed, restart)
+		}
+
+	case reflect.Map:
+		if dst.Len() != next.Len() {
+			*restart = append(*restart, path)
+			return
+		}
+
+		// The map is shared with the previous configuration, so change a copy
+		copied := reflect.MakeMapWithSize(dst.Type(), dst.Len())
+		for _, key := range dst.MapKeys() {
+			nextElem := next.MapIndex(key)
+			if !nextElem.IsValid() {
+				*restart = append(*restart, path)
+				return
+			}
+
+			elem := reflect.New(dst.Type().Elem()).Elem()
+			elem.Set(dst.MapIndex(key))
+			reloadFields(elem, nextElem, fmt.Sprintf("%s[%v]", path, key), false, changed, restart)
+			copied.SetMapIndex(key, elem)
+		}
+		dst.Set(copied)
+
+	default:
+		*restart = append(*restart, path)
+	}
+}
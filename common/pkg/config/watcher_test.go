@@ -0,0 +1,121 @@
+package config
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestWatcher loads content and watches it, recording what subscribers
+// are handed.
+func newTestWatcher(t *testing.T, content string) (*Watcher[testConfig], string, chan *testConfig) {
+	t.Helper()
+
+	file := writeFile(t, t.TempDir(), "config.yaml", content)
+	src := Source{File: file}
+
+	cfg, err := load(t, src)
+	require.NoError(t, err)
+
+	w := NewWatcher(zerolog.Nop(), src, cfg)
+
+	notified := make(chan *testConfig, 10)
+	w.Subscribe(func(cfg *testConfig) { notified <- cfg })
+
+	return w, file, notified
+}
+
+func TestWatcherReloadsTaggedFields(t *testing.T) {
+	w, file, notified := newTestWatcher(t, baseConfig+"logger:\n  level: info\n")
+	initial := w.Current()
+
+	writeFile(t, "", file, baseConfig+"logger:\n  level: debug\n")
+	require.NoError(t, w.Reload())
+
+	require.Len(t, notified, 1)
+	cfg := <-notified
+	assert.Equal(t, "debug", cfg.Logger.Level)
+	assert.Same(t, cfg, w.Current())
+
+	// The configuration handed out before is left as it was
+	assert.Equal(t, "info", initial.Logger.Level)
+}
+
+func TestWatcherKeepsFieldsThatNeedARestart(t *testing.T) {
+	w, file, notified := newTestWatcher(t, baseConfig+"logger:\n  level: info\n")
+
+	writeFile(t, "", file, "name: orders\nserver:\n  port: 9090\ndatabase:\n  host: db.local\nlogger:\n  level: warn\n")
+	require.NoError(t, w.Reload())
+
+	require.Len(t, notified, 1)
+	cfg := <-notified
+	assert.Equal(t, "warn", cfg.Logger.Level)
+	assert.Equal(t, 8080, cfg.Server.Port)
+}
+
+func TestWatcherIgnoresReloadWithoutReloadableChange(t *testing.T) {
+	w, file, notified := newTestWatcher(t, baseConfig)
+	initial := w.Current()
+
+	writeFile(t, "", file, "name: orders\nserver:\n  port: 9090\ndatabase:\n  host: db.local\n")
+	require.NoError(t, w.Reload())
+
+	assert.Empty(t, notified)
+	assert.Same(t, initial, w.Current())
+}
+
+func TestWatcherKeepsCurrentOnInvalidReload(t *testing.T) {
+	w, file, notified := newTestWatcher(t, baseConfig)
+	initial := w.Current()
+
+	writeFile(t, "", file, baseConfig+"logger:\n  level: loud\n")
+
+	err := w.Reload()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "logger.level")
+
+	assert.Empty(t, notified)
+	assert.Same(t, initial, w.Current())
+}
+
+func TestWatcherFilesChanged(t *testing.T) {
+	w, file, _ := newTestWatcher(t, baseConfig)
+
+	assert.False(t, w.filesChanged())
+
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(file, later, later))
+
+	assert.True(t, w.filesChanged())
+	assert.False(t, w.filesChanged())
+}
+
+func TestWatcherReloadsOnSIGHUP(t *testing.T) {
+	w, file, notified := newTestWatcher(t, baseConfig)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- w.Start(ctx) }()
+
+	<-w.Ready()
+
+	writeFile(t, "", file, baseConfig+"logger:\n  level: error\n")
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+
+	select {
+	case cfg := <-notified:
+		assert.Equal(t, "error", cfg.Logger.Level)
+	case <-time.After(2 * time.Second):
+		t.Fatal("no reload after SIGHUP")
+	}
+
+	cancel()
+	require.NoError(t, <-done)
+	require.NoError(t, w.Stop(context.Background()))
+}
@@ -0,0 +1,104 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+// ReplayedHeader is set on responses served from the store.
+const ReplayedHeader = "Idempotent-Replayed"
+
+// Middleware applies idempotency to requests carrying the Idempotency-Key
+// header. Keys are scoped to the route and to the gin context value userKey
+// when it is set, so it must run after authentication. onError renders a
+// rejection in the service's own error format.
+func (i *Idempotency) Middleware(userKey string, onError func(c *gin.Context, err error)) gin.HandlerFunc {
+	if i == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	return func(c *gin.Context) {
+		idemKey := c.GetHeader(Header)
+		if idemKey == "" {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			onError(c, x.WrapWithCode(err, x.CodeHTTPErrorOnReadBody, "invalid_request_body"))
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		route := c.Request.Method + " " + c.FullPath()
+		key := route + ":" + c.GetString(userKey) + ":" + idemKey
+		fp := fingerprint([]byte(route), body)
+
+		rec, err := i.begin(ctx, key, fp)
+		switch {
+		case errors.Is(err, ErrInProgress):
+			onError(c, x.WrapWithCode(err, x.CodeHTTPConflict, "idempotency_in_progress"))
+			c.Abort()
+			return
+		case errors.Is(err, ErrKeyReused):
+			onError(c, x.WrapWithCode(err, x.CodeHTTPUnprocessableEntity, "idempotency_key_reused"))
+			c.Abort()
+			return
+		case err != nil:
+			onError(c, x.WrapWithCode(err, x.CodeHTTPServiceUnavailable, "idempotency_unavailable"))
+			c.Abort()
+			return
+		case rec != nil:
+			c.Header(ReplayedHeader, "true")
+			c.Data(rec.StatusCode, "application/json; charset=utf-8", rec.Response)
+			c.Abort()
+			return
+		}
+
+		c.Request = c.Request.WithContext(WithKey(ctx, idemKey))
+
+		writer := &bodyWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		c.Next()
+
+		if status := writer.Status(); status < http.StatusOK || status >= http.StatusMultipleChoices {
+			if err := i.abort(context.WithoutCancel(ctx), key); err != nil {
+				zerolog.Ctx(ctx).Error().Err(err).Str("route", route).Msg("idempotency_abort_failed")
+			}
+			return
+		}
+
+		if err := i.finish(context.WithoutCancel(ctx), key, Record{Fingerprint: fp, StatusCode: writer.Status(), Response: writer.body.Bytes()}); err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Str("route", route).Msg("idempotency_save_failed")
+		}
+	}
+}
+
+// bodyWriter keeps a copy of the response so it can be stored for replay.
+type bodyWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
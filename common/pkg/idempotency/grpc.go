@@ -0,0 +1,128 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// keyedRequest is satisfied by any generated request message with an
+// idempotency_key field.
+type keyedRequest interface {
+	proto.Message
+	GetIdempotencyKey() string
+}
+
+// userRequest is satisfied by requests made on behalf of an end user, such
+// as those a trusted service forwards with a user_id field.
+type userRequest interface {
+	GetUserId() string
+}
+
+// keyScope names whose key space a request's idempotency key lives in: the
+// caller, and the user the request is for when the caller is not that user.
+// Without the user, every user behind one forwarding service would share a
+// key space.
+func keyScope(principal string, req any) string {
+	user, ok := req.(userRequest)
+	if !ok || user.GetUserId() == "" || principal == "user:"+user.GetUserId() {
+		return principal
+	}
+
+	return principal + ":user:" + user.GetUserId()
+}
+
+// UnaryServerInterceptor applies idempotency to every RPC whose request has
+// a non-empty idempotency_key. Keys are scoped to the method, to the caller
+// that principal names and to the request's user_id if it has one, so it
+// must run after the interceptors that authenticate the call.
+func (i *Idempotency) UnaryServerInterceptor(principal func(ctx context.Context) string) grpc.UnaryServerInterceptor {
+	if i == nil {
+		return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			return handler(ctx, req)
+		}
+	}
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		keyed, ok := req.(keyedRequest)
+		if !ok || keyed.GetIdempotencyKey() == "" {
+			return handler(ctx, req)
+		}
+
+		body, err := proto.MarshalOptions{Deterministic: true}.Marshal(keyed)
+		if err != nil {
+			return nil, status.Error(codes.Internal, "idempotency: encode request")
+		}
+
+		key := info.FullMethod + ":" + keyScope(principal(ctx), req) + ":" + keyed.GetIdempotencyKey()
+		fp := fingerprint([]byte(info.FullMethod), body)
+
+		rec, err := i.begin(ctx, key, fp)
+		switch {
+		case errors.Is(err, ErrInProgress):
+			return nil, status.Error(codes.Aborted, err.Error())
+		case errors.Is(err, ErrKeyReused):
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		case err != nil:
+			return nil, status.Error(codes.Unavailable, err.Error())
+		case rec != nil:
+			return replayProto(rec)
+		}
+
+		ctx = WithKey(ctx, keyed.GetIdempotencyKey())
+
+		resp, err := handler(ctx, req)
+		if err != nil {
+			if abortErr := i.abort(context.WithoutCancel(ctx), key); abortErr != nil {
+				zerolog.Ctx(ctx).Error().Err(abortErr).Str("method", info.FullMethod).Msg("idempotency_abort_failed")
+			}
+			return resp, err
+		}
+
+		if err := i.storeProto(context.WithoutCancel(ctx), key, fp, resp); err != nil {
+			// The call succeeded; a retry now runs again instead of replaying
+			zerolog.Ctx(ctx).Error().Err(err).Str("method", info.FullMethod).Msg("idempotency_save_failed")
+		}
+
+		return resp, nil
+	}
+}
+
+func (i *Idempotency) storeProto(ctx context.Context, key, fp string, resp any) error {
+	msg, ok := resp.(proto.Message)
+	if !ok {
+		return i.abort(ctx, key)
+	}
+
+	wrapped, err := anypb.New(msg)
+	if err != nil {
+		return err
+	}
+
+	raw, err := proto.Marshal(wrapped)
+	if err != nil {
+		return err
+	}
+
+	return i.finish(ctx, key, Record{Fingerprint: fp, Response: raw})
+}
+
+func replayProto(rec *Record) (any, error) {
+	var wrapped anypb.Any
+	if err := proto.Unmarshal(rec.Response, &wrapped); err != nil {
+		return nil, status.Error(codes.Internal, "idempotency: decode stored response")
+	}
+
+	msg, err := wrapped.UnmarshalNew()
+	if err != nil {
+		return nil, status.Error(codes.Internal, "idempotency: decode stored response")
+	}
+
+	return msg, nil
+}
@@ -0,0 +1,228 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	orderpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/order"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const testMethod = "/order.OrderService/CreateOrder"
+
+type principalKey struct{}
+
+func withPrincipal(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, principalKey{}, name)
+}
+
+func testPrincipal(ctx context.Context) string {
+	name, _ := ctx.Value(principalKey{}).(string)
+	return name
+}
+
+// countingHandler answers with an order numbered by how often it has run.
+type countingHandler struct {
+	calls int
+	err   error
+	keys  []string
+}
+
+func (h *countingHandler) handle(ctx context.Context, req any) (any, error) {
+	h.calls++
+	h.keys = append(h.keys, KeyFromContext(ctx))
+	if h.err != nil {
+		return nil, h.err
+	}
+
+	return &orderpb.CreateOrderResponse{Success: true, OrderId: "order-" + strconv.Itoa(h.calls)}, nil
+}
+
+func call(ctx context.Context, interceptor grpc.UnaryServerInterceptor, req *orderpb.CreateOrderRequest, h *countingHandler) (any, error) {
+	return interceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: testMethod}, h.handle)
+}
+
+func TestUnaryServerInterceptorReplay(t *testing.T) {
+	interceptor := New(newMemoryStore(), Config{}).UnaryServerInterceptor(testPrincipal)
+	ctx := withPrincipal(context.Background(), "user:u-1")
+	req := &orderpb.CreateOrderRequest{UserId: "u-1", IdempotencyKey: "k"}
+	h := &countingHandler{}
+
+	first, err := call(ctx, interceptor, req, h)
+	require.NoError(t, err)
+
+	second, err := call(ctx, interceptor, req, h)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, h.calls)
+	assert.Equal(t, []string{"k"}, h.keys)
+	assert.True(t, proto.Equal(first.(proto.Message), second.(proto.Message)), "replayed %v, want %v", second, first)
+}
+
+func TestUnaryServerInterceptorScopedToPrincipal(t *testing.T) {
+	interceptor := New(newMemoryStore(), Config{}).UnaryServerInterceptor(testPrincipal)
+	req := &orderpb.CreateOrderRequest{UserId: "u-1", IdempotencyKey: "k"}
+	h := &countingHandler{}
+
+	first, err := call(withPrincipal(context.Background(), "user:u-1"), interceptor, req, h)
+	require.NoError(t, err)
+
+	// Another caller with the same key and body never sees the first response
+	second, err := call(withPrincipal(context.Background(), "user:u-2"), interceptor, req, h)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, h.calls)
+	assert.Equal(t, "order-1", first.(*orderpb.CreateOrderResponse).OrderId)
+	assert.Equal(t, "order-2", second.(*orderpb.CreateOrderResponse).OrderId)
+}
+
+func TestUnaryServerInterceptorScopedToForwardedUser(t *testing.T) {
+	store := newMemoryStore()
+	interceptor := New(store, Config{}).UnaryServerInterceptor(testPrincipal)
+	ctx := withPrincipal(context.Background(), "service:api-gateway")
+	h := &countingHandler{}
+
+	// Two users behind the same service pick the same key
+	first, err := call(ctx, interceptor, &orderpb.CreateOrderRequest{UserId: "u-1", IdempotencyKey: "k"}, h)
+	require.NoError(t, err)
+
+	second, err := call(ctx, interceptor, &orderpb.CreateOrderRequest{UserId: "u-2", IdempotencyKey: "k"}, h)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, h.calls)
+	assert.Equal(t, "order-1", first.(*orderpb.CreateOrderResponse).OrderId)
+	assert.Equal(t, "order-2", second.(*orderpb.CreateOrderResponse).OrderId)
+	assert.Contains(t, store.records, testMethod+":service:api-gateway:user:u-1:k")
+	assert.Contains(t, store.records, testMethod+":service:api-gateway:user:u-2:k")
+
+	// A retry for the same user still replays
+	replayed, err := call(ctx, interceptor, &orderpb.CreateOrderRequest{UserId: "u-1", IdempotencyKey: "k"}, h)
+	require.NoError(t, err)
+	assert.Equal(t, 2, h.calls)
+	assert.Equal(t, "order-1", replayed.(*orderpb.CreateOrderResponse).OrderId)
+}
+
+func TestKeyScope(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal string
+		req       any
+		want      string
+	}{
+		{name: "user calling for themselves", principal: "user:u-1", req: &orderpb.CreateOrderRequest{UserId: "u-1"}, want: "user:u-1"},
+		{name: "user calling for someone else", principal: "user:u-1", req: &orderpb.CreateOrderRequest{UserId: "u-2"}, want: "user:u-1:user:u-2"},
+		{name: "service forwarding a user", principal: "service:api-gateway", req: &orderpb.CreateOrderRequest{UserId: "u-1"}, want: "service:api-gateway:user:u-1"},
+		{name: "no user on the request", principal: "service:api-gateway", req: &orderpb.CreateOrderRequest{}, want: "service:api-gateway"},
+		{name: "request without a user field", principal: "service:api-gateway", req: &orderpb.CreateOrderResponse{}, want: "service:api-gateway"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, keyScope(tt.principal, tt.req))
+		})
+	}
+}
+
+func TestUnaryServerInterceptorRejections(t *testing.T) {
+	tests := []struct {
+		name     string
+		prepare  func(store *memoryStore, key string)
+		req      *orderpb.CreateOrderRequest
+		wantCode codes.Code
+	}{
+		{
+			name:     "in progress",
+			prepare:  func(store *memoryStore, key string) {},
+			req:      &orderpb.CreateOrderRequest{UserId: "u-1", IdempotencyKey: "k"},
+			wantCode: codes.Aborted,
+		},
+		{
+			name:     "key reused for another request",
+			prepare:  func(store *memoryStore, key string) { store.records[key] = Record{Fingerprint: "other", Done: true} },
+			req:      &orderpb.CreateOrderRequest{UserId: "u-1", IdempotencyKey: "k"},
+			wantCode: codes.FailedPrecondition,
+		},
+		{
+			name:     "store unavailable",
+			prepare:  func(store *memoryStore, key string) { store.err = errors.New("redis down") },
+			req:      &orderpb.CreateOrderRequest{UserId: "u-1", IdempotencyKey: "k"},
+			wantCode: codes.Unavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMemoryStore()
+			interceptor := New(store, Config{}).UnaryServerInterceptor(testPrincipal)
+			ctx := withPrincipal(context.Background(), "user:u-1")
+
+			body, err := proto.MarshalOptions{Deterministic: true}.Marshal(tt.req)
+			require.NoError(t, err)
+
+			// Claimed by an earlier call that has not finished
+			key := testMethod + ":user:u-1:" + tt.req.IdempotencyKey
+			store.records[key] = Record{Fingerprint: fingerprint([]byte(testMethod), body)}
+			tt.prepare(store, key)
+
+			h := &countingHandler{}
+			_, err = call(ctx, interceptor, tt.req, h)
+
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			assert.Zero(t, h.calls)
+		})
+	}
+}
+
+func TestUnaryServerInterceptorFailureReleasesKey(t *testing.T) {
+	store := newMemoryStore()
+	interceptor := New(store, Config{}).UnaryServerInterceptor(testPrincipal)
+	ctx := withPrincipal(context.Background(), "user:u-1")
+	req := &orderpb.CreateOrderRequest{UserId: "u-1", IdempotencyKey: "k"}
+	h := &countingHandler{err: status.Error(codes.Unavailable, "product-service down")}
+
+	_, err := call(ctx, interceptor, req, h)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Empty(t, store.records)
+
+	h.err = nil
+	resp, err := call(ctx, interceptor, req, h)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, h.calls)
+	assert.Equal(t, "order-2", resp.(*orderpb.CreateOrderResponse).OrderId)
+}
+
+func TestUnaryServerInterceptorWithoutKey(t *testing.T) {
+	store := newMemoryStore()
+	interceptor := New(store, Config{}).UnaryServerInterceptor(testPrincipal)
+	h := &countingHandler{}
+
+	for range 2 {
+		_, err := call(context.Background(), interceptor, &orderpb.CreateOrderRequest{UserId: "u-1"}, h)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, 2, h.calls)
+	assert.Empty(t, store.records)
+}
+
+func TestUnaryServerInterceptorDisabled(t *testing.T) {
+	var i *Idempotency
+	interceptor := i.UnaryServerInterceptor(testPrincipal)
+	h := &countingHandler{}
+
+	for range 2 {
+		_, err := call(context.Background(), interceptor, &orderpb.CreateOrderRequest{IdempotencyKey: "k"}, h)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, 2, h.calls)
+}
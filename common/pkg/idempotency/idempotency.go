@@ -0,0 +1,111 @@
+// Package idempotency lets clients retry mutating requests safely. A request
+// carrying an idempotency key claims it before running; a retry with the same
+// key gets the first response replayed, and a duplicate that arrives while
+// the first is still running is rejected. Only successful responses are
+// stored, so a failed request can be retried with the same key.
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// Header carries the key on REST requests.
+const Header = "Idempotency-Key"
+
+var (
+	ErrInProgress = errors.New("idempotency: a request with this key is still in progress")
+	ErrKeyReused  = errors.New("idempotency: key was already used for a different request")
+)
+
+type Config struct {
+	// TTL is how long a stored response is replayed.
+	TTL time.Duration `yaml:"ttl"`
+	// LockTTL bounds how long an in-flight request holds its key, so a
+	// crashed request does not block retries forever. It should exceed the
+	// request timeout.
+	LockTTL time.Duration `yaml:"lock_ttl"`
+}
+
+// Record is what the store keeps per key. Response is empty until the first
+// request completes.
+type Record struct {
+	Fingerprint string `json:"fingerprint"`
+	Done        bool   `json:"done"`
+	StatusCode  int    `json:"status_code,omitempty"`
+	Response    []byte `json:"response,omitempty"`
+}
+
+// Idempotency is safe to use as a nil pointer, which disables it.
+type Idempotency struct {
+	store Store
+	cfg   Config
+}
+
+func New(store Store, cfg Config) *Idempotency {
+	if cfg.TTL <= 0 {
+		cfg.TTL = 24 * time.Hour
+	}
+	if cfg.LockTTL <= 0 {
+		cfg.LockTTL = time.Minute
+	}
+
+	return &Idempotency{store: store, cfg: cfg}
+}
+
+// begin claims key for the request identified by fingerprint. It returns nil
+// when the caller now owns the key and must call finish or abort, or the
+// completed record to replay.
+func (i *Idempotency) begin(ctx context.Context, key, fingerprint string) (*Record, error) {
+	existing, claimed, err := i.store.Claim(ctx, key, Record{Fingerprint: fingerprint}, i.cfg.LockTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case claimed:
+		return nil, nil
+	case existing.Fingerprint != fingerprint:
+		return nil, ErrKeyReused
+	case !existing.Done:
+		return nil, ErrInProgress
+	default:
+		return existing, nil
+	}
+}
+
+func (i *Idempotency) finish(ctx context.Context, key string, rec Record) error {
+	rec.Done = true
+	return i.store.Save(ctx, key, rec, i.cfg.TTL)
+}
+
+// abort releases the key so the client can retry with it.
+func (i *Idempotency) abort(ctx context.Context, key string) error {
+	return i.store.Delete(ctx, key)
+}
+
+func fingerprint(parts ...[]byte) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write(p)
+		h.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+type ctxKey struct{}
+
+// WithKey stores the request's key in ctx, so a handler can pass it on to the
+// downstream calls it makes.
+func WithKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, key)
+}
+
+func KeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(ctxKey{}).(string)
+	return key
+}
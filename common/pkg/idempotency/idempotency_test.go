@@ -0,0 +1,149 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore is a Store kept in a map, with the ttl of each call recorded.
+type memoryStore struct {
+	mu      sync.Mutex
+	records map[string]Record
+	ttls    map[string]time.Duration
+	err     error
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{records: map[string]Record{}, ttls: map[string]time.Duration{}}
+}
+
+func (m *memoryStore) Claim(ctx context.Context, key string, rec Record, ttl time.Duration) (*Record, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.err != nil {
+		return nil, false, m.err
+	}
+
+	if existing, ok := m.records[key]; ok {
+		return &existing, false, nil
+	}
+
+	m.records[key] = rec
+	m.ttls[key] = ttl
+
+	return nil, true, nil
+}
+
+func (m *memoryStore) Save(ctx context.Context, key string, rec Record, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.records[key] = rec
+	m.ttls[key] = ttl
+
+	return nil
+}
+
+func (m *memoryStore) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.records, key)
+	delete(m.ttls, key)
+
+	return nil
+}
+
+var _ Store = (*memoryStore)(nil)
+
+func TestNewDefaults(t *testing.T) {
+	i := New(newMemoryStore(), Config{})
+
+	assert.Equal(t, 24*time.Hour, i.cfg.TTL)
+	assert.Equal(t, time.Minute, i.cfg.LockTTL)
+}
+
+func TestBeginFinishReplay(t *testing.T) {
+	store := newMemoryStore()
+	i := New(store, Config{TTL: time.Hour, LockTTL: time.Second})
+	ctx := context.Background()
+
+	rec, err := i.begin(ctx, "k", "fp")
+	require.NoError(t, err)
+	assert.Nil(t, rec)
+
+	// The claim is held for the lock ttl only
+	assert.Equal(t, time.Second, store.ttls["k"])
+	assert.False(t, store.records["k"].Done)
+
+	_, err = i.begin(ctx, "k", "fp")
+	assert.ErrorIs(t, err, ErrInProgress)
+
+	require.NoError(t, i.finish(ctx, "k", Record{Fingerprint: "fp", StatusCode: 201, Response: []byte(`{"id":1}`)}))
+	assert.Equal(t, time.Hour, store.ttls["k"])
+
+	rec, err = i.begin(ctx, "k", "fp")
+	require.NoError(t, err)
+	require.NotNil(t, rec)
+	assert.True(t, rec.Done)
+	assert.Equal(t, 201, rec.StatusCode)
+	assert.Equal(t, []byte(`{"id":1}`), rec.Response)
+}
+
+func TestBeginKeyReused(t *testing.T) {
+	i := New(newMemoryStore(), Config{})
+	ctx := context.Background()
+
+	_, err := i.begin(ctx, "k", "fp")
+	require.NoError(t, err)
+	require.NoError(t, i.finish(ctx, "k", Record{Fingerprint: "fp"}))
+
+	// A different request under the key is refused, finished or not
+	_, err = i.begin(ctx, "k", "other")
+	assert.ErrorIs(t, err, ErrKeyReused)
+
+	_, err = i.begin(ctx, "k2", "fp")
+	require.NoError(t, err)
+	_, err = i.begin(ctx, "k2", "other")
+	assert.ErrorIs(t, err, ErrKeyReused)
+}
+
+func TestAbortReleasesKey(t *testing.T) {
+	i := New(newMemoryStore(), Config{})
+	ctx := context.Background()
+
+	_, err := i.begin(ctx, "k", "fp")
+	require.NoError(t, err)
+	require.NoError(t, i.abort(ctx, "k"))
+
+	rec, err := i.begin(ctx, "k", "fp")
+	require.NoError(t, err)
+	assert.Nil(t, rec)
+}
+
+func TestBeginStoreError(t *testing.T) {
+	store := newMemoryStore()
+	store.err = errors.New("redis down")
+
+	_, err := New(store, Config{}).begin(context.Background(), "k", "fp")
+	assert.EqualError(t, err, "redis down")
+}
+
+func TestFingerprint(t *testing.T) {
+	assert.Equal(t, fingerprint([]byte("a"), []byte("b")), fingerprint([]byte("a"), []byte("b")))
+
+	// Parts are delimited, so moving bytes between them changes the print
+	assert.NotEqual(t, fingerprint([]byte("ab"), []byte("c")), fingerprint([]byte("a"), []byte("bc")))
+}
+
+func TestKeyFromContext(t *testing.T) {
+	assert.Equal(t, "", KeyFromContext(context.Background()))
+	assert.Equal(t, "k", KeyFromContext(WithKey(context.Background(), "k")))
+}
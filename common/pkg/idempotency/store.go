@@ -0,0 +1,85 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type Store interface {
+	// Claim stores rec under key unless the key is taken, in which case it
+	// returns the record already there.
+	Claim(ctx context.Context, key string, rec Record, ttl time.Duration) (*Record, bool, error)
+	Save(ctx context.Context, key string, rec Record, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
+
+const redisKeyPrefix = "idempotency:"
+
+type RedisStore struct {
+	client *redis.Client
+}
+
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+func (r *RedisStore) Claim(ctx context.Context, key string, rec Record, ttl time.Duration) (*Record, bool, error) {
+	value, err := json.Marshal(rec)
+	if err != nil {
+		return nil, false, fmt.Errorf("idempotency: encode record: %w", err)
+	}
+
+	// A key that expires between SetNX and Get is simply claimed again
+	for range 2 {
+		claimed, err := r.client.SetNX(ctx, redisKeyPrefix+key, value, ttl).Result()
+		if err != nil {
+			return nil, false, fmt.Errorf("idempotency: claim key: %w", err)
+		}
+		if claimed {
+			return nil, true, nil
+		}
+
+		raw, err := r.client.Get(ctx, redisKeyPrefix+key).Bytes()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("idempotency: read key: %w", err)
+		}
+
+		var existing Record
+		if err := json.Unmarshal(raw, &existing); err != nil {
+			return nil, false, fmt.Errorf("idempotency: decode record: %w", err)
+		}
+
+		return &existing, false, nil
+	}
+
+	return nil, false, ErrInProgress
+}
+
+func (r *RedisStore) Save(ctx context.Context, key string, rec Record, ttl time.Duration) error {
+	value, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("idempotency: encode record: %w", err)
+	}
+
+	if err := r.client.Set(ctx, redisKeyPrefix+key, value, ttl).Err(); err != nil {
+		return fmt.Errorf("idempotency: save key: %w", err)
+	}
+
+	return nil
+}
+
+func (r *RedisStore) Delete(ctx context.Context, key string) error {
+	if err := r.client.Del(ctx, redisKeyPrefix+key).Err(); err != nil {
+		return fmt.Errorf("idempotency: delete key: %w", err)
+	}
+
+	return nil
+}
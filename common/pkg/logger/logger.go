@@ -17,10 +17,10 @@ var globalLogger zerolog.Logger
 
 type Config struct {
 	Enabled    bool   `yaml:"enabled"`
-	Level      string `yaml:"level"`
+	Level      string `yaml:"level" reload:"true" validate:"omitempty,oneof=debug info warn warning error fatal panic"`
 	Format     string `yaml:"format"`
 	Output     string `yaml:"output"`
-	Path       string `yaml:"path"`
+	Path       string `yaml:"path" validate:"required_if=Enabled true"`
 	MaxSize    int    `yaml:"max_size"`
 	MaxBackups int    `yaml:"max_backups"`
 	MaxAge     int    `yaml:"max_age"`
@@ -74,8 +74,10 @@ func Init(cfg Config) zerolog.Logger {
 			output = zerolog.MultiLevelWriter(os.Stderr, fileLogger)
 		}
 
+		// The level is global, so SetLevel changes it for every logger
+		zerolog.SetGlobalLevel(logLevel)
+
 		globalLogger = zerolog.New(output).
-			Level(logLevel).
 			With().
 			Timestamp().
 			Caller().
@@ -84,3 +86,9 @@ func Init(cfg Config) zerolog.Logger {
 
 	return globalLogger
 }
+
+// SetLevel changes the level of every logger, e.g. on a configuration
+// reload.
+func SetLevel(level string) {
+	zerolog.SetGlobalLevel(parseLogLevel(level))
+}
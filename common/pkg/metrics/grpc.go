@@ -0,0 +1,14 @@
+package metrics
+
+import "strings"
+
+// SplitMethodName splits a gRPC full method, /auth.AuthService/Login, into
+// its service and method names.
+func SplitMethodName(fullMethod string) (string, string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	if service, method, ok := strings.Cut(fullMethod, "/"); ok {
+		return service, method
+	}
+
+	return "unknown", "unknown"
+}
@@ -1,81 +1,176 @@
+// Package metrics holds the Prometheus collectors every service exports:
+// request rate, errors and duration for HTTP and gRPC, Kafka throughput and
+// consumer lag, and scheduler jobs. Init registers them, with a service
+// label, on the default registry, which Handler serves.
 package metrics
 
 import (
+	"net/http"
+	"sync"
+
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 var (
-	MessagesReceived = promauto.NewCounterVec(
+	HTTPRequests = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
-			Name: "analytics_messages_received_total",
-			Help: "Total number of messages received from Kafka",
+			Name: "http_requests_total",
+			Help: "Total number of HTTP requests handled",
 		},
-		[]string{"topic"},
+		[]string{"method", "route", "status"},
+	)
+
+	HTTPRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Histogram of HTTP request duration",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"method", "route"},
 	)
 
-	MessagesProcessed = promauto.NewCounterVec(
+	HTTPRequestsInFlight = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "http_requests_in_flight",
+			Help: "Number of HTTP requests being handled",
+		},
+	)
+
+	GRPCServerHandled = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
-			Name: "analytics_messages_processed_total",
-			Help: "Total number of messages successfully processed",
+			Name: "grpc_server_handled_total",
+			Help: "Total number of gRPC calls handled by the server",
 		},
-		[]string{"topic", "status"},
+		[]string{"grpc_service", "grpc_method", "grpc_code"},
 	)
 
-	MessageProcessingDuration = promauto.NewHistogramVec(
+	GRPCServerHandlingDuration = prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
-			Name:    "analytics_message_processing_duration_seconds",
-			Help:    "Histogram of message processing duration",
+			Name:    "grpc_server_handling_seconds",
+			Help:    "Histogram of gRPC call duration on the server",
 			Buckets: prometheus.DefBuckets,
 		},
-		[]string{"topic"},
+		[]string{"grpc_service", "grpc_method"},
 	)
 
-	DLQMessagesSent = promauto.NewCounterVec(
+	GRPCClientHandled = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
-			Name: "analytics_dlq_messages_total",
-			Help: "Total number of messages sent to DLQ",
+			Name: "grpc_client_handled_total",
+			Help: "Total number of gRPC calls completed by clients",
 		},
-		[]string{"topic"},
+		[]string{"grpc_service", "grpc_method", "grpc_code"},
 	)
 
-	RetryAttempts = promauto.NewCounterVec(
-		prometheus.CounterOpts{
-			Name: "analytics_retry_attempts_total",
-			Help: "Total number of retry attempts",
+	GRPCClientHandlingDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "grpc_client_handling_seconds",
+			Help:    "Histogram of gRPC call duration on the client",
+			Buckets: prometheus.DefBuckets,
 		},
-		[]string{"topic"},
+		[]string{"grpc_service", "grpc_method"},
 	)
 
-	MongoOperations = promauto.NewCounterVec(
+	KafkaProducerMessages = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
-			Name: "analytics_mongo_operations_total",
-			Help: "Total number of MongoDB operations",
+			Name: "kafka_producer_messages_total",
+			Help: "Total number of messages sent to Kafka",
 		},
-		[]string{"collection", "operation", "status"},
+		[]string{"topic", "status"},
 	)
 
-	MongoOperationDuration = promauto.NewHistogramVec(
+	KafkaProducerSendDuration = prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
-			Name:    "analytics_mongo_operation_duration_seconds",
-			Help:    "Histogram of MongoDB operation duration",
+			Name:    "kafka_producer_send_duration_seconds",
+			Help:    "Histogram of the time Kafka takes to acknowledge a message",
 			Buckets: prometheus.DefBuckets,
 		},
-		[]string{"collection", "operation"},
+		[]string{"topic"},
 	)
 
-	RedisOperations = promauto.NewCounterVec(
+	KafkaConsumerMessages = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
-			Name: "analytics_redis_operations_total",
-			Help: "Total number of Redis operations",
+			Name: "kafka_consumer_messages_total",
+			Help: "Total number of messages received from Kafka",
+		},
+		[]string{"group", "topic"},
+	)
+
+	KafkaConsumerLag = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kafka_consumer_lag",
+			Help: "Messages in the partition after the last one received",
 		},
-		[]string{"operation", "status"},
+		[]string{"group", "topic", "partition"},
 	)
 
-	KafkaProducerErrors = promauto.NewCounter(
+	SchedulerJobRuns = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
-			Name: "analytics_kafka_producer_errors_total",
-			Help: "Total number of Kafka producer errors",
+			Name: "scheduler_job_runs_total",
+			Help: "Total number of scheduled job runs",
 		},
+		[]string{"job", "status"},
+	)
+
+	SchedulerJobDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "scheduler_job_duration_seconds",
+			Help:    "Histogram of scheduled job duration",
+			Buckets: []float64{.1, .5, 1, 5, 15, 30, 60, 300, 900},
+		},
+		[]string{"job"},
 	)
 )
+
+var (
+	onceMetrics = &sync.Once{}
+
+	// registerer adds the service label once Init has run
+	registerer prometheus.Registerer = prometheus.DefaultRegisterer
+)
+
+// Init registers the collectors above for service. Call it before starting
+// the components, which register theirs with Register.
+func Init(service string) {
+	onceMetrics.Do(func() {
+		registerer = prometheus.WrapRegistererWith(prometheus.Labels{"service": service}, prometheus.DefaultRegisterer)
+
+		registerer.MustRegister(
+			HTTPRequests,
+			HTTPRequestDuration,
+			HTTPRequestsInFlight,
+			GRPCServerHandled,
+			GRPCServerHandlingDuration,
+			GRPCClientHandled,
+			GRPCClientHandlingDuration,
+			KafkaProducerMessages,
+			KafkaProducerSendDuration,
+			KafkaConsumerMessages,
+			KafkaConsumerLag,
+			SchedulerJobRuns,
+			SchedulerJobDuration,
+		)
+	})
+}
+
+// Register adds a collector, such as a component's pool stats, with the
+// service label.
+func Register(c prometheus.Collector) error {
+	return registerer.Register(c)
+}
+
+// MustRegister is Register for collectors declared by a service, panicking
+// on a conflict.
+func MustRegister(cs ...prometheus.Collector) {
+	registerer.MustRegister(cs...)
+}
+
+// Unregister removes a collector added with Register.
+func Unregister(c prometheus.Collector) bool {
+	return registerer.Unregister(c)
+}
+
+// Handler serves the registered metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
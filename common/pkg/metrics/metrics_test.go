@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+const testService = "test-service"
+
+func init() {
+	Init(testService)
+}
+
+// scrape returns what /metrics serves.
+func scrape(t *testing.T) string {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	return w.Body.String()
+}
+
+func TestSplitMethodName(t *testing.T) {
+	tests := []struct {
+		fullMethod  string
+		wantService string
+		wantMethod  string
+	}{
+		{"/auth.AuthService/Login", "auth.AuthService", "Login"},
+		{"order.OrderService/GetOrder", "order.OrderService", "GetOrder"},
+		{"/grpc.health.v1.Health/Check", "grpc.health.v1.Health", "Check"},
+		{"garbage", "unknown", "unknown"},
+		{"", "unknown", "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fullMethod, func(t *testing.T) {
+			service, method := SplitMethodName(tt.fullMethod)
+			assert.Equal(t, tt.wantService, service)
+			assert.Equal(t, tt.wantMethod, method)
+		})
+	}
+}
+
+func TestInitAddsServiceLabel(t *testing.T) {
+	HTTPRequests.WithLabelValues(http.MethodGet, "/api/v1/things", "200").Inc()
+	GRPCClientHandled.WithLabelValues("user.UserService", "GetUser", "OK").Inc()
+	SchedulerJobRuns.WithLabelValues("outbox_relay", "success").Inc()
+
+	body := scrape(t)
+
+	assert.Contains(t, body, `http_requests_total{method="GET",route="/api/v1/things",service="test-service",status="200"} 1`)
+	assert.Contains(t, body, `grpc_client_handled_total{grpc_code="OK",grpc_method="GetUser",grpc_service="user.UserService",service="test-service"} 1`)
+	assert.Contains(t, body, `scheduler_job_runs_total{job="outbox_relay",service="test-service",status="success"} 1`)
+	assert.Contains(t, body, `http_requests_in_flight{service="test-service"} 0`)
+}
+
+func TestInitRunsOnce(t *testing.T) {
+	assert.NotPanics(t, func() { Init("other-service") })
+	assert.NotContains(t, scrape(t), `service="other-service"`)
+}
+
+func TestRegisterAddsServiceLabel(t *testing.T) {
+	jobs := prometheus.NewGauge(prometheus.GaugeOpts{Name: "test_queue_depth", Help: "Jobs waiting"})
+	jobs.Set(3)
+
+	assert.NoError(t, Register(jobs))
+	assert.Contains(t, scrape(t), `test_queue_depth{service="test-service"} 3`)
+
+	// A second collector with the same series conflicts
+	assert.Error(t, Register(prometheus.NewGauge(prometheus.GaugeOpts{Name: "test_queue_depth", Help: "Jobs waiting"})))
+
+	assert.True(t, Unregister(jobs))
+	assert.NotContains(t, scrape(t), "test_queue_depth")
+}
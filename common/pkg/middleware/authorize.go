@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"github.com/linggaaskaedo/go-kill/common/pkg/authz"
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Authorize rejects requests whose principal lacks any of permissions. It
+// must run after the service's authentication middleware has stored the
+// principal with authz.WithPrincipal. onError renders a rejection in the
+// service's own error format.
+func Authorize(onError func(c *gin.Context, err error), permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := authz.FromContext(c.Request.Context())
+		if !ok {
+			onError(c, x.NewWithCode(x.CodeHTTPUnauthorized, "no_principal"))
+			c.Abort()
+			return
+		}
+
+		if !principal.Allows(permissions) {
+			onError(c, x.NewWithCode(x.CodeHTTPForbidden, "permission_denied"))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
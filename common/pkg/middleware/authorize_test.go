@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/linggaaskaedo/go-kill/common/pkg/authz"
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// statusOnError answers with the HTTP status of the error code, the way the
+// services' httpRespError does.
+func statusOnError(c *gin.Context, err error) {
+	switch x.ErrCode(err) {
+	case x.CodeHTTPUnauthorized:
+		c.Status(http.StatusUnauthorized)
+	case x.CodeHTTPForbidden:
+		c.Status(http.StatusForbidden)
+	default:
+		c.Status(http.StatusInternalServerError)
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name      string
+		principal *authz.Principal
+		required  []string
+		want      int
+	}{
+		{"no principal", nil, []string{authz.PermProductWrite}, http.StatusUnauthorized},
+		{"denied", &authz.Principal{UserID: "user-1", Permissions: []string{authz.PermOrderRead}}, []string{authz.PermProductWrite}, http.StatusForbidden},
+		{"allowed", &authz.Principal{UserID: "user-1", Permissions: []string{authz.PermProductWrite}}, []string{authz.PermProductWrite}, http.StatusOK},
+		{"no permission required", &authz.Principal{UserID: "user-1"}, nil, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reached := false
+			r := gin.New()
+			r.GET("/", func(c *gin.Context) {
+				if tt.principal != nil {
+					c.Request = c.Request.WithContext(authz.WithPrincipal(c.Request.Context(), tt.principal))
+				}
+				c.Next()
+			}, Authorize(statusOnError, tt.required...), func(c *gin.Context) {
+				reached = true
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			assert.Equal(t, tt.want, w.Code)
+			assert.Equal(t, tt.want == http.StatusOK, reached)
+		})
+	}
+}
@@ -2,10 +2,12 @@ package middleware
 
 import (
 	"context"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/linggaaskaedo/go-kill/common/pkg/correlation"
+	"github.com/linggaaskaedo/go-kill/common/pkg/metrics"
 	"github.com/linggaaskaedo/go-kill/common/pkg/preference"
 
 	"github.com/gin-gonic/gin"
@@ -22,6 +24,9 @@ func (mw *middleware) Handler() gin.HandlerFunc {
 		if !strings.HasPrefix(path, "/swagger/") {
 			start := time.Now()
 
+			metrics.HTTPRequestsInFlight.Inc()
+			defer metrics.HTTPRequestsInFlight.Dec()
+
 			span := trace.SpanFromContext(ctx)
 			spanContext := span.SpanContext()
 			traceID := spanContext.TraceID().String()
@@ -51,6 +56,8 @@ func (mw *middleware) Handler() gin.HandlerFunc {
 			c.Next()
 
 			latency := time.Since(start)
+			observeRequest(c, latency)
+
 			if latency > time.Minute {
 				latency = latency.Truncate(time.Second)
 			}
@@ -83,3 +90,15 @@ func (mw *middleware) attachLogger(ctx context.Context) context.Context {
 		Logger().
 		WithContext(ctx)
 }
+
+// observeRequest records the request by route rather than path, so that
+// path parameters do not each get a series.
+func observeRequest(c *gin.Context, latency time.Duration) {
+	route := c.FullPath()
+	if route == "" {
+		route = "unmatched"
+	}
+
+	metrics.HTTPRequests.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Inc()
+	metrics.HTTPRequestDuration.WithLabelValues(c.Request.Method, route).Observe(latency.Seconds())
+}
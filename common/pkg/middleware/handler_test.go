@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/linggaaskaedo/go-kill/common/pkg/metrics"
+	"github.com/linggaaskaedo/go-kill/common/pkg/preference"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	metrics.Init("test-service")
+}
+
+func scrape(t *testing.T) string {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	return w.Body.String()
+}
+
+func TestHandlerRecordsREDMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mw := &middleware{log: zerolog.Nop()}
+
+	router := gin.New()
+	router.Use(mw.Handler())
+	router.GET("/widgets/:id", func(c *gin.Context) {
+		if c.Param("id") == "missing" {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	for _, path := range []string{"/widgets/1", "/widgets/2", "/widgets/missing", "/nowhere", "/swagger/index.html"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+		if path != "/swagger/index.html" {
+			assert.NotEmpty(t, w.Header().Get(preference.REQUEST_ID), path)
+		}
+	}
+
+	body := scrape(t)
+
+	// Rate and errors, by route template rather than path
+	assert.Contains(t, body, `http_requests_total{method="GET",route="/widgets/:id",service="test-service",status="200"} 2`)
+	assert.Contains(t, body, `http_requests_total{method="GET",route="/widgets/:id",service="test-service",status="404"} 1`)
+	assert.Contains(t, body, `http_requests_total{method="GET",route="unmatched",service="test-service",status="404"} 1`)
+	assert.NotContains(t, body, `route="/widgets/1"`)
+	assert.NotContains(t, body, "swagger")
+
+	// Duration
+	assert.Contains(t, body, `http_request_duration_seconds_count{method="GET",route="/widgets/:id",service="test-service"} 3`)
+	assert.Contains(t, body, `http_requests_in_flight{service="test-service"} 0`)
+}
@@ -43,6 +43,7 @@ const (
 	// Custom HTTP Header
 	APP_LANG   string = `x-app-lang`
 	REQUEST_ID string = `x-request-id`
+	API_KEY    string = `x-api-key`
 
 	// Cache Control Header
 	CacheControl        string = `cache-control`
@@ -80,8 +80,10 @@ type CreateOrderRequest struct {
 	ShippingAddressId string                 `protobuf:"bytes,3,opt,name=shipping_address_id,json=shippingAddressId,proto3" json:"shipping_address_id,omitempty"`
 	BillingAddressId  string                 `protobuf:"bytes,4,opt,name=billing_address_id,json=billingAddressId,proto3" json:"billing_address_id,omitempty"`
 	PaymentMethod     string                 `protobuf:"bytes,5,opt,name=payment_method,json=paymentMethod,proto3" json:"payment_method,omitempty"`
-	unknownFields     protoimpl.UnknownFields
-	sizeCache         protoimpl.SizeCache
+	// Optional; a retry with the same key returns the first response
+	IdempotencyKey string `protobuf:"bytes,6,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *CreateOrderRequest) Reset() {
@@ -149,6 +151,13 @@ func (x *CreateOrderRequest) GetPaymentMethod() string {
 	return ""
 }
 
+func (x *CreateOrderRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
 type CreateOrderResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
@@ -669,6 +678,137 @@ func (x *CancelOrderResponse) GetError() string {
 	return ""
 }
 
+// UpdateOrderStatus moves an order along
+// pending → confirmed → processing → shipped → delivered. Pending and
+// confirmed orders may also be cancelled; anything else is rejected.
+type UpdateOrderStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       string                 `protobuf:"bytes,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Actor         string                 `protobuf:"bytes,3,opt,name=actor,proto3" json:"actor,omitempty"` // user id, admin id or "system"
+	Note          string                 `protobuf:"bytes,4,opt,name=note,proto3" json:"note,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateOrderStatusRequest) Reset() {
+	*x = UpdateOrderStatusRequest{}
+	mi := &file_order_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateOrderStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateOrderStatusRequest) ProtoMessage() {}
+
+func (x *UpdateOrderStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_order_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateOrderStatusRequest.ProtoReflect.Descriptor instead.
+func (*UpdateOrderStatusRequest) Descriptor() ([]byte, []int) {
+	return file_order_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *UpdateOrderStatusRequest) GetOrderId() string {
+	if x != nil {
+		return x.OrderId
+	}
+	return ""
+}
+
+func (x *UpdateOrderStatusRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *UpdateOrderStatusRequest) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *UpdateOrderStatusRequest) GetNote() string {
+	if x != nil {
+		return x.Note
+	}
+	return ""
+}
+
+type UpdateOrderStatusResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Success        bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	PreviousStatus string                 `protobuf:"bytes,2,opt,name=previous_status,json=previousStatus,proto3" json:"previous_status,omitempty"`
+	Status         string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *UpdateOrderStatusResponse) Reset() {
+	*x = UpdateOrderStatusResponse{}
+	mi := &file_order_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateOrderStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateOrderStatusResponse) ProtoMessage() {}
+
+func (x *UpdateOrderStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_order_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateOrderStatusResponse.ProtoReflect.Descriptor instead.
+func (*UpdateOrderStatusResponse) Descriptor() ([]byte, []int) {
+	return file_order_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *UpdateOrderStatusResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *UpdateOrderStatusResponse) GetPreviousStatus() string {
+	if x != nil {
+		return x.PreviousStatus
+	}
+	return ""
+}
+
+func (x *UpdateOrderStatusResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
 var File_order_proto protoreflect.FileDescriptor
 
 const file_order_proto_rawDesc = "" +
@@ -677,13 +817,14 @@ const file_order_proto_rawDesc = "" +
 	"\tOrderItem\x12\x1d\n" +
 	"\n" +
 	"product_id\x18\x01 \x01(\tR\tproductId\x12\x1a\n" +
-	"\bquantity\x18\x02 \x01(\x05R\bquantity\"\xda\x01\n" +
+	"\bquantity\x18\x02 \x01(\x05R\bquantity\"\x83\x02\n" +
 	"\x12CreateOrderRequest\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\tR\x06userId\x12&\n" +
 	"\x05items\x18\x02 \x03(\v2\x10.order.OrderItemR\x05items\x12.\n" +
 	"\x13shipping_address_id\x18\x03 \x01(\tR\x11shippingAddressId\x12,\n" +
 	"\x12billing_address_id\x18\x04 \x01(\tR\x10billingAddressId\x12%\n" +
-	"\x0epayment_method\x18\x05 \x01(\tR\rpaymentMethod\"\xa6\x01\n" +
+	"\x0epayment_method\x18\x05 \x01(\tR\rpaymentMethod\x12'\n" +
+	"\x0fidempotency_key\x18\x06 \x01(\tR\x0eidempotencyKey\"\xa6\x01\n" +
 	"\x13CreateOrderResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x19\n" +
 	"\border_id\x18\x02 \x01(\tR\aorderId\x12!\n" +
@@ -722,13 +863,23 @@ const file_order_proto_rawDesc = "" +
 	"\x06reason\x18\x03 \x01(\tR\x06reason\"E\n" +
 	"\x13CancelOrderResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
-	"\x05error\x18\x02 \x01(\tR\x05error2\x9a\x02\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"w\n" +
+	"\x18UpdateOrderStatusRequest\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\tR\aorderId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x14\n" +
+	"\x05actor\x18\x03 \x01(\tR\x05actor\x12\x12\n" +
+	"\x04note\x18\x04 \x01(\tR\x04note\"v\n" +
+	"\x19UpdateOrderStatusResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12'\n" +
+	"\x0fprevious_status\x18\x02 \x01(\tR\x0epreviousStatus\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status2\xf2\x02\n" +
 	"\fOrderService\x12D\n" +
 	"\vCreateOrder\x12\x19.order.CreateOrderRequest\x1a\x1a.order.CreateOrderResponse\x12;\n" +
 	"\bGetOrder\x12\x16.order.GetOrderRequest\x1a\x17.order.GetOrderResponse\x12A\n" +
 	"\n" +
 	"ListOrders\x12\x18.order.ListOrdersRequest\x1a\x19.order.ListOrdersResponse\x12D\n" +
-	"\vCancelOrder\x12\x19.order.CancelOrderRequest\x1a\x1a.order.CancelOrderResponseB3Z1github.com/yourusername/microservices/proto/orderb\x06proto3"
+	"\vCancelOrder\x12\x19.order.CancelOrderRequest\x1a\x1a.order.CancelOrderResponse\x12V\n" +
+	"\x11UpdateOrderStatus\x12\x1f.order.UpdateOrderStatusRequest\x1a .order.UpdateOrderStatusResponseB3Z1github.com/yourusername/microservices/proto/orderb\x06proto3"
 
 var (
 	file_order_proto_rawDescOnce sync.Once
@@ -742,36 +893,40 @@ func file_order_proto_rawDescGZIP() []byte {
 	return file_order_proto_rawDescData
 }
 
-var file_order_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_order_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
 var file_order_proto_goTypes = []any{
-	(*OrderItem)(nil),           // 0: order.OrderItem
-	(*CreateOrderRequest)(nil),  // 1: order.CreateOrderRequest
-	(*CreateOrderResponse)(nil), // 2: order.CreateOrderResponse
-	(*GetOrderRequest)(nil),     // 3: order.GetOrderRequest
-	(*OrderItemDetail)(nil),     // 4: order.OrderItemDetail
-	(*GetOrderResponse)(nil),    // 5: order.GetOrderResponse
-	(*ListOrdersRequest)(nil),   // 6: order.ListOrdersRequest
-	(*ListOrdersResponse)(nil),  // 7: order.ListOrdersResponse
-	(*CancelOrderRequest)(nil),  // 8: order.CancelOrderRequest
-	(*CancelOrderResponse)(nil), // 9: order.CancelOrderResponse
+	(*OrderItem)(nil),                 // 0: order.OrderItem
+	(*CreateOrderRequest)(nil),        // 1: order.CreateOrderRequest
+	(*CreateOrderResponse)(nil),       // 2: order.CreateOrderResponse
+	(*GetOrderRequest)(nil),           // 3: order.GetOrderRequest
+	(*OrderItemDetail)(nil),           // 4: order.OrderItemDetail
+	(*GetOrderResponse)(nil),          // 5: order.GetOrderResponse
+	(*ListOrdersRequest)(nil),         // 6: order.ListOrdersRequest
+	(*ListOrdersResponse)(nil),        // 7: order.ListOrdersResponse
+	(*CancelOrderRequest)(nil),        // 8: order.CancelOrderRequest
+	(*CancelOrderResponse)(nil),       // 9: order.CancelOrderResponse
+	(*UpdateOrderStatusRequest)(nil),  // 10: order.UpdateOrderStatusRequest
+	(*UpdateOrderStatusResponse)(nil), // 11: order.UpdateOrderStatusResponse
 }
 var file_order_proto_depIdxs = []int32{
-	0, // 0: order.CreateOrderRequest.items:type_name -> order.OrderItem
-	4, // 1: order.GetOrderResponse.items:type_name -> order.OrderItemDetail
-	5, // 2: order.ListOrdersResponse.orders:type_name -> order.GetOrderResponse
-	1, // 3: order.OrderService.CreateOrder:input_type -> order.CreateOrderRequest
-	3, // 4: order.OrderService.GetOrder:input_type -> order.GetOrderRequest
-	6, // 5: order.OrderService.ListOrders:input_type -> order.ListOrdersRequest
-	8, // 6: order.OrderService.CancelOrder:input_type -> order.CancelOrderRequest
-	2, // 7: order.OrderService.CreateOrder:output_type -> order.CreateOrderResponse
-	5, // 8: order.OrderService.GetOrder:output_type -> order.GetOrderResponse
-	7, // 9: order.OrderService.ListOrders:output_type -> order.ListOrdersResponse
-	9, // 10: order.OrderService.CancelOrder:output_type -> order.CancelOrderResponse
-	7, // [7:11] is the sub-list for method output_type
-	3, // [3:7] is the sub-list for method input_type
-	3, // [3:3] is the sub-list for extension type_name
-	3, // [3:3] is the sub-list for extension extendee
-	0, // [0:3] is the sub-list for field type_name
+	0,  // 0: order.CreateOrderRequest.items:type_name -> order.OrderItem
+	4,  // 1: order.GetOrderResponse.items:type_name -> order.OrderItemDetail
+	5,  // 2: order.ListOrdersResponse.orders:type_name -> order.GetOrderResponse
+	1,  // 3: order.OrderService.CreateOrder:input_type -> order.CreateOrderRequest
+	3,  // 4: order.OrderService.GetOrder:input_type -> order.GetOrderRequest
+	6,  // 5: order.OrderService.ListOrders:input_type -> order.ListOrdersRequest
+	8,  // 6: order.OrderService.CancelOrder:input_type -> order.CancelOrderRequest
+	10, // 7: order.OrderService.UpdateOrderStatus:input_type -> order.UpdateOrderStatusRequest
+	2,  // 8: order.OrderService.CreateOrder:output_type -> order.CreateOrderResponse
+	5,  // 9: order.OrderService.GetOrder:output_type -> order.GetOrderResponse
+	7,  // 10: order.OrderService.ListOrders:output_type -> order.ListOrdersResponse
+	9,  // 11: order.OrderService.CancelOrder:output_type -> order.CancelOrderResponse
+	11, // 12: order.OrderService.UpdateOrderStatus:output_type -> order.UpdateOrderStatusResponse
+	8,  // [8:13] is the sub-list for method output_type
+	3,  // [3:8] is the sub-list for method input_type
+	3,  // [3:3] is the sub-list for extension type_name
+	3,  // [3:3] is the sub-list for extension extendee
+	0,  // [0:3] is the sub-list for field type_name
 }
 
 func init() { file_order_proto_init() }
@@ -785,7 +940,7 @@ func file_order_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_order_proto_rawDesc), len(file_order_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   10,
+			NumMessages:   12,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -322,8 +322,11 @@ func (x *InventoryItem) GetQuantity() int32 {
 }
 
 type ReserveInventoryRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Items         []*InventoryItem       `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Items []*InventoryItem       `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	// Optional UUIDv7 chosen by the caller, so a reserve whose response was lost
+	// can still be released. Reusing an id returns the existing hold.
+	ReservationId string `protobuf:"bytes,2,opt,name=reservation_id,json=reservationId,proto3" json:"reservation_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -365,10 +368,19 @@ func (x *ReserveInventoryRequest) GetItems() []*InventoryItem {
 	return nil
 }
 
+func (x *ReserveInventoryRequest) GetReservationId() string {
+	if x != nil {
+		return x.ReservationId
+	}
+	return ""
+}
+
 type ReserveInventoryResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
 	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	ReservationId string                 `protobuf:"bytes,3,opt,name=reservation_id,json=reservationId,proto3" json:"reservation_id,omitempty"`
+	ExpiresAt     int64                  `protobuf:"varint,4,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"` // unix seconds
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -417,9 +429,26 @@ func (x *ReserveInventoryResponse) GetError() string {
 	return ""
 }
 
+func (x *ReserveInventoryResponse) GetReservationId() string {
+	if x != nil {
+		return x.ReservationId
+	}
+	return ""
+}
+
+func (x *ReserveInventoryResponse) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+// ReleaseInventory drops a hold. Releasing by reservation_id is idempotent;
+// items is only for holds taken before reservation ids existed.
 type ReleaseInventoryRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Items         []*InventoryItem       `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	ReservationId string                 `protobuf:"bytes,2,opt,name=reservation_id,json=reservationId,proto3" json:"reservation_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -461,6 +490,13 @@ func (x *ReleaseInventoryRequest) GetItems() []*InventoryItem {
 	return nil
 }
 
+func (x *ReleaseInventoryRequest) GetReservationId() string {
+	if x != nil {
+		return x.ReservationId
+	}
+	return ""
+}
+
 type ReleaseInventoryResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
@@ -505,6 +541,185 @@ func (x *ReleaseInventoryResponse) GetSuccess() bool {
 	return false
 }
 
+// CommitInventory turns a reservation into a stock decrement:
+// quantity -= n, reserved_quantity -= n.
+type CommitInventoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ReservationId string                 `protobuf:"bytes,1,opt,name=reservation_id,json=reservationId,proto3" json:"reservation_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommitInventoryRequest) Reset() {
+	*x = CommitInventoryRequest{}
+	mi := &file_product_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommitInventoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommitInventoryRequest) ProtoMessage() {}
+
+func (x *CommitInventoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_product_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommitInventoryRequest.ProtoReflect.Descriptor instead.
+func (*CommitInventoryRequest) Descriptor() ([]byte, []int) {
+	return file_product_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *CommitInventoryRequest) GetReservationId() string {
+	if x != nil {
+		return x.ReservationId
+	}
+	return ""
+}
+
+type CommitInventoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommitInventoryResponse) Reset() {
+	*x = CommitInventoryResponse{}
+	mi := &file_product_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommitInventoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommitInventoryResponse) ProtoMessage() {}
+
+func (x *CommitInventoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_product_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommitInventoryResponse.ProtoReflect.Descriptor instead.
+func (*CommitInventoryResponse) Descriptor() ([]byte, []int) {
+	return file_product_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *CommitInventoryResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+// RestockInventory returns committed stock, e.g. when a completed order is cancelled.
+type RestockInventoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*InventoryItem       `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestockInventoryRequest) Reset() {
+	*x = RestockInventoryRequest{}
+	mi := &file_product_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestockInventoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestockInventoryRequest) ProtoMessage() {}
+
+func (x *RestockInventoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_product_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestockInventoryRequest.ProtoReflect.Descriptor instead.
+func (*RestockInventoryRequest) Descriptor() ([]byte, []int) {
+	return file_product_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *RestockInventoryRequest) GetItems() []*InventoryItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type RestockInventoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestockInventoryResponse) Reset() {
+	*x = RestockInventoryResponse{}
+	mi := &file_product_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestockInventoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestockInventoryResponse) ProtoMessage() {}
+
+func (x *RestockInventoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_product_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestockInventoryResponse.ProtoReflect.Descriptor instead.
+func (*RestockInventoryResponse) Descriptor() ([]byte, []int) {
+	return file_product_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *RestockInventoryResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
 var File_product_proto protoreflect.FileDescriptor
 
 const file_product_proto_rawDesc = "" +
@@ -532,22 +747,37 @@ const file_product_proto_rawDesc = "" +
 	"\rInventoryItem\x12\x1d\n" +
 	"\n" +
 	"product_id\x18\x01 \x01(\tR\tproductId\x12\x1a\n" +
-	"\bquantity\x18\x02 \x01(\x05R\bquantity\"G\n" +
+	"\bquantity\x18\x02 \x01(\x05R\bquantity\"n\n" +
 	"\x17ReserveInventoryRequest\x12,\n" +
-	"\x05items\x18\x01 \x03(\v2\x16.product.InventoryItemR\x05items\"J\n" +
+	"\x05items\x18\x01 \x03(\v2\x16.product.InventoryItemR\x05items\x12%\n" +
+	"\x0ereservation_id\x18\x02 \x01(\tR\rreservationId\"\x90\x01\n" +
 	"\x18ReserveInventoryResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
-	"\x05error\x18\x02 \x01(\tR\x05error\"G\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12%\n" +
+	"\x0ereservation_id\x18\x03 \x01(\tR\rreservationId\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x04 \x01(\x03R\texpiresAt\"n\n" +
 	"\x17ReleaseInventoryRequest\x12,\n" +
-	"\x05items\x18\x01 \x03(\v2\x16.product.InventoryItemR\x05items\"4\n" +
+	"\x05items\x18\x01 \x03(\v2\x16.product.InventoryItemR\x05items\x12%\n" +
+	"\x0ereservation_id\x18\x02 \x01(\tR\rreservationId\"4\n" +
 	"\x18ReleaseInventoryResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess2\xdc\x02\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"?\n" +
+	"\x16CommitInventoryRequest\x12%\n" +
+	"\x0ereservation_id\x18\x01 \x01(\tR\rreservationId\"3\n" +
+	"\x17CommitInventoryResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"G\n" +
+	"\x17RestockInventoryRequest\x12,\n" +
+	"\x05items\x18\x01 \x03(\v2\x16.product.InventoryItemR\x05items\"4\n" +
+	"\x18RestockInventoryResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess2\x8b\x04\n" +
 	"\x0eProductService\x12E\n" +
 	"\n" +
 	"GetProduct\x12\x1a.product.GetProductRequest\x1a\x1b.product.GetProductResponse\x12Q\n" +
 	"\x0eCheckInventory\x12\x1e.product.CheckInventoryRequest\x1a\x1f.product.CheckInventoryResponse\x12W\n" +
 	"\x10ReserveInventory\x12 .product.ReserveInventoryRequest\x1a!.product.ReserveInventoryResponse\x12W\n" +
-	"\x10ReleaseInventory\x12 .product.ReleaseInventoryRequest\x1a!.product.ReleaseInventoryResponseB5Z3github.com/yourusername/microservices/proto/productb\x06proto3"
+	"\x10ReleaseInventory\x12 .product.ReleaseInventoryRequest\x1a!.product.ReleaseInventoryResponse\x12T\n" +
+	"\x0fCommitInventory\x12\x1f.product.CommitInventoryRequest\x1a .product.CommitInventoryResponse\x12W\n" +
+	"\x10RestockInventory\x12 .product.RestockInventoryRequest\x1a!.product.RestockInventoryResponseB5Z3github.com/yourusername/microservices/proto/productb\x06proto3"
 
 var (
 	file_product_proto_rawDescOnce sync.Once
@@ -561,7 +791,7 @@ func file_product_proto_rawDescGZIP() []byte {
 	return file_product_proto_rawDescData
 }
 
-var file_product_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_product_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
 var file_product_proto_goTypes = []any{
 	(*GetProductRequest)(nil),        // 0: product.GetProductRequest
 	(*GetProductResponse)(nil),       // 1: product.GetProductResponse
@@ -572,23 +802,32 @@ var file_product_proto_goTypes = []any{
 	(*ReserveInventoryResponse)(nil), // 6: product.ReserveInventoryResponse
 	(*ReleaseInventoryRequest)(nil),  // 7: product.ReleaseInventoryRequest
 	(*ReleaseInventoryResponse)(nil), // 8: product.ReleaseInventoryResponse
+	(*CommitInventoryRequest)(nil),   // 9: product.CommitInventoryRequest
+	(*CommitInventoryResponse)(nil),  // 10: product.CommitInventoryResponse
+	(*RestockInventoryRequest)(nil),  // 11: product.RestockInventoryRequest
+	(*RestockInventoryResponse)(nil), // 12: product.RestockInventoryResponse
 }
 var file_product_proto_depIdxs = []int32{
-	4, // 0: product.ReserveInventoryRequest.items:type_name -> product.InventoryItem
-	4, // 1: product.ReleaseInventoryRequest.items:type_name -> product.InventoryItem
-	0, // 2: product.ProductService.GetProduct:input_type -> product.GetProductRequest
-	2, // 3: product.ProductService.CheckInventory:input_type -> product.CheckInventoryRequest
-	5, // 4: product.ProductService.ReserveInventory:input_type -> product.ReserveInventoryRequest
-	7, // 5: product.ProductService.ReleaseInventory:input_type -> product.ReleaseInventoryRequest
-	1, // 6: product.ProductService.GetProduct:output_type -> product.GetProductResponse
-	3, // 7: product.ProductService.CheckInventory:output_type -> product.CheckInventoryResponse
-	6, // 8: product.ProductService.ReserveInventory:output_type -> product.ReserveInventoryResponse
-	8, // 9: product.ProductService.ReleaseInventory:output_type -> product.ReleaseInventoryResponse
-	6, // [6:10] is the sub-list for method output_type
-	2, // [2:6] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	4,  // 0: product.ReserveInventoryRequest.items:type_name -> product.InventoryItem
+	4,  // 1: product.ReleaseInventoryRequest.items:type_name -> product.InventoryItem
+	4,  // 2: product.RestockInventoryRequest.items:type_name -> product.InventoryItem
+	0,  // 3: product.ProductService.GetProduct:input_type -> product.GetProductRequest
+	2,  // 4: product.ProductService.CheckInventory:input_type -> product.CheckInventoryRequest
+	5,  // 5: product.ProductService.ReserveInventory:input_type -> product.ReserveInventoryRequest
+	7,  // 6: product.ProductService.ReleaseInventory:input_type -> product.ReleaseInventoryRequest
+	9,  // 7: product.ProductService.CommitInventory:input_type -> product.CommitInventoryRequest
+	11, // 8: product.ProductService.RestockInventory:input_type -> product.RestockInventoryRequest
+	1,  // 9: product.ProductService.GetProduct:output_type -> product.GetProductResponse
+	3,  // 10: product.ProductService.CheckInventory:output_type -> product.CheckInventoryResponse
+	6,  // 11: product.ProductService.ReserveInventory:output_type -> product.ReserveInventoryResponse
+	8,  // 12: product.ProductService.ReleaseInventory:output_type -> product.ReleaseInventoryResponse
+	10, // 13: product.ProductService.CommitInventory:output_type -> product.CommitInventoryResponse
+	12, // 14: product.ProductService.RestockInventory:output_type -> product.RestockInventoryResponse
+	9,  // [9:15] is the sub-list for method output_type
+	3,  // [3:9] is the sub-list for method input_type
+	3,  // [3:3] is the sub-list for extension type_name
+	3,  // [3:3] is the sub-list for extension extendee
+	0,  // [0:3] is the sub-list for field type_name
 }
 
 func init() { file_product_proto_init() }
@@ -602,7 +841,7 @@ func file_product_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_product_proto_rawDesc), len(file_product_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   9,
+			NumMessages:   13,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -23,6 +23,8 @@ const (
 	ProductService_CheckInventory_FullMethodName   = "/product.ProductService/CheckInventory"
 	ProductService_ReserveInventory_FullMethodName = "/product.ProductService/ReserveInventory"
 	ProductService_ReleaseInventory_FullMethodName = "/product.ProductService/ReleaseInventory"
+	ProductService_CommitInventory_FullMethodName  = "/product.ProductService/CommitInventory"
+	ProductService_RestockInventory_FullMethodName = "/product.ProductService/RestockInventory"
 )
 
 // ProductServiceClient is the client API for ProductService service.
@@ -33,6 +35,8 @@ type ProductServiceClient interface {
 	CheckInventory(ctx context.Context, in *CheckInventoryRequest, opts ...grpc.CallOption) (*CheckInventoryResponse, error)
 	ReserveInventory(ctx context.Context, in *ReserveInventoryRequest, opts ...grpc.CallOption) (*ReserveInventoryResponse, error)
 	ReleaseInventory(ctx context.Context, in *ReleaseInventoryRequest, opts ...grpc.CallOption) (*ReleaseInventoryResponse, error)
+	CommitInventory(ctx context.Context, in *CommitInventoryRequest, opts ...grpc.CallOption) (*CommitInventoryResponse, error)
+	RestockInventory(ctx context.Context, in *RestockInventoryRequest, opts ...grpc.CallOption) (*RestockInventoryResponse, error)
 }
 
 type productServiceClient struct {
@@ -83,6 +87,26 @@ func (c *productServiceClient) ReleaseInventory(ctx context.Context, in *Release
 	return out, nil
 }
 
+func (c *productServiceClient) CommitInventory(ctx context.Context, in *CommitInventoryRequest, opts ...grpc.CallOption) (*CommitInventoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CommitInventoryResponse)
+	err := c.cc.Invoke(ctx, ProductService_CommitInventory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) RestockInventory(ctx context.Context, in *RestockInventoryRequest, opts ...grpc.CallOption) (*RestockInventoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RestockInventoryResponse)
+	err := c.cc.Invoke(ctx, ProductService_RestockInventory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ProductServiceServer is the server API for ProductService service.
 // All implementations must embed UnimplementedProductServiceServer
 // for forward compatibility.
@@ -91,6 +115,8 @@ type ProductServiceServer interface {
 	CheckInventory(context.Context, *CheckInventoryRequest) (*CheckInventoryResponse, error)
 	ReserveInventory(context.Context, *ReserveInventoryRequest) (*ReserveInventoryResponse, error)
 	ReleaseInventory(context.Context, *ReleaseInventoryRequest) (*ReleaseInventoryResponse, error)
+	CommitInventory(context.Context, *CommitInventoryRequest) (*CommitInventoryResponse, error)
+	RestockInventory(context.Context, *RestockInventoryRequest) (*RestockInventoryResponse, error)
 	mustEmbedUnimplementedProductServiceServer()
 }
 
@@ -113,6 +139,12 @@ func (UnimplementedProductServiceServer) ReserveInventory(context.Context, *Rese
 func (UnimplementedProductServiceServer) ReleaseInventory(context.Context, *ReleaseInventoryRequest) (*ReleaseInventoryResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method ReleaseInventory not implemented")
 }
+func (UnimplementedProductServiceServer) CommitInventory(context.Context, *CommitInventoryRequest) (*CommitInventoryResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CommitInventory not implemented")
+}
+func (UnimplementedProductServiceServer) RestockInventory(context.Context, *RestockInventoryRequest) (*RestockInventoryResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RestockInventory not implemented")
+}
 func (UnimplementedProductServiceServer) mustEmbedUnimplementedProductServiceServer() {}
 func (UnimplementedProductServiceServer) testEmbeddedByValue()                        {}
 
@@ -206,6 +238,42 @@ func _ProductService_ReleaseInventory_Handler(srv interface{}, ctx context.Conte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ProductService_CommitInventory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CommitInventoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).CommitInventory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProductService_CommitInventory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).CommitInventory(ctx, req.(*CommitInventoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_RestockInventory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestockInventoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).RestockInventory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProductService_RestockInventory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).RestockInventory(ctx, req.(*RestockInventoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ProductService_ServiceDesc is the grpc.ServiceDesc for ProductService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -229,6 +297,14 @@ var ProductService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ReleaseInventory",
 			Handler:    _ProductService_ReleaseInventory_Handler,
 		},
+		{
+			MethodName: "CommitInventory",
+			Handler:    _ProductService_CommitInventory_Handler,
+		},
+		{
+			MethodName: "RestockInventory",
+			Handler:    _ProductService_RestockInventory_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "product.proto",
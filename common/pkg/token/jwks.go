@@ -0,0 +1,162 @@
+package token
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const JWKSPath = "/.well-known/jwks.json"
+
+// ErrJWKSUnavailable is returned, wrapped, when the key set could not be
+// fetched, so a caller can tell an outage from a bad token.
+var ErrJWKSUnavailable = errors.New("token: jwks unavailable")
+
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+func newJWK(kid string, key *rsa.PublicKey) JWK {
+	return JWK{
+		Kty: "RSA",
+		Use: "sig",
+		Alg: jwt.SigningMethodRS256.Alg(),
+		Kid: kid,
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func (j JWK) publicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(j.N)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := base64.RawURLEncoding.DecodeString(j.E)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(new(big.Int).SetBytes(e).Int64()),
+	}, nil
+}
+
+// RemoteKeySet verifies tokens against the JWKS published by auth-service.
+// Keys are fetched lazily and refetched when an unknown kid shows up, at most
+// once per minRefresh, so a rotated-in key is picked up without a restart.
+type RemoteKeySet struct {
+	url        string
+	client     *http.Client
+	minRefresh time.Duration
+
+	mu        sync.RWMutex
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+}
+
+func NewRemoteKeySet(url string, minRefresh time.Duration) *RemoteKeySet {
+	return &RemoteKeySet{
+		url:        url,
+		client:     &http.Client{Timeout: 5 * time.Second},
+		minRefresh: minRefresh,
+		keys:       map[string]*rsa.PublicKey{},
+	}
+}
+
+func (r *RemoteKeySet) Parse(ctx context.Context, tokenString string) (*jwt.Token, error) {
+	return parse(tokenString, func(kid string) (*rsa.PublicKey, error) {
+		return r.key(ctx, kid)
+	})
+}
+
+func (r *RemoteKeySet) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	r.mu.RLock()
+	key, ok := r.keys[kid]
+	stale := time.Since(r.fetchedAt) >= r.minRefresh
+	r.mu.RUnlock()
+
+	if ok {
+		return key, nil
+	}
+
+	if !stale {
+		return nil, fmt.Errorf("token: unknown key id %q", kid)
+	}
+
+	if err := r.refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if key, ok := r.keys[kid]; ok {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("token: unknown key id %q", kid)
+}
+
+func (r *RemoteKeySet) refresh(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.url, nil)
+	if err != nil {
+		return fmt.Errorf("%w: build request: %w", ErrJWKSUnavailable, err)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: fetch: %w", ErrJWKSUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: unexpected status %d", ErrJWKSUnavailable, resp.StatusCode)
+	}
+
+	var set JWKS
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return fmt.Errorf("%w: decode: %w", ErrJWKSUnavailable, err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Kty != "RSA" || jwk.Kid == "" {
+			continue
+		}
+
+		key, err := jwk.publicKey()
+		if err != nil {
+			return fmt.Errorf("%w: decode key %q: %w", ErrJWKSUnavailable, jwk.Kid, err)
+		}
+
+		keys[jwk.Kid] = key
+	}
+
+	r.mu.Lock()
+	r.keys = keys
+	r.fetchedAt = time.Now()
+	r.mu.Unlock()
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package token
+
+import (
+	"crypto/rsa"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Key describes one RSA key pair. Retired keys only need PublicKeyPath so
+// tokens they signed keep verifying until they expire.
+type Key struct {
+	ID             string `yaml:"id"`
+	PrivateKeyPath string `yaml:"private_key_path"`
+	PublicKeyPath  string `yaml:"public_key_path"`
+}
+
+type Config struct {
+	SigningKeyID string `yaml:"signing_key_id"`
+	Keys         []Key  `yaml:"keys"`
+}
+
+// KeySet signs tokens with the active key and verifies tokens signed by any
+// key it knows about, selected by the "kid" header.
+type KeySet struct {
+	signingKeyID string
+	signingKey   *rsa.PrivateKey
+	publicKeys   map[string]*rsa.PublicKey
+}
+
+func NewKeySet(cfg Config) (*KeySet, error) {
+	if cfg.SigningKeyID == "" {
+		return nil, fmt.Errorf("token: signing_key_id is required")
+	}
+
+	ks := &KeySet{
+		signingKeyID: cfg.SigningKeyID,
+		publicKeys:   make(map[string]*rsa.PublicKey, len(cfg.Keys)),
+	}
+
+	for _, k := range cfg.Keys {
+		if k.ID == "" {
+			return nil, fmt.Errorf("token: key id is required")
+		}
+
+		if _, ok := ks.publicKeys[k.ID]; ok {
+			return nil, fmt.Errorf("token: duplicate key id %q", k.ID)
+		}
+
+		switch {
+		case k.PrivateKeyPath != "":
+			pemBytes, err := os.ReadFile(k.PrivateKeyPath)
+			if err != nil {
+				return nil, fmt.Errorf("token: read private key %q: %w", k.ID, err)
+			}
+
+			privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(pemBytes)
+			if err != nil {
+				return nil, fmt.Errorf("token: parse private key %q: %w", k.ID, err)
+			}
+
+			ks.publicKeys[k.ID] = &privateKey.PublicKey
+			if k.ID == cfg.SigningKeyID {
+				ks.signingKey = privateKey
+			}
+		case k.PublicKeyPath != "":
+			pemBytes, err := os.ReadFile(k.PublicKeyPath)
+			if err != nil {
+				return nil, fmt.Errorf("token: read public key %q: %w", k.ID, err)
+			}
+
+			publicKey, err := jwt.ParseRSAPublicKeyFromPEM(pemBytes)
+			if err != nil {
+				return nil, fmt.Errorf("token: parse public key %q: %w", k.ID, err)
+			}
+
+			ks.publicKeys[k.ID] = publicKey
+		default:
+			return nil, fmt.Errorf("token: key %q has neither private_key_path nor public_key_path", k.ID)
+		}
+	}
+
+	if ks.signingKey == nil {
+		return nil, fmt.Errorf("token: no private key configured for signing key id %q", cfg.SigningKeyID)
+	}
+
+	return ks, nil
+}
+
+// Sign returns an RS256 token carrying the signing key id in its header.
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = ks.signingKeyID
+
+	return token.SignedString(ks.signingKey)
+}
+
+func (ks *KeySet) Parse(tokenString string) (*jwt.Token, error) {
+	return parse(tokenString, func(kid string) (*rsa.PublicKey, error) {
+		publicKey, ok := ks.publicKeys[kid]
+		if !ok {
+			return nil, fmt.Errorf("token: unknown key id %q", kid)
+		}
+
+		return publicKey, nil
+	})
+}
+
+// JWKS returns every verification key, the signing key first.
+func (ks *KeySet) JWKS() JWKS {
+	ids := make([]string, 0, len(ks.publicKeys))
+	for id := range ks.publicKeys {
+		if id != ks.signingKeyID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	set := JWKS{Keys: []JWK{newJWK(ks.signingKeyID, ks.publicKeys[ks.signingKeyID])}}
+	for _, id := range ids {
+		set.Keys = append(set.Keys, newJWK(id, ks.publicKeys[id]))
+	}
+
+	return set
+}
+
+func parse(tokenString string, lookup func(kid string) (*rsa.PublicKey, error)) (*jwt.Token, error) {
+	return jwt.Parse(tokenString, func(t *jwt.Token) (any, error) {
+		kid, ok := t.Header["kid"].(string)
+		if !ok || kid == "" {
+			return nil, fmt.Errorf("token: missing kid header")
+		}
+
+		return lookup(kid)
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}))
+}
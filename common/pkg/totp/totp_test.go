@@ -0,0 +1,84 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfc6238Secret is the SHA1 seed of the RFC 6238 appendix B test vectors.
+var rfc6238Secret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestCodeMatchesRFC6238Vectors(t *testing.T) {
+	// The RFC lists 8 digit codes; the 6 digit code is their last six digits
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+		{unix: 20000000000, want: "353130"},
+	}
+
+	for _, tt := range tests {
+		code, err := Code(rfc6238Secret, Step(time.Unix(tt.unix, 0)))
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, code, "T=%d", tt.unix)
+	}
+}
+
+func TestCodeAcceptsLowercaseUnpaddedSecret(t *testing.T) {
+	secret := strings.ToLower(strings.TrimRight(rfc6238Secret, "="))
+
+	code, err := Code(secret, Step(time.Unix(59, 0)))
+	require.NoError(t, err)
+	assert.Equal(t, "287082", code)
+
+	_, err = Code("not base32!", 1)
+	assert.Error(t, err)
+}
+
+func TestValidateWithinSkew(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := Step(now)
+
+	previous, err := Code(rfc6238Secret, step-1)
+	require.NoError(t, err)
+
+	matched, ok := Validate(rfc6238Secret, "050471", now, 0)
+	assert.True(t, ok)
+	assert.Equal(t, step, matched)
+
+	_, ok = Validate(rfc6238Secret, previous, now, 0)
+	assert.False(t, ok)
+
+	matched, ok = Validate(rfc6238Secret, previous, now, 1)
+	assert.True(t, ok)
+	assert.Equal(t, step-1, matched)
+
+	for _, code := range []string{"", "05047", "0504711", "000000"} {
+		_, ok := Validate(rfc6238Secret, code, now, 1)
+		assert.False(t, ok, code)
+	}
+}
+
+func TestNewSecretRoundTrips(t *testing.T) {
+	secret, err := NewSecret()
+	require.NoError(t, err)
+	assert.Len(t, secret, 32)
+
+	code, err := Code(secret, Step(time.Now()))
+	require.NoError(t, err)
+	assert.Len(t, code, Digits)
+
+	uri := URI("Go-Kill", "jane.doe@example.com", secret)
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/Go-Kill:jane.doe@example.com?"), uri)
+	assert.Contains(t, uri, "secret="+secret)
+}
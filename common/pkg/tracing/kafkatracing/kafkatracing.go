@@ -0,0 +1,124 @@
+// Package kafkatracing moves the trace context through Kafka message
+// headers, so that a consumer's span continues the producer's trace.
+package kafkatracing
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/linggaaskaedo/go-kill/common/pkg/tracing"
+
+	"github.com/IBM/sarama"
+	"go.opentelemetry.io/otel"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ProducerMessageCarrier carries the trace context in the headers of a
+// message being sent.
+type ProducerMessageCarrier struct {
+	Msg *sarama.ProducerMessage
+}
+
+func (c ProducerMessageCarrier) Get(key string) string {
+	for _, h := range c.Msg.Headers {
+		if string(h.Key) == key {
+			return string(h.Value)
+		}
+	}
+
+	return ""
+}
+
+func (c ProducerMessageCarrier) Set(key, value string) {
+	for i, h := range c.Msg.Headers {
+		if string(h.Key) == key {
+			c.Msg.Headers[i].Value = []byte(value)
+			return
+		}
+	}
+
+	c.Msg.Headers = append(c.Msg.Headers, sarama.RecordHeader{Key: []byte(key), Value: []byte(value)})
+}
+
+func (c ProducerMessageCarrier) Keys() []string {
+	keys := make([]string, len(c.Msg.Headers))
+	for i, h := range c.Msg.Headers {
+		keys[i] = string(h.Key)
+	}
+
+	return keys
+}
+
+// ConsumerMessageCarrier reads the trace context from the headers of a
+// received message.
+type ConsumerMessageCarrier struct {
+	Msg *sarama.ConsumerMessage
+}
+
+func (c ConsumerMessageCarrier) Get(key string) string {
+	for _, h := range c.Msg.Headers {
+		if h != nil && string(h.Key) == key {
+			return string(h.Value)
+		}
+	}
+
+	return ""
+}
+
+// Set is a no-op; received messages are not changed.
+func (c ConsumerMessageCarrier) Set(key, value string) {}
+
+func (c ConsumerMessageCarrier) Keys() []string {
+	keys := make([]string, 0, len(c.Msg.Headers))
+	for _, h := range c.Msg.Headers {
+		if h != nil {
+			keys = append(keys, string(h.Key))
+		}
+	}
+
+	return keys
+}
+
+// InjectMessage writes the trace context of ctx into msg's headers.
+func InjectMessage(ctx context.Context, msg *sarama.ProducerMessage) {
+	otel.GetTextMapPropagator().Inject(ctx, ProducerMessageCarrier{Msg: msg})
+}
+
+// ExtractMessage returns ctx with the trace context sent in msg's headers,
+// so that spans started from it continue the producer's trace.
+func ExtractMessage(ctx context.Context, msg *sarama.ConsumerMessage) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, ConsumerMessageCarrier{Msg: msg})
+}
+
+// StartSendSpan starts the span of sending msg and writes its trace context
+// into msg's headers, so that the consumer's span joins the trace. End it
+// with tracing.End once Kafka has acknowledged the message.
+func StartSendSpan(ctx context.Context, msg *sarama.ProducerMessage) (context.Context, trace.Span) {
+	ctx, span := tracing.Tracer().Start(ctx, "send "+msg.Topic,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKafka,
+			semconv.MessagingOperationTypeSend,
+			semconv.MessagingDestinationName(msg.Topic),
+		),
+	)
+	InjectMessage(ctx, msg)
+
+	return ctx, span
+}
+
+// StartProcessSpan starts the span of handling msg, continuing the trace it
+// was sent in. End it with tracing.End once the message is handled.
+func StartProcessSpan(ctx context.Context, msg *sarama.ConsumerMessage) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(ExtractMessage(ctx, msg), "process "+msg.Topic,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKafka,
+			semconv.MessagingOperationTypeProcess,
+			semconv.MessagingDestinationName(msg.Topic),
+			semconv.MessagingDestinationPartitionID(strconv.Itoa(int(msg.Partition))),
+			semconv.MessagingKafkaOffset(int(msg.Offset)),
+		),
+	)
+}
@@ -0,0 +1,123 @@
+package kafkatracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func init() {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+}
+
+// received turns a sent message into the one a consumer would get.
+func received(msg *sarama.ProducerMessage) *sarama.ConsumerMessage {
+	// sarama may hand out nil headers, the carrier has to skip them
+	headers := []*sarama.RecordHeader{nil}
+	for i := range msg.Headers {
+		headers = append(headers, &msg.Headers[i])
+	}
+
+	return &sarama.ConsumerMessage{Topic: msg.Topic, Partition: 2, Offset: 42, Headers: headers}
+}
+
+func remoteParent() trace.SpanContext {
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x0a},
+		SpanID:     trace.SpanID{0x0b},
+		TraceFlags: trace.FlagsSampled,
+	})
+}
+
+func TestInjectExtractRoundTrip(t *testing.T) {
+	parent := remoteParent()
+	ctx := trace.ContextWithSpanContext(context.Background(), parent)
+
+	msg := &sarama.ProducerMessage{
+		Topic:   "order.created",
+		Headers: []sarama.RecordHeader{{Key: []byte("event-type"), Value: []byte("created")}},
+	}
+	InjectMessage(ctx, msg)
+
+	carrier := ProducerMessageCarrier{Msg: msg}
+	assert.ElementsMatch(t, []string{"event-type", "traceparent"}, carrier.Keys())
+	assert.Equal(t, "created", carrier.Get("event-type"))
+
+	got := trace.SpanContextFromContext(ExtractMessage(context.Background(), received(msg)))
+
+	assert.True(t, got.IsRemote())
+	assert.Equal(t, parent.TraceID(), got.TraceID())
+	assert.Equal(t, parent.SpanID(), got.SpanID())
+	assert.True(t, got.IsSampled())
+}
+
+func TestExtractWithoutHeaders(t *testing.T) {
+	msg := &sarama.ConsumerMessage{Topic: "order.created", Headers: []*sarama.RecordHeader{nil}}
+
+	got := trace.SpanContextFromContext(ExtractMessage(context.Background(), msg))
+	assert.False(t, got.IsValid())
+}
+
+func TestProducerMessageCarrierReplacesHeader(t *testing.T) {
+	msg := &sarama.ProducerMessage{}
+	carrier := ProducerMessageCarrier{Msg: msg}
+
+	carrier.Set("traceparent", "first")
+	carrier.Set("traceparent", "second")
+
+	// A retried send must not carry two trace contexts
+	require.Len(t, msg.Headers, 1)
+	assert.Equal(t, "second", carrier.Get("traceparent"))
+	assert.Equal(t, "", carrier.Get("missing"))
+}
+
+func TestConsumerMessageCarrierIsReadOnly(t *testing.T) {
+	msg := &sarama.ConsumerMessage{Headers: []*sarama.RecordHeader{nil, {Key: []byte("a"), Value: []byte("1")}}}
+	carrier := ConsumerMessageCarrier{Msg: msg}
+
+	carrier.Set("b", "2")
+
+	assert.Equal(t, []string{"a"}, carrier.Keys())
+	assert.Equal(t, "1", carrier.Get("a"))
+	assert.Equal(t, "", carrier.Get("b"))
+}
+
+func TestSendAndProcessSpansShareTheTrace(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prev)
+		_ = provider.Shutdown(context.Background())
+	})
+
+	msg := &sarama.ProducerMessage{Topic: "order.created"}
+	_, send := StartSendSpan(context.Background(), msg)
+	send.End()
+
+	_, process := StartProcessSpan(context.Background(), received(msg))
+	process.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	sent, processed := spans[0], spans[1]
+	assert.Equal(t, "send order.created", sent.Name())
+	assert.Equal(t, trace.SpanKindProducer, sent.SpanKind())
+	assert.Equal(t, "process order.created", processed.Name())
+	assert.Equal(t, trace.SpanKindConsumer, processed.SpanKind())
+
+	assert.Equal(t, sent.SpanContext().TraceID(), processed.SpanContext().TraceID())
+	assert.Equal(t, sent.SpanContext().SpanID(), processed.Parent().SpanID())
+	assert.True(t, processed.Parent().IsRemote())
+}
@@ -0,0 +1,55 @@
+// Package tracing holds what the components share to trace calls across
+// services: the tracer they start spans with and the carrier that moves the
+// trace context through gRPC metadata. Kafka has its own in kafkatracing.
+// The tracer provider and propagator themselves are set by the tracer
+// component.
+package tracing
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+const instrumentationName = "github.com/linggaaskaedo/go-kill/common"
+
+// Tracer returns the tracer of the global provider. Spans started before
+// the tracer component has set the provider are not recorded.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// End records err, if any, on span and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+// MetadataCarrier carries the trace context in gRPC metadata.
+type MetadataCarrier metadata.MD
+
+func (c MetadataCarrier) Get(key string) string {
+	if vals := metadata.MD(c).Get(key); len(vals) > 0 {
+		return vals[0]
+	}
+
+	return ""
+}
+
+func (c MetadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c MetadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
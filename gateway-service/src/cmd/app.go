@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"time"
+
+	"github.com/linggaaskaedo/go-kill/common/app"
+	"github.com/linggaaskaedo/go-kill/common/component/grpcclient"
+	"github.com/linggaaskaedo/go-kill/common/component/http"
+	"github.com/linggaaskaedo/go-kill/common/component/server"
+	"github.com/linggaaskaedo/go-kill/common/component/tracer"
+	commonConfig "github.com/linggaaskaedo/go-kill/common/pkg/config"
+	"github.com/linggaaskaedo/go-kill/common/pkg/logger"
+	"github.com/linggaaskaedo/go-kill/common/pkg/metrics"
+	"github.com/linggaaskaedo/go-kill/common/pkg/middleware"
+	"github.com/linggaaskaedo/go-kill/common/pkg/token"
+	"github.com/linggaaskaedo/go-kill/gateway-service/src/internal/config"
+	restHandler "github.com/linggaaskaedo/go-kill/gateway-service/src/internal/handler/rest"
+)
+
+var (
+	minJitter int
+	maxJitter int
+)
+
+// @title			Go-Kill x Gateway Service
+// @version		1.0
+// @description	Microservices Architecture with Go
+// @termsOfService	http://swagger.io/terms/
+// @contact.name	API Support
+// @contact.url	http://www.swagger.io/support
+// @contact.email	support@swagger.io
+// @license.name	Apache 2.0
+// @license.url	http://www.apache.org/licenses/LICENSE-2.0.html
+//
+// @host			localhost:8000
+// @schemes		http https
+func main() {
+	src := commonConfig.Source{File: "config.yaml", EnvPrefix: "GATEWAY"}
+	src.RegisterFlags(flag.CommandLine)
+	flag.IntVar(&minJitter, "minSleep", DefaultMinJitter, "min. sleep duration during app initialization")
+	flag.IntVar(&maxJitter, "maxSleep", DefaultMaxJitter, "max. sleep duration during app initialization")
+	flag.Parse()
+
+	// Add sleep with Jitter to drag the the initialization time among instances
+	sleepWithJitter(minJitter, maxJitter)
+
+	// Load config
+	cfg, err := config.Load(src)
+	if err != nil {
+		panic(err)
+	}
+
+	// Initialize logger
+	log := logger.Init(cfg.Logger)
+
+	// Label the metrics with the service name
+	metrics.Init("gateway-service")
+
+	log.Info().Msg("Starting gateway service...")
+
+	// Create application with options
+	a := app.New(app.WithShutdownTimeout(10*time.Second), app.WithLogger(log))
+
+	// Added first so that it stops last, exporting the spans of shutdown
+	a.Add(tracer.NewTracerComponent(log, cfg.Tracer))
+
+	// Apply the reloadable settings when the config changes
+	watcher := commonConfig.NewWatcher(log, src, cfg)
+	watcher.Subscribe(func(cfg *config.Config) {
+		logger.SetLevel(cfg.Logger.Level)
+	})
+	a.Add(watcher)
+
+	authClientComp := grpcclient.NewGRPCClientComponent(log, cfg.GRPCClient["auth_service"])
+	a.Add(authClientComp, app.Name("auth_service"))
+
+	// Access tokens are verified here against the keys auth-service publishes
+	keys := token.NewRemoteKeySet(cfg.JWKSURL, time.Minute)
+
+	serviceComp := config.NewServiceComponent(log, keys, authClientComp)
+	a.Add(serviceComp, app.DependsOn(authClientComp))
+
+	mw := middleware.Init(log)
+	gin := http.Init(log, mw, cfg.Http)
+
+	httpServerComp := server.NewHTTPServerComponent(log, cfg.Server, mw, gin, func(ctx context.Context, engine *server.Engine) error {
+		server.RegisterHealth(engine, a)
+		server.RegisterMetrics(engine)
+		restHandler.InitRestHandler(engine, serviceComp.Service(), cfg.Upstream)
+		return nil
+	})
+	a.Add(httpServerComp, app.DependsOn(serviceComp))
+
+	if err := a.Run(); err != nil {
+		log.Fatal().Err(err).Msg("app failed")
+	}
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"context"
+
+	"github.com/linggaaskaedo/go-kill/common/component/grpcclient"
+	"github.com/linggaaskaedo/go-kill/common/pkg/token"
+	"github.com/linggaaskaedo/go-kill/gateway-service/src/internal/service"
+
+	"github.com/rs/zerolog"
+)
+
+type ServiceComponent struct {
+	log            zerolog.Logger
+	keys           *token.RemoteKeySet
+	authClientComp *grpcclient.GRPCClientComponent
+
+	service *service.Service
+	ready   chan struct{}
+}
+
+func NewServiceComponent(
+	log zerolog.Logger,
+	keys *token.RemoteKeySet,
+	authClientComp *grpcclient.GRPCClientComponent,
+) *ServiceComponent {
+	return &ServiceComponent{
+		log:            log,
+		keys:           keys,
+		authClientComp: authClientComp,
+		ready:          make(chan struct{}),
+	}
+}
+
+func (s *ServiceComponent) Start(ctx context.Context) error {
+	s.service = service.InitService(s.keys, s.authClientComp.Conn())
+
+	close(s.ready)
+	s.log.Debug().Msg("Service component started")
+	<-ctx.Done()
+
+	return nil
+}
+
+func (s *ServiceComponent) Stop(ctx context.Context) error {
+	s.log.Debug().Msg("Service component stopped")
+	return nil
+}
+
+func (s *ServiceComponent) Service() *service.Service {
+	return s.service
+}
+
+func (s *ServiceComponent) Ready() <-chan struct{} {
+	return s.ready
+}
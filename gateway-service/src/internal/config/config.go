@@ -0,0 +1,29 @@
+package config
+
+import (
+	"github.com/linggaaskaedo/go-kill/common/component/grpcclient"
+	"github.com/linggaaskaedo/go-kill/common/component/http"
+	"github.com/linggaaskaedo/go-kill/common/component/server"
+	"github.com/linggaaskaedo/go-kill/common/component/tracer"
+	commonConfig "github.com/linggaaskaedo/go-kill/common/pkg/config"
+	"github.com/linggaaskaedo/go-kill/common/pkg/logger"
+)
+
+type Config struct {
+	Logger     logger.Config                `yaml:"logger"`
+	Tracer     tracer.Config                `yaml:"tracer"`
+	GRPCClient map[string]grpcclient.Config `yaml:"grpc_client" validate:"dive"`
+	Upstream   map[string]string            `yaml:"upstream"`
+	JWKSURL    string                       `yaml:"jwks_url" validate:"required"`
+	Http       http.Config                  `yaml:"http"`
+	Server     server.Config                `yaml:"server"`
+}
+
+func Load(src commonConfig.Source) (*Config, error) {
+	var cfg Config
+	if err := commonConfig.Load(src, &cfg); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
@@ -0,0 +1,45 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+
+	"github.com/linggaaskaedo/go-kill/common/pkg/correlation"
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+	"github.com/linggaaskaedo/go-kill/common/pkg/preference"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+// proxy forwards the request to the named upstream. The upstream authenticates the forwarded credentials itself.
+func (e *rest) proxy(upstream string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		target, err := url.Parse(e.upstreams[upstream])
+		if err != nil || target.Host == "" {
+			zerolog.Ctx(ctx).Error().Err(err).Str("upstream", upstream).Msg("upstream_not_configured")
+			e.httpRespError(c, x.NewWithCode(x.CodeHTTPServiceUnavailable, "upstream_not_configured"))
+			return
+		}
+
+		if reqID := correlation.GetReqID(ctx, preference.CONTEXT_KEY_REQ_ID); reqID != "" {
+			c.Request.Header.Set(preference.REQUEST_ID, reqID)
+		}
+
+		rp := &httputil.ReverseProxy{
+			Rewrite: func(r *httputil.ProxyRequest) {
+				r.SetURL(target)
+				r.SetXForwarded()
+			},
+			ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+				zerolog.Ctx(ctx).Error().Err(err).Str("upstream", upstream).Msg("proxy_upstream")
+				e.httpRespError(c, x.WrapWithCode(err, x.CodeHTTPServiceUnavailable, "proxy_upstream"))
+			},
+		}
+
+		rp.ServeHTTP(c.Writer, c.Request)
+	}
+}
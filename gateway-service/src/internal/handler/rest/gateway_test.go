@@ -0,0 +1,105 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/linggaaskaedo/go-kill/common/pkg/authz"
+	"github.com/linggaaskaedo/go-kill/common/pkg/idempotency"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// closeNotifyRecorder satisfies http.CloseNotifier, which gin's writer requires once the reverse proxy asks for it.
+type closeNotifyRecorder struct {
+	*httptest.ResponseRecorder
+}
+
+func (closeNotifyRecorder) CloseNotify() <-chan bool {
+	return make(chan bool)
+}
+
+func TestProxyForwardsCredentials(t *testing.T) {
+	var gotAuth, gotPath string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotPath = r.URL.Path
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+
+	mockGateway := new(MockGatewayService)
+	handler := setupTestRest(mockGateway)
+	handler.upstreams = map[string]string{upstreamUser: upstream.URL}
+
+	router := setupTestRouter()
+	router.GET(pathUsersMe, handler.authMiddleware(authz.PermUserRead), handler.proxy(upstreamUser))
+
+	mockValidToken(mockGateway)
+
+	req, _ := http.NewRequest(http.MethodGet, pathUsersMe, nil)
+	req.Header.Set("Authorization", headerAuthBearer)
+	w := closeNotifyRecorder{httptest.NewRecorder()}
+
+	router.ServeHTTP(w, req)
+
+	// The upstream verifies the token again, it trusts nothing the gateway adds
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, pathUsersMe, gotPath)
+	assert.Equal(t, headerAuthBearer, gotAuth)
+	mockGateway.AssertExpectations(t)
+}
+
+func TestProxyUpstreamUnavailable(t *testing.T) {
+	upstream := httptest.NewServer(http.NotFoundHandler())
+	upstreamURL := upstream.URL
+	upstream.Close()
+
+	handler := setupTestRest(new(MockGatewayService))
+	handler.upstreams = map[string]string{upstreamProduct: upstreamURL}
+
+	router := setupTestRouter()
+	router.GET(pathProducts, handler.proxy(upstreamProduct))
+
+	req, _ := http.NewRequest(http.MethodGet, pathProducts, nil)
+	w := closeNotifyRecorder{httptest.NewRecorder()}
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestProxyOrderRoutesForwardToken(t *testing.T) {
+	var gotAuth, gotKey, gotPath string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotKey = r.Header.Get(idempotency.Header)
+		gotPath = r.URL.Path
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer upstream.Close()
+
+	mockGateway := new(MockGatewayService)
+	handler := setupTestRest(mockGateway)
+	handler.gin = setupTestRouter()
+	handler.upstreams = map[string]string{upstreamOrder: upstream.URL}
+	handler.Serve()
+
+	mockValidToken(mockGateway)
+
+	req, _ := http.NewRequest(http.MethodPost, pathOrders, nil)
+	req.Header.Set("Authorization", headerAuthBearer)
+	req.Header.Set(idempotency.Header, "key-1")
+	w := closeNotifyRecorder{httptest.NewRecorder()}
+
+	handler.gin.ServeHTTP(w, req)
+
+	// order-service checks the token with auth-service, which knows about revocation
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, pathOrders, gotPath)
+	assert.Equal(t, headerAuthBearer, gotAuth)
+	assert.Equal(t, "key-1", gotKey)
+	mockGateway.AssertNotCalled(t, "CreateOrder", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,38 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+	"github.com/linggaaskaedo/go-kill/common/pkg/preference"
+	"github.com/linggaaskaedo/go-kill/gateway-service/src/internal/model/dto"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (e *rest) httpRespError(c *gin.Context, err error) {
+	lang := preference.LANG_ID
+
+	appLangHeader := http.CanonicalHeaderKey(preference.APP_LANG)
+	if c.Request.Header[appLangHeader] != nil && c.Request.Header[appLangHeader][0] == preference.LANG_EN {
+		lang = preference.LANG_EN
+	}
+
+	statusCode, displayError := x.Compile(x.COMMON, err, lang, true)
+	statusStr := http.StatusText(statusCode)
+
+	jsonErrResp := &dto.HTTPErrorResp{
+		Meta: dto.Meta{
+			Path:       c.Request.URL.Path,
+			StatusCode: statusCode,
+			Status:     statusStr,
+			Message:    fmt.Sprintf("%s %s [%d] %s", c.Request.Method, c.Request.RequestURI, statusCode, http.StatusText(statusCode)),
+			Error:      &displayError,
+			Timestamp:  time.Now().Format(time.RFC3339),
+		},
+	}
+
+	c.JSON(statusCode, jsonErrResp)
+}
@@ -0,0 +1,184 @@
+package rest
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/linggaaskaedo/go-kill/common/pkg/authz"
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+	"github.com/linggaaskaedo/go-kill/common/pkg/preference"
+	authpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/auth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	testOrderID            = "order-123"
+	headerContentType      = "Content-Type"
+	headerContentTypeValue = "application/json"
+	pathAdminOrderByID     = "/api/v1/admin/orders/order-123"
+)
+
+// orderUpstream stands in for order-service and records the paths it served.
+func orderUpstream(t *testing.T) (*httptest.Server, *[]string) {
+	t.Helper()
+
+	var paths []string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.RequestURI())
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(upstream.Close)
+
+	return upstream, &paths
+}
+
+// setupOrderRouter serves the gateway routes with order-service at upstream.
+func setupOrderRouter(mockGateway *MockGatewayService, upstream string) *gin.Engine {
+	handler := setupTestRest(mockGateway)
+	handler.gin = setupTestRouter()
+	handler.upstreams = map[string]string{upstreamOrder: upstream}
+	handler.Serve()
+
+	return handler.gin
+}
+
+func TestOrderRoutesProxied(t *testing.T) {
+	upstream, paths := orderUpstream(t)
+
+	mockGateway := new(MockGatewayService)
+	router := setupOrderRouter(mockGateway, upstream.URL)
+
+	mockValidToken(mockGateway)
+
+	for _, tc := range []struct {
+		method string
+		path   string
+	}{
+		{http.MethodPost, pathOrders},
+		{http.MethodGet, pathOrders + "?page=2&limit=500"},
+		{http.MethodGet, pathOrderByID},
+		{http.MethodPost, pathOrderCancel},
+	} {
+		req, _ := http.NewRequest(tc.method, tc.path, bytes.NewBufferString("{}"))
+		req.Header.Set(headerContentType, headerContentTypeValue)
+		req.Header.Set("Authorization", headerAuthBearer)
+		w := closeNotifyRecorder{httptest.NewRecorder()}
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, tc.method+" "+tc.path)
+	}
+
+	// Paging and its cap are left to order-service
+	assert.Equal(t, []string{pathOrders, pathOrders + "?page=2&limit=500", pathOrderByID, pathOrderCancel}, *paths)
+}
+
+func TestOrderRouteInvalidToken(t *testing.T) {
+	upstream, paths := orderUpstream(t)
+
+	mockGateway := new(MockGatewayService)
+	router := setupOrderRouter(mockGateway, upstream.URL)
+
+	mockGateway.On("ValidateToken", mock.Anything, "invalid-token").Return(nil, x.NewWithCode(x.CodeHTTPUnauthorized, "validate_token"))
+
+	req, _ := http.NewRequest(http.MethodPost, pathOrders, bytes.NewBufferString("{}"))
+	req.Header.Set(headerContentType, headerContentTypeValue)
+	req.Header.Set("Authorization", "Bearer invalid-token")
+	w := closeNotifyRecorder{httptest.NewRecorder()}
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Empty(t, *paths)
+	mockGateway.AssertExpectations(t)
+}
+
+func TestOrderRouteWithApiKey(t *testing.T) {
+	upstream, paths := orderUpstream(t)
+
+	mockGateway := new(MockGatewayService)
+	router := setupOrderRouter(mockGateway, upstream.URL)
+
+	mockGateway.On("ValidateApiKey", mock.Anything, "gk_valid-key").Return(&authpb.ValidateTokenResponse{Valid: true, UserId: testUserAuthID, ApiKeyId: "key-123", Permissions: []string{authz.PermOrderRead}}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, pathOrderByID, nil)
+	req.Header.Set(preference.API_KEY, "gk_valid-key")
+	req.Header.Set("Authorization", "Bearer ignored-token")
+	w := closeNotifyRecorder{httptest.NewRecorder()}
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{pathOrderByID}, *paths)
+	mockGateway.AssertExpectations(t)
+	mockGateway.AssertNotCalled(t, "ValidateToken", mock.Anything, mock.Anything)
+}
+
+func TestApiKeyOutsideItsScopesIsForbidden(t *testing.T) {
+	upstream, paths := orderUpstream(t)
+
+	mockGateway := new(MockGatewayService)
+	router := setupOrderRouter(mockGateway, upstream.URL)
+
+	mockGateway.On("ValidateApiKey", mock.Anything, "gk_valid-key").Return(&authpb.ValidateTokenResponse{Valid: true, UserId: testUserAuthID, ApiKeyId: "key-123", Permissions: []string{authz.PermOrderRead}}, nil)
+
+	for _, tc := range []struct {
+		method string
+		path   string
+	}{
+		{http.MethodPost, pathOrders},
+		{http.MethodPost, pathOrderCancel},
+		{http.MethodGet, pathAdminOrderByID},
+		{http.MethodGet, pathUsersMe},
+		// Account routes name no permission and take bearer tokens only
+		{http.MethodPost, "/api/v1/auth/api-keys"},
+	} {
+		req, _ := http.NewRequest(tc.method, tc.path, bytes.NewBufferString("{}"))
+		req.Header.Set(headerContentType, headerContentTypeValue)
+		req.Header.Set(preference.API_KEY, "gk_valid-key")
+		w := closeNotifyRecorder{httptest.NewRecorder()}
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code, tc.method+" "+tc.path)
+	}
+
+	assert.Empty(t, *paths)
+}
+
+func TestAdminOrderRoute(t *testing.T) {
+	tests := []struct {
+		name        string
+		permissions []string
+		wantStatus  int
+		wantPaths   []string
+	}{
+		{name: "read any", permissions: []string{authz.PermOrderRead, authz.PermOrderReadAny}, wantStatus: http.StatusOK, wantPaths: []string{pathAdminOrderByID}},
+		{name: "own orders only", permissions: []string{authz.PermOrderRead}, wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upstream, paths := orderUpstream(t)
+
+			mockGateway := new(MockGatewayService)
+			router := setupOrderRouter(mockGateway, upstream.URL)
+
+			mockGateway.On("ValidateToken", mock.Anything, "valid-token").Return(&authpb.ValidateTokenResponse{Valid: true, UserId: testUserAuthID, Permissions: tt.permissions}, nil)
+
+			req, _ := http.NewRequest(http.MethodGet, pathAdminOrderByID, nil)
+			req.Header.Set("Authorization", headerAuthBearer)
+			w := closeNotifyRecorder{httptest.NewRecorder()}
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantPaths, *paths)
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package rest
+
+import (
+	"sync"
+
+	"github.com/linggaaskaedo/go-kill/common/pkg/authz"
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+	"github.com/linggaaskaedo/go-kill/common/pkg/middleware"
+	"github.com/linggaaskaedo/go-kill/common/pkg/preference"
+	authpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/auth"
+	"github.com/linggaaskaedo/go-kill/gateway-service/src/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	upstreamAuth    = "auth_service"
+	upstreamUser    = "user_service"
+	upstreamProduct = "product_service"
+	upstreamOrder   = "order_service"
+)
+
+var onceRestHandler = &sync.Once{}
+
+type rest struct {
+	gin       *gin.Engine
+	svc       *service.Service
+	upstreams map[string]string
+}
+
+func InitRestHandler(gin *gin.Engine, svc *service.Service, upstreams map[string]string) {
+	var e *rest
+
+	onceRestHandler.Do(func() {
+		e = &rest{
+			gin:       gin,
+			svc:       svc,
+			upstreams: upstreams,
+		}
+
+		e.Serve()
+	})
+}
+
+// authMiddleware validates the bearer token or API key once at the edge, keeps the verified identity on the context
+// and requires permissions of it. Routes that name no permission manage the account itself and are for bearer tokens
+// only, so an API key reaches nothing beyond its scopes.
+func (e *rest) authMiddleware(permissions ...string) gin.HandlerFunc {
+	authorize := middleware.Authorize(e.httpRespError, permissions...)
+
+	return func(c *gin.Context) {
+		resp, err := e.authenticate(c)
+		if err != nil {
+			e.httpRespError(c, err)
+			c.Abort()
+			return
+		}
+
+		if resp.ApiKeyId != "" && len(permissions) == 0 {
+			e.httpRespError(c, x.NewWithCode(x.CodeHTTPForbidden, "api_key_not_allowed"))
+			c.Abort()
+			return
+		}
+
+		c.Set("user_auth_id", resp.UserId)
+		c.Request = c.Request.WithContext(authz.WithPrincipal(c.Request.Context(), &authz.Principal{
+			UserID:      resp.UserId,
+			Roles:       resp.Roles,
+			Permissions: resp.Permissions,
+		}))
+		authorize(c)
+	}
+}
+
+// authenticate validates the X-API-Key header when it is sent and the bearer token otherwise.
+func (e *rest) authenticate(c *gin.Context) (*authpb.ValidateTokenResponse, error) {
+	if apiKey := c.GetHeader(preference.API_KEY); apiKey != "" {
+		return e.svc.Gateway.ValidateApiKey(c.Request.Context(), apiKey)
+	}
+
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		return nil, x.NewWithCode(x.CodeHTTPUnauthorized, "no_authorization_header")
+	}
+
+	const bearerPrefix = "Bearer "
+	if len(authHeader) < len(bearerPrefix) || authHeader[:len(bearerPrefix)] != bearerPrefix {
+		return nil, x.NewWithCode(x.CodeHTTPUnauthorized, "invalid_authorization_header_format")
+	}
+
+	return e.svc.Gateway.ValidateToken(c.Request.Context(), authHeader[len(bearerPrefix):])
+}
+
+func (e *rest) Serve() {
+	// Auth Service
+	e.gin.POST("/api/v1/auth/login", e.proxy(upstreamAuth))
+	e.gin.POST("/api/v1/auth/refresh", e.proxy(upstreamAuth))
+	e.gin.POST("/api/v1/auth/logout", e.authMiddleware(), e.proxy(upstreamAuth))
+	e.gin.POST("/api/v1/auth/verify-email/request", e.proxy(upstreamAuth))
+	e.gin.POST("/api/v1/auth/verify-email", e.proxy(upstreamAuth))
+	e.gin.POST("/api/v1/auth/forgot-password", e.proxy(upstreamAuth))
+	e.gin.POST("/api/v1/auth/reset-password", e.proxy(upstreamAuth))
+	e.gin.POST("/api/v1/auth/mfa/enroll", e.authMiddleware(), e.proxy(upstreamAuth))
+	e.gin.POST("/api/v1/auth/mfa/confirm", e.authMiddleware(), e.proxy(upstreamAuth))
+	e.gin.POST("/api/v1/auth/mfa/disable", e.authMiddleware(), e.proxy(upstreamAuth))
+	e.gin.POST("/api/v1/auth/mfa/verify", e.proxy(upstreamAuth))
+	e.gin.GET("/api/v1/auth/sessions", e.authMiddleware(), e.proxy(upstreamAuth))
+	e.gin.DELETE("/api/v1/auth/sessions/:session_id", e.authMiddleware(), e.proxy(upstreamAuth))
+	e.gin.POST("/api/v1/auth/logout-all", e.authMiddleware(), e.proxy(upstreamAuth))
+	e.gin.POST("/api/v1/auth/api-keys", e.authMiddleware(), e.proxy(upstreamAuth))
+	e.gin.GET("/api/v1/auth/api-keys", e.authMiddleware(), e.proxy(upstreamAuth))
+	e.gin.POST("/api/v1/auth/api-keys/:key_id/rotate", e.authMiddleware(), e.proxy(upstreamAuth))
+	e.gin.DELETE("/api/v1/auth/api-keys/:key_id", e.authMiddleware(), e.proxy(upstreamAuth))
+	e.gin.GET("/api/v1/auth/oauth/:provider/start", e.proxy(upstreamAuth))
+	e.gin.GET("/api/v1/auth/oauth/:provider/callback", e.proxy(upstreamAuth))
+	e.gin.GET("/.well-known/jwks.json", e.proxy(upstreamAuth))
+
+	// User Service
+	e.gin.POST("/api/v1/users/register", e.proxy(upstreamUser))
+	e.gin.GET("/api/v1/users/me", e.authMiddleware(authz.PermUserRead), e.proxy(upstreamUser))
+	e.gin.GET("/api/v1/users/me/activities", e.authMiddleware(authz.PermUserRead), e.proxy(upstreamUser))
+	e.gin.GET("/api/v1/users/me/addresses", e.authMiddleware(authz.PermUserRead), e.proxy(upstreamUser))
+	e.gin.POST("/api/v1/users/me/addresses", e.authMiddleware(authz.PermUserWrite), e.proxy(upstreamUser))
+
+	// Product Service
+	e.gin.POST("/api/v1/products", e.authMiddleware(authz.PermProductWrite), e.proxy(upstreamProduct))
+	e.gin.GET("/api/v1/products", e.proxy(upstreamProduct))
+	e.gin.GET("/api/v1/products/:id", e.proxy(upstreamProduct))
+	e.gin.GET("/api/v1/products/:id/categories", e.proxy(upstreamProduct))
+	e.gin.GET("/api/v1/categories", e.proxy(upstreamProduct))
+	e.gin.GET("/api/v1/categories/:id/products", e.proxy(upstreamProduct))
+
+	// Order Service, which checks the token again with auth-service so a revoked one cannot place or cancel orders
+	e.gin.POST("/api/v1/orders", e.authMiddleware(authz.PermOrderWrite), e.proxy(upstreamOrder))
+	e.gin.GET("/api/v1/orders", e.authMiddleware(authz.PermOrderRead), e.proxy(upstreamOrder))
+	e.gin.GET("/api/v1/orders/:id", e.authMiddleware(authz.PermOrderRead), e.proxy(upstreamOrder))
+	e.gin.POST("/api/v1/orders/:id/cancel", e.authMiddleware(authz.PermOrderWrite), e.proxy(upstreamOrder))
+	e.gin.GET("/api/v1/admin/orders/:id", e.authMiddleware(authz.PermOrderReadAny), e.proxy(upstreamOrder))
+}
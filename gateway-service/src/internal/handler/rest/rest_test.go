@@ -0,0 +1,177 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/linggaaskaedo/go-kill/common/pkg/authz"
+	authpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/auth"
+	"github.com/linggaaskaedo/go-kill/gateway-service/src/internal/service"
+	"github.com/linggaaskaedo/go-kill/gateway-service/src/internal/service/gateway"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	pathAuthLogin    = "/api/v1/auth/login"
+	pathAuthLogout   = "/api/v1/auth/logout"
+	pathJWKS         = "/.well-known/jwks.json"
+	pathUsersMe      = "/api/v1/users/me"
+	pathProducts     = "/api/v1/products"
+	pathOrders       = "/api/v1/orders"
+	pathOrderByID    = "/api/v1/orders/order-123"
+	pathOrderCancel  = "/api/v1/orders/order-123/cancel"
+	pathUsersUnknown = "/api/v1/users/unknown"
+)
+
+const (
+	testUserAuthID   = "auth-123"
+	testUserEmail    = "test@example.com"
+	headerAuthBearer = "Bearer valid-token"
+)
+
+type MockGatewayService struct {
+	mock.Mock
+}
+
+func (m *MockGatewayService) ValidateToken(ctx context.Context, token string) (*authpb.ValidateTokenResponse, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*authpb.ValidateTokenResponse), args.Error(1)
+}
+
+func (m *MockGatewayService) ValidateApiKey(ctx context.Context, apiKey string) (*authpb.ValidateTokenResponse, error) {
+	args := m.Called(ctx, apiKey)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*authpb.ValidateTokenResponse), args.Error(1)
+}
+
+var _ gateway.GatewayServiceItf = (*MockGatewayService)(nil)
+
+func setupTestRest(mockGateway *MockGatewayService) *rest {
+	mockSvc := &service.Service{}
+	mockSvc.Gateway = mockGateway
+
+	return &rest{
+		gin: nil,
+		svc: mockSvc,
+	}
+}
+
+func mockValidToken(mockGateway *MockGatewayService) {
+	mockGateway.On("ValidateToken", mock.Anything, "valid-token").Return(&authpb.ValidateTokenResponse{
+		Valid:       true,
+		UserId:      testUserAuthID,
+		Email:       testUserEmail,
+		Permissions: []string{authz.PermOrderRead, authz.PermOrderWrite, authz.PermUserRead, authz.PermUserWrite},
+	}, nil)
+}
+
+func setupTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	return gin.New()
+}
+
+func TestInitRestHandler(t *testing.T) {
+	router := setupTestRouter()
+
+	svc := &service.Service{}
+
+	InitRestHandler(router, svc, map[string]string{})
+}
+
+func TestServeRoutesRegistered(t *testing.T) {
+	router := setupTestRouter()
+
+	svc := &service.Service{}
+
+	handler := &rest{
+		gin:       router,
+		svc:       svc,
+		upstreams: map[string]string{},
+	}
+
+	handler.Serve()
+
+	tests := []struct {
+		method string
+		path   string
+		status int
+	}{
+		{http.MethodPost, pathAuthLogin, http.StatusServiceUnavailable},
+		{http.MethodPost, pathAuthLogout, http.StatusUnauthorized},
+		{http.MethodGet, pathJWKS, http.StatusServiceUnavailable},
+		{http.MethodGet, pathUsersMe, http.StatusUnauthorized},
+		{http.MethodGet, pathProducts, http.StatusServiceUnavailable},
+		{http.MethodPost, pathOrders, http.StatusUnauthorized},
+		{http.MethodGet, pathOrders, http.StatusUnauthorized},
+		{http.MethodGet, pathOrderByID, http.StatusUnauthorized},
+		{http.MethodPost, pathOrderCancel, http.StatusUnauthorized},
+		{http.MethodGet, pathAdminOrderByID, http.StatusUnauthorized},
+		{http.MethodGet, pathUsersUnknown, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		req, _ := http.NewRequest(tt.method, tt.path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != tt.status {
+			t.Errorf("route %s %s: expected %d, got %d", tt.method, tt.path, tt.status, w.Code)
+		}
+	}
+}
+
+func TestAuthMiddlewareNoHeaderRest(t *testing.T) {
+	router := setupTestRouter()
+
+	svc := &service.Service{}
+
+	handler := &rest{
+		gin: router,
+		svc: svc,
+	}
+
+	router.GET(pathUsersMe, handler.authMiddleware(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, pathUsersMe, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected status %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+}
+
+func TestAuthMiddlewareInvalidFormatRest(t *testing.T) {
+	router := setupTestRouter()
+
+	svc := &service.Service{}
+
+	handler := &rest{
+		gin: router,
+		svc: svc,
+	}
+
+	router.GET(pathUsersMe, handler.authMiddleware(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, pathUsersMe, nil)
+	req.Header.Set("Authorization", "InvalidToken")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected status %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+}
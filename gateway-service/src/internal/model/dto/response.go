@@ -0,0 +1,18 @@
+package dto
+
+import (
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+)
+
+type Meta struct {
+	Path       string      `json:"path" extensions:"x-order=0"`
+	StatusCode int         `json:"status_code" extensions:"x-order=1"`
+	Status     string      `json:"status" extensions:"x-order=2"`
+	Message    string      `json:"message" extensions:"x-order=3"`
+	Error      *x.AppError `json:"error,omitempty" swaggertype:"primitive,object" extensions:"x-order=4"`
+	Timestamp  string      `json:"timestamp" extensions:"x-order=5"`
+}
+
+type HTTPErrorResp struct {
+	Meta Meta `json:"metadata"`
+}
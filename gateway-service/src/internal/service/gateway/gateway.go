@@ -0,0 +1,28 @@
+package gateway
+
+import (
+	"context"
+
+	authpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/auth"
+	"github.com/linggaaskaedo/go-kill/common/pkg/token"
+
+	"google.golang.org/grpc"
+)
+
+type GatewayServiceItf interface {
+	// Auth
+	ValidateToken(ctx context.Context, token string) (*authpb.ValidateTokenResponse, error)
+	ValidateApiKey(ctx context.Context, apiKey string) (*authpb.ValidateTokenResponse, error)
+}
+
+type gatewayService struct {
+	keys       *token.RemoteKeySet
+	authClient authpb.AuthServiceClient
+}
+
+func InitGatewayService(keys *token.RemoteKeySet, authConn *grpc.ClientConn) GatewayServiceItf {
+	return &gatewayService{
+		keys:       keys,
+		authClient: authpb.NewAuthServiceClient(authConn),
+	}
+}
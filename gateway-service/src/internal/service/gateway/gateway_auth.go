@@ -0,0 +1,88 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+	authpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/auth"
+	"github.com/linggaaskaedo/go-kill/common/pkg/token"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ValidateToken verifies the signature and expiry of an access token against the keys auth-service publishes, so no
+// request waits on a call to it. A revoked token is accepted here until it expires, so every route it guards must be
+// proxied to a service that checks the token with auth-service again.
+func (s *gatewayService) ValidateToken(ctx context.Context, tokenString string) (*authpb.ValidateTokenResponse, error) {
+	parsed, err := s.keys.Parse(ctx, tokenString)
+	if err != nil {
+		if errors.Is(err, token.ErrJWKSUnavailable) {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("validate_token")
+			return nil, x.WrapWithCode(err, x.CodeHTTPServiceUnavailable, "validate_token")
+		}
+		return nil, x.WrapWithCode(err, x.CodeHTTPUnauthorized, "validate_token")
+	}
+
+	claims, ok := parsed.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, x.NewWithCode(x.CodeHTTPUnauthorized, "validate_token")
+	}
+
+	sub, _ := claims["sub"].(string)
+	email, _ := claims["email"].(string)
+	sid, _ := claims["sid"].(string)
+
+	return checkValidation(ctx, &authpb.ValidateTokenResponse{
+		Valid:       true,
+		UserId:      sub,
+		Email:       email,
+		SessionId:   sid,
+		Roles:       claimStrings(claims, "roles"),
+		Permissions: claimStrings(claims, "perms"),
+	}, nil, "validate_token")
+}
+
+// claimStrings reads a string array claim. Tokens signed before the claim existed yield an empty slice.
+func claimStrings(claims jwt.MapClaims, name string) []string {
+	values, _ := claims[name].([]any)
+
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if s, ok := v.(string); ok {
+			result = append(result, s)
+		}
+	}
+
+	return result
+}
+
+func (s *gatewayService) ValidateApiKey(ctx context.Context, apiKey string) (*authpb.ValidateTokenResponse, error) {
+	resp, err := s.authClient.ValidateApiKey(ctx, &authpb.ValidateApiKeyRequest{ApiKey: apiKey})
+
+	return checkValidation(ctx, resp, err, "validate_api_key")
+}
+
+// checkValidation turns the answer of auth-service to a token or API key into an identity or an error.
+func checkValidation(ctx context.Context, resp *authpb.ValidateTokenResponse, err error, msg string) (*authpb.ValidateTokenResponse, error) {
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg(msg)
+
+		// Only an unreachable auth service is reported as such, every other failure means the credential was rejected
+		switch status.Code(err) {
+		case codes.Unavailable, codes.DeadlineExceeded:
+			return nil, x.WrapWithCode(err, x.CodeHTTPServiceUnavailable, msg)
+		default:
+			return nil, x.WrapWithCode(err, x.CodeHTTPUnauthorized, msg)
+		}
+	}
+
+	if !resp.Valid || resp.UserId == "" {
+		return nil, x.NewWithCode(x.CodeHTTPUnauthorized, msg)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,128 @@
+package gateway
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+	"github.com/linggaaskaedo/go-kill/common/pkg/token"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestKeySet signs with a fresh key published under kid.
+func newTestKeySet(t *testing.T, kid string) *token.KeySet {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), kid+".pem")
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	require.NoError(t, os.WriteFile(path, pemBytes, 0o600))
+
+	ks, err := token.NewKeySet(token.Config{SigningKeyID: kid, Keys: []token.Key{{ID: kid, PrivateKeyPath: path}}})
+	require.NoError(t, err)
+
+	return ks
+}
+
+// newTestService verifies tokens against the keys of ks, served like
+// auth-service serves them.
+func newTestService(t *testing.T, ks *token.KeySet) *gatewayService {
+	t.Helper()
+
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(ks.JWKS())
+	}))
+	t.Cleanup(jwks.Close)
+
+	return &gatewayService{keys: token.NewRemoteKeySet(jwks.URL+token.JWKSPath, time.Minute)}
+}
+
+func sign(t *testing.T, ks *token.KeySet, claims jwt.MapClaims) string {
+	t.Helper()
+
+	signed, err := ks.Sign(claims)
+	require.NoError(t, err)
+
+	return signed
+}
+
+func accessClaims(exp time.Time) jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":   "auth-1",
+		"email": "user@example.com",
+		"sid":   "session-1",
+		"roles": []string{"customer"},
+		"perms": []string{"order:read", "order:write"},
+		"exp":   exp.Unix(),
+		"jti":   "token-1",
+	}
+}
+
+func TestValidateTokenLocally(t *testing.T) {
+	ks := newTestKeySet(t, "key-1")
+	svc := newTestService(t, ks)
+
+	resp, err := svc.ValidateToken(context.Background(), sign(t, ks, accessClaims(time.Now().Add(time.Hour))))
+	require.NoError(t, err)
+
+	assert.True(t, resp.Valid)
+	assert.Equal(t, "auth-1", resp.UserId)
+	assert.Equal(t, "user@example.com", resp.Email)
+	assert.Equal(t, "session-1", resp.SessionId)
+	assert.Equal(t, []string{"customer"}, resp.Roles)
+	assert.Equal(t, []string{"order:read", "order:write"}, resp.Permissions)
+}
+
+func TestValidateTokenRejected(t *testing.T) {
+	ks := newTestKeySet(t, "key-1")
+	svc := newTestService(t, ks)
+
+	noSubject := accessClaims(time.Now().Add(time.Hour))
+	delete(noSubject, "sub")
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "expired", token: sign(t, ks, accessClaims(time.Now().Add(-time.Minute)))},
+		{name: "signed by an unknown key", token: sign(t, newTestKeySet(t, "key-2"), accessClaims(time.Now().Add(time.Hour)))},
+		{name: "forged with a published kid", token: sign(t, newTestKeySet(t, "key-1"), accessClaims(time.Now().Add(time.Hour)))},
+		{name: "no subject", token: sign(t, ks, noSubject)},
+		{name: "not a token", token: "garbage"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.ValidateToken(context.Background(), tt.token)
+			require.Error(t, err)
+			assert.Equal(t, x.CodeHTTPUnauthorized, x.ErrCode(err))
+		})
+	}
+}
+
+func TestValidateTokenKeysUnavailable(t *testing.T) {
+	ks := newTestKeySet(t, "key-1")
+
+	jwks := httptest.NewServer(http.NotFoundHandler())
+	jwks.Close()
+	svc := &gatewayService{keys: token.NewRemoteKeySet(jwks.URL+token.JWKSPath, time.Minute)}
+
+	_, err := svc.ValidateToken(context.Background(), sign(t, ks, accessClaims(time.Now().Add(time.Hour))))
+	require.Error(t, err)
+	assert.Equal(t, x.CodeHTTPServiceUnavailable, x.ErrCode(err))
+}
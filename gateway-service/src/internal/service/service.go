@@ -0,0 +1,21 @@
+package service
+
+import (
+	"github.com/linggaaskaedo/go-kill/common/pkg/token"
+	"github.com/linggaaskaedo/go-kill/gateway-service/src/internal/service/gateway"
+
+	"google.golang.org/grpc"
+)
+
+type Service struct {
+	Gateway gateway.GatewayServiceItf
+}
+
+func InitService(keys *token.RemoteKeySet, authConn *grpc.ClientConn) *Service {
+	return &Service{
+		Gateway: gateway.InitGatewayService(
+			keys,
+			authConn,
+		),
+	}
+}
@@ -3,20 +3,21 @@ package main
 import (
 	"context"
 	"flag"
-	"fmt"
-	"os/signal"
-	"syscall"
 	"time"
 
 	"github.com/linggaaskaedo/go-kill/common/app"
+	"github.com/linggaaskaedo/go-kill/common/component/http"
 	"github.com/linggaaskaedo/go-kill/common/component/kafkaconsumer"
 	"github.com/linggaaskaedo/go-kill/common/component/mongo"
 	"github.com/linggaaskaedo/go-kill/common/component/redis"
+	"github.com/linggaaskaedo/go-kill/common/component/server"
+	"github.com/linggaaskaedo/go-kill/common/component/tracer"
+	commonConfig "github.com/linggaaskaedo/go-kill/common/pkg/config"
 	"github.com/linggaaskaedo/go-kill/common/pkg/logger"
+	"github.com/linggaaskaedo/go-kill/common/pkg/metrics"
+	"github.com/linggaaskaedo/go-kill/common/pkg/middleware"
 	"github.com/linggaaskaedo/go-kill/notification-service/src/internal/config"
 	"github.com/linggaaskaedo/go-kill/notification-service/src/internal/handler/pubsub"
-
-	"golang.org/x/sync/errgroup"
 )
 
 var (
@@ -37,6 +38,8 @@ var (
 // @host			localhost:8080
 // @schemes		http https
 func main() {
+	src := commonConfig.Source{File: "config.yaml", EnvPrefix: "NOTIFICATION"}
+	src.RegisterFlags(flag.CommandLine)
 	flag.IntVar(&minJitter, "minSleep", DefaultMinJitter, "min. sleep duration during app initialization")
 	flag.IntVar(&maxJitter, "maxSleep", DefaultMaxJitter, "max. sleep duration during app initialization")
 	flag.Parse()
@@ -45,7 +48,7 @@ func main() {
 	sleepWithJitter(minJitter, maxJitter)
 
 	// Load config
-	cfg, err := config.Load("config.yaml")
+	cfg, err := config.Load(src)
 	if err != nil {
 		panic(err)
 	}
@@ -53,83 +56,55 @@ func main() {
 	// Initialize logger
 	log := logger.Init(cfg.Logger)
 
+	// Label the metrics with the service name
+	metrics.Init("notification-service")
+
 	log.Info().Msg("Starting notification service...")
 
 	// Create application with options
-	appSubComp := app.New(app.WithShutdownTimeout(15*time.Second), app.WithLogger(log))
-	appMainComp := app.New(app.WithShutdownTimeout(15*time.Second), app.WithLogger(log))
+	a := app.New(app.WithShutdownTimeout(10*time.Second), app.WithLogger(log))
+
+	// Added first so that it stops last, exporting the spans of shutdown
+	a.Add(tracer.NewTracerComponent(log, cfg.Tracer))
 
-	// Initialize redis component
 	redisComp0 := redis.NewRedisComponent(log, cfg.Redis)
-	appSubComp.Add(redisComp0, 10*time.Second)
+	a.Add(redisComp0)
 
-	// Initialize database component
 	mongoComp0 := mongo.NewMongoDBComponent(log, cfg.Mongo["mongo-0"])
-	if mongoComp0 != nil {
-		appSubComp.Add(mongoComp0, 10*time.Second)
-	}
-
-	// Stage 1: Start independent components (no dependencies)
-	independent := []app.Component{redisComp0, mongoComp0}
-
-	// Create a shared context that cancels on SIGINT/SIGTERM
-	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
-	defer stop()
-
-	// Use an errgroup to manage their goroutines
-	indepGroup, indepCtx := errgroup.WithContext(ctx)
-	for _, comp := range independent {
-		indepGroup.Go(func() error {
-			return comp.Start(indepCtx)
-		})
-	}
+	a.Add(mongoComp0)
 
-	// Wait for each independent component to be ready (with timeout)
-	for _, comp := range independent {
-		select {
-		case <-comp.Ready():
-			log.Info().Str("component", fmt.Sprintf("%T", comp)).Msg("ready")
-		case <-time.After(10 * time.Second):
-			log.Fatal().Msgf("timeout waiting for component %T", comp)
-		}
-	}
-
-	// Now build the service component (which depends on database, mongo, query, etc.)
 	serviceComp := config.NewServiceComponent(log, redisComp0, mongoComp0, cfg.Repository)
+	a.Add(serviceComp, app.DependsOn(redisComp0, mongoComp0))
+
+	// Apply reloaded log level and rate limit; the rate limit needs the
+	// repository, so the watcher waits for the service
+	watcher := commonConfig.NewWatcher(log, src, cfg)
+	watcher.Subscribe(func(cfg *config.Config) {
+		logger.SetLevel(cfg.Logger.Level)
+		serviceComp.SetRateLimit(cfg.Repository.NotificationOpts.RateLimit)
+	})
+	a.Add(watcher, app.DependsOn(serviceComp))
 
-	// Start service component manually before creating consumer (needs service to be initialized)
-	indepGroup.Go(func() error {
-		return serviceComp.Start(indepCtx)
+	// The consumer handler needs the initialized service
+	consumerComp := app.NewDeferred(func() (app.Component, error) {
+		consumerHandler := pubsub.NewConsumerGroupHandler(log, serviceComp.Service())
+		return kafkaconsumer.NewKafkaConsumerComponent(log, cfg.KafkaConsumer, consumerHandler), nil
 	})
+	a.Add(consumerComp, app.DependsOn(serviceComp))
 
-	// Wait for service to be ready
-	select {
-	case <-serviceComp.Ready():
-		log.Info().Str("component", fmt.Sprintf("%T", serviceComp)).Msg("ready")
-	case <-time.After(10 * time.Second):
-		log.Fatal().Msgf("timeout waiting for component %T", serviceComp)
-	}
+	// The service has no API of its own; the HTTP server only serves the
+	// health probes
+	mw := middleware.Init(log)
+	gin := http.Init(log, mw, cfg.Http)
 
-	// Ensure service component is stopped on exit
-	defer func() {
-		stopCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
-		if err := serviceComp.Stop(stopCtx); err != nil {
-			log.Error().Err(err).Msg("error stopping service component")
-		}
-	}()
-
-	// Build Kafka consumer
-	consumerHandler := pubsub.NewConsumerGroupHandler(log, serviceComp.Service())
-	consumerComp := kafkaconsumer.NewKafkaConsumerComponent(log, cfg.KafkaConsumer, consumerHandler)
-	appMainComp.Add(consumerComp, 10*time.Second)
-
-	// Run the app – now all components are added and will start in the order they were added.
-	if err := appMainComp.Run(); err != nil {
-		log.Fatal().Err(err).Msg("app failed")
-	}
+	httpServerComp := server.NewHTTPServerComponent(log, cfg.Server, mw, gin, func(ctx context.Context, engine *server.Engine) error {
+		server.RegisterHealth(engine, a)
+		server.RegisterMetrics(engine)
+		return nil
+	})
+	a.Add(httpServerComp)
 
-	if err := indepGroup.Wait(); err != nil && err != context.Canceled {
-		log.Error().Err(err).Msg("Independent component error")
+	if err := a.Run(); err != nil {
+		log.Fatal().Err(err).Msg("app failed")
 	}
 }
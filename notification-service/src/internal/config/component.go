@@ -7,6 +7,7 @@ import (
 	"github.com/linggaaskaedo/go-kill/common/component/mongo"
 	"github.com/linggaaskaedo/go-kill/common/component/redis"
 	"github.com/linggaaskaedo/go-kill/notification-service/src/internal/repository"
+	"github.com/linggaaskaedo/go-kill/notification-service/src/internal/repository/notification"
 	"github.com/linggaaskaedo/go-kill/notification-service/src/internal/service"
 
 	"github.com/rs/zerolog"
@@ -80,6 +81,12 @@ func (s *ServiceComponent) Service() *service.Service {
 	return s.service
 }
 
+// SetRateLimit applies a reloaded notification rate limit. The component
+// must be ready.
+func (s *ServiceComponent) SetRateLimit(opts notification.RateLimitOpts) {
+	s.repo.Notification.SetRateLimit(opts)
+}
+
 func (s *ServiceComponent) Ready() <-chan struct{} {
 	return s.ready
 }
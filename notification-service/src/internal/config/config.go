@@ -1,37 +1,32 @@
 package config
 
 import (
-	"os"
-
+	"github.com/linggaaskaedo/go-kill/common/component/http"
 	"github.com/linggaaskaedo/go-kill/common/component/kafkaconsumer"
 	"github.com/linggaaskaedo/go-kill/common/component/mongo"
 	"github.com/linggaaskaedo/go-kill/common/component/redis"
+	"github.com/linggaaskaedo/go-kill/common/component/server"
+	"github.com/linggaaskaedo/go-kill/common/component/tracer"
+	commonConfig "github.com/linggaaskaedo/go-kill/common/pkg/config"
 	"github.com/linggaaskaedo/go-kill/common/pkg/logger"
 	"github.com/linggaaskaedo/go-kill/notification-service/src/internal/repository"
-
-	"github.com/goccy/go-yaml"
 )
 
 type Config struct {
 	Logger        logger.Config           `yaml:"logger"`
+	Tracer        tracer.Config           `yaml:"tracer"`
 	Redis         redis.Config            `yaml:"redis"`
-	Mongo         map[string]mongo.Config `yaml:"mongo"`
+	Mongo         map[string]mongo.Config `yaml:"mongo" validate:"dive"`
+	Http          http.Config             `yaml:"http"`
+	Server        server.Config           `yaml:"server"`
 	KafkaConsumer kafkaconsumer.Config    `yaml:"kafka_consumer"`
 
 	Repository repository.Options `yaml:"repository"`
 }
 
-func Load(configPath string) (*Config, error) {
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		return nil, err
-	}
-
-	// Expand environment variables in the YAML content
-	expandedData := os.ExpandEnv(string(data))
-
+func Load(src commonConfig.Source) (*Config, error) {
 	var cfg Config
-	if err := yaml.Unmarshal([]byte(expandedData), &cfg); err != nil {
+	if err := commonConfig.Load(src, &cfg); err != nil {
 		return nil, err
 	}
 
@@ -4,8 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"strings"
 	"time"
 
+	"github.com/linggaaskaedo/go-kill/common/pkg/tracing"
+	"github.com/linggaaskaedo/go-kill/common/pkg/tracing/kafkatracing"
 	"github.com/linggaaskaedo/go-kill/notification-service/src/internal/model/dto"
 	"github.com/linggaaskaedo/go-kill/notification-service/src/internal/service"
 
@@ -54,9 +57,14 @@ func (h *ConsumerGroupHandler) processMessageWithContext(sess sarama.ConsumerGro
 	logWithReq := h.log.With().Str("req_id", reqID).Logger()
 	ctx = logWithReq.WithContext(ctx)
 
+	// Continue the trace the producer sent in the headers
+	ctx, span := kafkatracing.StartProcessSpan(ctx, msg)
+
 	zerolog.Ctx(ctx).Info().Str("topic", msg.Topic).Msg("received message")
 
-	if err := h.processMessage(ctx, msg); err != nil {
+	err := h.processMessage(ctx, msg)
+	tracing.End(span, err)
+	if err != nil {
 		zerolog.Ctx(ctx).Error().Err(err).Msg("failed to process message, will retry")
 		return
 	}
@@ -76,6 +84,18 @@ func extractReqIDFromMessage(msg *sarama.ConsumerMessage) string {
 }
 
 func (h *ConsumerGroupHandler) processMessage(ctx context.Context, msg *sarama.ConsumerMessage) error {
+	var envelope struct {
+		EventType string `json:"event_type"`
+	}
+	if err := json.Unmarshal(msg.Value, &envelope); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed parse json")
+		return err
+	}
+
+	if strings.HasPrefix(envelope.EventType, "auth.") {
+		return h.processAuthMessage(ctx, msg)
+	}
+
 	var event dto.OrderEvent
 	if err := json.Unmarshal(msg.Value, &event); err != nil {
 		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed parse json")
@@ -116,6 +136,27 @@ func (h *ConsumerGroupHandler) processMessage(ctx context.Context, msg *sarama.C
 	return nil
 }
 
+// processAuthMessage sends account emails. They skip preferences and rate
+// limits: a user who asked for a reset link must get it.
+func (h *ConsumerGroupHandler) processAuthMessage(ctx context.Context, msg *sarama.ConsumerMessage) error {
+	var event dto.AuthEvent
+	if err := json.Unmarshal(msg.Value, &event); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed parse json")
+		return err
+	}
+
+	zerolog.Ctx(ctx).Info().Str("event_type", event.EventType).Str("auth_id", event.Data.AuthID).Msg("processing event")
+
+	switch event.EventType {
+	case "auth.verification_requested", "auth.password_reset_requested":
+		return h.service.Notification.SendAuthEmail(ctx, event)
+	default:
+		zerolog.Ctx(ctx).Warn().Str("event_type", event.EventType).Msg("unknown event type, skipping")
+	}
+
+	return nil
+}
+
 func (h *ConsumerGroupHandler) sendNotificationIfEnabled(ctx context.Context, enabled bool, sendFunc func() error, logMsg string) {
 	if !enabled {
 		return
@@ -26,6 +26,22 @@ type OrderItem struct {
 	UnitPrice   float64 `json:"unit_price"`
 }
 
+// AuthEvent carries a single-use account link from auth-service. It is not
+// subject to notification preferences or rate limits; the user asked for it.
+type AuthEvent struct {
+	EventID   string        `json:"event_id"`
+	EventType string        `json:"event_type"`
+	Timestamp time.Time     `json:"timestamp"`
+	Data      AuthEventData `json:"data"`
+}
+
+type AuthEventData struct {
+	AuthID    string    `json:"auth_id"`
+	Email     string    `json:"email"`
+	Link      string    `json:"link"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
 type Notification struct {
 	UserID    string                 `bson:"user_id"`
 	Type      string                 `bson:"type"`
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/linggaaskaedo/go-kill/notification-service/src/internal/model/dto"
@@ -18,32 +19,40 @@ type NotificationRepositoryItf interface {
 	SendOrderConfirmation(ctx context.Context, event dto.OrderEvent) error
 	SendOrderUpdate(ctx context.Context, event dto.OrderEvent) error
 	SendOrderCancellation(ctx context.Context, event dto.OrderEvent) error
+	SendAuthEmail(ctx context.Context, event dto.AuthEvent) error
+	SetRateLimit(opts RateLimitOpts)
 }
 
 type notificationRepository struct {
 	redis0 *redis.Client
 	mongo0 *mongo.Database
 	opts   Options
+
+	// rateLimit is swapped on configuration reloads
+	rateLimit atomic.Pointer[RateLimitOpts]
 }
 
 type Options struct {
 	Notifications           string        `yaml:"notifications"`
 	NotificationPreferences string        `yaml:"notification_preferences"`
 	NotificationTemplates   string        `yaml:"notification_templates"`
-	RateLimit               RateLimitOpts `yaml:"rate_limit"`
+	RateLimit               RateLimitOpts `yaml:"rate_limit" reload:"true"`
 }
 
 type RateLimitOpts struct {
-	MaxPerHour int           `yaml:"max_per_hour"`
-	Window     time.Duration `yaml:"window"`
+	MaxPerHour int           `yaml:"max_per_hour" default:"10" validate:"min=1"`
+	Window     time.Duration `yaml:"window" default:"1h" validate:"min=1s"`
 }
 
 func InitNotificationRepository(redis0 *redis.Client, mongo0 *mongo.Database, opts Options) NotificationRepositoryItf {
-	return &notificationRepository{
+	r := &notificationRepository{
 		redis0: redis0,
 		mongo0: mongo0,
 		opts:   opts,
 	}
+	r.rateLimit.Store(&opts.RateLimit)
+
+	return r
 }
 
 func replaceTemplate(template string, vars map[string]string) string {
@@ -25,3 +25,11 @@ func (r *notificationRepository) SendOrderUpdate(ctx context.Context, event dto.
 func (r *notificationRepository) SendOrderCancellation(ctx context.Context, event dto.OrderEvent) error {
 	return r.sendOrderCancellationMongo(ctx, event)
 }
+
+func (r *notificationRepository) SendAuthEmail(ctx context.Context, event dto.AuthEvent) error {
+	return r.sendAuthEmailMongo(ctx, event)
+}
+
+func (r *notificationRepository) SetRateLimit(opts RateLimitOpts) {
+	r.rateLimit.Store(&opts)
+}
@@ -11,11 +11,12 @@ import (
 func (r *notificationRepository) checkRateLimitCache(ctx context.Context, userID string) bool {
 	key := fmt.Sprintf("rate_limit:%s:order_notifications", userID)
 
-	ttl := r.opts.RateLimit.Window
+	rateLimit := r.rateLimit.Load()
+	ttl := rateLimit.Window
 	if ttl == 0 {
 		ttl = time.Hour
 	}
-	maxPerHour := r.opts.RateLimit.MaxPerHour
+	maxPerHour := rateLimit.MaxPerHour
 	if maxPerHour == 0 {
 		maxPerHour = 10
 	}
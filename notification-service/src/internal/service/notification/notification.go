@@ -13,6 +13,7 @@ type NotificationServiceItf interface {
 	SendOrderConfirmation(ctx context.Context, event dto.OrderEvent) error
 	SendOrderUpdate(ctx context.Context, event dto.OrderEvent) error
 	SendOrderCancellation(ctx context.Context, event dto.OrderEvent) error
+	SendAuthEmail(ctx context.Context, event dto.AuthEvent) error
 }
 
 type notificationService struct {
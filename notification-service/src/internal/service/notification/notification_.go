@@ -25,3 +25,7 @@ func (s *notificationService) SendOrderUpdate(ctx context.Context, event dto.Ord
 func (s *notificationService) SendOrderCancellation(ctx context.Context, event dto.OrderEvent) error {
 	return s.notificationRepository.SendOrderCancellation(ctx, event)
 }
+
+func (s *notificationService) SendAuthEmail(ctx context.Context, event dto.AuthEvent) error {
+	return s.notificationRepository.SendAuthEmail(ctx, event)
+}
@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE outbox (
+    id UUID PRIMARY KEY DEFAULT uuid_v7(),
+    aggregate_id UUID NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    topic VARCHAR(255) NOT NULL,
+    message_key VARCHAR(255) NOT NULL,
+    payload JSON NOT NULL,
+    status ENUM('pending', 'sent', 'failed') DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    claim_token CHAR(36),
+    next_attempt_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    sent_at TIMESTAMP NULL,
+    INDEX idx_status_next_attempt_at (status, next_attempt_at),
+    INDEX idx_claim_token (claim_token),
+    INDEX idx_aggregate_id (aggregate_id)
+) ENGINE=InnoDB;
+
+-- +goose Down
+DROP TABLE IF EXISTS outbox;
@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE order_sagas (
+    id UUID PRIMARY KEY DEFAULT uuid_v7(),
+    order_id UUID NULL,
+    user_id CHAR(36) NOT NULL,
+    status ENUM('started', 'inventory_reserved', 'order_created', 'payment_created', 'completed', 'compensating', 'compensated', 'failed') DEFAULT 'started',
+    items JSON NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_order_id (order_id),
+    INDEX idx_status_updated_at (status, updated_at)
+) ENGINE=InnoDB;
+
+-- +goose Down
+DROP TABLE IF EXISTS order_sagas;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE order_sagas ADD COLUMN reservation_id UUID NULL AFTER order_id;
+
+-- +goose Down
+ALTER TABLE order_sagas DROP COLUMN reservation_id;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE order_status_history ADD COLUMN actor VARCHAR(255) NULL AFTER status;
+
+-- +goose Down
+ALTER TABLE order_status_history DROP COLUMN actor;
@@ -0,0 +1,6 @@
+-- +goose Up
+ALTER TABLE payments MODIFY COLUMN status ENUM('pending', 'authorized', 'completed', 'failed', 'refunded') DEFAULT 'pending';
+
+-- +goose Down
+UPDATE payments SET status = 'pending' WHERE status = 'authorized';
+ALTER TABLE payments MODIFY COLUMN status ENUM('pending', 'completed', 'failed', 'refunded') DEFAULT 'pending';
@@ -0,0 +1,9 @@
+-- +goose Up
+CREATE TABLE order_number_sequences (
+    day DATE PRIMARY KEY,
+    last_value BIGINT NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB;
+
+-- +goose Down
+DROP TABLE IF EXISTS order_number_sequences;
@@ -1,27 +1,37 @@
 -- name: CreateOrder
-INSERT INTO orders (user_id, order_number, status, total_amount, shipping_address_id, billing_address_id, created_at, updated_at)
-VALUES (?, ?, ?, ?, ?, ?, NOW(), NOW());
+INSERT INTO orders (id, user_id, order_number, status, total_amount, shipping_address_id, billing_address_id, created_at, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, NOW(), NOW());
 
 -- name: GetLastInsertID
 SELECT LAST_INSERT_ID();
 
+-- name: NextOrderNumber
+INSERT INTO order_number_sequences (day, last_value)
+VALUES (?, LAST_INSERT_ID(1))
+ON DUPLICATE KEY UPDATE last_value = LAST_INSERT_ID(last_value + 1);
+
 -- name: CreateOrderItemsNamed
 INSERT INTO order_items (order_id, product_id, product_name, quantity, unit_price, subtotal, created_at) 
 VALUES (:order_id, :product_id, :product_name, :quantity, :unit_price, :subtotal, NOW());
 
 -- name: CreatePayment
-INSERT INTO payments (order_id, payment_method, amount, status, created_at, updated_at)
-VALUES (?, ?, ?, 'pending', NOW(), NOW());
+INSERT INTO payments (id, order_id, payment_method, amount, status, created_at, updated_at)
+VALUES (?, ?, ?, ?, 'pending', NOW(), NOW());
 
 -- name: CreateStatusHistory
-INSERT INTO order_status_history (order_id, status, note, created_at)
-VALUES (?, ?, ?, NOW());
+INSERT INTO order_status_history (order_id, status, actor, note, created_at)
+VALUES (?, ?, ?, ?, NOW());
 
 -- name: GetOrder
 SELECT id, order_number, status, total_amount 
 FROM orders 
 WHERE id = ? AND user_id = ?;
 
+-- name: GetOrderByID
+SELECT id, user_id, order_number, status, total_amount 
+FROM orders 
+WHERE id = ?;
+
 -- name: GetOrderItem
 SELECT id, product_id, product_name, quantity, unit_price, subtotal 
 FROM order_items 
@@ -41,5 +51,86 @@ WHERE user_id = ?;
 
 -- name: UpdateOrderStatus
 UPDATE orders 
-SET status = 'cancelled', updated_at = NOW() 
-WHERE id = ?;
\ No newline at end of file
+SET status = ?, updated_at = NOW() 
+WHERE id = ? AND status = ?;
+
+-- name: CreateOutbox
+INSERT INTO outbox (aggregate_id, event_type, topic, message_key, payload, status, attempts, next_attempt_at, created_at)
+VALUES (?, ?, ?, ?, ?, 'pending', 0, NOW(), NOW());
+
+-- name: ClaimOutbox
+UPDATE outbox
+SET claim_token = ?, next_attempt_at = DATE_ADD(NOW(), INTERVAL ? SECOND)
+WHERE status = 'pending' AND next_attempt_at <= NOW()
+ORDER BY created_at
+LIMIT ?;
+
+-- name: GetClaimedOutbox
+SELECT id, aggregate_id, event_type, topic, message_key, payload, status, attempts
+FROM outbox
+WHERE claim_token = ? AND status = 'pending'
+ORDER BY created_at;
+
+-- name: MarkOutboxSent
+UPDATE outbox
+SET status = 'sent', attempts = attempts + 1, last_error = NULL, claim_token = NULL, sent_at = NOW()
+WHERE id = ? AND claim_token = ?;
+
+-- name: MarkOutboxRetry
+UPDATE outbox
+SET status = ?, attempts = attempts + 1, last_error = ?, claim_token = NULL, next_attempt_at = DATE_ADD(NOW(), INTERVAL ? SECOND)
+WHERE id = ? AND claim_token = ?;
+
+-- name: CreateSaga
+INSERT INTO order_sagas (id, reservation_id, user_id, status, items, attempts, created_at, updated_at)
+VALUES (?, ?, ?, ?, ?, 0, NOW(), NOW());
+
+-- name: UpdateSagaStatus
+UPDATE order_sagas
+SET status = ?, order_id = COALESCE(?, order_id), last_error = ?, updated_at = NOW()
+WHERE id = ? AND status = ?;
+
+-- name: UpdateSagaReservation
+UPDATE order_sagas
+SET reservation_id = ?, updated_at = NOW()
+WHERE id = ? AND status = ?;
+
+-- name: GetSagaByOrderID
+SELECT id, order_id, reservation_id, user_id, status, items, attempts, last_error
+FROM order_sagas
+WHERE order_id = ?;
+
+-- name: GetStaleSagas
+SELECT id, order_id, reservation_id, user_id, status, items, attempts, last_error
+FROM order_sagas
+WHERE status IN ('started', 'inventory_reserved', 'order_created', 'payment_created', 'compensating')
+  AND updated_at <= DATE_SUB(NOW(), INTERVAL ? SECOND)
+ORDER BY updated_at
+LIMIT ?;
+
+-- name: ClaimSaga
+UPDATE order_sagas
+SET attempts = attempts + 1, updated_at = NOW()
+WHERE id = ? AND status = ? AND updated_at <= DATE_SUB(NOW(), INTERVAL ? SECOND);
+
+-- name: GetPayment
+SELECT id, order_id, payment_method, amount, status, transaction_id, created_at, updated_at
+FROM payments
+WHERE id = ?;
+
+-- name: GetPaymentByOrderID
+SELECT id, order_id, payment_method, amount, status, transaction_id, created_at, updated_at
+FROM payments
+WHERE order_id = ?
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: UpdatePaymentStatus
+UPDATE payments
+SET status = ?, transaction_id = COALESCE(?, transaction_id), updated_at = NOW()
+WHERE id = ? AND status = ?;
+
+-- name: CancelOrderPayment
+UPDATE payments
+SET status = 'failed', updated_at = NOW()
+WHERE order_id = ? AND status = 'pending';
@@ -3,22 +3,31 @@ package main
 import (
 	"context"
 	"flag"
-	"fmt"
-	"os/signal"
-	"syscall"
 	"time"
 
 	"github.com/linggaaskaedo/go-kill/common/app"
 	"github.com/linggaaskaedo/go-kill/common/component/database"
 	"github.com/linggaaskaedo/go-kill/common/component/grpcclient"
 	"github.com/linggaaskaedo/go-kill/common/component/grpcserver"
+	"github.com/linggaaskaedo/go-kill/common/component/http"
 	"github.com/linggaaskaedo/go-kill/common/component/kafkaproducer"
 	"github.com/linggaaskaedo/go-kill/common/component/query"
+	"github.com/linggaaskaedo/go-kill/common/component/redis"
+	"github.com/linggaaskaedo/go-kill/common/component/scheduler"
+	"github.com/linggaaskaedo/go-kill/common/component/server"
+	"github.com/linggaaskaedo/go-kill/common/component/tracer"
+	"github.com/linggaaskaedo/go-kill/common/pkg/authz"
+	commonConfig "github.com/linggaaskaedo/go-kill/common/pkg/config"
+	"github.com/linggaaskaedo/go-kill/common/pkg/idempotency"
 	"github.com/linggaaskaedo/go-kill/common/pkg/logger"
+	"github.com/linggaaskaedo/go-kill/common/pkg/metrics"
+	"github.com/linggaaskaedo/go-kill/common/pkg/middleware"
+	authpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/auth"
 	orderpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/order"
 	"github.com/linggaaskaedo/go-kill/order-service/src/internal/config"
+	restHandler "github.com/linggaaskaedo/go-kill/order-service/src/internal/handler/rest"
+	sched "github.com/linggaaskaedo/go-kill/order-service/src/internal/handler/scheduler"
 
-	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
 )
 
@@ -40,6 +49,8 @@ var (
 // @host			localhost:8080
 // @schemes		http https
 func main() {
+	src := commonConfig.Source{File: "config.yaml", EnvPrefix: "ORDER"}
+	src.RegisterFlags(flag.CommandLine)
 	flag.IntVar(&minJitter, "minSleep", DefaultMinJitter, "min. sleep duration during app initialization")
 	flag.IntVar(&maxJitter, "maxSleep", DefaultMaxJitter, "max. sleep duration during app initialization")
 	flag.Parse()
@@ -48,7 +59,7 @@ func main() {
 	sleepWithJitter(minJitter, maxJitter)
 
 	// Load config
-	cfg, err := config.Load("config.yaml")
+	cfg, err := config.Load(src)
 	if err != nil {
 		panic(err)
 	}
@@ -56,82 +67,94 @@ func main() {
 	// Initialize logger
 	log := logger.Init(cfg.Logger)
 
+	// Label the metrics with the service name
+	metrics.Init("order-service")
+
 	log.Info().Msg("Starting order service...")
 
 	// Create application with options
-	appSubComp := app.New(app.WithShutdownTimeout(15*time.Second), app.WithLogger(log))
-	appMainComp := app.New(app.WithShutdownTimeout(15*time.Second), app.WithLogger(log))
+	a := app.New(app.WithShutdownTimeout(10*time.Second), app.WithLogger(log))
+
+	// Added first so that it stops last, exporting the spans of shutdown
+	a.Add(tracer.NewTracerComponent(log, cfg.Tracer))
+
+	// Apply the reloadable settings when the config changes
+	watcher := commonConfig.NewWatcher(log, src, cfg)
+	watcher.Subscribe(func(cfg *config.Config) {
+		logger.SetLevel(cfg.Logger.Level)
+	})
+	a.Add(watcher)
+
+	redisComp0 := redis.NewRedisComponent(log, cfg.Redis)
+	a.Add(redisComp0)
 
-	// Initialize database component
 	dbComp0 := database.NewDatabaseComponent(log, cfg.Database["db-0"])
-	if dbComp0 != nil {
-		appSubComp.Add(dbComp0, 10*time.Second)
-	}
+	a.Add(dbComp0)
 
-	// Initialize query loader component
 	queryComp := query.NewQueryComponent(log, cfg.Query)
-	appSubComp.Add(queryComp, 10*time.Second)
+	a.Add(queryComp)
+
+	authClientComp := grpcclient.NewGRPCClientComponent(log, cfg.GRPCClient["auth_service"])
+	a.Add(authClientComp, app.Name("auth_service"))
 
-	// Initialize gRPC client components
 	userClientComp := grpcclient.NewGRPCClientComponent(log, cfg.GRPCClient["user_service"])
-	appSubComp.Add(userClientComp, 10*time.Second)
+	a.Add(userClientComp, app.Name("user_service"))
 
 	productClientComp := grpcclient.NewGRPCClientComponent(log, cfg.GRPCClient["product_service"])
-	appSubComp.Add(productClientComp, 10*time.Second)
+	a.Add(productClientComp, app.Name("product_service"))
 
-	// Kafka Producer
 	kafkaProducerComp := kafkaproducer.NewKafkaProducerComponent(log, cfg.KafkaProducer)
-	appSubComp.Add(kafkaProducerComp, 10*time.Second)
+	a.Add(kafkaProducerComp)
 
-	// Stage 1: Start independent components (no dependencies)
-	independent := []app.Component{dbComp0, queryComp, userClientComp, productClientComp, kafkaProducerComp}
+	serviceComp := config.NewServiceComponent(log, dbComp0, queryComp, authClientComp, userClientComp, productClientComp, kafkaProducerComp, cfg.Service)
+	a.Add(serviceComp, app.DependsOn(dbComp0, queryComp, authClientComp, userClientComp, productClientComp, kafkaProducerComp))
 
-	// Create a shared context that cancels on SIGINT/SIGTERM
-	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
-	defer stop()
-
-	// Use an errgroup to manage their goroutines
-	indepGroup, indepCtx := errgroup.WithContext(ctx)
-	for _, comp := range independent {
-		indepGroup.Go(func() error {
-			return comp.Start(indepCtx)
-		})
-	}
-
-	// Wait for each independent component to be ready (with timeout)
-	for _, comp := range independent {
-		select {
-		case <-comp.Ready():
-			log.Info().Str("component", fmt.Sprintf("%T", comp)).Msg("ready")
-		case <-time.After(10 * time.Second):
-			log.Fatal().Msgf("timeout waiting for component %T", comp)
-		}
-	}
+	// Outbox relay and saga recovery
+	schedComp := scheduler.NewSchedulerComponent(log, func() ([]scheduler.Job, error) {
+		outboxRelayJob := sched.NewOutboxRelayJob(log, serviceComp.Service().Order, cfg.Scheduler["job-0"])
+		sagaRecoveryJob := sched.NewSagaRecoveryJob(log, serviceComp.Service().Order, cfg.Scheduler["job-1"])
+		return []scheduler.Job{outboxRelayJob, sagaRecoveryJob}, nil
+	})
+	a.Add(schedComp, app.DependsOn(serviceComp))
 
-	// Now build the service component (which depends on database, mongo, query, etc.)
-	serviceComp := config.NewServiceComponent(log, dbComp0, queryComp, userClientComp, productClientComp, kafkaProducerComp)
-	appMainComp.Add(serviceComp, 10*time.Second)
+	// Idempotency keys for CreateOrder, over gRPC and REST
+	idem := idempotency.New(idempotency.NewRedisStore(redisComp0.Client()), cfg.Idempotency)
 
-	// Now build gRPC server (depends on service)
 	grpcServerComp := grpcserver.NewGRPCServerComponent(log, cfg.GRPCServer, func(ctx context.Context, s *grpc.Server) error {
-		select {
-		case <-serviceComp.Ready():
-			orderpb.RegisterOrderServiceServer(s, serviceComp.GrpcHandler())
-			return nil
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-time.After(10 * time.Second):
-			return fmt.Errorf("timeout waiting for gRPC Server")
+		orderpb.RegisterOrderServiceServer(s, serviceComp.GrpcHandler())
+		return nil
+	}, grpcserver.AuthorizationUnaryServerInterceptor(func(ctx context.Context, creds grpcserver.Credentials) (*authz.Principal, error) {
+		var resp *authpb.ValidateTokenResponse
+		var err error
+		if creds.APIKey != "" {
+			resp, err = serviceComp.Service().Order.ValidateApiKey(ctx, &authpb.ValidateApiKeyRequest{ApiKey: creds.APIKey})
+		} else {
+			resp, err = serviceComp.Service().Order.ValidateToken(ctx, &authpb.ValidateTokenRequest{Token: creds.Token})
 		}
+		if err != nil {
+			return nil, err
+		}
+
+		return &authz.Principal{UserID: resp.UserId, Roles: resp.Roles, Permissions: resp.Permissions}, nil
+	}, grpcserver.MethodPermissions{
+		// Status changes outside the order flow are an admin operation
+		orderpb.OrderService_UpdateOrderStatus_FullMethodName: {authz.PermOrderStatusUpdate},
+	}), idem.UnaryServerInterceptor(grpcserver.Principal))
+	grpcServerComp.SetHealthCheck(a.Serving)
+	a.Add(grpcServerComp, app.DependsOn(serviceComp, redisComp0))
+
+	mw := middleware.Init(log)
+	gin := http.Init(log, mw, cfg.Http)
+
+	httpServerComp := server.NewHTTPServerComponent(log, cfg.Server, mw, gin, func(ctx context.Context, engine *server.Engine) error {
+		server.RegisterHealth(engine, a)
+		server.RegisterMetrics(engine)
+		restHandler.InitRestHandler(engine, serviceComp.Service(), idem)
+		return nil
 	})
-	appMainComp.Add(grpcServerComp, 10*time.Second)
+	a.Add(httpServerComp, app.DependsOn(serviceComp, redisComp0))
 
-	// Run the app – now all components are added and will start in the order they were added.
-	if err := appMainComp.Run(); err != nil {
+	if err := a.Run(); err != nil {
 		log.Fatal().Err(err).Msg("app failed")
 	}
-
-	if err := indepGroup.Wait(); err != nil && err != context.Canceled {
-		log.Error().Err(err).Msg("Independent component error")
-	}
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/linggaaskaedo/go-kill/common/component/kafkaproducer"
 	"github.com/linggaaskaedo/go-kill/common/component/query"
 	grpcHandler "github.com/linggaaskaedo/go-kill/order-service/src/internal/handler/grpc"
+	"github.com/linggaaskaedo/go-kill/order-service/src/internal/provider/payment"
 	"github.com/linggaaskaedo/go-kill/order-service/src/internal/repository"
 	"github.com/linggaaskaedo/go-kill/order-service/src/internal/service"
 
@@ -18,9 +19,11 @@ type ServiceComponent struct {
 	log               zerolog.Logger
 	dbComp0           *database.DatabaseComponent
 	queryComp         *query.QueryComponent
+	authClientComp    *grpcclient.GRPCClientComponent
 	userClientComp    *grpcclient.GRPCClientComponent
 	productClientComp *grpcclient.GRPCClientComponent
 	kafkaProducerComp *kafkaproducer.KafkaProducerComponent
+	svcOpts           service.Options
 
 	repo        *repository.Repository
 	service     *service.Service
@@ -32,24 +35,33 @@ func NewServiceComponent(
 	log zerolog.Logger,
 	dbComp0 *database.DatabaseComponent,
 	queryComp *query.QueryComponent,
+	authClientComp *grpcclient.GRPCClientComponent,
 	userClientComp *grpcclient.GRPCClientComponent,
 	productClientComp *grpcclient.GRPCClientComponent,
 	kafkaProducerComp *kafkaproducer.KafkaProducerComponent,
+	svcOpts service.Options,
 ) *ServiceComponent {
 	return &ServiceComponent{
 		log:               log,
 		dbComp0:           dbComp0,
 		queryComp:         queryComp,
+		authClientComp:    authClientComp,
 		userClientComp:    userClientComp,
 		productClientComp: productClientComp,
 		kafkaProducerComp: kafkaProducerComp,
+		svcOpts:           svcOpts,
 		ready:             make(chan struct{}),
 	}
 }
 
 func (s *ServiceComponent) Start(ctx context.Context) error {
-	s.repo = repository.InitRepository(s.dbComp0.Client(), s.queryComp, s.productClientComp.Conn())
-	s.service = service.InitService(s.repo, s.userClientComp.Conn(), s.productClientComp.Conn(), s.kafkaProducerComp)
+	paymentProvider, err := payment.NewPaymentProvider(s.svcOpts.PaymentOpts)
+	if err != nil {
+		return err
+	}
+
+	s.repo = repository.InitRepository(s.dbComp0.Client(), s.queryComp)
+	s.service = service.InitService(s.repo, s.authClientComp.Conn(), s.userClientComp.Conn(), s.productClientComp.Conn(), s.kafkaProducerComp, paymentProvider, s.svcOpts)
 	s.grpcHandler = grpcHandler.InitGrpcHandler(s.log, s.service)
 
 	close(s.ready) // signal that service is ready
@@ -59,22 +71,11 @@ func (s *ServiceComponent) Start(ctx context.Context) error {
 	return nil
 }
 
+// Stop leaves the clients and the database alone; the app stops them after
+// this component.
 func (s *ServiceComponent) Stop(ctx context.Context) error {
 	s.log.Info().Msg("Service component stopping")
 
-	if err := s.userClientComp.Stop(ctx); err != nil {
-		s.log.Error().Err(err).Msg("failed to stop user client")
-	}
-	if err := s.productClientComp.Stop(ctx); err != nil {
-		s.log.Error().Err(err).Msg("failed to stop product client")
-	}
-	if err := s.kafkaProducerComp.Stop(ctx); err != nil {
-		s.log.Error().Err(err).Msg("failed to stop kafka producer")
-	}
-	if err := s.dbComp0.Stop(ctx); err != nil {
-		s.log.Error().Err(err).Msg("failed to stop database")
-	}
-
 	s.log.Info().Msg("Service component stopped")
 	return nil
 }
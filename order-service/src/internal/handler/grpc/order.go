@@ -111,3 +111,26 @@ func (g *Grpc) CancelOrder(ctx context.Context, req *orderpb.CancelOrderRequest)
 
 	return &orderpb.CancelOrderResponse{Success: true}, nil
 }
+
+func (g *Grpc) UpdateOrderStatus(ctx context.Context, req *orderpb.UpdateOrderStatusRequest) (*orderpb.UpdateOrderStatusResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	reqData := &dto.UpdateOrderStatusRequest{
+		OrderID: req.OrderId,
+		Status:  req.Status,
+		Actor:   req.Actor,
+		Note:    req.Note,
+	}
+
+	previous, err := g.svc.Order.UpdateOrderStatus(ctx, reqData)
+	if err != nil {
+		return nil, err
+	}
+
+	return &orderpb.UpdateOrderStatusResponse{
+		Success:        true,
+		PreviousStatus: string(previous),
+		Status:         req.Status,
+	}, nil
+}
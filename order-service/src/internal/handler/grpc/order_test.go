@@ -3,8 +3,10 @@ package grpc
 import (
 	"context"
 	"errors"
+	"net/http"
 	"testing"
 
+	authpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/auth"
 	orderpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/order"
 	"github.com/linggaaskaedo/go-kill/order-service/src/internal/model/dto"
 	"github.com/linggaaskaedo/go-kill/order-service/src/internal/model/entity"
@@ -62,6 +64,79 @@ func (m *MockOrderService) CancelOrder(ctx context.Context, reqData *dto.CancelO
 	return args.Error(0)
 }
 
+func (m *MockOrderService) UpdateOrderStatus(ctx context.Context, reqData *dto.UpdateOrderStatusRequest) (entity.OrderStatus, error) {
+	args := m.Called(mock.Anything, reqData)
+	return args.Get(0).(entity.OrderStatus), args.Error(1)
+}
+
+func (m *MockOrderService) ValidateToken(ctx context.Context, req *authpb.ValidateTokenRequest) (*authpb.ValidateTokenResponse, error) {
+	args := m.Called(mock.Anything, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*authpb.ValidateTokenResponse), args.Error(1)
+}
+
+func (m *MockOrderService) ValidateApiKey(ctx context.Context, req *authpb.ValidateApiKeyRequest) (*authpb.ValidateTokenResponse, error) {
+	args := m.Called(mock.Anything, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*authpb.ValidateTokenResponse), args.Error(1)
+}
+
+func (m *MockOrderService) CreateUserOrder(ctx context.Context, userAuthID string, req dto.CreateUserOrderRequest) (*dto.CreateOrderResp, error) {
+	args := m.Called(mock.Anything, userAuthID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.CreateOrderResp), args.Error(1)
+}
+
+func (m *MockOrderService) GetUserOrder(ctx context.Context, userAuthID string, orderID string) (*entity.Order, error) {
+	args := m.Called(mock.Anything, userAuthID, orderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Order), args.Error(1)
+}
+
+func (m *MockOrderService) ListUserOrders(ctx context.Context, userAuthID string, page string, limit string) ([]*entity.Order, *dto.Pagination, error) {
+	args := m.Called(mock.Anything, userAuthID, page, limit)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).([]*entity.Order), args.Get(1).(*dto.Pagination), args.Error(2)
+}
+
+func (m *MockOrderService) CancelUserOrder(ctx context.Context, userAuthID string, orderID string, req dto.CancelUserOrderRequest) error {
+	args := m.Called(mock.Anything, userAuthID, orderID, req)
+	return args.Error(0)
+}
+
+func (m *MockOrderService) GetAnyOrder(ctx context.Context, orderID string) (*entity.Order, error) {
+	args := m.Called(ctx, orderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Order), args.Error(1)
+}
+
+func (m *MockOrderService) HandlePaymentCallback(ctx context.Context, header http.Header, body []byte) error {
+	args := m.Called(mock.Anything, header, body)
+	return args.Error(0)
+}
+
+func (m *MockOrderService) RelayOutbox(ctx context.Context, batchSize int) (int, error) {
+	args := m.Called(mock.Anything, batchSize)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockOrderService) RecoverSagas(ctx context.Context, batchSize int) (int, error) {
+	args := m.Called(mock.Anything, batchSize)
+	return args.Int(0), args.Error(1)
+}
+
 var _ order.OrderServiceItf = (*MockOrderService)(nil)
 
 func setupTestGrpc(mockOrder *MockOrderService) (*Grpc, *service.Service) {
@@ -332,3 +407,51 @@ func TestCancelOrderError(t *testing.T) {
 	assert.Equal(t, expectedErr, err)
 	mockOrder.AssertExpectations(t)
 }
+
+func TestUpdateOrderStatusSuccess(t *testing.T) {
+	mockOrder := new(MockOrderService)
+	grpcHandler, _ := setupTestGrpc(mockOrder)
+	ctx := context.Background()
+
+	mockOrder.On("UpdateOrderStatus", ctx, mock.MatchedBy(func(req *dto.UpdateOrderStatusRequest) bool {
+		return req.OrderID == testOrderID && req.Status == "shipped" && req.Actor == "warehouse" && req.Note == "Picked up by courier"
+	})).Return(entity.StatusProcessing, nil)
+
+	req := &orderpb.UpdateOrderStatusRequest{
+		OrderId: testOrderID,
+		Status:  "shipped",
+		Actor:   "warehouse",
+		Note:    "Picked up by courier",
+	}
+
+	resp, err := grpcHandler.UpdateOrderStatus(ctx, req)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, resp)
+	assert.True(t, resp.Success)
+	assert.Equal(t, "processing", resp.PreviousStatus)
+	assert.Equal(t, "shipped", resp.Status)
+	mockOrder.AssertExpectations(t)
+}
+
+func TestUpdateOrderStatusError(t *testing.T) {
+	mockOrder := new(MockOrderService)
+	grpcHandler, _ := setupTestGrpc(mockOrder)
+	ctx := context.Background()
+
+	expectedErr := errors.New("Order cannot move from delivered to cancelled")
+	mockOrder.On("UpdateOrderStatus", ctx, mock.AnythingOfType("*dto.UpdateOrderStatusRequest")).Return(entity.OrderStatus(""), expectedErr)
+
+	req := &orderpb.UpdateOrderStatusRequest{
+		OrderId: testOrderID,
+		Status:  "cancelled",
+		Actor:   "admin",
+	}
+
+	resp, err := grpcHandler.UpdateOrderStatus(ctx, req)
+
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+	assert.Equal(t, expectedErr, err)
+	mockOrder.AssertExpectations(t)
+}
@@ -0,0 +1,93 @@
+package rest
+
+import (
+	"net/http"
+
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+	"github.com/linggaaskaedo/go-kill/order-service/src/internal/model/dto"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+func (e *rest) handleCreateOrder(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req dto.CreateUserOrderRequest
+	userAuthID := c.GetString("user_auth_id")
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("invalid_request_body")
+		e.httpRespError(c, x.WrapWithCode(err, x.CodeHTTPUnmarshal, "invalid_request_body"))
+		return
+	}
+
+	resp, err := e.svc.Order.CreateUserOrder(ctx, userAuthID, req)
+	if err != nil {
+		e.httpRespError(c, err)
+		return
+	}
+
+	e.httpRespSuccess(c, http.StatusCreated, resp, nil)
+}
+
+func (e *rest) handleGetOrder(c *gin.Context) {
+	ctx := c.Request.Context()
+	userAuthID := c.GetString("user_auth_id")
+
+	resp, err := e.svc.Order.GetUserOrder(ctx, userAuthID, c.Param("id"))
+	if err != nil {
+		e.httpRespError(c, err)
+		return
+	}
+
+	e.httpRespSuccess(c, http.StatusOK, resp, nil)
+}
+
+func (e *rest) handleGetAnyOrder(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	resp, err := e.svc.Order.GetAnyOrder(ctx, c.Param("id"))
+	if err != nil {
+		e.httpRespError(c, err)
+		return
+	}
+
+	e.httpRespSuccess(c, http.StatusOK, resp, nil)
+}
+
+func (e *rest) handleListOrders(c *gin.Context) {
+	ctx := c.Request.Context()
+	userAuthID := c.GetString("user_auth_id")
+	page := c.DefaultQuery("page", "1")
+	limit := c.DefaultQuery("limit", "20")
+
+	resp, pagination, err := e.svc.Order.ListUserOrders(ctx, userAuthID, page, limit)
+	if err != nil {
+		e.httpRespError(c, err)
+		return
+	}
+
+	e.httpRespSuccess(c, http.StatusOK, resp, pagination)
+}
+
+func (e *rest) handleCancelOrder(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req dto.CancelUserOrderRequest
+	userAuthID := c.GetString("user_auth_id")
+
+	// The reason is optional, so an empty body is accepted
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("invalid_request_body")
+			e.httpRespError(c, x.WrapWithCode(err, x.CodeHTTPUnmarshal, "invalid_request_body"))
+			return
+		}
+	}
+
+	if err := e.svc.Order.CancelUserOrder(ctx, userAuthID, c.Param("id"), req); err != nil {
+		e.httpRespError(c, err)
+		return
+	}
+
+	e.httpRespSuccess(c, http.StatusOK, nil, nil)
+}
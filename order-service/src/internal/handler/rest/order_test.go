@@ -0,0 +1,485 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/linggaaskaedo/go-kill/common/pkg/authz"
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+	"github.com/linggaaskaedo/go-kill/common/pkg/preference"
+	authpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/auth"
+	"github.com/linggaaskaedo/go-kill/order-service/src/internal/model/dto"
+	"github.com/linggaaskaedo/go-kill/order-service/src/internal/model/entity"
+	"github.com/linggaaskaedo/go-kill/order-service/src/internal/service"
+	"github.com/linggaaskaedo/go-kill/order-service/src/internal/service/order"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	testUserAuthID         = "auth-123"
+	testUserEmail          = "test@example.com"
+	testOrderID            = "order-123"
+	headerContentType      = "Content-Type"
+	headerContentTypeValue = "application/json"
+	headerAuthBearer       = "Bearer valid-token"
+)
+
+type MockOrderService struct {
+	mock.Mock
+}
+
+func (m *MockOrderService) CreateOrder(ctx context.Context, reqData *dto.CreateOrderRequest) (*string, *string, float64, error) {
+	args := m.Called(mock.Anything, reqData)
+	if args.Get(0) == nil {
+		return nil, nil, 0, args.Error(3)
+	}
+	return args.Get(0).(*string), args.Get(1).(*string), args.Get(2).(float64), args.Error(3)
+}
+
+func (m *MockOrderService) GetOrder(ctx context.Context, reqData *dto.GetOrderRequest) (*entity.Order, error) {
+	args := m.Called(mock.Anything, reqData)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Order), args.Error(1)
+}
+
+func (m *MockOrderService) ListOrders(ctx context.Context, reqData *dto.ListOrderRequest) ([]*entity.Order, int32, error) {
+	args := m.Called(mock.Anything, reqData)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*entity.Order), args.Get(1).(int32), args.Error(2)
+}
+
+func (m *MockOrderService) CancelOrder(ctx context.Context, reqData *dto.CancelOrderRequest) error {
+	args := m.Called(mock.Anything, reqData)
+	return args.Error(0)
+}
+
+func (m *MockOrderService) UpdateOrderStatus(ctx context.Context, reqData *dto.UpdateOrderStatusRequest) (entity.OrderStatus, error) {
+	args := m.Called(mock.Anything, reqData)
+	return args.Get(0).(entity.OrderStatus), args.Error(1)
+}
+
+func (m *MockOrderService) ValidateToken(ctx context.Context, req *authpb.ValidateTokenRequest) (*authpb.ValidateTokenResponse, error) {
+	args := m.Called(mock.Anything, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*authpb.ValidateTokenResponse), args.Error(1)
+}
+
+func (m *MockOrderService) ValidateApiKey(ctx context.Context, req *authpb.ValidateApiKeyRequest) (*authpb.ValidateTokenResponse, error) {
+	args := m.Called(mock.Anything, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*authpb.ValidateTokenResponse), args.Error(1)
+}
+
+func (m *MockOrderService) CreateUserOrder(ctx context.Context, userAuthID string, req dto.CreateUserOrderRequest) (*dto.CreateOrderResp, error) {
+	args := m.Called(mock.Anything, userAuthID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.CreateOrderResp), args.Error(1)
+}
+
+func (m *MockOrderService) GetUserOrder(ctx context.Context, userAuthID string, orderID string) (*entity.Order, error) {
+	args := m.Called(mock.Anything, userAuthID, orderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Order), args.Error(1)
+}
+
+func (m *MockOrderService) ListUserOrders(ctx context.Context, userAuthID string, page string, limit string) ([]*entity.Order, *dto.Pagination, error) {
+	args := m.Called(mock.Anything, userAuthID, page, limit)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).([]*entity.Order), args.Get(1).(*dto.Pagination), args.Error(2)
+}
+
+func (m *MockOrderService) CancelUserOrder(ctx context.Context, userAuthID string, orderID string, req dto.CancelUserOrderRequest) error {
+	args := m.Called(mock.Anything, userAuthID, orderID, req)
+	return args.Error(0)
+}
+
+func (m *MockOrderService) GetAnyOrder(ctx context.Context, orderID string) (*entity.Order, error) {
+	args := m.Called(ctx, orderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Order), args.Error(1)
+}
+
+func (m *MockOrderService) HandlePaymentCallback(ctx context.Context, header http.Header, body []byte) error {
+	args := m.Called(mock.Anything, header, body)
+	return args.Error(0)
+}
+
+func (m *MockOrderService) RelayOutbox(ctx context.Context, batchSize int) (int, error) {
+	args := m.Called(mock.Anything, batchSize)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockOrderService) RecoverSagas(ctx context.Context, batchSize int) (int, error) {
+	args := m.Called(mock.Anything, batchSize)
+	return args.Int(0), args.Error(1)
+}
+
+var _ order.OrderServiceItf = (*MockOrderService)(nil)
+
+func setupTestRest(mockOrder *MockOrderService) *rest {
+	mockSvc := &service.Service{}
+	mockSvc.Order = mockOrder
+
+	return &rest{
+		gin: nil,
+		svc: mockSvc,
+	}
+}
+
+func setupRouter(handler *rest) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+
+	r.POST(pathOrders, handler.authMiddleware(authz.PermOrderWrite), handler.handleCreateOrder)
+	r.GET(pathOrders, handler.authMiddleware(authz.PermOrderRead), handler.handleListOrders)
+	r.GET("/api/v1/orders/:id", handler.authMiddleware(authz.PermOrderRead), handler.handleGetOrder)
+	r.POST("/api/v1/orders/:id/cancel", handler.authMiddleware(authz.PermOrderWrite), handler.handleCancelOrder)
+	r.GET("/api/v1/admin/orders/:id", handler.authMiddleware(authz.PermOrderReadAny), handler.handleGetAnyOrder)
+	r.POST(pathPaymentCallback, handler.handlePaymentCallback)
+
+	return r
+}
+
+func mockValidToken(mockOrder *MockOrderService) {
+	mockOrder.On("ValidateToken", mock.Anything, &authpb.ValidateTokenRequest{Token: "valid-token"}).Return(&authpb.ValidateTokenResponse{
+		Valid:       true,
+		UserId:      testUserAuthID,
+		Email:       testUserEmail,
+		Permissions: []string{authz.PermOrderRead, authz.PermOrderWrite},
+	}, nil)
+}
+
+func TestHandleCreateOrderSuccess(t *testing.T) {
+	mockOrder := new(MockOrderService)
+	handler := setupTestRest(mockOrder)
+	router := setupRouter(handler)
+
+	reqBody := dto.CreateUserOrderRequest{
+		ShippingAddressID: "addr-123",
+		PaymentMethod:     "credit_card",
+		Items:             []dto.CreateUserOrderItem{{ProductID: "prod-123", Quantity: 2}},
+	}
+
+	mockValidToken(mockOrder)
+	mockOrder.On("CreateUserOrder", mock.Anything, testUserAuthID, reqBody).Return(&dto.CreateOrderResp{
+		OrderID:     testOrderID,
+		OrderNumber: "ORD-20260101-000001",
+		TotalAmount: 100,
+	}, nil)
+
+	body, _ := json.Marshal(reqBody)
+	req, _ := http.NewRequest(http.MethodPost, pathOrders, bytes.NewBuffer(body))
+	req.Header.Set(headerContentType, headerContentTypeValue)
+	req.Header.Set("Authorization", headerAuthBearer)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response dto.HttpSuccessResp
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, response.Meta.StatusCode)
+
+	mockOrder.AssertExpectations(t)
+}
+
+func TestHandleCreateOrderIgnoresBodyUserID(t *testing.T) {
+	mockOrder := new(MockOrderService)
+	handler := setupTestRest(mockOrder)
+	router := setupRouter(handler)
+
+	mockValidToken(mockOrder)
+	mockOrder.On("CreateUserOrder", mock.Anything, testUserAuthID, mock.AnythingOfType("dto.CreateUserOrderRequest")).Return(&dto.CreateOrderResp{OrderID: testOrderID}, nil)
+
+	body := []byte(`{"user_id":"someone-else","shipping_address_id":"addr-123","payment_method":"credit_card","items":[{"product_id":"prod-123","quantity":1}]}`)
+	req, _ := http.NewRequest(http.MethodPost, pathOrders, bytes.NewBuffer(body))
+	req.Header.Set(headerContentType, headerContentTypeValue)
+	req.Header.Set("Authorization", headerAuthBearer)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	mockOrder.AssertExpectations(t)
+}
+
+func TestHandleCreateOrderInvalidBody(t *testing.T) {
+	mockOrder := new(MockOrderService)
+	handler := setupTestRest(mockOrder)
+	router := setupRouter(handler)
+
+	mockValidToken(mockOrder)
+
+	req, _ := http.NewRequest(http.MethodPost, pathOrders, bytes.NewBuffer([]byte(`{"shipping_address_id":"addr-123","payment_method":"credit_card","items":[]}`)))
+	req.Header.Set(headerContentType, headerContentTypeValue)
+	req.Header.Set("Authorization", headerAuthBearer)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockOrder.AssertNotCalled(t, "CreateUserOrder", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestHandleCreateOrderInvalidToken(t *testing.T) {
+	mockOrder := new(MockOrderService)
+	handler := setupTestRest(mockOrder)
+	router := setupRouter(handler)
+
+	mockOrder.On("ValidateToken", mock.Anything, mock.Anything).Return(nil, errors.New("invalid token"))
+
+	req, _ := http.NewRequest(http.MethodPost, pathOrders, bytes.NewBuffer([]byte("{}")))
+	req.Header.Set(headerContentType, headerContentTypeValue)
+	req.Header.Set("Authorization", "Bearer invalid-token")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	mockOrder.AssertExpectations(t)
+}
+
+func TestHandleGetOrderSuccess(t *testing.T) {
+	mockOrder := new(MockOrderService)
+	handler := setupTestRest(mockOrder)
+	router := setupRouter(handler)
+
+	mockValidToken(mockOrder)
+	mockOrder.On("GetUserOrder", mock.Anything, testUserAuthID, testOrderID).Return(&entity.Order{ID: testOrderID, Status: entity.StatusPending}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, pathOrderByID, nil)
+	req.Header.Set("Authorization", headerAuthBearer)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockOrder.AssertExpectations(t)
+}
+
+func TestHandleGetOrderWithApiKey(t *testing.T) {
+	mockOrder := new(MockOrderService)
+	handler := setupTestRest(mockOrder)
+	router := setupRouter(handler)
+
+	mockOrder.On("ValidateApiKey", mock.Anything, &authpb.ValidateApiKeyRequest{ApiKey: "gk_valid-key"}).Return(&authpb.ValidateTokenResponse{Valid: true, UserId: testUserAuthID, ApiKeyId: "key-123", Permissions: []string{authz.PermOrderRead}}, nil)
+	mockOrder.On("GetUserOrder", mock.Anything, testUserAuthID, testOrderID).Return(&entity.Order{ID: testOrderID, Status: entity.StatusPending}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, pathOrderByID, nil)
+	req.Header.Set(preference.API_KEY, "gk_valid-key")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockOrder.AssertExpectations(t)
+}
+
+func TestApiKeyOutsideItsScopesIsForbidden(t *testing.T) {
+	mockOrder := new(MockOrderService)
+	handler := setupTestRest(mockOrder)
+	router := setupRouter(handler)
+
+	mockOrder.On("ValidateApiKey", mock.Anything, &authpb.ValidateApiKeyRequest{ApiKey: "gk_valid-key"}).Return(&authpb.ValidateTokenResponse{Valid: true, UserId: testUserAuthID, ApiKeyId: "key-123", Permissions: []string{authz.PermOrderRead}}, nil)
+
+	for _, tc := range []struct {
+		method string
+		path   string
+	}{
+		{http.MethodPost, pathOrders},
+		{http.MethodPost, "/api/v1/orders/" + testOrderID + "/cancel"},
+	} {
+		req, _ := http.NewRequest(tc.method, tc.path, bytes.NewBufferString("{}"))
+		req.Header.Set(headerContentType, headerContentTypeValue)
+		req.Header.Set(preference.API_KEY, "gk_valid-key")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code, tc.path)
+	}
+
+	mockOrder.AssertNotCalled(t, "CreateUserOrder", mock.Anything, mock.Anything, mock.Anything)
+	mockOrder.AssertNotCalled(t, "CancelUserOrder", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestHandleGetAnyOrder(t *testing.T) {
+	mockOrder := new(MockOrderService)
+	handler := setupTestRest(mockOrder)
+	router := setupRouter(handler)
+
+	mockOrder.On("ValidateToken", mock.Anything, &authpb.ValidateTokenRequest{Token: "admin-token"}).Return(&authpb.ValidateTokenResponse{Valid: true, UserId: "admin-auth-id", Roles: []string{"admin"}, Permissions: []string{authz.PermOrderReadAny}}, nil)
+	mockOrder.On("GetAnyOrder", mock.Anything, testOrderID).Return(&entity.Order{ID: testOrderID, UserID: "someone-else"}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/admin/orders/"+testOrderID, nil)
+	req.Header.Set("Authorization", "Bearer admin-token")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockOrder.AssertExpectations(t)
+}
+
+func TestHandleGetAnyOrderForbiddenForCustomers(t *testing.T) {
+	mockOrder := new(MockOrderService)
+	handler := setupTestRest(mockOrder)
+	router := setupRouter(handler)
+
+	mockValidToken(mockOrder)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/admin/orders/"+testOrderID, nil)
+	req.Header.Set("Authorization", headerAuthBearer)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockOrder.AssertNotCalled(t, "GetAnyOrder", mock.Anything, mock.Anything)
+}
+
+func TestHandleGetOrderNotFound(t *testing.T) {
+	mockOrder := new(MockOrderService)
+	handler := setupTestRest(mockOrder)
+	router := setupRouter(handler)
+
+	mockValidToken(mockOrder)
+	mockOrder.On("GetUserOrder", mock.Anything, testUserAuthID, testOrderID).Return(nil, x.NewWithCode(x.CodeSQLRecordDoesNotExist, "get_order_sql"))
+
+	req, _ := http.NewRequest(http.MethodGet, pathOrderByID, nil)
+	req.Header.Set("Authorization", headerAuthBearer)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockOrder.AssertExpectations(t)
+}
+
+func TestHandleListOrdersSuccess(t *testing.T) {
+	mockOrder := new(MockOrderService)
+	handler := setupTestRest(mockOrder)
+	router := setupRouter(handler)
+
+	orders := []*entity.Order{{ID: testOrderID}}
+	pagination := &dto.Pagination{CurrentPage: 2, CurrentElements: 1, TotalPages: 2, TotalElements: 6}
+
+	mockValidToken(mockOrder)
+	mockOrder.On("ListUserOrders", mock.Anything, testUserAuthID, "2", "5").Return(orders, pagination, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, pathOrders+"?page=2&limit=5", nil)
+	req.Header.Set("Authorization", headerAuthBearer)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response dto.HttpSuccessResp
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.NotNil(t, response.Pagination)
+	assert.Equal(t, int64(6), response.Pagination.TotalElements)
+
+	mockOrder.AssertExpectations(t)
+}
+
+func TestHandleListOrdersDefaultPagination(t *testing.T) {
+	mockOrder := new(MockOrderService)
+	handler := setupTestRest(mockOrder)
+	router := setupRouter(handler)
+
+	mockValidToken(mockOrder)
+	mockOrder.On("ListUserOrders", mock.Anything, testUserAuthID, "1", "20").Return([]*entity.Order{}, &dto.Pagination{}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, pathOrders, nil)
+	req.Header.Set("Authorization", headerAuthBearer)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockOrder.AssertExpectations(t)
+}
+
+func TestHandleCancelOrderSuccess(t *testing.T) {
+	mockOrder := new(MockOrderService)
+	handler := setupTestRest(mockOrder)
+	router := setupRouter(handler)
+
+	mockValidToken(mockOrder)
+	mockOrder.On("CancelUserOrder", mock.Anything, testUserAuthID, testOrderID, dto.CancelUserOrderRequest{Reason: "changed my mind"}).Return(nil)
+
+	body, _ := json.Marshal(dto.CancelUserOrderRequest{Reason: "changed my mind"})
+	req, _ := http.NewRequest(http.MethodPost, pathOrderCancel, bytes.NewBuffer(body))
+	req.Header.Set(headerContentType, headerContentTypeValue)
+	req.Header.Set("Authorization", headerAuthBearer)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockOrder.AssertExpectations(t)
+}
+
+func TestHandleCancelOrderWithoutBody(t *testing.T) {
+	mockOrder := new(MockOrderService)
+	handler := setupTestRest(mockOrder)
+	router := setupRouter(handler)
+
+	mockValidToken(mockOrder)
+	mockOrder.On("CancelUserOrder", mock.Anything, testUserAuthID, testOrderID, dto.CancelUserOrderRequest{}).Return(nil)
+
+	req, _ := http.NewRequest(http.MethodPost, pathOrderCancel, nil)
+	req.Header.Set("Authorization", headerAuthBearer)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockOrder.AssertExpectations(t)
+}
+
+func TestHandleCancelOrderError(t *testing.T) {
+	mockOrder := new(MockOrderService)
+	handler := setupTestRest(mockOrder)
+	router := setupRouter(handler)
+
+	mockValidToken(mockOrder)
+	mockOrder.On("CancelUserOrder", mock.Anything, testUserAuthID, testOrderID, dto.CancelUserOrderRequest{}).Return(errors.New("Order cannot be cancelled"))
+
+	req, _ := http.NewRequest(http.MethodPost, pathOrderCancel, nil)
+	req.Header.Set("Authorization", headerAuthBearer)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	mockOrder.AssertExpectations(t)
+}
@@ -0,0 +1,30 @@
+package rest
+
+import (
+	"io"
+	"net/http"
+
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+func (e *rest) handlePaymentCallback(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// The provider signs the raw body, so it is verified before decoding
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("invalid_request_body")
+		e.httpRespError(c, x.WrapWithCode(err, x.CodeHTTPErrorOnReadBody, "invalid_request_body"))
+		return
+	}
+
+	if err := e.svc.Order.HandlePaymentCallback(ctx, c.Request.Header, body); err != nil {
+		e.httpRespError(c, err)
+		return
+	}
+
+	e.httpRespSuccess(c, http.StatusOK, nil, nil)
+}
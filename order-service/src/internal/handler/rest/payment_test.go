@@ -0,0 +1,68 @@
+package rest
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testCallbackBody = `{"payment_id":"payment-123","transaction_id":"fake_payment-123","status":"authorized"}`
+
+func TestHandlePaymentCallbackSuccess(t *testing.T) {
+	mockOrder := new(MockOrderService)
+	handler := setupTestRest(mockOrder)
+	router := setupRouter(handler)
+
+	mockOrder.On("HandlePaymentCallback", mock.Anything, mock.MatchedBy(func(header http.Header) bool {
+		return header.Get("X-Fake-Signature") == "signature"
+	}), []byte(testCallbackBody)).Return(nil)
+
+	req, _ := http.NewRequest(http.MethodPost, pathPaymentCallback, bytes.NewBufferString(testCallbackBody))
+	req.Header.Set(headerContentType, headerContentTypeValue)
+	req.Header.Set("X-Fake-Signature", "signature")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockOrder.AssertExpectations(t)
+}
+
+func TestHandlePaymentCallbackNeedsNoToken(t *testing.T) {
+	mockOrder := new(MockOrderService)
+	handler := setupTestRest(mockOrder)
+	router := setupRouter(handler)
+
+	mockOrder.On("HandlePaymentCallback", mock.Anything, mock.Anything, []byte(testCallbackBody)).Return(nil)
+
+	req, _ := http.NewRequest(http.MethodPost, pathPaymentCallback, bytes.NewBufferString(testCallbackBody))
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockOrder.AssertNotCalled(t, "ValidateToken", mock.Anything, mock.Anything)
+	mockOrder.AssertExpectations(t)
+}
+
+func TestHandlePaymentCallbackInvalidSignature(t *testing.T) {
+	mockOrder := new(MockOrderService)
+	handler := setupTestRest(mockOrder)
+	router := setupRouter(handler)
+
+	mockOrder.On("HandlePaymentCallback", mock.Anything, mock.Anything, []byte(testCallbackBody)).Return(x.NewWithCode(x.CodeHTTPUnauthorized, "Invalid payment callback signature"))
+
+	req, _ := http.NewRequest(http.MethodPost, pathPaymentCallback, bytes.NewBufferString(testCallbackBody))
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	mockOrder.AssertExpectations(t)
+}
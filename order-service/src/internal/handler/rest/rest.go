@@ -0,0 +1,110 @@
+package rest
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/linggaaskaedo/go-kill/common/pkg/authz"
+	"github.com/linggaaskaedo/go-kill/common/pkg/idempotency"
+	"github.com/linggaaskaedo/go-kill/common/pkg/middleware"
+	"github.com/linggaaskaedo/go-kill/common/pkg/preference"
+	authpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/auth"
+	"github.com/linggaaskaedo/go-kill/order-service/src/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+var onceRestHandler = &sync.Once{}
+
+type rest struct {
+	gin         *gin.Engine
+	svc         *service.Service
+	idempotency *idempotency.Idempotency
+}
+
+func InitRestHandler(gin *gin.Engine, svc *service.Service, idem *idempotency.Idempotency) {
+	var e *rest
+
+	onceRestHandler.Do(func() {
+		e = &rest{
+			gin:         gin,
+			svc:         svc,
+			idempotency: idem,
+		}
+
+		e.Serve()
+	})
+}
+
+// authMiddleware accepts either an API key in X-API-Key or a bearer token,
+// keeps the identity auth-service verified on the context and requires
+// permissions of it. Every route names one, so an API key only reaches the
+// routes its scopes cover.
+func (e *rest) authMiddleware(permissions ...string) gin.HandlerFunc {
+	authorize := middleware.Authorize(e.httpRespError, permissions...)
+
+	return func(c *gin.Context) {
+		if apiKey := c.GetHeader(preference.API_KEY); apiKey != "" {
+			resp, err := e.svc.Order.ValidateApiKey(c.Request.Context(), &authpb.ValidateApiKeyRequest{ApiKey: apiKey})
+			if err != nil || !resp.Valid {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
+				c.Abort()
+				return
+			}
+
+			setIdentity(c, resp)
+			authorize(c)
+			return
+		}
+
+		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "No authorization header"})
+			c.Abort()
+			return
+		}
+
+		const bearerPrefix = "Bearer "
+		if len(authHeader) < len(bearerPrefix) || authHeader[:len(bearerPrefix)] != bearerPrefix {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization header format"})
+			c.Abort()
+			return
+		}
+
+		token := authHeader[len(bearerPrefix):]
+
+		resp, err := e.svc.Order.ValidateToken(c.Request.Context(), &authpb.ValidateTokenRequest{Token: token})
+		if err != nil || !resp.Valid {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+			c.Abort()
+			return
+		}
+
+		setIdentity(c, resp)
+		authorize(c)
+	}
+}
+
+func setIdentity(c *gin.Context, resp *authpb.ValidateTokenResponse) {
+	c.Set("user_auth_id", resp.UserId)
+	c.Set("email", resp.Email)
+	c.Request = c.Request.WithContext(authz.WithPrincipal(c.Request.Context(), &authz.Principal{
+		UserID:      resp.UserId,
+		Roles:       resp.Roles,
+		Permissions: resp.Permissions,
+	}))
+}
+
+func (e *rest) Serve() {
+	e.gin.POST("/api/v1/orders", e.authMiddleware(authz.PermOrderWrite), e.idempotency.Middleware("user_auth_id", e.httpRespError), e.handleCreateOrder)
+	e.gin.GET("/api/v1/orders", e.authMiddleware(authz.PermOrderRead), e.handleListOrders)
+	e.gin.GET("/api/v1/orders/:id", e.authMiddleware(authz.PermOrderRead), e.handleGetOrder)
+	e.gin.POST("/api/v1/orders/:id/cancel", e.authMiddleware(authz.PermOrderWrite), e.handleCancelOrder)
+
+	// Support and back office
+	e.gin.GET("/api/v1/admin/orders/:id", e.authMiddleware(authz.PermOrderReadAny), e.handleGetAnyOrder)
+
+	// Called by the payment provider, which signs the body instead of
+	// sending a user token
+	e.gin.POST("/api/v1/payments/callback", e.handlePaymentCallback)
+}
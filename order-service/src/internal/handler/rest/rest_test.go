@@ -0,0 +1,108 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/linggaaskaedo/go-kill/order-service/src/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	pathOrders          = "/api/v1/orders"
+	pathOrderByID       = "/api/v1/orders/order-123"
+	pathOrderCancel     = "/api/v1/orders/order-123/cancel"
+	pathPaymentCallback = "/api/v1/payments/callback"
+)
+
+func setupTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	return gin.New()
+}
+
+func TestInitRestHandler(t *testing.T) {
+	router := setupTestRouter()
+
+	svc := &service.Service{}
+
+	InitRestHandler(router, svc, nil)
+}
+
+func TestServeRoutesRegistered(t *testing.T) {
+	router := setupTestRouter()
+
+	svc := &service.Service{}
+
+	handler := &rest{
+		gin: router,
+		svc: svc,
+	}
+
+	handler.Serve()
+
+	tests := []struct {
+		method string
+		path   string
+		status int
+	}{
+		{http.MethodPost, pathOrders, http.StatusUnauthorized},
+		{http.MethodGet, pathOrders, http.StatusUnauthorized},
+		{http.MethodGet, pathOrderByID, http.StatusUnauthorized},
+		{http.MethodPost, pathOrderCancel, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		req, _ := http.NewRequest(tt.method, tt.path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != tt.status {
+			t.Errorf("route %s %s: expected %d, got %d", tt.method, tt.path, tt.status, w.Code)
+		}
+	}
+}
+
+func TestAuthMiddlewareNoHeaderRest(t *testing.T) {
+	router := setupTestRouter()
+
+	handler := &rest{
+		gin: router,
+		svc: &service.Service{},
+	}
+
+	router.GET(pathOrders, handler.authMiddleware(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, pathOrders, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected status %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+}
+
+func TestAuthMiddlewareInvalidFormatRest(t *testing.T) {
+	router := setupTestRouter()
+
+	handler := &rest{
+		gin: router,
+		svc: &service.Service{},
+	}
+
+	router.GET(pathOrders, handler.authMiddleware(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, pathOrders, nil)
+	req.Header.Set("Authorization", "InvalidToken")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected status %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+}
@@ -0,0 +1,51 @@
+package scheduler
+
+import (
+	"context"
+
+	"github.com/linggaaskaedo/go-kill/common/component/scheduler"
+	"github.com/linggaaskaedo/go-kill/order-service/src/internal/service/order"
+
+	"github.com/rs/zerolog"
+)
+
+type OutboxRelayJob struct {
+	log          zerolog.Logger
+	orderService order.OrderServiceItf
+	cfg          scheduler.Config
+}
+
+func NewOutboxRelayJob(log zerolog.Logger, orderService order.OrderServiceItf, cfg scheduler.Config) *OutboxRelayJob {
+	return &OutboxRelayJob{
+		log:          log,
+		orderService: orderService,
+		cfg:          cfg,
+	}
+}
+
+func (j *OutboxRelayJob) Name() string {
+	return "outbox_relay_job"
+}
+
+func (j *OutboxRelayJob) Schedule() string {
+	return j.cfg.Cron
+}
+
+func (j *OutboxRelayJob) Run(ctx context.Context) error {
+	if !j.cfg.Enabled {
+		zerolog.Ctx(ctx).Debug().Msg(j.cfg.Name + " is disabled")
+		return nil
+	}
+
+	sent, err := j.orderService.RelayOutbox(ctx, j.cfg.BatchSize)
+	if err != nil {
+		zerolog.Ctx(ctx).Err(err).Msg("Outbox relay failed")
+		return err
+	}
+
+	if sent > 0 {
+		zerolog.Ctx(ctx).Info().Int("sent", sent).Int("batch_size", j.cfg.BatchSize).Msg("Outbox messages relayed")
+	}
+
+	return nil
+}
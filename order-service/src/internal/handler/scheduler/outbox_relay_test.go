@@ -0,0 +1,182 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/linggaaskaedo/go-kill/common/component/scheduler"
+	authpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/auth"
+	"github.com/linggaaskaedo/go-kill/order-service/src/internal/model/dto"
+	"github.com/linggaaskaedo/go-kill/order-service/src/internal/model/entity"
+	"github.com/linggaaskaedo/go-kill/order-service/src/internal/service/order"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	testBatchSize = 100
+	testCron      = "*/2 * * * * *"
+)
+
+type MockOrderService struct {
+	mock.Mock
+}
+
+func (m *MockOrderService) CreateOrder(ctx context.Context, reqData *dto.CreateOrderRequest) (*string, *string, float64, error) {
+	args := m.Called(mock.Anything, reqData)
+	if args.Get(0) == nil {
+		return nil, nil, 0, args.Error(3)
+	}
+	orderID := args.Get(0).(*string)
+	orderNumber := args.Get(1).(*string)
+	totalAmount := args.Get(2).(float64)
+	return orderID, orderNumber, totalAmount, args.Error(3)
+}
+
+func (m *MockOrderService) GetOrder(ctx context.Context, reqData *dto.GetOrderRequest) (*entity.Order, error) {
+	args := m.Called(mock.Anything, reqData)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Order), args.Error(1)
+}
+
+func (m *MockOrderService) ListOrders(ctx context.Context, reqData *dto.ListOrderRequest) ([]*entity.Order, int32, error) {
+	args := m.Called(mock.Anything, reqData)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*entity.Order), args.Get(1).(int32), args.Error(2)
+}
+
+func (m *MockOrderService) CancelOrder(ctx context.Context, reqData *dto.CancelOrderRequest) error {
+	args := m.Called(mock.Anything, reqData)
+	return args.Error(0)
+}
+
+func (m *MockOrderService) UpdateOrderStatus(ctx context.Context, reqData *dto.UpdateOrderStatusRequest) (entity.OrderStatus, error) {
+	args := m.Called(mock.Anything, reqData)
+	return args.Get(0).(entity.OrderStatus), args.Error(1)
+}
+
+func (m *MockOrderService) ValidateToken(ctx context.Context, req *authpb.ValidateTokenRequest) (*authpb.ValidateTokenResponse, error) {
+	args := m.Called(mock.Anything, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*authpb.ValidateTokenResponse), args.Error(1)
+}
+
+func (m *MockOrderService) ValidateApiKey(ctx context.Context, req *authpb.ValidateApiKeyRequest) (*authpb.ValidateTokenResponse, error) {
+	args := m.Called(mock.Anything, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*authpb.ValidateTokenResponse), args.Error(1)
+}
+
+func (m *MockOrderService) CreateUserOrder(ctx context.Context, userAuthID string, req dto.CreateUserOrderRequest) (*dto.CreateOrderResp, error) {
+	args := m.Called(mock.Anything, userAuthID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.CreateOrderResp), args.Error(1)
+}
+
+func (m *MockOrderService) GetUserOrder(ctx context.Context, userAuthID string, orderID string) (*entity.Order, error) {
+	args := m.Called(mock.Anything, userAuthID, orderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Order), args.Error(1)
+}
+
+func (m *MockOrderService) ListUserOrders(ctx context.Context, userAuthID string, page string, limit string) ([]*entity.Order, *dto.Pagination, error) {
+	args := m.Called(mock.Anything, userAuthID, page, limit)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).([]*entity.Order), args.Get(1).(*dto.Pagination), args.Error(2)
+}
+
+func (m *MockOrderService) CancelUserOrder(ctx context.Context, userAuthID string, orderID string, req dto.CancelUserOrderRequest) error {
+	args := m.Called(mock.Anything, userAuthID, orderID, req)
+	return args.Error(0)
+}
+
+func (m *MockOrderService) GetAnyOrder(ctx context.Context, orderID string) (*entity.Order, error) {
+	args := m.Called(ctx, orderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Order), args.Error(1)
+}
+
+func (m *MockOrderService) HandlePaymentCallback(ctx context.Context, header http.Header, body []byte) error {
+	args := m.Called(mock.Anything, header, body)
+	return args.Error(0)
+}
+
+func (m *MockOrderService) RelayOutbox(ctx context.Context, batchSize int) (int, error) {
+	args := m.Called(mock.Anything, batchSize)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockOrderService) RecoverSagas(ctx context.Context, batchSize int) (int, error) {
+	args := m.Called(mock.Anything, batchSize)
+	return args.Int(0), args.Error(1)
+}
+
+var _ order.OrderServiceItf = (*MockOrderService)(nil)
+
+func newTestJob(svc order.OrderServiceItf, enabled bool) *OutboxRelayJob {
+	return NewOutboxRelayJob(zerolog.Nop(), svc, scheduler.Config{
+		Enabled:   enabled,
+		Name:      "outbox_relay",
+		Cron:      testCron,
+		BatchSize: testBatchSize,
+	})
+}
+
+func TestOutboxRelayJobNameAndSchedule(t *testing.T) {
+	job := newTestJob(new(MockOrderService), true)
+
+	assert.Equal(t, "outbox_relay_job", job.Name())
+	assert.Equal(t, testCron, job.Schedule())
+}
+
+func TestOutboxRelayJobDisabled(t *testing.T) {
+	mockSvc := new(MockOrderService)
+	job := newTestJob(mockSvc, false)
+
+	err := job.Run(context.Background())
+
+	assert.NoError(t, err)
+	mockSvc.AssertNotCalled(t, "RelayOutbox", mock.Anything, mock.Anything)
+}
+
+func TestOutboxRelayJobRunSuccess(t *testing.T) {
+	mockSvc := new(MockOrderService)
+	mockSvc.On("RelayOutbox", mock.Anything, testBatchSize).Return(3, nil)
+	job := newTestJob(mockSvc, true)
+
+	err := job.Run(context.Background())
+
+	assert.NoError(t, err)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestOutboxRelayJobRunError(t *testing.T) {
+	mockSvc := new(MockOrderService)
+	mockSvc.On("RelayOutbox", mock.Anything, testBatchSize).Return(0, errors.New("database unavailable"))
+	job := newTestJob(mockSvc, true)
+
+	err := job.Run(context.Background())
+
+	assert.Error(t, err)
+	mockSvc.AssertExpectations(t)
+}
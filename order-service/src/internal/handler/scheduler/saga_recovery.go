@@ -0,0 +1,51 @@
+package scheduler
+
+import (
+	"context"
+
+	"github.com/linggaaskaedo/go-kill/common/component/scheduler"
+	"github.com/linggaaskaedo/go-kill/order-service/src/internal/service/order"
+
+	"github.com/rs/zerolog"
+)
+
+type SagaRecoveryJob struct {
+	log          zerolog.Logger
+	orderService order.OrderServiceItf
+	cfg          scheduler.Config
+}
+
+func NewSagaRecoveryJob(log zerolog.Logger, orderService order.OrderServiceItf, cfg scheduler.Config) *SagaRecoveryJob {
+	return &SagaRecoveryJob{
+		log:          log,
+		orderService: orderService,
+		cfg:          cfg,
+	}
+}
+
+func (j *SagaRecoveryJob) Name() string {
+	return "saga_recovery_job"
+}
+
+func (j *SagaRecoveryJob) Schedule() string {
+	return j.cfg.Cron
+}
+
+func (j *SagaRecoveryJob) Run(ctx context.Context) error {
+	if !j.cfg.Enabled {
+		zerolog.Ctx(ctx).Debug().Msg(j.cfg.Name + " is disabled")
+		return nil
+	}
+
+	recovered, err := j.orderService.RecoverSagas(ctx, j.cfg.BatchSize)
+	if err != nil {
+		zerolog.Ctx(ctx).Err(err).Msg("Saga recovery failed")
+		return err
+	}
+
+	if recovered > 0 {
+		zerolog.Ctx(ctx).Info().Int("recovered", recovered).Int("batch_size", j.cfg.BatchSize).Msg("Stale sagas recovered")
+	}
+
+	return nil
+}
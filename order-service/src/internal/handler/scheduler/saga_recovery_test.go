@@ -0,0 +1,64 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/linggaaskaedo/go-kill/common/component/scheduler"
+	"github.com/linggaaskaedo/go-kill/order-service/src/internal/service/order"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testSagaCron = "0 * * * * *"
+
+func newTestSagaRecoveryJob(svc order.OrderServiceItf, enabled bool) *SagaRecoveryJob {
+	return NewSagaRecoveryJob(zerolog.Nop(), svc, scheduler.Config{
+		Enabled:   enabled,
+		Name:      "saga_recovery",
+		Cron:      testSagaCron,
+		BatchSize: testBatchSize,
+	})
+}
+
+func TestSagaRecoveryJobNameAndSchedule(t *testing.T) {
+	job := newTestSagaRecoveryJob(new(MockOrderService), true)
+
+	assert.Equal(t, "saga_recovery_job", job.Name())
+	assert.Equal(t, testSagaCron, job.Schedule())
+}
+
+func TestSagaRecoveryJobDisabled(t *testing.T) {
+	mockSvc := new(MockOrderService)
+	job := newTestSagaRecoveryJob(mockSvc, false)
+
+	err := job.Run(context.Background())
+
+	assert.NoError(t, err)
+	mockSvc.AssertNotCalled(t, "RecoverSagas", mock.Anything, mock.Anything)
+}
+
+func TestSagaRecoveryJobRunSuccess(t *testing.T) {
+	mockSvc := new(MockOrderService)
+	mockSvc.On("RecoverSagas", mock.Anything, testBatchSize).Return(2, nil)
+	job := newTestSagaRecoveryJob(mockSvc, true)
+
+	err := job.Run(context.Background())
+
+	assert.NoError(t, err)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestSagaRecoveryJobRunError(t *testing.T) {
+	mockSvc := new(MockOrderService)
+	mockSvc.On("RecoverSagas", mock.Anything, testBatchSize).Return(0, errors.New("database unavailable"))
+	job := newTestSagaRecoveryJob(mockSvc, true)
+
+	err := job.Run(context.Background())
+
+	assert.Error(t, err)
+	mockSvc.AssertExpectations(t)
+}
@@ -19,6 +19,11 @@ type OrderData struct {
 	TotalAmount float64     `json:"total_amount"`
 	Status      string      `json:"status"`
 	Items       []OrderItem `json:"items"`
+
+	// Set on order.updated only
+	PreviousStatus string `json:"previous_status,omitempty"`
+	UpdatedBy      string `json:"updated_by,omitempty"`
+	Note           string `json:"note,omitempty"`
 }
 
 type OrderItem struct {
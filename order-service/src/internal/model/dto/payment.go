@@ -0,0 +1,24 @@
+package dto
+
+import "time"
+
+type PaymentEvent struct {
+	EventID   string      `json:"event_id"`
+	EventType string      `json:"event_type"`
+	Version   string      `json:"version"`
+	Timestamp time.Time   `json:"timestamp"`
+	Source    string      `json:"source"`
+	Data      PaymentData `json:"data"`
+}
+
+type PaymentData struct {
+	PaymentID     string  `json:"payment_id"`
+	OrderID       string  `json:"order_id"`
+	OrderNumber   string  `json:"order_number"`
+	UserID        string  `json:"user_id"`
+	Amount        float64 `json:"amount"`
+	PaymentMethod string  `json:"payment_method"`
+	Status        string  `json:"status"`
+	TransactionID string  `json:"transaction_id,omitempty"`
+	Reason        string  `json:"reason,omitempty"`
+}
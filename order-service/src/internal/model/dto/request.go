@@ -24,3 +24,26 @@ type CancelOrderRequest struct {
 	UserID  string `json:"user_id"`
 	Reason  string `json:"reason"`
 }
+
+type UpdateOrderStatusRequest struct {
+	OrderID string `json:"order_id"`
+	Status  string `json:"status"`
+	Actor   string `json:"actor"`
+	Note    string `json:"note"`
+}
+
+type CreateUserOrderItem struct {
+	ProductID string `json:"product_id" binding:"required"`
+	Quantity  int32  `json:"quantity" binding:"required,min=1"`
+}
+
+type CreateUserOrderRequest struct {
+	ShippingAddressID string                `json:"shipping_address_id" binding:"required"`
+	BillingAddressID  string                `json:"billing_address_id"`
+	PaymentMethod     string                `json:"payment_method" binding:"required"`
+	Items             []CreateUserOrderItem `json:"items" binding:"required,min=1,dive"`
+}
+
+type CancelUserOrderRequest struct {
+	Reason string `json:"reason"`
+}
@@ -13,6 +13,10 @@ const (
 	StatusCancelled  OrderStatus = "cancelled"
 )
 
+// ActorSystem marks status changes made by order-service itself rather than
+// a user or an operator.
+const ActorSystem = "system"
+
 type Order struct {
 	ID                string       `db:"id" json:"id"`
 	UserID            string       `db:"user_id" json:"user_id"`
@@ -25,3 +29,14 @@ type Order struct {
 	UpdatedAt         time.Time    `db:"updated_at" json:"updated_at"`
 	Items             []*OrderItem `db:"-" json:"items,omitempty"`
 }
+
+type OrderStatusChange struct {
+	OrderID string
+	From    OrderStatus
+	To      OrderStatus
+	Actor   string
+	Note    string
+
+	// Payment, when set, is applied in the same transaction as the order
+	Payment *PaymentStatusChange
+}
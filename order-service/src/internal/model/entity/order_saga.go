@@ -0,0 +1,37 @@
+package entity
+
+import "time"
+
+type SagaStatus string
+
+// Forward steps run in declaration order up to SagaCompleted. Before
+// SagaPaymentCreated a failure is compensated; after it the saga only moves
+// forward (the stock commit is retried until it succeeds).
+const (
+	SagaStarted           SagaStatus = "started"
+	SagaInventoryReserved SagaStatus = "inventory_reserved"
+	SagaOrderCreated      SagaStatus = "order_created"
+	SagaPaymentCreated    SagaStatus = "payment_created"
+	SagaCompleted         SagaStatus = "completed"
+	SagaCompensating      SagaStatus = "compensating"
+	SagaCompensated       SagaStatus = "compensated"
+	SagaFailed            SagaStatus = "failed"
+)
+
+type SagaItem struct {
+	ProductID string `json:"product_id"`
+	Quantity  int32  `json:"quantity"`
+}
+
+type OrderSaga struct {
+	ID            string     `db:"id" json:"id"`
+	OrderID       *string    `db:"order_id" json:"order_id,omitempty"`
+	ReservationID *string    `db:"reservation_id" json:"reservation_id,omitempty"`
+	UserID        string     `db:"user_id" json:"user_id"`
+	Status        SagaStatus `db:"status" json:"status"`
+	Items         []SagaItem `db:"-" json:"items"`
+	Attempts      int        `db:"attempts" json:"attempts"`
+	LastError     *string    `db:"last_error" json:"last_error,omitempty"`
+	CreatedAt     time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt     time.Time  `db:"updated_at" json:"updated_at"`
+}
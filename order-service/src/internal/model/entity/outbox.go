@@ -0,0 +1,26 @@
+package entity
+
+import "time"
+
+type OutboxStatus string
+
+const (
+	OutboxPending OutboxStatus = "pending"
+	OutboxSent    OutboxStatus = "sent"
+	OutboxFailed  OutboxStatus = "failed"
+)
+
+type OutboxMessage struct {
+	ID            string       `db:"id" json:"id"`
+	AggregateID   string       `db:"aggregate_id" json:"aggregate_id"`
+	EventType     string       `db:"event_type" json:"event_type"`
+	Topic         string       `db:"topic" json:"topic"`
+	MessageKey    string       `db:"message_key" json:"message_key"`
+	Payload       []byte       `db:"payload" json:"payload"`
+	Status        OutboxStatus `db:"status" json:"status"`
+	Attempts      int          `db:"attempts" json:"attempts"`
+	LastError     *string      `db:"last_error" json:"last_error,omitempty"`
+	NextAttemptAt time.Time    `db:"next_attempt_at" json:"next_attempt_at"`
+	CreatedAt     time.Time    `db:"created_at" json:"created_at"`
+	SentAt        *time.Time   `db:"sent_at" json:"sent_at,omitempty"`
+}
@@ -2,13 +2,33 @@ package entity
 
 import "time"
 
+// PaymentStatus follows the provider flow: a pending payment is authorized
+// when the order is confirmed, completed when it is captured on shipment, and
+// refunded if the order is cancelled after authorization.
+type PaymentStatus string
+
+const (
+	PaymentPending    PaymentStatus = "pending"
+	PaymentAuthorized PaymentStatus = "authorized"
+	PaymentCompleted  PaymentStatus = "completed"
+	PaymentFailed     PaymentStatus = "failed"
+	PaymentRefunded   PaymentStatus = "refunded"
+)
+
 type Payment struct {
-	ID            string    `db:"id" json:"id"`
-	OrderID       string    `db:"order_id" json:"order_id"`
-	PaymentMethod string    `db:"payment_method" json:"payment_method"`
-	Amount        float64   `db:"amount" json:"amount"`
-	Status        string    `db:"status" json:"status"`
-	TransactionID *string   `db:"transaction_id" json:"transaction_id,omitempty"`
-	CreatedAt     time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt     time.Time `db:"updated_at" json:"updated_at"`
+	ID            string        `db:"id" json:"id"`
+	OrderID       string        `db:"order_id" json:"order_id"`
+	PaymentMethod string        `db:"payment_method" json:"payment_method"`
+	Amount        float64       `db:"amount" json:"amount"`
+	Status        PaymentStatus `db:"status" json:"status"`
+	TransactionID *string       `db:"transaction_id" json:"transaction_id,omitempty"`
+	CreatedAt     time.Time     `db:"created_at" json:"created_at"`
+	UpdatedAt     time.Time     `db:"updated_at" json:"updated_at"`
+}
+
+type PaymentStatusChange struct {
+	PaymentID     string
+	From          PaymentStatus
+	To            PaymentStatus
+	TransactionID *string
 }
@@ -0,0 +1,142 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+
+	"github.com/rs/zerolog"
+)
+
+const FakeSignatureHeader = "X-Fake-Signature"
+
+type FakeOptions struct {
+	// DeclineAbove declines authorizations for larger amounts; zero
+	// authorizes everything.
+	DeclineAbove float64 `yaml:"decline_above"`
+
+	// Async answers authorizations with StatusPending and posts the outcome
+	// to CallbackURL after CallbackDelay, like a real gateway's webhook.
+	Async          bool          `yaml:"async"`
+	CallbackURL    string        `yaml:"callback_url"`
+	CallbackDelay  time.Duration `yaml:"callback_delay"`
+	CallbackSecret string        `yaml:"callback_secret"`
+}
+
+// FakeProvider is an in-process gateway for local and test runs. It keeps no
+// state: transaction ids are derived from the payment id, and capture and
+// refund always succeed.
+type FakeProvider struct {
+	opts   FakeOptions
+	client *http.Client
+}
+
+type fakeCallback struct {
+	PaymentID     string `json:"payment_id"`
+	TransactionID string `json:"transaction_id"`
+	Status        Status `json:"status"`
+	Reason        string `json:"reason,omitempty"`
+}
+
+func NewFakeProvider(opts FakeOptions) *FakeProvider {
+	return &FakeProvider{
+		opts:   opts,
+		client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+func (f *FakeProvider) Name() string {
+	return ProviderFake
+}
+
+func (f *FakeProvider) Authorize(ctx context.Context, req AuthorizeRequest) (*Result, error) {
+	result := &Result{TransactionID: "fake_" + req.PaymentID, Status: StatusAuthorized}
+	if f.opts.DeclineAbove > 0 && req.Amount > f.opts.DeclineAbove {
+		result.Status = StatusDeclined
+		result.Reason = "Amount exceeds the fake provider limit"
+	}
+
+	if !f.opts.Async {
+		return result, nil
+	}
+
+	go f.sendCallback(zerolog.Ctx(ctx), fakeCallback{
+		PaymentID:     req.PaymentID,
+		TransactionID: result.TransactionID,
+		Status:        result.Status,
+		Reason:        result.Reason,
+	})
+
+	return &Result{TransactionID: result.TransactionID, Status: StatusPending}, nil
+}
+
+func (f *FakeProvider) Capture(ctx context.Context, transactionID string, amount float64) (*Result, error) {
+	return &Result{TransactionID: transactionID, Status: StatusCaptured}, nil
+}
+
+func (f *FakeProvider) Refund(ctx context.Context, transactionID string, amount float64) (*Result, error) {
+	return &Result{TransactionID: transactionID, Status: StatusRefunded}, nil
+}
+
+func (f *FakeProvider) ParseCallback(header http.Header, body []byte) (*Callback, error) {
+	signature, err := hex.DecodeString(header.Get(FakeSignatureHeader))
+	if err != nil || !hmac.Equal(signature, f.sign(body)) {
+		return nil, x.NewWithCode(x.CodeHTTPUnauthorized, "Invalid payment callback signature")
+	}
+
+	var cb fakeCallback
+	if err := json.Unmarshal(body, &cb); err != nil {
+		return nil, x.WrapWithCode(err, x.CodeHTTPUnmarshal, "Invalid payment callback body")
+	}
+
+	return &Callback{
+		PaymentID:     cb.PaymentID,
+		TransactionID: cb.TransactionID,
+		Status:        cb.Status,
+		Reason:        cb.Reason,
+	}, nil
+}
+
+func (f *FakeProvider) sign(body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(f.opts.CallbackSecret))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// sendCallback runs detached from the authorizing request, so it logs
+// failures instead of returning them. Like a real gateway it does not retry.
+func (f *FakeProvider) sendCallback(log *zerolog.Logger, cb fakeCallback) {
+	time.Sleep(f.opts.CallbackDelay)
+
+	body, err := json.Marshal(cb)
+	if err != nil {
+		log.Error().Err(err).Str("paymentID", cb.PaymentID).Msg("fake_payment_callback_failed")
+		return
+	}
+
+	req, err := http.NewRequest(http.MethodPost, f.opts.CallbackURL, bytes.NewReader(body))
+	if err != nil {
+		log.Error().Err(err).Str("paymentID", cb.PaymentID).Msg("fake_payment_callback_failed")
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(FakeSignatureHeader, hex.EncodeToString(f.sign(body)))
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		log.Error().Err(err).Str("paymentID", cb.PaymentID).Msg("fake_payment_callback_failed")
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		log.Error().Int("statusCode", resp.StatusCode).Str("paymentID", cb.PaymentID).Msg("fake_payment_callback_failed")
+	}
+}
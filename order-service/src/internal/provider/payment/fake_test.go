@@ -0,0 +1,129 @@
+package payment
+
+import (
+	"context"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "callback-secret"
+
+func TestFakeProviderDeclinesAboveLimit(t *testing.T) {
+	tests := []struct {
+		name         string
+		declineAbove float64
+		amount       float64
+		want         Status
+	}{
+		{name: "below the limit", declineAbove: 100, amount: 99.99, want: StatusAuthorized},
+		{name: "at the limit", declineAbove: 100, amount: 100, want: StatusAuthorized},
+		{name: "above the limit", declineAbove: 100, amount: 100.01, want: StatusDeclined},
+		{name: "no limit", declineAbove: 0, amount: 1_000_000, want: StatusAuthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewFakeProvider(FakeOptions{DeclineAbove: tt.declineAbove})
+
+			result, err := f.Authorize(context.Background(), AuthorizeRequest{PaymentID: "payment-1", Amount: tt.amount})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, result.Status)
+			assert.Equal(t, "fake_payment-1", result.TransactionID)
+			if tt.want == StatusDeclined {
+				assert.NotEmpty(t, result.Reason)
+			}
+		})
+	}
+}
+
+func TestFakeProviderAsyncPostsSignedCallback(t *testing.T) {
+	type received struct {
+		header http.Header
+		body   []byte
+	}
+	callbacks := make(chan received, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		callbacks <- received{header: r.Header.Clone(), body: body}
+	}))
+	defer server.Close()
+
+	f := NewFakeProvider(FakeOptions{
+		DeclineAbove:   100,
+		Async:          true,
+		CallbackURL:    server.URL,
+		CallbackSecret: testSecret,
+	})
+
+	// The outcome is only reported through the callback
+	result, err := f.Authorize(context.Background(), AuthorizeRequest{PaymentID: "payment-1", Amount: 150})
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, result.Status)
+	assert.Equal(t, "fake_payment-1", result.TransactionID)
+
+	var cb received
+	select {
+	case cb = <-callbacks:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no callback posted")
+	}
+
+	assert.Equal(t, "application/json", cb.header.Get("Content-Type"))
+	assert.Equal(t, hex.EncodeToString(f.sign(cb.body)), cb.header.Get(FakeSignatureHeader))
+
+	parsed, err := f.ParseCallback(cb.header, cb.body)
+	require.NoError(t, err)
+	assert.Equal(t, &Callback{
+		PaymentID:     "payment-1",
+		TransactionID: "fake_payment-1",
+		Status:        StatusDeclined,
+		Reason:        "Amount exceeds the fake provider limit",
+	}, parsed)
+}
+
+func TestFakeProviderParseCallbackRejectsBadSignature(t *testing.T) {
+	f := NewFakeProvider(FakeOptions{CallbackSecret: testSecret})
+	body := []byte(`{"payment_id":"payment-1","transaction_id":"fake_payment-1","status":"authorized"}`)
+
+	signed := func(secret string, body []byte) http.Header {
+		signer := NewFakeProvider(FakeOptions{CallbackSecret: secret})
+		header := http.Header{}
+		header.Set(FakeSignatureHeader, hex.EncodeToString(signer.sign(body)))
+		return header
+	}
+
+	tests := []struct {
+		name   string
+		header http.Header
+		body   []byte
+	}{
+		{name: "missing signature", header: http.Header{}, body: body},
+		{name: "not hex", header: http.Header{FakeSignatureHeader: {"not-hex"}}, body: body},
+		{name: "other secret", header: signed("other-secret", body), body: body},
+		{name: "tampered body", header: signed(testSecret, body), body: []byte(`{"payment_id":"payment-1","status":"captured"}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cb, err := f.ParseCallback(tt.header, tt.body)
+			require.Error(t, err)
+			assert.Nil(t, cb)
+			assert.Equal(t, x.CodeHTTPUnauthorized, x.ErrCode(err))
+		})
+	}
+
+	// The same body with its own signature is accepted
+	cb, err := f.ParseCallback(signed(testSecret, body), body)
+	require.NoError(t, err)
+	assert.Equal(t, StatusAuthorized, cb.Status)
+}
@@ -0,0 +1,72 @@
+package payment
+
+import (
+	"context"
+	"net/http"
+
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+)
+
+const ProviderFake = "fake"
+
+// Status is the outcome a provider reports for an operation. StatusPending
+// means the provider settles it later and reports back through a callback.
+type Status string
+
+const (
+	StatusPending    Status = "pending"
+	StatusAuthorized Status = "authorized"
+	StatusCaptured   Status = "captured"
+	StatusRefunded   Status = "refunded"
+	StatusDeclined   Status = "declined"
+)
+
+type AuthorizeRequest struct {
+	// PaymentID is sent as the provider's idempotency key, so retrying an
+	// authorization never holds the funds twice.
+	PaymentID string
+	OrderID   string
+	Method    string
+	Amount    float64
+}
+
+type Result struct {
+	TransactionID string
+	Status        Status
+	Reason        string
+}
+
+type Callback struct {
+	PaymentID     string
+	TransactionID string
+	Status        Status
+	Reason        string
+}
+
+// PaymentProvider is the gateway order payments are settled through. A
+// transport error means the outcome is unknown; a declined payment is a
+// Result with StatusDeclined.
+type PaymentProvider interface {
+	Name() string
+	Authorize(ctx context.Context, req AuthorizeRequest) (*Result, error)
+	Capture(ctx context.Context, transactionID string, amount float64) (*Result, error)
+	Refund(ctx context.Context, transactionID string, amount float64) (*Result, error)
+
+	// ParseCallback verifies and decodes a notification the provider posted
+	// to the callback endpoint.
+	ParseCallback(header http.Header, body []byte) (*Callback, error)
+}
+
+type Options struct {
+	Provider string      `yaml:"provider"`
+	Fake     FakeOptions `yaml:"fake"`
+}
+
+func NewPaymentProvider(opts Options) (PaymentProvider, error) {
+	switch opts.Provider {
+	case "", ProviderFake:
+		return NewFakeProvider(opts.Fake), nil
+	default:
+		return nil, x.New("Unknown payment provider %q", opts.Provider)
+	}
+}
@@ -2,33 +2,60 @@ package order
 
 import (
 	"context"
+	"time"
 
 	"github.com/linggaaskaedo/go-kill/common/component/query"
-	productpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/product"
 	"github.com/linggaaskaedo/go-kill/order-service/src/internal/model/dto"
 	"github.com/linggaaskaedo/go-kill/order-service/src/internal/model/entity"
 
 	"github.com/jmoiron/sqlx"
-	"google.golang.org/grpc"
 )
 
+// ReturnStockFunc gives the items of a cancelled order back to product-service.
+// It runs before the cancellation commits, so a failure rolls the cancel back.
+type ReturnStockFunc func(ctx context.Context, items []*entity.OrderItem) error
+
+// OutboxMessageFunc builds the event for a freshly inserted order so it can be
+// written to the outbox inside the same transaction.
+type OutboxMessageFunc func(orderID, orderNumber string) (*entity.OutboxMessage, error)
+
 type OrderRepositoryItf interface {
-	StoreOrder(ctx context.Context, productDetails []*dto.ProductDetails, createOrders *dto.CreateOrderRequest, totalAmount float64) (*string, *string, error)
+	StoreOrder(ctx context.Context, sagaID string, productDetails []*dto.ProductDetails, createOrders *dto.CreateOrderRequest, totalAmount float64, newEvent OutboxMessageFunc) (*string, *string, error)
 	GetOrder(ctx context.Context, reqData *dto.GetOrderRequest) (*entity.Order, error)
 	ListOrders(ctx context.Context, reqData *dto.ListOrderRequest) ([]*entity.Order, int32, error)
-	CancelOrder(ctx context.Context, reqData *dto.CancelOrderRequest) error
+	GetOrderByID(ctx context.Context, orderID string) (*entity.Order, error)
+	GetAnyOrder(ctx context.Context, orderID string) (*entity.Order, error)
+	UpdateOrderStatus(ctx context.Context, change *entity.OrderStatusChange, events []*entity.OutboxMessage, returnStock ReturnStockFunc) error
+
+	// Order saga
+	CreateSaga(ctx context.Context, saga *entity.OrderSaga) error
+	UpdateSagaStatus(ctx context.Context, sagaID string, from entity.SagaStatus, to entity.SagaStatus, lastErr *string) error
+	UpdateSagaReservation(ctx context.Context, sagaID string, status entity.SagaStatus, reservationID string) error
+	GetSagaByOrderID(ctx context.Context, orderID string) (*entity.OrderSaga, error)
+	ListStaleSagas(ctx context.Context, staleAfter time.Duration, limit int) ([]*entity.OrderSaga, error)
+	ClaimSaga(ctx context.Context, sagaID string, status entity.SagaStatus, staleAfter time.Duration) (bool, error)
+	StorePayment(ctx context.Context, sagaID string, payment *entity.Payment) error
+	CompensateOrder(ctx context.Context, sagaID string, from entity.SagaStatus, orderID string, reason string, events []*entity.OutboxMessage) error
+
+	// Payments
+	GetPayment(ctx context.Context, paymentID string) (*entity.Payment, error)
+	GetPaymentByOrderID(ctx context.Context, orderID string) (*entity.Payment, error)
+	UpdatePaymentStatus(ctx context.Context, change *entity.PaymentStatusChange, events []*entity.OutboxMessage) error
+
+	// Outbox relay
+	ClaimOutbox(ctx context.Context, claimToken string, lease time.Duration, limit int) ([]*entity.OutboxMessage, error)
+	MarkOutboxSent(ctx context.Context, id string, claimToken string) error
+	MarkOutboxRetry(ctx context.Context, id string, claimToken string, status entity.OutboxStatus, lastErr string, backoff time.Duration) error
 }
 
 type orderRepository struct {
-	db0           *sqlx.DB
-	queryLoader   *query.QueryComponent
-	productClient productpb.ProductServiceClient
+	db0         *sqlx.DB
+	queryLoader *query.QueryComponent
 }
 
-func InitOrderRepository(db0 *sqlx.DB, queryLoader *query.QueryComponent, productClientConn *grpc.ClientConn) OrderRepositoryItf {
+func InitOrderRepository(db0 *sqlx.DB, queryLoader *query.QueryComponent) OrderRepositoryItf {
 	return &orderRepository{
-		db0:           db0,
-		queryLoader:   queryLoader,
-		productClient: productpb.NewProductServiceClient(productClientConn),
+		db0:         db0,
+		queryLoader: queryLoader,
 	}
 }
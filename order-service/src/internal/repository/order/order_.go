@@ -3,46 +3,57 @@ package order
 import (
 	"context"
 	"database/sql"
-	"fmt"
 	"time"
 
 	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
-	productpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/product"
 	"github.com/linggaaskaedo/go-kill/order-service/src/internal/model/dto"
 	"github.com/linggaaskaedo/go-kill/order-service/src/internal/model/entity"
-	"github.com/linggaaskaedo/go-kill/order-service/src/internal/util"
 
+	"github.com/openpcc/openpcc/uuidv7"
 	"github.com/rs/zerolog"
 )
 
-func (r *orderRepository) StoreOrder(ctx context.Context, productDetails []*dto.ProductDetails, createOrders *dto.CreateOrderRequest, totalAmount float64) (*string, *string, error) {
-	// Step 4: Reserve inventory
-	var inventoryItems []*productpb.InventoryItem
-	for _, item := range createOrders.Items {
-		inventoryItems = append(inventoryItems, &productpb.InventoryItem{ProductId: item.ProductID, Quantity: item.Quantity})
-	}
+// StoreOrder draws an order number and writes the order. A number that is
+// already taken makes it draw a fresh one and retry the whole transaction.
+func (r *orderRepository) StoreOrder(ctx context.Context, sagaID string, productDetails []*dto.ProductDetails, createOrders *dto.CreateOrderRequest, totalAmount float64, newEvent OutboxMessageFunc) (*string, *string, error) {
+	return withOrderNumber(ctx, r.nextOrderNumber, func(orderNumber string) (*string, error) {
+		return r.storeOrder(ctx, sagaID, orderNumber, productDetails, createOrders, totalAmount, newEvent)
+	})
+}
+
+// withOrderNumber runs store with a freshly drawn order number, up to
+// maxOrderNumberAttempts times while store reports the number taken.
+func withOrderNumber(ctx context.Context, next func(context.Context, time.Time) (string, error), store func(orderNumber string) (*string, error)) (*string, *string, error) {
+	for attempt := 1; ; attempt++ {
+		orderNumber, err := next(ctx, time.Now())
+		if err != nil {
+			return nil, nil, err
+		}
 
-	reserveResp, err := r.productClient.ReserveInventory(ctx, &productpb.ReserveInventoryRequest{Items: inventoryItems})
-	if err != nil || !reserveResp.Success {
-		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to reserve inventory")
-		return nil, nil, x.New("Failed to reserve inventory", err)
+		orderID, err := store(orderNumber)
+		if x.ErrCode(err) == x.CodeSQLUniqueConstraint && attempt < maxOrderNumberAttempts {
+			zerolog.Ctx(ctx).Warn().Str("orderNumber", orderNumber).Int("attempt", attempt).Msg("order_number_taken")
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return orderID, &orderNumber, nil
 	}
+}
 
-	// Step 5: Create order in MySQL
+func (r *orderRepository) storeOrder(ctx context.Context, sagaID string, orderNumber string, productDetails []*dto.ProductDetails, createOrders *dto.CreateOrderRequest, totalAmount float64, newEvent OutboxMessageFunc) (*string, error) {
 	tx, err := r.db0.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
 	if err != nil {
 		zerolog.Ctx(ctx).Error().Err(err).Msg("tx_store_order")
-		if releaseErr := r.releaseInventory(ctx, inventoryItems); releaseErr != nil {
-			zerolog.Ctx(ctx).Error().Err(releaseErr).Msg("failed_to_release_inventory_after_tx_begin_error")
-		}
-
-		return nil, nil, err
+		return nil, err
 	}
 
-	// Insert order
-	now := time.Now()
-	orderNumber := fmt.Sprintf("ORD-%s-%d", now.Format("20060102"), now.Unix()%1000000)
+	// Insert order; the id is generated here so the items, outbox event and
+	// saga written in the same transaction can reference it
 	order := &entity.Order{
+		ID:                uuidv7.MustNew().String(),
 		UserID:            createOrders.UserID,
 		OrderNumber:       orderNumber,
 		Status:            entity.StatusPending,
@@ -54,67 +65,73 @@ func (r *orderRepository) StoreOrder(ctx context.Context, productDetails []*dto.
 	tx, order, err = r.createOrderSQL(ctx, tx, order)
 	if err != nil {
 		_ = tx.Rollback()
-		if releaseErr := r.releaseInventory(ctx, inventoryItems); releaseErr != nil {
-			zerolog.Ctx(ctx).Error().Err(releaseErr).Msg("rollback_release_inventory_failed")
-		}
-
-		return nil, nil, err
+		return nil, err
 	}
 
 	// Insert order items (one-to-many relationship)
 	tx, err = r.createOrderItemsSQL(ctx, tx, order.ID, productDetails, createOrders)
 	if err != nil {
 		_ = tx.Rollback()
-		if releaseErr := r.releaseInventory(ctx, inventoryItems); releaseErr != nil {
-			zerolog.Ctx(ctx).Error().Err(releaseErr).Msg("rollback_release_inventory_failed")
-		}
-
-		return nil, nil, err
+		return nil, err
 	}
 
-	// Insert payment record
-	payment := &entity.Payment{
-		OrderID:       order.ID,
-		PaymentMethod: createOrders.PaymentMethod,
-		Amount:        totalAmount,
+	// Insert status history
+	tx, err = r.createStatusHistorySQL(ctx, tx, order.ID, string(entity.StatusPending), createOrders.UserID, "Order created")
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
 	}
 
-	tx, err = r.createPaymentSQL(ctx, tx, payment)
+	// Insert order.created event into the outbox, committed together with the order
+	event, err := newEvent(order.ID, orderNumber)
+	if err == nil {
+		tx, err = r.createOutboxSQL(ctx, tx, event)
+	}
 	if err != nil {
 		_ = tx.Rollback()
-		if releaseErr := r.releaseInventory(ctx, inventoryItems); releaseErr != nil {
-			zerolog.Ctx(ctx).Error().Err(releaseErr).Msg("rollback_release_inventory_failed")
-		}
-
-		return nil, nil, err
+		return nil, err
 	}
 
-	// Insert status history
-	tx, err = r.createStatusHistorySQL(ctx, tx, order.ID, string(entity.StatusPending), "Order created")
+	// Advance the saga in the same transaction so a crash leaves either both or neither
+	tx, err = r.updateSagaStatusSQL(ctx, tx, sagaID, entity.SagaInventoryReserved, entity.SagaOrderCreated, &order.ID, nil)
 	if err != nil {
 		_ = tx.Rollback()
-		if releaseErr := r.releaseInventory(ctx, inventoryItems); releaseErr != nil {
-			zerolog.Ctx(ctx).Error().Err(releaseErr).Msg("rollback_release_inventory_failed")
-		}
-
-		return nil, nil, err
+		return nil, err
 	}
 
 	if err = tx.Commit(); err != nil {
 		zerolog.Ctx(ctx).Error().Err(err).Msg("commit_store_order")
 
-		return nil, nil, x.Wrap(err, "commit_store_order")
+		return nil, x.Wrap(err, "commit_store_order")
 	}
 
-	return &order.ID, &orderNumber, nil
+	return &order.ID, nil
 }
 
-func (r *orderRepository) releaseInventory(ctx context.Context, inventoryItems []*productpb.InventoryItem) error {
-	_, err := r.productClient.ReleaseInventory(ctx, &productpb.ReleaseInventoryRequest{Items: inventoryItems})
+func (r *orderRepository) StorePayment(ctx context.Context, sagaID string, payment *entity.Payment) error {
+	tx, err := r.db0.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("tx_store_payment")
+		return err
+	}
+
+	tx, err = r.createPaymentSQL(ctx, tx, payment)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	tx, err = r.updateSagaStatusSQL(ctx, tx, sagaID, entity.SagaOrderCreated, entity.SagaPaymentCreated, nil, nil)
 	if err != nil {
-		zerolog.Ctx(ctx).Error().Err(err).Msg("release_inventory_failed")
-		return x.New("failed to release inventory", err)
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("commit_store_payment")
+		return x.Wrap(err, "commit_store_payment")
 	}
+
 	return nil
 }
 
@@ -148,55 +165,227 @@ func (r *orderRepository) ListOrders(ctx context.Context, reqData *dto.ListOrder
 	return orders, total, nil
 }
 
-func (r *orderRepository) CancelOrder(ctx context.Context, reqData *dto.CancelOrderRequest) error {
-	order, err := r.getOrderSQL(ctx, reqData.OrderID, reqData.UserID)
+func (r *orderRepository) GetOrderByID(ctx context.Context, orderID string) (*entity.Order, error) {
+	return r.getOrderByIDSQL(ctx, orderID)
+}
+
+// GetAnyOrder is GetOrder without the owner check, for admins.
+func (r *orderRepository) GetAnyOrder(ctx context.Context, orderID string) (*entity.Order, error) {
+	order, err := r.getOrderByIDSQL(ctx, orderID)
 	if err != nil {
+		return nil, err
+	}
+
+	orderItems, err := r.getOrderItemSQL(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	order.Items = orderItems
+
+	return order, nil
+}
+
+// UpdateOrderStatus applies one status transition together with its history
+// row, payment change and outbox events. The update only matches while the
+// order is still in change.From, so two concurrent transitions cannot both
+// win. returnStock is optional and, when set, runs before the commit.
+func (r *orderRepository) UpdateOrderStatus(ctx context.Context, change *entity.OrderStatusChange, events []*entity.OutboxMessage, returnStock ReturnStockFunc) error {
+	var orderItems []*entity.OrderItem
+	if returnStock != nil {
+		var err error
+		orderItems, err = r.getOrderItemSQL(ctx, change.OrderID)
+		if err != nil {
+			return err
+		}
+	}
+
+	tx, err := r.db0.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("tx_update_order_status")
 		return err
 	}
 
-	if order.Status != entity.StatusPending && order.Status != entity.StatusConfirmed {
-		return x.New("Order cannot be cancelled")
+	// Update order status
+	tx, err = r.updateOrderStatusSQL(ctx, tx, change.OrderID, change.From, change.To)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
 	}
 
-	orderItems, err := r.getOrderItemSQL(ctx, reqData.OrderID)
+	// Add status history
+	tx, err = r.createStatusHistorySQL(ctx, tx, change.OrderID, string(change.To), change.Actor, change.Note)
 	if err != nil {
+		_ = tx.Rollback()
 		return err
 	}
 
+	// Move the payment along with the order
+	if change.Payment != nil {
+		tx, err = r.updatePaymentStatusSQL(ctx, tx, change.Payment)
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	// Add status events to the outbox
+	for _, event := range events {
+		tx, err = r.createOutboxSQL(ctx, tx, event)
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	// Return stock to product-service before committing
+	if returnStock != nil {
+		if err = returnStock(ctx, orderItems); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("commit_update_order_status")
+		return x.Wrap(err, "commit_update_order_status")
+	}
+
+	return nil
+}
+
+func (r *orderRepository) GetPayment(ctx context.Context, paymentID string) (*entity.Payment, error) {
+	return r.getPaymentSQL(ctx, "GetPayment", paymentID)
+}
+
+func (r *orderRepository) GetPaymentByOrderID(ctx context.Context, orderID string) (*entity.Payment, error) {
+	return r.getPaymentSQL(ctx, "GetPaymentByOrderID", orderID)
+}
+
+// UpdatePaymentStatus records a payment outcome that does not move the order,
+// such as a capture confirmed by a provider callback.
+func (r *orderRepository) UpdatePaymentStatus(ctx context.Context, change *entity.PaymentStatusChange, events []*entity.OutboxMessage) error {
 	tx, err := r.db0.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
 	if err != nil {
-		zerolog.Ctx(ctx).Error().Err(err).Msg("tx_cancel_order")
+		zerolog.Ctx(ctx).Error().Err(err).Msg("tx_update_payment_status")
 		return err
 	}
 
-	// Update order status
-	tx, err = r.updateOrderStatusSQL(ctx, tx, reqData.OrderID)
+	tx, err = r.updatePaymentStatusSQL(ctx, tx, change)
 	if err != nil {
 		_ = tx.Rollback()
 		return err
 	}
 
-	// Add status history
-	tx, err = r.createStatusHistorySQL(ctx, tx, order.ID, string(entity.StatusCancelled), reqData.Reason)
+	for _, event := range events {
+		tx, err = r.createOutboxSQL(ctx, tx, event)
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("commit_update_payment_status")
+		return x.Wrap(err, "commit_update_payment_status")
+	}
+
+	return nil
+}
+
+// CompensateOrder cancels the still pending order of a saga in status from
+// and moves the saga to compensating, in one transaction.
+func (r *orderRepository) CompensateOrder(ctx context.Context, sagaID string, from entity.SagaStatus, orderID string, reason string, events []*entity.OutboxMessage) error {
+	tx, err := r.db0.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("tx_compensate_order")
+		return err
+	}
+
+	tx, err = r.updateOrderStatusSQL(ctx, tx, orderID, entity.StatusPending, entity.StatusCancelled)
 	if err != nil {
 		_ = tx.Rollback()
 		return err
 	}
 
-	// Release inventory
-	_, err = r.productClient.ReleaseInventory(ctx, &productpb.ReleaseInventoryRequest{
-		Items: util.ToInventoryItemPB(orderItems),
-	})
+	tx, err = r.cancelOrderPaymentSQL(ctx, tx, orderID)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	tx, err = r.createStatusHistorySQL(ctx, tx, orderID, string(entity.StatusCancelled), entity.ActorSystem, reason)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	for _, event := range events {
+		tx, err = r.createOutboxSQL(ctx, tx, event)
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	tx, err = r.updateSagaStatusSQL(ctx, tx, sagaID, from, entity.SagaCompensating, nil, &reason)
 	if err != nil {
-		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to release inventory")
 		_ = tx.Rollback()
 		return err
 	}
 
 	if err = tx.Commit(); err != nil {
-		zerolog.Ctx(ctx).Error().Err(err).Msg("commit_cancel_order")
-		return x.Wrap(err, "commit_cancel_order")
+		zerolog.Ctx(ctx).Error().Err(err).Msg("commit_compensate_order")
+		return x.Wrap(err, "commit_compensate_order")
 	}
 
 	return nil
 }
+
+func (r *orderRepository) CreateSaga(ctx context.Context, saga *entity.OrderSaga) error {
+	return r.createSagaSQL(ctx, saga)
+}
+
+func (r *orderRepository) UpdateSagaStatus(ctx context.Context, sagaID string, from entity.SagaStatus, to entity.SagaStatus, lastErr *string) error {
+	_, err := r.updateSagaStatusSQL(ctx, nil, sagaID, from, to, nil, lastErr)
+	return err
+}
+
+// UpdateSagaReservation points a saga that is still in status at a new
+// inventory reservation.
+func (r *orderRepository) UpdateSagaReservation(ctx context.Context, sagaID string, status entity.SagaStatus, reservationID string) error {
+	return r.updateSagaReservationSQL(ctx, sagaID, status, reservationID)
+}
+
+func (r *orderRepository) GetSagaByOrderID(ctx context.Context, orderID string) (*entity.OrderSaga, error) {
+	return r.getSagaByOrderIDSQL(ctx, orderID)
+}
+
+func (r *orderRepository) ListStaleSagas(ctx context.Context, staleAfter time.Duration, limit int) ([]*entity.OrderSaga, error) {
+	return r.getStaleSagasSQL(ctx, staleAfter, limit)
+}
+
+func (r *orderRepository) ClaimSaga(ctx context.Context, sagaID string, status entity.SagaStatus, staleAfter time.Duration) (bool, error) {
+	return r.claimSagaSQL(ctx, sagaID, status, staleAfter)
+}
+
+func (r *orderRepository) ClaimOutbox(ctx context.Context, claimToken string, lease time.Duration, limit int) ([]*entity.OutboxMessage, error) {
+	claimed, err := r.claimOutboxSQL(ctx, claimToken, lease, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if claimed == 0 {
+		return []*entity.OutboxMessage{}, nil
+	}
+
+	return r.getClaimedOutboxSQL(ctx, claimToken)
+}
+
+func (r *orderRepository) MarkOutboxSent(ctx context.Context, id string, claimToken string) error {
+	return r.markOutboxSentSQL(ctx, id, claimToken)
+}
+
+func (r *orderRepository) MarkOutboxRetry(ctx context.Context, id string, claimToken string, status entity.OutboxStatus, lastErr string, backoff time.Duration) error {
+	return r.markOutboxRetrySQL(ctx, id, claimToken, status, lastErr, backoff)
+}
@@ -0,0 +1,76 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/rs/zerolog"
+)
+
+// maxOrderNumberAttempts bounds how often StoreOrder draws a new number after
+// a unique-constraint hit, e.g. against numbers issued before the sequence
+// table existed.
+const maxOrderNumberAttempts = 3
+
+const mysqlErrDuplicateEntry = 1062
+
+// formatOrderNumber renders ORD-YYYYMMDD-NNNNNN. The sequence is zero padded
+// to six digits so numbers sort in issue order within a day.
+func formatOrderNumber(day time.Time, seq int64) string {
+	return fmt.Sprintf("ORD-%s-%06d", day.Format("20060102"), seq)
+}
+
+// nextOrderNumber draws the next number for day from order_number_sequences.
+// It runs in its own short transaction so the sequence row is not locked for
+// the lifetime of the order transaction; a rolled back order leaves a gap,
+// never a duplicate.
+func (r *orderRepository) nextOrderNumber(ctx context.Context, day time.Time) (string, error) {
+	query, ok := r.queryLoader.Get("NextOrderNumber")
+	if !ok {
+		zerolog.Ctx(ctx).Error().Str("query", "NextOrderNumber").Msg("query_not_found")
+		return "", x.NewWithCode(x.CodeSQLQueryBuild, "query_NextOrderNumber_not_found")
+	}
+
+	lastIDQuery, ok := r.queryLoader.Get("GetLastInsertID")
+	if !ok {
+		zerolog.Ctx(ctx).Error().Str("query", "GetLastInsertID").Msg("query_not_found")
+		return "", x.NewWithCode(x.CodeSQLQueryBuild, "query_GetLastInsertID_not_found")
+	}
+
+	// LAST_INSERT_ID is per connection, so both statements share one tx
+	tx, err := r.db0.BeginTxx(ctx, nil)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("tx_next_order_number")
+		return "", x.WrapWithCode(err, x.CodeSQLTxBegin, "tx_next_order_number")
+	}
+
+	if _, err := tx.ExecContext(ctx, query, day.Format("2006-01-02")); err != nil {
+		_ = tx.Rollback()
+		zerolog.Ctx(ctx).Error().Err(err).Msg("next_order_number_sql")
+		return "", x.WrapWithCode(err, x.CodeSQLUpdate, "next_order_number_sql")
+	}
+
+	var seq int64
+	if err := tx.QueryRowxContext(ctx, lastIDQuery).Scan(&seq); err != nil {
+		_ = tx.Rollback()
+		zerolog.Ctx(ctx).Error().Err(err).Msg("next_order_number_last_insert_id")
+		return "", x.WrapWithCode(err, x.CodeSQLCannotRetrieveLastInsertID, "next_order_number_last_insert_id")
+	}
+
+	if err := tx.Commit(); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("commit_next_order_number")
+		return "", x.WrapWithCode(err, x.CodeSQLTxCommit, "commit_next_order_number")
+	}
+
+	return formatOrderNumber(day, seq), nil
+}
+
+func isDuplicateEntry(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDuplicateEntry
+}
@@ -0,0 +1,112 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatOrderNumber(t *testing.T) {
+	day := time.Date(2026, 3, 7, 23, 59, 0, 0, time.UTC)
+
+	tests := []struct {
+		seq  int64
+		want string
+	}{
+		{seq: 1, want: "ORD-20260307-000001"},
+		{seq: 42, want: "ORD-20260307-000042"},
+		{seq: 999999, want: "ORD-20260307-999999"},
+		{seq: 1000000, want: "ORD-20260307-1000000"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, formatOrderNumber(day, tt.seq))
+	}
+
+	// Within a day the padded numbers sort in issue order
+	assert.Less(t, formatOrderNumber(day, 9), formatOrderNumber(day, 10))
+}
+
+func TestIsDuplicateEntry(t *testing.T) {
+	duplicate := &mysql.MySQLError{Number: mysqlErrDuplicateEntry, Message: "Duplicate entry"}
+
+	assert.True(t, isDuplicateEntry(duplicate))
+	assert.False(t, isDuplicateEntry(&mysql.MySQLError{Number: 1213, Message: "Deadlock found"}))
+	assert.False(t, isDuplicateEntry(errors.New("connection refused")))
+}
+
+// orderNumbers hands out ORD-...-000001, 000002, ... and counts the draws.
+type orderNumbers struct {
+	seq int64
+	err error
+}
+
+func (n *orderNumbers) next(ctx context.Context, day time.Time) (string, error) {
+	if n.err != nil {
+		return "", n.err
+	}
+
+	n.seq++
+	return formatOrderNumber(day, n.seq), nil
+}
+
+func TestWithOrderNumberRetriesTakenNumber(t *testing.T) {
+	numbers := &orderNumbers{}
+	var tried []string
+
+	orderID, orderNumber, err := withOrderNumber(context.Background(), numbers.next, func(orderNumber string) (*string, error) {
+		tried = append(tried, orderNumber)
+		if len(tried) == 1 {
+			return nil, x.NewWithCode(x.CodeSQLUniqueConstraint, "order_number_taken")
+		}
+
+		id := "order-1"
+		return &id, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "order-1", *orderID)
+	require.Len(t, tried, 2)
+	assert.NotEqual(t, tried[0], tried[1])
+	assert.Equal(t, tried[1], *orderNumber)
+}
+
+func TestWithOrderNumberGivesUpAfterMaxAttempts(t *testing.T) {
+	numbers := &orderNumbers{}
+	attempts := 0
+
+	_, _, err := withOrderNumber(context.Background(), numbers.next, func(orderNumber string) (*string, error) {
+		attempts++
+		return nil, x.NewWithCode(x.CodeSQLUniqueConstraint, "order_number_taken")
+	})
+	require.Error(t, err)
+	assert.Equal(t, x.CodeSQLUniqueConstraint, x.ErrCode(err))
+	assert.Equal(t, maxOrderNumberAttempts, attempts)
+	assert.EqualValues(t, maxOrderNumberAttempts, numbers.seq)
+}
+
+func TestWithOrderNumberDoesNotRetryOtherErrors(t *testing.T) {
+	numbers := &orderNumbers{}
+	attempts := 0
+
+	_, _, err := withOrderNumber(context.Background(), numbers.next, func(orderNumber string) (*string, error) {
+		attempts++
+		return nil, x.NewWithCode(x.CodeSQLCreate, "create_order_sql")
+	})
+	assert.Equal(t, x.CodeSQLCreate, x.ErrCode(err))
+	assert.Equal(t, 1, attempts)
+
+	// Nothing is stored without a number
+	numbers.err = x.NewWithCode(x.CodeSQLUpdate, "next_order_number_sql")
+	_, _, err = withOrderNumber(context.Background(), numbers.next, func(orderNumber string) (*string, error) {
+		t.Fatal("store called without an order number")
+		return nil, nil
+	})
+	assert.Equal(t, x.CodeSQLUpdate, x.ErrCode(err))
+}
@@ -0,0 +1,205 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/linggaaskaedo/go-kill/common/component/query"
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+	"github.com/linggaaskaedo/go-kill/order-service/src/internal/model/entity"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testClaimToken = "claim-1"
+
+type recordedCall struct {
+	query string
+	args  []any
+}
+
+// recordingDB is a database/sql driver that records every statement it is
+// given and answers with canned results, so the outbox SQL can be checked
+// against the real query file without a MySQL server.
+type recordingDB struct {
+	execs        []recordedCall
+	queries      []recordedCall
+	rowsAffected int64
+	execErr      error
+	rows         [][]driver.Value
+}
+
+func (db *recordingDB) Connect(context.Context) (driver.Conn, error) {
+	return &recordingConn{db: db}, nil
+}
+
+func (db *recordingDB) Driver() driver.Driver { return recordingDriver{} }
+
+type recordingDriver struct{}
+
+func (recordingDriver) Open(string) (driver.Conn, error) { return nil, errors.New("not supported") }
+
+type recordingConn struct {
+	db *recordingDB
+}
+
+func (c *recordingConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *recordingConn) Close() error                        { return nil }
+func (c *recordingConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c *recordingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.db.execs = append(c.db.execs, recordedCall{query: query, args: values(args)})
+	if c.db.execErr != nil {
+		return nil, c.db.execErr
+	}
+	return driver.RowsAffected(c.db.rowsAffected), nil
+}
+
+func (c *recordingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.db.queries = append(c.db.queries, recordedCall{query: query, args: values(args)})
+	return &recordingRows{rows: c.db.rows}, nil
+}
+
+type recordingRows struct {
+	rows [][]driver.Value
+}
+
+func (r *recordingRows) Columns() []string {
+	return []string{"id", "aggregate_id", "event_type", "topic", "message_key", "payload", "status", "attempts"}
+}
+
+func (r *recordingRows) Close() error { return nil }
+
+func (r *recordingRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func values(args []driver.NamedValue) []any {
+	out := make([]any, len(args))
+	for i, arg := range args {
+		out[i] = arg.Value
+	}
+	return out
+}
+
+func newTestOutboxRepository(t *testing.T, db *recordingDB) *orderRepository {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	queryLoader := query.NewQueryComponent(zerolog.Nop(), query.Config{Path: "../../../../etc/sql"})
+	errCh := make(chan error, 1)
+	go func() { errCh <- queryLoader.Start(ctx) }()
+
+	select {
+	case <-queryLoader.Ready():
+	case err := <-errCh:
+		t.Fatalf("load queries: %v", err)
+	}
+
+	return &orderRepository{
+		db0:         sqlx.NewDb(sql.OpenDB(db), "mysql"),
+		queryLoader: queryLoader,
+	}
+}
+
+func outboxRow(id string, attempts int64) []driver.Value {
+	return []driver.Value{id, "order-1", "order.created", "order.created", "user-1", []byte(`{}`), "pending", attempts}
+}
+
+func TestClaimOutboxReadsBackClaimedMessages(t *testing.T) {
+	db := &recordingDB{rowsAffected: 2, rows: [][]driver.Value{outboxRow("1", 0), outboxRow("2", 3)}}
+	repo := newTestOutboxRepository(t, db)
+
+	messages, err := repo.ClaimOutbox(context.Background(), testClaimToken, 30*time.Second, 10)
+	require.NoError(t, err)
+
+	// The claim stamps the token and pushes next_attempt_at out by the lease
+	require.Len(t, db.execs, 1)
+	assert.Contains(t, db.execs[0].query, "SET claim_token = ?")
+	assert.Contains(t, db.execs[0].query, "WHERE status = 'pending' AND next_attempt_at <= NOW()")
+	assert.Equal(t, []any{testClaimToken, int64(30), int64(10)}, db.execs[0].args)
+
+	// Only the rows carrying this claim's token are read back
+	require.Len(t, db.queries, 1)
+	assert.Contains(t, db.queries[0].query, "WHERE claim_token = ? AND status = 'pending'")
+	assert.Equal(t, []any{testClaimToken}, db.queries[0].args)
+
+	require.Len(t, messages, 2)
+	assert.Equal(t, "1", messages[0].ID)
+	assert.Equal(t, entity.OutboxPending, messages[0].Status)
+	assert.Equal(t, []byte(`{}`), messages[0].Payload)
+	assert.Equal(t, 3, messages[1].Attempts)
+}
+
+func TestClaimOutboxSkipsReadWhenNothingClaimed(t *testing.T) {
+	db := &recordingDB{}
+	repo := newTestOutboxRepository(t, db)
+
+	messages, err := repo.ClaimOutbox(context.Background(), testClaimToken, 30*time.Second, 10)
+	require.NoError(t, err)
+	assert.Empty(t, messages)
+	assert.Len(t, db.execs, 1)
+	assert.Empty(t, db.queries)
+}
+
+func TestClaimOutboxReturnsExecError(t *testing.T) {
+	db := &recordingDB{execErr: errors.New("connection refused")}
+	repo := newTestOutboxRepository(t, db)
+
+	_, err := repo.ClaimOutbox(context.Background(), testClaimToken, 30*time.Second, 10)
+	require.Error(t, err)
+	assert.Equal(t, x.CodeSQLUpdate, x.ErrCode(err))
+	assert.Empty(t, db.queries)
+}
+
+func TestMarkOutboxSentReleasesClaim(t *testing.T) {
+	db := &recordingDB{rowsAffected: 1}
+	repo := newTestOutboxRepository(t, db)
+
+	require.NoError(t, repo.MarkOutboxSent(context.Background(), "1", testClaimToken))
+
+	require.Len(t, db.execs, 1)
+	assert.Contains(t, db.execs[0].query, "SET status = 'sent', attempts = attempts + 1")
+	assert.Contains(t, db.execs[0].query, "claim_token = NULL")
+	assert.Equal(t, []any{"1", testClaimToken}, db.execs[0].args)
+}
+
+func TestMarkOutboxRetrySchedulesNextAttempt(t *testing.T) {
+	tests := []struct {
+		name   string
+		status entity.OutboxStatus
+	}{
+		{name: "retry", status: entity.OutboxPending},
+		{name: "give up", status: entity.OutboxFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &recordingDB{rowsAffected: 1}
+			repo := newTestOutboxRepository(t, db)
+
+			require.NoError(t, repo.MarkOutboxRetry(context.Background(), "1", testClaimToken, tt.status, "broker down", 4*time.Second))
+
+			require.Len(t, db.execs, 1)
+			assert.Contains(t, db.execs[0].query, "attempts = attempts + 1")
+			assert.Contains(t, db.execs[0].query, "next_attempt_at = DATE_ADD(NOW(), INTERVAL ? SECOND)")
+			assert.Contains(t, db.execs[0].query, "WHERE id = ? AND claim_token = ?")
+			assert.Equal(t, []any{string(tt.status), "broker down", int64(4), "1", testClaimToken}, db.execs[0].args)
+		})
+	}
+}
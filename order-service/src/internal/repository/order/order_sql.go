@@ -3,8 +3,9 @@ package order
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
-	"fmt"
+	"time"
 
 	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
 	"github.com/linggaaskaedo/go-kill/order-service/src/internal/model/dto"
@@ -20,24 +21,17 @@ func (r *orderRepository) createOrderSQL(ctx context.Context, tx *sqlx.Tx, order
 		zerolog.Ctx(ctx).Error().Str("query", "CreateOrder").Msg("query_not_found")
 		return tx, order, x.NewWithCode(x.CodeSQLQueryBuild, "query_CreateOrder_not_found")
 	}
-	_, err := tx.ExecContext(ctx, query, order.UserID, order.OrderNumber, order.Status, order.TotalAmount, order.ShippingAddressID, order.BillingAddressID)
+	_, err := tx.ExecContext(ctx, query, order.ID, order.UserID, order.OrderNumber, order.Status, order.TotalAmount, order.ShippingAddressID, order.BillingAddressID)
 	if err != nil {
 		zerolog.Ctx(ctx).Error().Str("userID", order.UserID).Str("orderID", order.OrderNumber).Msg("create_order_sql")
-		return tx, order, x.WrapWithCode(err, x.CodeSQLCreate, "create_order_sql")
-	}
 
-	lastIDQuery, ok := r.queryLoader.Get("GetLastInsertID")
-	if !ok {
-		zerolog.Ctx(ctx).Error().Str("query", "GetLastInsertID").Msg("query_not_found")
-		return tx, order, x.NewWithCode(x.CodeSQLQueryBuild, "query_GetLastInsertID_not_found")
-	}
-	var lastInsertID int64
-	err = tx.QueryRowxContext(ctx, lastIDQuery).Scan(&lastInsertID)
-	if err != nil {
-		zerolog.Ctx(ctx).Error().Str("userID", order.UserID).Str("orderID", order.OrderNumber).Msg("get_last_insert_id")
-		return tx, order, x.WrapWithCode(err, x.CodeSQLCannotRetrieveLastInsertID, "get_last_insert_id")
+		// order_number is the only unique column besides the primary key
+		if isDuplicateEntry(err) {
+			return tx, order, x.WrapWithCode(err, x.CodeSQLUniqueConstraint, "order_number_taken")
+		}
+
+		return tx, order, x.WrapWithCode(err, x.CodeSQLCreate, "create_order_sql")
 	}
-	order.ID = fmt.Sprintf("%d", lastInsertID)
 
 	return tx, order, nil
 }
@@ -92,7 +86,7 @@ func (r *orderRepository) createPaymentSQL(ctx context.Context, tx *sqlx.Tx, pay
 		zerolog.Ctx(ctx).Error().Str("query", "CreatePayment").Msg("query_not_found")
 		return tx, x.NewWithCode(x.CodeSQLQueryBuild, "query_CreatePayment_not_found")
 	}
-	result, err := tx.ExecContext(ctx, query, payment.OrderID, payment.PaymentMethod, payment.Amount)
+	result, err := tx.ExecContext(ctx, query, payment.ID, payment.OrderID, payment.PaymentMethod, payment.Amount)
 	if err != nil {
 		zerolog.Ctx(ctx).Error().Str("orderID", payment.OrderID).Msg("create_payment_sql")
 		return tx, x.WrapWithCode(err, x.CodeSQLCreate, "create_payment_sql")
@@ -107,13 +101,13 @@ func (r *orderRepository) createPaymentSQL(ctx context.Context, tx *sqlx.Tx, pay
 	return tx, nil
 }
 
-func (r *orderRepository) createStatusHistorySQL(ctx context.Context, tx *sqlx.Tx, orderID, status, note string) (*sqlx.Tx, error) {
+func (r *orderRepository) createStatusHistorySQL(ctx context.Context, tx *sqlx.Tx, orderID, status, actor, note string) (*sqlx.Tx, error) {
 	query, ok := r.queryLoader.Get("CreateStatusHistory")
 	if !ok {
 		zerolog.Ctx(ctx).Error().Str("query", "CreateStatusHistory").Msg("query_not_found")
 		return tx, x.NewWithCode(x.CodeSQLQueryBuild, "query_CreateStatusHistory_not_found")
 	}
-	result, err := tx.ExecContext(ctx, query, orderID, status, note)
+	result, err := tx.ExecContext(ctx, query, orderID, status, actor, note)
 	if err != nil {
 		zerolog.Ctx(ctx).Error().Str("orderID", orderID).Msg("create_status_history_sql")
 		return tx, x.WrapWithCode(err, x.CodeSQLCreate, "create_status_history_sql")
@@ -260,17 +254,399 @@ func (r *orderRepository) getOrderTotalSQL(ctx context.Context, userID string) (
 	return total, nil
 }
 
-func (r *orderRepository) updateOrderStatusSQL(ctx context.Context, tx *sqlx.Tx, orderID string) (*sqlx.Tx, error) {
+func (r *orderRepository) updateOrderStatusSQL(ctx context.Context, tx *sqlx.Tx, orderID string, from entity.OrderStatus, to entity.OrderStatus) (*sqlx.Tx, error) {
 	query, ok := r.queryLoader.Get("UpdateOrderStatus")
 	if !ok {
 		zerolog.Ctx(ctx).Error().Str("query", "UpdateOrderStatus").Msg("query_not_found")
 		return tx, x.NewWithCode(x.CodeSQLQueryBuild, "query_UpdateOrderStatus_not_found")
 	}
-	_, err := tx.ExecContext(ctx, query, orderID)
+	result, err := tx.ExecContext(ctx, query, to, orderID, from)
 	if err != nil {
 		zerolog.Ctx(ctx).Error().Err(err).Msg("update_order_status_sql")
 		return tx, x.WrapWithCode(err, x.CodeSQLUpdate, "update_order_status_sql")
 	}
 
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("update_order_status_sql")
+		return tx, x.WrapWithCode(err, x.CodeSQLCannotRetrieveAffectedRows, "update_order_status_sql")
+	}
+
+	// The order left `from` since it was read
+	if rowsAffected == 0 {
+		zerolog.Ctx(ctx).Warn().Str("orderID", orderID).Str("from", string(from)).Str("to", string(to)).Msg("update_order_status_sql")
+		return tx, x.NewWithCode(x.CodeSQLConflict, "Order status has changed")
+	}
+
+	return tx, nil
+}
+
+func (r *orderRepository) getOrderByIDSQL(ctx context.Context, orderID string) (*entity.Order, error) {
+	var order entity.Order
+
+	query, ok := r.queryLoader.Get("GetOrderByID")
+	if !ok {
+		zerolog.Ctx(ctx).Error().Str("query", "GetOrderByID").Msg("query_not_found")
+		return nil, x.NewWithCode(x.CodeSQLQueryBuild, "query_GetOrderByID_not_found")
+	}
+	err := r.db0.QueryRowxContext(ctx, query, orderID).StructScan(&order)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("get_order_by_id_sql")
+
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, x.WrapWithCode(err, x.CodeSQLRecordDoesNotExist, "get_order_by_id_sql")
+		}
+
+		return nil, x.WrapWithCode(err, x.CodeSQLRowScan, "get_order_by_id_sql")
+	}
+
+	return &order, nil
+}
+
+func (r *orderRepository) createOutboxSQL(ctx context.Context, tx *sqlx.Tx, event *entity.OutboxMessage) (*sqlx.Tx, error) {
+	query, ok := r.queryLoader.Get("CreateOutbox")
+	if !ok {
+		zerolog.Ctx(ctx).Error().Str("query", "CreateOutbox").Msg("query_not_found")
+		return tx, x.NewWithCode(x.CodeSQLQueryBuild, "query_CreateOutbox_not_found")
+	}
+	_, err := tx.ExecContext(ctx, query, event.AggregateID, event.EventType, event.Topic, event.MessageKey, event.Payload)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("aggregateID", event.AggregateID).Str("eventType", event.EventType).Msg("create_outbox_sql")
+		return tx, x.WrapWithCode(err, x.CodeSQLCreate, "create_outbox_sql")
+	}
+
+	return tx, nil
+}
+
+func (r *orderRepository) claimOutboxSQL(ctx context.Context, claimToken string, lease time.Duration, limit int) (int64, error) {
+	query, ok := r.queryLoader.Get("ClaimOutbox")
+	if !ok {
+		zerolog.Ctx(ctx).Error().Str("query", "ClaimOutbox").Msg("query_not_found")
+		return 0, x.NewWithCode(x.CodeSQLQueryBuild, "query_ClaimOutbox_not_found")
+	}
+	result, err := r.db0.ExecContext(ctx, query, claimToken, int64(lease.Seconds()), limit)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("claim_outbox_sql")
+		return 0, x.WrapWithCode(err, x.CodeSQLUpdate, "claim_outbox_sql")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("claim_outbox_sql")
+		return 0, x.WrapWithCode(err, x.CodeSQLCannotRetrieveAffectedRows, "claim_outbox_sql")
+	}
+
+	return rowsAffected, nil
+}
+
+func (r *orderRepository) getClaimedOutboxSQL(ctx context.Context, claimToken string) ([]*entity.OutboxMessage, error) {
+	messages := make([]*entity.OutboxMessage, 0)
+
+	query, ok := r.queryLoader.Get("GetClaimedOutbox")
+	if !ok {
+		zerolog.Ctx(ctx).Error().Str("query", "GetClaimedOutbox").Msg("query_not_found")
+		return messages, x.NewWithCode(x.CodeSQLQueryBuild, "query_GetClaimedOutbox_not_found")
+	}
+	rows, err := r.db0.QueryContext(ctx, query, claimToken)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("get_claimed_outbox_sql")
+		return messages, x.WrapWithCode(err, x.CodeSQLRead, "get_claimed_outbox_sql")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var message entity.OutboxMessage
+		if err := rows.Scan(
+			&message.ID,
+			&message.AggregateID,
+			&message.EventType,
+			&message.Topic,
+			&message.MessageKey,
+			&message.Payload,
+			&message.Status,
+			&message.Attempts,
+		); err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("get_claimed_outbox_sql_row_scan")
+			return messages, x.WrapWithCode(err, x.CodeSQLRowScan, "get_claimed_outbox_sql_row_scan")
+		}
+
+		messages = append(messages, &message)
+	}
+
+	if err = rows.Err(); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("get_claimed_outbox_sql_rows")
+		return messages, x.WrapWithCode(err, x.CodeSQLRead, "get_claimed_outbox_sql_rows")
+	}
+
+	return messages, nil
+}
+
+func (r *orderRepository) markOutboxSentSQL(ctx context.Context, id string, claimToken string) error {
+	query, ok := r.queryLoader.Get("MarkOutboxSent")
+	if !ok {
+		zerolog.Ctx(ctx).Error().Str("query", "MarkOutboxSent").Msg("query_not_found")
+		return x.NewWithCode(x.CodeSQLQueryBuild, "query_MarkOutboxSent_not_found")
+	}
+	_, err := r.db0.ExecContext(ctx, query, id, claimToken)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("outboxID", id).Msg("mark_outbox_sent_sql")
+		return x.WrapWithCode(err, x.CodeSQLUpdate, "mark_outbox_sent_sql")
+	}
+
+	return nil
+}
+
+func (r *orderRepository) markOutboxRetrySQL(ctx context.Context, id string, claimToken string, status entity.OutboxStatus, lastErr string, backoff time.Duration) error {
+	query, ok := r.queryLoader.Get("MarkOutboxRetry")
+	if !ok {
+		zerolog.Ctx(ctx).Error().Str("query", "MarkOutboxRetry").Msg("query_not_found")
+		return x.NewWithCode(x.CodeSQLQueryBuild, "query_MarkOutboxRetry_not_found")
+	}
+	_, err := r.db0.ExecContext(ctx, query, status, lastErr, int64(backoff.Seconds()), id, claimToken)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("outboxID", id).Msg("mark_outbox_retry_sql")
+		return x.WrapWithCode(err, x.CodeSQLUpdate, "mark_outbox_retry_sql")
+	}
+
+	return nil
+}
+
+func (r *orderRepository) cancelOrderPaymentSQL(ctx context.Context, tx *sqlx.Tx, orderID string) (*sqlx.Tx, error) {
+	query, ok := r.queryLoader.Get("CancelOrderPayment")
+	if !ok {
+		zerolog.Ctx(ctx).Error().Str("query", "CancelOrderPayment").Msg("query_not_found")
+		return tx, x.NewWithCode(x.CodeSQLQueryBuild, "query_CancelOrderPayment_not_found")
+	}
+	_, err := tx.ExecContext(ctx, query, orderID)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("orderID", orderID).Msg("cancel_order_payment_sql")
+		return tx, x.WrapWithCode(err, x.CodeSQLUpdate, "cancel_order_payment_sql")
+	}
+
+	return tx, nil
+}
+
+func (r *orderRepository) createSagaSQL(ctx context.Context, saga *entity.OrderSaga) error {
+	query, ok := r.queryLoader.Get("CreateSaga")
+	if !ok {
+		zerolog.Ctx(ctx).Error().Str("query", "CreateSaga").Msg("query_not_found")
+		return x.NewWithCode(x.CodeSQLQueryBuild, "query_CreateSaga_not_found")
+	}
+
+	items, err := json.Marshal(saga.Items)
+	if err != nil {
+		return x.Wrap(err, "marshal_saga_items")
+	}
+
+	_, err = r.db0.ExecContext(ctx, query, saga.ID, saga.ReservationID, saga.UserID, saga.Status, items)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("sagaID", saga.ID).Msg("create_saga_sql")
+		return x.WrapWithCode(err, x.CodeSQLCreate, "create_saga_sql")
+	}
+
+	return nil
+}
+
+// updateSagaStatusSQL moves a saga from one status to the next. The update is
+// guarded by the expected current status, so two actors racing on the same
+// saga cannot both advance it. Runs on tx when given, otherwise on the pool.
+func (r *orderRepository) updateSagaStatusSQL(ctx context.Context, tx *sqlx.Tx, sagaID string, from entity.SagaStatus, to entity.SagaStatus, orderID *string, lastErr *string) (*sqlx.Tx, error) {
+	query, ok := r.queryLoader.Get("UpdateSagaStatus")
+	if !ok {
+		zerolog.Ctx(ctx).Error().Str("query", "UpdateSagaStatus").Msg("query_not_found")
+		return tx, x.NewWithCode(x.CodeSQLQueryBuild, "query_UpdateSagaStatus_not_found")
+	}
+
+	var (
+		result sql.Result
+		err    error
+	)
+	if tx != nil {
+		result, err = tx.ExecContext(ctx, query, to, orderID, lastErr, sagaID, from)
+	} else {
+		result, err = r.db0.ExecContext(ctx, query, to, orderID, lastErr, sagaID, from)
+	}
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("sagaID", sagaID).Str("to", string(to)).Msg("update_saga_status_sql")
+		return tx, x.WrapWithCode(err, x.CodeSQLUpdate, "update_saga_status_sql")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("sagaID", sagaID).Msg("update_saga_status_sql")
+		return tx, x.WrapWithCode(err, x.CodeSQLCannotRetrieveAffectedRows, "update_saga_status_sql")
+	}
+	if rowsAffected == 0 {
+		zerolog.Ctx(ctx).Error().Str("sagaID", sagaID).Str("from", string(from)).Str("to", string(to)).Msg("update_saga_status_conflict")
+		return tx, x.NewWithCode(x.CodeSQLConflict, "update_saga_status_conflict")
+	}
+
+	return tx, nil
+}
+
+func (r *orderRepository) updateSagaReservationSQL(ctx context.Context, sagaID string, status entity.SagaStatus, reservationID string) error {
+	query, ok := r.queryLoader.Get("UpdateSagaReservation")
+	if !ok {
+		zerolog.Ctx(ctx).Error().Str("query", "UpdateSagaReservation").Msg("query_not_found")
+		return x.NewWithCode(x.CodeSQLQueryBuild, "query_UpdateSagaReservation_not_found")
+	}
+
+	result, err := r.db0.ExecContext(ctx, query, reservationID, sagaID, status)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("sagaID", sagaID).Msg("update_saga_reservation_sql")
+		return x.WrapWithCode(err, x.CodeSQLUpdate, "update_saga_reservation_sql")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("sagaID", sagaID).Msg("update_saga_reservation_sql")
+		return x.WrapWithCode(err, x.CodeSQLCannotRetrieveAffectedRows, "update_saga_reservation_sql")
+	}
+	if rowsAffected == 0 {
+		zerolog.Ctx(ctx).Error().Str("sagaID", sagaID).Str("status", string(status)).Msg("update_saga_reservation_conflict")
+		return x.NewWithCode(x.CodeSQLConflict, "update_saga_reservation_conflict")
+	}
+
+	return nil
+}
+
+func (r *orderRepository) getSagaByOrderIDSQL(ctx context.Context, orderID string) (*entity.OrderSaga, error) {
+	query, ok := r.queryLoader.Get("GetSagaByOrderID")
+	if !ok {
+		zerolog.Ctx(ctx).Error().Str("query", "GetSagaByOrderID").Msg("query_not_found")
+		return nil, x.NewWithCode(x.CodeSQLQueryBuild, "query_GetSagaByOrderID_not_found")
+	}
+
+	saga, err := scanSaga(r.db0.QueryRowxContext(ctx, query, orderID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, x.WrapWithCode(err, x.CodeSQLRecordDoesNotExist, "get_saga_by_order_id_sql")
+		}
+
+		zerolog.Ctx(ctx).Error().Err(err).Msg("get_saga_by_order_id_sql")
+		return nil, x.WrapWithCode(err, x.CodeSQLRowScan, "get_saga_by_order_id_sql")
+	}
+
+	return saga, nil
+}
+
+func (r *orderRepository) getStaleSagasSQL(ctx context.Context, staleAfter time.Duration, limit int) ([]*entity.OrderSaga, error) {
+	sagas := make([]*entity.OrderSaga, 0)
+
+	query, ok := r.queryLoader.Get("GetStaleSagas")
+	if !ok {
+		zerolog.Ctx(ctx).Error().Str("query", "GetStaleSagas").Msg("query_not_found")
+		return sagas, x.NewWithCode(x.CodeSQLQueryBuild, "query_GetStaleSagas_not_found")
+	}
+	rows, err := r.db0.QueryxContext(ctx, query, int64(staleAfter.Seconds()), limit)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("get_stale_sagas_sql")
+		return sagas, x.WrapWithCode(err, x.CodeSQLRead, "get_stale_sagas_sql")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		saga, err := scanSaga(rows)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("get_stale_sagas_sql_row_scan")
+			return sagas, x.WrapWithCode(err, x.CodeSQLRowScan, "get_stale_sagas_sql_row_scan")
+		}
+
+		sagas = append(sagas, saga)
+	}
+
+	if err = rows.Err(); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("get_stale_sagas_sql_rows")
+		return sagas, x.WrapWithCode(err, x.CodeSQLRead, "get_stale_sagas_sql_rows")
+	}
+
+	return sagas, nil
+}
+
+func (r *orderRepository) claimSagaSQL(ctx context.Context, sagaID string, status entity.SagaStatus, staleAfter time.Duration) (bool, error) {
+	query, ok := r.queryLoader.Get("ClaimSaga")
+	if !ok {
+		zerolog.Ctx(ctx).Error().Str("query", "ClaimSaga").Msg("query_not_found")
+		return false, x.NewWithCode(x.CodeSQLQueryBuild, "query_ClaimSaga_not_found")
+	}
+	result, err := r.db0.ExecContext(ctx, query, sagaID, status, int64(staleAfter.Seconds()))
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("sagaID", sagaID).Msg("claim_saga_sql")
+		return false, x.WrapWithCode(err, x.CodeSQLUpdate, "claim_saga_sql")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("sagaID", sagaID).Msg("claim_saga_sql")
+		return false, x.WrapWithCode(err, x.CodeSQLCannotRetrieveAffectedRows, "claim_saga_sql")
+	}
+
+	return rowsAffected == 1, nil
+}
+
+func scanSaga(row interface{ Scan(dest ...any) error }) (*entity.OrderSaga, error) {
+	var (
+		saga  entity.OrderSaga
+		items []byte
+	)
+
+	if err := row.Scan(&saga.ID, &saga.OrderID, &saga.ReservationID, &saga.UserID, &saga.Status, &items, &saga.Attempts, &saga.LastError); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(items, &saga.Items); err != nil {
+		return nil, err
+	}
+
+	return &saga, nil
+}
+
+func (r *orderRepository) getPaymentSQL(ctx context.Context, queryName string, arg string) (*entity.Payment, error) {
+	var payment entity.Payment
+
+	query, ok := r.queryLoader.Get(queryName)
+	if !ok {
+		zerolog.Ctx(ctx).Error().Str("query", queryName).Msg("query_not_found")
+		return nil, x.NewWithCode(x.CodeSQLQueryBuild, "query_"+queryName+"_not_found")
+	}
+	err := r.db0.QueryRowxContext(ctx, query, arg).StructScan(&payment)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("query", queryName).Msg("get_payment_sql")
+
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, x.WrapWithCode(err, x.CodeSQLRecordDoesNotExist, "get_payment_sql")
+		}
+
+		return nil, x.WrapWithCode(err, x.CodeSQLRowScan, "get_payment_sql")
+	}
+
+	return &payment, nil
+}
+
+func (r *orderRepository) updatePaymentStatusSQL(ctx context.Context, tx *sqlx.Tx, change *entity.PaymentStatusChange) (*sqlx.Tx, error) {
+	query, ok := r.queryLoader.Get("UpdatePaymentStatus")
+	if !ok {
+		zerolog.Ctx(ctx).Error().Str("query", "UpdatePaymentStatus").Msg("query_not_found")
+		return tx, x.NewWithCode(x.CodeSQLQueryBuild, "query_UpdatePaymentStatus_not_found")
+	}
+	result, err := tx.ExecContext(ctx, query, change.To, change.TransactionID, change.PaymentID, change.From)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("paymentID", change.PaymentID).Msg("update_payment_status_sql")
+		return tx, x.WrapWithCode(err, x.CodeSQLUpdate, "update_payment_status_sql")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("paymentID", change.PaymentID).Msg("update_payment_status_sql")
+		return tx, x.WrapWithCode(err, x.CodeSQLCannotRetrieveAffectedRows, "update_payment_status_sql")
+	}
+
+	// The payment left `from` since it was read, e.g. a callback raced a
+	// cancellation
+	if rowsAffected == 0 {
+		zerolog.Ctx(ctx).Warn().Str("paymentID", change.PaymentID).Str("from", string(change.From)).Str("to", string(change.To)).Msg("update_payment_status_sql")
+		return tx, x.NewWithCode(x.CodeSQLConflict, "Payment status has changed")
+	}
+
 	return tx, nil
 }
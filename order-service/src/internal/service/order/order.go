@@ -2,45 +2,89 @@ package order
 
 import (
 	"context"
+	"net/http"
+	"time"
 
+	authpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/auth"
 	productpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/product"
 	userpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/user"
 	"github.com/linggaaskaedo/go-kill/order-service/src/internal/model/dto"
 	"github.com/linggaaskaedo/go-kill/order-service/src/internal/model/entity"
+	provider "github.com/linggaaskaedo/go-kill/order-service/src/internal/provider/payment"
 	"github.com/linggaaskaedo/go-kill/order-service/src/internal/repository/order"
 
 	"google.golang.org/grpc"
 )
 
 type OrderServiceItf interface {
+	// gRPC
 	CreateOrder(ctx context.Context, reqData *dto.CreateOrderRequest) (*string, *string, float64, error)
 	GetOrder(ctx context.Context, reqData *dto.GetOrderRequest) (*entity.Order, error)
 	ListOrders(ctx context.Context, reqData *dto.ListOrderRequest) ([]*entity.Order, int32, error)
 	CancelOrder(ctx context.Context, reqData *dto.CancelOrderRequest) error
+	UpdateOrderStatus(ctx context.Context, reqData *dto.UpdateOrderStatusRequest) (entity.OrderStatus, error)
+
+	// REST
+	ValidateToken(ctx context.Context, req *authpb.ValidateTokenRequest) (*authpb.ValidateTokenResponse, error)
+	ValidateApiKey(ctx context.Context, req *authpb.ValidateApiKeyRequest) (*authpb.ValidateTokenResponse, error)
+	CreateUserOrder(ctx context.Context, userAuthID string, req dto.CreateUserOrderRequest) (*dto.CreateOrderResp, error)
+	GetUserOrder(ctx context.Context, userAuthID string, orderID string) (*entity.Order, error)
+	ListUserOrders(ctx context.Context, userAuthID string, page string, limit string) ([]*entity.Order, *dto.Pagination, error)
+	CancelUserOrder(ctx context.Context, userAuthID string, orderID string, req dto.CancelUserOrderRequest) error
+	GetAnyOrder(ctx context.Context, orderID string) (*entity.Order, error)
+	HandlePaymentCallback(ctx context.Context, header http.Header, body []byte) error
+
+	// Outbox
+	RelayOutbox(ctx context.Context, batchSize int) (int, error)
+
+	// Saga
+	RecoverSagas(ctx context.Context, batchSize int) (int, error)
 }
 
 type KafkaProducer interface {
-	SendMessage(topic string, key, value []byte) (partition int32, offset int64, err error)
+	SendMessage(ctx context.Context, topic string, key, value []byte) (partition int32, offset int64, err error)
 }
 
 type orderService struct {
 	orderRepository order.OrderRepositoryItf
+	authClient      authpb.AuthServiceClient
 	userClient      userpb.UserServiceClient
 	productClient   productpb.ProductServiceClient
 	kafkaProducer   KafkaProducer
+	paymentProvider provider.PaymentProvider
 	orderOptions    Options
 }
 
 type Options struct {
-	TopicOrderCreated  string `yaml:"topic_order_created"`
-	TopicOrderCanceled string `yaml:"topic_order_canceled"`
+	TopicOrderCreated     string        `yaml:"topic_order_created"`
+	TopicOrderUpdated     string        `yaml:"topic_order_updated"`
+	TopicOrderCanceled    string        `yaml:"topic_order_canceled"`
+	TopicPaymentCompleted string        `yaml:"topic_payment_completed"`
+	TopicPaymentFailed    string        `yaml:"topic_payment_failed"`
+	Outbox                OutboxOptions `yaml:"outbox"`
+	Saga                  SagaOptions   `yaml:"saga"`
+}
+
+type OutboxOptions struct {
+	MaxAttempts int           `yaml:"max_attempts"`
+	BaseBackoff time.Duration `yaml:"base_backoff"`
+	MaxBackoff  time.Duration `yaml:"max_backoff"`
+	ClaimLease  time.Duration `yaml:"claim_lease"`
+}
+
+type SagaOptions struct {
+	StaleAfter  time.Duration `yaml:"stale_after"`
+	MaxAttempts int           `yaml:"max_attempts"`
 }
 
-func InitOrderService(orderRepository order.OrderRepositoryItf, userClientConn *grpc.ClientConn, productClientConn *grpc.ClientConn, kafkaProducer KafkaProducer) OrderServiceItf {
+func InitOrderService(orderRepository order.OrderRepositoryItf, authClientConn *grpc.ClientConn, userClientConn *grpc.ClientConn, productClientConn *grpc.ClientConn, kafkaProducer KafkaProducer, paymentProvider provider.PaymentProvider, orderOptions Options) OrderServiceItf {
 	return &orderService{
 		orderRepository: orderRepository,
+		authClient:      authpb.NewAuthServiceClient(authClientConn),
 		userClient:      userpb.NewUserServiceClient(userClientConn),
 		productClient:   productpb.NewProductServiceClient(productClientConn),
 		kafkaProducer:   kafkaProducer,
+		paymentProvider: paymentProvider,
+		orderOptions:    orderOptions,
 	}
 }
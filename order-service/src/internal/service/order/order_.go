@@ -2,7 +2,6 @@ package order
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"time"
 
@@ -11,6 +10,7 @@ import (
 	userpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/user"
 	"github.com/linggaaskaedo/go-kill/order-service/src/internal/model/dto"
 	"github.com/linggaaskaedo/go-kill/order-service/src/internal/model/entity"
+	"github.com/linggaaskaedo/go-kill/order-service/src/internal/util"
 
 	"github.com/openpcc/openpcc/uuidv7"
 	"github.com/rs/zerolog"
@@ -49,15 +49,26 @@ func (s *orderService) CreateOrder(ctx context.Context, reqData *dto.CreateOrder
 		return nil, nil, 0, err
 	}
 
-	// Step 4: Create order in MySQL
-	orderID, orderNumber, err := s.orderRepository.StoreOrder(ctx, productDetails, reqData, totalAmount)
+	// Step 4: Reserve inventory, create order, record payment and commit stock as a saga
+	orderID, orderNumber, err := s.runOrderSaga(ctx, reqData, userResp, productDetails, totalAmount)
 	if err != nil {
 		return nil, nil, 0, err
 	}
 
-	// Step 5: Publish event to Kafka (non‑critical, errors are logged)
-	if err := s.publishOrderEvent(ctx, orderID, orderNumber, reqData, userResp, productDetails, totalAmount); err != nil {
-		return nil, nil, 0, err
+	// Step 5: Authorize payment, which confirms the order. If the provider
+	// cannot be reached the order stays pending; confirming it through
+	// UpdateOrderStatus retries the authorization.
+	paymentStatus, err := s.authorizePayment(ctx, &entity.Order{
+		ID:          *orderID,
+		UserID:      reqData.UserID,
+		OrderNumber: *orderNumber,
+		Status:      entity.StatusPending,
+		TotalAmount: totalAmount,
+	}, entity.ActorSystem, "Payment authorized")
+	if err != nil {
+		zerolog.Ctx(ctx).Warn().Err(err).Str("orderID", *orderID).Msg("authorize_payment_deferred")
+	} else if paymentStatus == entity.PaymentFailed {
+		return nil, nil, 0, authorizationError(paymentStatus)
 	}
 
 	return orderID, orderNumber, totalAmount, nil
@@ -122,14 +133,13 @@ func (s *orderService) fetchAndCheckProduct(ctx context.Context, item *dto.Order
 	}, nil
 }
 
-func (s *orderService) publishOrderEvent(
-	ctx context.Context,
-	orderID, orderNumber *string,
+func (s *orderService) newOrderCreatedEvent(
+	orderID, orderNumber string,
 	reqData *dto.CreateOrderRequest,
 	userResp *userpb.GetUserResponse,
 	productDetails []*dto.ProductDetails,
 	totalAmount float64,
-) error {
+) (*entity.OutboxMessage, error) {
 	orderItems := make([]dto.OrderItem, len(reqData.Items))
 
 	for i, item := range reqData.Items {
@@ -148,8 +158,8 @@ func (s *orderService) publishOrderEvent(
 		Timestamp: time.Now(),
 		Source:    "order-service",
 		Data: dto.OrderData{
-			OrderID:     *orderID,
-			OrderNumber: *orderNumber,
+			OrderID:     orderID,
+			OrderNumber: orderNumber,
 			UserID:      reqData.UserID,
 			UserEmail:   userResp.Email,
 			TotalAmount: totalAmount,
@@ -158,19 +168,7 @@ func (s *orderService) publishOrderEvent(
 		},
 	}
 
-	eventBytes, err := json.Marshal(event)
-	if err != nil {
-		return x.New("Failed to marshal event order creation", err)
-	}
-
-	partition, offset, err := s.kafkaProducer.SendMessage(s.orderOptions.TopicOrderCreated, []byte(event.Data.UserID), eventBytes)
-	if err != nil {
-		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to send Kafka message")
-	} else {
-		zerolog.Ctx(ctx).Debug().Int32("partition", partition).Int64("offset", offset).Msg("Kafka message sent")
-	}
-
-	return nil
+	return newOutboxMessage(s.orderOptions.TopicOrderCreated, event)
 }
 
 func (s *orderService) GetOrder(ctx context.Context, reqData *dto.GetOrderRequest) (*entity.Order, error) {
@@ -182,35 +180,40 @@ func (s *orderService) ListOrders(ctx context.Context, reqData *dto.ListOrderReq
 }
 
 func (s *orderService) CancelOrder(ctx context.Context, reqData *dto.CancelOrderRequest) error {
-	err := s.orderRepository.CancelOrder(ctx, reqData)
+	// Scoped to the user so nobody can cancel someone else's order
+	current, err := s.orderRepository.GetOrder(ctx, &dto.GetOrderRequest{OrderID: reqData.OrderID, UserID: reqData.UserID})
 	if err != nil {
 		return err
 	}
 
-	// Publish cancellation event
-	event := dto.OrderEvent{
-		EventID:   uuidv7.MustNew().String(),
-		EventType: "order.cancelled",
-		Version:   "1.0",
-		Timestamp: time.Now(),
-		Source:    "order-service",
-		Data: dto.OrderData{
-			OrderID: reqData.OrderID,
-			UserID:  reqData.UserID,
-			Status:  "cancelled",
-		},
+	current.UserID = reqData.UserID
+
+	return s.transitionOrder(ctx, current, entity.StatusCancelled, reqData.UserID, reqData.Reason)
+}
+
+// returnStock gives a cancelled order's items back. Orders whose saga
+// completed have had their stock committed, so it is restocked; orders that
+// predate the saga only ever held a reservation, which is released.
+func (s *orderService) returnStock(ctx context.Context, orderID string, items []*entity.OrderItem) error {
+	saga, err := s.orderRepository.GetSagaByOrderID(ctx, orderID)
+	if err != nil && x.ErrCode(err) != x.CodeSQLRecordDoesNotExist {
+		return err
 	}
 
-	eventBytes, err := json.Marshal(event)
-	if err != nil {
-		return x.New("Failed to marshal event order cancellation", err)
+	inventoryItems := util.ToInventoryItemPB(items)
+
+	switch {
+	case saga == nil:
+		_, err = s.productClient.ReleaseInventory(ctx, &productpb.ReleaseInventoryRequest{Items: inventoryItems})
+	case saga.Status == entity.SagaCompleted:
+		_, err = s.productClient.RestockInventory(ctx, &productpb.RestockInventoryRequest{Items: inventoryItems})
+	default:
+		return x.NewWithCode(x.CodeHTTPConflict, "Order is still being processed")
 	}
 
-	partition, offset, err := s.kafkaProducer.SendMessage(s.orderOptions.TopicOrderCanceled, []byte(event.Data.UserID), eventBytes)
 	if err != nil {
-		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to send Kafka message")
-	} else {
-		zerolog.Ctx(ctx).Debug().Int32("partition", partition).Int64("offset", offset).Msg("Kafka message sent")
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to return inventory")
+		return x.Wrap(err, "Failed to return inventory")
 	}
 
 	return nil
@@ -0,0 +1,99 @@
+package order
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+	"github.com/linggaaskaedo/go-kill/order-service/src/internal/model/dto"
+	"github.com/linggaaskaedo/go-kill/order-service/src/internal/model/entity"
+
+	"github.com/openpcc/openpcc/uuidv7"
+	"github.com/rs/zerolog"
+)
+
+func newOutboxMessage(topic string, event dto.OrderEvent) (*entity.OutboxMessage, error) {
+	return marshalOutboxMessage(topic, event.EventType, event.Data.OrderID, event.Data.UserID, event)
+}
+
+// newPaymentOutboxMessage files payment events under their order and keys
+// them by user like order events, so a user's events keep their order.
+func newPaymentOutboxMessage(topic string, event dto.PaymentEvent) (*entity.OutboxMessage, error) {
+	return marshalOutboxMessage(topic, event.EventType, event.Data.OrderID, event.Data.UserID, event)
+}
+
+func marshalOutboxMessage(topic, eventType, aggregateID, messageKey string, event any) (*entity.OutboxMessage, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, x.Wrap(err, "Failed to marshal event "+eventType)
+	}
+
+	return &entity.OutboxMessage{
+		AggregateID: aggregateID,
+		EventType:   eventType,
+		Topic:       topic,
+		MessageKey:  messageKey,
+		Payload:     payload,
+		Status:      entity.OutboxPending,
+	}, nil
+}
+
+// RelayOutbox publishes a batch of pending outbox messages to Kafka and returns
+// how many were sent. Delivery is at-least-once: a message that was published
+// but could not be marked sent is picked up again once its claim lease expires.
+func (s *orderService) RelayOutbox(ctx context.Context, batchSize int) (int, error) {
+	claimToken := uuidv7.MustNew().String()
+
+	messages, err := s.orderRepository.ClaimOutbox(ctx, claimToken, s.orderOptions.Outbox.ClaimLease, batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, msg := range messages {
+		partition, offset, err := s.kafkaProducer.SendMessage(ctx, msg.Topic, []byte(msg.MessageKey), msg.Payload)
+		if err != nil {
+			attempts := msg.Attempts + 1
+			status := entity.OutboxPending
+			if s.orderOptions.Outbox.MaxAttempts > 0 && attempts >= s.orderOptions.Outbox.MaxAttempts {
+				status = entity.OutboxFailed
+			}
+
+			zerolog.Ctx(ctx).Error().Err(err).Str("outboxID", msg.ID).Str("eventType", msg.EventType).Int("attempts", attempts).Str("status", string(status)).Msg("outbox_send_failed")
+
+			if markErr := s.orderRepository.MarkOutboxRetry(ctx, msg.ID, claimToken, status, err.Error(), s.outboxBackoff(attempts)); markErr != nil {
+				zerolog.Ctx(ctx).Error().Err(markErr).Str("outboxID", msg.ID).Msg("outbox_mark_retry_failed")
+			}
+
+			continue
+		}
+
+		zerolog.Ctx(ctx).Debug().Str("outboxID", msg.ID).Int32("partition", partition).Int64("offset", offset).Msg("Kafka message sent")
+
+		if err := s.orderRepository.MarkOutboxSent(ctx, msg.ID, claimToken); err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Str("outboxID", msg.ID).Msg("outbox_mark_sent_failed")
+			continue
+		}
+
+		sent++
+	}
+
+	return sent, nil
+}
+
+// outboxBackoff doubles the delay on every attempt, capped at MaxBackoff. The
+// outbox stores whole seconds, so the delay never drops below one second.
+func (s *orderService) outboxBackoff(attempts int) time.Duration {
+	opts := s.orderOptions.Outbox
+
+	backoff := max(opts.BaseBackoff, time.Second)
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if opts.MaxBackoff > 0 && backoff >= opts.MaxBackoff {
+			return opts.MaxBackoff
+		}
+	}
+
+	return backoff
+}
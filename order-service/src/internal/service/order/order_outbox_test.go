@@ -0,0 +1,203 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/linggaaskaedo/go-kill/order-service/src/internal/model/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockKafkaProducer struct {
+	mock.Mock
+}
+
+func (m *MockKafkaProducer) SendMessage(ctx context.Context, topic string, key, value []byte) (int32, int64, error) {
+	args := m.Called(ctx, topic, key, value)
+	return args.Get(0).(int32), args.Get(1).(int64), args.Error(2)
+}
+
+var _ KafkaProducer = (*MockKafkaProducer)(nil)
+
+var errBrokerDown = errors.New("kafka: broker not available")
+
+func newTestOutboxService(repo *MockOrderRepository, producer *MockKafkaProducer) *orderService {
+	return &orderService{
+		orderRepository: repo,
+		kafkaProducer:   producer,
+		orderOptions: Options{
+			Outbox: OutboxOptions{
+				MaxAttempts: 3,
+				BaseBackoff: 2 * time.Second,
+				MaxBackoff:  10 * time.Second,
+				ClaimLease:  30 * time.Second,
+			},
+		},
+	}
+}
+
+func newTestOutboxMessage(id string, attempts int) *entity.OutboxMessage {
+	return &entity.OutboxMessage{
+		ID:          id,
+		AggregateID: testOrderID,
+		EventType:   "order.created",
+		Topic:       "order.created",
+		MessageKey:  "user-1",
+		Payload:     []byte(`{"event_type":"order.created"}`),
+		Status:      entity.OutboxPending,
+		Attempts:    attempts,
+	}
+}
+
+// claimedWith captures the claim token so the marks can be checked against it.
+func claimedWith(repo *MockOrderRepository, messages ...*entity.OutboxMessage) *string {
+	var token string
+	repo.On("ClaimOutbox", mock.Anything, mock.AnythingOfType("string"), 30*time.Second, testBatchSize).
+		Run(func(args mock.Arguments) { token = args.String(1) }).
+		Return(messages, nil).Once()
+	return &token
+}
+
+func TestRelayOutboxMarksSentMessages(t *testing.T) {
+	repo, producer := new(MockOrderRepository), new(MockKafkaProducer)
+	svc := newTestOutboxService(repo, producer)
+
+	msg := newTestOutboxMessage("1", 0)
+	token := claimedWith(repo, msg)
+	producer.On("SendMessage", mock.Anything, msg.Topic, []byte(msg.MessageKey), msg.Payload).Return(int32(0), int64(7), nil).Once()
+	repo.On("MarkOutboxSent", mock.Anything, "1", mock.AnythingOfType("string")).Return(nil).Once()
+
+	sent, err := svc.RelayOutbox(context.Background(), testBatchSize)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	repo.AssertExpectations(t)
+	producer.AssertExpectations(t)
+
+	// The message is marked under the token it was claimed with
+	assert.NotEmpty(t, *token)
+	repo.AssertCalled(t, "MarkOutboxSent", mock.Anything, "1", *token)
+	repo.AssertNotCalled(t, "MarkOutboxRetry", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRelayOutboxUsesFreshClaimTokens(t *testing.T) {
+	repo, producer := new(MockOrderRepository), new(MockKafkaProducer)
+	svc := newTestOutboxService(repo, producer)
+
+	first := claimedWith(repo)
+	_, err := svc.RelayOutbox(context.Background(), testBatchSize)
+	require.NoError(t, err)
+
+	second := claimedWith(repo)
+	_, err = svc.RelayOutbox(context.Background(), testBatchSize)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, *first, *second)
+	producer.AssertNotCalled(t, "SendMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRelayOutboxSchedulesRetryOnSendFailure(t *testing.T) {
+	repo, producer := new(MockOrderRepository), new(MockKafkaProducer)
+	svc := newTestOutboxService(repo, producer)
+
+	failed, delivered := newTestOutboxMessage("1", 1), newTestOutboxMessage("2", 0)
+	claimedWith(repo, failed, delivered)
+	producer.On("SendMessage", mock.Anything, mock.Anything, mock.Anything, failed.Payload).Return(int32(0), int64(0), errBrokerDown).Once()
+	producer.On("SendMessage", mock.Anything, mock.Anything, mock.Anything, delivered.Payload).Return(int32(0), int64(8), nil).Once()
+
+	// Second attempt: still pending, backoff doubled from the base
+	repo.On("MarkOutboxRetry", mock.Anything, "1", mock.AnythingOfType("string"), entity.OutboxPending, errBrokerDown.Error(), 4*time.Second).Return(nil).Once()
+	repo.On("MarkOutboxSent", mock.Anything, "2", mock.AnythingOfType("string")).Return(nil).Once()
+
+	sent, err := svc.RelayOutbox(context.Background(), testBatchSize)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	repo.AssertExpectations(t)
+	producer.AssertExpectations(t)
+}
+
+func TestRelayOutboxGivesUpAfterMaxAttempts(t *testing.T) {
+	repo, producer := new(MockOrderRepository), new(MockKafkaProducer)
+	svc := newTestOutboxService(repo, producer)
+
+	msg := newTestOutboxMessage("1", 2)
+	claimedWith(repo, msg)
+	producer.On("SendMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(int32(0), int64(0), errBrokerDown).Once()
+	repo.On("MarkOutboxRetry", mock.Anything, "1", mock.AnythingOfType("string"), entity.OutboxFailed, errBrokerDown.Error(), 8*time.Second).Return(nil).Once()
+
+	sent, err := svc.RelayOutbox(context.Background(), testBatchSize)
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+	repo.AssertExpectations(t)
+}
+
+func TestRelayOutboxRetriesForeverWithoutMaxAttempts(t *testing.T) {
+	repo, producer := new(MockOrderRepository), new(MockKafkaProducer)
+	svc := newTestOutboxService(repo, producer)
+	svc.orderOptions.Outbox.MaxAttempts = 0
+
+	msg := newTestOutboxMessage("1", 50)
+	claimedWith(repo, msg)
+	producer.On("SendMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(int32(0), int64(0), errBrokerDown).Once()
+	repo.On("MarkOutboxRetry", mock.Anything, "1", mock.AnythingOfType("string"), entity.OutboxPending, errBrokerDown.Error(), 10*time.Second).Return(nil).Once()
+
+	_, err := svc.RelayOutbox(context.Background(), testBatchSize)
+	require.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestRelayOutboxDoesNotCountUnmarkedMessages(t *testing.T) {
+	repo, producer := new(MockOrderRepository), new(MockKafkaProducer)
+	svc := newTestOutboxService(repo, producer)
+
+	msg := newTestOutboxMessage("1", 0)
+	claimedWith(repo, msg)
+	producer.On("SendMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(int32(0), int64(7), nil).Once()
+
+	// Published but not marked: the lease runs out and it is sent again
+	repo.On("MarkOutboxSent", mock.Anything, "1", mock.AnythingOfType("string")).Return(errors.New("connection reset")).Once()
+
+	sent, err := svc.RelayOutbox(context.Background(), testBatchSize)
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+	repo.AssertNotCalled(t, "MarkOutboxRetry", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRelayOutboxReturnsClaimError(t *testing.T) {
+	repo, producer := new(MockOrderRepository), new(MockKafkaProducer)
+	svc := newTestOutboxService(repo, producer)
+
+	repo.On("ClaimOutbox", mock.Anything, mock.Anything, mock.Anything, testBatchSize).Return(nil, errors.New("connection refused")).Once()
+
+	sent, err := svc.RelayOutbox(context.Background(), testBatchSize)
+	require.Error(t, err)
+	assert.Equal(t, 0, sent)
+	producer.AssertNotCalled(t, "SendMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     OutboxOptions
+		attempts int
+		want     time.Duration
+	}{
+		{name: "first retry waits the base", opts: OutboxOptions{BaseBackoff: 2 * time.Second, MaxBackoff: time.Minute}, attempts: 1, want: 2 * time.Second},
+		{name: "doubles per attempt", opts: OutboxOptions{BaseBackoff: 2 * time.Second, MaxBackoff: time.Minute}, attempts: 4, want: 16 * time.Second},
+		{name: "capped at max", opts: OutboxOptions{BaseBackoff: 2 * time.Second, MaxBackoff: time.Minute}, attempts: 10, want: time.Minute},
+		{name: "uncapped without max", opts: OutboxOptions{BaseBackoff: time.Second}, attempts: 8, want: 128 * time.Second},
+		{name: "never below a second", opts: OutboxOptions{BaseBackoff: 100 * time.Millisecond}, attempts: 1, want: time.Second},
+		{name: "zero base", opts: OutboxOptions{}, attempts: 2, want: 2 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &orderService{orderOptions: Options{Outbox: tt.opts}}
+			assert.Equal(t, tt.want, svc.outboxBackoff(tt.attempts))
+		})
+	}
+}
@@ -0,0 +1,313 @@
+package order
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+	"github.com/linggaaskaedo/go-kill/order-service/src/internal/model/dto"
+	"github.com/linggaaskaedo/go-kill/order-service/src/internal/model/entity"
+	provider "github.com/linggaaskaedo/go-kill/order-service/src/internal/provider/payment"
+
+	"github.com/openpcc/openpcc/uuidv7"
+	"github.com/rs/zerolog"
+)
+
+// authorizePayment asks the provider to authorize the order's pending
+// payment and returns the payment's resulting status. Authorization confirms
+// the order and a decline cancels it; an authorization the provider settles
+// later leaves the order pending until its callback arrives.
+func (s *orderService) authorizePayment(ctx context.Context, current *entity.Order, actor, note string) (entity.PaymentStatus, error) {
+	payment, err := s.orderRepository.GetPaymentByOrderID(ctx, current.ID)
+	if err != nil {
+		return "", err
+	}
+
+	switch payment.Status {
+	case entity.PaymentPending:
+	case entity.PaymentAuthorized:
+		// Authorized earlier, but confirming the order failed
+		return payment.Status, s.applyTransition(ctx, current, entity.StatusConfirmed, actor, note, nil, nil)
+	default:
+		return "", x.NewWithCode(x.CodeHTTPConflict, "Payment is already "+string(payment.Status))
+	}
+
+	result, err := s.paymentProvider.Authorize(ctx, provider.AuthorizeRequest{
+		PaymentID: payment.ID,
+		OrderID:   current.ID,
+		Method:    payment.PaymentMethod,
+		Amount:    payment.Amount,
+	})
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("paymentID", payment.ID).Msg("authorize_payment")
+		return "", x.Wrap(err, "Failed to authorize payment")
+	}
+
+	return s.settleAuthorization(ctx, current, payment, result.Status, result.TransactionID, result.Reason, actor, note)
+}
+
+// settleAuthorization applies an authorization outcome, whether it came back
+// from the provider call or later through a callback.
+func (s *orderService) settleAuthorization(
+	ctx context.Context,
+	current *entity.Order,
+	payment *entity.Payment,
+	status provider.Status,
+	transactionID, reason, actor, note string,
+) (entity.PaymentStatus, error) {
+	change := &entity.PaymentStatusChange{
+		PaymentID:     payment.ID,
+		From:          entity.PaymentPending,
+		TransactionID: optionalString(transactionID),
+	}
+
+	switch status {
+	case provider.StatusAuthorized:
+		change.To = entity.PaymentAuthorized
+		return change.To, s.applyTransition(ctx, current, entity.StatusConfirmed, actor, note, change, nil)
+	case provider.StatusDeclined:
+		change.To = entity.PaymentFailed
+
+		failed, err := s.newPaymentEvent(s.orderOptions.TopicPaymentFailed, "payment.failed", current, payment, change, reason)
+		if err != nil {
+			return "", err
+		}
+
+		return change.To, s.applyTransition(ctx, current, entity.StatusCancelled, entity.ActorSystem, "Payment declined", change, []*entity.OutboxMessage{failed})
+	case provider.StatusPending:
+		change.To = entity.PaymentPending
+		return change.To, s.orderRepository.UpdatePaymentStatus(ctx, change, nil)
+	default:
+		return "", x.New("Unexpected authorization status %s", status)
+	}
+}
+
+// authorizationError reports an authorization that did not confirm the order
+// to whoever asked for the confirmation.
+func authorizationError(status entity.PaymentStatus) error {
+	switch status {
+	case entity.PaymentFailed:
+		return x.NewWithCode(x.CodeHTTPUnprocessableEntity, "Payment was declined")
+	case entity.PaymentPending:
+		return x.NewWithCode(x.CodeHTTPConflict, "Payment authorization is pending")
+	default:
+		return nil
+	}
+}
+
+// capturePayment collects the authorized payment of an order about to ship.
+// A declined capture is recorded and blocks the shipment.
+func (s *orderService) capturePayment(ctx context.Context, current *entity.Order) (*entity.PaymentStatusChange, []*entity.OutboxMessage, error) {
+	payment, err := s.orderRepository.GetPaymentByOrderID(ctx, current.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch {
+	case payment.Status == entity.PaymentCompleted:
+		return nil, nil, nil
+	case payment.Status != entity.PaymentAuthorized || payment.TransactionID == nil:
+		return nil, nil, x.NewWithCode(x.CodeHTTPConflict, "Payment is "+string(payment.Status)+", not authorized")
+	}
+
+	result, err := s.paymentProvider.Capture(ctx, *payment.TransactionID, payment.Amount)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("paymentID", payment.ID).Msg("capture_payment")
+		return nil, nil, x.Wrap(err, "Failed to capture payment")
+	}
+
+	change := &entity.PaymentStatusChange{
+		PaymentID: payment.ID,
+		From:      entity.PaymentAuthorized,
+	}
+
+	switch result.Status {
+	case provider.StatusCaptured:
+		change.To = entity.PaymentCompleted
+
+		completed, err := s.newPaymentEvent(s.orderOptions.TopicPaymentCompleted, "payment.completed", current, payment, change, "")
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return change, []*entity.OutboxMessage{completed}, nil
+	case provider.StatusPending:
+		// The callback completes the payment; the order ships meanwhile
+		return nil, nil, nil
+	case provider.StatusDeclined:
+		change.To = entity.PaymentFailed
+
+		failed, err := s.newPaymentEvent(s.orderOptions.TopicPaymentFailed, "payment.failed", current, payment, change, result.Reason)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if err := s.orderRepository.UpdatePaymentStatus(ctx, change, []*entity.OutboxMessage{failed}); err != nil {
+			return nil, nil, err
+		}
+
+		return nil, nil, x.NewWithCode(x.CodeHTTPUnprocessableEntity, "Payment capture was declined")
+	default:
+		return nil, nil, x.New("Unexpected capture status %s", result.Status)
+	}
+}
+
+// refundPayment returns the money of an order being cancelled. A payment the
+// provider never authorized is failed instead.
+func (s *orderService) refundPayment(ctx context.Context, current *entity.Order) (*entity.PaymentStatusChange, error) {
+	payment, err := s.orderRepository.GetPaymentByOrderID(ctx, current.ID)
+	if err != nil {
+		if x.ErrCode(err) == x.CodeSQLRecordDoesNotExist {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	change := &entity.PaymentStatusChange{
+		PaymentID: payment.ID,
+		From:      payment.Status,
+	}
+
+	switch {
+	case payment.Status == entity.PaymentPending:
+		change.To = entity.PaymentFailed
+		return change, nil
+	case payment.Status != entity.PaymentAuthorized && payment.Status != entity.PaymentCompleted:
+		return nil, nil
+	case payment.TransactionID == nil:
+		return nil, x.New("Payment %s has no transaction id", payment.ID)
+	}
+
+	result, err := s.paymentProvider.Refund(ctx, *payment.TransactionID, payment.Amount)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("paymentID", payment.ID).Msg("refund_payment")
+		return nil, x.Wrap(err, "Failed to refund payment")
+	}
+
+	switch result.Status {
+	case provider.StatusRefunded:
+		change.To = entity.PaymentRefunded
+		return change, nil
+	case provider.StatusPending:
+		// The callback marks the payment refunded
+		return nil, nil
+	default:
+		return nil, x.NewWithCode(x.CodeHTTPUnprocessableEntity, "Payment refund was declined")
+	}
+}
+
+// HandlePaymentCallback applies an outcome the provider settled
+// asynchronously. Outcomes for payments that have already moved past the
+// state they settle are ignored, so redelivered callbacks are harmless.
+func (s *orderService) HandlePaymentCallback(ctx context.Context, header http.Header, body []byte) error {
+	cb, err := s.paymentProvider.ParseCallback(header, body)
+	if err != nil {
+		zerolog.Ctx(ctx).Warn().Err(err).Str("provider", s.paymentProvider.Name()).Msg("payment_callback_rejected")
+		return err
+	}
+
+	payment, err := s.orderRepository.GetPayment(ctx, cb.PaymentID)
+	if err != nil {
+		return err
+	}
+
+	current, err := s.orderRepository.GetOrderByID(ctx, payment.OrderID)
+	if err != nil {
+		return err
+	}
+
+	log := zerolog.Ctx(ctx).With().Str("paymentID", payment.ID).Str("paymentStatus", string(payment.Status)).Str("callbackStatus", string(cb.Status)).Logger()
+
+	change := &entity.PaymentStatusChange{
+		PaymentID:     payment.ID,
+		From:          payment.Status,
+		TransactionID: optionalString(cb.TransactionID),
+	}
+
+	switch {
+	case payment.Status == entity.PaymentPending && current.Status == entity.StatusPending &&
+		(cb.Status == provider.StatusAuthorized || cb.Status == provider.StatusDeclined):
+		_, err := s.settleAuthorization(ctx, current, payment, cb.Status, cb.TransactionID, cb.Reason, entity.ActorSystem, "Payment authorized")
+		return err
+	case payment.Status == entity.PaymentFailed && cb.Status == provider.StatusAuthorized:
+		// The order was cancelled while the authorization was pending, so
+		// the hold is released straight away
+		if _, err := s.paymentProvider.Refund(ctx, cb.TransactionID, payment.Amount); err != nil {
+			log.Error().Err(err).Msg("payment_callback_release_failed")
+			return x.Wrap(err, "Failed to release payment authorization")
+		}
+
+		return nil
+	case payment.Status == entity.PaymentAuthorized && cb.Status == provider.StatusCaptured:
+		change.To = entity.PaymentCompleted
+
+		completed, err := s.newPaymentEvent(s.orderOptions.TopicPaymentCompleted, "payment.completed", current, payment, change, "")
+		if err != nil {
+			return err
+		}
+
+		return s.orderRepository.UpdatePaymentStatus(ctx, change, []*entity.OutboxMessage{completed})
+	case payment.Status == entity.PaymentAuthorized && cb.Status == provider.StatusDeclined:
+		change.To = entity.PaymentFailed
+
+		failed, err := s.newPaymentEvent(s.orderOptions.TopicPaymentFailed, "payment.failed", current, payment, change, cb.Reason)
+		if err != nil {
+			return err
+		}
+
+		return s.orderRepository.UpdatePaymentStatus(ctx, change, []*entity.OutboxMessage{failed})
+	case (payment.Status == entity.PaymentAuthorized || payment.Status == entity.PaymentCompleted) && cb.Status == provider.StatusRefunded:
+		change.To = entity.PaymentRefunded
+		return s.orderRepository.UpdatePaymentStatus(ctx, change, nil)
+	default:
+		log.Info().Msg("payment_callback_ignored")
+		return nil
+	}
+}
+
+func (s *orderService) newPaymentEvent(
+	topic, eventType string,
+	current *entity.Order,
+	payment *entity.Payment,
+	change *entity.PaymentStatusChange,
+	reason string,
+) (*entity.OutboxMessage, error) {
+	transactionID := change.TransactionID
+	if transactionID == nil {
+		transactionID = payment.TransactionID
+	}
+
+	data := dto.PaymentData{
+		PaymentID:     payment.ID,
+		OrderID:       current.ID,
+		OrderNumber:   current.OrderNumber,
+		UserID:        current.UserID,
+		Amount:        payment.Amount,
+		PaymentMethod: payment.PaymentMethod,
+		Status:        string(change.To),
+		Reason:        reason,
+	}
+	if transactionID != nil {
+		data.TransactionID = *transactionID
+	}
+
+	return newPaymentOutboxMessage(topic, dto.PaymentEvent{
+		EventID:   uuidv7.MustNew().String(),
+		EventType: eventType,
+		Version:   "1.0",
+		Timestamp: time.Now(),
+		Source:    "order-service",
+		Data:      data,
+	})
+}
+
+// optionalString keeps an empty transaction id from overwriting a stored one.
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+
+	return &s
+}
@@ -0,0 +1,354 @@
+package order
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+	productpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/product"
+	userpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/user"
+	"github.com/linggaaskaedo/go-kill/order-service/src/internal/model/dto"
+	"github.com/linggaaskaedo/go-kill/order-service/src/internal/model/entity"
+	provider "github.com/linggaaskaedo/go-kill/order-service/src/internal/provider/payment"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+type MockPaymentProvider struct {
+	mock.Mock
+}
+
+func (m *MockPaymentProvider) Name() string {
+	return "mock"
+}
+
+func (m *MockPaymentProvider) Authorize(ctx context.Context, req provider.AuthorizeRequest) (*provider.Result, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*provider.Result), args.Error(1)
+}
+
+func (m *MockPaymentProvider) Capture(ctx context.Context, transactionID string, amount float64) (*provider.Result, error) {
+	args := m.Called(ctx, transactionID, amount)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*provider.Result), args.Error(1)
+}
+
+func (m *MockPaymentProvider) Refund(ctx context.Context, transactionID string, amount float64) (*provider.Result, error) {
+	args := m.Called(ctx, transactionID, amount)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*provider.Result), args.Error(1)
+}
+
+func (m *MockPaymentProvider) ParseCallback(header http.Header, body []byte) (*provider.Callback, error) {
+	args := m.Called(header, body)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*provider.Callback), args.Error(1)
+}
+
+var _ provider.PaymentProvider = (*MockPaymentProvider)(nil)
+
+type MockUserClient struct {
+	mock.Mock
+}
+
+func (m *MockUserClient) CreateUser(ctx context.Context, in *userpb.CreateUserRequest, opts ...grpc.CallOption) (*userpb.CreateUserResponse, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*userpb.CreateUserResponse), args.Error(1)
+}
+
+func (m *MockUserClient) GetUser(ctx context.Context, in *userpb.GetUserRequest, opts ...grpc.CallOption) (*userpb.GetUserResponse, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*userpb.GetUserResponse), args.Error(1)
+}
+
+func (m *MockUserClient) GetUserByAuthId(ctx context.Context, in *userpb.GetUserByAuthIdRequest, opts ...grpc.CallOption) (*userpb.GetUserResponse, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*userpb.GetUserResponse), args.Error(1)
+}
+
+func (m *MockUserClient) GetAddress(ctx context.Context, in *userpb.GetAddressRequest, opts ...grpc.CallOption) (*userpb.GetAddressResponse, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*userpb.GetAddressResponse), args.Error(1)
+}
+
+func (m *MockUserClient) LogActivity(ctx context.Context, in *userpb.LogActivityRequest, opts ...grpc.CallOption) (*userpb.LogActivityResponse, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*userpb.LogActivityResponse), args.Error(1)
+}
+
+var _ userpb.UserServiceClient = (*MockUserClient)(nil)
+
+func decodePaymentEvent(t *testing.T, msg *entity.OutboxMessage) dto.PaymentEvent {
+	t.Helper()
+
+	var event dto.PaymentEvent
+	require.NoError(t, json.Unmarshal(msg.Payload, &event))
+	return event
+}
+
+func TestShipCapturesAuthorizedPayment(t *testing.T) {
+	repo, product, payments := new(MockOrderRepository), new(MockProductClient), new(MockPaymentProvider)
+	svc := newTestStatusService(repo, product, payments)
+
+	repo.On("GetPaymentByOrderID", mock.Anything, testOrderID).Return(newTestPayment(entity.PaymentAuthorized), nil).Once()
+	payments.On("Capture", mock.Anything, testTransactionID, 150.0).Return(&provider.Result{TransactionID: testTransactionID, Status: provider.StatusCaptured}, nil).Once()
+	update := expectStatusUpdate(repo)
+
+	require.NoError(t, svc.transitionOrder(context.Background(), newTestOrder(entity.StatusProcessing), entity.StatusShipped, testActor, ""))
+	payments.AssertExpectations(t)
+
+	require.NotNil(t, update.change.Payment)
+	assert.Equal(t, entity.PaymentCompleted, update.change.Payment.To)
+	require.Equal(t, []string{"order.updated", "payment.completed"}, eventTypes(update.events))
+
+	completed := decodePaymentEvent(t, update.events[1])
+	assert.Equal(t, testPaymentID, completed.Data.PaymentID)
+	assert.Equal(t, testTransactionID, completed.Data.TransactionID)
+	assert.Equal(t, string(entity.PaymentCompleted), completed.Data.Status)
+}
+
+func TestShipBlockedByDeclinedCapture(t *testing.T) {
+	repo, product, payments := new(MockOrderRepository), new(MockProductClient), new(MockPaymentProvider)
+	svc := newTestStatusService(repo, product, payments)
+
+	repo.On("GetPaymentByOrderID", mock.Anything, testOrderID).Return(newTestPayment(entity.PaymentAuthorized), nil).Once()
+	payments.On("Capture", mock.Anything, testTransactionID, 150.0).Return(&provider.Result{Status: provider.StatusDeclined, Reason: "Card expired"}, nil).Once()
+	repo.On("UpdatePaymentStatus", mock.Anything, mock.MatchedBy(func(change *entity.PaymentStatusChange) bool {
+		return change.From == entity.PaymentAuthorized && change.To == entity.PaymentFailed
+	}), mock.MatchedBy(func(events []*entity.OutboxMessage) bool {
+		return len(events) == 1 && events[0].EventType == "payment.failed"
+	})).Return(nil).Once()
+
+	err := svc.transitionOrder(context.Background(), newTestOrder(entity.StatusProcessing), entity.StatusShipped, testActor, "")
+	require.Error(t, err)
+	assert.Equal(t, x.CodeHTTPUnprocessableEntity, x.ErrCode(err))
+	repo.AssertExpectations(t)
+	repo.AssertNotCalled(t, "UpdateOrderStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCancelRefundsPayment(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    entity.OrderStatus
+		payment entity.PaymentStatus
+	}{
+		{name: "authorized", from: entity.StatusConfirmed, payment: entity.PaymentAuthorized},
+		{name: "captured", from: entity.StatusConfirmed, payment: entity.PaymentCompleted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, product, payments := new(MockOrderRepository), new(MockProductClient), new(MockPaymentProvider)
+			svc := newTestStatusService(repo, product, payments)
+
+			repo.On("GetPaymentByOrderID", mock.Anything, testOrderID).Return(newTestPayment(tt.payment), nil).Once()
+			payments.On("Refund", mock.Anything, testTransactionID, 150.0).Return(&provider.Result{TransactionID: testTransactionID, Status: provider.StatusRefunded}, nil).Once()
+			update := expectStatusUpdate(repo)
+
+			require.NoError(t, svc.transitionOrder(context.Background(), newTestOrder(tt.from), entity.StatusCancelled, testActor, ""))
+			payments.AssertExpectations(t)
+
+			require.NotNil(t, update.change.Payment)
+			assert.Equal(t, tt.payment, update.change.Payment.From)
+			assert.Equal(t, entity.PaymentRefunded, update.change.Payment.To)
+		})
+	}
+}
+
+func TestCancelKeepsOrderWhenRefundDeclined(t *testing.T) {
+	repo, product, payments := new(MockOrderRepository), new(MockProductClient), new(MockPaymentProvider)
+	svc := newTestStatusService(repo, product, payments)
+
+	repo.On("GetPaymentByOrderID", mock.Anything, testOrderID).Return(newTestPayment(entity.PaymentAuthorized), nil).Once()
+	payments.On("Refund", mock.Anything, testTransactionID, 150.0).Return(&provider.Result{Status: provider.StatusDeclined}, nil).Once()
+
+	err := svc.transitionOrder(context.Background(), newTestOrder(entity.StatusConfirmed), entity.StatusCancelled, testActor, "")
+	require.Error(t, err)
+	assert.Equal(t, x.CodeHTTPUnprocessableEntity, x.ErrCode(err))
+	repo.AssertNotCalled(t, "UpdateOrderStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCreateOrderDeclinedPaymentCancelsOrder(t *testing.T) {
+	repo, product, users := new(MockOrderRepository), new(MockProductClient), new(MockUserClient)
+	svc := newTestStatusService(repo, product, provider.NewFakeProvider(provider.FakeOptions{DeclineAbove: 100}))
+	svc.userClient = users
+
+	orderID, orderNumber := testOrderID, "ORD-20261017-000001"
+	req := &dto.CreateOrderRequest{
+		UserID:            "user-1",
+		ShippingAddressID: "address-1",
+		PaymentMethod:     "credit_card",
+		Items:             []*dto.OrderItem{{ProductID: "product-1", Quantity: 2}},
+	}
+
+	users.On("GetUser", mock.Anything, mock.Anything).Return(&userpb.GetUserResponse{Id: "user-1", Found: true}, nil)
+	users.On("GetAddress", mock.Anything, mock.Anything).Return(&userpb.GetAddressResponse{Id: "address-1", Found: true}, nil)
+	product.On("GetProduct", mock.Anything, mock.Anything).Return(&productpb.GetProductResponse{Id: "product-1", Name: "Widget", Price: 75, Found: true}, nil)
+	product.On("CheckInventory", mock.Anything, mock.Anything).Return(&productpb.CheckInventoryResponse{Available: true}, nil)
+	product.On("ReserveInventory", mock.Anything, mock.Anything).Return(&productpb.ReserveInventoryResponse{Success: true}, nil).Once()
+	product.On("CommitInventory", mock.Anything, mock.Anything).Return(&productpb.CommitInventoryResponse{Success: true}, nil).Once()
+	repo.On("CreateSaga", mock.Anything, mock.Anything).Return(nil).Once()
+	repo.On("UpdateSagaStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	repo.On("StoreOrder", mock.Anything, mock.Anything, mock.Anything, req, 150.0, mock.Anything).Return(&orderID, &orderNumber, nil).Once()
+	repo.On("StorePayment", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+
+	// 150 is over the fake provider's limit, so the authorization is declined
+	repo.On("GetPaymentByOrderID", mock.Anything, testOrderID).Return(newTestPayment(entity.PaymentPending), nil).Once()
+	update := expectStatusUpdate(repo)
+
+	_, _, _, err := svc.CreateOrder(context.Background(), req)
+	require.Error(t, err)
+	assert.Equal(t, x.CodeHTTPUnprocessableEntity, x.ErrCode(err))
+
+	require.NotNil(t, update.change)
+	assert.Equal(t, entity.StatusPending, update.change.From)
+	assert.Equal(t, entity.StatusCancelled, update.change.To)
+	assert.Equal(t, entity.ActorSystem, update.change.Actor)
+	require.NotNil(t, update.change.Payment)
+	assert.Equal(t, entity.PaymentPending, update.change.Payment.From)
+	assert.Equal(t, entity.PaymentFailed, update.change.Payment.To)
+	require.Equal(t, []string{"order.updated", "order.cancelled", "payment.failed"}, eventTypes(update.events))
+	assert.NotEmpty(t, decodePaymentEvent(t, update.events[2]).Data.Reason)
+
+	// The stock committed by the saga goes back with the cancellation
+	repo.On("GetSagaByOrderID", mock.Anything, testOrderID).Return(&entity.OrderSaga{ID: testSagaID, Status: entity.SagaCompleted}, nil).Once()
+	product.On("RestockInventory", mock.Anything, mock.MatchedBy(func(in *productpb.RestockInventoryRequest) bool {
+		return len(in.Items) == 1 && in.Items[0].ProductId == "product-1" && in.Items[0].Quantity == 2
+	})).Return(&productpb.RestockInventoryResponse{Success: true}, nil).Once()
+
+	require.NotNil(t, update.returnStock)
+	require.NoError(t, update.returnStock(context.Background(), []*entity.OrderItem{{ProductID: "product-1", Quantity: 2}}))
+	repo.AssertExpectations(t)
+	product.AssertExpectations(t)
+}
+
+func TestHandlePaymentCallbackRejectsBadSignature(t *testing.T) {
+	repo, product := new(MockOrderRepository), new(MockProductClient)
+	svc := newTestStatusService(repo, product, provider.NewFakeProvider(provider.FakeOptions{CallbackSecret: "callback-secret"}))
+
+	header := http.Header{}
+	header.Set(provider.FakeSignatureHeader, "00")
+
+	err := svc.HandlePaymentCallback(context.Background(), header, []byte(`{"payment_id":"payment-1","status":"authorized"}`))
+	require.Error(t, err)
+	assert.Equal(t, x.CodeHTTPUnauthorized, x.ErrCode(err))
+	repo.AssertNotCalled(t, "GetPayment", mock.Anything, mock.Anything)
+}
+
+// newTestCallbackService answers ParseCallback with cb and looks up the
+// payment and order in the given states.
+func newTestCallbackService(repo *MockOrderRepository, payments *MockPaymentProvider, cb *provider.Callback, payment entity.PaymentStatus, order entity.OrderStatus) *orderService {
+	payments.On("ParseCallback", mock.Anything, mock.Anything).Return(cb, nil).Once()
+	repo.On("GetPayment", mock.Anything, testPaymentID).Return(newTestPayment(payment), nil).Once()
+	repo.On("GetOrderByID", mock.Anything, testOrderID).Return(newTestOrder(order), nil).Once()
+
+	return newTestStatusService(repo, new(MockProductClient), payments)
+}
+
+func TestHandlePaymentCallbackSettlesPendingAuthorization(t *testing.T) {
+	repo, payments := new(MockOrderRepository), new(MockPaymentProvider)
+	cb := &provider.Callback{PaymentID: testPaymentID, TransactionID: testTransactionID, Status: provider.StatusAuthorized}
+	svc := newTestCallbackService(repo, payments, cb, entity.PaymentPending, entity.StatusPending)
+	update := expectStatusUpdate(repo)
+
+	require.NoError(t, svc.HandlePaymentCallback(context.Background(), http.Header{}, nil))
+
+	require.NotNil(t, update.change)
+	assert.Equal(t, entity.StatusConfirmed, update.change.To)
+	assert.Equal(t, entity.ActorSystem, update.change.Actor)
+	require.NotNil(t, update.change.Payment)
+	assert.Equal(t, entity.PaymentAuthorized, update.change.Payment.To)
+	assert.Equal(t, testTransactionID, *update.change.Payment.TransactionID)
+}
+
+func TestHandlePaymentCallbackCompletesCapture(t *testing.T) {
+	repo, payments := new(MockOrderRepository), new(MockPaymentProvider)
+	cb := &provider.Callback{PaymentID: testPaymentID, TransactionID: testTransactionID, Status: provider.StatusCaptured}
+	svc := newTestCallbackService(repo, payments, cb, entity.PaymentAuthorized, entity.StatusShipped)
+
+	repo.On("UpdatePaymentStatus", mock.Anything, mock.MatchedBy(func(change *entity.PaymentStatusChange) bool {
+		return change.From == entity.PaymentAuthorized && change.To == entity.PaymentCompleted
+	}), mock.MatchedBy(func(events []*entity.OutboxMessage) bool {
+		return len(events) == 1 && events[0].EventType == "payment.completed"
+	})).Return(nil).Once()
+
+	require.NoError(t, svc.HandlePaymentCallback(context.Background(), http.Header{}, nil))
+	repo.AssertExpectations(t)
+}
+
+func TestHandlePaymentCallbackIgnoresStaleOutcomes(t *testing.T) {
+	tests := []struct {
+		name     string
+		payment  entity.PaymentStatus
+		order    entity.OrderStatus
+		callback provider.Status
+	}{
+		{name: "authorization delivered twice", payment: entity.PaymentAuthorized, order: entity.StatusConfirmed, callback: provider.StatusAuthorized},
+		{name: "decline delivered twice", payment: entity.PaymentFailed, order: entity.StatusCancelled, callback: provider.StatusDeclined},
+		{name: "capture delivered twice", payment: entity.PaymentCompleted, order: entity.StatusShipped, callback: provider.StatusCaptured},
+		{name: "refund delivered twice", payment: entity.PaymentRefunded, order: entity.StatusCancelled, callback: provider.StatusRefunded},
+		{name: "capture before authorization", payment: entity.PaymentPending, order: entity.StatusPending, callback: provider.StatusCaptured},
+		{name: "refund before authorization", payment: entity.PaymentPending, order: entity.StatusPending, callback: provider.StatusRefunded},
+		{name: "authorization after capture", payment: entity.PaymentCompleted, order: entity.StatusShipped, callback: provider.StatusAuthorized},
+		{name: "authorization after refund", payment: entity.PaymentRefunded, order: entity.StatusCancelled, callback: provider.StatusAuthorized},
+		{name: "decline after capture", payment: entity.PaymentCompleted, order: entity.StatusShipped, callback: provider.StatusDeclined},
+		{name: "authorization for an order no longer pending", payment: entity.PaymentPending, order: entity.StatusCancelled, callback: provider.StatusAuthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, payments := new(MockOrderRepository), new(MockPaymentProvider)
+			cb := &provider.Callback{PaymentID: testPaymentID, TransactionID: testTransactionID, Status: tt.callback}
+			svc := newTestCallbackService(repo, payments, cb, tt.payment, tt.order)
+
+			require.NoError(t, svc.HandlePaymentCallback(context.Background(), http.Header{}, nil))
+			repo.AssertExpectations(t)
+			repo.AssertNotCalled(t, "UpdatePaymentStatus", mock.Anything, mock.Anything, mock.Anything)
+			repo.AssertNotCalled(t, "UpdateOrderStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			payments.AssertNotCalled(t, "Refund", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestHandlePaymentCallbackReleasesAuthorizationForCancelledOrder(t *testing.T) {
+	repo, payments := new(MockOrderRepository), new(MockPaymentProvider)
+	cb := &provider.Callback{PaymentID: testPaymentID, TransactionID: testTransactionID, Status: provider.StatusAuthorized}
+	svc := newTestCallbackService(repo, payments, cb, entity.PaymentFailed, entity.StatusCancelled)
+
+	// The order was cancelled while the authorization was pending
+	payments.On("Refund", mock.Anything, testTransactionID, 150.0).Return(&provider.Result{Status: provider.StatusRefunded}, nil).Once()
+
+	require.NoError(t, svc.HandlePaymentCallback(context.Background(), http.Header{}, nil))
+	payments.AssertExpectations(t)
+	repo.AssertNotCalled(t, "UpdatePaymentStatus", mock.Anything, mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "UpdateOrderStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,161 @@
+package order
+
+import (
+	"context"
+	"strconv"
+
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+	authpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/auth"
+	userpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/user"
+	"github.com/linggaaskaedo/go-kill/order-service/src/internal/model/dto"
+	"github.com/linggaaskaedo/go-kill/order-service/src/internal/model/entity"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
+func (s *orderService) ValidateToken(ctx context.Context, req *authpb.ValidateTokenRequest) (*authpb.ValidateTokenResponse, error) {
+	authResp, err := s.authClient.ValidateToken(ctx, req)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("validate_token")
+		return nil, err
+	}
+
+	return authResp, nil
+}
+
+func (s *orderService) ValidateApiKey(ctx context.Context, req *authpb.ValidateApiKeyRequest) (*authpb.ValidateTokenResponse, error) {
+	authResp, err := s.authClient.ValidateApiKey(ctx, req)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("validate_api_key")
+		return nil, err
+	}
+
+	return authResp, nil
+}
+
+func (s *orderService) CreateUserOrder(ctx context.Context, userAuthID string, req dto.CreateUserOrderRequest) (*dto.CreateOrderResp, error) {
+	userID, err := s.resolveUserID(ctx, userAuthID)
+	if err != nil {
+		return nil, err
+	}
+
+	billingAddressID := req.BillingAddressID
+	if billingAddressID == "" {
+		billingAddressID = req.ShippingAddressID
+	}
+
+	items := make([]*dto.OrderItem, len(req.Items))
+	for i, item := range req.Items {
+		items[i] = &dto.OrderItem{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+		}
+	}
+
+	orderID, orderNumber, totalAmount, err := s.CreateOrder(ctx, &dto.CreateOrderRequest{
+		UserID:            userID,
+		ShippingAddressID: req.ShippingAddressID,
+		BillingAddressID:  billingAddressID,
+		PaymentMethod:     req.PaymentMethod,
+		Items:             items,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.CreateOrderResp{
+		OrderID:     *orderID,
+		OrderNumber: *orderNumber,
+		TotalAmount: totalAmount,
+	}, nil
+}
+
+func (s *orderService) GetUserOrder(ctx context.Context, userAuthID string, orderID string) (*entity.Order, error) {
+	userID, err := s.resolveUserID(ctx, userAuthID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetOrder(ctx, &dto.GetOrderRequest{OrderID: orderID, UserID: userID})
+}
+
+// GetAnyOrder reads an order of any user. The route requires order:read:any.
+func (s *orderService) GetAnyOrder(ctx context.Context, orderID string) (*entity.Order, error) {
+	return s.orderRepository.GetAnyOrder(ctx, orderID)
+}
+
+func (s *orderService) ListUserOrders(ctx context.Context, userAuthID string, page string, limit string) ([]*entity.Order, *dto.Pagination, error) {
+	pageNum, err := strconv.ParseInt(page, 10, 32)
+	if err != nil {
+		return nil, nil, x.WrapWithCode(err, x.CodeHTTPParamDecode, "list_user_orders_page")
+	}
+
+	limitNum, err := strconv.ParseInt(limit, 10, 32)
+	if err != nil {
+		return nil, nil, x.WrapWithCode(err, x.CodeHTTPParamDecode, "list_user_orders_limit")
+	}
+
+	if pageNum < 1 {
+		pageNum = 1
+	}
+
+	if limitNum < 1 {
+		limitNum = defaultListLimit
+	}
+
+	if limitNum > maxListLimit {
+		limitNum = maxListLimit
+	}
+
+	userID, err := s.resolveUserID(ctx, userAuthID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	orders, total, err := s.ListOrders(ctx, &dto.ListOrderRequest{
+		UserID: userID,
+		Limit:  int32(limitNum),
+		Offset: int32((pageNum - 1) * limitNum),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	totalElements := int64(total)
+
+	return orders, &dto.Pagination{
+		CurrentPage:     pageNum,
+		CurrentElements: int64(len(orders)),
+		TotalPages:      (totalElements + limitNum - 1) / limitNum,
+		TotalElements:   totalElements,
+	}, nil
+}
+
+func (s *orderService) CancelUserOrder(ctx context.Context, userAuthID string, orderID string, req dto.CancelUserOrderRequest) error {
+	userID, err := s.resolveUserID(ctx, userAuthID)
+	if err != nil {
+		return err
+	}
+
+	return s.CancelOrder(ctx, &dto.CancelOrderRequest{OrderID: orderID, UserID: userID, Reason: req.Reason})
+}
+
+// resolveUserID maps the auth identity from the token to the user-service ID that orders are stored under.
+func (s *orderService) resolveUserID(ctx context.Context, userAuthID string) (string, error) {
+	userResp, err := s.userClient.GetUserByAuthId(ctx, &userpb.GetUserByAuthIdRequest{AuthId: userAuthID})
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("resolve_user_id")
+		return "", x.WrapWithCode(err, x.CodeHTTPUnauthorized, "resolve_user_id")
+	}
+
+	if !userResp.Found {
+		return "", x.NewWithCode(x.CodeHTTPUnauthorized, "resolve_user_id")
+	}
+
+	return userResp.Id, nil
+}
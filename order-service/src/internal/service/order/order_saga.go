@@ -0,0 +1,305 @@
+package order
+
+import (
+	"context"
+
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+	productpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/product"
+	userpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/user"
+	"github.com/linggaaskaedo/go-kill/order-service/src/internal/model/dto"
+	"github.com/linggaaskaedo/go-kill/order-service/src/internal/model/entity"
+	"github.com/linggaaskaedo/go-kill/order-service/src/internal/util"
+
+	"github.com/openpcc/openpcc/uuidv7"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// runOrderSaga drives an order through reserve → create order → payment →
+// commit stock, persisting the step reached after each one so the recovery
+// job can resume or compensate a saga whose process died midway.
+func (s *orderService) runOrderSaga(
+	ctx context.Context,
+	reqData *dto.CreateOrderRequest,
+	userResp *userpb.GetUserResponse,
+	productDetails []*dto.ProductDetails,
+	totalAmount float64,
+) (*string, *string, error) {
+	// The reservation id is picked here and stored with the saga before the
+	// reserve call, so the hold can be released even if that call times out.
+	reservationID := uuidv7.MustNew().String()
+
+	saga := &entity.OrderSaga{
+		ID:            uuidv7.MustNew().String(),
+		ReservationID: &reservationID,
+		UserID:        reqData.UserID,
+		Status:        entity.SagaStarted,
+		Items:         util.ToSagaItems(reqData.Items),
+	}
+
+	if err := s.orderRepository.CreateSaga(ctx, saga); err != nil {
+		return nil, nil, err
+	}
+
+	// Step 1: Reserve inventory
+	reserveResp, err := s.productClient.ReserveInventory(ctx, &productpb.ReserveInventoryRequest{
+		Items:         util.SagaItemsToInventoryItemPB(saga.Items),
+		ReservationId: reservationID,
+	})
+	if err != nil || !reserveResp.Success {
+		zerolog.Ctx(ctx).Error().Err(err).Str("sagaID", saga.ID).Msg("Failed to reserve inventory")
+		s.compensateSaga(ctx, saga, "Failed to reserve inventory")
+		return nil, nil, x.New("Failed to reserve inventory", err)
+	}
+
+	if err := s.advanceSaga(ctx, saga, entity.SagaInventoryReserved); err != nil {
+		s.compensateSaga(ctx, saga, "Failed to record inventory reservation")
+		return nil, nil, err
+	}
+
+	// Step 2: Create order, outbox event and saga step in one transaction
+	orderID, orderNumber, err := s.orderRepository.StoreOrder(ctx, saga.ID, productDetails, reqData, totalAmount, func(orderID, orderNumber string) (*entity.OutboxMessage, error) {
+		return s.newOrderCreatedEvent(orderID, orderNumber, reqData, userResp, productDetails, totalAmount)
+	})
+	if err != nil {
+		s.compensateSaga(ctx, saga, "Failed to create order")
+		return nil, nil, err
+	}
+
+	saga.OrderID = orderID
+	saga.Status = entity.SagaOrderCreated
+
+	// Step 3: Record payment
+	err = s.orderRepository.StorePayment(ctx, saga.ID, &entity.Payment{
+		ID:            uuidv7.MustNew().String(),
+		OrderID:       *orderID,
+		PaymentMethod: reqData.PaymentMethod,
+		Amount:        totalAmount,
+	})
+	if err != nil {
+		s.compensateSaga(ctx, saga, "Failed to record payment")
+		return nil, nil, err
+	}
+
+	saga.Status = entity.SagaPaymentCreated
+
+	// Step 4: Commit stock. If this fails the order stands and the recovery
+	// job retries the commit, unless the hold is gone and the order was
+	// cancelled instead.
+	if err := s.commitSaga(ctx, saga); err != nil {
+		if saga.Status != entity.SagaPaymentCreated {
+			return nil, nil, err
+		}
+
+		zerolog.Ctx(ctx).Warn().Err(err).Str("sagaID", saga.ID).Msg("commit_inventory_deferred")
+	}
+
+	return orderID, orderNumber, nil
+}
+
+func (s *orderService) advanceSaga(ctx context.Context, saga *entity.OrderSaga, to entity.SagaStatus) error {
+	if err := s.orderRepository.UpdateSagaStatus(ctx, saga.ID, saga.Status, to, nil); err != nil {
+		return err
+	}
+
+	saga.Status = to
+	return nil
+}
+
+func (s *orderService) commitSaga(ctx context.Context, saga *entity.OrderSaga) error {
+	if saga.ReservationID == nil {
+		return x.New("Saga has no reservation to commit")
+	}
+
+	_, err := s.productClient.CommitInventory(ctx, &productpb.CommitInventoryRequest{ReservationId: *saga.ReservationID})
+	if reservationLost(err) {
+		// The hold expired or was released while the commit was deferred.
+		// Hold the stock again, and cancel the order if it is gone.
+		zerolog.Ctx(ctx).Warn().Err(err).Str("sagaID", saga.ID).Msg("saga_reservation_lost")
+
+		if err = s.reserveAgain(ctx, saga); err == nil {
+			_, err = s.productClient.CommitInventory(ctx, &productpb.CommitInventoryRequest{ReservationId: *saga.ReservationID})
+		}
+
+		if reservationLost(err) {
+			zerolog.Ctx(ctx).Error().Err(err).Str("sagaID", saga.ID).Msg("saga_stock_unavailable")
+			s.compensateSaga(ctx, saga, "Stock is no longer available")
+			return x.Wrap(err, "Stock is no longer available")
+		}
+	}
+	if err != nil {
+		return x.Wrap(err, "Failed to commit inventory")
+	}
+
+	return s.advanceSaga(ctx, saga, entity.SagaCompleted)
+}
+
+// reserveAgain replaces a saga's lapsed hold with a new one. As in
+// runOrderSaga the id is stored before the reserve call, so a hold placed by
+// a call that timed out is still committed or released later.
+func (s *orderService) reserveAgain(ctx context.Context, saga *entity.OrderSaga) error {
+	reservationID := uuidv7.MustNew().String()
+
+	if err := s.orderRepository.UpdateSagaReservation(ctx, saga.ID, saga.Status, reservationID); err != nil {
+		return err
+	}
+
+	saga.ReservationID = &reservationID
+
+	resp, err := s.productClient.ReserveInventory(ctx, &productpb.ReserveInventoryRequest{
+		Items:         util.SagaItemsToInventoryItemPB(saga.Items),
+		ReservationId: reservationID,
+	})
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return x.New("Failed to reserve inventory")
+	}
+
+	return nil
+}
+
+// reservationLost reports whether product-service refused a reserve or
+// commit for good: the stock is not available or the reservation is no
+// longer active or does not exist.
+func reservationLost(err error) bool {
+	switch status.Code(err) {
+	case codes.FailedPrecondition, codes.NotFound:
+		return true
+	default:
+		return false
+	}
+}
+
+// compensateSaga undoes the steps completed so far, newest first. Each
+// compensation is recorded before moving on, so it is safe to call again for
+// a saga the recovery job finds half compensated. Errors are logged and leave
+// the saga in place for the next recovery run.
+func (s *orderService) compensateSaga(ctx context.Context, saga *entity.OrderSaga, reason string) {
+	log := zerolog.Ctx(ctx).With().Str("sagaID", saga.ID).Str("status", string(saga.Status)).Str("reason", reason).Logger()
+
+	switch saga.Status {
+	case entity.SagaStarted:
+		// The reserve call failed or its outcome is unknown. Releasing by
+		// reservation id is a no-op if nothing was held; sagas without one
+		// have no hold we can safely release.
+		if saga.ReservationID != nil {
+			if err := s.releaseSagaInventory(ctx, saga); err != nil {
+				log.Error().Err(err).Msg("saga_release_inventory_failed")
+				return
+			}
+		}
+
+		if err := s.orderRepository.UpdateSagaStatus(ctx, saga.ID, entity.SagaStarted, entity.SagaFailed, &reason); err != nil {
+			log.Error().Err(err).Msg("saga_mark_failed_failed")
+			return
+		}
+
+		saga.Status = entity.SagaFailed
+		log.Warn().Msg("saga_failed")
+		return
+	case entity.SagaOrderCreated, entity.SagaPaymentCreated:
+		// Only a pending order is cancelled; one that has been paid in the
+		// meantime fails here and stays for an operator
+		events, err := s.newStatusEvents(&entity.Order{
+			ID:     *saga.OrderID,
+			UserID: saga.UserID,
+			Status: entity.StatusPending,
+		}, entity.StatusCancelled, entity.ActorSystem, reason)
+		if err == nil {
+			err = s.orderRepository.CompensateOrder(ctx, saga.ID, saga.Status, *saga.OrderID, reason, events)
+		}
+		if err != nil {
+			log.Error().Err(err).Msg("saga_compensate_order_failed")
+			return
+		}
+
+		saga.Status = entity.SagaCompensating
+	case entity.SagaInventoryReserved:
+		if err := s.advanceSaga(ctx, saga, entity.SagaCompensating); err != nil {
+			log.Error().Err(err).Msg("saga_mark_compensating_failed")
+			return
+		}
+	case entity.SagaCompensating:
+	default:
+		return
+	}
+
+	if err := s.releaseSagaInventory(ctx, saga); err != nil {
+		log.Error().Err(err).Msg("saga_release_inventory_failed")
+		return
+	}
+
+	if err := s.orderRepository.UpdateSagaStatus(ctx, saga.ID, entity.SagaCompensating, entity.SagaCompensated, &reason); err != nil {
+		log.Error().Err(err).Msg("saga_mark_compensated_failed")
+		return
+	}
+
+	saga.Status = entity.SagaCompensated
+	log.Info().Msg("saga_compensated")
+}
+
+// releaseSagaInventory drops the saga's hold. Sagas created before
+// reservation ids existed fall back to releasing by item quantities.
+func (s *orderService) releaseSagaInventory(ctx context.Context, saga *entity.OrderSaga) error {
+	req := &productpb.ReleaseInventoryRequest{Items: util.SagaItemsToInventoryItemPB(saga.Items)}
+	if saga.ReservationID != nil {
+		req = &productpb.ReleaseInventoryRequest{ReservationId: *saga.ReservationID}
+	}
+
+	_, err := s.productClient.ReleaseInventory(ctx, req)
+	return err
+}
+
+// RecoverSagas resumes or compensates sagas that have not moved for
+// StaleAfter, which means the process running them died or gave up. It
+// returns how many sagas reached a terminal status.
+func (s *orderService) RecoverSagas(ctx context.Context, batchSize int) (int, error) {
+	opts := s.orderOptions.Saga
+
+	sagas, err := s.orderRepository.ListStaleSagas(ctx, opts.StaleAfter, batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	recovered := 0
+	for _, saga := range sagas {
+		claimed, err := s.orderRepository.ClaimSaga(ctx, saga.ID, saga.Status, opts.StaleAfter)
+		if err != nil || !claimed {
+			continue
+		}
+
+		if saga.Status == entity.SagaPaymentCreated {
+			err := s.commitSaga(ctx, saga)
+			if err == nil || saga.Status == entity.SagaCompensated {
+				recovered++
+				continue
+			}
+
+			// Cancelled, with the release left to the next run
+			if saga.Status == entity.SagaCompensating {
+				continue
+			}
+
+			zerolog.Ctx(ctx).Error().Err(err).Str("sagaID", saga.ID).Int("attempts", saga.Attempts+1).Msg("saga_commit_retry_failed")
+
+			if opts.MaxAttempts > 0 && saga.Attempts+1 >= opts.MaxAttempts {
+				lastErr := err.Error()
+				if markErr := s.orderRepository.UpdateSagaStatus(ctx, saga.ID, entity.SagaPaymentCreated, entity.SagaFailed, &lastErr); markErr != nil {
+					zerolog.Ctx(ctx).Error().Err(markErr).Str("sagaID", saga.ID).Msg("saga_mark_failed_failed")
+				}
+			}
+
+			continue
+		}
+
+		s.compensateSaga(ctx, saga, "Recovered stale saga")
+		if saga.Status == entity.SagaCompensated || saga.Status == entity.SagaFailed {
+			recovered++
+		}
+	}
+
+	return recovered, nil
+}
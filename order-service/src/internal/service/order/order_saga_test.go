@@ -0,0 +1,354 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	productpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/product"
+	"github.com/linggaaskaedo/go-kill/order-service/src/internal/model/dto"
+	"github.com/linggaaskaedo/go-kill/order-service/src/internal/model/entity"
+	"github.com/linggaaskaedo/go-kill/order-service/src/internal/repository/order"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	testSagaID        = "saga-1"
+	testOrderID       = "order-1"
+	testReservationID = "019d227d-6eac-749c-b935-263bddc5a650"
+	testBatchSize     = 10
+)
+
+type MockOrderRepository struct {
+	mock.Mock
+}
+
+func (m *MockOrderRepository) StoreOrder(ctx context.Context, sagaID string, productDetails []*dto.ProductDetails, createOrders *dto.CreateOrderRequest, totalAmount float64, newEvent order.OutboxMessageFunc) (*string, *string, error) {
+	args := m.Called(ctx, sagaID, productDetails, createOrders, totalAmount, newEvent)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*string), args.Get(1).(*string), args.Error(2)
+}
+
+func (m *MockOrderRepository) GetOrder(ctx context.Context, reqData *dto.GetOrderRequest) (*entity.Order, error) {
+	args := m.Called(ctx, reqData)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) ListOrders(ctx context.Context, reqData *dto.ListOrderRequest) ([]*entity.Order, int32, error) {
+	args := m.Called(ctx, reqData)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*entity.Order), args.Get(1).(int32), args.Error(2)
+}
+
+func (m *MockOrderRepository) GetOrderByID(ctx context.Context, orderID string) (*entity.Order, error) {
+	args := m.Called(ctx, orderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) GetAnyOrder(ctx context.Context, orderID string) (*entity.Order, error) {
+	args := m.Called(ctx, orderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) UpdateOrderStatus(ctx context.Context, change *entity.OrderStatusChange, events []*entity.OutboxMessage, returnStock order.ReturnStockFunc) error {
+	args := m.Called(ctx, change, events, returnStock)
+	return args.Error(0)
+}
+
+func (m *MockOrderRepository) CreateSaga(ctx context.Context, saga *entity.OrderSaga) error {
+	args := m.Called(ctx, saga)
+	return args.Error(0)
+}
+
+func (m *MockOrderRepository) UpdateSagaStatus(ctx context.Context, sagaID string, from entity.SagaStatus, to entity.SagaStatus, lastErr *string) error {
+	args := m.Called(ctx, sagaID, from, to, lastErr)
+	return args.Error(0)
+}
+
+func (m *MockOrderRepository) UpdateSagaReservation(ctx context.Context, sagaID string, status entity.SagaStatus, reservationID string) error {
+	args := m.Called(ctx, sagaID, status, reservationID)
+	return args.Error(0)
+}
+
+func (m *MockOrderRepository) GetSagaByOrderID(ctx context.Context, orderID string) (*entity.OrderSaga, error) {
+	args := m.Called(ctx, orderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.OrderSaga), args.Error(1)
+}
+
+func (m *MockOrderRepository) ListStaleSagas(ctx context.Context, staleAfter time.Duration, limit int) ([]*entity.OrderSaga, error) {
+	args := m.Called(ctx, staleAfter, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.OrderSaga), args.Error(1)
+}
+
+func (m *MockOrderRepository) ClaimSaga(ctx context.Context, sagaID string, status entity.SagaStatus, staleAfter time.Duration) (bool, error) {
+	args := m.Called(ctx, sagaID, status, staleAfter)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockOrderRepository) StorePayment(ctx context.Context, sagaID string, payment *entity.Payment) error {
+	args := m.Called(ctx, sagaID, payment)
+	return args.Error(0)
+}
+
+func (m *MockOrderRepository) CompensateOrder(ctx context.Context, sagaID string, from entity.SagaStatus, orderID string, reason string, events []*entity.OutboxMessage) error {
+	args := m.Called(ctx, sagaID, from, orderID, reason, events)
+	return args.Error(0)
+}
+
+func (m *MockOrderRepository) GetPayment(ctx context.Context, paymentID string) (*entity.Payment, error) {
+	args := m.Called(ctx, paymentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Payment), args.Error(1)
+}
+
+func (m *MockOrderRepository) GetPaymentByOrderID(ctx context.Context, orderID string) (*entity.Payment, error) {
+	args := m.Called(ctx, orderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Payment), args.Error(1)
+}
+
+func (m *MockOrderRepository) UpdatePaymentStatus(ctx context.Context, change *entity.PaymentStatusChange, events []*entity.OutboxMessage) error {
+	args := m.Called(ctx, change, events)
+	return args.Error(0)
+}
+
+func (m *MockOrderRepository) ClaimOutbox(ctx context.Context, claimToken string, lease time.Duration, limit int) ([]*entity.OutboxMessage, error) {
+	args := m.Called(ctx, claimToken, lease, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.OutboxMessage), args.Error(1)
+}
+
+func (m *MockOrderRepository) MarkOutboxSent(ctx context.Context, id string, claimToken string) error {
+	args := m.Called(ctx, id, claimToken)
+	return args.Error(0)
+}
+
+func (m *MockOrderRepository) MarkOutboxRetry(ctx context.Context, id string, claimToken string, status entity.OutboxStatus, lastErr string, backoff time.Duration) error {
+	args := m.Called(ctx, id, claimToken, status, lastErr, backoff)
+	return args.Error(0)
+}
+
+var _ order.OrderRepositoryItf = (*MockOrderRepository)(nil)
+
+type MockProductClient struct {
+	mock.Mock
+}
+
+func (m *MockProductClient) GetProduct(ctx context.Context, in *productpb.GetProductRequest, opts ...grpc.CallOption) (*productpb.GetProductResponse, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*productpb.GetProductResponse), args.Error(1)
+}
+
+func (m *MockProductClient) CheckInventory(ctx context.Context, in *productpb.CheckInventoryRequest, opts ...grpc.CallOption) (*productpb.CheckInventoryResponse, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*productpb.CheckInventoryResponse), args.Error(1)
+}
+
+func (m *MockProductClient) ReserveInventory(ctx context.Context, in *productpb.ReserveInventoryRequest, opts ...grpc.CallOption) (*productpb.ReserveInventoryResponse, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*productpb.ReserveInventoryResponse), args.Error(1)
+}
+
+func (m *MockProductClient) ReleaseInventory(ctx context.Context, in *productpb.ReleaseInventoryRequest, opts ...grpc.CallOption) (*productpb.ReleaseInventoryResponse, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*productpb.ReleaseInventoryResponse), args.Error(1)
+}
+
+func (m *MockProductClient) CommitInventory(ctx context.Context, in *productpb.CommitInventoryRequest, opts ...grpc.CallOption) (*productpb.CommitInventoryResponse, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*productpb.CommitInventoryResponse), args.Error(1)
+}
+
+func (m *MockProductClient) RestockInventory(ctx context.Context, in *productpb.RestockInventoryRequest, opts ...grpc.CallOption) (*productpb.RestockInventoryResponse, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*productpb.RestockInventoryResponse), args.Error(1)
+}
+
+var _ productpb.ProductServiceClient = (*MockProductClient)(nil)
+
+// newStaleSaga is a saga whose order and payment were stored but whose stock
+// commit was deferred.
+func newStaleSaga() *entity.OrderSaga {
+	orderID := testOrderID
+	reservationID := testReservationID
+
+	return &entity.OrderSaga{
+		ID:            testSagaID,
+		OrderID:       &orderID,
+		ReservationID: &reservationID,
+		UserID:        "user-1",
+		Status:        entity.SagaPaymentCreated,
+		Items:         []entity.SagaItem{{ProductID: "product-1", Quantity: 2}},
+	}
+}
+
+func newTestSagaService(repo *MockOrderRepository, product *MockProductClient, saga *entity.OrderSaga) *orderService {
+	opts := SagaOptions{StaleAfter: 2 * time.Minute, MaxAttempts: 3}
+
+	repo.On("ListStaleSagas", mock.Anything, opts.StaleAfter, testBatchSize).Return([]*entity.OrderSaga{saga}, nil)
+	repo.On("ClaimSaga", mock.Anything, testSagaID, saga.Status, opts.StaleAfter).Return(true, nil)
+
+	return &orderService{
+		orderRepository: repo,
+		productClient:   product,
+		orderOptions: Options{
+			TopicOrderUpdated:  "order.updated",
+			TopicOrderCanceled: "order.cancelled",
+			Saga:               opts,
+		},
+	}
+}
+
+var (
+	commitRequest      = &productpb.CommitInventoryRequest{ReservationId: testReservationID}
+	errReservationGone = status.Error(codes.FailedPrecondition, "reservation is no longer active")
+	errOutOfStock      = status.Error(codes.FailedPrecondition, "Insufficient inventory")
+)
+
+// newReservation matches a request carrying any reservation id but the
+// expired one.
+func newReservation[T interface{ GetReservationId() string }]() any {
+	return mock.MatchedBy(func(in T) bool {
+		return in.GetReservationId() != testReservationID
+	})
+}
+
+func TestRecoverSagasCommitsDeferredStock(t *testing.T) {
+	repo, product := new(MockOrderRepository), new(MockProductClient)
+	svc := newTestSagaService(repo, product, newStaleSaga())
+
+	product.On("CommitInventory", mock.Anything, commitRequest).Return(&productpb.CommitInventoryResponse{Success: true}, nil)
+	repo.On("UpdateSagaStatus", mock.Anything, testSagaID, entity.SagaPaymentCreated, entity.SagaCompleted, (*string)(nil)).Return(nil).Once()
+
+	recovered, err := svc.RecoverSagas(context.Background(), testBatchSize)
+	require.NoError(t, err)
+	assert.Equal(t, 1, recovered)
+	repo.AssertExpectations(t)
+	repo.AssertNotCalled(t, "CompensateOrder", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	product.AssertNotCalled(t, "ReserveInventory", mock.Anything, mock.Anything)
+}
+
+func TestRecoverSagasReservesAgainWhenReservationExpired(t *testing.T) {
+	repo, product := new(MockOrderRepository), new(MockProductClient)
+	svc := newTestSagaService(repo, product, newStaleSaga())
+
+	product.On("CommitInventory", mock.Anything, commitRequest).Return(nil, errReservationGone).Once()
+	repo.On("UpdateSagaReservation", mock.Anything, testSagaID, entity.SagaPaymentCreated, mock.MatchedBy(func(id string) bool {
+		return id != testReservationID
+	})).Return(nil).Once()
+	product.On("ReserveInventory", mock.Anything, newReservation[*productpb.ReserveInventoryRequest]()).Return(&productpb.ReserveInventoryResponse{Success: true}, nil).Once()
+	product.On("CommitInventory", mock.Anything, newReservation[*productpb.CommitInventoryRequest]()).Return(&productpb.CommitInventoryResponse{Success: true}, nil).Once()
+	repo.On("UpdateSagaStatus", mock.Anything, testSagaID, entity.SagaPaymentCreated, entity.SagaCompleted, (*string)(nil)).Return(nil).Once()
+
+	recovered, err := svc.RecoverSagas(context.Background(), testBatchSize)
+	require.NoError(t, err)
+	assert.Equal(t, 1, recovered)
+	repo.AssertExpectations(t)
+	product.AssertExpectations(t)
+	repo.AssertNotCalled(t, "CompensateOrder", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRecoverSagasCancelsOrderWhenStockIsGone(t *testing.T) {
+	repo, product := new(MockOrderRepository), new(MockProductClient)
+	svc := newTestSagaService(repo, product, newStaleSaga())
+
+	product.On("CommitInventory", mock.Anything, commitRequest).Return(nil, errReservationGone).Once()
+	repo.On("UpdateSagaReservation", mock.Anything, testSagaID, entity.SagaPaymentCreated, mock.Anything).Return(nil).Once()
+	product.On("ReserveInventory", mock.Anything, newReservation[*productpb.ReserveInventoryRequest]()).Return(nil, errOutOfStock).Once()
+	repo.On("CompensateOrder", mock.Anything, testSagaID, entity.SagaPaymentCreated, testOrderID, mock.Anything, mock.MatchedBy(func(events []*entity.OutboxMessage) bool {
+		return len(events) == 2
+	})).Return(nil).Once()
+	product.On("ReleaseInventory", mock.Anything, newReservation[*productpb.ReleaseInventoryRequest]()).Return(&productpb.ReleaseInventoryResponse{Success: true}, nil).Once()
+	repo.On("UpdateSagaStatus", mock.Anything, testSagaID, entity.SagaCompensating, entity.SagaCompensated, mock.Anything).Return(nil).Once()
+
+	recovered, err := svc.RecoverSagas(context.Background(), testBatchSize)
+	require.NoError(t, err)
+	assert.Equal(t, 1, recovered)
+	repo.AssertExpectations(t)
+	product.AssertExpectations(t)
+	repo.AssertNotCalled(t, "UpdateSagaStatus", mock.Anything, testSagaID, entity.SagaPaymentCreated, entity.SagaFailed, mock.Anything)
+}
+
+func TestRecoverSagasPaidOrderWithoutStockFailsLoudly(t *testing.T) {
+	repo, product := new(MockOrderRepository), new(MockProductClient)
+	saga := newStaleSaga()
+	saga.Attempts = 2
+	svc := newTestSagaService(repo, product, saga)
+
+	// The order was paid in the meantime, so it is no longer pending
+	product.On("CommitInventory", mock.Anything, commitRequest).Return(nil, errReservationGone).Once()
+	repo.On("UpdateSagaReservation", mock.Anything, testSagaID, entity.SagaPaymentCreated, mock.Anything).Return(nil).Once()
+	product.On("ReserveInventory", mock.Anything, mock.Anything).Return(nil, errOutOfStock).Once()
+	repo.On("CompensateOrder", mock.Anything, testSagaID, entity.SagaPaymentCreated, testOrderID, mock.Anything, mock.Anything).Return(errors.New("order status changed")).Once()
+	repo.On("UpdateSagaStatus", mock.Anything, testSagaID, entity.SagaPaymentCreated, entity.SagaFailed, mock.Anything).Return(nil).Once()
+
+	recovered, err := svc.RecoverSagas(context.Background(), testBatchSize)
+	require.NoError(t, err)
+	assert.Equal(t, 0, recovered)
+	repo.AssertExpectations(t)
+	product.AssertNotCalled(t, "ReleaseInventory", mock.Anything, mock.Anything)
+}
+
+func TestRecoverSagasRetriesTransientCommitFailure(t *testing.T) {
+	repo, product := new(MockOrderRepository), new(MockProductClient)
+	svc := newTestSagaService(repo, product, newStaleSaga())
+
+	product.On("CommitInventory", mock.Anything, commitRequest).Return(nil, status.Error(codes.Unavailable, "connection refused"))
+
+	recovered, err := svc.RecoverSagas(context.Background(), testBatchSize)
+	require.NoError(t, err)
+	assert.Equal(t, 0, recovered)
+	repo.AssertNotCalled(t, "CompensateOrder", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "UpdateSagaStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	product.AssertNotCalled(t, "ReserveInventory", mock.Anything, mock.Anything)
+}
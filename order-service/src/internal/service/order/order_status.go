@@ -0,0 +1,176 @@
+package order
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+	"github.com/linggaaskaedo/go-kill/order-service/src/internal/model/dto"
+	"github.com/linggaaskaedo/go-kill/order-service/src/internal/model/entity"
+	"github.com/linggaaskaedo/go-kill/order-service/src/internal/repository/order"
+
+	"github.com/openpcc/openpcc/uuidv7"
+)
+
+// orderTransitions lists the statuses an order may move to from each status.
+// Delivered and cancelled are final.
+var orderTransitions = map[entity.OrderStatus][]entity.OrderStatus{
+	entity.StatusPending:    {entity.StatusConfirmed, entity.StatusCancelled},
+	entity.StatusConfirmed:  {entity.StatusProcessing, entity.StatusCancelled},
+	entity.StatusProcessing: {entity.StatusShipped},
+	entity.StatusShipped:    {entity.StatusDelivered},
+}
+
+func canTransition(from, to entity.OrderStatus) bool {
+	return slices.Contains(orderTransitions[from], to)
+}
+
+func isKnownStatus(status entity.OrderStatus) bool {
+	switch status {
+	case entity.StatusPending, entity.StatusConfirmed, entity.StatusProcessing,
+		entity.StatusShipped, entity.StatusDelivered, entity.StatusCancelled:
+		return true
+	}
+
+	return false
+}
+
+func (s *orderService) UpdateOrderStatus(ctx context.Context, reqData *dto.UpdateOrderStatusRequest) (entity.OrderStatus, error) {
+	if reqData.Actor == "" {
+		return "", x.NewWithCode(x.CodeHTTPBadRequest, "Actor is required")
+	}
+
+	to := entity.OrderStatus(reqData.Status)
+	if !isKnownStatus(to) {
+		return "", x.NewWithCode(x.CodeHTTPBadRequest, "Unknown order status "+reqData.Status)
+	}
+
+	current, err := s.orderRepository.GetOrderByID(ctx, reqData.OrderID)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.transitionOrder(ctx, current, to, reqData.Actor, reqData.Note); err != nil {
+		return "", err
+	}
+
+	return current.Status, nil
+}
+
+// transitionOrder validates one status change and settles the payment it
+// implies: confirming authorizes the payment, shipping captures it and
+// cancelling refunds it.
+func (s *orderService) transitionOrder(ctx context.Context, current *entity.Order, to entity.OrderStatus, actor, note string) error {
+	if !canTransition(current.Status, to) {
+		return x.NewWithCode(x.CodeHTTPConflict, fmt.Sprintf("Order cannot move from %s to %s", current.Status, to))
+	}
+
+	switch to {
+	case entity.StatusConfirmed:
+		status, err := s.authorizePayment(ctx, current, actor, note)
+		if err != nil {
+			return err
+		}
+
+		return authorizationError(status)
+	case entity.StatusShipped:
+		payment, events, err := s.capturePayment(ctx, current)
+		if err != nil {
+			return err
+		}
+
+		return s.applyTransition(ctx, current, to, actor, note, payment, events)
+	case entity.StatusCancelled:
+		payment, err := s.refundPayment(ctx, current)
+		if err != nil {
+			return err
+		}
+
+		return s.applyTransition(ctx, current, to, actor, note, payment, nil)
+	default:
+		return s.applyTransition(ctx, current, to, actor, note, nil, nil)
+	}
+}
+
+// applyTransition writes a status change that has already been validated.
+// Every change writes a history row and an order.updated event; cancelling
+// also emits order.cancelled and hands the stock back before the change
+// commits. payment and extra events, when given, commit with it.
+func (s *orderService) applyTransition(
+	ctx context.Context,
+	current *entity.Order,
+	to entity.OrderStatus,
+	actor, note string,
+	payment *entity.PaymentStatusChange,
+	extra []*entity.OutboxMessage,
+) error {
+	events, err := s.newStatusEvents(current, to, actor, note)
+	if err != nil {
+		return err
+	}
+
+	var returnStock order.ReturnStockFunc
+	if to == entity.StatusCancelled {
+		returnStock = func(ctx context.Context, items []*entity.OrderItem) error {
+			return s.returnStock(ctx, current.ID, items)
+		}
+	}
+
+	return s.orderRepository.UpdateOrderStatus(ctx, &entity.OrderStatusChange{
+		OrderID: current.ID,
+		From:    current.Status,
+		To:      to,
+		Actor:   actor,
+		Note:    note,
+		Payment: payment,
+	}, append(events, extra...), returnStock)
+}
+
+func (s *orderService) newStatusEvents(current *entity.Order, to entity.OrderStatus, actor, note string) ([]*entity.OutboxMessage, error) {
+	updated, err := newOutboxMessage(s.orderOptions.TopicOrderUpdated, dto.OrderEvent{
+		EventID:   uuidv7.MustNew().String(),
+		EventType: "order.updated",
+		Version:   "1.0",
+		Timestamp: time.Now(),
+		Source:    "order-service",
+		Data: dto.OrderData{
+			OrderID:        current.ID,
+			OrderNumber:    current.OrderNumber,
+			UserID:         current.UserID,
+			TotalAmount:    current.TotalAmount,
+			Status:         string(to),
+			PreviousStatus: string(current.Status),
+			UpdatedBy:      actor,
+			Note:           note,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if to != entity.StatusCancelled {
+		return []*entity.OutboxMessage{updated}, nil
+	}
+
+	cancelled, err := newOutboxMessage(s.orderOptions.TopicOrderCanceled, dto.OrderEvent{
+		EventID:   uuidv7.MustNew().String(),
+		EventType: "order.cancelled",
+		Version:   "1.0",
+		Timestamp: time.Now(),
+		Source:    "order-service",
+		Data: dto.OrderData{
+			OrderID:     current.ID,
+			OrderNumber: current.OrderNumber,
+			UserID:      current.UserID,
+			TotalAmount: current.TotalAmount,
+			Status:      string(entity.StatusCancelled),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return []*entity.OutboxMessage{updated, cancelled}, nil
+}
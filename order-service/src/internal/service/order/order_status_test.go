@@ -0,0 +1,291 @@
+package order
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+	productpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/product"
+	"github.com/linggaaskaedo/go-kill/order-service/src/internal/model/dto"
+	"github.com/linggaaskaedo/go-kill/order-service/src/internal/model/entity"
+	provider "github.com/linggaaskaedo/go-kill/order-service/src/internal/provider/payment"
+	"github.com/linggaaskaedo/go-kill/order-service/src/internal/repository/order"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testPaymentID     = "payment-1"
+	testTransactionID = "fake_payment-1"
+	testActor         = "admin-1"
+	testNote          = "Checked by support"
+)
+
+var allStatuses = []entity.OrderStatus{
+	entity.StatusPending, entity.StatusConfirmed, entity.StatusProcessing,
+	entity.StatusShipped, entity.StatusDelivered, entity.StatusCancelled,
+}
+
+// legalTransitions is the order lifecycle spelled out, independent of the
+// orderTransitions table it checks.
+var legalTransitions = map[[2]entity.OrderStatus]bool{
+	{entity.StatusPending, entity.StatusConfirmed}:    true,
+	{entity.StatusPending, entity.StatusCancelled}:    true,
+	{entity.StatusConfirmed, entity.StatusProcessing}: true,
+	{entity.StatusConfirmed, entity.StatusCancelled}:  true,
+	{entity.StatusProcessing, entity.StatusShipped}:   true,
+	{entity.StatusShipped, entity.StatusDelivered}:    true,
+}
+
+func newTestOrder(status entity.OrderStatus) *entity.Order {
+	return &entity.Order{
+		ID:          testOrderID,
+		UserID:      "user-1",
+		OrderNumber: "ORD-20261017-000001",
+		Status:      status,
+		TotalAmount: 150,
+	}
+}
+
+func newTestPayment(status entity.PaymentStatus) *entity.Payment {
+	payment := &entity.Payment{
+		ID:            testPaymentID,
+		OrderID:       testOrderID,
+		PaymentMethod: "credit_card",
+		Amount:        150,
+		Status:        status,
+	}
+	if status != entity.PaymentPending {
+		transactionID := testTransactionID
+		payment.TransactionID = &transactionID
+	}
+
+	return payment
+}
+
+func newTestStatusService(repo *MockOrderRepository, product *MockProductClient, payments provider.PaymentProvider) *orderService {
+	return &orderService{
+		orderRepository: repo,
+		productClient:   product,
+		paymentProvider: payments,
+		orderOptions: Options{
+			TopicOrderUpdated:     "order.updated",
+			TopicOrderCanceled:    "order.cancelled",
+			TopicPaymentCompleted: "payment.completed",
+			TopicPaymentFailed:    "payment.failed",
+		},
+	}
+}
+
+// statusUpdate captures what UpdateOrderStatus was asked to write.
+type statusUpdate struct {
+	change      *entity.OrderStatusChange
+	events      []*entity.OutboxMessage
+	returnStock order.ReturnStockFunc
+}
+
+func expectStatusUpdate(repo *MockOrderRepository) *statusUpdate {
+	update := &statusUpdate{}
+	repo.On("UpdateOrderStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			update.change = args.Get(1).(*entity.OrderStatusChange)
+			update.events = args.Get(2).([]*entity.OutboxMessage)
+			update.returnStock = args.Get(3).(order.ReturnStockFunc)
+		}).
+		Return(nil).Once()
+	return update
+}
+
+func eventTypes(events []*entity.OutboxMessage) []string {
+	types := make([]string, len(events))
+	for i, event := range events {
+		types[i] = event.EventType
+	}
+	return types
+}
+
+func decodeOrderEvent(t *testing.T, msg *entity.OutboxMessage) dto.OrderEvent {
+	t.Helper()
+
+	var event dto.OrderEvent
+	require.NoError(t, json.Unmarshal(msg.Payload, &event))
+	return event
+}
+
+func TestCanTransition(t *testing.T) {
+	for _, from := range allStatuses {
+		for _, to := range allStatuses {
+			want := legalTransitions[[2]entity.OrderStatus{from, to}]
+			assert.Equal(t, want, canTransition(from, to), "%s -> %s", from, to)
+		}
+	}
+}
+
+func TestTransitionOrderRejectsIllegalMoves(t *testing.T) {
+	for _, from := range allStatuses {
+		for _, to := range allStatuses {
+			if legalTransitions[[2]entity.OrderStatus{from, to}] {
+				continue
+			}
+
+			t.Run(string(from)+"_to_"+string(to), func(t *testing.T) {
+				repo, product := new(MockOrderRepository), new(MockProductClient)
+				svc := newTestStatusService(repo, product, provider.NewFakeProvider(provider.FakeOptions{}))
+
+				err := svc.transitionOrder(context.Background(), newTestOrder(from), to, testActor, testNote)
+				require.Error(t, err)
+				assert.Equal(t, x.CodeHTTPConflict, x.ErrCode(err))
+
+				// Nothing is read, charged or written for a refused move
+				repo.AssertNotCalled(t, "GetPaymentByOrderID", mock.Anything, mock.Anything)
+				repo.AssertNotCalled(t, "UpdateOrderStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			})
+		}
+	}
+}
+
+func TestTransitionOrderAppliesLegalMoves(t *testing.T) {
+	tests := []struct {
+		from, to    entity.OrderStatus
+		payment     entity.PaymentStatus
+		wantPayment *entity.PaymentStatusChange
+		wantEvents  []string
+	}{
+		{
+			from: entity.StatusPending, to: entity.StatusConfirmed,
+			payment:     entity.PaymentPending,
+			wantPayment: &entity.PaymentStatusChange{From: entity.PaymentPending, To: entity.PaymentAuthorized},
+			wantEvents:  []string{"order.updated"},
+		},
+		{
+			from: entity.StatusConfirmed, to: entity.StatusProcessing,
+			wantEvents: []string{"order.updated"},
+		},
+		{
+			from: entity.StatusProcessing, to: entity.StatusShipped,
+			payment:     entity.PaymentAuthorized,
+			wantPayment: &entity.PaymentStatusChange{From: entity.PaymentAuthorized, To: entity.PaymentCompleted},
+			wantEvents:  []string{"order.updated", "payment.completed"},
+		},
+		{
+			from: entity.StatusShipped, to: entity.StatusDelivered,
+			wantEvents: []string{"order.updated"},
+		},
+		{
+			from: entity.StatusPending, to: entity.StatusCancelled,
+			payment:     entity.PaymentPending,
+			wantPayment: &entity.PaymentStatusChange{From: entity.PaymentPending, To: entity.PaymentFailed},
+			wantEvents:  []string{"order.updated", "order.cancelled"},
+		},
+		{
+			from: entity.StatusConfirmed, to: entity.StatusCancelled,
+			payment:     entity.PaymentAuthorized,
+			wantPayment: &entity.PaymentStatusChange{From: entity.PaymentAuthorized, To: entity.PaymentRefunded},
+			wantEvents:  []string{"order.updated", "order.cancelled"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"_to_"+string(tt.to), func(t *testing.T) {
+			require.True(t, legalTransitions[[2]entity.OrderStatus{tt.from, tt.to}])
+
+			repo, product := new(MockOrderRepository), new(MockProductClient)
+			svc := newTestStatusService(repo, product, provider.NewFakeProvider(provider.FakeOptions{}))
+
+			if tt.payment != "" {
+				repo.On("GetPaymentByOrderID", mock.Anything, testOrderID).Return(newTestPayment(tt.payment), nil).Once()
+			}
+			update := expectStatusUpdate(repo)
+
+			require.NoError(t, svc.transitionOrder(context.Background(), newTestOrder(tt.from), tt.to, testActor, testNote))
+			repo.AssertExpectations(t)
+
+			// The change carries what the history row records
+			require.NotNil(t, update.change)
+			assert.Equal(t, testOrderID, update.change.OrderID)
+			assert.Equal(t, tt.from, update.change.From)
+			assert.Equal(t, tt.to, update.change.To)
+			assert.Equal(t, testActor, update.change.Actor)
+			assert.Equal(t, testNote, update.change.Note)
+
+			if tt.wantPayment == nil {
+				assert.Nil(t, update.change.Payment)
+			} else {
+				require.NotNil(t, update.change.Payment)
+				assert.Equal(t, testPaymentID, update.change.Payment.PaymentID)
+				assert.Equal(t, tt.wantPayment.From, update.change.Payment.From)
+				assert.Equal(t, tt.wantPayment.To, update.change.Payment.To)
+			}
+
+			require.Equal(t, tt.wantEvents, eventTypes(update.events))
+			for _, event := range update.events {
+				assert.Equal(t, event.EventType, event.Topic)
+				assert.Equal(t, testOrderID, event.AggregateID)
+				assert.Equal(t, "user-1", event.MessageKey)
+				assert.Equal(t, entity.OutboxPending, event.Status)
+			}
+
+			updated := decodeOrderEvent(t, update.events[0])
+			assert.Equal(t, string(tt.to), updated.Data.Status)
+			assert.Equal(t, string(tt.from), updated.Data.PreviousStatus)
+			assert.Equal(t, testActor, updated.Data.UpdatedBy)
+			assert.Equal(t, testNote, updated.Data.Note)
+
+			if tt.to == entity.StatusCancelled {
+				cancelled := decodeOrderEvent(t, update.events[1])
+				assert.Equal(t, string(entity.StatusCancelled), cancelled.Data.Status)
+				assert.NotNil(t, update.returnStock)
+			} else {
+				assert.Nil(t, update.returnStock)
+			}
+		})
+	}
+}
+
+func TestTransitionOrderCancelReturnsStockBeforeCommit(t *testing.T) {
+	items := []*entity.OrderItem{{ProductID: "product-1", Quantity: 2}}
+
+	tests := []struct {
+		name    string
+		saga    *entity.OrderSaga
+		sagaErr error
+		expect  func(product *MockProductClient)
+	}{
+		{
+			name: "committed stock is restocked",
+			saga: &entity.OrderSaga{ID: testSagaID, Status: entity.SagaCompleted},
+			expect: func(product *MockProductClient) {
+				product.On("RestockInventory", mock.Anything, mock.Anything).Return(&productpb.RestockInventoryResponse{Success: true}, nil).Once()
+			},
+		},
+		{
+			name:    "orders without a saga release their hold",
+			sagaErr: x.NewWithCode(x.CodeSQLRecordDoesNotExist, "Saga not found"),
+			expect: func(product *MockProductClient) {
+				product.On("ReleaseInventory", mock.Anything, mock.Anything).Return(&productpb.ReleaseInventoryResponse{Success: true}, nil).Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, product := new(MockOrderRepository), new(MockProductClient)
+			svc := newTestStatusService(repo, product, provider.NewFakeProvider(provider.FakeOptions{}))
+
+			repo.On("GetPaymentByOrderID", mock.Anything, testOrderID).Return(newTestPayment(entity.PaymentPending), nil).Once()
+			repo.On("GetSagaByOrderID", mock.Anything, testOrderID).Return(tt.saga, tt.sagaErr).Once()
+			tt.expect(product)
+			update := expectStatusUpdate(repo)
+
+			require.NoError(t, svc.transitionOrder(context.Background(), newTestOrder(entity.StatusPending), entity.StatusCancelled, testActor, testNote))
+
+			// The repository runs it inside the cancelling transaction
+			require.NotNil(t, update.returnStock)
+			require.NoError(t, update.returnStock(context.Background(), items))
+			product.AssertExpectations(t)
+		})
+	}
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"github.com/linggaaskaedo/go-kill/common/component/kafkaproducer"
+	"github.com/linggaaskaedo/go-kill/order-service/src/internal/provider/payment"
 	"github.com/linggaaskaedo/go-kill/order-service/src/internal/repository"
 	"github.com/linggaaskaedo/go-kill/order-service/src/internal/service/order"
 
@@ -13,16 +14,20 @@ type Service struct {
 }
 
 type Options struct {
-	OrderOpts order.Options `yaml:"order"`
+	OrderOpts   order.Options   `yaml:"order"`
+	PaymentOpts payment.Options `yaml:"payment"`
 }
 
-func InitService(repository *repository.Repository, userClientConn *grpc.ClientConn, productClientConn *grpc.ClientConn, kafkaProducer *kafkaproducer.KafkaProducerComponent) *Service {
+func InitService(repository *repository.Repository, authClientConn *grpc.ClientConn, userClientConn *grpc.ClientConn, productClientConn *grpc.ClientConn, kafkaProducer *kafkaproducer.KafkaProducerComponent, paymentProvider payment.PaymentProvider, opts Options) *Service {
 	return &Service{
 		Order: order.InitOrderService(
 			repository.Order,
+			authClientConn,
 			userClientConn,
 			productClientConn,
 			kafkaProducer,
+			paymentProvider,
+			opts.OrderOpts,
 		),
 	}
 }
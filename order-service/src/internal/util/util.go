@@ -72,3 +72,31 @@ func ToInventoryItemPB(original []*entity.OrderItem) []*productpb.InventoryItem
 
 	return result
 }
+
+func ToSagaItems(original []*dto.OrderItem) []entity.SagaItem {
+	result := make([]entity.SagaItem, 0, len(original))
+
+	for _, item := range original {
+		if item != nil {
+			result = append(result, entity.SagaItem{
+				ProductID: item.ProductID,
+				Quantity:  item.Quantity,
+			})
+		}
+	}
+
+	return result
+}
+
+func SagaItemsToInventoryItemPB(original []entity.SagaItem) []*productpb.InventoryItem {
+	result := make([]*productpb.InventoryItem, len(original))
+
+	for i, item := range original {
+		result[i] = &productpb.InventoryItem{
+			ProductId: item.ProductID,
+			Quantity:  item.Quantity,
+		}
+	}
+
+	return result
+}
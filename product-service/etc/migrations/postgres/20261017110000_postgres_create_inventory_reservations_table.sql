@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE inventory_reservations (
+    id UUID PRIMARY KEY DEFAULT uuidv7(),
+    reservation_id UUID NOT NULL,
+    product_id UUID NOT NULL,
+    quantity INT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_inventory_reservations_reservation_id ON inventory_reservations(reservation_id);
+CREATE INDEX idx_inventory_reservations_status_expires_at ON inventory_reservations(status, expires_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS inventory_reservations;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- A reservation holds each product once, so a retried reserve cannot take the
+-- stock twice. The unique index also serves lookups by reservation_id.
+ALTER TABLE inventory_reservations
+    ADD CONSTRAINT uq_inventory_reservations_reservation_product UNIQUE (reservation_id, product_id);
+
+DROP INDEX IF EXISTS idx_inventory_reservations_reservation_id;
+
+-- +goose Down
+CREATE INDEX idx_inventory_reservations_reservation_id ON inventory_reservations(reservation_id);
+
+ALTER TABLE inventory_reservations
+    DROP CONSTRAINT IF EXISTS uq_inventory_reservations_reservation_product;
@@ -57,4 +57,57 @@ WHERE product_id = $2;
 -- name: UpdateReleaseQuantity
 UPDATE inventory 
 SET reserved_quantity = GREATEST(0, reserved_quantity - $1), updated_at = NOW()
-WHERE product_id = $2;
\ No newline at end of file
+WHERE product_id = $2;
+
+-- name: UpdateCommitQuantity
+UPDATE inventory 
+SET quantity = quantity - $1, reserved_quantity = reserved_quantity - $1, updated_at = NOW()
+WHERE product_id = $2 AND reserved_quantity >= $1 AND quantity >= $1;
+
+-- name: UpdateRestockQuantity
+UPDATE inventory 
+SET quantity = quantity + $1, updated_at = NOW()
+WHERE product_id = $2;
+
+-- name: CreateInventoryReservation
+INSERT INTO inventory_reservations (reservation_id, product_id, quantity, status, expires_at, created_at, updated_at)
+VALUES($1, $2, $3, 'active', NOW() + make_interval(secs => $4), NOW(), NOW())
+ON CONFLICT (reservation_id, product_id) DO NOTHING
+RETURNING expires_at;
+
+-- name: GetInventoryReservation
+SELECT id, reservation_id, product_id, quantity, status, expires_at 
+FROM inventory_reservations 
+WHERE reservation_id = $1;
+
+-- name: UpdateReservationStatus
+UPDATE inventory_reservations 
+SET status = $1, updated_at = NOW()
+WHERE reservation_id = $2 AND status = 'active'
+RETURNING id, reservation_id, product_id, quantity, status, expires_at;
+
+-- name: CommitReservation
+UPDATE inventory_reservations 
+SET status = 'committed', updated_at = NOW()
+WHERE reservation_id = $1 AND status = 'active'
+  AND NOT EXISTS (
+    SELECT 1 
+    FROM inventory_reservations 
+    WHERE reservation_id = $1 AND status <> 'active'
+  )
+RETURNING id, reservation_id, product_id, quantity, status, expires_at;
+
+-- name: UpdateExpiredReservations
+WITH expired AS (
+    SELECT reservation_id 
+    FROM inventory_reservations 
+    WHERE status = 'active' AND expires_at <= NOW() 
+    GROUP BY reservation_id 
+    ORDER BY MIN(expires_at) 
+    LIMIT $1
+)
+UPDATE inventory_reservations r 
+SET status = 'expired', updated_at = NOW()
+FROM expired e
+WHERE r.reservation_id = e.reservation_id AND r.status = 'active'
+RETURNING r.id, r.reservation_id, r.product_id, r.quantity, r.status, r.expires_at;
@@ -3,27 +3,27 @@ package main
 import (
 	"context"
 	"flag"
-	"fmt"
-	"os/signal"
-	"syscall"
 	"time"
 
 	"github.com/linggaaskaedo/go-kill/common/app"
 	"github.com/linggaaskaedo/go-kill/common/component/database"
+	"github.com/linggaaskaedo/go-kill/common/component/grpcclient"
 	"github.com/linggaaskaedo/go-kill/common/component/grpcserver"
 	"github.com/linggaaskaedo/go-kill/common/component/http"
 	"github.com/linggaaskaedo/go-kill/common/component/query"
 	"github.com/linggaaskaedo/go-kill/common/component/redis"
 	"github.com/linggaaskaedo/go-kill/common/component/scheduler"
 	"github.com/linggaaskaedo/go-kill/common/component/server"
+	"github.com/linggaaskaedo/go-kill/common/component/tracer"
+	commonConfig "github.com/linggaaskaedo/go-kill/common/pkg/config"
 	"github.com/linggaaskaedo/go-kill/common/pkg/logger"
+	"github.com/linggaaskaedo/go-kill/common/pkg/metrics"
 	"github.com/linggaaskaedo/go-kill/common/pkg/middleware"
 	productpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/product"
 	"github.com/linggaaskaedo/go-kill/product-service/src/internal/config"
 	restHandler "github.com/linggaaskaedo/go-kill/product-service/src/internal/handler/rest"
 	sched "github.com/linggaaskaedo/go-kill/product-service/src/internal/handler/scheduler"
 
-	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
 )
 
@@ -45,6 +45,8 @@ var (
 // @host			localhost:8080
 // @schemes		http https
 func main() {
+	src := commonConfig.Source{File: "config.yaml", EnvPrefix: "PRODUCT"}
+	src.RegisterFlags(flag.CommandLine)
 	flag.IntVar(&minJitter, "minSleep", DefaultMinJitter, "min. sleep duration during app initialization")
 	flag.IntVar(&maxJitter, "maxSleep", DefaultMaxJitter, "max. sleep duration during app initialization")
 	flag.Parse()
@@ -53,7 +55,7 @@ func main() {
 	sleepWithJitter(minJitter, maxJitter)
 
 	// Load config
-	cfg, err := config.Load("config.yaml")
+	cfg, err := config.Load(src)
 	if err != nil {
 		panic(err)
 	}
@@ -61,123 +63,67 @@ func main() {
 	// Initialize logger
 	log := logger.Init(cfg.Logger)
 
+	// Label the metrics with the service name
+	metrics.Init("product-service")
+
 	log.Info().Msg("Starting product service...")
 
-	// Create application with options
-	appSubComp := app.New(app.WithShutdownTimeout(15*time.Second), app.WithLogger(log))
-	appMainComp := app.New(app.WithShutdownTimeout(15*time.Second), app.WithLogger(log))
+	// Create application with options. The database can take a while to
+	// accept connections, so components get 30s to become ready.
+	a := app.New(app.WithShutdownTimeout(10*time.Second), app.WithReadyTimeout(30*time.Second), app.WithLogger(log))
+
+	// Added first so that it stops last, exporting the spans of shutdown
+	a.Add(tracer.NewTracerComponent(log, cfg.Tracer))
+
+	// Apply the reloadable settings when the config changes
+	watcher := commonConfig.NewWatcher(log, src, cfg)
+	watcher.Subscribe(func(cfg *config.Config) {
+		logger.SetLevel(cfg.Logger.Level)
+	})
+	a.Add(watcher)
 
-	// Initialize redis component
 	redisComp0 := redis.NewRedisComponent(log, cfg.Redis)
-	appSubComp.Add(redisComp0, 10*time.Second)
+	a.Add(redisComp0)
 
-	// Initialize database component
 	dbComp0 := database.NewDatabaseComponent(log, cfg.Database["db-0"])
-	if dbComp0 != nil {
-		appSubComp.Add(dbComp0, 10*time.Second)
-	}
+	a.Add(dbComp0)
 
-	// Initialize query loader component
 	queryComp := query.NewQueryComponent(log, cfg.Query)
-	appSubComp.Add(queryComp, 10*time.Second)
-
-	// Initialze middleware
-	mw := middleware.Init(log)
-
-	// Initialize Gin engine
-	gin := http.Init(log, mw, cfg.Http)
-
-	var independent []app.Component
-	if redisComp0 != nil {
-		independent = append(independent, redisComp0)
-	}
-	if dbComp0 != nil {
-		independent = append(independent, dbComp0)
-	}
-	if queryComp != nil {
-		independent = append(independent, queryComp)
-	}
+	a.Add(queryComp)
 
-	if len(independent) == 0 {
-		log.Fatal().Msg("no independent components to start")
-	}
-
-	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
-	defer stop()
-
-	indepGroup, indepCtx := errgroup.WithContext(ctx)
-	for _, comp := range independent {
-		indepGroup.Go(func() error {
-			return comp.Start(indepCtx)
-		})
-	}
-
-	startupCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
-	defer cancel()
-
-	for _, comp := range independent {
-		select {
-		case <-comp.Ready():
-			log.Info().Str("component", fmt.Sprintf("%T", comp)).Msg("ready")
-		case <-startupCtx.Done():
-			log.Fatal().Msgf("timeout waiting for component %T", comp)
-		}
-	}
+	// Validates tokens and API keys for the product write routes
+	authClientComp := grpcclient.NewGRPCClientComponent(log, cfg.GRPCClient["auth_service"])
+	a.Add(authClientComp, app.Name("auth_service"))
 
-	// Now build the service component (which depends on database, mongo, query, etc.)
-	serviceComp := config.NewServiceComponent(log, dbComp0, queryComp, redisComp0)
-	appMainComp.Add(serviceComp, 10*time.Second)
+	serviceComp := config.NewServiceComponent(log, dbComp0, queryComp, redisComp0, authClientComp, cfg.Service)
+	a.Add(serviceComp, app.DependsOn(redisComp0, dbComp0, queryComp, authClientComp))
 
-	// Initialize scheduler component
 	schedComp := scheduler.NewSchedulerComponent(log, func() ([]scheduler.Job, error) {
-		select {
-		case <-serviceComp.Ready():
-			productGenJob := sched.NewProductGeneratorJob(log, serviceComp.Service().Product, cfg.Scheduler["job-0"])
-			return []scheduler.Job{productGenJob}, nil
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		case <-time.After(10 * time.Second):
-			return nil, fmt.Errorf("timeout waiting for scheduler")
-		}
+		productGenJob := sched.NewProductGeneratorJob(log, serviceComp.Service().Product, cfg.Scheduler["job-0"])
+		reservationExpiryJob := sched.NewReservationExpiryJob(log, serviceComp.Service().Product, cfg.Scheduler["job-1"])
+		return []scheduler.Job{productGenJob, reservationExpiryJob}, nil
 	})
-	if schedComp != nil {
-		appMainComp.Add(schedComp, 10*time.Second)
-	}
+	a.Add(schedComp, app.DependsOn(serviceComp))
 
-	// Now build gRPC server (depends on service)
 	grpcServerComp := grpcserver.NewGRPCServerComponent(log, cfg.GRPCServer, func(ctx context.Context, s *grpc.Server) error {
-		select {
-		case <-serviceComp.Ready():
-			productpb.RegisterProductServiceServer(s, serviceComp.GrpcHandler())
-			return nil
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-time.After(10 * time.Second):
-			return fmt.Errorf("timeout waiting for gRPC Server")
-		}
+		productpb.RegisterProductServiceServer(s, serviceComp.GrpcHandler())
+		return nil
 	})
-	appMainComp.Add(grpcServerComp, 10*time.Second)
+	grpcServerComp.SetHealthCheck(a.Serving)
+	a.Add(grpcServerComp, app.DependsOn(serviceComp))
+
+	mw := middleware.Init(log)
+	gin := http.Init(log, mw, cfg.Http)
 
-	// Build HTTP server (depends on service)
 	httpServerComp := server.NewHTTPServerComponent(log, cfg.Server, mw, gin, func(ctx context.Context, engine *server.Engine) error {
-		select {
-		case <-serviceComp.Ready():
-			restHandler.InitRestHandler(engine, serviceComp.Service())
-			return nil
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-time.After(10 * time.Second):
-			return fmt.Errorf("timeout waiting for HTTP Server")
-		}
+		server.RegisterHealth(engine, a)
+		server.RegisterMetrics(engine)
+		restHandler.InitRestHandler(engine, serviceComp.Service())
+		return nil
 	})
-	appMainComp.Add(httpServerComp, 10*time.Second)
+	a.Add(httpServerComp, app.DependsOn(serviceComp))
 
-	// Run the app – now all components are added and will start in the order they were added.
-	if err := appMainComp.Run(); err != nil {
+	if err := a.Run(); err != nil {
 		log.Fatal().Err(err).Msg("app failed")
 	}
-
-	if err := indepGroup.Wait(); err != nil && err != context.Canceled {
-		log.Error().Err(err).Msg("Independent component error")
-	}
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/linggaaskaedo/go-kill/common/component/database"
+	"github.com/linggaaskaedo/go-kill/common/component/grpcclient"
 	"github.com/linggaaskaedo/go-kill/common/component/query"
 	"github.com/linggaaskaedo/go-kill/common/component/redis"
 	grpcHandler "github.com/linggaaskaedo/go-kill/product-service/src/internal/handler/grpc"
@@ -14,10 +15,12 @@ import (
 )
 
 type ServiceComponent struct {
-	log        zerolog.Logger
-	dbComp0    *database.DatabaseComponent
-	queryComp  *query.QueryComponent
-	redisComp0 *redis.RedisComponent
+	log            zerolog.Logger
+	dbComp0        *database.DatabaseComponent
+	queryComp      *query.QueryComponent
+	redisComp0     *redis.RedisComponent
+	authClientComp *grpcclient.GRPCClientComponent
+	svcOpts        service.Options
 
 	repo        *repository.Repository
 	service     *service.Service
@@ -30,19 +33,23 @@ func NewServiceComponent(
 	dbComp0 *database.DatabaseComponent,
 	queryComp *query.QueryComponent,
 	redisComp0 *redis.RedisComponent,
+	authClientComp *grpcclient.GRPCClientComponent,
+	svcOpts service.Options,
 ) *ServiceComponent {
 	return &ServiceComponent{
-		log:        log,
-		dbComp0:    dbComp0,
-		queryComp:  queryComp,
-		redisComp0: redisComp0,
-		ready:      make(chan struct{}),
+		log:            log,
+		dbComp0:        dbComp0,
+		queryComp:      queryComp,
+		redisComp0:     redisComp0,
+		authClientComp: authClientComp,
+		svcOpts:        svcOpts,
+		ready:          make(chan struct{}),
 	}
 }
 
 func (s *ServiceComponent) Start(ctx context.Context) error {
 	s.repo = repository.InitRepository(s.dbComp0.Client(), s.queryComp, s.redisComp0.Client())
-	s.service = service.InitService(s.repo)
+	s.service = service.InitService(s.authClientComp.Conn(), s.repo, s.svcOpts)
 	s.grpcHandler = grpcHandler.InitGrpcHandler(s.log, s.service)
 
 	close(s.ready) // signal that service is ready
@@ -5,6 +5,9 @@ import (
 
 	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
 	productpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/product"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func (g *Grpc) GetProduct(ctx context.Context, req *productpb.GetProductRequest) (*productpb.GetProductResponse, error) {
@@ -52,15 +55,35 @@ func (g *Grpc) ReserveInventory(ctx context.Context, req *productpb.ReserveInven
 		return nil, x.New("Item is empty")
 	}
 
-	err := g.svc.Product.ReserveInventory(ctx, convertItems(req.Items))
+	reservation, err := g.svc.Product.ReserveInventory(ctx, req.ReservationId, convertItems(req.Items))
 	if err != nil {
+		// Neither missing stock nor a spent reservation id is fixed by a
+		// retry
+		switch x.ErrCode(err) {
+		case x.CodeHTTPUnprocessableEntity:
+			return nil, status.Error(codes.FailedPrecondition, "insufficient inventory")
+		case x.CodeHTTPConflict:
+			return nil, status.Error(codes.FailedPrecondition, "reservation is no longer active")
+		}
 		return nil, err
 	}
 
-	return &productpb.ReserveInventoryResponse{Success: true}, nil
+	return &productpb.ReserveInventoryResponse{
+		Success:       true,
+		ReservationId: reservation.ID,
+		ExpiresAt:     reservation.ExpiresAt.Unix(),
+	}, nil
 }
 
 func (g *Grpc) ReleaseInventory(ctx context.Context, req *productpb.ReleaseInventoryRequest) (*productpb.ReleaseInventoryResponse, error) {
+	if req.ReservationId != "" {
+		if err := g.svc.Product.ReleaseReservation(ctx, req.ReservationId); err != nil {
+			return nil, err
+		}
+
+		return &productpb.ReleaseInventoryResponse{Success: true}, nil
+	}
+
 	if req.Items == nil {
 		return nil, x.New("Item is empty")
 	}
@@ -72,3 +95,37 @@ func (g *Grpc) ReleaseInventory(ctx context.Context, req *productpb.ReleaseInven
 
 	return &productpb.ReleaseInventoryResponse{Success: true}, nil
 }
+
+func (g *Grpc) CommitInventory(ctx context.Context, req *productpb.CommitInventoryRequest) (*productpb.CommitInventoryResponse, error) {
+	if req.ReservationId == "" {
+		return nil, x.New("Reservation ID is empty")
+	}
+
+	err := g.svc.Product.CommitInventory(ctx, req.ReservationId)
+	if err != nil {
+		// A hold that expired or was released cannot be committed by a
+		// retry, so tell the caller apart from a transient failure
+		switch x.ErrCode(err) {
+		case x.CodeSQLRecordDoesNotMatch:
+			return nil, status.Error(codes.FailedPrecondition, "reservation is no longer active")
+		case x.CodeSQLRecordDoesNotExist:
+			return nil, status.Error(codes.NotFound, "reservation not found")
+		}
+		return nil, err
+	}
+
+	return &productpb.CommitInventoryResponse{Success: true}, nil
+}
+
+func (g *Grpc) RestockInventory(ctx context.Context, req *productpb.RestockInventoryRequest) (*productpb.RestockInventoryResponse, error) {
+	if req.Items == nil {
+		return nil, x.New("Item is empty")
+	}
+
+	err := g.svc.Product.RestockInventory(ctx, convertItems(req.Items))
+	if err != nil {
+		return nil, err
+	}
+
+	return &productpb.RestockInventoryResponse{Success: true}, nil
+}
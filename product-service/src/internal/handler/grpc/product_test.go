@@ -4,7 +4,10 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+	authpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/auth"
 	productpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/product"
 	"github.com/linggaaskaedo/go-kill/product-service/src/internal/model/dto"
 	"github.com/linggaaskaedo/go-kill/product-service/src/internal/service"
@@ -13,6 +16,8 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 const (
@@ -22,6 +27,7 @@ const (
 	testProductPrice       = 99.99
 	testProductSKU         = "SKU-12345"
 	mockInventoryType      = "[]dto.CreateReserveInventory"
+	testReservationID      = "019a3b5c-7d2e-7f10-8a4b-1c2d3e4f5a6b"
 )
 
 type MockProductService struct {
@@ -81,9 +87,12 @@ func (m *MockProductService) CheckInventory(ctx context.Context, productID strin
 	return args.Get(0).(int32), args.Get(1).(int32), args.Error(2)
 }
 
-func (m *MockProductService) ReserveInventory(ctx context.Context, req []dto.CreateReserveInventory) error {
-	args := m.Called(ctx, req)
-	return args.Error(0)
+func (m *MockProductService) ReserveInventory(ctx context.Context, reservationID string, req []dto.CreateReserveInventory) (*dto.Reservation, error) {
+	args := m.Called(ctx, reservationID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.Reservation), args.Error(1)
 }
 
 func (m *MockProductService) ReleaseInventory(ctx context.Context, req []dto.CreateReserveInventory) error {
@@ -92,6 +101,42 @@ func (m *MockProductService) ReleaseInventory(ctx context.Context, req []dto.Cre
 	return args.Error(0)
 }
 
+func (m *MockProductService) ReleaseReservation(ctx context.Context, reservationID string) error {
+	args := m.Called(ctx, reservationID)
+	return args.Error(0)
+}
+
+func (m *MockProductService) CommitInventory(ctx context.Context, reservationID string) error {
+	args := m.Called(ctx, reservationID)
+	return args.Error(0)
+}
+
+func (m *MockProductService) RestockInventory(ctx context.Context, req []dto.CreateReserveInventory) error {
+	args := m.Called(ctx, req)
+	return args.Error(0)
+}
+
+func (m *MockProductService) ReleaseExpiredReservations(ctx context.Context, batchSize int) (int, error) {
+	args := m.Called(ctx, batchSize)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockProductService) ValidateToken(ctx context.Context, req *authpb.ValidateTokenRequest) (*authpb.ValidateTokenResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*authpb.ValidateTokenResponse), args.Error(1)
+}
+
+func (m *MockProductService) ValidateApiKey(ctx context.Context, req *authpb.ValidateApiKeyRequest) (*authpb.ValidateTokenResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*authpb.ValidateTokenResponse), args.Error(1)
+}
+
 var _ product.ProductServiceItf = (*MockProductService)(nil)
 
 func setupTestGrpc(mockProduct *MockProductService) (*Grpc, *service.Service) {
@@ -258,7 +303,8 @@ func TestReserveInventorySuccess(t *testing.T) {
 	grpcHandler, _ := setupTestGrpc(mockProduct)
 	ctx := context.Background()
 
-	mockProduct.On("ReserveInventory", ctx, mock.AnythingOfType(mockInventoryType)).Return(nil)
+	expiresAt := time.Now().Add(15 * time.Minute)
+	mockProduct.On("ReserveInventory", ctx, "", mock.AnythingOfType(mockInventoryType)).Return(&dto.Reservation{ID: testReservationID, ExpiresAt: expiresAt}, nil)
 
 	req := &productpb.ReserveInventoryRequest{
 		Items: []*productpb.InventoryItem{
@@ -271,6 +317,29 @@ func TestReserveInventorySuccess(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 	assert.True(t, resp.Success)
+	assert.Equal(t, testReservationID, resp.ReservationId)
+	assert.Equal(t, expiresAt.Unix(), resp.ExpiresAt)
+	mockProduct.AssertExpectations(t)
+}
+
+func TestReserveInventoryWithReservationID(t *testing.T) {
+	mockProduct := new(MockProductService)
+	grpcHandler, _ := setupTestGrpc(mockProduct)
+	ctx := context.Background()
+
+	mockProduct.On("ReserveInventory", ctx, testReservationID, mock.AnythingOfType(mockInventoryType)).Return(&dto.Reservation{ID: testReservationID, ExpiresAt: time.Now()}, nil)
+
+	req := &productpb.ReserveInventoryRequest{
+		Items: []*productpb.InventoryItem{
+			{ProductId: testProductID, Quantity: 5},
+		},
+		ReservationId: testReservationID,
+	}
+
+	resp, err := grpcHandler.ReserveInventory(ctx, req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, testReservationID, resp.ReservationId)
 	mockProduct.AssertExpectations(t)
 }
 
@@ -280,7 +349,7 @@ func TestReserveInventoryError(t *testing.T) {
 	ctx := context.Background()
 
 	expectedErr := errors.New("failed to reserve inventory")
-	mockProduct.On("ReserveInventory", ctx, mock.AnythingOfType(mockInventoryType)).Return(expectedErr)
+	mockProduct.On("ReserveInventory", ctx, "", mock.AnythingOfType(mockInventoryType)).Return(nil, expectedErr)
 
 	req := &productpb.ReserveInventoryRequest{
 		Items: []*productpb.InventoryItem{
@@ -353,6 +422,44 @@ func TestReleaseInventoryError(t *testing.T) {
 	mockProduct.AssertExpectations(t)
 }
 
+func TestReleaseInventoryByReservationID(t *testing.T) {
+	mockProduct := new(MockProductService)
+	grpcHandler, _ := setupTestGrpc(mockProduct)
+	ctx := context.Background()
+
+	mockProduct.On("ReleaseReservation", ctx, testReservationID).Return(nil)
+
+	req := &productpb.ReleaseInventoryRequest{
+		ReservationId: testReservationID,
+	}
+
+	resp, err := grpcHandler.ReleaseInventory(ctx, req)
+
+	assert.NoError(t, err)
+	assert.True(t, resp.Success)
+	mockProduct.AssertExpectations(t)
+	mockProduct.AssertNotCalled(t, "ReleaseInventory", mock.Anything, mock.Anything)
+}
+
+func TestReleaseInventoryByReservationIDError(t *testing.T) {
+	mockProduct := new(MockProductService)
+	grpcHandler, _ := setupTestGrpc(mockProduct)
+	ctx := context.Background()
+
+	expectedErr := errors.New("failed to release reservation")
+	mockProduct.On("ReleaseReservation", ctx, testReservationID).Return(expectedErr)
+
+	req := &productpb.ReleaseInventoryRequest{
+		ReservationId: testReservationID,
+	}
+
+	resp, err := grpcHandler.ReleaseInventory(ctx, req)
+
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+	mockProduct.AssertExpectations(t)
+}
+
 func TestReleaseInventoryEmptyItems(t *testing.T) {
 	mockProduct := new(MockProductService)
 	grpcHandler, _ := setupTestGrpc(mockProduct)
@@ -369,6 +476,150 @@ func TestReleaseInventoryEmptyItems(t *testing.T) {
 	assert.Contains(t, err.Error(), "Item is empty")
 }
 
+func TestCommitInventorySuccess(t *testing.T) {
+	mockProduct := new(MockProductService)
+	grpcHandler, _ := setupTestGrpc(mockProduct)
+	ctx := context.Background()
+
+	mockProduct.On("CommitInventory", ctx, testReservationID).Return(nil)
+
+	req := &productpb.CommitInventoryRequest{
+		ReservationId: testReservationID,
+	}
+
+	resp, err := grpcHandler.CommitInventory(ctx, req)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, resp)
+	assert.True(t, resp.Success)
+	mockProduct.AssertExpectations(t)
+}
+
+func TestCommitInventoryError(t *testing.T) {
+	mockProduct := new(MockProductService)
+	grpcHandler, _ := setupTestGrpc(mockProduct)
+	ctx := context.Background()
+
+	expectedErr := errors.New("failed to commit inventory")
+	mockProduct.On("CommitInventory", ctx, testReservationID).Return(expectedErr)
+
+	req := &productpb.CommitInventoryRequest{
+		ReservationId: testReservationID,
+	}
+
+	resp, err := grpcHandler.CommitInventory(ctx, req)
+
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+	mockProduct.AssertExpectations(t)
+}
+
+func TestReserveInventoryOutOfStock(t *testing.T) {
+	mockProduct := new(MockProductService)
+	grpcHandler, _ := setupTestGrpc(mockProduct)
+	ctx := context.Background()
+
+	spentID := "019d227d-6eac-749c-b935-263bddc5a640"
+	mockProduct.On("ReserveInventory", ctx, testReservationID, mock.Anything).Return(nil, x.NewWithCode(x.CodeHTTPUnprocessableEntity, "Insufficient inventory"))
+	mockProduct.On("ReserveInventory", ctx, spentID, mock.Anything).Return(nil, x.NewWithCode(x.CodeHTTPConflict, "Reservation is no longer active"))
+
+	items := []*productpb.InventoryItem{{ProductId: "product-1", Quantity: 5}}
+
+	_, err := grpcHandler.ReserveInventory(ctx, &productpb.ReserveInventoryRequest{ReservationId: testReservationID, Items: items})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, err = grpcHandler.ReserveInventory(ctx, &productpb.ReserveInventoryRequest{ReservationId: spentID, Items: items})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestCommitInventoryReservationGone(t *testing.T) {
+	mockProduct := new(MockProductService)
+	grpcHandler, _ := setupTestGrpc(mockProduct)
+	ctx := context.Background()
+
+	expiredID := "019d227d-6eac-749c-b935-263bddc5a640"
+	mockProduct.On("CommitInventory", ctx, expiredID).Return(x.NewWithCode(x.CodeSQLRecordDoesNotMatch, "Reservation is no longer active"))
+	mockProduct.On("CommitInventory", ctx, testReservationID).Return(x.NewWithCode(x.CodeSQLRecordDoesNotExist, "Reservation not found"))
+
+	_, err := grpcHandler.CommitInventory(ctx, &productpb.CommitInventoryRequest{ReservationId: expiredID})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, err = grpcHandler.CommitInventory(ctx, &productpb.CommitInventoryRequest{ReservationId: testReservationID})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestCommitInventoryEmptyReservationID(t *testing.T) {
+	mockProduct := new(MockProductService)
+	grpcHandler, _ := setupTestGrpc(mockProduct)
+	ctx := context.Background()
+
+	req := &productpb.CommitInventoryRequest{}
+
+	resp, err := grpcHandler.CommitInventory(ctx, req)
+
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+	assert.Contains(t, err.Error(), "Reservation ID is empty")
+}
+
+func TestRestockInventorySuccess(t *testing.T) {
+	mockProduct := new(MockProductService)
+	grpcHandler, _ := setupTestGrpc(mockProduct)
+	ctx := context.Background()
+
+	mockProduct.On("RestockInventory", ctx, mock.AnythingOfType(mockInventoryType)).Return(nil)
+
+	req := &productpb.RestockInventoryRequest{
+		Items: []*productpb.InventoryItem{
+			{ProductId: testProductID, Quantity: 10},
+		},
+	}
+
+	resp, err := grpcHandler.RestockInventory(ctx, req)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, resp)
+	assert.True(t, resp.Success)
+	mockProduct.AssertExpectations(t)
+}
+
+func TestRestockInventoryError(t *testing.T) {
+	mockProduct := new(MockProductService)
+	grpcHandler, _ := setupTestGrpc(mockProduct)
+	ctx := context.Background()
+
+	expectedErr := errors.New("failed to restock inventory")
+	mockProduct.On("RestockInventory", ctx, mock.AnythingOfType(mockInventoryType)).Return(expectedErr)
+
+	req := &productpb.RestockInventoryRequest{
+		Items: []*productpb.InventoryItem{
+			{ProductId: testProductID, Quantity: 10},
+		},
+	}
+
+	resp, err := grpcHandler.RestockInventory(ctx, req)
+
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+	mockProduct.AssertExpectations(t)
+}
+
+func TestRestockInventoryEmptyItems(t *testing.T) {
+	mockProduct := new(MockProductService)
+	grpcHandler, _ := setupTestGrpc(mockProduct)
+	ctx := context.Background()
+
+	req := &productpb.RestockInventoryRequest{
+		Items: nil,
+	}
+
+	resp, err := grpcHandler.RestockInventory(ctx, req)
+
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+	assert.Contains(t, err.Error(), "Item is empty")
+}
+
 func TestConvertItems(t *testing.T) {
 	items := []*productpb.InventoryItem{
 		{ProductId: "product-1", Quantity: 10},
@@ -3,10 +3,33 @@ package rest
 import (
 	"net/http"
 
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+	"github.com/linggaaskaedo/go-kill/product-service/src/internal/model/dto"
+
 	"github.com/gin-gonic/gin"
 	"github.com/openpcc/openpcc/uuidv7"
+	"github.com/rs/zerolog"
 )
 
+func (e *rest) handleCreateProduct(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req dto.AddProductRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("invalid_request_body")
+		e.httpRespError(c, x.WrapWithCode(err, x.CodeHTTPUnmarshal, "invalid_request_body"))
+		return
+	}
+
+	resp, err := e.svc.Product.CreateProduct(ctx, req.CreateProductRequest, req.Quantity, 0)
+	if err != nil {
+		e.httpRespError(c, err)
+		return
+	}
+
+	e.httpRespSuccess(c, http.StatusCreated, resp, nil)
+}
+
 func (e *rest) handleListProducts(c *gin.Context) {
 	ctx := c.Request.Context()
 
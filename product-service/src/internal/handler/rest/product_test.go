@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -8,6 +9,9 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/linggaaskaedo/go-kill/common/pkg/authz"
+	"github.com/linggaaskaedo/go-kill/common/pkg/preference"
+	authpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/auth"
 	"github.com/linggaaskaedo/go-kill/product-service/src/internal/model/dto"
 	"github.com/linggaaskaedo/go-kill/product-service/src/internal/service"
 	"github.com/linggaaskaedo/go-kill/product-service/src/internal/service/product"
@@ -80,16 +84,55 @@ func (m *MockProductService) CheckInventory(ctx context.Context, productID strin
 	return args.Get(0).(int32), args.Get(1).(int32), args.Error(2)
 }
 
-func (m *MockProductService) ReserveInventory(ctx context.Context, req []dto.CreateReserveInventory) error {
+func (m *MockProductService) ReserveInventory(ctx context.Context, reservationID string, req []dto.CreateReserveInventory) (*dto.Reservation, error) {
+	args := m.Called(ctx, reservationID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.Reservation), args.Error(1)
+}
+
+func (m *MockProductService) ReleaseInventory(ctx context.Context, req []dto.CreateReserveInventory) error {
 	args := m.Called(ctx, req)
 	return args.Error(0)
 }
 
-func (m *MockProductService) ReleaseInventory(ctx context.Context, req []dto.CreateReserveInventory) error {
+func (m *MockProductService) ReleaseReservation(ctx context.Context, reservationID string) error {
+	args := m.Called(ctx, reservationID)
+	return args.Error(0)
+}
+
+func (m *MockProductService) CommitInventory(ctx context.Context, reservationID string) error {
+	args := m.Called(ctx, reservationID)
+	return args.Error(0)
+}
+
+func (m *MockProductService) RestockInventory(ctx context.Context, req []dto.CreateReserveInventory) error {
 	args := m.Called(ctx, req)
 	return args.Error(0)
 }
 
+func (m *MockProductService) ReleaseExpiredReservations(ctx context.Context, batchSize int) (int, error) {
+	args := m.Called(ctx, batchSize)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockProductService) ValidateToken(ctx context.Context, req *authpb.ValidateTokenRequest) (*authpb.ValidateTokenResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*authpb.ValidateTokenResponse), args.Error(1)
+}
+
+func (m *MockProductService) ValidateApiKey(ctx context.Context, req *authpb.ValidateApiKeyRequest) (*authpb.ValidateTokenResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*authpb.ValidateTokenResponse), args.Error(1)
+}
+
 var _ product.ProductServiceItf = (*MockProductService)(nil)
 
 func setupTestRest(mockProduct *MockProductService) *rest {
@@ -106,6 +149,7 @@ func setupRouter(handler *rest) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	r := gin.New()
 
+	r.POST("/api/v1/products", handler.authMiddleware(authz.PermProductWrite), handler.handleCreateProduct)
 	r.GET("/api/v1/products", handler.handleListProducts)
 	r.GET("/api/v1/products/:id", handler.handleGetProduct)
 	r.GET("/api/v1/categories", handler.handleListCategories)
@@ -115,6 +159,81 @@ func setupRouter(handler *rest) *gin.Engine {
 	return r
 }
 
+func newAddProductBody() []byte {
+	body, _ := json.Marshal(dto.AddProductRequest{
+		CreateProductRequest: dto.CreateProductRequest{
+			Name:        testProductName,
+			Description: "A product",
+			Price:       10,
+			SKU:         "SKU-1",
+			IsActive:    true,
+		},
+		Quantity: 5,
+	})
+	return body
+}
+
+func TestHandleCreateProduct_Success(t *testing.T) {
+	mockProduct := new(MockProductService)
+	handler := setupTestRest(mockProduct)
+	router := setupRouter(handler)
+
+	mockProduct.On("ValidateToken", mock.Anything, &authpb.ValidateTokenRequest{Token: "admin-token"}).Return(&authpb.ValidateTokenResponse{Valid: true, UserId: "auth-1", Roles: []string{"admin"}, Permissions: []string{authz.PermProductWrite}}, nil)
+	mockProduct.On("CreateProduct", mock.Anything, mock.MatchedBy(func(req dto.CreateProductRequest) bool {
+		return req.SKU == "SKU-1"
+	}), 5, 0).Return(&dto.Product{ID: testProductID, Name: testProductName}, nil)
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/products", bytes.NewBuffer(newAddProductBody()))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer admin-token")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	mockProduct.AssertExpectations(t)
+}
+
+func TestHandleCreateProduct_Forbidden(t *testing.T) {
+	mockProduct := new(MockProductService)
+	handler := setupTestRest(mockProduct)
+	router := setupRouter(handler)
+
+	mockProduct.On("ValidateToken", mock.Anything, &authpb.ValidateTokenRequest{Token: "customer-token"}).Return(&authpb.ValidateTokenResponse{Valid: true, UserId: "auth-1", Roles: []string{"customer"}, Permissions: []string{authz.PermOrderRead, authz.PermOrderWrite}}, nil)
+	mockProduct.On("ValidateApiKey", mock.Anything, &authpb.ValidateApiKeyRequest{ApiKey: "gk_orders-only"}).Return(&authpb.ValidateTokenResponse{Valid: true, UserId: "auth-1", ApiKeyId: "key-1", Permissions: []string{authz.PermOrderReadAny}}, nil)
+
+	for name, setAuth := range map[string]func(r *http.Request){
+		"customer token": func(r *http.Request) { r.Header.Set("Authorization", "Bearer customer-token") },
+		"narrow api key": func(r *http.Request) { r.Header.Set(preference.API_KEY, "gk_orders-only") },
+	} {
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/products", bytes.NewBuffer(newAddProductBody()))
+		req.Header.Set("Content-Type", "application/json")
+		setAuth(req)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code, name)
+	}
+
+	mockProduct.AssertNotCalled(t, "CreateProduct", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestHandleCreateProduct_Unauthenticated(t *testing.T) {
+	mockProduct := new(MockProductService)
+	handler := setupTestRest(mockProduct)
+	router := setupRouter(handler)
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/products", bytes.NewBuffer(newAddProductBody()))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	mockProduct.AssertNotCalled(t, "CreateProduct", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestHandleListProducts_Success(t *testing.T) {
 	mockProduct := new(MockProductService)
 	handler := setupTestRest(mockProduct)
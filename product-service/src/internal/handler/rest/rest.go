@@ -1,9 +1,14 @@
 package rest
 
 import (
+	"net/http"
 	"sync"
 
+	"github.com/linggaaskaedo/go-kill/common/pkg/authz"
 	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+	"github.com/linggaaskaedo/go-kill/common/pkg/middleware"
+	"github.com/linggaaskaedo/go-kill/common/pkg/preference"
+	authpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/auth"
 	"github.com/linggaaskaedo/go-kill/product-service/src/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -37,7 +42,66 @@ func InitRestHandler(gin *gin.Engine, svc *service.Service) {
 	})
 }
 
+// authMiddleware accepts either an API key in X-API-Key or a bearer token,
+// keeps the identity auth-service verified on the context and requires
+// permissions of it. The catalog is public; only its changes go through here.
+func (e *rest) authMiddleware(permissions ...string) gin.HandlerFunc {
+	authorize := middleware.Authorize(e.httpRespError, permissions...)
+
+	return func(c *gin.Context) {
+		if apiKey := c.GetHeader(preference.API_KEY); apiKey != "" {
+			resp, err := e.svc.Product.ValidateApiKey(c.Request.Context(), &authpb.ValidateApiKeyRequest{ApiKey: apiKey})
+			if err != nil || !resp.Valid {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
+				c.Abort()
+				return
+			}
+
+			setIdentity(c, resp)
+			authorize(c)
+			return
+		}
+
+		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "No authorization header"})
+			c.Abort()
+			return
+		}
+
+		const bearerPrefix = "Bearer "
+		if len(authHeader) < len(bearerPrefix) || authHeader[:len(bearerPrefix)] != bearerPrefix {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization header format"})
+			c.Abort()
+			return
+		}
+
+		token := authHeader[len(bearerPrefix):]
+
+		resp, err := e.svc.Product.ValidateToken(c.Request.Context(), &authpb.ValidateTokenRequest{Token: token})
+		if err != nil || !resp.Valid {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+			c.Abort()
+			return
+		}
+
+		setIdentity(c, resp)
+		authorize(c)
+	}
+}
+
+func setIdentity(c *gin.Context, resp *authpb.ValidateTokenResponse) {
+	c.Set("user_auth_id", resp.UserId)
+	c.Set("email", resp.Email)
+	c.Request = c.Request.WithContext(authz.WithPrincipal(c.Request.Context(), &authz.Principal{
+		UserID:      resp.UserId,
+		Roles:       resp.Roles,
+		Permissions: resp.Permissions,
+	}))
+}
+
 func (e *rest) Serve() {
+	e.gin.POST("/api/v1/products", e.authMiddleware(authz.PermProductWrite), e.handleCreateProduct)
 	e.gin.GET("/api/v1/products", e.handleListProducts)
 	e.gin.GET("/api/v1/products/:id", e.handleGetProduct)
 	e.gin.GET("/api/v1/categories", e.handleListCategories)
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/linggaaskaedo/go-kill/common/component/scheduler"
+	authpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/auth"
 	"github.com/linggaaskaedo/go-kill/product-service/src/internal/model/dto"
 	"github.com/linggaaskaedo/go-kill/product-service/src/internal/service/product"
 
@@ -77,9 +78,12 @@ func (m *MockProductService) CheckInventory(ctx context.Context, productID strin
 	return args.Get(0).(int32), args.Get(1).(int32), args.Error(2)
 }
 
-func (m *MockProductService) ReserveInventory(ctx context.Context, req []dto.CreateReserveInventory) error {
-	args := m.Called(ctx, req)
-	return args.Error(0)
+func (m *MockProductService) ReserveInventory(ctx context.Context, reservationID string, req []dto.CreateReserveInventory) (*dto.Reservation, error) {
+	args := m.Called(ctx, reservationID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.Reservation), args.Error(1)
 }
 
 func (m *MockProductService) ReleaseInventory(ctx context.Context, req []dto.CreateReserveInventory) error {
@@ -88,6 +92,42 @@ func (m *MockProductService) ReleaseInventory(ctx context.Context, req []dto.Cre
 	return args.Error(0)
 }
 
+func (m *MockProductService) ReleaseReservation(ctx context.Context, reservationID string) error {
+	args := m.Called(ctx, reservationID)
+	return args.Error(0)
+}
+
+func (m *MockProductService) CommitInventory(ctx context.Context, reservationID string) error {
+	args := m.Called(ctx, reservationID)
+	return args.Error(0)
+}
+
+func (m *MockProductService) RestockInventory(ctx context.Context, req []dto.CreateReserveInventory) error {
+	args := m.Called(ctx, req)
+	return args.Error(0)
+}
+
+func (m *MockProductService) ReleaseExpiredReservations(ctx context.Context, batchSize int) (int, error) {
+	args := m.Called(ctx, batchSize)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockProductService) ValidateToken(ctx context.Context, req *authpb.ValidateTokenRequest) (*authpb.ValidateTokenResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*authpb.ValidateTokenResponse), args.Error(1)
+}
+
+func (m *MockProductService) ValidateApiKey(ctx context.Context, req *authpb.ValidateApiKeyRequest) (*authpb.ValidateTokenResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*authpb.ValidateTokenResponse), args.Error(1)
+}
+
 var _ product.ProductServiceItf = (*MockProductService)(nil)
 
 func TestName(t *testing.T) {
@@ -0,0 +1,51 @@
+package scheduler
+
+import (
+	"context"
+
+	"github.com/linggaaskaedo/go-kill/common/component/scheduler"
+	"github.com/linggaaskaedo/go-kill/product-service/src/internal/service/product"
+
+	"github.com/rs/zerolog"
+)
+
+type ReservationExpiryJob struct {
+	log            zerolog.Logger
+	productService product.ProductServiceItf
+	cfg            scheduler.Config
+}
+
+func NewReservationExpiryJob(log zerolog.Logger, productService product.ProductServiceItf, cfg scheduler.Config) *ReservationExpiryJob {
+	return &ReservationExpiryJob{
+		log:            log,
+		productService: productService,
+		cfg:            cfg,
+	}
+}
+
+func (j *ReservationExpiryJob) Name() string {
+	return "reservation_expiry_job"
+}
+
+func (j *ReservationExpiryJob) Schedule() string {
+	return j.cfg.Cron
+}
+
+func (j *ReservationExpiryJob) Run(ctx context.Context) error {
+	if !j.cfg.Enabled {
+		zerolog.Ctx(ctx).Debug().Msg(j.cfg.Name + " is disabled")
+		return nil
+	}
+
+	released, err := j.productService.ReleaseExpiredReservations(ctx, j.cfg.BatchSize)
+	if err != nil {
+		zerolog.Ctx(ctx).Err(err).Msg("Reservation expiry failed")
+		return err
+	}
+
+	if released > 0 {
+		zerolog.Ctx(ctx).Info().Int("released", released).Int("batch_size", j.cfg.BatchSize).Msg("Expired reservations released")
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/linggaaskaedo/go-kill/common/component/scheduler"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestReservationExpiryJob(mockProduct *MockProductService, enabled bool) *ReservationExpiryJob {
+	return NewReservationExpiryJob(zerolog.Logger{}, mockProduct, scheduler.Config{
+		Name:      "ReservationExpiry",
+		Enabled:   enabled,
+		Cron:      testCron,
+		BatchSize: testBatchSize,
+	})
+}
+
+func TestReservationExpiryNameAndSchedule(t *testing.T) {
+	job := newTestReservationExpiryJob(new(MockProductService), true)
+
+	assert.Equal(t, "reservation_expiry_job", job.Name())
+	assert.Equal(t, testCron, job.Schedule())
+}
+
+func TestReservationExpiryRunDisabled(t *testing.T) {
+	mockProduct := new(MockProductService)
+	job := newTestReservationExpiryJob(mockProduct, false)
+
+	err := job.Run(context.Background())
+
+	assert.NoError(t, err)
+	mockProduct.AssertNotCalled(t, "ReleaseExpiredReservations", mock.Anything, mock.Anything)
+}
+
+func TestReservationExpiryRunSuccess(t *testing.T) {
+	mockProduct := new(MockProductService)
+	ctx := context.Background()
+	mockProduct.On("ReleaseExpiredReservations", ctx, testBatchSize).Return(3, nil)
+	job := newTestReservationExpiryJob(mockProduct, true)
+
+	err := job.Run(ctx)
+
+	assert.NoError(t, err)
+	mockProduct.AssertExpectations(t)
+}
+
+func TestReservationExpiryRunError(t *testing.T) {
+	mockProduct := new(MockProductService)
+	ctx := context.Background()
+	mockProduct.On("ReleaseExpiredReservations", ctx, testBatchSize).Return(0, errors.New("database unavailable"))
+	job := newTestReservationExpiryJob(mockProduct, true)
+
+	err := job.Run(ctx)
+
+	assert.Error(t, err)
+	mockProduct.AssertExpectations(t)
+}
@@ -9,6 +9,13 @@ type CreateProductRequest struct {
 	Categories  []string `json:"categories,omitempty"`
 }
 
+// AddProductRequest is the body of POST /api/v1/products: the product and
+// the stock it starts with.
+type AddProductRequest struct {
+	CreateProductRequest
+	Quantity int `json:"quantity" binding:"gte=0"`
+}
+
 type CreateReserveInventory struct {
 	ProductId string
 	Quantity  int32
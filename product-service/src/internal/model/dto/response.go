@@ -1,6 +1,8 @@
 package dto
 
 import (
+	"time"
+
 	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
 )
 
@@ -41,3 +43,8 @@ type Category struct {
 	CreatedAt string `json:"created_at"`
 	UpdatedAt string `json:"updated_at"`
 }
+
+type Reservation struct {
+	ID        string
+	ExpiresAt time.Time
+}
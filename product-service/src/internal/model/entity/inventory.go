@@ -1,5 +1,7 @@
 package entity
 
+import "time"
+
 type Inventory struct {
 	ID               string `json:"id"`
 	ProductID        string `json:"product_id"`
@@ -7,3 +9,23 @@ type Inventory struct {
 	ReservedQuantity int    `json:"reserved_quantity"`
 	UpdatedAt        string `db:"updated_at" json:"updated_at"`
 }
+
+type ReservationStatus string
+
+const (
+	ReservationActive    ReservationStatus = "active"
+	ReservationReleased  ReservationStatus = "released"
+	ReservationCommitted ReservationStatus = "committed"
+	ReservationExpired   ReservationStatus = "expired"
+)
+
+// InventoryReservation is one product line of a hold. A reservation made for
+// several products has one row per product sharing the same ReservationID.
+type InventoryReservation struct {
+	ID            string            `db:"id" json:"id"`
+	ReservationID string            `db:"reservation_id" json:"reservation_id"`
+	ProductID     string            `db:"product_id" json:"product_id"`
+	Quantity      int32             `db:"quantity" json:"quantity"`
+	Status        ReservationStatus `db:"status" json:"status"`
+	ExpiresAt     time.Time         `db:"expires_at" json:"expires_at"`
+}
@@ -2,6 +2,7 @@ package product
 
 import (
 	"context"
+	"time"
 
 	"github.com/linggaaskaedo/go-kill/common/component/query"
 	"github.com/linggaaskaedo/go-kill/product-service/src/internal/model/dto"
@@ -19,8 +20,13 @@ type ProductRepositoryItf interface {
 	GetCategoriesByProduct(ctx context.Context, productID string) ([]*entity.Category, error)
 	GetProductsByCategory(ctx context.Context, categoryID string) ([]*entity.Product, error)
 	CheckInventory(ctx context.Context, productID string) (int32, int32, error)
-	ReserveInventory(ctx context.Context, req []dto.CreateReserveInventory) error
+	ReserveInventory(ctx context.Context, reservationID string, ttl time.Duration, req []dto.CreateReserveInventory) (time.Time, error)
+	GetReservation(ctx context.Context, reservationID string) ([]*entity.InventoryReservation, error)
 	ReleaseInventory(ctx context.Context, req []dto.CreateReserveInventory) error
+	ReleaseReservation(ctx context.Context, reservationID string) error
+	CommitInventory(ctx context.Context, reservationID string) error
+	RestockInventory(ctx context.Context, req []dto.CreateReserveInventory) error
+	ReleaseExpiredReservations(ctx context.Context, limit int) (int, error)
 }
 
 type productRepository struct {
@@ -3,6 +3,7 @@ package product
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
 	"github.com/linggaaskaedo/go-kill/product-service/src/internal/model/dto"
@@ -81,25 +82,51 @@ func (r *productRepository) CheckInventory(ctx context.Context, productID string
 	return r.getInventoryByProductIDSQL(ctx, productID)
 }
 
-func (r *productRepository) ReserveInventory(ctx context.Context, req []dto.CreateReserveInventory) error {
+// ReserveInventory holds req under reservationID. Each line is inserted first
+// and stock is only taken for lines that were not there yet, so concurrent
+// retries of the same reservation wait on each other and reserve once. A
+// reservation that already exists is returned as is while it is still active.
+func (r *productRepository) ReserveInventory(ctx context.Context, reservationID string, ttl time.Duration, req []dto.CreateReserveInventory) (time.Time, error) {
+	var expiresAt time.Time
+
 	tx, err := r.db0.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelDefault})
 	if err != nil {
 		zerolog.Ctx(ctx).Error().Err(err).Msg("tx_reserve_inventory")
-		return err
+		return expiresAt, err
 	}
 
-	tx, err = r.createReserveInventorySQL(ctx, tx, req)
+	tx, reserved, expiresAt, err := r.createInventoryReservationSQL(ctx, tx, reservationID, ttl, req)
 	if err != nil {
 		_ = tx.Rollback()
-		return err
+		return expiresAt, err
+	}
+
+	if len(reserved) < len(req) {
+		_ = tx.Rollback()
+		if len(reserved) > 0 {
+			zerolog.Ctx(ctx).Error().Str("reservationID", reservationID).Msg("reserve_inventory")
+			return expiresAt, x.NewWithCode(x.CodeHTTPConflict, "Reservation already holds other items")
+		}
+
+		return r.getActiveReservationExpiry(ctx, reservationID)
+	}
+
+	tx, err = r.createReserveInventorySQL(ctx, tx, reserved)
+	if err != nil {
+		_ = tx.Rollback()
+		return expiresAt, err
 	}
 
 	if err = tx.Commit(); err != nil {
 		zerolog.Ctx(ctx).Error().Err(err).Msg("commit_reserve_inventory")
-		return x.Wrap(err, "commit_reserve_inventory")
+		return expiresAt, x.Wrap(err, "commit_reserve_inventory")
 	}
 
-	return nil
+	return expiresAt, nil
+}
+
+func (r *productRepository) GetReservation(ctx context.Context, reservationID string) ([]*entity.InventoryReservation, error) {
+	return r.getInventoryReservationSQL(ctx, reservationID)
 }
 
 func (r *productRepository) ReleaseInventory(ctx context.Context, req []dto.CreateReserveInventory) error {
@@ -122,3 +149,198 @@ func (r *productRepository) ReleaseInventory(ctx context.Context, req []dto.Crea
 
 	return nil
 }
+
+// ReleaseReservation gives back whatever is still held under reservationID.
+// Releasing a reservation that is already released, committed or expired is a
+// no-op, so callers can retry freely.
+func (r *productRepository) ReleaseReservation(ctx context.Context, reservationID string) error {
+	tx, err := r.db0.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelDefault})
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("tx_release_reservation")
+		return err
+	}
+
+	tx, reservations, err := r.updateReservationStatusSQL(ctx, tx, reservationID, entity.ReservationReleased)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if len(reservations) == 0 {
+		_ = tx.Rollback()
+		zerolog.Ctx(ctx).Debug().Str("reservationID", reservationID).Msg("reservation_not_active")
+		return nil
+	}
+
+	tx, err = r.createReleaseInventorySQLTx(ctx, tx, toReserveItems(reservations))
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("commit_release_reservation")
+		return x.Wrap(err, "commit_release_reservation")
+	}
+
+	return nil
+}
+
+func (r *productRepository) CommitInventory(ctx context.Context, reservationID string) error {
+	tx, err := r.db0.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelDefault})
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("tx_commit_inventory")
+		return err
+	}
+
+	tx, reservations, err := r.commitReservationSQL(ctx, tx, reservationID)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if len(reservations) == 0 {
+		_ = tx.Rollback()
+		return r.checkCommittedReservation(ctx, reservationID)
+	}
+
+	req := toReserveItems(reservations)
+
+	tx, err = r.createCommitInventorySQLTx(ctx, tx, req)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("commit_commit_inventory")
+		return x.Wrap(err, "commit_commit_inventory")
+	}
+
+	for _, item := range req {
+		r.setInventoryCommitCache(ctx, item.ProductId, item.Quantity)
+	}
+
+	return nil
+}
+
+func (r *productRepository) RestockInventory(ctx context.Context, req []dto.CreateReserveInventory) error {
+	tx, err := r.db0.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelDefault})
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("tx_restock_inventory")
+		return err
+	}
+
+	tx, err = r.createRestockInventorySQLTx(ctx, tx, req)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("commit_restock_inventory")
+		return x.Wrap(err, "commit_restock_inventory")
+	}
+
+	for _, item := range req {
+		r.setInventoryRestockCache(ctx, item.ProductId, item.Quantity)
+	}
+
+	return nil
+}
+
+// ReleaseExpiredReservations expires up to limit whole reservations, every
+// line of each at once, gives their stock back and returns how many there were.
+func (r *productRepository) ReleaseExpiredReservations(ctx context.Context, limit int) (int, error) {
+	tx, err := r.db0.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelDefault})
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("tx_release_expired_reservations")
+		return 0, err
+	}
+
+	tx, reservations, err := r.updateExpiredReservationsSQL(ctx, tx, limit)
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
+	if len(reservations) == 0 {
+		_ = tx.Rollback()
+		return 0, nil
+	}
+
+	tx, err = r.createReleaseInventorySQLTx(ctx, tx, toReserveItems(reservations))
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("commit_release_expired_reservations")
+		return 0, x.Wrap(err, "commit_release_expired_reservations")
+	}
+
+	released := make(map[string]struct{}, len(reservations))
+	for _, reservation := range reservations {
+		released[reservation.ReservationID] = struct{}{}
+	}
+
+	return len(released), nil
+}
+
+// checkCommittedReservation explains why a commit found nothing active: a
+// reservation that is already committed is fine, anything else is an error.
+func (r *productRepository) checkCommittedReservation(ctx context.Context, reservationID string) error {
+	reservations, err := r.getInventoryReservationSQL(ctx, reservationID)
+	if err != nil {
+		return err
+	}
+
+	if len(reservations) == 0 {
+		return x.NewWithCode(x.CodeSQLRecordDoesNotExist, "Reservation not found")
+	}
+
+	for _, reservation := range reservations {
+		if reservation.Status != entity.ReservationCommitted {
+			zerolog.Ctx(ctx).Error().Str("reservationID", reservationID).Str("status", string(reservation.Status)).Msg("commit_inventory")
+			return x.NewWithCode(x.CodeSQLRecordDoesNotMatch, "Reservation is no longer active")
+		}
+	}
+
+	return nil
+}
+
+// getActiveReservationExpiry answers a reserve whose lines were all there
+// already: the reservation is returned while it still holds the stock.
+func (r *productRepository) getActiveReservationExpiry(ctx context.Context, reservationID string) (time.Time, error) {
+	var expiresAt time.Time
+
+	reservations, err := r.getInventoryReservationSQL(ctx, reservationID)
+	if err != nil {
+		return expiresAt, err
+	}
+
+	if len(reservations) == 0 {
+		return expiresAt, x.NewWithCode(x.CodeSQLRecordDoesNotExist, "Reservation not found")
+	}
+
+	for _, reservation := range reservations {
+		if reservation.Status != entity.ReservationActive {
+			return expiresAt, x.NewWithCode(x.CodeHTTPConflict, "Reservation is no longer active")
+		}
+	}
+
+	return reservations[0].ExpiresAt, nil
+}
+
+func toReserveItems(reservations []*entity.InventoryReservation) []dto.CreateReserveInventory {
+	items := make([]dto.CreateReserveInventory, len(reservations))
+	for i, reservation := range reservations {
+		items[i] = dto.CreateReserveInventory{
+			ProductId: reservation.ProductID,
+			Quantity:  reservation.Quantity,
+		}
+	}
+
+	return items
+}
@@ -34,6 +34,23 @@ func (r *productRepository) setInventoryReleaseCache(ctx context.Context, produc
 	}
 }
 
+func (r *productRepository) setInventoryCommitCache(ctx context.Context, productID string, qty int32) {
+	key := fmt.Sprintf("inventory:%s", productID)
+
+	pipe := r.redis0.TxPipeline()
+	pipe.HIncrBy(ctx, key, "quantity", -int64(qty))
+	pipe.HIncrBy(ctx, key, "reserved", -int64(qty))
+	if _, err := pipe.Exec(ctx); err != nil {
+		zerolog.Ctx(ctx).Warn().Err(err).Msg("failed to update inventory commit cache")
+	}
+}
+
+func (r *productRepository) setInventoryRestockCache(ctx context.Context, productID string, qty int32) {
+	if err := r.redis0.HIncrBy(ctx, fmt.Sprintf("inventory:%s", productID), "quantity", int64(qty)).Err(); err != nil {
+		zerolog.Ctx(ctx).Warn().Err(err).Msg("failed to update inventory restock cache")
+	}
+}
+
 func (r *productRepository) setProductCache(ctx context.Context, product *entity.Product) error {
 	cacheKey := fmt.Sprintf("product:%s", product.ID)
 	return r.redis0.Set(ctx, cacheKey, product, 0).Err()
@@ -0,0 +1,329 @@
+package product
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/linggaaskaedo/go-kill/common/component/query"
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
+	"github.com/linggaaskaedo/go-kill/product-service/src/internal/model/dto"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testReservationID = "019d227d-6eac-749c-b935-263bddc5a650"
+	testProductA      = "019d227d-6eac-749c-b935-000000000001"
+	testProductB      = "019d227d-6eac-749c-b935-000000000002"
+)
+
+var (
+	testExpiresAt     = time.Date(2026, 10, 17, 12, 15, 0, 0, time.UTC)
+	reservationColumn = []string{"id", "reservation_id", "product_id", "quantity", "status", "expires_at"}
+)
+
+type scriptedCall struct {
+	name string
+	args []any
+}
+
+type scriptedResult struct {
+	columns  []string
+	rows     [][]driver.Value
+	affected int64
+}
+
+// scriptedDB is a database/sql driver that answers each named query from the
+// real query file with a canned result and records the order they ran in, so
+// the reservation flows can be checked without PostgreSQL.
+type scriptedDB struct {
+	names     map[string]string
+	answers   map[string][]scriptedResult
+	calls     []scriptedCall
+	commits   int
+	rollbacks int
+}
+
+func (db *scriptedDB) Connect(context.Context) (driver.Conn, error) {
+	return &scriptedConn{db: db}, nil
+}
+
+func (db *scriptedDB) Driver() driver.Driver { return scriptedDriver{} }
+
+// answer queues result for the next run of the named query. A query run more
+// often than it has answers repeats its last one.
+func (db *scriptedDB) answer(name string, result scriptedResult) {
+	db.answers[name] = append(db.answers[name], result)
+}
+
+func (db *scriptedDB) run(query string, args []driver.NamedValue) (scriptedResult, error) {
+	name, ok := db.names[query]
+	if !ok {
+		return scriptedResult{}, errors.New("unexpected query: " + query)
+	}
+
+	values := make([]any, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	db.calls = append(db.calls, scriptedCall{name: name, args: values})
+
+	answers := db.answers[name]
+	if len(answers) == 0 {
+		return scriptedResult{}, errors.New("no answer for " + name)
+	}
+	if len(answers) > 1 {
+		db.answers[name] = answers[1:]
+	}
+
+	return answers[0], nil
+}
+
+func (db *scriptedDB) ran() []string {
+	names := make([]string, len(db.calls))
+	for i, call := range db.calls {
+		names[i] = call.name
+	}
+	return names
+}
+
+type scriptedDriver struct{}
+
+func (scriptedDriver) Open(string) (driver.Conn, error) { return nil, errors.New("not supported") }
+
+type scriptedConn struct {
+	db *scriptedDB
+}
+
+func (c *scriptedConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *scriptedConn) Close() error                        { return nil }
+func (c *scriptedConn) Begin() (driver.Tx, error)           { return &scriptedTx{db: c.db}, nil }
+
+func (c *scriptedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	result, err := c.db.run(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(result.affected), nil
+}
+
+func (c *scriptedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	result, err := c.db.run(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &scriptedRows{columns: result.columns, rows: result.rows}, nil
+}
+
+type scriptedTx struct {
+	db *scriptedDB
+}
+
+func (tx *scriptedTx) Commit() error   { tx.db.commits++; return nil }
+func (tx *scriptedTx) Rollback() error { tx.db.rollbacks++; return nil }
+
+type scriptedRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *scriptedRows) Columns() []string { return r.columns }
+func (r *scriptedRows) Close() error      { return nil }
+
+func (r *scriptedRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func newTestReservationRepository(t *testing.T) (*productRepository, *scriptedDB) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	queryLoader := query.NewQueryComponent(zerolog.Nop(), query.Config{Path: "../../../../etc/sql"})
+	errCh := make(chan error, 1)
+	go func() { errCh <- queryLoader.Start(ctx) }()
+
+	select {
+	case <-queryLoader.Ready():
+	case err := <-errCh:
+		t.Fatalf("load queries: %v", err)
+	}
+
+	db := &scriptedDB{names: make(map[string]string), answers: make(map[string][]scriptedResult)}
+	for _, name := range []string{
+		"CreateInventoryReservation", "GetInventoryReservation", "LockUpdateInventory", "UpdateReservedQuantity",
+		"CommitReservation", "UpdateCommitQuantity", "UpdateExpiredReservations", "UpdateReleaseQuantity",
+	} {
+		sqlText, ok := queryLoader.Get(name)
+		require.True(t, ok, name)
+		db.names[sqlText] = name
+	}
+
+	// Nothing listens here, so cache updates fail fast and are only logged
+	redis0 := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1, DialTimeout: 10 * time.Millisecond})
+	t.Cleanup(func() { _ = redis0.Close() })
+
+	return &productRepository{
+		db0:         sqlx.NewDb(sql.OpenDB(db), "postgres"),
+		queryLoader: queryLoader,
+		redis0:      redis0,
+	}, db
+}
+
+func reservationLine(productID string, qty int64, status string) []driver.Value {
+	return []driver.Value{"line-" + productID, testReservationID, productID, qty, status, testExpiresAt}
+}
+
+var (
+	inserted    = scriptedResult{columns: []string{"expires_at"}, rows: [][]driver.Value{{testExpiresAt}}}
+	notInserted = scriptedResult{columns: []string{"expires_at"}}
+	inStock     = scriptedResult{columns: []string{"quantity", "reserved_quantity"}, rows: [][]driver.Value{{int64(10), int64(0)}}}
+	updated     = scriptedResult{affected: 1}
+)
+
+var reserveItems = []dto.CreateReserveInventory{
+	{ProductId: testProductA, Quantity: 2},
+	{ProductId: testProductB, Quantity: 1},
+}
+
+func TestReserveInventoryTakesStockForNewLines(t *testing.T) {
+	repo, db := newTestReservationRepository(t)
+	db.answer("CreateInventoryReservation", inserted)
+	db.answer("LockUpdateInventory", inStock)
+	db.answer("UpdateReservedQuantity", updated)
+
+	expiresAt, err := repo.ReserveInventory(context.Background(), testReservationID, 15*time.Minute, reserveItems)
+	require.NoError(t, err)
+	assert.True(t, testExpiresAt.Equal(expiresAt))
+	assert.Equal(t, []string{
+		"CreateInventoryReservation", "CreateInventoryReservation",
+		"LockUpdateInventory", "UpdateReservedQuantity",
+		"LockUpdateInventory", "UpdateReservedQuantity",
+	}, db.ran())
+	assert.Equal(t, []any{testReservationID, testProductA, int64(2), int64(900)}, db.calls[0].args)
+	assert.Equal(t, 1, db.commits)
+}
+
+func TestReserveInventoryRetryDoesNotTakeStockTwice(t *testing.T) {
+	repo, db := newTestReservationRepository(t)
+
+	// Both lines were inserted by the first attempt, so the retry's inserts
+	// conflict and the stock is left alone
+	db.answer("CreateInventoryReservation", notInserted)
+	db.answer("GetInventoryReservation", scriptedResult{columns: reservationColumn, rows: [][]driver.Value{
+		reservationLine(testProductA, 2, "active"),
+		reservationLine(testProductB, 1, "active"),
+	}})
+
+	expiresAt, err := repo.ReserveInventory(context.Background(), testReservationID, 15*time.Minute, reserveItems)
+	require.NoError(t, err)
+	assert.True(t, testExpiresAt.Equal(expiresAt))
+	assert.Equal(t, []string{"CreateInventoryReservation", "CreateInventoryReservation", "GetInventoryReservation"}, db.ran())
+	assert.Equal(t, 0, db.commits)
+	assert.Equal(t, 1, db.rollbacks)
+}
+
+func TestReserveInventoryRetryOfEndedReservationConflicts(t *testing.T) {
+	repo, db := newTestReservationRepository(t)
+	db.answer("CreateInventoryReservation", notInserted)
+	db.answer("GetInventoryReservation", scriptedResult{columns: reservationColumn, rows: [][]driver.Value{
+		reservationLine(testProductA, 2, "released"),
+		reservationLine(testProductB, 1, "released"),
+	}})
+
+	_, err := repo.ReserveInventory(context.Background(), testReservationID, 15*time.Minute, reserveItems)
+	require.Error(t, err)
+	assert.Equal(t, x.CodeHTTPConflict, x.ErrCode(err))
+	assert.NotContains(t, db.ran(), "LockUpdateInventory")
+}
+
+func TestReserveInventoryWithOtherItemsConflicts(t *testing.T) {
+	repo, db := newTestReservationRepository(t)
+	db.answer("CreateInventoryReservation", notInserted)
+	db.answer("CreateInventoryReservation", inserted)
+
+	_, err := repo.ReserveInventory(context.Background(), testReservationID, 15*time.Minute, reserveItems)
+	require.Error(t, err)
+	assert.Equal(t, x.CodeHTTPConflict, x.ErrCode(err))
+	assert.NotContains(t, db.ran(), "LockUpdateInventory")
+	assert.Equal(t, 0, db.commits)
+	assert.Equal(t, 1, db.rollbacks)
+}
+
+func TestCommitInventoryCommitsEveryLine(t *testing.T) {
+	repo, db := newTestReservationRepository(t)
+	db.answer("CommitReservation", scriptedResult{columns: reservationColumn, rows: [][]driver.Value{
+		reservationLine(testProductA, 2, "committed"),
+		reservationLine(testProductB, 1, "committed"),
+	}})
+	db.answer("UpdateCommitQuantity", updated)
+
+	require.NoError(t, repo.CommitInventory(context.Background(), testReservationID))
+	assert.Equal(t, []string{"CommitReservation", "UpdateCommitQuantity", "UpdateCommitQuantity"}, db.ran())
+	assert.Equal(t, []any{int64(2), testProductA}, db.calls[1].args)
+	assert.Equal(t, 1, db.commits)
+}
+
+func TestCommitInventoryRefusesPartlyExpiredReservation(t *testing.T) {
+	repo, db := newTestReservationRepository(t)
+
+	// One line expired, so the guarded commit touches none of them
+	db.answer("CommitReservation", scriptedResult{columns: reservationColumn})
+	db.answer("GetInventoryReservation", scriptedResult{columns: reservationColumn, rows: [][]driver.Value{
+		reservationLine(testProductA, 2, "active"),
+		reservationLine(testProductB, 1, "expired"),
+	}})
+
+	err := repo.CommitInventory(context.Background(), testReservationID)
+	require.Error(t, err)
+	assert.Equal(t, x.CodeSQLRecordDoesNotMatch, x.ErrCode(err))
+	assert.NotContains(t, db.ran(), "UpdateCommitQuantity")
+	assert.Equal(t, 0, db.commits)
+}
+
+func TestCommitInventoryAlreadyCommittedIsNoop(t *testing.T) {
+	repo, db := newTestReservationRepository(t)
+	db.answer("CommitReservation", scriptedResult{columns: reservationColumn})
+	db.answer("GetInventoryReservation", scriptedResult{columns: reservationColumn, rows: [][]driver.Value{
+		reservationLine(testProductA, 2, "committed"),
+		reservationLine(testProductB, 1, "committed"),
+	}})
+
+	require.NoError(t, repo.CommitInventory(context.Background(), testReservationID))
+	assert.NotContains(t, db.ran(), "UpdateCommitQuantity")
+}
+
+func TestReleaseExpiredReservationsReleasesWholeReservations(t *testing.T) {
+	repo, db := newTestReservationRepository(t)
+	other := "019d227d-6eac-749c-b935-263bddc5a651"
+	db.answer("UpdateExpiredReservations", scriptedResult{columns: reservationColumn, rows: [][]driver.Value{
+		reservationLine(testProductA, 2, "expired"),
+		reservationLine(testProductB, 1, "expired"),
+		{"line-3", other, testProductA, int64(4), "expired", testExpiresAt},
+	}})
+	db.answer("UpdateReleaseQuantity", updated)
+
+	released, err := repo.ReleaseExpiredReservations(context.Background(), 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, released)
+
+	// The limit counts reservations, and every line of each is given back
+	assert.Equal(t, []any{int64(2)}, db.calls[0].args)
+	assert.Equal(t, []string{"UpdateExpiredReservations", "UpdateReleaseQuantity", "UpdateReleaseQuantity", "UpdateReleaseQuantity"}, db.ran())
+	assert.Equal(t, 1, db.commits)
+}
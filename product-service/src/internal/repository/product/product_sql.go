@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
 	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
 	"github.com/linggaaskaedo/go-kill/product-service/src/internal/model/dto"
@@ -243,7 +244,7 @@ func (r *productRepository) createReserveInventorySQL(ctx context.Context, tx *s
 		status := available < int32(item.Quantity)
 		if status {
 			zerolog.Ctx(ctx).Error().Bool("status", status).Msg("create_reserve_inventory_sql")
-			return tx, x.New("Insufficient inventory")
+			return tx, x.NewWithCode(x.CodeHTTPUnprocessableEntity, "Insufficient inventory")
 		}
 
 		// Update reserved quantity
@@ -279,3 +280,178 @@ func (r *productRepository) createReleaseInventorySQLTx(ctx context.Context, tx
 
 	return tx, nil
 }
+
+func (r *productRepository) createCommitInventorySQLTx(ctx context.Context, tx *sqlx.Tx, req []dto.CreateReserveInventory) (*sqlx.Tx, error) {
+	query, ok := r.queryLoader.Get("UpdateCommitQuantity")
+	if !ok {
+		zerolog.Ctx(ctx).Error().Str("query", "UpdateCommitQuantity").Msg("query_not_found")
+		return tx, x.NewWithCode(x.CodeSQLQueryBuild, "query_UpdateCommitQuantity_not_found")
+	}
+
+	for _, item := range req {
+		result, err := tx.ExecContext(ctx, query, item.Quantity, item.ProductId)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Str("productID", item.ProductId).Int32("qty", item.Quantity).Msg("create_commit_inventory_sql")
+			return tx, x.WrapWithCode(err, x.CodeSQLUpdate, "create_commit_inventory_sql")
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Str("productID", item.ProductId).Msg("create_commit_inventory_sql")
+			return tx, x.WrapWithCode(err, x.CodeSQLCannotRetrieveAffectedRows, "create_commit_inventory_sql")
+		}
+
+		// Nothing updated: the product is gone or less than qty is actually reserved
+		if rowsAffected == 0 {
+			zerolog.Ctx(ctx).Error().Str("productID", item.ProductId).Int32("qty", item.Quantity).Msg("create_commit_inventory_sql")
+			return tx, x.NewWithCode(x.CodeSQLRecordDoesNotMatch, "Insufficient reserved inventory")
+		}
+	}
+
+	return tx, nil
+}
+
+func (r *productRepository) createRestockInventorySQLTx(ctx context.Context, tx *sqlx.Tx, req []dto.CreateReserveInventory) (*sqlx.Tx, error) {
+	query, ok := r.queryLoader.Get("UpdateRestockQuantity")
+	if !ok {
+		zerolog.Ctx(ctx).Error().Str("query", "UpdateRestockQuantity").Msg("query_not_found")
+		return tx, x.NewWithCode(x.CodeSQLQueryBuild, "query_UpdateRestockQuantity_not_found")
+	}
+
+	for _, item := range req {
+		_, err := tx.ExecContext(ctx, query, item.Quantity, item.ProductId)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Str("productID", item.ProductId).Int32("qty", item.Quantity).Msg("create_restock_inventory_sql")
+			return tx, x.WrapWithCode(err, x.CodeSQLUpdate, "create_restock_inventory_sql")
+		}
+	}
+
+	return tx, nil
+}
+
+// createInventoryReservationSQL inserts the lines of a reservation and returns
+// the ones that were new. A line already held under reservationID is skipped,
+// so its stock must not be taken again.
+func (r *productRepository) createInventoryReservationSQL(ctx context.Context, tx *sqlx.Tx, reservationID string, ttl time.Duration, req []dto.CreateReserveInventory) (*sqlx.Tx, []dto.CreateReserveInventory, time.Time, error) {
+	var expiresAt time.Time
+
+	query, ok := r.queryLoader.Get("CreateInventoryReservation")
+	if !ok {
+		zerolog.Ctx(ctx).Error().Str("query", "CreateInventoryReservation").Msg("query_not_found")
+		return tx, nil, expiresAt, x.NewWithCode(x.CodeSQLQueryBuild, "query_CreateInventoryReservation_not_found")
+	}
+
+	reserved := make([]dto.CreateReserveInventory, 0, len(req))
+	for _, item := range req {
+		err := tx.QueryRowxContext(ctx, query, reservationID, item.ProductId, item.Quantity, int64(ttl.Seconds())).Scan(&expiresAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Str("reservationID", reservationID).Str("productID", item.ProductId).Msg("create_inventory_reservation_sql")
+			return tx, nil, expiresAt, x.WrapWithCode(err, x.CodeSQLCreate, "create_inventory_reservation_sql")
+		}
+
+		reserved = append(reserved, item)
+	}
+
+	return tx, reserved, expiresAt, nil
+}
+
+func (r *productRepository) getInventoryReservationSQL(ctx context.Context, reservationID string) ([]*entity.InventoryReservation, error) {
+	query, ok := r.queryLoader.Get("GetInventoryReservation")
+	if !ok {
+		zerolog.Ctx(ctx).Error().Str("query", "GetInventoryReservation").Msg("query_not_found")
+		return nil, x.NewWithCode(x.CodeSQLQueryBuild, "query_GetInventoryReservation_not_found")
+	}
+
+	rows, err := r.db0.QueryxContext(ctx, query, reservationID)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("reservationID", reservationID).Msg("get_inventory_reservation_sql")
+		return nil, x.WrapWithCode(err, x.CodeSQLRead, "get_inventory_reservation_sql")
+	}
+
+	return scanInventoryReservations(ctx, rows, "get_inventory_reservation_sql")
+}
+
+// updateReservationStatusSQL moves the active lines of a reservation to status
+// and returns them. Lines no longer active are left alone, so a repeated call
+// returns nothing.
+func (r *productRepository) updateReservationStatusSQL(ctx context.Context, tx *sqlx.Tx, reservationID string, status entity.ReservationStatus) (*sqlx.Tx, []*entity.InventoryReservation, error) {
+	query, ok := r.queryLoader.Get("UpdateReservationStatus")
+	if !ok {
+		zerolog.Ctx(ctx).Error().Str("query", "UpdateReservationStatus").Msg("query_not_found")
+		return tx, nil, x.NewWithCode(x.CodeSQLQueryBuild, "query_UpdateReservationStatus_not_found")
+	}
+
+	rows, err := tx.QueryxContext(ctx, query, status, reservationID)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("reservationID", reservationID).Str("status", string(status)).Msg("update_reservation_status_sql")
+		return tx, nil, x.WrapWithCode(err, x.CodeSQLUpdate, "update_reservation_status_sql")
+	}
+
+	reservations, err := scanInventoryReservations(ctx, rows, "update_reservation_status_sql")
+	return tx, reservations, err
+}
+
+// commitReservationSQL commits the lines of a reservation only while every one
+// of them is still active; a partly expired or released reservation returns
+// nothing.
+func (r *productRepository) commitReservationSQL(ctx context.Context, tx *sqlx.Tx, reservationID string) (*sqlx.Tx, []*entity.InventoryReservation, error) {
+	query, ok := r.queryLoader.Get("CommitReservation")
+	if !ok {
+		zerolog.Ctx(ctx).Error().Str("query", "CommitReservation").Msg("query_not_found")
+		return tx, nil, x.NewWithCode(x.CodeSQLQueryBuild, "query_CommitReservation_not_found")
+	}
+
+	rows, err := tx.QueryxContext(ctx, query, reservationID)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("reservationID", reservationID).Msg("commit_reservation_sql")
+		return tx, nil, x.WrapWithCode(err, x.CodeSQLUpdate, "commit_reservation_sql")
+	}
+
+	reservations, err := scanInventoryReservations(ctx, rows, "commit_reservation_sql")
+	return tx, reservations, err
+}
+
+// updateExpiredReservationsSQL expires the lines of up to limit reservations
+// whose hold has run out. It works on whole reservations, so a reservation is
+// never left half expired for CommitInventory to pick up.
+func (r *productRepository) updateExpiredReservationsSQL(ctx context.Context, tx *sqlx.Tx, limit int) (*sqlx.Tx, []*entity.InventoryReservation, error) {
+	query, ok := r.queryLoader.Get("UpdateExpiredReservations")
+	if !ok {
+		zerolog.Ctx(ctx).Error().Str("query", "UpdateExpiredReservations").Msg("query_not_found")
+		return tx, nil, x.NewWithCode(x.CodeSQLQueryBuild, "query_UpdateExpiredReservations_not_found")
+	}
+
+	rows, err := tx.QueryxContext(ctx, query, limit)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Int("limit", limit).Msg("update_expired_reservations_sql")
+		return tx, nil, x.WrapWithCode(err, x.CodeSQLUpdate, "update_expired_reservations_sql")
+	}
+
+	reservations, err := scanInventoryReservations(ctx, rows, "update_expired_reservations_sql")
+	return tx, reservations, err
+}
+
+func scanInventoryReservations(ctx context.Context, rows *sqlx.Rows, op string) ([]*entity.InventoryReservation, error) {
+	defer rows.Close()
+
+	reservations := make([]*entity.InventoryReservation, 0, 4)
+	for rows.Next() {
+		var reservation entity.InventoryReservation
+		if err := rows.StructScan(&reservation); err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg(op)
+			return nil, x.WrapWithCode(err, x.CodeSQLRowScan, op)
+		}
+
+		reservations = append(reservations, &reservation)
+	}
+
+	if err := rows.Err(); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg(op)
+		return nil, x.WrapWithCode(err, x.CodeSQLRead, op)
+	}
+
+	return reservations, nil
+}
@@ -2,10 +2,14 @@ package product
 
 import (
 	"context"
+	"time"
 
+	authpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/auth"
 	"github.com/linggaaskaedo/go-kill/product-service/src/internal/model/dto"
 	"github.com/linggaaskaedo/go-kill/product-service/src/internal/model/entity"
 	"github.com/linggaaskaedo/go-kill/product-service/src/internal/repository/product"
+
+	"google.golang.org/grpc"
 )
 
 type ProductServiceItf interface {
@@ -16,17 +20,41 @@ type ProductServiceItf interface {
 	GetCategoriesByProduct(ctx context.Context, productID string) ([]*dto.Category, error)
 	GetProductsByCategory(ctx context.Context, categoryID string) ([]*dto.Product, error)
 	CheckInventory(ctx context.Context, productID string) (int32, int32, error)
-	ReserveInventory(ctx context.Context, req []dto.CreateReserveInventory) error
+	ReserveInventory(ctx context.Context, reservationID string, req []dto.CreateReserveInventory) (*dto.Reservation, error)
 	ReleaseInventory(ctx context.Context, req []dto.CreateReserveInventory) error
+	ReleaseReservation(ctx context.Context, reservationID string) error
+	CommitInventory(ctx context.Context, reservationID string) error
+	RestockInventory(ctx context.Context, req []dto.CreateReserveInventory) error
+	ReleaseExpiredReservations(ctx context.Context, batchSize int) (int, error)
+
+	// REST
+	ValidateToken(ctx context.Context, req *authpb.ValidateTokenRequest) (*authpb.ValidateTokenResponse, error)
+	ValidateApiKey(ctx context.Context, req *authpb.ValidateApiKeyRequest) (*authpb.ValidateTokenResponse, error)
 }
 
 type productService struct {
+	authClient        authpb.AuthServiceClient
 	productRepository product.ProductRepositoryItf
+	productOptions    Options
+}
+
+type Options struct {
+	// ReservationTTL is how long a ReserveInventory hold lasts before the
+	// expiry job hands the stock back.
+	ReservationTTL time.Duration `yaml:"reservation_ttl"`
 }
 
-func InitProductService(productRepository product.ProductRepositoryItf) ProductServiceItf {
+const defaultReservationTTL = 15 * time.Minute
+
+func InitProductService(clientConn *grpc.ClientConn, productRepository product.ProductRepositoryItf, productOptions Options) ProductServiceItf {
+	if productOptions.ReservationTTL <= 0 {
+		productOptions.ReservationTTL = defaultReservationTTL
+	}
+
 	return &productService{
+		authClient:        authpb.NewAuthServiceClient(clientConn),
 		productRepository: productRepository,
+		productOptions:    productOptions,
 	}
 }
 
@@ -3,7 +3,10 @@ package product
 import (
 	"context"
 
+	x "github.com/linggaaskaedo/go-kill/common/pkg/errors"
 	"github.com/linggaaskaedo/go-kill/product-service/src/internal/model/dto"
+
+	"github.com/openpcc/openpcc/uuidv7"
 )
 
 func (s *productService) CreateProduct(ctx context.Context, req dto.CreateProductRequest, qty int, rsv int) (*dto.Product, error) {
@@ -66,10 +69,67 @@ func (s *productService) CheckInventory(ctx context.Context, productID string) (
 	return s.productRepository.CheckInventory(ctx, productID)
 }
 
-func (s *productService) ReserveInventory(ctx context.Context, req []dto.CreateReserveInventory) error {
-	return s.productRepository.ReserveInventory(ctx, req)
+// ReserveInventory holds stock for ReservationTTL. An empty reservationID gets
+// a fresh one; a reservationID that already holds stock is returned as is, so
+// a caller retrying after a lost response does not reserve twice.
+func (s *productService) ReserveInventory(ctx context.Context, reservationID string, req []dto.CreateReserveInventory) (*dto.Reservation, error) {
+	if reservationID == "" {
+		reservationID = uuidv7.MustNew().String()
+	} else if _, err := uuidv7.Parse(reservationID); err != nil {
+		return nil, x.WrapWithCode(err, x.CodeHTTPBadRequest, "Invalid reservation ID")
+	}
+
+	expiresAt, err := s.productRepository.ReserveInventory(ctx, reservationID, s.productOptions.ReservationTTL, mergeReserveItems(req))
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.Reservation{ID: reservationID, ExpiresAt: expiresAt}, nil
+}
+
+// mergeReserveItems sums the lines asking for the same product, since a
+// reservation holds each product once.
+func mergeReserveItems(req []dto.CreateReserveInventory) []dto.CreateReserveInventory {
+	merged := make([]dto.CreateReserveInventory, 0, len(req))
+	index := make(map[string]int, len(req))
+
+	for _, item := range req {
+		if i, ok := index[item.ProductId]; ok {
+			merged[i].Quantity += item.Quantity
+			continue
+		}
+
+		index[item.ProductId] = len(merged)
+		merged = append(merged, item)
+	}
+
+	return merged
 }
 
 func (s *productService) ReleaseInventory(ctx context.Context, req []dto.CreateReserveInventory) error {
 	return s.productRepository.ReleaseInventory(ctx, req)
 }
+
+func (s *productService) ReleaseReservation(ctx context.Context, reservationID string) error {
+	if _, err := uuidv7.Parse(reservationID); err != nil {
+		return x.WrapWithCode(err, x.CodeHTTPBadRequest, "Invalid reservation ID")
+	}
+
+	return s.productRepository.ReleaseReservation(ctx, reservationID)
+}
+
+func (s *productService) CommitInventory(ctx context.Context, reservationID string) error {
+	if _, err := uuidv7.Parse(reservationID); err != nil {
+		return x.WrapWithCode(err, x.CodeHTTPBadRequest, "Invalid reservation ID")
+	}
+
+	return s.productRepository.CommitInventory(ctx, reservationID)
+}
+
+func (s *productService) RestockInventory(ctx context.Context, req []dto.CreateReserveInventory) error {
+	return s.productRepository.RestockInventory(ctx, req)
+}
+
+func (s *productService) ReleaseExpiredReservations(ctx context.Context, batchSize int) (int, error) {
+	return s.productRepository.ReleaseExpiredReservations(ctx, batchSize)
+}
@@ -0,0 +1,29 @@
+package product
+
+import (
+	"context"
+
+	authpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/auth"
+
+	"github.com/rs/zerolog"
+)
+
+func (s *productService) ValidateToken(ctx context.Context, req *authpb.ValidateTokenRequest) (*authpb.ValidateTokenResponse, error) {
+	authResp, err := s.authClient.ValidateToken(ctx, req)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("validate_token")
+		return nil, err
+	}
+
+	return authResp, nil
+}
+
+func (s *productService) ValidateApiKey(ctx context.Context, req *authpb.ValidateApiKeyRequest) (*authpb.ValidateTokenResponse, error) {
+	authResp, err := s.authClient.ValidateApiKey(ctx, req)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("validate_api_key")
+		return nil, err
+	}
+
+	return authResp, nil
+}
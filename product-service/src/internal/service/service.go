@@ -3,16 +3,24 @@ package service
 import (
 	"github.com/linggaaskaedo/go-kill/product-service/src/internal/repository"
 	"github.com/linggaaskaedo/go-kill/product-service/src/internal/service/product"
+
+	"google.golang.org/grpc"
 )
 
 type Service struct {
 	Product product.ProductServiceItf
 }
 
-func InitService(repository *repository.Repository) *Service {
+type Options struct {
+	ProductOpts product.Options `yaml:"product"`
+}
+
+func InitService(clientConn *grpc.ClientConn, repository *repository.Repository, opts Options) *Service {
 	return &Service{
 		Product: product.InitProductService(
+			clientConn,
 			repository.Product,
+			opts.ProductOpts,
 		),
 	}
 }
@@ -3,9 +3,6 @@ package main
 import (
 	"context"
 	"flag"
-	"fmt"
-	"os/signal"
-	"syscall"
 	"time"
 
 	"github.com/linggaaskaedo/go-kill/common/app"
@@ -15,16 +12,20 @@ import (
 	"github.com/linggaaskaedo/go-kill/common/component/http"
 	"github.com/linggaaskaedo/go-kill/common/component/mongo"
 	"github.com/linggaaskaedo/go-kill/common/component/query"
+	"github.com/linggaaskaedo/go-kill/common/component/redis"
 	"github.com/linggaaskaedo/go-kill/common/component/scheduler"
 	"github.com/linggaaskaedo/go-kill/common/component/server"
+	"github.com/linggaaskaedo/go-kill/common/component/tracer"
+	commonConfig "github.com/linggaaskaedo/go-kill/common/pkg/config"
+	"github.com/linggaaskaedo/go-kill/common/pkg/idempotency"
 	"github.com/linggaaskaedo/go-kill/common/pkg/logger"
+	"github.com/linggaaskaedo/go-kill/common/pkg/metrics"
 	"github.com/linggaaskaedo/go-kill/common/pkg/middleware"
 	userpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/user"
 	"github.com/linggaaskaedo/go-kill/user-service/src/internal/config"
 	restHandler "github.com/linggaaskaedo/go-kill/user-service/src/internal/handler/rest"
 	sched "github.com/linggaaskaedo/go-kill/user-service/src/internal/handler/scheduler"
 
-	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
 )
 
@@ -46,6 +47,8 @@ var (
 // @host			localhost:8080
 // @schemes		http https
 func main() {
+	src := commonConfig.Source{File: "config.yaml", EnvPrefix: "USER"}
+	src.RegisterFlags(flag.CommandLine)
 	flag.IntVar(&minJitter, "minSleep", DefaultMinJitter, "min. sleep duration during app initialization")
 	flag.IntVar(&maxJitter, "maxSleep", DefaultMaxJitter, "max. sleep duration during app initialization")
 	flag.Parse()
@@ -54,7 +57,7 @@ func main() {
 	sleepWithJitter(minJitter, maxJitter)
 
 	// Load config
-	cfg, err := config.Load("config.yaml")
+	cfg, err := config.Load(src)
 	if err != nil {
 		panic(err)
 	}
@@ -62,115 +65,70 @@ func main() {
 	// Initialize logger
 	log := logger.Init(cfg.Logger)
 
+	// Label the metrics with the service name
+	metrics.Init("user-service")
+
 	log.Info().Msg("Starting user service...")
 
 	// Create application with options
-	appSubComp := app.New(app.WithShutdownTimeout(15*time.Second), app.WithLogger(log))
-	appMainComp := app.New(app.WithShutdownTimeout(15*time.Second), app.WithLogger(log))
+	a := app.New(app.WithShutdownTimeout(10*time.Second), app.WithLogger(log))
+
+	// Added first so that it stops last, exporting the spans of shutdown
+	a.Add(tracer.NewTracerComponent(log, cfg.Tracer))
+
+	// Apply the reloadable settings when the config changes
+	watcher := commonConfig.NewWatcher(log, src, cfg)
+	watcher.Subscribe(func(cfg *config.Config) {
+		logger.SetLevel(cfg.Logger.Level)
+	})
+	a.Add(watcher)
+
+	redisComp0 := redis.NewRedisComponent(log, cfg.Redis)
+	a.Add(redisComp0)
 
-	// Initialize database component
 	dbComp0 := database.NewDatabaseComponent(log, cfg.Database["db-0"])
-	if dbComp0 != nil {
-		appSubComp.Add(dbComp0, 10*time.Second)
-	}
+	a.Add(dbComp0)
 
-	// Initialize query loader component
 	queryComp := query.NewQueryComponent(log, cfg.Query)
-	appSubComp.Add(queryComp, 10*time.Second)
+	a.Add(queryComp)
 
-	// Initialize mongo component
 	mongoComp0 := mongo.NewMongoDBComponent(log, cfg.Mongo["mongo-0"])
-	appSubComp.Add(mongoComp0, 10*time.Second)
+	a.Add(mongoComp0)
 
-	// Initialize gRPC client components
 	authClientComp := grpcclient.NewGRPCClientComponent(log, cfg.GRPCClient["auth_service"])
-	appSubComp.Add(authClientComp, 10*time.Second)
-
-	// Initialze middleware
-	mw := middleware.Init(log)
-
-	// Initialize Gin engine
-	gin := http.Init(log, mw, cfg.Http)
-
-	// Stage 1: Start independent components (no dependencies)
-	independent := []app.Component{dbComp0, queryComp, mongoComp0, authClientComp}
-
-	// Create a shared context that cancels on SIGINT/SIGTERM
-	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
-	defer stop()
+	a.Add(authClientComp, app.Name("auth_service"))
 
-	// Use an errgroup to manage their goroutines
-	indepGroup, indepCtx := errgroup.WithContext(ctx)
-	for _, comp := range independent {
-		indepGroup.Go(func() error {
-			return comp.Start(indepCtx)
-		})
-	}
-
-	// Wait for each independent component to be ready (with timeout)
-	for _, comp := range independent {
-		select {
-		case <-comp.Ready():
-			log.Info().Str("component", fmt.Sprintf("%T", comp)).Msg("ready")
-		case <-time.After(10 * time.Second):
-			log.Fatal().Msgf("timeout waiting for component %T", comp)
-		}
-	}
-
-	// Now build the service component (which depends on database, mongo, query, etc.)
 	serviceComp := config.NewServiceComponent(log, dbComp0, queryComp, mongoComp0, authClientComp)
-	appMainComp.Add(serviceComp, 10*time.Second)
+	a.Add(serviceComp, app.DependsOn(dbComp0, queryComp, mongoComp0, authClientComp))
 
-	// Initialize scheduler component
 	schedComp := scheduler.NewSchedulerComponent(log, func() ([]scheduler.Job, error) {
-		select {
-		case <-serviceComp.Ready():
-			userGenJob := sched.NewUserGeneratorJob(log, cfg.Scheduler["job-0"])
-			return []scheduler.Job{userGenJob}, nil
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		case <-time.After(10 * time.Second):
-			return nil, fmt.Errorf("timeout waiting for scheduler")
-		}
+		userGenJob := sched.NewUserGeneratorJob(log, cfg.Scheduler["job-0"])
+		return []scheduler.Job{userGenJob}, nil
 	})
-	if schedComp != nil {
-		appMainComp.Add(schedComp, 10*time.Second)
-	}
+	a.Add(schedComp, app.DependsOn(serviceComp))
 
-	// Initialize gRPC server component
 	grpcServerComp := grpcserver.NewGRPCServerComponent(log, cfg.GRPCServer, func(ctx context.Context, s *grpc.Server) error {
-		select {
-		case <-serviceComp.Ready():
-			userpb.RegisterUserServiceServer(s, serviceComp.GrpcHandler())
-			return nil
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-time.After(10 * time.Second):
-			return fmt.Errorf("timeout waiting for gRPC Server")
-		}
+		userpb.RegisterUserServiceServer(s, serviceComp.GrpcHandler())
+		return nil
 	})
-	appMainComp.Add(grpcServerComp, 10*time.Second)
+	grpcServerComp.SetHealthCheck(a.Serving)
+	a.Add(grpcServerComp, app.DependsOn(serviceComp))
+
+	// Idempotency keys for registration
+	idem := idempotency.New(idempotency.NewRedisStore(redisComp0.Client()), cfg.Idempotency)
+
+	mw := middleware.Init(log)
+	gin := http.Init(log, mw, cfg.Http)
 
-	// Initialize HTTP server component
 	httpServerComp := server.NewHTTPServerComponent(log, cfg.Server, mw, gin, func(ctx context.Context, engine *server.Engine) error {
-		select {
-		case <-serviceComp.Ready():
-			restHandler.InitRestHandler(engine, serviceComp.Service())
-			return nil
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-time.After(10 * time.Second):
-			return fmt.Errorf("timeout waiting for HTTP Server")
-		}
+		server.RegisterHealth(engine, a)
+		server.RegisterMetrics(engine)
+		restHandler.InitRestHandler(engine, serviceComp.Service(), idem)
+		return nil
 	})
-	appMainComp.Add(httpServerComp, 10*time.Second)
+	a.Add(httpServerComp, app.DependsOn(serviceComp, redisComp0))
 
-	// Run the app – now all components are added and will start in the order they were added.
-	if err := appMainComp.Run(); err != nil {
+	if err := a.Run(); err != nil {
 		log.Fatal().Err(err).Msg("app failed")
 	}
-
-	if err := indepGroup.Wait(); err != nil && err != context.Canceled {
-		log.Error().Err(err).Msg("Independent component error")
-	}
 }
@@ -1,8 +1,6 @@
 package config
 
 import (
-	"os"
-
 	"github.com/linggaaskaedo/go-kill/common/component/database"
 	"github.com/linggaaskaedo/go-kill/common/component/grpcclient"
 	"github.com/linggaaskaedo/go-kill/common/component/grpcserver"
@@ -12,35 +10,31 @@ import (
 	"github.com/linggaaskaedo/go-kill/common/component/redis"
 	"github.com/linggaaskaedo/go-kill/common/component/scheduler"
 	"github.com/linggaaskaedo/go-kill/common/component/server"
+	"github.com/linggaaskaedo/go-kill/common/component/tracer"
+	commonConfig "github.com/linggaaskaedo/go-kill/common/pkg/config"
+	"github.com/linggaaskaedo/go-kill/common/pkg/idempotency"
 	"github.com/linggaaskaedo/go-kill/common/pkg/logger"
-
-	"github.com/goccy/go-yaml"
 )
 
 type Config struct {
 	Logger     logger.Config                `yaml:"logger"`
+	Tracer     tracer.Config                `yaml:"tracer"`
 	Redis      redis.Config                 `yaml:"redis"`
-	Database   map[string]database.Config   `yaml:"database"`
+	Database   map[string]database.Config   `yaml:"database" validate:"dive"`
 	Query      query.Config                 `yaml:"queries"`
-	Mongo      map[string]mongo.Config      `yaml:"mongo"`
-	Scheduler  map[string]scheduler.Config  `yaml:"scheduler"`
-	GRPCClient map[string]grpcclient.Config `yaml:"grpc_client"`
+	Mongo      map[string]mongo.Config      `yaml:"mongo" validate:"dive"`
+	Scheduler  map[string]scheduler.Config  `yaml:"scheduler" validate:"dive"`
+	GRPCClient map[string]grpcclient.Config `yaml:"grpc_client" validate:"dive"`
 	GRPCServer grpcserver.Config            `yaml:"grpc_server"`
 	Http       http.Config                  `yaml:"http"`
 	Server     server.Config                `yaml:"server"`
-}
 
-func Load(configPath string) (*Config, error) {
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		return nil, err
-	}
-
-	// Expand environment variables in the YAML content
-	expandedData := os.ExpandEnv(string(data))
+	Idempotency idempotency.Config `yaml:"idempotency"`
+}
 
+func Load(src commonConfig.Source) (*Config, error) {
 	var cfg Config
-	if err := yaml.Unmarshal([]byte(expandedData), &cfg); err != nil {
+	if err := commonConfig.Load(src, &cfg); err != nil {
 		return nil, err
 	}
 
@@ -18,6 +18,10 @@ func (g *Grpc) GetUser(ctx context.Context, req *userpb.GetUserRequest) (*userpb
 	return resp, nil
 }
 
+func (g *Grpc) GetUserByAuthId(ctx context.Context, req *userpb.GetUserByAuthIdRequest) (*userpb.GetUserResponse, error) {
+	return g.svc.User.GetUserByAuthId(ctx, req)
+}
+
 func (g *Grpc) GetAddress(ctx context.Context, req *userpb.GetAddressRequest) (*userpb.GetAddressResponse, error) {
 	return g.svc.User.GetAddress(ctx, req)
 }
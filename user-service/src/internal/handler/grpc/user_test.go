@@ -43,6 +43,14 @@ func (m *MockUserService) GetUser(ctx context.Context, req *userpb.GetUserReques
 	return args.Get(0).(*userpb.GetUserResponse), args.Error(1)
 }
 
+func (m *MockUserService) GetUserByAuthId(ctx context.Context, req *userpb.GetUserByAuthIdRequest) (*userpb.GetUserResponse, error) {
+	args := m.Called(mock.Anything, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*userpb.GetUserResponse), args.Error(1)
+}
+
 func (m *MockUserService) GetAddress(ctx context.Context, req *userpb.GetAddressRequest) (*userpb.GetAddressResponse, error) {
 	args := m.Called(mock.Anything, req)
 	if args.Get(0) == nil {
@@ -67,6 +75,14 @@ func (m *MockUserService) ValidateToken(ctx context.Context, req *authpb.Validat
 	return args.Get(0).(*authpb.ValidateTokenResponse), args.Error(1)
 }
 
+func (m *MockUserService) ValidateApiKey(ctx context.Context, req *authpb.ValidateApiKeyRequest) (*authpb.ValidateTokenResponse, error) {
+	args := m.Called(mock.Anything, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*authpb.ValidateTokenResponse), args.Error(1)
+}
+
 func (m *MockUserService) RegisterUser(ctx context.Context, req dto.RegisterUserRequest) (*dto.UserRegResp, error) {
 	args := m.Called(mock.Anything, req)
 	if args.Get(0) == nil {
@@ -208,6 +224,52 @@ func TestGetUserError(t *testing.T) {
 	mockUser.AssertExpectations(t)
 }
 
+func TestGetUserByAuthIdSuccess(t *testing.T) {
+	mockUser := new(MockUserService)
+	grpcHandler, _ := setupTestGrpc(mockUser)
+	ctx := context.Background()
+
+	expectedResp := &userpb.GetUserResponse{
+		Id:    testUserID,
+		Email: testUserEmail,
+		Found: true,
+	}
+
+	mockUser.On("GetUserByAuthId", mock.Anything, mock.Anything).Return(expectedResp, nil)
+
+	req := &userpb.GetUserByAuthIdRequest{
+		AuthId: testAuthID,
+	}
+
+	resp, err := grpcHandler.GetUserByAuthId(ctx, req)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, resp)
+	assert.Equal(t, testUserID, resp.GetId())
+	assert.True(t, resp.GetFound())
+	mockUser.AssertExpectations(t)
+}
+
+func TestGetUserByAuthIdError(t *testing.T) {
+	mockUser := new(MockUserService)
+	grpcHandler, _ := setupTestGrpc(mockUser)
+	ctx := context.Background()
+
+	expectedErr := errors.New("user not found")
+	mockUser.On("GetUserByAuthId", mock.Anything, mock.Anything).Return(nil, expectedErr)
+
+	req := &userpb.GetUserByAuthIdRequest{
+		AuthId: "non-existent-auth",
+	}
+
+	resp, err := grpcHandler.GetUserByAuthId(ctx, req)
+
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+	assert.Equal(t, expectedErr, err)
+	mockUser.AssertExpectations(t)
+}
+
 func TestGetAddressSuccess(t *testing.T) {
 	mockUser := new(MockUserService)
 	grpcHandler, _ := setupTestGrpc(mockUser)
@@ -4,6 +4,10 @@ import (
 	"net/http"
 	"sync"
 
+	"github.com/linggaaskaedo/go-kill/common/pkg/authz"
+	"github.com/linggaaskaedo/go-kill/common/pkg/idempotency"
+	"github.com/linggaaskaedo/go-kill/common/pkg/middleware"
+	"github.com/linggaaskaedo/go-kill/common/pkg/preference"
 	authpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/auth"
 	"github.com/linggaaskaedo/go-kill/user-service/src/internal/service"
 
@@ -13,25 +17,46 @@ import (
 var onceRestHandler = &sync.Once{}
 
 type rest struct {
-	gin *gin.Engine
-	svc *service.Service
+	gin         *gin.Engine
+	svc         *service.Service
+	idempotency *idempotency.Idempotency
 }
 
-func InitRestHandler(gin *gin.Engine, svc *service.Service) {
+func InitRestHandler(gin *gin.Engine, svc *service.Service, idem *idempotency.Idempotency) {
 	var e *rest
 
 	onceRestHandler.Do(func() {
 		e = &rest{
-			gin: gin,
-			svc: svc,
+			gin:         gin,
+			svc:         svc,
+			idempotency: idem,
 		}
 
 		e.Serve()
 	})
 }
 
-func (e *rest) authMiddleware() gin.HandlerFunc {
+// authMiddleware accepts either an API key in X-API-Key or a bearer token,
+// keeps the identity auth-service verified on the context and requires
+// permissions of it. Every route names one, so an API key only reaches the
+// routes its scopes cover.
+func (e *rest) authMiddleware(permissions ...string) gin.HandlerFunc {
+	authorize := middleware.Authorize(e.httpRespError, permissions...)
+
 	return func(c *gin.Context) {
+		if apiKey := c.GetHeader(preference.API_KEY); apiKey != "" {
+			resp, err := e.svc.User.ValidateApiKey(c.Request.Context(), &authpb.ValidateApiKeyRequest{ApiKey: apiKey})
+			if err != nil || !resp.Valid {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
+				c.Abort()
+				return
+			}
+
+			setIdentity(c, resp)
+			authorize(c)
+			return
+		}
+
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "No authorization header"})
@@ -55,16 +80,25 @@ func (e *rest) authMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		c.Set("user_auth_id", resp.UserId)
-		c.Set("email", resp.Email)
-		c.Next()
+		setIdentity(c, resp)
+		authorize(c)
 	}
 }
 
+func setIdentity(c *gin.Context, resp *authpb.ValidateTokenResponse) {
+	c.Set("user_auth_id", resp.UserId)
+	c.Set("email", resp.Email)
+	c.Request = c.Request.WithContext(authz.WithPrincipal(c.Request.Context(), &authz.Principal{
+		UserID:      resp.UserId,
+		Roles:       resp.Roles,
+		Permissions: resp.Permissions,
+	}))
+}
+
 func (e *rest) Serve() {
-	e.gin.POST("/api/v1/users/register", e.handleRegister)
-	e.gin.GET("/api/v1/users/me", e.authMiddleware(), e.handleGetMe)
-	e.gin.GET("/api/v1/users/me/activities", e.authMiddleware(), e.handleGetActivities)
-	e.gin.GET("/api/v1/users/me/addresses", e.authMiddleware(), e.handleGetAddresses)
-	e.gin.POST("/api/v1/users/me/addresses", e.authMiddleware(), e.handleCreateAddress)
+	e.gin.POST("/api/v1/users/register", e.idempotency.Middleware("", e.httpRespError), e.handleRegister)
+	e.gin.GET("/api/v1/users/me", e.authMiddleware(authz.PermUserRead), e.handleGetMe)
+	e.gin.GET("/api/v1/users/me/activities", e.authMiddleware(authz.PermUserRead), e.handleGetActivities)
+	e.gin.GET("/api/v1/users/me/addresses", e.authMiddleware(authz.PermUserRead), e.handleGetAddresses)
+	e.gin.POST("/api/v1/users/me/addresses", e.authMiddleware(authz.PermUserWrite), e.handleCreateAddress)
 }
@@ -26,7 +26,7 @@ func TestInitRestHandler(t *testing.T) {
 
 	svc := &service.Service{}
 
-	InitRestHandler(router, svc)
+	InitRestHandler(router, svc, nil)
 }
 
 func TestServeRoutesRegistered(t *testing.T) {
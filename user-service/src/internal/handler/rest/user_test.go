@@ -9,6 +9,8 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/linggaaskaedo/go-kill/common/pkg/authz"
+	"github.com/linggaaskaedo/go-kill/common/pkg/preference"
 	authpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/auth"
 	userpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/user"
 	"github.com/linggaaskaedo/go-kill/user-service/src/internal/model/dto"
@@ -30,6 +32,9 @@ const (
 	headerAuthBearer       = "Bearer valid-token"
 )
 
+// userPermissions are what the customer role grants a bearer token.
+var userPermissions = []string{authz.PermUserRead, authz.PermUserWrite}
+
 type MockUserService struct {
 	mock.Mock
 }
@@ -50,6 +55,14 @@ func (m *MockUserService) GetUser(ctx context.Context, req *userpb.GetUserReques
 	return args.Get(0).(*userpb.GetUserResponse), args.Error(1)
 }
 
+func (m *MockUserService) GetUserByAuthId(ctx context.Context, req *userpb.GetUserByAuthIdRequest) (*userpb.GetUserResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*userpb.GetUserResponse), args.Error(1)
+}
+
 func (m *MockUserService) GetAddress(ctx context.Context, req *userpb.GetAddressRequest) (*userpb.GetAddressResponse, error) {
 	args := m.Called(ctx, req)
 	if args.Get(0) == nil {
@@ -74,6 +87,14 @@ func (m *MockUserService) ValidateToken(ctx context.Context, req *authpb.Validat
 	return args.Get(0).(*authpb.ValidateTokenResponse), args.Error(1)
 }
 
+func (m *MockUserService) ValidateApiKey(ctx context.Context, req *authpb.ValidateApiKeyRequest) (*authpb.ValidateTokenResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*authpb.ValidateTokenResponse), args.Error(1)
+}
+
 func (m *MockUserService) RegisterUser(ctx context.Context, req dto.RegisterUserRequest) (*dto.UserRegResp, error) {
 	args := m.Called(ctx, req)
 	if args.Get(0) == nil {
@@ -128,10 +149,10 @@ func setupRouter(handler *rest) *gin.Engine {
 	r := gin.New()
 
 	r.POST(pathUsersRegister, handler.handleRegister)
-	r.GET(pathUsersMe, handler.authMiddleware(), handler.handleGetMe)
-	r.GET("/api/v1/users/me/activities", handler.authMiddleware(), handler.handleGetActivities)
-	r.GET(pathUsersMeAddresses, handler.authMiddleware(), handler.handleGetAddresses)
-	r.POST(pathUsersMeAddresses, handler.authMiddleware(), handler.handleCreateAddress)
+	r.GET(pathUsersMe, handler.authMiddleware(authz.PermUserRead), handler.handleGetMe)
+	r.GET("/api/v1/users/me/activities", handler.authMiddleware(authz.PermUserRead), handler.handleGetActivities)
+	r.GET(pathUsersMeAddresses, handler.authMiddleware(authz.PermUserRead), handler.handleGetAddresses)
+	r.POST(pathUsersMeAddresses, handler.authMiddleware(authz.PermUserWrite), handler.handleCreateAddress)
 
 	return r
 }
@@ -226,7 +247,7 @@ func TestHandleGetMeSuccess(t *testing.T) {
 		LastName:  testLastName,
 	}
 
-	mockUser.On("ValidateToken", mock.Anything, mock.Anything).Return(&authpb.ValidateTokenResponse{Valid: true, UserId: testUserAuthID}, nil)
+	mockUser.On("ValidateToken", mock.Anything, mock.Anything).Return(&authpb.ValidateTokenResponse{Valid: true, UserId: testUserAuthID, Permissions: userPermissions}, nil)
 	mockUser.On("GetMe", mock.Anything, testUserAuthID).Return(resp, nil)
 
 	req, _ := http.NewRequest(http.MethodGet, pathUsersMe, nil)
@@ -270,6 +291,74 @@ func TestHandleGetMeInvalidToken(t *testing.T) {
 	mockUser.AssertExpectations(t)
 }
 
+func TestHandleGetMeWithApiKey(t *testing.T) {
+	mockUser := new(MockUserService)
+	handler := setupTestRest(mockUser)
+	router := setupRouter(handler)
+
+	mockUser.On("ValidateApiKey", mock.Anything, &authpb.ValidateApiKeyRequest{ApiKey: "gk_valid-key"}).Return(&authpb.ValidateTokenResponse{Valid: true, UserId: testUserAuthID, ApiKeyId: "key-123", Permissions: []string{authz.PermUserRead}}, nil)
+	mockUser.On("GetMe", mock.Anything, testUserAuthID).Return(&dto.UserResp{ID: "user-123"}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, pathUsersMe, nil)
+	req.Header.Set(preference.API_KEY, "gk_valid-key")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockUser.AssertExpectations(t)
+	mockUser.AssertNotCalled(t, "ValidateToken", mock.Anything, mock.Anything)
+}
+
+func TestApiKeyOutsideItsScopesIsForbidden(t *testing.T) {
+	mockUser := new(MockUserService)
+	handler := setupTestRest(mockUser)
+	router := setupRouter(handler)
+
+	// A key made only for order automation cannot read the profile
+	mockUser.On("ValidateApiKey", mock.Anything, &authpb.ValidateApiKeyRequest{ApiKey: "gk_valid-key"}).Return(&authpb.ValidateTokenResponse{Valid: true, UserId: testUserAuthID, ApiKeyId: "key-123", Permissions: []string{authz.PermOrderStatusUpdate}}, nil)
+
+	for _, tc := range []struct {
+		method string
+		path   string
+	}{
+		{http.MethodGet, pathUsersMe},
+		{http.MethodGet, pathUsersMeAddresses},
+		{http.MethodPost, pathUsersMeAddresses},
+	} {
+		req, _ := http.NewRequest(tc.method, tc.path, bytes.NewBufferString("{}"))
+		req.Header.Set(headerContentType, headerContentTypeValue)
+		req.Header.Set(preference.API_KEY, "gk_valid-key")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code, tc.method+" "+tc.path)
+	}
+
+	mockUser.AssertNotCalled(t, "GetMe", mock.Anything, mock.Anything)
+	mockUser.AssertNotCalled(t, "GetAddresses", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockUser.AssertNotCalled(t, "CreateAddress", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestHandleGetMeInvalidApiKey(t *testing.T) {
+	mockUser := new(MockUserService)
+	handler := setupTestRest(mockUser)
+	router := setupRouter(handler)
+
+	mockUser.On("ValidateApiKey", mock.Anything, mock.Anything).Return(nil, errors.New("invalid API key"))
+
+	req, _ := http.NewRequest(http.MethodGet, pathUsersMe, nil)
+	req.Header.Set(preference.API_KEY, "gk_revoked-key")
+	req.Header.Set("Authorization", headerAuthBearer)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	mockUser.AssertNotCalled(t, "GetMe", mock.Anything, mock.Anything)
+}
+
 func TestHandleGetActivitiesSuccess(t *testing.T) {
 	mockUser := new(MockUserService)
 	handler := setupTestRest(mockUser)
@@ -279,7 +368,7 @@ func TestHandleGetActivitiesSuccess(t *testing.T) {
 		Success: true,
 	}
 
-	mockUser.On("ValidateToken", mock.Anything, mock.Anything).Return(&authpb.ValidateTokenResponse{Valid: true, UserId: testUserAuthID}, nil)
+	mockUser.On("ValidateToken", mock.Anything, mock.Anything).Return(&authpb.ValidateTokenResponse{Valid: true, UserId: testUserAuthID, Permissions: userPermissions}, nil)
 	mockUser.On("GetActivities", mock.Anything, testUserAuthID, "1", "20").Return(resp, nil)
 
 	req, _ := http.NewRequest(http.MethodGet, "/api/v1/users/me/activities", nil)
@@ -303,7 +392,7 @@ func TestHandleGetAddressesSuccess(t *testing.T) {
 		Data:    []*dto.Address{},
 	}
 
-	mockUser.On("ValidateToken", mock.Anything, mock.Anything).Return(&authpb.ValidateTokenResponse{Valid: true, UserId: testUserAuthID}, nil)
+	mockUser.On("ValidateToken", mock.Anything, mock.Anything).Return(&authpb.ValidateTokenResponse{Valid: true, UserId: testUserAuthID, Permissions: userPermissions}, nil)
 	mockUser.On("GetAddresses", mock.Anything, testUserAuthID, "1", "20").Return(resp, nil)
 
 	req, _ := http.NewRequest(http.MethodGet, pathUsersMeAddresses, nil)
@@ -322,7 +411,7 @@ func TestHandleCreateAddressSuccess(t *testing.T) {
 	handler := setupTestRest(mockUser)
 	router := setupRouter(handler)
 
-	mockUser.On("ValidateToken", mock.Anything, mock.Anything).Return(&authpb.ValidateTokenResponse{Valid: true, UserId: testUserAuthID}, nil)
+	mockUser.On("ValidateToken", mock.Anything, mock.Anything).Return(&authpb.ValidateTokenResponse{Valid: true, UserId: testUserAuthID, Permissions: userPermissions}, nil)
 	mockUser.On("CreateAddress", mock.Anything, testUserAuthID, mock.AnythingOfType("dto.CreateUserAddress")).Return("addr-123", nil)
 
 	reqBody := dto.CreateUserAddress{
@@ -351,7 +440,7 @@ func TestHandleCreateAddressInvalidBody(t *testing.T) {
 	handler := setupTestRest(mockUser)
 	router := setupRouter(handler)
 
-	mockUser.On("ValidateToken", mock.Anything, mock.Anything).Return(&authpb.ValidateTokenResponse{Valid: true, UserId: testUserAuthID}, nil)
+	mockUser.On("ValidateToken", mock.Anything, mock.Anything).Return(&authpb.ValidateTokenResponse{Valid: true, UserId: testUserAuthID, Permissions: userPermissions}, nil)
 
 	req, _ := http.NewRequest(http.MethodPost, pathUsersMeAddresses, bytes.NewBuffer([]byte("invalid json")))
 	req.Header.Set(headerContentType, headerContentTypeValue)
@@ -16,6 +16,7 @@ type UserRepositoryItf interface {
 	// gRPC
 	CreateUser(ctx context.Context, req *userpb.CreateUserRequest) (*userpb.CreateUserResponse, error)
 	GetUser(ctx context.Context, req *userpb.GetUserRequest) (*userpb.GetUserResponse, error)
+	GetUserByAuthId(ctx context.Context, req *userpb.GetUserByAuthIdRequest) (*userpb.GetUserResponse, error)
 	GetAddress(ctx context.Context, req *userpb.GetAddressRequest) (*userpb.GetAddressResponse, error)
 	LogActivity(ctx context.Context, req *userpb.LogActivityRequest) (*userpb.LogActivityResponse, error)
 
@@ -30,6 +30,21 @@ func (u *userRepository) GetUser(ctx context.Context, req *userpb.GetUserRequest
 	}, nil
 }
 
+func (u *userRepository) GetUserByAuthId(ctx context.Context, req *userpb.GetUserByAuthIdRequest) (*userpb.GetUserResponse, error) {
+	resp, err := u.getUserByAuthIDSQL(ctx, req.AuthId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &userpb.GetUserResponse{
+		Id:        resp.ID,
+		Email:     resp.Email,
+		FirstName: resp.FirstName,
+		LastName:  resp.LastName,
+		Found:     true,
+	}, nil
+}
+
 func (u *userRepository) GetAddress(ctx context.Context, req *userpb.GetAddressRequest) (*userpb.GetAddressResponse, error) {
 	resp, err := u.getUserAddressByIDSQL(ctx, req)
 	if err != nil {
@@ -16,11 +16,13 @@ type UserServiceItf interface {
 	//gRPC
 	CreateUser(ctx context.Context, req *userpb.CreateUserRequest) (*userpb.CreateUserResponse, error)
 	GetUser(ctx context.Context, req *userpb.GetUserRequest) (*userpb.GetUserResponse, error)
+	GetUserByAuthId(ctx context.Context, req *userpb.GetUserByAuthIdRequest) (*userpb.GetUserResponse, error)
 	GetAddress(ctx context.Context, req *userpb.GetAddressRequest) (*userpb.GetAddressResponse, error)
 	LogActivity(ctx context.Context, req *userpb.LogActivityRequest) (*userpb.LogActivityResponse, error)
 
 	// REST
 	ValidateToken(ctx context.Context, req *authpb.ValidateTokenRequest) (*authpb.ValidateTokenResponse, error)
+	ValidateApiKey(ctx context.Context, req *authpb.ValidateApiKeyRequest) (*authpb.ValidateTokenResponse, error)
 	RegisterUser(ctx context.Context, req dto.RegisterUserRequest) (*dto.UserRegResp, error)
 	GetMe(ctx context.Context, userAuthID string) (*dto.UserResp, error)
 	GetActivities(ctx context.Context, userAuthID string, page string, limit string) (*dto.UserActivity, error)
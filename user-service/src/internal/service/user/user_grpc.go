@@ -14,6 +14,10 @@ func (s *userService) GetUser(ctx context.Context, req *userpb.GetUserRequest) (
 	return s.userRepository.GetUser(ctx, req)
 }
 
+func (s *userService) GetUserByAuthId(ctx context.Context, req *userpb.GetUserByAuthIdRequest) (*userpb.GetUserResponse, error) {
+	return s.userRepository.GetUserByAuthId(ctx, req)
+}
+
 func (s *userService) GetAddress(ctx context.Context, req *userpb.GetAddressRequest) (*userpb.GetAddressResponse, error) {
 	return s.userRepository.GetAddress(ctx, req)
 }
@@ -3,6 +3,7 @@ package user
 import (
 	"context"
 
+	"github.com/linggaaskaedo/go-kill/common/pkg/idempotency"
 	authpb "github.com/linggaaskaedo/go-kill/common/pkg/proto/auth"
 	"github.com/linggaaskaedo/go-kill/user-service/src/internal/model/dto"
 	"github.com/linggaaskaedo/go-kill/user-service/src/internal/model/entity"
@@ -20,8 +21,22 @@ func (s *userService) ValidateToken(ctx context.Context, req *authpb.ValidateTok
 	return authResp, nil
 }
 
+func (s *userService) ValidateApiKey(ctx context.Context, req *authpb.ValidateApiKeyRequest) (*authpb.ValidateTokenResponse, error) {
+	authResp, err := s.authClient.ValidateApiKey(ctx, req)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("validate_api_key")
+		return nil, err
+	}
+
+	return authResp, nil
+}
+
 func (s *userService) RegisterUser(ctx context.Context, req dto.RegisterUserRequest) (*dto.UserRegResp, error) {
-	authResp, err := s.authClient.CreateAuthUser(ctx, &authpb.CreateAuthUserRequest{Email: req.Email, Password: req.Password})
+	authResp, err := s.authClient.CreateAuthUser(ctx, &authpb.CreateAuthUserRequest{
+		Email:          req.Email,
+		Password:       req.Password,
+		IdempotencyKey: idempotency.KeyFromContext(ctx),
+	})
 	if err != nil {
 		zerolog.Ctx(ctx).Error().Err(err).Msg("register_user")
 		return nil, err